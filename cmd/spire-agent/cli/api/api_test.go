@@ -2,22 +2,30 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/test/clitest"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakeworkloadapi"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testca"
@@ -31,6 +39,160 @@ import (
 
 var availableFormats = []string{"pretty", "json"}
 
+func TestFetchBundleCommandHelp(t *testing.T) {
+	test := setupTest(t, newFetchBundleCommand)
+	test.cmd.Help()
+	require.Equal(t, fetchBundleUsage, test.stderr.String())
+}
+
+func TestFetchBundleCommandSynopsis(t *testing.T) {
+	test := setupTest(t, newFetchBundleCommand)
+	require.Equal(t, "Fetches X.509 bundles from the Workload API", test.cmd.Synopsis())
+}
+
+func TestFetchBundleCommand(t *testing.T) {
+	ca := testca.New(t, spiffeid.RequireTrustDomainFromString("example.org"))
+	federatedCA := testca.New(t, spiffeid.RequireTrustDomainFromString("domain.test"))
+	bundlePEM := string(pemFromCertificates(ca.Bundle().X509Authorities()))
+	federatedBundlePEM := string(pemFromCertificates(federatedCA.Bundle().X509Authorities()))
+
+	bundlesRequest := &fakeworkloadapi.FakeRequest{
+		Req: &workload.X509BundlesRequest{},
+		Resp: &workload.X509BundlesResponse{
+			Bundles: map[string][]byte{
+				"spiffe://example.org": x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+				"spiffe://domain.test": x509util.DERFromCertificates(federatedCA.Bundle().X509Authorities()),
+			},
+		},
+	}
+	quote := func(s string) string {
+		b, err := json.Marshal(s)
+		require.NoError(t, err)
+		return string(b)
+	}
+	bothBundlesJSON := fmt.Sprintf(`[
+		{"trust_domain": "domain.test", "bundle": %s},
+		{"trust_domain": "example.org", "bundle": %s}
+	]`, quote(federatedBundlePEM), quote(bundlePEM))
+
+	tests := []struct {
+		name                 string
+		args                 []string
+		fakeRequests         []*fakeworkloadapi.FakeRequest
+		expectedStderr       string
+		expectedStdoutPretty []string
+		expectedStdoutJSON   string
+	}{
+		{
+			name:         "success fetching local and federated bundles",
+			fakeRequests: []*fakeworkloadapi.FakeRequest{bundlesRequest},
+			expectedStdoutPretty: []string{
+				"Received 2 bundles after",
+				"Trust domain: domain.test\n" + federatedBundlePEM,
+				"Trust domain: example.org\n" + bundlePEM,
+			},
+			expectedStdoutJSON: bothBundlesJSON,
+		},
+		{
+			name:         "success fetching federated bundle by trust domain",
+			args:         []string{"-trustDomain", "domain.test"},
+			fakeRequests: []*fakeworkloadapi.FakeRequest{bundlesRequest},
+			expectedStdoutPretty: []string{
+				"Received 1 bundle after",
+				"Trust domain: domain.test\n" + federatedBundlePEM,
+			},
+			expectedStdoutJSON: fmt.Sprintf(`[{"trust_domain": "domain.test", "bundle": %s}]`, quote(federatedBundlePEM)),
+		},
+		{
+			name:         "success fetching local bundle by trust domain ID",
+			args:         []string{"-trustDomain", "spiffe://example.org"},
+			fakeRequests: []*fakeworkloadapi.FakeRequest{bundlesRequest},
+			expectedStdoutPretty: []string{
+				"Received 1 bundle after",
+				"Trust domain: example.org\n" + bundlePEM,
+			},
+			expectedStdoutJSON: fmt.Sprintf(`[{"trust_domain": "example.org", "bundle": %s}]`, quote(bundlePEM)),
+		},
+		{
+			name:           "unknown trust domain",
+			args:           []string{"-trustDomain", "unknown.test"},
+			fakeRequests:   []*fakeworkloadapi.FakeRequest{bundlesRequest},
+			expectedStderr: "no bundle found for trust domain \"unknown.test\"\n",
+		},
+		{
+			name: "response with no bundles",
+			fakeRequests: []*fakeworkloadapi.FakeRequest{
+				{
+					Req:  &workload.X509BundlesRequest{},
+					Resp: &workload.X509BundlesResponse{},
+				},
+			},
+			expectedStderr: "workload response contains no bundles\n",
+		},
+		{
+			name: "fail with unknown error",
+			fakeRequests: []*fakeworkloadapi.FakeRequest{
+				{
+					Req:  &workload.X509BundlesRequest{},
+					Resp: &workload.X509BundlesResponse{},
+					Err:  errors.New("error"),
+				},
+			},
+			expectedStderr: "rpc error: code = Unknown desc = error\n",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				test := setupTest(t, newFetchBundleCommand, tt.fakeRequests...)
+				args := tt.args
+				args = append(args, "-output", format)
+
+				rc := test.cmd.Run(test.args(args...))
+
+				if tt.expectedStderr != "" {
+					assert.Equal(t, 1, rc)
+					assert.Equal(t, tt.expectedStderr, test.stderr.String())
+					return
+				}
+
+				assert.Empty(t, test.stderr.String())
+				assert.Equal(t, 0, rc)
+				assertOutputBasedOnFormat(t, format, test.stdout.String(), tt.expectedStdoutJSON, tt.expectedStdoutPretty...)
+			})
+		}
+	}
+
+	t.Run("write", func(t *testing.T) {
+		test := setupTest(t, newFetchBundleCommand, bundlesRequest)
+		testDir := t.TempDir()
+
+		rc := test.cmd.Run(test.args("-write", testDir))
+		require.Equal(t, 0, rc, test.stderr.String())
+
+		content, err := os.ReadFile(filepath.Join(testDir, "example.org.pem"))
+		require.NoError(t, err)
+		require.Equal(t, bundlePEM, string(content))
+		content, err = os.ReadFile(filepath.Join(testDir, "domain.test.pem"))
+		require.NoError(t, err)
+		require.Equal(t, federatedBundlePEM, string(content))
+	})
+
+	t.Run("write json", func(t *testing.T) {
+		test := setupTest(t, newFetchBundleCommand, bundlesRequest)
+		testDir := t.TempDir()
+
+		rc := test.cmd.Run(test.args("-output", "json", "-write", testDir))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Empty(t, test.stdout.String())
+
+		content, err := os.ReadFile(filepath.Join(testDir, "bundles.json"))
+		require.NoError(t, err)
+		require.JSONEq(t, bothBundlesJSON, string(content))
+	})
+}
+
 func TestFetchJWTCommandHelp(t *testing.T) {
 	test := setupTest(t, newFetchJWTCommandWithEnv)
 	test.cmd.Help()
@@ -47,6 +209,7 @@ func TestFetchJWTCommand(t *testing.T) {
 	ca := testca.New(t, td)
 	encodedSvid1 := ca.CreateJWTSVID(spiffeid.RequireFromString("spiffe://domain1.test"), []string{"foo"}).Marshal()
 	encodedSvid2 := ca.CreateJWTSVID(spiffeid.RequireFromString("spiffe://domain2.test"), []string{"foo"}).Marshal()
+	encodedSvid3 := ca.CreateJWTSVID(spiffeid.RequireFromString("spiffe://domain1.test"), []string{"bar", "baz"}).Marshal()
 	bundleJWKSBytes, err := ca.JWTBundle().Marshal()
 	require.NoError(t, err)
 
@@ -120,6 +283,166 @@ func TestFetchJWTCommand(t *testing.T) {
     }
   }
 ]`, encodedSvid1, encodedSvid2, base64.StdEncoding.EncodeToString(bundleJWKSBytes), base64.StdEncoding.EncodeToString(bundleJWKSBytes)),
+		},
+		{
+			name: "success fetching jwt by hint",
+			args: []string{"-audience", "foo", "-hint", "external"},
+			fakeRequests: []*fakeworkloadapi.FakeRequest{
+				{
+					Req: &workload.JWTBundlesRequest{},
+					Resp: &workload.JWTBundlesResponse{
+						Bundles: map[string][]byte{
+							"spiffe://domain1.test": bundleJWKSBytes,
+						},
+					},
+				},
+				{
+					Req: &workload.JWTSVIDRequest{
+						Audience: []string{"foo"},
+					},
+					Resp: &workload.JWTSVIDResponse{
+						Svids: []*workload.JWTSVID{
+							{
+								SpiffeId: "spiffe://domain1.test",
+								Svid:     encodedSvid1,
+								Hint:     "external",
+							},
+							{
+								SpiffeId: "spiffe://domain2.test",
+								Svid:     encodedSvid2,
+								Hint:     "internal",
+							},
+						},
+					},
+				},
+			},
+			expectedStdoutPretty: []string{
+				fmt.Sprintf("token(spiffe://domain1.test):\n\t%s", encodedSvid1),
+				fmt.Sprintf("hint(spiffe://domain1.test):\n\t%s", "external"),
+			},
+			expectedStdoutJSON: fmt.Sprintf(`[
+  {
+    "svids": [
+      {
+        "hint": "external",
+        "spiffe_id": "spiffe://domain1.test",
+        "svid": "%s"
+      }
+    ]
+  },
+  {
+    "bundles": {
+      "spiffe://domain1.test": "%s"
+    }
+  }
+]`, encodedSvid1, base64.StdEncoding.EncodeToString(bundleJWKSBytes)),
+		},
+		{
+			name: "fail when no svid matches the hint",
+			args: []string{"-audience", "foo", "-hint", "unknown"},
+			fakeRequests: []*fakeworkloadapi.FakeRequest{
+				{
+					Req:  &workload.JWTBundlesRequest{},
+					Resp: &workload.JWTBundlesResponse{},
+				},
+				{
+					Req: &workload.JWTSVIDRequest{
+						Audience: []string{"foo"},
+					},
+					Resp: &workload.JWTSVIDResponse{
+						Svids: []*workload.JWTSVID{
+							{
+								SpiffeId: "spiffe://domain1.test",
+								Svid:     encodedSvid1,
+								Hint:     "external",
+							},
+							{
+								SpiffeId: "spiffe://domain2.test",
+								Svid:     encodedSvid2,
+								Hint:     "internal",
+							},
+						},
+					},
+				},
+			},
+			expectedStderr: "no SVID found with hint \"unknown\"; available hints: \"external\", \"internal\"\n",
+		},
+		{
+			name: "success fetching jwt for several audiences",
+			args: []string{"-audience", "foo", "-audience", "bar,baz", "-hint", "external"},
+			fakeRequests: []*fakeworkloadapi.FakeRequest{
+				{
+					Req: &workload.JWTBundlesRequest{},
+					Resp: &workload.JWTBundlesResponse{
+						Bundles: map[string][]byte{
+							"spiffe://domain1.test": bundleJWKSBytes,
+						},
+					},
+				},
+				{
+					Req: &workload.JWTSVIDRequest{
+						Audience: []string{"foo"},
+					},
+					Resp: &workload.JWTSVIDResponse{
+						Svids: []*workload.JWTSVID{
+							{
+								SpiffeId: "spiffe://domain1.test",
+								Svid:     encodedSvid1,
+								Hint:     "external",
+							},
+							{
+								SpiffeId: "spiffe://domain2.test",
+								Svid:     encodedSvid2,
+								Hint:     "internal",
+							},
+						},
+					},
+				},
+				{
+					Req: &workload.JWTSVIDRequest{
+						Audience: []string{"bar", "baz"},
+					},
+					Resp: &workload.JWTSVIDResponse{
+						Svids: []*workload.JWTSVID{
+							{
+								SpiffeId: "spiffe://domain1.test",
+								Svid:     encodedSvid3,
+								Hint:     "external",
+							},
+						},
+					},
+				},
+			},
+			expectedStdoutPretty: []string{
+				fmt.Sprintf("audience(foo):\ntoken(spiffe://domain1.test):\n\t%s\nhint(spiffe://domain1.test):\n\t%s\n", encodedSvid1, "external"),
+				fmt.Sprintf("audience(bar,baz):\ntoken(spiffe://domain1.test):\n\t%s\nhint(spiffe://domain1.test):\n\t%s\n", encodedSvid3, "external"),
+				fmt.Sprintf("bundle(spiffe://domain1.test):\n\t%s", bundleJWKSBytes),
+			},
+			expectedStdoutJSON: fmt.Sprintf(`[
+  {
+    "svids": [
+      {
+        "hint": "external",
+        "spiffe_id": "spiffe://domain1.test",
+        "svid": "%s"
+      }
+    ]
+  },
+  {
+    "svids": [
+      {
+        "hint": "external",
+        "spiffe_id": "spiffe://domain1.test",
+        "svid": "%s"
+      }
+    ]
+  },
+  {
+    "bundles": {
+      "spiffe://domain1.test": "%s"
+    }
+  }
+]`, encodedSvid1, encodedSvid3, base64.StdEncoding.EncodeToString(bundleJWKSBytes)),
 		},
 		{
 			name: "fail with error fetching bundles",
@@ -185,6 +508,146 @@ func TestFetchJWTCommand(t *testing.T) {
 	}
 }
 
+func TestFetchJWTCommandAudiences(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	id := spiffeid.RequireFromString("spiffe://example.org/workload")
+
+	test := setupTest(t, newFetchJWTCommandWithEnv,
+		&fakeworkloadapi.FakeRequest{
+			Req:  &workload.JWTBundlesRequest{},
+			Resp: &workload.JWTBundlesResponse{},
+		},
+		&fakeworkloadapi.FakeRequest{
+			Req: &workload.JWTSVIDRequest{
+				Audience: []string{"foo"},
+				SpiffeId: id.String(),
+			},
+			Resp: &workload.JWTSVIDResponse{
+				Svids: []*workload.JWTSVID{
+					{SpiffeId: id.String(), Svid: ca.CreateJWTSVID(id, []string{"foo"}).Marshal()},
+				},
+			},
+		},
+		&fakeworkloadapi.FakeRequest{
+			Req: &workload.JWTSVIDRequest{
+				Audience: []string{"bar"},
+				SpiffeId: id.String(),
+			},
+			Resp: &workload.JWTSVIDResponse{
+				Svids: []*workload.JWTSVID{
+					{SpiffeId: id.String(), Svid: ca.CreateJWTSVID(id, []string{"bar"}).Marshal()},
+				},
+			},
+		},
+	)
+
+	rc := test.cmd.Run(test.args("-audience", "foo", "-audience", "bar", "-spiffeID", id.String(), "-output", "json"))
+	require.Equal(t, 0, rc, test.stderr.String())
+
+	var output []struct {
+		Svids []struct {
+			SpiffeID string `json:"spiffe_id"`
+			Svid     string `json:"svid"`
+		} `json:"svids"`
+	}
+	require.NoError(t, json.Unmarshal(test.stdout.Bytes(), &output))
+	require.Len(t, output, 3)
+
+	// A distinct token is minted for each audience, in order
+	var tokens []string
+	for i, audience := range []string{"foo", "bar"} {
+		require.Len(t, output[i].Svids, 1)
+		token := output[i].Svids[0].Svid
+		svid, err := jwtsvid.ParseInsecure(token, []string{audience})
+		require.NoError(t, err)
+		require.Equal(t, []string{audience}, svid.Audience)
+		require.Equal(t, id, svid.ID)
+		tokens = append(tokens, token)
+	}
+	require.NotEqual(t, tokens[0], tokens[1])
+}
+
+func TestFetchJWTCommandWrite(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	encodedSvid1 := ca.CreateJWTSVID(spiffeid.RequireFromString("spiffe://example.org/foo"), []string{"aud"}).Marshal()
+	encodedSvid2 := ca.CreateJWTSVID(spiffeid.RequireFromString("spiffe://example.org/bar"), []string{"aud"}).Marshal()
+	bundleJWKSBytes, err := ca.JWTBundle().Marshal()
+	require.NoError(t, err)
+	federatedBundleJWKSBytes, err := testca.New(t, spiffeid.RequireTrustDomainFromString("domain.test")).JWTBundle().Marshal()
+	require.NoError(t, err)
+
+	bundlesRequest := &fakeworkloadapi.FakeRequest{
+		Req: &workload.JWTBundlesRequest{},
+		Resp: &workload.JWTBundlesResponse{
+			Bundles: map[string][]byte{
+				"spiffe://example.org": bundleJWKSBytes,
+				"spiffe://domain.test": federatedBundleJWKSBytes,
+			},
+		},
+	}
+	svidRequest := func(svids ...*workload.JWTSVID) *fakeworkloadapi.FakeRequest {
+		return &fakeworkloadapi.FakeRequest{
+			Req:  &workload.JWTSVIDRequest{Audience: []string{"aud"}},
+			Resp: &workload.JWTSVIDResponse{Svids: svids},
+		}
+	}
+	requireFile := func(t *testing.T, path string, content []byte, mode os.FileMode) {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			require.Equal(t, mode, info.Mode().Perm())
+		}
+		actual, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, string(content), string(actual))
+	}
+
+	t.Run("single svid", func(t *testing.T) {
+		test := setupTest(t, newFetchJWTCommandWithEnv, bundlesRequest,
+			svidRequest(&workload.JWTSVID{SpiffeId: "spiffe://example.org/foo", Svid: encodedSvid1}))
+		tokenPath := filepath.Join(t.TempDir(), "token")
+
+		rc := test.cmd.Run(test.args("-audience", "aud", "-write", tokenPath))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.NotContains(t, test.stdout.String(), encodedSvid1)
+		require.Equal(t, fmt.Sprintf("Writing JWT-SVID for spiffe://example.org/foo to file %s.\n", tokenPath), test.stdout.String())
+
+		requireFile(t, tokenPath, []byte(encodedSvid1), 0600)
+		_, err := os.Stat(tokenPath + ".example.org.jwks")
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("multiple svids with bundles", func(t *testing.T) {
+		test := setupTest(t, newFetchJWTCommandWithEnv, bundlesRequest,
+			svidRequest(
+				&workload.JWTSVID{SpiffeId: "spiffe://example.org/foo", Svid: encodedSvid1},
+				&workload.JWTSVID{SpiffeId: "spiffe://example.org/bar", Svid: encodedSvid2},
+			))
+		tokenPath := filepath.Join(t.TempDir(), "token")
+
+		rc := test.cmd.Run(test.args("-audience", "aud", "-write", tokenPath, "-bundle", "-output", "json"))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Empty(t, test.stdout.String())
+
+		requireFile(t, tokenPath+".0", []byte(encodedSvid1), 0600)
+		requireFile(t, tokenPath+".1", []byte(encodedSvid2), 0600)
+		requireFile(t, tokenPath+".example.org.jwks", bundleJWKSBytes, 0644)
+		requireFile(t, tokenPath+".domain.test.jwks", federatedBundleJWKSBytes, 0644)
+		_, err := os.Stat(tokenPath)
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+
+	t.Run("bundle without write", func(t *testing.T) {
+		test := setupTest(t, newFetchJWTCommandWithEnv)
+
+		rc := test.cmd.Run(test.args("-audience", "aud", "-bundle"))
+		require.Equal(t, 1, rc)
+		require.Equal(t, "-bundle requires -write\n", test.stderr.String())
+	})
+}
+
 func TestFetchX509CommandHelp(t *testing.T) {
 	test := setupTest(t, newFetchX509Command)
 	test.cmd.Help()
@@ -201,6 +664,29 @@ func TestFetchX509Command(t *testing.T) {
 	td := spiffeid.RequireTrustDomainFromString("example.org")
 	ca := testca.New(t, td)
 	svid := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+	otherSVID := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/bar"))
+
+	twoSVIDsRequest := &fakeworkloadapi.FakeRequest{
+		Req: &workload.X509SVIDRequest{},
+		Resp: &workload.X509SVIDResponse{
+			Svids: []*workload.X509SVID{
+				{
+					SpiffeId:    otherSVID.ID.String(),
+					X509Svid:    x509util.DERFromCertificates(otherSVID.Certificates),
+					X509SvidKey: pkcs8FromSigner(t, otherSVID.PrivateKey),
+					Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+					Hint:        "internal",
+				},
+				{
+					SpiffeId:    svid.ID.String(),
+					X509Svid:    x509util.DERFromCertificates(svid.Certificates),
+					X509SvidKey: pkcs8FromSigner(t, svid.PrivateKey),
+					Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+					Hint:        "external",
+				},
+			},
+		},
+	}
 
 	tests := []struct {
 		name                 string
@@ -244,22 +730,20 @@ CA #1 Valid Until:	%v
 				ca.Bundle().X509Authorities()[0].NotBefore,
 				ca.Bundle().X509Authorities()[0].NotAfter,
 			),
-			expectedStdoutJSON: fmt.Sprintf(`{
-  "crl": [],
-  "federated_bundles": {},
-  "svids": [
-    {
-      "bundle": "%s",
-      "hint": "external",
-      "spiffe_id": "spiffe://example.org/foo",
-      "x509_svid": "%s",
-      "x509_svid_key": "%s"
-    }
-  ]
-}`,
-				base64.StdEncoding.EncodeToString(x509util.DERFromCertificates(ca.Bundle().X509Authorities())),
-				base64.StdEncoding.EncodeToString(x509util.DERFromCertificates(svid.Certificates)),
-				base64.StdEncoding.EncodeToString(pkcs8FromSigner(t, svid.PrivateKey)),
+			expectedStdoutJSON: fmt.Sprintf(`[
+  {
+    "spiffe_id": "spiffe://example.org/foo",
+    "hint": "external",
+    "x509_svid": %q,
+    "x509_svid_key": %q,
+    "bundle": %q,
+    "expires_at": %q
+  }
+]`,
+				pemFromCertificates(svid.Certificates),
+				pemFromPKCS8(t, svid.PrivateKey),
+				pemFromCertificates(ca.Bundle().X509Authorities()),
+				svid.Certificates[0].NotAfter.UTC().Format(time.RFC3339),
 			),
 		},
 		{
@@ -301,25 +785,69 @@ Writing bundle #0 to file %s
 				fmt.Sprintf("%s/svid.0.key.", testDir),
 				fmt.Sprintf("%s/bundle.0.pem.", testDir),
 			),
-			expectedStdoutJSON: fmt.Sprintf(`{
-  "crl": [],
-  "federated_bundles": {},
-  "svids": [
-    {
-      "bundle": "%s",
-      "hint": "",
-      "spiffe_id": "spiffe://example.org/foo",
-      "x509_svid": "%s",
-      "x509_svid_key": "%s"
-    }
-  ]
-}`,
-				base64.StdEncoding.EncodeToString(x509util.DERFromCertificates(ca.Bundle().X509Authorities())),
-				base64.StdEncoding.EncodeToString(x509util.DERFromCertificates(svid.Certificates)),
-				base64.StdEncoding.EncodeToString(pkcs8FromSigner(t, svid.PrivateKey)),
-			),
+			// With json output, the document is written to a file instead
 			expectedFileResult: true,
 		},
+		{
+			name:                 "success fetching x509 svid by hint",
+			args:                 []string{"-hint", "external"},
+			fakeRequests:         []*fakeworkloadapi.FakeRequest{twoSVIDsRequest},
+			expectedStdoutPretty: "Received 1 svid after",
+			expectedStdoutJSON: fmt.Sprintf(`[
+  {
+    "spiffe_id": "spiffe://example.org/foo",
+    "hint": "external",
+    "x509_svid": %q,
+    "x509_svid_key": %q,
+    "bundle": %q,
+    "expires_at": %q
+  }
+]`,
+				pemFromCertificates(svid.Certificates),
+				pemFromPKCS8(t, svid.PrivateKey),
+				pemFromCertificates(ca.Bundle().X509Authorities()),
+				svid.Certificates[0].NotAfter.UTC().Format(time.RFC3339),
+			),
+		},
+		{
+			name:           "fails when no svid matches the hint",
+			args:           []string{"-hint", "unknown"},
+			fakeRequests:   []*fakeworkloadapi.FakeRequest{twoSVIDsRequest},
+			expectedStderr: "no SVID found with hint \"unknown\"; available hints: \"internal\", \"external\"\n",
+		},
+		{
+			name:                 "success fetching x509 svid by spiffe id",
+			args:                 []string{"-spiffeID", "spiffe://example.org/foo"},
+			fakeRequests:         []*fakeworkloadapi.FakeRequest{twoSVIDsRequest},
+			expectedStdoutPretty: "Received 1 svid after",
+			expectedStdoutJSON: fmt.Sprintf(`[
+  {
+    "spiffe_id": "spiffe://example.org/foo",
+    "hint": "external",
+    "x509_svid": %q,
+    "x509_svid_key": %q,
+    "bundle": %q,
+    "expires_at": %q
+  }
+]`,
+				pemFromCertificates(svid.Certificates),
+				pemFromPKCS8(t, svid.PrivateKey),
+				pemFromCertificates(ca.Bundle().X509Authorities()),
+				svid.Certificates[0].NotAfter.UTC().Format(time.RFC3339),
+			),
+		},
+		{
+			name:           "fails when the workload is not authorized for the spiffe id",
+			args:           []string{"-spiffeID", "spiffe://example.org/baz"},
+			fakeRequests:   []*fakeworkloadapi.FakeRequest{twoSVIDsRequest},
+			expectedStderr: "workload is not authorized for SPIFFE ID \"spiffe://example.org/baz\"; authorized SPIFFE IDs: \"spiffe://example.org/bar\", \"spiffe://example.org/foo\"\n",
+		},
+		{
+			name:           "fails when the svid for the spiffe id does not match the hint",
+			args:           []string{"-spiffeID", "spiffe://example.org/foo", "-hint", "internal"},
+			fakeRequests:   []*fakeworkloadapi.FakeRequest{twoSVIDsRequest},
+			expectedStderr: "no SVID found with hint \"internal\"; available hints: \"external\"\n",
+		},
 		{
 			name: "fails fetching svid",
 			fakeRequests: []*fakeworkloadapi.FakeRequest{
@@ -378,6 +906,327 @@ Writing bundle #0 to file %s
 	}
 }
 
+func TestFetchX509CommandJSON(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	fooSVID := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+	barSVID := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/bar"))
+	federatedCA := testca.New(t, spiffeid.RequireTrustDomainFromString("domain.test"))
+
+	fakeRequest := &fakeworkloadapi.FakeRequest{
+		Req: &workload.X509SVIDRequest{},
+		Resp: &workload.X509SVIDResponse{
+			Svids: []*workload.X509SVID{
+				{
+					SpiffeId:    fooSVID.ID.String(),
+					X509Svid:    x509util.DERFromCertificates(fooSVID.Certificates),
+					X509SvidKey: pkcs8FromSigner(t, fooSVID.PrivateKey),
+					Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+					Hint:        "internal",
+				},
+				{
+					SpiffeId:    barSVID.ID.String(),
+					X509Svid:    x509util.DERFromCertificates(barSVID.Certificates),
+					X509SvidKey: pkcs8FromSigner(t, barSVID.PrivateKey),
+					Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+				},
+			},
+			FederatedBundles: map[string][]byte{
+				"spiffe://domain.test": x509util.DERFromCertificates(federatedCA.Bundle().X509Authorities()),
+			},
+		},
+	}
+
+	// The golden file holds placeholders for the values that change on
+	// every run, since certificates and keys are generated by the test.
+	golden, err := os.ReadFile(filepath.Join("testdata", "fetch_x509.json.golden"))
+	require.NoError(t, err)
+	quote := func(s string) string {
+		b, err := json.Marshal(s)
+		require.NoError(t, err)
+		return string(b)
+	}
+	expected := strings.NewReplacer(
+		`"$FOO_SVID"`, quote(string(pemFromCertificates(fooSVID.Certificates))),
+		`"$FOO_SVID_KEY"`, quote(pemFromPKCS8(t, fooSVID.PrivateKey)),
+		`"$FOO_EXPIRES_AT"`, quote(fooSVID.Certificates[0].NotAfter.UTC().Format(time.RFC3339)),
+		`"$BAR_SVID"`, quote(string(pemFromCertificates(barSVID.Certificates))),
+		`"$BAR_SVID_KEY"`, quote(pemFromPKCS8(t, barSVID.PrivateKey)),
+		`"$BAR_EXPIRES_AT"`, quote(barSVID.Certificates[0].NotAfter.UTC().Format(time.RFC3339)),
+		`"$BUNDLE"`, quote(string(pemFromCertificates(ca.Bundle().X509Authorities()))),
+		`"$FEDERATED_BUNDLE"`, quote(string(pemFromCertificates(federatedCA.Bundle().X509Authorities()))),
+	).Replace(string(golden))
+
+	t.Run("stdout", func(t *testing.T) {
+		test := setupTest(t, newFetchX509Command, fakeRequest)
+
+		rc := test.cmd.Run(test.args("-output", "json"))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.JSONEq(t, expected, test.stdout.String())
+	})
+
+	t.Run("write", func(t *testing.T) {
+		test := setupTest(t, newFetchX509Command, fakeRequest)
+		testDir := t.TempDir()
+
+		rc := test.cmd.Run(test.args("-output", "json", "-write", testDir))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Empty(t, test.stdout.String())
+
+		content, err := os.ReadFile(filepath.Join(testDir, "svids.json"))
+		require.NoError(t, err)
+		require.JSONEq(t, expected, string(content))
+
+		// Certificates and keys are not split into separate files
+		_, err = os.Stat(filepath.Join(testDir, "svid.0.pem"))
+		require.True(t, errors.Is(err, os.ErrNotExist))
+	})
+}
+
+func TestFetchX509CommandPEMBundle(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	intermediateCA := ca.ChildCA()
+	svid := intermediateCA.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+	require.Len(t, svid.Certificates, 2)
+
+	fakeRequest := &fakeworkloadapi.FakeRequest{
+		Req: &workload.X509SVIDRequest{},
+		Resp: &workload.X509SVIDResponse{
+			Svids: []*workload.X509SVID{
+				{
+					SpiffeId:    svid.ID.String(),
+					X509Svid:    x509util.DERFromCertificates(svid.Certificates),
+					X509SvidKey: pkcs8FromSigner(t, svid.PrivateKey),
+					Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+				},
+			},
+		},
+	}
+
+	t.Run("write", func(t *testing.T) {
+		test := setupTest(t, newFetchX509Command, fakeRequest)
+		testDir := t.TempDir()
+		combinedPath := filepath.Join(testDir, "svid.0.combined.pem")
+
+		rc := test.cmd.Run(test.args("-format", "pem-bundle", "-write", testDir))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Contains(t, test.stdout.String(), fmt.Sprintf("Writing SVID #0 and its key to file %s.\n", combinedPath))
+
+		info, err := os.Stat(combinedPath)
+		require.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+		}
+
+		// The file holds the leaf, then the intermediates, then the key
+		content, err := os.ReadFile(combinedPath)
+		require.NoError(t, err)
+		var blocks []*pem.Block
+		for rest := content; ; {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				require.Empty(t, rest)
+				break
+			}
+			blocks = append(blocks, block)
+		}
+		require.Len(t, blocks, 3)
+		require.Equal(t, "CERTIFICATE", blocks[0].Type)
+		require.Equal(t, svid.Certificates[0].Raw, blocks[0].Bytes)
+		require.Equal(t, "CERTIFICATE", blocks[1].Type)
+		require.Equal(t, svid.Certificates[1].Raw, blocks[1].Bytes)
+		require.Equal(t, "PRIVATE KEY", blocks[2].Type)
+		require.Equal(t, pkcs8FromSigner(t, svid.PrivateKey), blocks[2].Bytes)
+
+		// The certificates and the key are not written to separate files
+		for _, name := range []string{"svid.0.pem", "svid.0.key"} {
+			_, err = os.Stat(filepath.Join(testDir, name))
+			require.True(t, errors.Is(err, os.ErrNotExist), name)
+		}
+		bundle, err := os.ReadFile(filepath.Join(testDir, "bundle.0.pem"))
+		require.NoError(t, err)
+		require.Equal(t, string(pemFromCertificates(ca.Bundle().X509Authorities())), string(bundle))
+	})
+
+	for _, tt := range []struct {
+		name   string
+		args   []string
+		expErr string
+	}{
+		{
+			name:   "write is required",
+			args:   []string{"-format", "pem-bundle"},
+			expErr: "the pem-bundle format requires -write\n",
+		},
+		{
+			name:   "json output",
+			args:   []string{"-format", "pem-bundle", "-output", "json", "-write", "."},
+			expErr: "the pem-bundle format cannot be used with json output\n",
+		},
+		{
+			name:   "unknown format",
+			args:   []string{"-format", "pkcs12"},
+			expErr: "unknown format \"pkcs12\"; expected separate or pem-bundle\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newFetchX509Command, fakeRequest)
+
+			rc := test.cmd.Run(test.args(tt.args...))
+			require.Equal(t, 1, rc)
+			require.Equal(t, tt.expErr, test.stderr.String())
+		})
+	}
+}
+
+func TestFetchX509CommandRetry(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	svid := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+
+	newRequest := func(err error, errCount int) *fakeworkloadapi.FakeRequest {
+		return &fakeworkloadapi.FakeRequest{
+			Req: &workload.X509SVIDRequest{},
+			Resp: &workload.X509SVIDResponse{
+				Svids: []*workload.X509SVID{
+					{
+						SpiffeId:    svid.ID.String(),
+						X509Svid:    x509util.DERFromCertificates(svid.Certificates),
+						X509SvidKey: pkcs8FromSigner(t, svid.PrivateKey),
+						Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+					},
+				},
+			},
+			Err:      err,
+			ErrCount: errCount,
+		}
+	}
+	unavailable := status.Error(codes.Unavailable, "agent is not ready")
+
+	for _, tt := range []struct {
+		name           string
+		request        *fakeworkloadapi.FakeRequest
+		args           []string
+		expectedCalls  int
+		expectedStderr string
+	}{
+		{
+			name:           "no retries by default",
+			request:        newRequest(unavailable, 1),
+			expectedCalls:  1,
+			expectedStderr: "rpc error: code = Unavailable desc = agent is not ready\n",
+		},
+		{
+			name:          "succeeds once the workload API is available",
+			request:       newRequest(unavailable, 2),
+			args:          []string{"-retry", "3"},
+			expectedCalls: 3,
+		},
+		{
+			name:           "gives up after exhausting retries",
+			request:        newRequest(unavailable, 5),
+			args:           []string{"-retry", "2"},
+			expectedCalls:  3,
+			expectedStderr: "rpc error: code = Unavailable desc = agent is not ready\n",
+		},
+		{
+			name:           "does not retry other errors",
+			request:        newRequest(status.Error(codes.PermissionDenied, "no identity issued"), 1),
+			args:           []string{"-retry", "3"},
+			expectedCalls:  1,
+			expectedStderr: "rpc error: code = PermissionDenied desc = no identity issued\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newFetchX509Command, tt.request)
+
+			args := append([]string{"-silent", "-retryInterval", "10ms"}, tt.args...)
+			rc := test.cmd.Run(test.args(args...))
+
+			assert.Equal(t, tt.expectedCalls, test.workloadAPI.FetchX509SVIDCalls())
+			if tt.expectedStderr != "" {
+				assert.Equal(t, 1, rc)
+				assert.Equal(t, tt.expectedStderr, test.stderr.String())
+				return
+			}
+			assert.Equal(t, 0, rc, test.stderr.String())
+			assert.Empty(t, test.stderr.String())
+		})
+	}
+}
+
+func TestFetchX509CommandCache(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	svid := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+	resp := &workload.X509SVIDResponse{
+		Svids: []*workload.X509SVID{
+			{
+				SpiffeId:    svid.ID.String(),
+				X509Svid:    x509util.DERFromCertificates(svid.Certificates),
+				X509SvidKey: pkcs8FromSigner(t, svid.PrivateKey),
+				Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+			},
+		},
+	}
+
+	cacheDir := t.TempDir()
+	clk := clock.NewMock(t)
+	fetch := func(err error) *apiTest {
+		test := setupTest(t, func(env *commoncli.Env, clientMaker workloadClientMaker) cli.Command {
+			return adaptCommand(env, clientMaker, &fetchX509Command{env: env, clk: clk})
+		}, &fakeworkloadapi.FakeRequest{
+			Req:  &workload.X509SVIDRequest{},
+			Resp: resp,
+			Err:  err,
+		})
+		test.cmd.Run(test.args("-cacheDir", cacheDir, "-output", "json"))
+		return test
+	}
+	unavailable := status.Error(codes.Unavailable, "agent is not ready")
+
+	t.Run("no cache yet", func(t *testing.T) {
+		test := fetch(unavailable)
+		require.Equal(t, "rpc error: code = Unavailable desc = agent is not ready; cached SVIDs cannot be used: "+
+			"open "+filepath.Join(cacheDir, x509SVIDCacheFile)+": no such file or directory\n", test.stderr.String())
+		require.Empty(t, test.stdout.String())
+	})
+
+	t.Run("fresh fetch writes the cache", func(t *testing.T) {
+		test := fetch(nil)
+		require.Empty(t, test.stderr.String())
+		require.Contains(t, test.stdout.String(), `"spiffe_id":"spiffe://example.org/foo"`)
+
+		info, err := os.Stat(filepath.Join(cacheDir, x509SVIDCacheFile))
+		require.NoError(t, err)
+		if runtime.GOOS != "windows" {
+			require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+	})
+
+	t.Run("failure serves the cache", func(t *testing.T) {
+		test := fetch(unavailable)
+		require.Equal(t, "Workload API is unavailable (rpc error: code = Unavailable desc = agent is not ready); using cached SVIDs from "+cacheDir+"\n", test.stderr.String())
+		require.Contains(t, test.stdout.String(), `"spiffe_id":"spiffe://example.org/foo"`)
+	})
+
+	t.Run("other errors are not served from the cache", func(t *testing.T) {
+		test := fetch(status.Error(codes.PermissionDenied, "no identity issued"))
+		require.Equal(t, "rpc error: code = PermissionDenied desc = no identity issued\n", test.stderr.String())
+		require.Empty(t, test.stdout.String())
+	})
+
+	t.Run("expired cache is refused", func(t *testing.T) {
+		clk.Add(2 * time.Hour)
+		test := fetch(unavailable)
+		require.Equal(t, "rpc error: code = Unavailable desc = agent is not ready; cached SVIDs cannot be used: "+
+			"cached SVID \"spiffe://example.org/foo\" expired at "+svid.Certificates[0].NotAfter.UTC().Format(time.RFC3339)+"\n", test.stderr.String())
+		require.Empty(t, test.stdout.String())
+	})
+}
+
 func TestValidateJWTCommandHelp(t *testing.T) {
 	test := setupTest(t, newValidateJWTCommand)
 	test.cmd.Help()
@@ -487,6 +1336,75 @@ Claims    : {"aud":["foo"]}`,
 	}
 }
 
+func TestValidateJWTCommandCache(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	otherCA := testca.New(t, td)
+	encodedSvid := ca.CreateJWTSVID(spiffeid.RequireFromString("spiffe://example.org/foo"), []string{"foo"}).Marshal()
+	unknownKeySvid := otherCA.CreateJWTSVID(spiffeid.RequireFromString("spiffe://example.org/foo"), []string{"foo"}).Marshal()
+
+	jwks, err := ca.JWTBundle().Marshal()
+	require.NoError(t, err)
+
+	clk := clock.NewMock(t)
+	fetches := 0
+	test := setupTest(t, func(env *commoncli.Env, clientMaker workloadClientMaker) cli.Command {
+		countingClientMaker := func(ctx context.Context, addr net.Addr, timeout time.Duration) (*workloadClient, error) {
+			client, err := clientMaker(ctx, addr, timeout)
+			if err != nil {
+				return nil, err
+			}
+			client.SpiffeWorkloadAPIClient = countingWorkloadClient{
+				SpiffeWorkloadAPIClient: client.SpiffeWorkloadAPIClient,
+				fetchJWTBundles:         &fetches,
+			}
+			return client, nil
+		}
+		return adaptCommand(env, countingClientMaker, &validateJWTCommand{env: env, clk: clk})
+	}, &fakeworkloadapi.FakeRequest{
+		Req: &workload.JWTBundlesRequest{},
+		Resp: &workload.JWTBundlesResponse{
+			Bundles: map[string][]byte{td.Name(): jwks},
+		},
+	})
+
+	validate := func(svid string) int {
+		test.stdout.Reset()
+		test.stderr.Reset()
+		return test.cmd.Run(test.args("-audience", "foo", "-svid", svid, "-cacheTTL", "1m"))
+	}
+
+	// The bundles are fetched on the first validation
+	require.Equal(t, 0, validate(encodedSvid))
+	require.Contains(t, test.stdout.String(), "SVID is valid.\nSPIFFE ID : spiffe://example.org/foo\n")
+	require.Equal(t, 1, fetches)
+
+	// A validation within the TTL uses the cached bundles
+	clk.Add(30 * time.Second)
+	require.Equal(t, 0, validate(encodedSvid))
+	require.Equal(t, 1, fetches)
+
+	// An unknown key forces a refresh before the SVID is rejected
+	require.Equal(t, 1, validate(unknownKeySvid))
+	require.Contains(t, test.stderr.String(), "SVID is not valid: jwtsvid: no JWT authority")
+	require.Equal(t, 2, fetches)
+
+	// The bundles are fetched again once the TTL elapses
+	clk.Add(time.Minute)
+	require.Equal(t, 0, validate(encodedSvid))
+	require.Equal(t, 3, fetches)
+}
+
+type countingWorkloadClient struct {
+	workload.SpiffeWorkloadAPIClient
+	fetchJWTBundles *int
+}
+
+func (c countingWorkloadClient) FetchJWTBundles(ctx context.Context, in *workload.JWTBundlesRequest, opts ...grpc.CallOption) (workload.SpiffeWorkloadAPI_FetchJWTBundlesClient, error) {
+	*c.fetchJWTBundles++
+	return c.SpiffeWorkloadAPIClient.FetchJWTBundles(ctx, in, opts...)
+}
+
 func setupTest(t *testing.T, newCmd func(env *commoncli.Env, clientMaker workloadClientMaker) cli.Command, requests ...*fakeworkloadapi.FakeRequest) *apiTest {
 	workloadAPIServer := fakeworkloadapi.New(t, requests...)
 
@@ -542,6 +1460,13 @@ func (s *apiTest) args(extra ...string) []string {
 	return append([]string{clitest.AddrArg, s.addr}, extra...)
 }
 
+func pemFromPKCS8(t *testing.T, key crypto.Signer) string {
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "PRIVATE KEY",
+		Bytes: pkcs8FromSigner(t, key),
+	}))
+}
+
 func assertOutputBasedOnFormat(t *testing.T, format, stdoutString, expectedStdoutJSON string, expectedStdoutPretty ...string) {
 	switch format {
 	case "pretty":
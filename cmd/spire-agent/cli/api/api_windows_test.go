@@ -3,38 +3,62 @@
 package api
 
 const (
+	fetchBundleUsage = `Usage of fetch bundle:
+` + addrUsage + `  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -timeout value
+    	Time to wait for a response (default 5s)
+  -trustDomain string
+    	Only fetch the bundle of this trust domain (optional)
+  -write string
+    	Write bundles to the specified path, one PEM file per trust domain (optional; with json output format, a single bundles.json file is written)
+`
 	fetchJWTUsage = `Usage of fetch jwt:
   -audience value
-    	comma separated list of audience values
+    	comma separated list of audience values; can be repeated to fetch a JWT-SVID per list of audience values
+  -bundle
+    	Also write the JWT bundle of each trust domain to <path>.<trust domain>.jwks; requires -write (optional)
   -format value
     	deprecated; use -output
-  -namedPipeName string
-    	Pipe name of the SPIRE Agent API named pipe (default "\\spire-agent\\public\\api")
-  -output value
+  -hint string
+    	Only fetch the SVID with this hint (optional)
+` + addrUsage + `  -output value
     	Desired output format (pretty, json); default: pretty.
   -spiffeID string
     	SPIFFE ID subject (optional)
   -timeout value
     	Time to wait for a response (default 5s)
+  -write string
+    	Write the JWT-SVID to the specified file instead of stdout; if several SVIDs are returned, they are written to numbered files (<path>.0, <path>.1, ...) (optional)
 `
 	fetchX509Usage = `Usage of fetch x509:
-  -namedPipeName string
-    	Pipe name of the SPIRE Agent API named pipe (default "\\spire-agent\\public\\api")
-  -output value
+  -cacheDir string
+    	Directory the fetched SVIDs are cached in. When the Workload API is unavailable, the cached SVIDs are used instead as long as none has expired (optional)
+  -format string
+    	Layout of the files written with -write: separate, or pem-bundle to write the SVID certificate, its intermediates and its key to a single svid.<n>.combined.pem file, readable only by its owner (default "separate")
+  -hint string
+    	Only fetch the SVID with this hint (optional)
+` + addrUsage + `  -output value
     	Desired output format (pretty, json); default: pretty.
+  -retry int
+    	Number of times to retry while the Workload API is unavailable (optional)
+  -retryInterval duration
+    	Time to wait between retries (default 1s)
   -silent
     	Suppress stdout
+  -spiffeID string
+    	Only fetch the SVID for this SPIFFE ID (optional)
   -timeout value
     	Time to wait for a response (default 5s)
   -write string
-    	Write SVID data to the specified path (optional; only available for pretty output format)
+    	Write SVID data to the specified path (optional; with json output format, a single svids.json file is written)
 `
 	validateJWTUsage = `Usage of validate jwt:
   -audience string
     	expected audience value
-  -namedPipeName string
-    	Pipe name of the SPIRE Agent API named pipe (default "\\spire-agent\\public\\api")
-  -output value
+  -cacheTTL duration
+    	Validate the SVID locally with the JWT bundles fetched from the Workload API, caching them for this amount of time; 0 validates through the Workload API instead (optional)
+` + addrUsage + `  -output value
     	Desired output format (pretty, json); default: pretty.
   -svid string
     	JWT SVID
@@ -42,3 +66,7 @@ const (
     	Time to wait for a response (default 5s)
 `
 )
+
+const addrUsage = `  -namedPipeName string
+    	Pipe name of the SPIRE Agent API named pipe (default "\\spire-agent\\public\\api")
+`
@@ -3,7 +3,9 @@ package api
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
@@ -47,6 +49,21 @@ func (c *workloadClient) prepareContext(ctx context.Context) (context.Context, f
 	return ctx, func() {}
 }
 
+// newHintNotFoundError returns an error reporting that none of the fetched
+// SVIDs has the requested hint, listing the hints that were received.
+func newHintNotFoundError(hint string, available []string) error {
+	var quoted []string
+	for _, h := range available {
+		if h != "" {
+			quoted = append(quoted, fmt.Sprintf("%q", h))
+		}
+	}
+	if len(quoted) == 0 {
+		return fmt.Errorf("no SVID found with hint %q; fetched SVIDs have no hints", hint)
+	}
+	return fmt.Errorf("no SVID found with hint %q; available hints: %s", hint, strings.Join(quoted, ", "))
+}
+
 // command is a common interface for commands in this package. the adapter
 // can adapter this interface to the Command interface from github.com/mitchellh/cli.
 type command interface {
@@ -0,0 +1,214 @@
+package api
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+)
+
+func NewFetchBundleCommand() cli.Command {
+	return newFetchBundleCommand(commoncli.DefaultEnv, newWorkloadClient)
+}
+
+func newFetchBundleCommand(env *commoncli.Env, clientMaker workloadClientMaker) cli.Command {
+	return adaptCommand(env, clientMaker, &fetchBundleCommand{env: env})
+}
+
+type fetchBundleCommand struct {
+	trustDomain string
+	writePath   string
+	env         *commoncli.Env
+	printer     cliprinter.Printer
+	output      *cliprinter.FormatterFlag
+	respTime    time.Duration
+}
+
+func (*fetchBundleCommand) name() string {
+	return "fetch bundle"
+}
+
+func (*fetchBundleCommand) synopsis() string {
+	return "Fetches X.509 bundles from the Workload API"
+}
+
+func (c *fetchBundleCommand) run(ctx context.Context, _ *commoncli.Env, client *workloadClient) error {
+	start := time.Now()
+	resp, err := c.fetchX509Bundles(ctx, client)
+	c.respTime = time.Since(start)
+	if err != nil {
+		return err
+	}
+
+	bundles, err := parseX509BundlesResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	if c.trustDomain != "" {
+		bundles, err = filterX509BundlesByTrustDomain(bundles, c.trustDomain)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.output.String() == "json" {
+		return c.printJSON(bundles)
+	}
+
+	return c.printer.PrintStruct(bundles)
+}
+
+func (c *fetchBundleCommand) appendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.trustDomain, "trustDomain", "", "Only fetch the bundle of this trust domain (optional)")
+	fs.StringVar(&c.writePath, "write", "", "Write bundles to the specified path, one PEM file per trust domain (optional; with json output format, a single bundles.json file is written)")
+	c.output = cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintFetchBundle)
+}
+
+// x509Bundle is the set of X.509 authorities of a trust domain.
+type x509Bundle struct {
+	TrustDomain  string
+	Certificates []*x509.Certificate
+}
+
+// x509BundleDocument is the JSON representation of an X.509 bundle, with
+// its certificates PEM encoded.
+type x509BundleDocument struct {
+	TrustDomain string `json:"trust_domain"`
+	Bundle      string `json:"bundle"`
+}
+
+func (c *fetchBundleCommand) fetchX509Bundles(ctx context.Context, client *workloadClient) (*workload.X509BundlesResponse, error) {
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+
+	stream, err := client.FetchX509Bundles(ctx, &workload.X509BundlesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return stream.Recv()
+}
+
+// printJSON prints the bundles as a JSON array of documents, or writes it to
+// a single file if a write path was given.
+func (c *fetchBundleCommand) printJSON(bundles []*x509Bundle) error {
+	docs := make([]any, 0, len(bundles))
+	for _, bundle := range bundles {
+		docs = append(docs, &x509BundleDocument{
+			TrustDomain: bundle.TrustDomain,
+			Bundle:      string(pemFromCertificates(bundle.Certificates)),
+		})
+	}
+
+	if c.writePath != "" {
+		data, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			return err
+		}
+		return diskutil.WritePubliclyReadableFile(path.Join(c.writePath, "bundles.json"), data)
+	}
+
+	return c.printer.PrintStruct(docs...)
+}
+
+func (c *fetchBundleCommand) prettyPrintFetchBundle(env *commoncli.Env, results ...any) error {
+	structs, ok := results[0].([]any)
+	if !ok || len(structs) != 1 {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+	bundles, ok := structs[0].([]*x509Bundle)
+	if !ok {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+
+	if c.writePath != "" {
+		return c.writeBundles(bundles)
+	}
+
+	lenMsg := fmt.Sprintf("Received %d bundle", len(bundles))
+	if len(bundles) != 1 {
+		lenMsg += "s"
+	}
+	env.Printf("%s after %s\n", lenMsg, c.respTime)
+	for _, bundle := range bundles {
+		env.Println()
+		env.Printf("Trust domain: %s\n", bundle.TrustDomain)
+		env.Printf("%s", pemFromCertificates(bundle.Certificates))
+	}
+
+	return nil
+}
+
+// writeBundles writes the bundle of each trust domain to its own PEM file,
+// named after the trust domain
+func (c *fetchBundleCommand) writeBundles(bundles []*x509Bundle) error {
+	for _, bundle := range bundles {
+		bundlePath := path.Join(c.writePath, bundle.TrustDomain+".pem")
+		c.env.Printf("Writing bundle for trust domain %s to file %s.\n", bundle.TrustDomain, bundlePath)
+		if err := diskutil.WritePubliclyReadableFile(bundlePath, pemFromCertificates(bundle.Certificates)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseX509BundlesResponse parses the bundles in the response, sorted by
+// trust domain so the output is consistent
+func parseX509BundlesResponse(resp *workload.X509BundlesResponse) ([]*x509Bundle, error) {
+	if len(resp.Bundles) == 0 {
+		return nil, errors.New("workload response contains no bundles")
+	}
+
+	bundles := make([]*x509Bundle, 0, len(resp.Bundles))
+	for trustDomainID, bundleDER := range resp.Bundles {
+		td, err := spiffeid.TrustDomainFromString(trustDomainID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust domain %q in workload response: %w", trustDomainID, err)
+		}
+		certificates, err := x509.ParseCertificates(bundleDER)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse bundle for trust domain %q: %w", td, err)
+		}
+		if len(certificates) == 0 {
+			return nil, fmt.Errorf("no certificates in bundle for trust domain %q", td)
+		}
+		bundles = append(bundles, &x509Bundle{
+			TrustDomain:  td.Name(),
+			Certificates: certificates,
+		})
+	}
+
+	sort.Slice(bundles, func(i, j int) bool {
+		return bundles[i].TrustDomain < bundles[j].TrustDomain
+	})
+	return bundles, nil
+}
+
+// filterX509BundlesByTrustDomain keeps only the bundle of the given trust
+// domain, which may be given either as a name or as a SPIFFE ID.
+func filterX509BundlesByTrustDomain(bundles []*x509Bundle, trustDomain string) ([]*x509Bundle, error) {
+	td, err := spiffeid.TrustDomainFromString(trustDomain)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trust domain %q: %w", trustDomain, err)
+	}
+
+	for _, bundle := range bundles {
+		if bundle.TrustDomain == td.Name() {
+			return []*x509Bundle{bundle}, nil
+		}
+	}
+	return nil, fmt.Errorf("no bundle found for trust domain %q", td)
+}
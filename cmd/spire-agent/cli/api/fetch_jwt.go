@@ -5,11 +5,16 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+	"google.golang.org/protobuf/proto"
 )
 
 func NewFetchJWTCommand() cli.Command {
@@ -21,10 +26,14 @@ func newFetchJWTCommandWithEnv(env *commoncli.Env, clientMaker workloadClientMak
 }
 
 type fetchJWTCommand struct {
-	audience commoncli.CommaStringsFlag
-	spiffeID string
-	printer  cliprinter.Printer
-	env      *commoncli.Env
+	audiences   audiencesFlag
+	spiffeID    string
+	hint        string
+	writePath   string
+	writeBundle bool
+	printer     cliprinter.Printer
+	output      *cliprinter.FormatterFlag
+	env         *commoncli.Env
 }
 
 func (c *fetchJWTCommand) name() string {
@@ -36,34 +45,59 @@ func (c *fetchJWTCommand) synopsis() string {
 }
 
 func (c *fetchJWTCommand) run(ctx context.Context, _ *commoncli.Env, client *workloadClient) error {
-	if len(c.audience) == 0 {
+	if len(c.audiences) == 0 {
 		return errors.New("audience must be specified")
 	}
+	if c.writeBundle && c.writePath == "" {
+		return errors.New("-bundle requires -write")
+	}
 
 	bundlesResp, err := c.fetchJWTBundles(ctx, client)
 	if err != nil {
 		return err
 	}
-	svidResp, err := c.fetchJWTSVID(ctx, client)
-	if err != nil {
-		return err
+
+	// A JWT-SVID is fetched per -audience flag, in the same order
+	svidResps := make([]*workload.JWTSVIDResponse, 0, len(c.audiences))
+	for _, audience := range c.audiences {
+		svidResp, err := c.fetchJWTSVID(ctx, client, audience)
+		if err != nil {
+			return err
+		}
+		if c.hint != "" {
+			if err := filterJWTSVIDsByHint(svidResp, c.hint); err != nil {
+				return err
+			}
+		}
+		svidResps = append(svidResps, svidResp)
 	}
 
-	return c.printer.PrintProto(svidResp, bundlesResp)
+	if c.writePath != "" {
+		return c.writeResponse(svidResps, bundlesResp)
+	}
+
+	resps := make([]proto.Message, 0, len(svidResps)+1)
+	for _, svidResp := range svidResps {
+		resps = append(resps, svidResp)
+	}
+	return c.printer.PrintProto(append(resps, bundlesResp)...)
 }
 
 func (c *fetchJWTCommand) appendFlags(fs *flag.FlagSet) {
-	fs.Var(&c.audience, "audience", "comma separated list of audience values")
+	fs.Var(&c.audiences, "audience", "comma separated list of audience values; can be repeated to fetch a JWT-SVID per list of audience values")
 	fs.StringVar(&c.spiffeID, "spiffeID", "", "SPIFFE ID subject (optional)")
-	outputValue := cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, printPrettyResult)
-	fs.Var(outputValue, "format", "deprecated; use -output")
+	fs.StringVar(&c.hint, "hint", "", "Only fetch the SVID with this hint (optional)")
+	fs.StringVar(&c.writePath, "write", "", "Write the JWT-SVID to the specified file instead of stdout; if several SVIDs are returned, they are written to numbered files (<path>.0, <path>.1, ...) (optional)")
+	fs.BoolVar(&c.writeBundle, "bundle", false, "Also write the JWT bundle of each trust domain to <path>.<trust domain>.jwks; requires -write (optional)")
+	c.output = cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.printPrettyResult)
+	fs.Var(c.output, "format", "deprecated; use -output")
 }
 
-func (c *fetchJWTCommand) fetchJWTSVID(ctx context.Context, client *workloadClient) (*workload.JWTSVIDResponse, error) {
+func (c *fetchJWTCommand) fetchJWTSVID(ctx context.Context, client *workloadClient, audience []string) (*workload.JWTSVIDResponse, error) {
 	ctx, cancel := client.prepareContext(ctx)
 	defer cancel()
 	return client.FetchJWTSVID(ctx, &workload.JWTSVIDRequest{
-		Audience: c.audience,
+		Audience: audience,
 		SpiffeId: c.spiffeID,
 	})
 }
@@ -78,23 +112,103 @@ func (c *fetchJWTCommand) fetchJWTBundles(ctx context.Context, client *workloadC
 	return stream.Recv()
 }
 
-func printPrettyResult(env *commoncli.Env, results ...any) error {
-	svidResp, ok := results[0].(*workload.JWTSVIDResponse)
-	if !ok {
+// filterJWTSVIDsByHint keeps only the SVID in the response whose hint
+// matches exactly.
+func filterJWTSVIDsByHint(resp *workload.JWTSVIDResponse, hint string) error {
+	var hints []string
+	for _, svid := range resp.Svids {
+		if svid.Hint == hint {
+			resp.Svids = []*workload.JWTSVID{svid}
+			return nil
+		}
+		hints = append(hints, svid.Hint)
+	}
+	return newHintNotFoundError(hint, hints)
+}
+
+// writeResponse writes the tokens, which are credentials, to files only
+// readable by the current user, and the bundles alongside them if requested.
+// The SVIDs fetched for the different audiences are numbered as a whole.
+func (c *fetchJWTCommand) writeResponse(svidResps []*workload.JWTSVIDResponse, bundlesResp *workload.JWTBundlesResponse) error {
+	// Progress is not reported with the json output format, since nothing is
+	// printed to stdout.
+	verbose := c.output.String() != "json"
+
+	var svids []*workload.JWTSVID
+	for _, svidResp := range svidResps {
+		svids = append(svids, svidResp.Svids...)
+	}
+	for i, svid := range svids {
+		svidPath := c.writePath
+		if len(svids) > 1 {
+			svidPath = fmt.Sprintf("%s.%d", c.writePath, i)
+		}
+		if verbose {
+			c.env.Printf("Writing JWT-SVID for %s to file %s.\n", svid.SpiffeId, svidPath)
+		}
+		if err := diskutil.WritePrivateFile(svidPath, []byte(svid.Svid)); err != nil {
+			return err
+		}
+	}
+
+	if !c.writeBundle {
+		return nil
+	}
+
+	// sort and write the bundles by trust domain so the output is consistent
+	trustDomainIDs := make([]string, 0, len(bundlesResp.Bundles))
+	for trustDomainID := range bundlesResp.Bundles {
+		trustDomainIDs = append(trustDomainIDs, trustDomainID)
+	}
+	sort.Strings(trustDomainIDs)
+
+	for _, trustDomainID := range trustDomainIDs {
+		td, err := spiffeid.TrustDomainFromString(trustDomainID)
+		if err != nil {
+			return fmt.Errorf("invalid trust domain %q in workload response: %w", trustDomainID, err)
+		}
+		bundlePath := fmt.Sprintf("%s.%s.jwks", c.writePath, td.Name())
+		if verbose {
+			c.env.Printf("Writing bundle for trust domain %s to file %s.\n", td.Name(), bundlePath)
+		}
+		if err := diskutil.WritePubliclyReadableFile(bundlePath, bundlesResp.Bundles[trustDomainID]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// printPrettyResult prints the JWT-SVIDs fetched for each audience, followed
+// by the bundles. The SVIDs are grouped by audience when several audiences
+// were requested.
+func (c *fetchJWTCommand) printPrettyResult(env *commoncli.Env, results ...any) error {
+	if len(results) != len(c.audiences)+1 {
 		env.Println(cliprinter.ErrInternalCustomPrettyFunc.Error())
 		return cliprinter.ErrInternalCustomPrettyFunc
 	}
 
-	bundlesResp, ok := results[1].(*workload.JWTBundlesResponse)
+	bundlesResp, ok := results[len(results)-1].(*workload.JWTBundlesResponse)
 	if !ok {
 		env.Println(cliprinter.ErrInternalCustomPrettyFunc.Error())
 		return cliprinter.ErrInternalCustomPrettyFunc
 	}
 
-	for _, svid := range svidResp.Svids {
-		env.Printf("token(%s):\n\t%s\n", svid.SpiffeId, svid.Svid)
-		if svid.Hint != "" {
-			env.Printf("hint(%s):\n\t%s\n", svid.SpiffeId, svid.Hint)
+	for i, result := range results[:len(results)-1] {
+		svidResp, ok := result.(*workload.JWTSVIDResponse)
+		if !ok {
+			env.Println(cliprinter.ErrInternalCustomPrettyFunc.Error())
+			return cliprinter.ErrInternalCustomPrettyFunc
+		}
+
+		if len(c.audiences) > 1 {
+			env.Printf("audience(%s):\n", strings.Join(c.audiences[i], ","))
+		}
+		for _, svid := range svidResp.Svids {
+			env.Printf("token(%s):\n\t%s\n", svid.SpiffeId, svid.Svid)
+			if svid.Hint != "" {
+				env.Printf("hint(%s):\n\t%s\n", svid.SpiffeId, svid.Hint)
+			}
 		}
 	}
 
@@ -104,3 +218,20 @@ func printPrettyResult(env *commoncli.Env, results ...any) error {
 
 	return nil
 }
+
+// audiencesFlag holds the audience values of each -audience flag, which is a
+// comma separated list of audience values.
+type audiencesFlag [][]string
+
+func (f audiencesFlag) String() string {
+	audiences := make([]string, 0, len(f))
+	for _, audience := range f {
+		audiences = append(audiences, strings.Join(audience, ","))
+	}
+	return strings.Join(audiences, " ")
+}
+
+func (f *audiencesFlag) Set(v string) error {
+	*f = append(*f, strings.Split(v, ","))
+	return nil
+}
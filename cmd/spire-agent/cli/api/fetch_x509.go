@@ -4,13 +4,17 @@ import (
 	"context"
 	"crypto"
 	"crypto/x509"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 	"path"
+	"strings"
 	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
@@ -19,6 +23,23 @@ import (
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/common/diskutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// x509FormatSeparate writes the certificates and the key of each SVID
+	// to separate files
+	x509FormatSeparate = "separate"
+
+	// x509FormatPEMBundle writes the certificates and the key of each SVID
+	// to a single file, as expected by many TLS libraries
+	x509FormatPEMBundle = "pem-bundle"
+
+	// x509SVIDCacheFile is the file in the cache directory the last
+	// response fetched from the Workload API is written to
+	x509SVIDCacheFile = "x509_svids.pb"
 )
 
 func NewFetchX509Command() cli.Command {
@@ -26,15 +47,24 @@ func NewFetchX509Command() cli.Command {
 }
 
 func newFetchX509Command(env *commoncli.Env, clientMaker workloadClientMaker) cli.Command {
-	return adaptCommand(env, clientMaker, &fetchX509Command{env: env})
+	return adaptCommand(env, clientMaker, &fetchX509Command{env: env, clk: clock.New()})
 }
 
 type fetchX509Command struct {
-	silent    bool
-	writePath string
-	env       *commoncli.Env
-	printer   cliprinter.Printer
-	respTime  time.Duration
+	silent        bool
+	writePath     string
+	format        string
+	spiffeID      string
+	hint          string
+	retry         int
+	retryInterval time.Duration
+	cacheDir      string
+	env           *commoncli.Env
+	printer       cliprinter.Printer
+	output        *cliprinter.FormatterFlag
+	respTime      time.Duration
+
+	clk clock.Clock
 }
 
 func (*fetchX509Command) name() string {
@@ -46,20 +76,152 @@ func (*fetchX509Command) synopsis() string {
 }
 
 func (c *fetchX509Command) run(ctx context.Context, _ *commoncli.Env, client *workloadClient) error {
-	start := time.Now()
-	resp, err := c.fetchX509SVID(ctx, client)
-	c.respTime = time.Since(start)
-	if err != nil {
+	switch c.format {
+	case x509FormatSeparate:
+	case x509FormatPEMBundle:
+		if c.writePath == "" {
+			return fmt.Errorf("the %s format requires -write", x509FormatPEMBundle)
+		}
+		if c.output.String() == "json" {
+			return fmt.Errorf("the %s format cannot be used with json output", x509FormatPEMBundle)
+		}
+	default:
+		return fmt.Errorf("unknown format %q; expected %s or %s", c.format, x509FormatSeparate, x509FormatPEMBundle)
+	}
+
+	resp, err := c.fetchX509SVIDWithRetry(ctx, client)
+	switch {
+	case err == nil && c.cacheDir != "":
+		if err := writeX509SVIDCache(c.cacheDir, resp); err != nil {
+			_ = c.env.ErrPrintf("Failed to cache the SVIDs: %v\n", err)
+		}
+	case err != nil && c.cacheDir != "" && status.Code(err) == codes.Unavailable:
+		cached, cacheErr := readX509SVIDCache(c.cacheDir, c.clk.Now())
+		if cacheErr != nil {
+			return fmt.Errorf("%w; cached SVIDs cannot be used: %v", err, cacheErr)
+		}
+		_ = c.env.ErrPrintf("Workload API is unavailable (%v); using cached SVIDs from %s\n", err, c.cacheDir)
+		resp = cached
+	case err != nil:
 		return err
 	}
 
+	if c.spiffeID != "" {
+		if err := filterX509SVIDsBySPIFFEID(resp, c.spiffeID); err != nil {
+			return err
+		}
+	}
+
+	if c.hint != "" {
+		if err := filterX509SVIDsByHint(resp, c.hint); err != nil {
+			return err
+		}
+	}
+
+	if c.output.String() == "json" {
+		return c.printJSON(resp)
+	}
+
 	return c.printer.PrintProto(resp)
 }
 
 func (c *fetchX509Command) appendFlags(fs *flag.FlagSet) {
 	fs.BoolVar(&c.silent, "silent", false, "Suppress stdout")
-	fs.StringVar(&c.writePath, "write", "", "Write SVID data to the specified path (optional; only available for pretty output format)")
-	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintFetchX509)
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "Only fetch the SVID for this SPIFFE ID (optional)")
+	fs.StringVar(&c.hint, "hint", "", "Only fetch the SVID with this hint (optional)")
+	fs.IntVar(&c.retry, "retry", 0, "Number of times to retry while the Workload API is unavailable (optional)")
+	fs.DurationVar(&c.retryInterval, "retryInterval", time.Second, "Time to wait between retries")
+	fs.StringVar(&c.cacheDir, "cacheDir", "", "Directory the fetched SVIDs are cached in. When the Workload API is unavailable, the cached SVIDs are used instead as long as none has expired (optional)")
+	fs.StringVar(&c.writePath, "write", "", "Write SVID data to the specified path (optional; with json output format, a single svids.json file is written)")
+	fs.StringVar(&c.format, "format", x509FormatSeparate, "Layout of the files written with -write: separate, or pem-bundle to write the SVID certificate, its intermediates and its key to a single svid.<n>.combined.pem file, readable only by its owner")
+	c.output = cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintFetchX509)
+}
+
+// x509SVIDDocument is the JSON representation of an X509-SVID, with its
+// certificates and key PEM encoded.
+type x509SVIDDocument struct {
+	SPIFFEID         string            `json:"spiffe_id"`
+	Hint             string            `json:"hint,omitempty"`
+	X509SVID         string            `json:"x509_svid"`
+	X509SVIDKey      string            `json:"x509_svid_key"`
+	Bundle           string            `json:"bundle"`
+	FederatedBundles map[string]string `json:"federated_bundles,omitempty"`
+	ExpiresAt        string            `json:"expires_at"`
+}
+
+// printJSON prints the SVIDs as a JSON array of documents, or writes it to
+// a single file if a write path was given.
+func (c *fetchX509Command) printJSON(resp *workload.X509SVIDResponse) error {
+	svids, err := parseAndValidateX509SVIDResponse(resp)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]any, 0, len(svids))
+	for _, svid := range svids {
+		doc, err := newX509SVIDDocument(svid)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	if c.writePath != "" {
+		data, err := json.MarshalIndent(docs, "", "  ")
+		if err != nil {
+			return err
+		}
+		// The document contains private keys
+		return diskutil.WritePrivateFile(path.Join(c.writePath, "svids.json"), data)
+	}
+
+	if c.silent {
+		return nil
+	}
+	return c.printer.PrintStruct(docs...)
+}
+
+func newX509SVIDDocument(svid *X509SVID) (*x509SVIDDocument, error) {
+	keyPEM, err := pemFromPrivateKey(svid.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &x509SVIDDocument{
+		SPIFFEID:    svid.SPIFFEID,
+		Hint:        svid.Hint,
+		X509SVID:    string(pemFromCertificates(svid.Certificates)),
+		X509SVIDKey: string(keyPEM),
+		Bundle:      string(pemFromCertificates(svid.Bundle)),
+		ExpiresAt:   svid.Certificates[0].NotAfter.UTC().Format(time.RFC3339),
+	}
+	if len(svid.FederatedBundles) > 0 {
+		doc.FederatedBundles = make(map[string]string, len(svid.FederatedBundles))
+		for trustDomain, bundle := range svid.FederatedBundles {
+			doc.FederatedBundles[trustDomain] = string(pemFromCertificates(bundle))
+		}
+	}
+	return doc, nil
+}
+
+// fetchX509SVIDWithRetry fetches the SVIDs, retrying up to c.retry times
+// while the Workload API is unavailable (e.g. the agent socket is not ready
+// yet). Any other error is returned right away.
+func (c *fetchX509Command) fetchX509SVIDWithRetry(ctx context.Context, client *workloadClient) (*workload.X509SVIDResponse, error) {
+	for attempt := 0; ; attempt++ {
+		start := time.Now()
+		resp, err := c.fetchX509SVID(ctx, client)
+		c.respTime = time.Since(start)
+		if err == nil || attempt >= c.retry || status.Code(err) != codes.Unavailable {
+			return resp, err
+		}
+
+		select {
+		case <-time.After(c.retryInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
 }
 
 func (c *fetchX509Command) fetchX509SVID(ctx context.Context, client *workloadClient) (*workload.X509SVIDResponse, error) {
@@ -74,26 +236,99 @@ func (c *fetchX509Command) fetchX509SVID(ctx context.Context, client *workloadCl
 	return stream.Recv()
 }
 
+// writeX509SVIDCache writes the response to the cache directory. Since the
+// response contains the SVID keys, the file is only readable by its owner.
+func writeX509SVIDCache(cacheDir string, resp *workload.X509SVIDResponse) error {
+	data, err := proto.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	return diskutil.AtomicWritePrivateFile(path.Join(cacheDir, x509SVIDCacheFile), data)
+}
+
+// readX509SVIDCache reads the response cached in the cache directory. The
+// response is only returned if it is valid and none of its SVIDs has expired.
+func readX509SVIDCache(cacheDir string, now time.Time) (*workload.X509SVIDResponse, error) {
+	data, err := os.ReadFile(path.Join(cacheDir, x509SVIDCacheFile))
+	if err != nil {
+		return nil, err
+	}
+	resp := new(workload.X509SVIDResponse)
+	if err := proto.Unmarshal(data, resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal cached SVIDs: %w", err)
+	}
+
+	svids, err := parseX509SVIDResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	for _, svid := range svids {
+		if notAfter := svid.Certificates[0].NotAfter; !now.Before(notAfter) {
+			return nil, fmt.Errorf("cached SVID %q expired at %s", svid.SPIFFEID, notAfter.UTC().Format(time.RFC3339))
+		}
+	}
+	return resp, nil
+}
+
+// filterX509SVIDsBySPIFFEID keeps only the SVIDs in the response whose SPIFFE
+// ID matches exactly. It fails, listing the SPIFFE IDs the workload is
+// authorized for, if there are none.
+func filterX509SVIDsBySPIFFEID(resp *workload.X509SVIDResponse, spiffeID string) error {
+	var svids []*workload.X509SVID
+	var spiffeIDs []string
+	for _, svid := range resp.Svids {
+		if svid.SpiffeId == spiffeID {
+			svids = append(svids, svid)
+		}
+		spiffeIDs = append(spiffeIDs, fmt.Sprintf("%q", svid.SpiffeId))
+	}
+	if len(svids) == 0 {
+		return fmt.Errorf("workload is not authorized for SPIFFE ID %q; authorized SPIFFE IDs: %s", spiffeID, strings.Join(spiffeIDs, ", "))
+	}
+	resp.Svids = svids
+	return nil
+}
+
+// filterX509SVIDsByHint keeps only the SVID in the response whose hint
+// matches exactly.
+func filterX509SVIDsByHint(resp *workload.X509SVIDResponse, hint string) error {
+	var hints []string
+	for _, svid := range resp.Svids {
+		if svid.Hint == hint {
+			resp.Svids = []*workload.X509SVID{svid}
+			return nil
+		}
+		hints = append(hints, svid.Hint)
+	}
+	return newHintNotFoundError(hint, hints)
+}
+
 func (c *fetchX509Command) writeResponse(svids []*X509SVID) error {
 	for i, svid := range svids {
-		svidPath := path.Join(c.writePath, fmt.Sprintf("svid.%v.pem", i))
-		keyPath := path.Join(c.writePath, fmt.Sprintf("svid.%v.key", i))
-		bundlePath := path.Join(c.writePath, fmt.Sprintf("bundle.%v.pem", i))
+		if c.format == x509FormatPEMBundle {
+			combinedPath := path.Join(c.writePath, fmt.Sprintf("svid.%v.combined.pem", i))
+			c.env.Printf("Writing SVID #%d and its key to file %s.\n", i, combinedPath)
+			if err := writePEMBundle(combinedPath, svid); err != nil {
+				return err
+			}
+		} else {
+			svidPath := path.Join(c.writePath, fmt.Sprintf("svid.%v.pem", i))
+			keyPath := path.Join(c.writePath, fmt.Sprintf("svid.%v.key", i))
 
-		c.env.Printf("Writing SVID #%d to file %s.\n", i, svidPath)
-		err := c.writeCerts(svidPath, svid.Certificates)
-		if err != nil {
-			return err
-		}
+			c.env.Printf("Writing SVID #%d to file %s.\n", i, svidPath)
+			if err := c.writeCerts(svidPath, svid.Certificates); err != nil {
+				return err
+			}
 
-		c.env.Printf("Writing key #%d to file %s.\n", i, keyPath)
-		err = c.writeKey(keyPath, svid.PrivateKey)
-		if err != nil {
-			return err
+			c.env.Printf("Writing key #%d to file %s.\n", i, keyPath)
+			if err := c.writeKey(keyPath, svid.PrivateKey); err != nil {
+				return err
+			}
 		}
 
+		bundlePath := path.Join(c.writePath, fmt.Sprintf("bundle.%v.pem", i))
 		c.env.Printf("Writing bundle #%d to file %s.\n", i, bundlePath)
-		err = c.writeCerts(bundlePath, svid.Bundle)
+		err := c.writeCerts(bundlePath, svid.Bundle)
 		if err != nil {
 			return err
 		}
@@ -120,6 +355,33 @@ func (c *fetchX509Command) writeResponse(svids []*X509SVID) error {
 // writeCerts takes a slice of data, which may contain multiple certificates,
 // and encodes them as PEM blocks, writing them to filename
 func (c *fetchX509Command) writeCerts(filename string, certs []*x509.Certificate) error {
+	return c.writeFile(filename, pemFromCertificates(certs))
+}
+
+// writeKey takes a private key, formats as PEM, and writes it to filename
+func (c *fetchX509Command) writeKey(filename string, privateKey crypto.PrivateKey) error {
+	data, err := pemFromPrivateKey(privateKey)
+	if err != nil {
+		return err
+	}
+
+	return diskutil.WritePrivateFile(filename, data)
+}
+
+// writePEMBundle writes the SVID certificate, followed by its intermediates
+// and its private key, as PEM blocks to filename. Since the file contains the
+// key, it is only readable by its owner.
+func writePEMBundle(filename string, svid *X509SVID) error {
+	keyPEM, err := pemFromPrivateKey(svid.PrivateKey)
+	if err != nil {
+		return err
+	}
+
+	return diskutil.WritePrivateFile(filename, append(pemFromCertificates(svid.Certificates), keyPEM...))
+}
+
+// pemFromCertificates encodes the certificates as PEM blocks
+func pemFromCertificates(certs []*x509.Certificate) []byte {
 	pemData := []byte{}
 	for _, cert := range certs {
 		b := &pem.Block{
@@ -128,22 +390,20 @@ func (c *fetchX509Command) writeCerts(filename string, certs []*x509.Certificate
 		}
 		pemData = append(pemData, pem.EncodeToMemory(b)...)
 	}
-
-	return c.writeFile(filename, pemData)
+	return pemData
 }
 
-// writeKey takes a private key, formats as PEM, and writes it to filename
-func (c *fetchX509Command) writeKey(filename string, privateKey crypto.PrivateKey) error {
+// pemFromPrivateKey encodes the private key as a PKCS#8 PEM block
+func pemFromPrivateKey(privateKey crypto.PrivateKey) ([]byte, error) {
 	data, err := x509.MarshalPKCS8PrivateKey(privateKey)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	b := &pem.Block{
 		Type:  "PRIVATE KEY",
 		Bytes: data,
 	}
-
-	return diskutil.WritePrivateFile(filename, pem.EncodeToMemory(b))
+	return pem.EncodeToMemory(b), nil
 }
 
 // writeFile creates or truncates filename, and writes data to it
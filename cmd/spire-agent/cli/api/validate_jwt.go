@@ -2,17 +2,26 @@ package api
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/bundle/jwtbundle"
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/jwtsvid"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 func NewValidateJWTCommand() cli.Command {
@@ -20,14 +29,23 @@ func NewValidateJWTCommand() cli.Command {
 }
 
 func newValidateJWTCommand(env *commoncli.Env, clientMaker workloadClientMaker) cli.Command {
-	return adaptCommand(env, clientMaker, &validateJWTCommand{env: env})
+	return adaptCommand(env, clientMaker, &validateJWTCommand{env: env, clk: clock.New()})
 }
 
 type validateJWTCommand struct {
 	audience string
 	svid     string
+	cacheTTL time.Duration
 	env      *commoncli.Env
 	printer  cliprinter.Printer
+
+	clk clock.Clock
+
+	// bundles are the JWT bundles fetched from the Workload API, by trust
+	// domain, when validating locally. They are kept across runs of the
+	// command in the same process until cacheTTL elapses.
+	bundles          map[spiffeid.TrustDomain]*jwtbundle.Bundle
+	bundlesFetchedAt time.Time
 }
 
 func (*validateJWTCommand) name() string {
@@ -41,6 +59,7 @@ func (*validateJWTCommand) synopsis() string {
 func (c *validateJWTCommand) appendFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.audience, "audience", "", "expected audience value")
 	fs.StringVar(&c.svid, "svid", "", "JWT SVID")
+	fs.DurationVar(&c.cacheTTL, "cacheTTL", 0, "Validate the SVID locally with the JWT bundles fetched from the Workload API, caching them for this amount of time; 0 validates through the Workload API instead (optional)")
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintValidate)
 }
 
@@ -52,7 +71,11 @@ func (c *validateJWTCommand) run(ctx context.Context, _ *commoncli.Env, client *
 		return errors.New("svid must be specified")
 	}
 
-	resp, err := c.validateJWTSVID(ctx, client)
+	validate := c.validateJWTSVID
+	if c.cacheTTL > 0 {
+		validate = c.validateJWTSVIDLocally
+	}
+	resp, err := validate(ctx, client)
 	if err != nil {
 		return err
 	}
@@ -76,6 +99,103 @@ func (c *validateJWTCommand) validateJWTSVID(ctx context.Context, client *worklo
 	return resp, nil
 }
 
+// validateJWTSVIDLocally validates the SVID with the cached JWT bundles,
+// fetching them first if they are missing or older than the cache TTL. If the
+// SVID is signed with a key that is not in a cached bundle, the bundles are
+// refreshed before giving up, since the key may have been added since.
+func (c *validateJWTCommand) validateJWTSVIDLocally(ctx context.Context, client *workloadClient) (*workload.ValidateJWTSVIDResponse, error) {
+	audience := []string{c.audience}
+	unverified, err := jwtsvid.ParseInsecure(c.svid, audience)
+	if err != nil {
+		return nil, fmt.Errorf("SVID is not valid: %v", err)
+	}
+	td := unverified.ID.TrustDomain()
+
+	fetched := false
+	if c.bundles == nil || c.clk.Now().Sub(c.bundlesFetchedAt) >= c.cacheTTL {
+		if err := c.fetchJWTBundles(ctx, client); err != nil {
+			return nil, err
+		}
+		fetched = true
+	}
+
+	bundle, ok := c.bundles[td]
+	if !fetched && (!ok || !hasJWTAuthority(bundle, c.svid)) {
+		if err := c.fetchJWTBundles(ctx, client); err != nil {
+			return nil, err
+		}
+		bundle, ok = c.bundles[td]
+	}
+	if !ok {
+		return nil, fmt.Errorf("SVID is not valid: no JWT bundle found for trust domain %q", td)
+	}
+
+	svid, err := jwtsvid.ParseAndValidate(c.svid, bundle, audience)
+	if err != nil {
+		return nil, fmt.Errorf("SVID is not valid: %v", err)
+	}
+
+	claims, err := structpb.NewStruct(svid.Claims)
+	if err != nil {
+		return nil, fmt.Errorf("unable to convert claims: %w", err)
+	}
+	return &workload.ValidateJWTSVIDResponse{
+		SpiffeId: svid.ID.String(),
+		Claims:   claims,
+	}, nil
+}
+
+// fetchJWTBundles replaces the cached JWT bundles with the ones returned by
+// the Workload API.
+func (c *validateJWTCommand) fetchJWTBundles(ctx context.Context, client *workloadClient) error {
+	ctx, cancel := client.prepareContext(ctx)
+	defer cancel()
+	stream, err := client.FetchJWTBundles(ctx, &workload.JWTBundlesRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to receive JWT bundles: %w", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		return fmt.Errorf("failed to receive JWT bundles: %w", err)
+	}
+
+	bundles := make(map[spiffeid.TrustDomain]*jwtbundle.Bundle, len(resp.Bundles))
+	for tdName, jwks := range resp.Bundles {
+		td, err := spiffeid.TrustDomainFromString(tdName)
+		if err != nil {
+			return fmt.Errorf("invalid trust domain %q in JWT bundles: %w", tdName, err)
+		}
+		bundle, err := jwtbundle.Parse(td, jwks)
+		if err != nil {
+			return fmt.Errorf("failed to parse JWT bundle for trust domain %q: %w", tdName, err)
+		}
+		bundles[td] = bundle
+	}
+
+	c.bundles = bundles
+	c.bundlesFetchedAt = c.clk.Now()
+	return nil
+}
+
+// hasJWTAuthority returns whether the bundle has the key the token header
+// refers to. Tokens with a malformed header are left for the validation to
+// reject.
+func hasJWTAuthority(bundle *jwtbundle.Bundle, token string) bool {
+	encodedHeader, _, _ := strings.Cut(token, ".")
+	rawHeader, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+	if err != nil {
+		return true
+	}
+	var header struct {
+		KeyID string `json:"kid"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return true
+	}
+	_, ok := bundle.FindJWTAuthority(header.KeyID)
+	return ok
+}
+
 func prettyPrintValidate(env *commoncli.Env, results ...any) error {
 	resp, ok := results[0].(*workload.ValidateJWTSVIDResponse)
 	if !ok {
@@ -3,20 +3,33 @@ package api
 import (
 	"context"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"flag"
-	"fmt"
+	"maps"
 	"os"
 	"os/signal"
+	"slices"
 	"time"
 
 	"github.com/spiffe/go-spiffe/v2/workloadapi"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/common/util"
 )
 
 type WatchCLI struct {
 	config *common.ConfigOS
+
+	// env is where updates and errors are printed. Defaults to
+	// commoncli.DefaultEnv when nil.
+	env *commoncli.Env
+
+	timeout        time.Duration
+	exitAfterFirst bool
+	printer        cliprinter.Printer
+	output         *cliprinter.FormatterFlag
 }
 
 func (WatchCLI) Synopsis() string {
@@ -29,29 +42,62 @@ func (w WatchCLI) Help() string {
 }
 
 func (w *WatchCLI) Run(args []string) int {
+	env := w.env
+	if env == nil {
+		env = commoncli.DefaultEnv
+	}
+
 	err := w.parseConfig(args)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		_ = env.ErrPrintln(err)
 		return 1
 	}
 
 	addr, err := w.config.GetAddr()
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		_ = env.ErrPrintln(err)
 		return 1
 	}
 
 	clientOption, err := util.GetWorkloadAPIClientOption(addr)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
+		_ = env.ErrPrintln(err)
 		return 1
 	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer cancel()
 
-	if err := workloadapi.WatchX509Context(ctx, newWatcher(), clientOption); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	if w.timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, w.timeout)
+		defer cancelTimeout()
+	}
+
+	// Cancelled to stop watching once the first update is received
+	ctx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	watcher := newWatcher(env)
+	watcher.jsonOutput = w.output.String() == "json"
+	if w.exitAfterFirst {
+		watcher.onUpdate = stop
+	}
+	if w.exitAfterFirst || w.timeout > 0 {
+		// The watch is expected to be cancelled, so the resulting error
+		// is not worth printing.
+		watcher.done = ctx.Done()
+	}
+
+	err = workloadapi.WatchX509Context(ctx, watcher, clientOption)
+	switch {
+	case watcher.received && ctx.Err() != nil && (w.exitAfterFirst || w.timeout > 0):
+		return 0
+	case w.timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		_ = env.ErrPrintf("No update received after %s\n", w.timeout)
+		return 1
+	case err != nil:
+		_ = env.ErrPrintln(err)
 		return 1
 	}
 
@@ -59,20 +105,43 @@ func (w *WatchCLI) Run(args []string) int {
 }
 
 func (w *WatchCLI) parseConfig(args []string) error {
+	env := w.env
+	if env == nil {
+		env = commoncli.DefaultEnv
+	}
+
 	fs := flag.NewFlagSet("watch", flag.ContinueOnError)
 	c := &common.ConfigOS{}
 	c.AddOSFlags(fs)
+	fs.DurationVar(&w.timeout, "timeout", 0, "Stop watching after this amount of time, exiting with a nonzero code if no update was received (optional)")
+	fs.BoolVar(&w.exitAfterFirst, "exitAfterFirst", false, "Exit after the first update is received (optional)")
+	w.output = cliprinter.AppendFlag(&w.printer, fs, env)
 
 	w.config = c
 	return fs.Parse(args)
 }
 
 type watcher struct {
+	env        *commoncli.Env
 	updateTime time.Time
+	received   bool
+
+	// jsonOutput prints every update as a single line JSON event instead
+	// of the human readable details
+	jsonOutput bool
+	// previous holds the SVIDs of the last update, keyed by SPIFFE ID and
+	// hint, to tell how the SVIDs changed in JSON events
+	previous map[string]watchSVID
+
+	// onUpdate, if set, is called after an update is printed
+	onUpdate func()
+	// done, if set, silences the watch errors once it is closed
+	done <-chan struct{}
 }
 
-func newWatcher() *watcher {
+func newWatcher(env *commoncli.Env) *watcher {
 	return &watcher{
+		env:        env,
 		updateTime: time.Now(),
 	}
 }
@@ -99,10 +168,97 @@ func (w *watcher) OnX509ContextUpdate(x509Context *workloadapi.X509Context) {
 			FederatedBundles: federatedBundles,
 		})
 	}
-	printX509SVIDResponse(commoncli.DefaultEnv, svids, time.Since(w.updateTime))
+	if w.jsonOutput {
+		w.printJSONEvent(x509Context)
+	} else {
+		printX509SVIDResponse(w.env, svids, time.Since(w.updateTime))
+	}
 	w.updateTime = time.Now()
+	w.received = true
+	if w.onUpdate != nil {
+		w.onUpdate()
+	}
 }
 
 func (w *watcher) OnX509ContextWatchError(err error) {
-	fmt.Fprintln(os.Stderr, err)
+	if w.done != nil {
+		select {
+		case <-w.done:
+			return
+		default:
+		}
+	}
+	_ = w.env.ErrPrintln(err)
+}
+
+// watchEvent is the JSON representation of an update received while
+// watching, printed on a line of its own.
+type watchEvent struct {
+	Type  string      `json:"type"`
+	SVIDs []watchSVID `json:"svids"`
+}
+
+// watchSVID describes an SVID of an update, and how it changed relative to
+// the previous update: "added", "removed", "rotated" or "unchanged".
+type watchSVID struct {
+	SPIFFEID  string `json:"spiffe_id"`
+	Hint      string `json:"hint,omitempty"`
+	ExpiresAt string `json:"expires_at"`
+	Change    string `json:"change"`
+
+	serialNumber string
+}
+
+func (w *watcher) printJSONEvent(x509Context *workloadapi.X509Context) {
+	event := watchEvent{
+		Type:  "x509_update",
+		SVIDs: make([]watchSVID, 0, len(x509Context.SVIDs)),
+	}
+
+	current := make(map[string]watchSVID, len(x509Context.SVIDs))
+	for _, svid := range x509Context.SVIDs {
+		leaf := svid.Certificates[0]
+		s := watchSVID{
+			SPIFFEID:     svid.ID.String(),
+			Hint:         svid.Hint,
+			ExpiresAt:    leaf.NotAfter.UTC().Format(time.RFC3339),
+			serialNumber: leaf.SerialNumber.String(),
+		}
+		key := s.SPIFFEID + "#" + s.Hint
+		previous, ok := w.previous[key]
+		switch {
+		case !ok:
+			s.Change = "added"
+		case previous.serialNumber != s.serialNumber:
+			s.Change = "rotated"
+		default:
+			s.Change = "unchanged"
+		}
+		current[key] = s
+		event.SVIDs = append(event.SVIDs, s)
+	}
+	for _, key := range slices.Sorted(maps.Keys(w.previous)) {
+		if _, ok := current[key]; !ok {
+			s := w.previous[key]
+			s.Change = "removed"
+			event.SVIDs = append(event.SVIDs, s)
+		}
+	}
+	w.previous = current
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		_ = w.env.ErrPrintln(err)
+		return
+	}
+	if err := w.env.Println(string(data)); err != nil {
+		_ = w.env.ErrPrintln(err)
+		return
+	}
+	// Downstream readers must see every event as soon as it is received
+	if flusher, ok := w.env.Stdout.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			_ = w.env.ErrPrintln(err)
+		}
+	}
 }
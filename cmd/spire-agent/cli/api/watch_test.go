@@ -0,0 +1,209 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/bundle/x509bundle"
+	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/go-spiffe/v2/workloadapi"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/x509util"
+	"github.com/spiffe/spire/test/fakes/fakeworkloadapi"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestWatchCommandSynopsis(t *testing.T) {
+	test := setupTest(t, newWatchCommand)
+	require.Equal(t, "Attaches to the Workload API and prints updates as they're received", test.cmd.Synopsis())
+}
+
+func TestWatchCommand(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	svid := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+
+	// The fake Workload API delivers this update and then blocks until the
+	// stream is closed
+	update := &fakeworkloadapi.FakeRequest{
+		Req: &workload.X509SVIDRequest{},
+		Resp: &workload.X509SVIDResponse{
+			Svids: []*workload.X509SVID{
+				{
+					SpiffeId:    svid.ID.String(),
+					X509Svid:    x509util.DERFromCertificates(svid.Certificates),
+					X509SvidKey: pkcs8FromSigner(t, svid.PrivateKey),
+					Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+				},
+			},
+		},
+	}
+	noUpdate := &fakeworkloadapi.FakeRequest{
+		Req:  &workload.X509SVIDRequest{},
+		Resp: &workload.X509SVIDResponse{},
+		Err:  status.Error(codes.PermissionDenied, "no identity issued"),
+	}
+
+	for _, tt := range []struct {
+		name           string
+		args           []string
+		fakeRequest    *fakeworkloadapi.FakeRequest
+		expectedCode   int
+		expectedStdout string
+		expectedStderr string
+	}{
+		{
+			name:           "exit after first update",
+			args:           []string{"-exitAfterFirst"},
+			fakeRequest:    update,
+			expectedStdout: "Received 1 svid after",
+		},
+		{
+			name:           "timeout after an update",
+			args:           []string{"-timeout", "100ms"},
+			fakeRequest:    update,
+			expectedStdout: "Received 1 svid after",
+		},
+		{
+			name:           "timeout without updates",
+			args:           []string{"-timeout", "100ms"},
+			fakeRequest:    noUpdate,
+			expectedCode:   1,
+			expectedStderr: "No update received after 100ms\n",
+		},
+		{
+			name:           "exit after first update before the timeout",
+			args:           []string{"-exitAfterFirst", "-timeout", "1m"},
+			fakeRequest:    update,
+			expectedStdout: "Received 1 svid after",
+		},
+		{
+			name:           "timeout before the first update",
+			args:           []string{"-exitAfterFirst", "-timeout", "100ms"},
+			fakeRequest:    noUpdate,
+			expectedCode:   1,
+			expectedStderr: "No update received after 100ms\n",
+		},
+		{
+			name:           "invalid timeout",
+			args:           []string{"-timeout", "soon"},
+			fakeRequest:    update,
+			expectedCode:   1,
+			expectedStderr: `invalid value "soon" for flag -timeout`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newWatchCommand, tt.fakeRequest)
+
+			rc := test.cmd.Run(test.args(tt.args...))
+			require.Equal(t, tt.expectedCode, rc)
+			if tt.expectedStdout != "" {
+				require.Contains(t, test.stdout.String(), tt.expectedStdout)
+				require.Contains(t, test.stdout.String(), "SPIFFE ID:\t\tspiffe://example.org/foo")
+			} else {
+				require.Empty(t, test.stdout.String())
+			}
+			if tt.expectedStderr != "" {
+				require.Contains(t, test.stderr.String(), tt.expectedStderr)
+			} else {
+				require.Empty(t, test.stderr.String())
+			}
+		})
+	}
+}
+
+func TestWatchCommandJSONOutput(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	svid := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+
+	test := setupTest(t, newWatchCommand, &fakeworkloadapi.FakeRequest{
+		Req: &workload.X509SVIDRequest{},
+		Resp: &workload.X509SVIDResponse{
+			Svids: []*workload.X509SVID{
+				{
+					SpiffeId:    svid.ID.String(),
+					X509Svid:    x509util.DERFromCertificates(svid.Certificates),
+					X509SvidKey: pkcs8FromSigner(t, svid.PrivateKey),
+					Bundle:      x509util.DERFromCertificates(ca.Bundle().X509Authorities()),
+				},
+			},
+		},
+	})
+
+	rc := test.cmd.Run(test.args("-exitAfterFirst", "-output", "json"))
+	require.Equal(t, 0, rc)
+	require.Empty(t, test.stderr.String())
+	require.JSONEq(t, fmt.Sprintf(`{
+		"type": "x509_update",
+		"svids": [
+			{"spiffe_id": "spiffe://example.org/foo", "expires_at": %q, "change": "added"}
+		]
+	}`, svid.Certificates[0].NotAfter.UTC().Format(time.RFC3339)), test.stdout.String())
+}
+
+func TestWatcherJSONEvents(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("example.org")
+	ca := testca.New(t, td)
+	foo := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+	rotatedFoo := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/foo"))
+	bar := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/bar"))
+	baz := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/baz"))
+	bundles := x509bundle.NewSet(ca.X509Bundle())
+
+	stdout := new(bytes.Buffer)
+	env := &commoncli.Env{Stdout: stdout, Stderr: new(bytes.Buffer)}
+	w := newWatcher(env)
+	w.jsonOutput = true
+	w.OnX509ContextUpdate(&workloadapi.X509Context{
+		SVIDs:   []*x509svid.SVID{foo, bar},
+		Bundles: bundles,
+	})
+	w.OnX509ContextUpdate(&workloadapi.X509Context{
+		SVIDs:   []*x509svid.SVID{rotatedFoo, baz},
+		Bundles: bundles,
+	})
+
+	expiresAt := func(svid *x509svid.SVID) string {
+		return svid.Certificates[0].NotAfter.UTC().Format(time.RFC3339)
+	}
+	expected := []watchEvent{
+		{
+			Type: "x509_update",
+			SVIDs: []watchSVID{
+				{SPIFFEID: "spiffe://example.org/foo", ExpiresAt: expiresAt(foo), Change: "added"},
+				{SPIFFEID: "spiffe://example.org/bar", ExpiresAt: expiresAt(bar), Change: "added"},
+			},
+		},
+		{
+			Type: "x509_update",
+			SVIDs: []watchSVID{
+				{SPIFFEID: "spiffe://example.org/foo", ExpiresAt: expiresAt(rotatedFoo), Change: "rotated"},
+				{SPIFFEID: "spiffe://example.org/baz", ExpiresAt: expiresAt(baz), Change: "added"},
+				{SPIFFEID: "spiffe://example.org/bar", ExpiresAt: expiresAt(bar), Change: "removed"},
+			},
+		},
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	require.Len(t, lines, len(expected))
+	for i, line := range lines {
+		var event watchEvent
+		require.NoError(t, json.Unmarshal([]byte(line), &event), "line %d is not well-formed JSON", i)
+		require.Equal(t, expected[i], event)
+	}
+}
+
+func newWatchCommand(env *commoncli.Env, _ workloadClientMaker) cli.Command {
+	return &WatchCLI{env: env}
+}
@@ -28,6 +28,9 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"api fetch x509": func() (cli.Command, error) {
 			return api.NewFetchX509Command(), nil
 		},
+		"api fetch bundle": func() (cli.Command, error) {
+			return api.NewFetchBundleCommand(), nil
+		},
 		"api fetch jwt": func() (cli.Command, error) {
 			return api.NewFetchJWTCommand(), nil
 		},
@@ -2,7 +2,9 @@
 
 package common
 
-const (
+// The default paths are variables so they can be overridden at build time,
+// e.g. with -ldflags "-X github.com/spiffe/spire/cmd/spire-agent/cli/common.DefaultSocketPath=/run/spire-agent/public/api.sock".
+var (
 	// DefaultSocketPath is the SPIRE agent's default socket path
 	DefaultSocketPath = "/tmp/kirin-agent/public/api.sock"
 	// DefaultAdminSocketPath is the SPIRE agent's default admin socket path
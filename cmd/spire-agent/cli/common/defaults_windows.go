@@ -2,7 +2,9 @@
 
 package common
 
-const (
+// The default names are variables so they can be overridden at build time,
+// e.g. with -ldflags "-X github.com/spiffe/spire/cmd/spire-agent/cli/common.DefaultNamedPipeName=\spire\agent\api".
+var (
 	// DefaultNamedPipeName is the SPIRE agent's default named pipe name
 	DefaultNamedPipeName = "\\spire-agent\\public\\api"
 	// DefaultAdminNamedPipeName is the SPIRE agent's default admin named pipe name
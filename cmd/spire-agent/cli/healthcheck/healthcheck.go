@@ -2,16 +2,27 @@ package healthcheck
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/mitchellh/cli"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/common/util"
 	"google.golang.org/grpc/health/grpc_health_v1"
 )
 
+const (
+	defaultHealthURL = "http://localhost/ready"
+
+	// healthURLTimeout is the time to wait for the readiness endpoint to respond
+	healthURLTimeout = 5 * time.Second
+)
+
 func NewHealthCheckCommand() cli.Command {
 	return newHealthCheckCommand(common_cli.DefaultEnv)
 }
@@ -29,6 +40,39 @@ type healthCheckCommand struct {
 
 	shallow bool
 	verbose bool
+
+	svidExpiryThreshold time.Duration
+	healthURL           string
+
+	printer cliprinter.Printer
+	output  *cliprinter.FormatterFlag
+}
+
+// healthCheckResult is the outcome of a health check, as printed with the
+// json output format.
+type healthCheckResult struct {
+	Healthy bool              `json:"healthy"`
+	Error   string            `json:"error,omitempty"`
+	Checks  []*subCheckResult `json:"checks"`
+}
+
+// subCheckResult is the outcome of one of the steps of a health check. Steps
+// following a failed one are not run and not reported.
+type subCheckResult struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (r *healthCheckResult) addCheck(name string, err error) {
+	check := &subCheckResult{
+		Name:    name,
+		Healthy: err == nil,
+	}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	r.Checks = append(r.Checks, check)
 }
 
 func (c *healthCheckCommand) Help() string {
@@ -45,13 +89,14 @@ func (c *healthCheckCommand) Run(args []string) int {
 	if err := c.parseFlags(args); err != nil {
 		return 1
 	}
-	if err := c.run(); err != nil {
-		// Ignore error since a failure to write to stderr cannot very well be
-		// reported
-		_ = c.env.ErrPrintf("Agent is unhealthy: %v\n", err)
-		return 1
+	result := new(healthCheckResult)
+	err := c.run(result)
+	result.Healthy = err == nil
+	if err != nil {
+		result.Error = err.Error()
 	}
-	if err := c.env.Println("Agent is healthy."); err != nil {
+
+	if printErr := c.printer.PrintStruct(result); printErr != nil || err != nil {
 		return 1
 	}
 	return 0
@@ -62,31 +107,35 @@ func (c *healthCheckCommand) parseFlags(args []string) error {
 	fs.SetOutput(c.env.Stderr)
 	fs.BoolVar(&c.shallow, "shallow", false, "Perform a less stringent health check")
 	fs.BoolVar(&c.verbose, "verbose", false, "Print verbose information")
+	fs.DurationVar(&c.svidExpiryThreshold, "svidExpiryThreshold", 0, "Report the agent as unhealthy if its SVID expires within this amount of time (optional)")
+	fs.StringVar(&c.healthURL, "healthURL", defaultHealthURL, "URL of the agent readiness endpoint, queried for the SVID expiry when -svidExpiryThreshold is set")
 	c.addOSFlags(fs)
+	c.output = cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintHealthCheck)
 	return fs.Parse(args)
 }
 
-func (c *healthCheckCommand) run() error {
-	if c.verbose {
+func (c *healthCheckCommand) run(result *healthCheckResult) error {
+	// Progress output would corrupt the JSON document written to stdout
+	if c.verbose && c.output.String() != "json" {
 		c.env.Printf("Checking agent health...\n")
 	}
 
-	addr, err := c.getAddr()
-	if err != nil {
-		return err
-	}
-	target, err := util.GetTargetName(addr)
+	target, err := c.getTarget()
+	result.addCheck("agent_address", err)
 	if err != nil {
 		return err
 	}
+
 	conn, err := util.NewGRPCClient(target)
 	if err != nil {
+		result.addCheck("health_service", err)
 		return err
 	}
 	defer conn.Close()
 
 	healthClient := grpc_health_v1.NewHealthClient(conn)
 	resp, err := healthClient.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	result.addCheck("health_service", err)
 	if err != nil {
 		if c.verbose {
 			// Ignore error since a failure to write to stderr cannot very well
@@ -97,8 +146,75 @@ func (c *healthCheckCommand) run() error {
 	}
 
 	if resp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
-		return fmt.Errorf("agent returned status %q", resp.Status)
+		err = fmt.Errorf("agent returned status %q", resp.Status)
+	}
+	result.addCheck("serving_status", err)
+	if err != nil || c.svidExpiryThreshold <= 0 {
+		return err
 	}
 
+	err = c.checkSVIDExpiry()
+	result.addCheck("svid_expiry", err)
+	return err
+}
+
+// checkSVIDExpiry queries the agent readiness endpoint for the expiration
+// time of the agent SVID and fails if it is within the threshold.
+func (c *healthCheckCommand) checkSVIDExpiry() error {
+	ctx, cancel := context.WithTimeout(context.Background(), healthURLTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.healthURL, nil)
+	if err != nil {
+		return fmt.Errorf("invalid health URL: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to query the readiness endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// The details are returned whether the agent is ready or not
+	var details struct {
+		Agent struct {
+			SVIDExpiresAt *time.Time `json:"svid_expires_at"`
+		} `json:"agent"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&details); err != nil {
+		return fmt.Errorf("unable to parse the readiness endpoint response: %w", err)
+	}
+	if details.Agent.SVIDExpiresAt == nil {
+		return errors.New("agent did not report its SVID expiration time")
+	}
+
+	expiresAt := *details.Agent.SVIDExpiresAt
+	if time.Until(expiresAt) < c.svidExpiryThreshold {
+		return fmt.Errorf("agent SVID expires at %s, within the %s threshold", expiresAt.Format(time.RFC3339), c.svidExpiryThreshold)
+	}
 	return nil
 }
+
+func (c *healthCheckCommand) getTarget() (string, error) {
+	addr, err := c.getAddr()
+	if err != nil {
+		return "", err
+	}
+	return util.GetTargetName(addr)
+}
+
+func prettyPrintHealthCheck(env *common_cli.Env, results ...any) error {
+	var result *healthCheckResult
+	if len(results) == 1 {
+		if structs, ok := results[0].([]any); ok && len(structs) == 1 {
+			result, _ = structs[0].(*healthCheckResult)
+		}
+	}
+	if result == nil {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+
+	if !result.Healthy {
+		return env.ErrPrintf("Agent is unhealthy: %s\n", result.Error)
+	}
+	return env.Println("Agent is healthy.")
+}
@@ -5,16 +5,26 @@ package healthcheck
 import (
 	"testing"
 
+	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/stretchr/testify/require"
+
 	"github.com/spiffe/spire/test/spiretest"
 	"google.golang.org/grpc"
 )
 
 var (
 	usage = `Usage of health:
+  -healthURL string
+    	URL of the agent readiness endpoint, queried for the SVID expiry when -svidExpiryThreshold is set (default "http://localhost/ready")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
   -shallow
     	Perform a less stringent health check
   -socketPath string
     	Path to the Kirin Agent API socket (default "/tmp/kirin-agent/public/api.sock")
+  -svidExpiryThreshold duration
+    	Report the agent as unhealthy if its SVID expires within this amount of time (optional)
   -verbose
     	Print verbose information
 `
@@ -26,3 +36,20 @@ var (
 func startGRPCSocketServer(t *testing.T, registerFn func(srv *grpc.Server)) string {
 	return spiretest.StartGRPCServer(t, registerFn).String()
 }
+
+func TestHelpWithDefaultSocketPathOverride(t *testing.T) {
+	// Overridden as -ldflags -X would at build time
+	original := common.DefaultSocketPath
+	common.DefaultSocketPath = "/run/spire-agent/public/api.sock"
+	t.Cleanup(func() {
+		common.DefaultSocketPath = original
+	})
+
+	test := setupTest()
+	require.Empty(t, test.cmd.Help())
+	require.Contains(t, test.stderr.String(), `Path to the Kirin Agent API socket (default "/run/spire-agent/public/api.sock")`)
+
+	cmd := newHealthCheckCommand(common_cli.DefaultEnv)
+	require.NoError(t, cmd.parseFlags(nil))
+	require.Equal(t, "/run/spire-agent/public/api.sock", cmd.socketPath)
+}
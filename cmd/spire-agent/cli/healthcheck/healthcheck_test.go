@@ -3,7 +3,12 @@ package healthcheck
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/mitchellh/cli"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
@@ -77,6 +82,65 @@ func TestFailsOnUnavailableVerbose(t *testing.T) {
 	require.Equal(t, unavailableErr, test.stderr.String())
 }
 
+func TestFailsOnUnavailableJSON(t *testing.T) {
+	test := setupTest()
+
+	code := test.cmd.Run([]string{socketAddrArg, socketAddrUnavailable, "-output", "json"})
+	require.Equal(t, 1, code, "exit code")
+	require.Empty(t, test.stderr.String(), "stderr")
+
+	var results []healthCheckResult
+	require.NoError(t, json.Unmarshal(test.stdout.Bytes(), &results))
+	require.Len(t, results, 1)
+	result := results[0]
+	require.False(t, result.Healthy)
+	require.Equal(t, "unable to determine health", result.Error)
+	require.Len(t, result.Checks, 2)
+	require.Equal(t, &subCheckResult{Name: "agent_address", Healthy: true}, result.Checks[0])
+	require.Equal(t, "health_service", result.Checks[1].Name)
+	require.False(t, result.Checks[1].Healthy)
+	require.Contains(t, result.Checks[1].Error, "code = Unavailable")
+}
+
+func TestSucceedsIfServingStatusServingJSON(t *testing.T) {
+	test := setupTest()
+
+	socketAddr := startGRPCSocketServer(t, func(srv *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(srv, withStatus(grpc_health_v1.HealthCheckResponse_SERVING))
+	})
+	code := test.cmd.Run([]string{socketAddrArg, socketAddr, "-output", "json"})
+	require.Equal(t, 0, code, "exit code")
+	require.JSONEq(t, `[{
+		"healthy": true,
+		"checks": [
+			{"name": "agent_address", "healthy": true},
+			{"name": "health_service", "healthy": true},
+			{"name": "serving_status", "healthy": true}
+		]
+	}]`, test.stdout.String(), "stdout")
+	require.Empty(t, test.stderr.String(), "stderr")
+}
+
+func TestFailsIfServiceStatusOtherJSON(t *testing.T) {
+	test := setupTest()
+
+	socketAddr := startGRPCSocketServer(t, func(srv *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(srv, withStatus(grpc_health_v1.HealthCheckResponse_NOT_SERVING))
+	})
+	code := test.cmd.Run([]string{socketAddrArg, socketAddr, "-output", "json"})
+	require.Equal(t, 1, code, "exit code")
+	require.JSONEq(t, `[{
+		"healthy": false,
+		"error": "agent returned status \"NOT_SERVING\"",
+		"checks": [
+			{"name": "agent_address", "healthy": true},
+			{"name": "health_service", "healthy": true},
+			{"name": "serving_status", "healthy": false, "error": "agent returned status \"NOT_SERVING\""}
+		]
+	}]`, test.stdout.String(), "stdout")
+	require.Empty(t, test.stderr.String(), "stderr")
+}
+
 func TestSucceedsIfServingStatusServing(t *testing.T) {
 	test := setupTest()
 
@@ -116,6 +180,85 @@ func TestFailsIfServiceStatusOther(t *testing.T) {
 `, test.stderr.String(), "stderr")
 }
 
+func TestSVIDExpiryThreshold(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	readyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintf(w, `{"agent":{"svid_expires_at":%q}}`, expiresAt.Format(time.RFC3339))
+	}))
+	t.Cleanup(readyServer.Close)
+	noExpiryServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"agent":{}}`))
+	}))
+	t.Cleanup(noExpiryServer.Close)
+
+	socketAddr := startGRPCSocketServer(t, func(srv *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(srv, withStatus(grpc_health_v1.HealthCheckResponse_SERVING))
+	})
+
+	for _, tt := range []struct {
+		name        string
+		threshold   string
+		healthURL   string
+		expectCode  int
+		expectCheck *subCheckResult
+	}{
+		{
+			name:        "expiry beyond the threshold",
+			threshold:   "30m",
+			healthURL:   readyServer.URL,
+			expectCheck: &subCheckResult{Name: "svid_expiry", Healthy: true},
+		},
+		{
+			name:       "expiry within the threshold",
+			threshold:  "2h",
+			healthURL:  readyServer.URL,
+			expectCode: 1,
+			expectCheck: &subCheckResult{
+				Name:  "svid_expiry",
+				Error: fmt.Sprintf("agent SVID expires at %s, within the 2h0m0s threshold", expiresAt.Format(time.RFC3339)),
+			},
+		},
+		{
+			name:       "expiry not reported",
+			threshold:  "30m",
+			healthURL:  noExpiryServer.URL,
+			expectCode: 1,
+			expectCheck: &subCheckResult{
+				Name:  "svid_expiry",
+				Error: "agent did not report its SVID expiration time",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest()
+
+			code := test.cmd.Run([]string{socketAddrArg, socketAddr, "-svidExpiryThreshold", tt.threshold, "-healthURL", tt.healthURL, "-output", "json"})
+			require.Equal(t, tt.expectCode, code, "exit code")
+			require.Empty(t, test.stderr.String(), "stderr")
+
+			var results []healthCheckResult
+			require.NoError(t, json.Unmarshal(test.stdout.Bytes(), &results))
+			require.Len(t, results, 1)
+			require.Equal(t, tt.expectCode == 0, results[0].Healthy)
+			require.Len(t, results[0].Checks, 4)
+			require.Equal(t, tt.expectCheck, results[0].Checks[3])
+		})
+	}
+}
+
+func TestSVIDExpiryNotCheckedByDefault(t *testing.T) {
+	test := setupTest()
+
+	socketAddr := startGRPCSocketServer(t, func(srv *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(srv, withStatus(grpc_health_v1.HealthCheckResponse_SERVING))
+	})
+	// The readiness endpoint is not queried, so an unreachable URL is fine
+	code := test.cmd.Run([]string{socketAddrArg, socketAddr, "-healthURL", "http://127.0.0.1:1/ready"})
+	require.Equal(t, 0, code, "exit code")
+	require.Equal(t, "Agent is healthy.\n", test.stdout.String(), "stdout")
+	require.Empty(t, test.stderr.String(), "stderr")
+}
+
 func withStatus(status grpc_health_v1.HealthCheckResponse_ServingStatus) healthServer {
 	return healthServer{status: status}
 }
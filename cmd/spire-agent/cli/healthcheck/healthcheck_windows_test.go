@@ -5,17 +5,26 @@ package healthcheck
 import (
 	"testing"
 
+	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
+	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/namedpipe"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 )
 
 var (
 	usage = `Usage of health:
+  -healthURL string
+    	URL of the agent readiness endpoint, queried for the SVID expiry when -svidExpiryThreshold is set (default "http://localhost/ready")
   -namedPipeName string
     	Pipe name of the SPIRE Agent API named pipe (default "\\spire-agent\\public\\api")
+  -output value
+    	Desired output format (pretty, json); default: pretty.
   -shallow
     	Perform a less stringent health check
+  -svidExpiryThreshold duration
+    	Report the agent as unhealthy if its SVID expires within this amount of time (optional)
   -verbose
     	Print verbose information
 `
@@ -27,3 +36,35 @@ var (
 func startGRPCSocketServer(t *testing.T, registerFn func(srv *grpc.Server)) string {
 	return namedpipe.GetPipeName(spiretest.StartGRPCServer(t, registerFn).String())
 }
+
+func TestGetAddrResolvesNamedPipeName(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		args         []string
+		expectedAddr string
+	}{
+		{
+			name:         "default",
+			expectedAddr: namedpipe.AddrFromName(common.DefaultNamedPipeName).String(),
+		},
+		{
+			name:         "bare name",
+			args:         []string{"-namedPipeName", "foo"},
+			expectedAddr: "\\\\.\\pipe\\foo",
+		},
+		{
+			name:         "full pipe path",
+			args:         []string{"-namedPipeName", "\\\\.\\pipe\\foo"},
+			expectedAddr: "\\\\.\\pipe\\foo",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newHealthCheckCommand(common_cli.DefaultEnv)
+			require.NoError(t, cmd.parseFlags(tt.args))
+
+			addr, err := cmd.getAddr()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedAddr, addr.String())
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package run
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spiffe/spire/pkg/common/catalog"
+	"github.com/spiffe/spire/pkg/common/fflag"
+	"github.com/spiffe/spire/pkg/common/log"
+)
+
+const (
+	sameDefaultBundleNamesWarning = `The "default_bundle_name" and "default_all_bundles_name" configurables have the same value. "default_all_bundles_name" will be ignored. Please configure distinct values or use the defaults. This will be a configuration error in a future release.`
+	experimentalFeaturesWarning   = "Experimental features have been enabled. Please see doc/upgrading.md for upgrade and compatibility considerations for experimental features."
+)
+
+// Severity tells whether a finding prevents the agent from starting.
+type Severity string
+
+const (
+	// SeverityError findings prevent the agent from starting.
+	SeverityError Severity = "error"
+
+	// SeverityWarning findings are logged when the agent starts.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a problem found in the agent configuration.
+type Finding struct {
+	// Path is the dot-separated path of the configurable the finding is
+	// about, e.g. "agent.server_port", or empty if it is about the
+	// configuration as a whole.
+	Path     string   `json:"path"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+func newErrorFinding(path, msg string) Finding {
+	return Finding{Path: path, Severity: SeverityError, Message: msg}
+}
+
+func newWarningFinding(path, msg string) Finding {
+	return Finding{Path: path, Severity: SeverityWarning, Message: msg}
+}
+
+// ValidateConfig returns every problem found in the configuration, instead
+// of stopping at the first one like NewAgentConfig does. The configuration
+// is only fully loaded, reading the trust bundle and opening the log file
+// among others, if no other error is found, so that the problems reported
+// that way don't mask each other.
+func ValidateConfig(c *Config) []Finding {
+	var findings []Finding
+	if c.Plugins == nil {
+		findings = append(findings, newErrorFinding("plugins", "plugins section must be configured"))
+	} else if _, err := catalog.PluginConfigsFromHCLNode(c.Plugins); err != nil {
+		findings = append(findings, newErrorFinding("plugins", err.Error()))
+	}
+
+	findings = append(findings, c.Agent.findings()...)
+
+	if c.Agent != nil {
+		if err := fflag.Validate(c.Agent.Experimental.Flags); err != nil {
+			findings = append(findings, newErrorFinding("agent.experimental.feature_flags", fmt.Sprintf("error loading feature flags: %v", err)))
+		}
+	}
+
+	for _, section := range findUnknownConfig(c) {
+		findings = append(findings, newErrorFinding(section.path, fmt.Sprintf("unknown configuration detected: %s", strings.Join(section.keys, ", "))))
+	}
+
+	if !HasErrorFindings(findings) {
+		if _, err := NewAgentConfig(c, []log.Option{log.WithOutputFile(os.DevNull)}, false); err != nil {
+			findings = append(findings, newErrorFinding("", err.Error()))
+		}
+	}
+
+	return findings
+}
+
+// HasErrorFindings returns true if any of the findings is an error.
+func HasErrorFindings(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
@@ -154,38 +154,52 @@ func (cmd *Command) Help() string {
 
 // Help is a standalone function that prints a help message to writer.
 // It is used by both the run and validate commands, so they can share flag usage messages.
-func Help(name string, writer io.Writer) string {
-	_, err := parseFlags(name, []string{"-h"}, writer)
+func Help(name string, writer io.Writer, extraFlags ...func(*flag.FlagSet)) string {
+	_, err := parseFlags(name, []string{"-h"}, writer, extraFlags...)
 	// Error is always present because -h is passed
 	return err.Error()
 }
 
 func LoadConfig(name string, args []string, logOptions []log.Option, output io.Writer, allowUnknownConfig bool) (*agent.Config, error) {
-	// First parse the CLI flags so we can get the config
-	// file path, if set
-	cliInput, err := parseFlags(name, args, output)
+	input, err := LoadInput(name, args, output)
 	if err != nil {
 		return nil, err
 	}
 
-	// Load and parse the config file using either the default
-	// path or CLI-specified value
-	fileInput, err := ParseFile(cliInput.ConfigPath, cliInput.ExpandEnv)
+	return LoadConfigFromInput(input, logOptions, allowUnknownConfig)
+}
+
+// LoadConfigFromInput loads the feature flags set in the input, and creates
+// the agent config from it.
+func LoadConfigFromInput(input *Config, logOptions []log.Option, allowUnknownConfig bool) (*agent.Config, error) {
+	err := fflag.Load(input.Agent.Experimental.Flags)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error loading feature flags: %w", err)
 	}
 
-	input, err := mergeInput(fileInput, cliInput)
+	return NewAgentConfig(input, logOptions, allowUnknownConfig)
+}
+
+// LoadInput parses the CLI flags and the config file they refer to, and
+// merges them into the configuration the agent config is created from. The
+// extra flags are added to the flag set, for commands that have flags of
+// their own on top of the configuration ones.
+func LoadInput(name string, args []string, output io.Writer, extraFlags ...func(*flag.FlagSet)) (*Config, error) {
+	// First parse the CLI flags so we can get the config
+	// file path, if set
+	cliInput, err := parseFlags(name, args, output, extraFlags...)
 	if err != nil {
 		return nil, err
 	}
 
-	err = fflag.Load(input.Agent.Experimental.Flags)
+	// Load and parse the config file using either the default
+	// path or CLI-specified value
+	fileInput, err := ParseFile(cliInput.ConfigPath, cliInput.ExpandEnv)
 	if err != nil {
-		return nil, fmt.Errorf("error loading feature flags: %w", err)
+		return nil, err
 	}
 
-	return NewAgentConfig(input, logOptions, allowUnknownConfig)
+	return mergeInput(fileInput, cliInput)
 }
 
 func (cmd *Command) Run(args []string) int {
@@ -224,20 +238,36 @@ func (*Command) Synopsis() string {
 }
 
 func (c *agentConfig) validate() error {
+	for _, finding := range c.findings() {
+		if finding.Severity == SeverityError {
+			return errors.New(finding.Message)
+		}
+	}
+	return nil
+}
+
+// findings returns the problems found in the agent section that can be
+// detected without loading anything the configuration refers to.
+func (c *agentConfig) findings() []Finding {
 	if c == nil {
-		return errors.New("agent section must be configured")
+		return []Finding{newErrorFinding("agent", "agent section must be configured")}
+	}
+
+	var findings []Finding
+	addError := func(path, msg string) {
+		findings = append(findings, newErrorFinding(path, msg))
 	}
 
 	if c.ServerAddress == "" {
-		return errors.New("server_address must be configured")
+		addError("agent.server_address", "server_address must be configured")
 	}
 
 	if c.ServerPort == 0 {
-		return errors.New("server_port must be configured")
+		addError("agent.server_port", "server_port must be configured")
 	}
 
 	if c.TrustDomain == "" {
-		return errors.New("trust_domain must be configured")
+		addError("agent.trust_domain", "trust_domain must be configured")
 	}
 
 	// If insecure_bootstrap is set, trust_bundle_path or trust_bundle_url cannot be set
@@ -245,38 +275,115 @@ func (c *agentConfig) validate() error {
 	// If trust_bundle_path is set, parse the trust bundle file on disk
 	// Both cannot be set
 	// The trust bundle URL must start with HTTPS
-	if c.InsecureBootstrap {
-		switch {
-		case c.TrustBundleURL != "" && c.TrustBundlePath != "":
-			return errors.New("only one of insecure_bootstrap, trust_bundle_url, or trust_bundle_path can be specified, not the three options")
-		case c.TrustBundleURL != "":
-			return errors.New("only one of insecure_bootstrap or trust_bundle_url can be specified, not both")
-		case c.TrustBundlePath != "":
-			return errors.New("only one of insecure_bootstrap or trust_bundle_path can be specified, not both")
-		}
-	} else if c.TrustBundlePath == "" && c.TrustBundleURL == "" {
-		return errors.New("trust_bundle_path or trust_bundle_url must be configured unless insecure_bootstrap is set")
-	}
-
-	if c.TrustBundleURL != "" && c.TrustBundlePath != "" {
-		return errors.New("only one of trust_bundle_url or trust_bundle_path can be specified, not both")
+	switch {
+	case c.InsecureBootstrap && c.TrustBundleURL != "" && c.TrustBundlePath != "":
+		addError("agent.insecure_bootstrap", "only one of insecure_bootstrap, trust_bundle_url, or trust_bundle_path can be specified, not the three options")
+	case c.InsecureBootstrap && c.TrustBundleURL != "":
+		addError("agent.insecure_bootstrap", "only one of insecure_bootstrap or trust_bundle_url can be specified, not both")
+	case c.InsecureBootstrap && c.TrustBundlePath != "":
+		addError("agent.insecure_bootstrap", "only one of insecure_bootstrap or trust_bundle_path can be specified, not both")
+	case !c.InsecureBootstrap && c.TrustBundlePath == "" && c.TrustBundleURL == "":
+		addError("agent.trust_bundle_path", "trust_bundle_path or trust_bundle_url must be configured unless insecure_bootstrap is set")
+	case c.TrustBundleURL != "" && c.TrustBundlePath != "":
+		addError("agent.trust_bundle_url", "only one of trust_bundle_url or trust_bundle_path can be specified, not both")
 	}
 
 	if c.TrustBundleFormat != bundleFormatPEM && c.TrustBundleFormat != bundleFormatSPIFFE {
-		return fmt.Errorf("invalid value for trust_bundle_format, expected %q or %q", bundleFormatPEM, bundleFormatSPIFFE)
+		addError("agent.trust_bundle_format", fmt.Sprintf("invalid value for trust_bundle_format, expected %q or %q", bundleFormatPEM, bundleFormatSPIFFE))
 	}
 
 	if c.TrustBundleURL != "" {
 		u, err := url.Parse(c.TrustBundleURL)
-		if err != nil {
-			return fmt.Errorf("unable to parse trust bundle URL: %w", err)
+		switch {
+		case err != nil:
+			addError("agent.trust_bundle_url", fmt.Sprintf("unable to parse trust bundle URL: %v", err))
+		case u.Scheme != "https":
+			addError("agent.trust_bundle_url", "trust bundle URL must start with https://")
 		}
-		if u.Scheme != "https" {
-			return errors.New("trust bundle URL must start with https://")
+	}
+
+	if err := c.validateOS(); err != nil {
+		addError("agent", err.Error())
+	}
+
+	if c.X509SVIDCacheMaxSize < 0 {
+		addError("agent.x509_svid_cache_max_size", "x509_svid_cache_max_size should not be negative")
+	}
+
+	if c.JWTSVIDCacheMaxSize < 0 {
+		addError("agent.jwt_svid_cache_max_size", "jwt_svid_cache_max_size should not be negative")
+	}
+
+	if _, err := c.syncInterval(); err != nil {
+		addError("agent.experimental.sync_interval", err.Error())
+	}
+
+	if _, err := c.workloadKeyType(); err != nil {
+		addError("agent.workload_x509_svid_key_type", err.Error())
+	}
+
+	if _, err := c.availabilityTarget(); err != nil {
+		addError("agent.availability_target", err.Error())
+	}
+
+	if c.TrustDomain != "" {
+		if td, err := spiffeid.TrustDomainFromString(c.TrustDomain); err != nil {
+			addError("agent.trust_domain", fmt.Sprintf("could not parse trust_domain %q: %v", c.TrustDomain, err))
+		} else if err := c.validateAuthorizedDelegates(td); err != nil {
+			addError("agent.authorized_delegates", err.Error())
 		}
 	}
 
-	return c.validateOS()
+	if c.SDS.DefaultAllBundlesName == c.SDS.DefaultBundleName {
+		findings = append(findings, newWarningFinding("agent.sds.default_all_bundles_name", sameDefaultBundleNamesWarning))
+	}
+
+	if cmp.Diff(experimentalConfig{}, c.Experimental) != "" {
+		findings = append(findings, newWarningFinding("agent.experimental", experimentalFeaturesWarning))
+	}
+
+	return findings
+}
+
+func (c *agentConfig) syncInterval() (time.Duration, error) {
+	if c.Experimental.SyncInterval == "" {
+		return 0, nil
+	}
+	syncInterval, err := time.ParseDuration(c.Experimental.SyncInterval)
+	if err != nil {
+		return 0, fmt.Errorf("could not parse synchronization interval: %w", err)
+	}
+	return syncInterval, nil
+}
+
+func (c *agentConfig) workloadKeyType() (workloadkey.KeyType, error) {
+	if c.WorkloadX509SVIDKeyType == "" {
+		return workloadkey.ECP256, nil
+	}
+	return workloadkey.KeyTypeFromString(c.WorkloadX509SVIDKeyType)
+}
+
+func (c *agentConfig) availabilityTarget() (time.Duration, error) {
+	if c.AvailabilityTarget == "" {
+		return 0, nil
+	}
+	t, err := time.ParseDuration(c.AvailabilityTarget)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse availability_target: %w", err)
+	}
+	if t < minimumAvailabilityTarget {
+		return 0, fmt.Errorf("availability_target must be at least %s", minimumAvailabilityTarget.String())
+	}
+	return t, nil
+}
+
+func (c *agentConfig) validateAuthorizedDelegates(td spiffeid.TrustDomain) error {
+	for _, authorizedDelegate := range c.AuthorizedDelegates {
+		if _, err := idutil.MemberFromString(td, authorizedDelegate); err != nil {
+			return fmt.Errorf("error validating authorized delegate: %w", err)
+		}
+	}
+	return nil
 }
 
 func ParseFile(path string, expandEnv bool) (*Config, error) {
@@ -315,7 +422,7 @@ func ParseFile(path string, expandEnv bool) (*Config, error) {
 	return c, nil
 }
 
-func parseFlags(name string, args []string, output io.Writer) (*agentConfig, error) {
+func parseFlags(name string, args []string, output io.Writer, extraFlags ...func(*flag.FlagSet)) (*agentConfig, error) {
 	flags := flag.NewFlagSet(name, flag.ContinueOnError)
 	flags.SetOutput(output)
 	c := &agentConfig{}
@@ -339,6 +446,9 @@ func parseFlags(name string, args []string, output io.Writer) (*agentConfig, err
 	flags.BoolVar(&c.ExpandEnv, "expandEnv", false, "Expand environment variables in Kirin config file")
 
 	c.addOSFlags(flags)
+	for _, addFlags := range extraFlags {
+		addFlags(flags)
+	}
 
 	err := flags.Parse(args)
 	if err != nil {
@@ -460,13 +570,11 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 
 	ac.RetryBootstrap = c.Agent.RetryBootstrap
 
-	if c.Agent.Experimental.SyncInterval != "" {
-		var err error
-		ac.SyncInterval, err = time.ParseDuration(c.Agent.Experimental.SyncInterval)
-		if err != nil {
-			return nil, fmt.Errorf("could not parse synchronization interval: %w", err)
-		}
+	syncInterval, err := c.Agent.syncInterval()
+	if err != nil {
+		return nil, err
 	}
+	ac.SyncInterval = syncInterval
 
 	ac.UseSyncAuthorizedEntries = c.Agent.Experimental.UseSyncAuthorizedEntries
 
@@ -499,14 +607,7 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 		ac.LogReopener = log.ReopenOnSignal(logger, reopenableFile)
 	}
 
-	if c.Agent.X509SVIDCacheMaxSize < 0 {
-		return nil, errors.New("x509_svid_cache_max_size should not be negative")
-	}
 	ac.X509SVIDCacheMaxSize = c.Agent.X509SVIDCacheMaxSize
-
-	if c.Agent.JWTSVIDCacheMaxSize < 0 {
-		return nil, errors.New("jwt_svid_cache_max_size should not be negative")
-	}
 	ac.JWTSVIDCacheMaxSize = c.Agent.JWTSVIDCacheMaxSize
 
 	td, err := common_cli.ParseTrustDomain(c.Agent.TrustDomain, logger)
@@ -534,7 +635,7 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	ac.DefaultBundleName = c.Agent.SDS.DefaultBundleName
 	ac.DefaultAllBundlesName = c.Agent.SDS.DefaultAllBundlesName
 	if ac.DefaultAllBundlesName == ac.DefaultBundleName {
-		logger.Warn(`The "default_bundle_name" and "default_all_bundles_name" configurables have the same value. "default_all_bundles_name" will be ignored. Please configure distinct values or use the defaults. This will be a configuration error in a future release.`)
+		logger.Warn(sameDefaultBundleNamesWarning)
 	}
 	ac.DisableSPIFFECertValidation = c.Agent.SDS.DisableSPIFFECertValidation
 
@@ -543,12 +644,9 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 		return nil, err
 	}
 
-	ac.WorkloadKeyType = workloadkey.ECP256
-	if c.Agent.WorkloadX509SVIDKeyType != "" {
-		ac.WorkloadKeyType, err = workloadkey.KeyTypeFromString(c.Agent.WorkloadX509SVIDKeyType)
-		if err != nil {
-			return nil, err
-		}
+	ac.WorkloadKeyType, err = c.Agent.workloadKeyType()
+	if err != nil {
+		return nil, err
 	}
 
 	ac.ProfilingEnabled = c.Agent.ProfilingEnabled
@@ -574,23 +672,15 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 
 	ac.AllowUnauthenticatedVerifiers = c.Agent.AllowUnauthenticatedVerifiers
 
-	for _, authorizedDelegate := range c.Agent.AuthorizedDelegates {
-		if _, err := idutil.MemberFromString(ac.TrustDomain, authorizedDelegate); err != nil {
-			return nil, fmt.Errorf("error validating authorized delegate: %w", err)
-		}
+	if err := c.Agent.validateAuthorizedDelegates(ac.TrustDomain); err != nil {
+		return nil, err
 	}
 
 	ac.AuthorizedDelegates = c.Agent.AuthorizedDelegates
 
-	if c.Agent.AvailabilityTarget != "" {
-		t, err := time.ParseDuration(c.Agent.AvailabilityTarget)
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse availability_target: %w", err)
-		}
-		if t < minimumAvailabilityTarget {
-			return nil, fmt.Errorf("availability_target must be at least %s", minimumAvailabilityTarget.String())
-		}
-		ac.AvailabilityTarget = t
+	ac.AvailabilityTarget, err = c.Agent.availabilityTarget()
+	if err != nil {
+		return nil, err
 	}
 
 	ac.TLSPolicy = tlspolicy.Policy{
@@ -600,7 +690,7 @@ func NewAgentConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool)
 	tlspolicy.LogPolicy(ac.TLSPolicy, log.NewHCLogAdapter(logger, "tlspolicy"))
 
 	if cmp.Diff(experimentalConfig{}, c.Agent.Experimental) != "" {
-		logger.Warn("Experimental features have been enabled. Please see doc/upgrading.md for upgrade and compatibility considerations for experimental features.")
+		logger.Warn(experimentalFeaturesWarning)
 	}
 
 	for _, f := range c.Agent.Experimental.Flags {
@@ -619,66 +709,85 @@ func validateConfig(c *Config) error {
 }
 
 func checkForUnknownConfig(c *Config, l logrus.FieldLogger) (err error) {
-	detectedUnknown := func(section string, keyPositions map[string][]token.Pos) {
+	for _, section := range findUnknownConfig(c) {
+		l.WithFields(logrus.Fields{
+			"section": section.name,
+			"keys":    strings.Join(section.keys, ","),
+		}).Error("Unknown configuration detected")
+		err = errors.New("unknown configuration detected")
+	}
+	return err
+}
+
+// unknownConfigSection holds the unknown keys found in a config section.
+type unknownConfigSection struct {
+	// name is the name of the section in logs
+	name string
+
+	// path is the path of the section in the config file
+	path string
+
+	keys []string
+}
+
+func findUnknownConfig(c *Config) []unknownConfigSection {
+	var sections []unknownConfigSection
+	detectedUnknown := func(name, path string, keyPositions map[string][]token.Pos) {
 		var keys []string
 		for k := range keyPositions {
 			keys = append(keys, k)
 		}
 
 		sort.Strings(keys)
-		l.WithFields(logrus.Fields{
-			"section": section,
-			"keys":    strings.Join(keys, ","),
-		}).Error("Unknown configuration detected")
-		err = errors.New("unknown configuration detected")
+		sections = append(sections, unknownConfigSection{name: name, path: path, keys: keys})
 	}
 
 	if len(c.UnusedKeyPositions) != 0 {
-		detectedUnknown("top-level", c.UnusedKeyPositions)
+		detectedUnknown("top-level", "", c.UnusedKeyPositions)
 	}
 
 	if a := c.Agent; a != nil && len(a.UnusedKeyPositions) != 0 {
-		detectedUnknown("agent", a.UnusedKeyPositions)
+		detectedUnknown("agent", "agent", a.UnusedKeyPositions)
 	}
 
 	// TODO: Re-enable unused key detection for telemetry. See
 	// https://github.com/spiffe/spire/issues/1101 for more information
 	//
 	// if len(c.Telemetry.UnusedKeyPositions) != 0 {
-	//	detectedUnknown("telemetry", c.Telemetry.UnusedKeyPositions)
+	//	detectedUnknown("telemetry", "telemetry", c.Telemetry.UnusedKeyPositions)
 	// }
 
 	if p := c.Telemetry.Prometheus; p != nil && len(p.UnusedKeyPositions) != 0 {
-		detectedUnknown("Prometheus", p.UnusedKeyPositions)
+		detectedUnknown("Prometheus", "telemetry.Prometheus", p.UnusedKeyPositions)
 	}
 
 	for _, v := range c.Telemetry.DogStatsd {
 		if len(v.UnusedKeyPositions) != 0 {
-			detectedUnknown("DogStatsd", v.UnusedKeyPositions)
+			detectedUnknown("DogStatsd", "telemetry.DogStatsd", v.UnusedKeyPositions)
 		}
 	}
 
 	for _, v := range c.Telemetry.Statsd {
 		if len(v.UnusedKeyPositions) != 0 {
-			detectedUnknown("Statsd", v.UnusedKeyPositions)
+			detectedUnknown("Statsd", "telemetry.Statsd", v.UnusedKeyPositions)
 		}
 	}
 
 	for _, v := range c.Telemetry.M3 {
 		if len(v.UnusedKeyPositions) != 0 {
-			detectedUnknown("M3", v.UnusedKeyPositions)
+			detectedUnknown("M3", "telemetry.M3", v.UnusedKeyPositions)
 		}
 	}
 
 	if p := c.Telemetry.InMem; p != nil && len(p.UnusedKeyPositions) != 0 {
-		detectedUnknown("InMem", p.UnusedKeyPositions)
+		detectedUnknown("InMem", "telemetry.InMem", p.UnusedKeyPositions)
 	}
 
 	if len(c.HealthChecks.UnusedKeyPositions) != 0 {
-		detectedUnknown("health check", c.HealthChecks.UnusedKeyPositions)
+		detectedUnknown("health check", "health_checks", c.HealthChecks.UnusedKeyPositions)
 	}
 
-	return err
+	return sections
 }
 
 func defaultConfig() *Config {
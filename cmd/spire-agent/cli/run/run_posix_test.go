@@ -9,6 +9,7 @@ import (
 	"path"
 	"testing"
 
+	"github.com/spiffe/spire/cmd/spire-agent/cli/common"
 	"github.com/spiffe/spire/pkg/agent"
 	"github.com/spiffe/spire/pkg/common/catalog"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
@@ -168,6 +169,18 @@ func TestParseFlagsGood(t *testing.T) {
 	assert.Equal(t, c.AllowUnauthenticatedVerifiers, true)
 }
 
+func TestDefaultSocketPathOverride(t *testing.T) {
+	// Overridden as -ldflags -X would at build time
+	original := common.DefaultSocketPath
+	common.DefaultSocketPath = "/run/spire-agent/public/api.sock"
+	t.Cleanup(func() {
+		common.DefaultSocketPath = original
+	})
+
+	c := defaultConfig()
+	require.Equal(t, "/run/spire-agent/public/api.sock", c.Agent.SocketPath)
+}
+
 func TestParseConfigGood(t *testing.T) {
 	c, err := ParseFile("../../../../test/fixture/config/agent_good_posix.conf", false)
 	require.NoError(t, err)
@@ -1,6 +1,8 @@
 package run
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -1253,6 +1255,65 @@ func TestLogOptions(t *testing.T) {
 	require.Equal(t, fd.Name(), logger.Out.(*log.ReopenableFile).Name())
 }
 
+// TestLogFormatOutput verifies the lines written by the agent logger for each
+// log_format value
+func TestLogFormatOutput(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		logFormat string
+	}{
+		{name: "default", logFormat: log.DefaultFormat},
+		{name: "text", logFormat: "text"},
+		{name: "json", logFormat: "json"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			logOptions := []log.Option{
+				func(logger *log.Logger) error {
+					logger.SetOutput(buffer)
+					return nil
+				},
+			}
+
+			config := defaultValidConfig()
+			config.Agent.LogFormat = tt.logFormat
+			agentConfig, err := NewAgentConfig(config, logOptions, false)
+			require.NoError(t, err)
+
+			agentConfig.Log.WithField("trust_domain", "example.org").Info("Agent started")
+			agentConfig.Log.Warn("Agent is shutting down")
+
+			lines := strings.Split(strings.TrimSuffix(buffer.String(), "\n"), "\n")
+			require.Len(t, lines, 2)
+
+			if !strings.EqualFold(tt.logFormat, log.JSONFormat) {
+				require.Regexp(t, `^time=.+ level=info msg="Agent started" trust_domain=example.org$`, lines[0])
+				require.Regexp(t, `^time=.+ level=warning msg="Agent is shutting down"$`, lines[1])
+				return
+			}
+
+			var first map[string]string
+			require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+			require.Contains(t, first, "time")
+			delete(first, "time")
+			require.Equal(t, map[string]string{
+				"level":        "info",
+				"msg":          "Agent started",
+				"trust_domain": "example.org",
+			}, first)
+
+			var second map[string]string
+			require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+			require.Contains(t, second, "time")
+			delete(second, "time")
+			require.Equal(t, map[string]string{
+				"level": "warning",
+				"msg":   "Agent is shutting down",
+			}, second)
+		})
+	}
+}
+
 func TestExpandEnv(t *testing.T) {
 	require.NoError(t, os.Setenv("TEST_DATA_TRUST_DOMAIN", "example.org"))
 
@@ -271,6 +271,25 @@ func newAgentConfigCasesOS(*testing.T) []newAgentConfigCase {
 				require.Equal(t, "pipe", c.BindAddress.(*namedpipe.Addr).Network())
 			},
 		},
+		{
+			msg: "named_pipe_name should accept the full pipe path",
+			input: func(c *Config) {
+				c.Agent.Experimental.NamedPipeName = "\\\\.\\pipe\\foo"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, "\\\\.\\pipe\\foo", c.BindAddress.String())
+				require.Equal(t, "foo", c.BindAddress.(*namedpipe.Addr).PipeName())
+			},
+		},
+		{
+			msg: "admin_named_pipe_name should accept the full pipe path",
+			input: func(c *Config) {
+				c.Agent.Experimental.AdminNamedPipeName = "\\\\.\\pipe\\spire-agent\\private\\admin"
+			},
+			test: func(t *testing.T, c *agent.Config) {
+				require.Equal(t, "\\\\.\\pipe\\spire-agent\\private\\admin", c.AdminBindAddress.String())
+			},
+		},
 		{
 			msg: "admin_named_pipe_name not provided",
 			input: func(c *Config) {
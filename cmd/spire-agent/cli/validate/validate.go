@@ -1,12 +1,21 @@
 package validate
 
 import (
+	"encoding/json"
+	"flag"
+	"fmt"
+
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-agent/cli/run"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 )
 
-const commandName = "validate"
+const (
+	commandName = "validate"
+
+	outputPretty = "pretty"
+	outputJSON   = "json"
+)
 
 func NewValidateCommand() cli.Command {
 	return newValidateCommand(common_cli.DefaultEnv)
@@ -20,19 +29,39 @@ func newValidateCommand(env *common_cli.Env) *validateCommand {
 
 type validateCommand struct {
 	env *common_cli.Env
+
+	output string
 }
 
 // Help prints the agent cmd usage
 func (c *validateCommand) Help() string {
-	return run.Help(commandName, c.env.Stderr)
+	return run.Help(commandName, c.env.Stderr, c.addFlags)
 }
 
 func (c *validateCommand) Synopsis() string {
 	return "Validates agent configuration file"
 }
 
+func (c *validateCommand) addFlags(flags *flag.FlagSet) {
+	flags.StringVar(&c.output, "output", outputPretty, fmt.Sprintf("Desired output format (%s, %s). With %s, every problem found is reported as a list of findings", outputPretty, outputJSON, outputJSON))
+}
+
 func (c *validateCommand) Run(args []string) int {
-	if _, err := run.LoadConfig(commandName, args, nil, c.env.Stderr, false); err != nil {
+	input, err := run.LoadInput(commandName, args, c.env.Stderr, c.addFlags)
+
+	switch c.output {
+	case outputPretty:
+	case outputJSON:
+		return c.reportFindings(input, err)
+	default:
+		_ = c.env.ErrPrintf("Unknown output format %q; expected %s or %s\n", c.output, outputPretty, outputJSON)
+		return 1
+	}
+
+	if err == nil {
+		_, err = run.LoadConfigFromInput(input, nil, false)
+	}
+	if err != nil {
 		// Ignore error since a failure to write to stderr cannot very well be reported
 		_ = c.env.ErrPrintf("Kirin agent configuration file is invalid: %v\n", err)
 		return 1
@@ -40,3 +69,25 @@ func (c *validateCommand) Run(args []string) int {
 	_ = c.env.Println("Kirin agent configuration file is valid.")
 	return 0
 }
+
+// reportFindings prints every problem found in the configuration as a JSON
+// list of findings. The configuration is only invalid if any of them is an
+// error.
+func (c *validateCommand) reportFindings(input *run.Config, loadErr error) int {
+	findings := []run.Finding{}
+	if loadErr != nil {
+		// The flags or the config file could not be parsed, so there is
+		// nothing else to check
+		findings = append(findings, run.Finding{Severity: run.SeverityError, Message: loadErr.Error()})
+	} else {
+		findings = append(findings, run.ValidateConfig(input)...)
+	}
+
+	if err := json.NewEncoder(c.env.Stdout).Encode(findings); err != nil {
+		return 1
+	}
+	if run.HasErrorFindings(findings) {
+		return 1
+	}
+	return 0
+}
@@ -2,15 +2,20 @@ package validate
 
 import (
 	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-agent/cli/run"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/stretchr/testify/suite"
 )
 
 // NOTE: Since Run() in this package is a wrapper
-// using some functions in run package, Do not test here.
+// using some functions in run package, the validations themselves are not
+// tested here.
 
 func TestValidate(t *testing.T) {
 	suite.Run(t, new(ValidateSuite))
@@ -45,6 +50,7 @@ func (s *ValidateSuite) TestSynopsis() {
 func (s *ValidateSuite) TestHelp() {
 	s.Equal("flag: help requested", s.cmd.Help())
 	s.Contains(s.stderr.String(), "Usage of validate:", "stderr")
+	s.Contains(s.stderr.String(), "-output string", "stderr")
 }
 
 func (s *ValidateSuite) TestBadFlags() {
@@ -53,3 +59,86 @@ func (s *ValidateSuite) TestBadFlags() {
 	s.Equal("", s.stdout.String(), "stdout")
 	s.Contains(s.stderr.String(), "flag provided but not defined: -badflag", "stderr")
 }
+
+func (s *ValidateSuite) TestJSONOutputReportsEveryError() {
+	path := s.writeConfig(`
+agent {
+	trust_domain = "Example.org"
+	trust_bundle_format = "der"
+	insecure_bootstrap = true
+	x509_svid_cache_max_size = -1
+	unknown_key = "value"
+}
+`)
+
+	code := s.cmd.Run([]string{"-config", path, "-output", "json"})
+	s.Equal(1, code, "exit code")
+	s.JSONEq(`[
+		{"path": "plugins", "severity": "error", "message": "plugins section must be configured"},
+		{"path": "agent.server_address", "severity": "error", "message": "server_address must be configured"},
+		{"path": "agent.server_port", "severity": "error", "message": "server_port must be configured"},
+		{"path": "agent.trust_bundle_format", "severity": "error", "message": "invalid value for trust_bundle_format, expected \"pem\" or \"spiffe\""},
+		{"path": "agent.x509_svid_cache_max_size", "severity": "error", "message": "x509_svid_cache_max_size should not be negative"},
+		{"path": "agent.trust_domain", "severity": "error", "message": "could not parse trust_domain \"Example.org\": trust domain characters are limited to lowercase letters, numbers, dots, dashes, and underscores"},
+		{"path": "agent", "severity": "error", "message": "unknown configuration detected: unknown_key"}
+	]`, s.stdout.String())
+}
+
+func (s *ValidateSuite) TestJSONOutputWithOnlyWarnings() {
+	path := s.writeConfig(`
+agent {
+	server_address = "127.0.0.1"
+	server_port = 8081
+	trust_domain = "example.org"
+	insecure_bootstrap = true
+	sds {
+		default_bundle_name = "ALL"
+	}
+}
+plugins {}
+`)
+
+	code := s.cmd.Run([]string{"-config", path, "-output", "json"})
+	s.Equal(0, code, "exit code")
+	s.JSONEq(`[
+		{"path": "agent.sds.default_all_bundles_name", "severity": "warning", "message": "The \"default_bundle_name\" and \"default_all_bundles_name\" configurables have the same value. \"default_all_bundles_name\" will be ignored. Please configure distinct values or use the defaults. This will be a configuration error in a future release."}
+	]`, s.stdout.String())
+}
+
+func (s *ValidateSuite) TestJSONOutputWithUnreadableConfig() {
+	path := s.writeConfig(`agent {`)
+
+	code := s.cmd.Run([]string{"-config", path, "-output", "json"})
+	s.Equal(1, code, "exit code")
+
+	var findings []run.Finding
+	s.Require().NoError(json.Unmarshal(s.stdout.Bytes(), &findings))
+	s.Require().Len(findings, 1)
+	s.Equal(run.SeverityError, findings[0].Severity)
+	s.Contains(findings[0].Message, "unable to decode configuration")
+}
+
+func (s *ValidateSuite) TestPrettyOutputReportsFirstError() {
+	path := s.writeConfig(`
+agent {
+	trust_domain = "example.org"
+}
+`)
+
+	code := s.cmd.Run([]string{"-config", path})
+	s.Equal(1, code, "exit code")
+	s.Equal("", s.stdout.String(), "stdout")
+	s.Equal("Kirin agent configuration file is invalid: plugins section must be configured\n", s.stderr.String(), "stderr")
+}
+
+func (s *ValidateSuite) TestUnknownOutputFormat() {
+	code := s.cmd.Run([]string{"-config", s.writeConfig(""), "-output", "yaml"})
+	s.Equal(1, code, "exit code")
+	s.Equal("Unknown output format \"yaml\"; expected pretty or json\n", s.stderr.String(), "stderr")
+}
+
+func (s *ValidateSuite) writeConfig(config string) string {
+	path := filepath.Join(s.T().TempDir(), "agent.conf")
+	s.Require().NoError(os.WriteFile(path, []byte(config), 0o600))
+	return path
+}
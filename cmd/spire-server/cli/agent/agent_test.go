@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
@@ -13,6 +14,10 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/cli/agent"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/test/clitest"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
@@ -53,6 +58,8 @@ type agentTest struct {
 	args   []string
 	server *fakeAgentServer
 	client cli.Command
+
+	extensionServer *fakeAgentExtensionServer
 }
 
 func (s *agentTest) afterTest(t *testing.T) {
@@ -789,11 +796,273 @@ func TestShow(t *testing.T) {
 	}
 }
 
+func TestShowSerialHistory(t *testing.T) {
+	test := setupTest(t, agent.NewShowCommandWithEnv)
+	test.server.agents = testAgents
+	test.extensionServer.details = &extensionv1.GetAgentDetailsResponse{
+		SerialHistory: []*extensionv1.AgentSerial{
+			{SerialNumber: "serial-1", SupersededAt: 1700000100, Reason: "banned"},
+			{SerialNumber: "serial-0", SupersededAt: 1700000000, Reason: "rotated"},
+		},
+	}
+
+	returnCode := test.client.Run(append(test.args, "-spiffeID", "spiffe://example.org/spire/agent/agent1"))
+	require.Equal(t, 0, returnCode, test.stderr.String())
+	require.Contains(t, test.stdout.String(), `Previous serial   : serial-1 (banned at 2023-11-14T22:15:00Z)
+Previous serial   : serial-0 (rotated at 2023-11-14T22:13:20Z)
+`)
+	spiretest.AssertProtoEqual(t, &extensionv1.GetAgentDetailsRequest{
+		Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent1"},
+	}, test.extensionServer.gotGetAgentDetailsRequest)
+}
+
+func TestShowSelectorSources(t *testing.T) {
+	test := setupTest(t, agent.NewShowCommandWithEnv)
+	test.server.agents = testAgentsWithSelectors
+	test.extensionServer.details = &extensionv1.GetAgentDetailsResponse{
+		SelectorSources: []*extensionv1.AgentSelectorSource{
+			{Selector: &types.Selector{Type: "k8s_psat", Value: "agent_ns:spire"}, Source: "k8s_psat"},
+			{Selector: &types.Selector{Type: "k8s_psat", Value: "cluster:demo-cluster"}, Source: "k8s_psat"},
+		},
+	}
+
+	returnCode := test.client.Run(append(test.args, "-spiffeID", "spiffe://example.org/spire/agent/agent2"))
+	require.Equal(t, 0, returnCode, test.stderr.String())
+	require.Contains(t, test.stdout.String(), `Selectors         : k8s_psat:agent_ns:spire (source: k8s_psat)
+Selectors         : k8s_psat:agent_sa:spire-agent
+Selectors         : k8s_psat:cluster:demo-cluster (source: k8s_psat)
+`)
+}
+
+func TestShowAttestedTimes(t *testing.T) {
+	test := setupTest(t, agent.NewShowCommandWithEnv)
+	test.server.agents = testAgents
+	test.extensionServer.details = &extensionv1.GetAgentDetailsResponse{
+		AttestedAt:     1700000000,
+		LastAttestedAt: 1700003600,
+	}
+
+	returnCode := test.client.Run(append(test.args, "-spiffeID", "spiffe://example.org/spire/agent/agent1"))
+	require.Equal(t, 0, returnCode, test.stderr.String())
+	require.Contains(t, test.stdout.String(), `First attested    : 2023-11-14T22:13:20Z
+Last attested     : 2023-11-14T23:13:20Z
+`)
+}
+
+func TestShowDetailsError(t *testing.T) {
+	test := setupTest(t, agent.NewShowCommandWithEnv)
+	test.server.agents = testAgents
+	test.extensionServer.err = status.Error(codes.Internal, "internal server error")
+
+	returnCode := test.client.Run(append(test.args, "-spiffeID", "spiffe://example.org/spire/agent/agent1"))
+	require.Equal(t, 1, returnCode)
+	require.Equal(t, "Error: rpc error: code = Internal desc = internal server error\n", test.stderr.String())
+}
+
+func TestReattest(t *testing.T) {
+	for _, tt := range []struct {
+		name               string
+		args               []string
+		agents             []*types.Agent
+		updated            int32
+		expectedMatch      types.SelectorMatch_MatchBehavior
+		expectedSelectors  []*types.Selector
+		expectedSetRequest *extensionv1.SetAgentsCanReattestRequest
+		expectedStdout     string
+	}{
+		{
+			name: "can reattest",
+			args: []string{"-canReattest", "true", "-selector", "k8s_psat:cluster:a"},
+			agents: []*types.Agent{
+				{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent1"}},
+				{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent2"}},
+			},
+			updated:           2,
+			expectedMatch:     types.SelectorMatch_MATCH_SUPERSET,
+			expectedSelectors: []*types.Selector{{Type: "k8s_psat", Value: "cluster:a"}},
+			expectedSetRequest: &extensionv1.SetAgentsCanReattestRequest{
+				Ids: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/spire/agent/agent1"},
+					{TrustDomain: "example.org", Path: "/spire/agent/agent2"},
+				},
+				CanReattest: true,
+			},
+			expectedStdout: "Updated 2 agents out of 2 matching\n",
+		},
+		{
+			name: "can't reattest matching all",
+			args: []string{"-canReattest", "false", "-selector", "k8s_psat:cluster:a", "-selector", "k8s_psat:node:b", "-matchSelectorsOn", "all"},
+			agents: []*types.Agent{
+				{Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/agent2"}},
+			},
+			updated:           1,
+			expectedMatch:     types.SelectorMatch_MatchBehavior(datastore.MatchAll),
+			expectedSelectors: []*types.Selector{{Type: "k8s_psat", Value: "cluster:a"}, {Type: "k8s_psat", Value: "node:b"}},
+			expectedSetRequest: &extensionv1.SetAgentsCanReattestRequest{
+				Ids: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/spire/agent/agent2"},
+				},
+			},
+			expectedStdout: "Updated 1 agent out of 1 matching\n",
+		},
+		{
+			name:              "no matching agents",
+			args:              []string{"-canReattest", "true", "-selector", "k8s_psat:cluster:c", "-matchSelectorsOn", "exact"},
+			expectedMatch:     types.SelectorMatch_MATCH_EXACT,
+			expectedSelectors: []*types.Selector{{Type: "k8s_psat", Value: "cluster:c"}},
+			expectedStdout:    "Updated 0 agents out of 0 matching\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, agent.NewReattestCommandWithEnv)
+			test.server.agents = tt.agents
+			test.extensionServer.updated = tt.updated
+
+			returnCode := test.client.Run(append(test.args, tt.args...))
+			require.Equal(t, 0, returnCode, test.stderr.String())
+			require.Equal(t, tt.expectedStdout, test.stdout.String())
+			spiretest.AssertProtoEqual(t, &agentv1.ListAgentsRequest{
+				PageSize: 1000,
+				Filter: &agentv1.ListAgentsRequest_Filter{
+					BySelectorMatch: &types.SelectorMatch{
+						Selectors: tt.expectedSelectors,
+						Match:     tt.expectedMatch,
+					},
+				},
+				OutputMask: &types.AgentMask{},
+			}, test.server.gotListAgentRequest)
+			if tt.expectedSetRequest == nil {
+				require.Nil(t, test.extensionServer.gotSetAgentsCanReattestRequest)
+			} else {
+				spiretest.AssertProtoEqual(t, tt.expectedSetRequest, test.extensionServer.gotSetAgentsCanReattestRequest)
+			}
+		})
+	}
+}
+
+func TestReattestErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		serverErr error
+		expErr    string
+	}{
+		{
+			name:   "missing canReattest",
+			args:   []string{"-selector", "k8s_psat:cluster:a"},
+			expErr: "Error: canReattest must be set to 'true' or 'false'\n",
+		},
+		{
+			name:   "missing selector",
+			args:   []string{"-canReattest", "false"},
+			expErr: "Error: at least one selector is required\n",
+		},
+		{
+			name:   "invalid match behavior",
+			args:   []string{"-canReattest", "false", "-selector", "k8s_psat:cluster:a", "-matchSelectorsOn", "none"},
+			expErr: "Error: unsupported match behavior\n",
+		},
+		{
+			name:   "invalid selector",
+			args:   []string{"-canReattest", "false", "-selector", "k8s_psat"},
+			expErr: "Error: error parsing selector \"k8s_psat\": selector \"k8s_psat\" must be formatted as type:value\n",
+		},
+		{
+			name:      "server error",
+			args:      []string{"-canReattest", "false", "-selector", "k8s_psat:cluster:a"},
+			serverErr: status.Error(codes.Internal, "internal server error"),
+			expErr:    "Error: rpc error: code = Internal desc = internal server error\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, agent.NewReattestCommandWithEnv)
+			test.server.agents = testAgents
+			test.extensionServer.err = tt.serverErr
+
+			require.Equal(t, 1, test.client.Run(append(test.args, tt.args...)))
+			require.Equal(t, tt.expErr, test.stderr.String())
+		})
+	}
+}
+
+func TestAlias(t *testing.T) {
+	test := setupTest(t, agent.NewAliasCommandWithEnv)
+	test.extensionServer.aliases = map[string][]string{
+		"spiffe://example.org/spire/agent/agent1": nil,
+	}
+
+	returnCode := test.client.Run(append(test.args, "-spiffeID", "spiffe://example.org/spire/agent/agent1", "-add", "spiffe://example.org/node/a", "-add", "spiffe://example.org/node/b"))
+	require.Equal(t, 0, returnCode, test.stderr.String())
+	require.Equal(t, `Aliases of agent "spiffe://example.org/spire/agent/agent1": 2
+  spiffe://example.org/node/a
+  spiffe://example.org/node/b
+`, test.stdout.String())
+
+	// The flags of a command accumulate, so remove through a new one
+	test.stdout.Reset()
+	client := agent.NewAliasCommandWithEnv(&commoncli.Env{Stdout: test.stdout, Stderr: test.stderr})
+	returnCode = client.Run(append(test.args, "-spiffeID", "spiffe://example.org/spire/agent/agent1", "-remove", "spiffe://example.org/node/a"))
+	require.Equal(t, 0, returnCode, test.stderr.String())
+	require.Equal(t, `Aliases of agent "spiffe://example.org/spire/agent/agent1": 1
+  spiffe://example.org/node/b
+`, test.stdout.String())
+}
+
+func TestAliasErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		serverErr error
+		expErr    string
+	}{
+		{
+			name:   "missing SPIFFE ID",
+			expErr: "Error: a SPIFFE ID is required\n",
+		},
+		{
+			name:   "invalid SPIFFE ID",
+			args:   []string{"-spiffeID", "invalid"},
+			expErr: "Error: invalid SPIFFE ID \"invalid\": scheme is missing or invalid\n",
+		},
+		{
+			name:   "invalid alias",
+			args:   []string{"-spiffeID", "spiffe://example.org/spire/agent/agent1", "-add", "invalid"},
+			expErr: "Error: invalid alias \"invalid\": scheme is missing or invalid\n",
+		},
+		{
+			name:      "add fails",
+			args:      []string{"-spiffeID", "spiffe://example.org/spire/agent/unknown", "-add", "spiffe://example.org/node/b"},
+			serverErr: status.Error(codes.NotFound, "agent not found"),
+			expErr:    "Error: failed to add alias \"spiffe://example.org/node/b\": rpc error: code = NotFound desc = agent not found\n",
+		},
+		{
+			name:   "remove alias of another agent",
+			args:   []string{"-spiffeID", "spiffe://example.org/spire/agent/agent1", "-remove", "spiffe://example.org/node/a"},
+			expErr: "Error: failed to remove alias \"spiffe://example.org/node/a\": rpc error: code = NotFound desc = agent alias not found\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, agent.NewAliasCommandWithEnv)
+			test.extensionServer.aliases = map[string][]string{
+				"spiffe://example.org/spire/agent/agent1": nil,
+				"spiffe://example.org/spire/agent/agent2": {"spiffe://example.org/node/a"},
+			}
+			test.extensionServer.err = tt.serverErr
+
+			require.Equal(t, 1, test.client.Run(append(test.args, tt.args...)))
+			require.Equal(t, tt.expErr, test.stderr.String())
+			require.Empty(t, test.stdout.String())
+		})
+	}
+}
+
 func setupTest(t *testing.T, newClient func(*commoncli.Env) cli.Command) *agentTest {
 	server := &fakeAgentServer{}
+	extensionServer := &fakeAgentExtensionServer{}
 
 	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
 		agentv1.RegisterAgentServer(s, server)
+		extensionv1.RegisterAgentExtensionServer(s, extensionServer)
 	})
 
 	stdin := new(bytes.Buffer)
@@ -813,6 +1082,8 @@ func setupTest(t *testing.T, newClient func(*commoncli.Env) cli.Command) *agentT
 		args:   []string{clitest.AddrArg, clitest.GetAddr(addr)},
 		server: server,
 		client: client,
+
+		extensionServer: extensionServer,
 	}
 
 	t.Cleanup(func() {
@@ -862,6 +1133,57 @@ func (s *fakeAgentServer) GetAgent(context.Context, *agentv1.GetAgentRequest) (*
 	return nil, s.err
 }
 
+type fakeAgentExtensionServer struct {
+	extensionv1.UnimplementedAgentExtensionServer
+
+	updated                        int32
+	aliases                        map[string][]string
+	gotSetAgentsCanReattestRequest *extensionv1.SetAgentsCanReattestRequest
+	details                        *extensionv1.GetAgentDetailsResponse
+	gotGetAgentDetailsRequest      *extensionv1.GetAgentDetailsRequest
+	err                            error
+}
+
+func (s *fakeAgentExtensionServer) SetAgentsCanReattest(_ context.Context, req *extensionv1.SetAgentsCanReattestRequest) (*extensionv1.SetAgentsCanReattestResponse, error) {
+	s.gotSetAgentsCanReattestRequest = req
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &extensionv1.SetAgentsCanReattestResponse{Updated: s.updated}, nil
+}
+
+func (s *fakeAgentExtensionServer) ListAgentAliases(_ context.Context, req *extensionv1.ListAgentAliasesRequest) (*extensionv1.ListAgentAliasesResponse, error) {
+	aliases := slices.Clone(s.aliases[idutil.RequireIDProtoString(req.Id)])
+	slices.Sort(aliases)
+	resp := &extensionv1.ListAgentAliasesResponse{}
+	for _, alias := range aliases {
+		resp.Aliases = append(resp.Aliases, api.ProtoFromID(spiffeid.RequireFromString(alias)))
+	}
+	return resp, nil
+}
+
+func (s *fakeAgentExtensionServer) AddAgentAlias(_ context.Context, req *extensionv1.AddAgentAliasRequest) (*emptypb.Empty, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	id := idutil.RequireIDProtoString(req.Id)
+	s.aliases[id] = append(s.aliases[id], idutil.RequireIDProtoString(req.Alias))
+	return &emptypb.Empty{}, nil
+}
+
+func (s *fakeAgentExtensionServer) RemoveAgentAlias(_ context.Context, req *extensionv1.RemoveAgentAliasRequest) (*emptypb.Empty, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	id := idutil.RequireIDProtoString(req.Id)
+	i := slices.Index(s.aliases[id], idutil.RequireIDProtoString(req.Alias))
+	if i < 0 {
+		return nil, status.Error(codes.NotFound, "agent alias not found")
+	}
+	s.aliases[id] = slices.Delete(s.aliases[id], i, i+1)
+	return &emptypb.Empty{}, nil
+}
+
 func requireOutputBasedOnFormat(t *testing.T, format, stdoutString string, expectedStdoutPretty, expectedStdoutJSON string) {
 	switch format {
 	case "pretty":
@@ -874,3 +1196,14 @@ func requireOutputBasedOnFormat(t *testing.T, format, stdoutString string, expec
 		}
 	}
 }
+
+func (s *fakeAgentExtensionServer) GetAgentDetails(_ context.Context, req *extensionv1.GetAgentDetailsRequest) (*extensionv1.GetAgentDetailsResponse, error) {
+	s.gotGetAgentDetailsRequest = req
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.details == nil {
+		return &extensionv1.GetAgentDetailsResponse{}, nil
+	}
+	return s.details, nil
+}
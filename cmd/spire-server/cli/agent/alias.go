@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	"github.com/spiffe/spire/pkg/server/api"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
+)
+
+// NewAliasCommand creates a new "alias" subcommand for "agent" command.
+func NewAliasCommand() cli.Command {
+	return NewAliasCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewAliasCommandWithEnv creates a new "alias" subcommand for "agent" command
+// using the environment specified
+func NewAliasCommandWithEnv(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &aliasCommand{})
+}
+
+// aliasCommand adds and removes the alias SPIFFE IDs of an attested agent,
+// and lists the aliases it ends up with.
+type aliasCommand struct {
+	spiffeID string
+	add      commoncli.StringsFlag
+	remove   commoncli.StringsFlag
+}
+
+func (*aliasCommand) Name() string {
+	return "agent alias"
+}
+
+func (*aliasCommand) Synopsis() string {
+	return "Adds, removes and lists the alias SPIFFE IDs of an attested agent"
+}
+
+func (c *aliasCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the agent whose aliases are managed (agent identity)")
+	fs.Var(&c.add, "add", "An alias SPIFFE ID to add to the agent. Can be used more than once")
+	fs.Var(&c.remove, "remove", "An alias SPIFFE ID to remove from the agent. Can be used more than once")
+}
+
+// Run adds and removes the aliases of an agent, then lists its aliases
+func (c *aliasCommand) Run(ctx context.Context, env *commoncli.Env, serverClient util.ServerClient) error {
+	if c.spiffeID == "" {
+		return errors.New("a SPIFFE ID is required")
+	}
+	id, err := spiffeid.FromString(c.spiffeID)
+	if err != nil {
+		return fmt.Errorf("invalid SPIFFE ID %q: %w", c.spiffeID, err)
+	}
+	for _, alias := range append(slices.Clone(c.add), c.remove...) {
+		if _, err := spiffeid.FromString(alias); err != nil {
+			return fmt.Errorf("invalid alias %q: %w", alias, err)
+		}
+	}
+
+	client := serverClient.NewAgentExtensionClient()
+	for _, alias := range c.remove {
+		if _, err := client.RemoveAgentAlias(ctx, &extensionv1.RemoveAgentAliasRequest{
+			Id:    api.ProtoFromID(id),
+			Alias: api.ProtoFromID(spiffeid.RequireFromString(alias)),
+		}); err != nil {
+			return fmt.Errorf("failed to remove alias %q: %w", alias, err)
+		}
+	}
+	for _, alias := range c.add {
+		if _, err := client.AddAgentAlias(ctx, &extensionv1.AddAgentAliasRequest{
+			Id:    api.ProtoFromID(id),
+			Alias: api.ProtoFromID(spiffeid.RequireFromString(alias)),
+		}); err != nil {
+			return fmt.Errorf("failed to add alias %q: %w", alias, err)
+		}
+	}
+
+	resp, err := client.ListAgentAliases(ctx, &extensionv1.ListAgentAliasesRequest{
+		Id: api.ProtoFromID(id),
+	})
+	if err != nil {
+		return err
+	}
+	if err := env.Printf("Aliases of agent %q: %d\n", id, len(resp.Aliases)); err != nil {
+		return err
+	}
+	for _, alias := range resp.Aliases {
+		aliasID, err := idutil.IDProtoString(alias)
+		if err != nil {
+			return err
+		}
+		if err := env.Printf("  %s\n", aliasID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
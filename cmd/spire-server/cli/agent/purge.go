@@ -42,15 +42,25 @@ func (*purgeCommand) Synopsis() string {
 
 func (c *purgeCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) (err error) {
 	agentClient := serverClient.NewAgentClient()
-	resp, err := agentClient.ListAgents(ctx, &agentv1.ListAgentsRequest{
-		Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
-		OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to list agents: %w", err)
+
+	// The server caps the page size, so agents are listed page by page
+	var agents []*types.Agent
+	pageToken := ""
+	for {
+		resp, err := agentClient.ListAgents(ctx, &agentv1.ListAgentsRequest{
+			Filter:     &agentv1.ListAgentsRequest_Filter{ByCanReattest: wrapperspb.Bool(true)},
+			OutputMask: &types.AgentMask{X509SvidExpiresAt: true},
+			PageToken:  pageToken,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to list agents: %w", err)
+		}
+		agents = append(agents, resp.Agents...)
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
 	}
 
-	agents := resp.GetAgents()
 	expiredAgents := &expiredAgents{Agents: []*expiredAgent{}}
 
 	for _, agent := range agents {
@@ -0,0 +1,127 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
+)
+
+// selectorMatchAll is the agent API match behavior for the datastore MatchAll
+// behavior, which the API SDK doesn't define. The API passes the match
+// behavior on to the datastore as is.
+const selectorMatchAll = types.SelectorMatch_MatchBehavior(datastore.MatchAll)
+
+// NewReattestCommand creates a new "reattest" subcommand for "agent" command.
+func NewReattestCommand() cli.Command {
+	return NewReattestCommandWithEnv(commoncli.DefaultEnv)
+}
+
+// NewReattestCommandWithEnv creates a new "reattest" subcommand for "agent"
+// command using the environment specified
+func NewReattestCommandWithEnv(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &reattestCommand{})
+}
+
+// reattestCommand sets whether the attested agents matching a set of
+// selectors can re-attest.
+type reattestCommand struct {
+	canReattest      commoncli.BoolFlag
+	selectors        commoncli.StringsFlag
+	matchSelectorsOn string
+}
+
+func (*reattestCommand) Name() string {
+	return "agent reattest"
+}
+
+func (*reattestCommand) Synopsis() string {
+	return "Sets whether the attested agents matching the given selectors can re-attest"
+}
+
+func (c *reattestCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.Var(&c.canReattest, "canReattest", "Whether the matching agents can re-attest, 'true' or 'false'")
+	fs.Var(&c.selectors, "selector", "A colon-delimited type:value selector of the agents to update. Can be used more than once")
+	fs.StringVar(&c.matchSelectorsOn, "matchSelectorsOn", "superset", "The match mode used when filtering by selectors. Options: exact, any, superset, subset and all, which matches the agents having all the selectors like superset")
+}
+
+// Run sets whether the matching agents can re-attest
+func (c *reattestCommand) Run(ctx context.Context, env *commoncli.Env, serverClient util.ServerClient) error {
+	if c.canReattest == commoncli.BoolFlagAll {
+		return errors.New("canReattest must be set to 'true' or 'false'")
+	}
+	if len(c.selectors) == 0 {
+		return errors.New("at least one selector is required")
+	}
+
+	matchBehavior := selectorMatchAll
+	if c.matchSelectorsOn != "all" {
+		var err error
+		if matchBehavior, err = parseToSelectorMatch(c.matchSelectorsOn); err != nil {
+			return err
+		}
+	}
+	selectors := make([]*types.Selector, len(c.selectors))
+	for i, sel := range c.selectors {
+		selector, err := util.ParseSelector(sel)
+		if err != nil {
+			return fmt.Errorf("error parsing selector %q: %w", sel, err)
+		}
+		selectors[i] = selector
+	}
+
+	agentClient := serverClient.NewAgentClient()
+	extensionClient := serverClient.NewAgentExtensionClient()
+
+	// Update the matching agents a page at a time. The flag isn't part of the
+	// filter, so updating a page doesn't change which agents the next page
+	// holds.
+	matching, updated := 0, 0
+	pageToken := ""
+	for {
+		listResponse, err := agentClient.ListAgents(ctx, &agentv1.ListAgentsRequest{
+			PageSize:  1000,
+			PageToken: pageToken,
+			Filter: &agentv1.ListAgentsRequest_Filter{
+				BySelectorMatch: &types.SelectorMatch{
+					Selectors: selectors,
+					Match:     matchBehavior,
+				},
+			},
+			OutputMask: &types.AgentMask{},
+		})
+		if err != nil {
+			return err
+		}
+		if len(listResponse.Agents) > 0 {
+			ids := make([]*types.SPIFFEID, 0, len(listResponse.Agents))
+			for _, agent := range listResponse.Agents {
+				ids = append(ids, agent.Id)
+			}
+			setResponse, err := extensionClient.SetAgentsCanReattest(ctx, &extensionv1.SetAgentsCanReattestRequest{
+				Ids:         ids,
+				CanReattest: c.canReattest == commoncli.BoolFlagTrue,
+			})
+			if err != nil {
+				return err
+			}
+			matching += len(ids)
+			updated += int(setResponse.Updated)
+		}
+		if pageToken = listResponse.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	msg := fmt.Sprintf("Updated %d ", updated)
+	msg = util.Pluralizer(msg, "agent", "agents", updated)
+	return env.Printf("%s out of %d matching\n", msg, matching)
+}
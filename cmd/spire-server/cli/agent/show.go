@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"flag"
+	"time"
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -13,6 +14,7 @@ import (
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/server/api"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 )
 
 type showCommand struct {
@@ -20,6 +22,9 @@ type showCommand struct {
 	// SPIFFE ID of the agent being shown
 	spiffeID string
 	printer  cliprinter.Printer
+
+	// details holds the details of the agent the agent API doesn't expose
+	details *extensionv1.GetAgentDetailsResponse
 }
 
 // NewShowCommand creates a new "show" subcommand for "agent" command.
@@ -58,15 +63,20 @@ func (c *showCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient ut
 		return err
 	}
 
+	c.details, err = serverClient.NewAgentExtensionClient().GetAgentDetails(ctx, &extensionv1.GetAgentDetailsRequest{Id: api.ProtoFromID(id)})
+	if err != nil {
+		return err
+	}
+
 	return c.printer.PrintProto(agent)
 }
 
 func (c *showCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the agent to show (agent identity)")
-	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintAgent)
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintAgent)
 }
 
-func prettyPrintAgent(env *commoncli.Env, results ...any) error {
+func (c *showCommand) prettyPrintAgent(env *commoncli.Env, results ...any) error {
 	agent, ok := results[0].(*types.Agent)
 	if !ok {
 		return errors.New("internal error: cli printer; please report this bug")
@@ -77,8 +87,26 @@ func prettyPrintAgent(env *commoncli.Env, results ...any) error {
 		return err
 	}
 
+	if c.details.AttestedAt != 0 {
+		env.Printf("First attested    : %s\n", time.Unix(c.details.AttestedAt, 0).UTC().Format(time.RFC3339))
+	}
+	if c.details.LastAttestedAt != 0 {
+		env.Printf("Last attested     : %s\n", time.Unix(c.details.LastAttestedAt, 0).UTC().Format(time.RFC3339))
+	}
+	selectorSources := make(map[string]string, len(c.details.SelectorSources))
+	for _, s := range c.details.SelectorSources {
+		selectorSources[s.Selector.Type+":"+s.Selector.Value] = s.Source
+	}
 	for _, s := range agent.Selectors {
-		env.Printf("Selectors         : %s:%s\n", s.Type, s.Value)
+		selector := s.Type + ":" + s.Value
+		if source, ok := selectorSources[selector]; ok {
+			env.Printf("Selectors         : %s (source: %s)\n", selector, source)
+			continue
+		}
+		env.Printf("Selectors         : %s\n", selector)
+	}
+	for _, serial := range c.details.SerialHistory {
+		env.Printf("Previous serial   : %s (%s at %s)\n", serial.SerialNumber, serial.Reason, time.Unix(serial.SupersededAt, 0).UTC().Format(time.RFC3339))
 	}
 	return nil
 }
@@ -36,6 +36,8 @@ var (
     	The format to list federated bundles (only pretty output format supports this flag). Either "pem" or "spiffe". (default "pem")
   -id string
     	SPIFFE ID of the trust domain
+  -lastRefreshed
+    	Show the time each bundle was last refreshed from its bundle endpoint (only pretty output format supports this flag)
   -output value
     	Desired output format (pretty, json); default: pretty.
   -socketPath string
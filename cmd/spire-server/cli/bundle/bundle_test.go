@@ -7,11 +7,13 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
@@ -689,6 +691,41 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListLastRefreshed(t *testing.T) {
+	test := setupTest(t, newListCommand)
+	test.server.bundles = []*types.Bundle{
+		{
+			TrustDomain:     "spiffe://domain1.test",
+			X509Authorities: []*types.X509Certificate{{Asn1: test.cert1.Raw}},
+		},
+		{
+			TrustDomain:     "spiffe://domain2.test",
+			X509Authorities: []*types.X509Certificate{{Asn1: test.cert2.Raw}},
+		},
+	}
+	// Only the bundle of domain1.test was refreshed from its bundle endpoint
+	test.extensionServer.refreshTimes = []*extensionv1.BundleRefreshTime{
+		{TrustDomain: "domain1.test", LastRefreshedAt: time.Date(2024, 3, 4, 5, 6, 7, 0, time.UTC).Unix()},
+		{TrustDomain: "domain2.test"},
+	}
+
+	rc := test.client.Run(test.args("-lastRefreshed"))
+	require.Equal(t, 0, rc, test.stderr.String())
+	require.Equal(t, fmt.Sprintf(headerFmt, "spiffe://domain1.test")+cert1PEM+
+		"Last refreshed: 2024-03-04T05:06:07Z\n\n"+
+		fmt.Sprintf(headerFmt, "spiffe://domain2.test")+cert2PEM+
+		"Last refreshed: never\n", test.stdout.String())
+}
+
+func TestListLastRefreshedServerError(t *testing.T) {
+	test := setupTest(t, newListCommand)
+	test.extensionServer.err = status.Error(codes.Internal, "internal server error")
+
+	rc := test.client.Run(test.args("-lastRefreshed"))
+	require.Equal(t, 1, rc)
+	require.Equal(t, "Error: rpc error: code = Internal desc = internal server error\n", test.stderr.String())
+}
+
 func TestDeleteHelp(t *testing.T) {
 	test := setupTest(t, newDeleteCommand)
 	test.client.Help()
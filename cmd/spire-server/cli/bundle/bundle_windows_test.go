@@ -34,6 +34,8 @@ var (
     	The format to list federated bundles (only pretty output format supports this flag). Either "pem" or "spiffe". (default "pem")
   -id string
     	SPIFFE ID of the trust domain
+  -lastRefreshed
+    	Show the time each bundle was last refreshed from its bundle endpoint (only pretty output format supports this flag)
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -output value
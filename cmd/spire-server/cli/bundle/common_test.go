@@ -11,6 +11,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/test/clitest"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
@@ -184,9 +185,11 @@ func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *bundl
 	require.NoError(t, err)
 
 	server := &fakeBundleServer{t: t}
+	extensionServer := &fakeBundleExtensionServer{}
 
 	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
 		bundlev1.RegisterBundleServer(s, server)
+		extensionv1.RegisterBundleExtensionServer(s, extensionServer)
 	})
 
 	stdin := new(bytes.Buffer)
@@ -209,6 +212,8 @@ func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *bundl
 		stderr:   stderr,
 		server:   server,
 		client:   client,
+
+		extensionServer: extensionServer,
 	}
 
 	t.Cleanup(func() {
@@ -227,8 +232,9 @@ type bundleTest struct {
 	stdout *bytes.Buffer
 	stderr *bytes.Buffer
 
-	addr   string
-	server *fakeBundleServer
+	addr            string
+	server          *fakeBundleServer
+	extensionServer *fakeBundleExtensionServer
 
 	client cli.Command
 }
@@ -319,3 +325,19 @@ func (f *fakeBundleServer) BatchDeleteFederatedBundle(_ context.Context, req *bu
 		Results: f.deleteResults,
 	}, nil
 }
+
+type fakeBundleExtensionServer struct {
+	extensionv1.UnimplementedBundleExtensionServer
+
+	refreshTimes []*extensionv1.BundleRefreshTime
+	err          error
+}
+
+func (f *fakeBundleExtensionServer) ListFederatedBundleRefreshTimes(context.Context, *extensionv1.ListFederatedBundleRefreshTimesRequest) (*extensionv1.ListFederatedBundleRefreshTimesResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &extensionv1.ListFederatedBundleRefreshTimesResponse{
+		Bundles: f.refreshTimes,
+	}, nil
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 )
 
 // NewListCommand creates a new "list" subcommand for "bundle" command.
@@ -23,10 +26,16 @@ func newListCommand(env *commoncli.Env) cli.Command {
 }
 
 type listCommand struct {
-	env          *commoncli.Env
-	id           string // SPIFFE ID of the trust bundle
-	bundleFormat string
-	printer      cliprinter.Printer
+	env               *commoncli.Env
+	id                string // SPIFFE ID of the trust bundle
+	bundleFormat      string
+	showLastRefreshed bool
+	printer           cliprinter.Printer
+
+	// lastRefreshed holds the time each bundle was last refreshed from its
+	// bundle endpoint, keyed by trust domain. It is only loaded when
+	// -lastRefreshed is set.
+	lastRefreshed map[spiffeid.TrustDomain]time.Time
 }
 
 func (c *listCommand) Name() string {
@@ -40,10 +49,19 @@ func (c *listCommand) Synopsis() string {
 func (c *listCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.StringVar(&c.id, "id", "", "SPIFFE ID of the trust domain")
 	fs.StringVar(&c.bundleFormat, "format", util.FormatPEM, fmt.Sprintf("The format to list federated bundles (only pretty output format supports this flag). Either %q or %q.", util.FormatPEM, util.FormatSPIFFE))
+	fs.BoolVar(&c.showLastRefreshed, "lastRefreshed", false, "Show the time each bundle was last refreshed from its bundle endpoint (only pretty output format supports this flag)")
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintList)
 }
 
 func (c *listCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	if c.showLastRefreshed {
+		lastRefreshed, err := loadLastRefreshed(ctx, serverClient)
+		if err != nil {
+			return err
+		}
+		c.lastRefreshed = lastRefreshed
+	}
+
 	bundleClient := serverClient.NewBundleClient()
 	if c.id != "" {
 		resp, err := bundleClient.GetFederatedBundle(ctx, &bundlev1.GetFederatedBundleRequest{
@@ -75,12 +93,60 @@ func (c *listCommand) prettyPrintList(env *commoncli.Env, results ...any) error
 			if err := printBundleWithFormat(env.Stdout, bundle, c.bundleFormat, true); err != nil {
 				return err
 			}
+			if err := c.printLastRefreshed(env, bundle); err != nil {
+				return err
+			}
 		}
 		return nil
 	}
 	if resp, ok := results[0].(*types.Bundle); ok {
-		return printBundleWithFormat(env.Stdout, resp, c.bundleFormat, false)
+		if err := printBundleWithFormat(env.Stdout, resp, c.bundleFormat, false); err != nil {
+			return err
+		}
+		return c.printLastRefreshed(env, resp)
 	}
 
 	return cliprinter.ErrInternalCustomPrettyFunc
 }
+
+// loadLastRefreshed fetches from the server the time each federated bundle
+// was last refreshed from its bundle endpoint.
+func loadLastRefreshed(ctx context.Context, serverClient util.ServerClient) (map[spiffeid.TrustDomain]time.Time, error) {
+	resp, err := serverClient.NewBundleExtensionClient().ListFederatedBundleRefreshTimes(ctx, &extensionv1.ListFederatedBundleRefreshTimesRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	lastRefreshed := make(map[spiffeid.TrustDomain]time.Time, len(resp.Bundles))
+	for _, bundle := range resp.Bundles {
+		td, err := spiffeid.TrustDomainFromString(bundle.TrustDomain)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trust domain %q: %w", bundle.TrustDomain, err)
+		}
+		var t time.Time
+		if bundle.LastRefreshedAt != 0 {
+			t = time.Unix(bundle.LastRefreshedAt, 0)
+		}
+		lastRefreshed[td] = t
+	}
+	return lastRefreshed, nil
+}
+
+// printLastRefreshed prints when the bundle was last refreshed, if the times
+// were requested.
+func (c *listCommand) printLastRefreshed(env *commoncli.Env, bundle *types.Bundle) error {
+	if c.lastRefreshed == nil {
+		return nil
+	}
+
+	td, err := spiffeid.TrustDomainFromString(bundle.TrustDomain)
+	if err != nil {
+		return err
+	}
+
+	lastRefreshed := "never"
+	if t := c.lastRefreshed[td]; !t.IsZero() {
+		lastRefreshed = t.UTC().Format(time.RFC3339)
+	}
+	return env.Printf("Last refreshed: %s\n", lastRefreshed)
+}
@@ -0,0 +1,307 @@
+package ca
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/cmd/spire-server/cli/run"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	"github.com/spiffe/spire/pkg/common/catalog"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/pkg/server/datastore/sqlstore"
+	"github.com/spiffe/spire/proto/private/server/journal"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// dataStore is the part of the datastore used by the command.
+type dataStore interface {
+	FetchCAJournal(ctx context.Context, activeX509AuthorityID string) (*datastore.CAJournal, error)
+	SetCAJournal(ctx context.Context, caJournal *datastore.CAJournal) (*datastore.CAJournal, error)
+	Close() error
+}
+
+// loadDataStoreFunc loads the datastore configured in the server
+// configuration file at the given path.
+type loadDataStoreFunc func(ctx context.Context, configPath string, expandEnv bool, log logrus.FieldLogger) (dataStore, error)
+
+// NewJournalShowCommand creates a new "ca journal show" command.
+func NewJournalShowCommand() cli.Command {
+	return newJournalShowCommand(commoncli.DefaultEnv, loadDataStore)
+}
+
+func newJournalShowCommand(env *commoncli.Env, loadDataStore loadDataStoreFunc) cli.Command {
+	return util.AdaptCommand(env, &journalShowCommand{
+		env:           env,
+		loadDataStore: loadDataStore,
+	})
+}
+
+type journalShowCommand struct {
+	env           *commoncli.Env
+	loadDataStore loadDataStoreFunc
+	printer       cliprinter.Printer
+
+	configPath  string
+	expandEnv   bool
+	authorityID string
+	forceActive string
+}
+
+// Journal is the decoded CA journal, as printed by the command.
+type Journal struct {
+	ID                    uint        `json:"id"`
+	ActiveX509AuthorityID string      `json:"active_x509_authority_id"`
+	X509Authorities       []Authority `json:"x509_authorities"`
+	JWTAuthorities        []Authority `json:"jwt_authorities"`
+}
+
+// Authority is an X.509 authority or a JWT key recorded in the CA journal.
+type Authority struct {
+	AuthorityID         string    `json:"authority_id"`
+	Status              string    `json:"status"`
+	SlotID              string    `json:"slot_id"`
+	UpstreamAuthorityID string    `json:"upstream_authority_id,omitempty"`
+	IssuedAt            time.Time `json:"issued_at"`
+	NotAfter            time.Time `json:"not_after"`
+}
+
+func (*journalShowCommand) Name() string {
+	return "ca journal show"
+}
+
+func (*journalShowCommand) Synopsis() string {
+	return "Shows the CA journal of a server, read from its datastore"
+}
+
+func (c *journalShowCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.configPath, "config", run.DefaultConfigPath, "Path to the SPIRE server config file; the datastore configured in it is used")
+	fs.BoolVar(&c.expandEnv, "expandEnv", false, "Expand environment variables in SPIRE config file")
+	fs.StringVar(&c.authorityID, "authorityID", "", "The ID of the X.509 authority the CA journal was last active with")
+	fs.StringVar(&c.forceActive, "forceActive", "", "The ID of a prepared or old X.509 authority or JWT key to mark as active, in order to fix a stuck rotation. Refused while the server answers on its API socket; other servers sharing the CA journal must be stopped too")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintJournal)
+}
+
+func (c *journalShowCommand) Run(ctx context.Context, _ *commoncli.Env, client util.ServerClient) error {
+	if c.authorityID == "" {
+		return errors.New("an X.509 authority ID is required")
+	}
+	if c.forceActive != "" {
+		// A running server keeps its own copy of the journal and would
+		// overwrite the change on its next rotation.
+		if err := checkServerStopped(ctx, client); err != nil {
+			return err
+		}
+	}
+
+	log := logrus.New()
+	log.SetOutput(c.env.Stderr)
+	log.SetLevel(logrus.WarnLevel)
+
+	ds, err := c.loadDataStore(ctx, c.configPath, c.expandEnv, log)
+	if err != nil {
+		return fmt.Errorf("could not load datastore: %w", err)
+	}
+	defer ds.Close()
+
+	caJournal, err := ds.FetchCAJournal(ctx, c.authorityID)
+	if err != nil {
+		return fmt.Errorf("could not fetch CA journal: %w", err)
+	}
+	if caJournal == nil {
+		return fmt.Errorf("no CA journal found with active X.509 authority ID %q", c.authorityID)
+	}
+
+	entries := new(journal.Entries)
+	if err := proto.Unmarshal(caJournal.Data, entries); err != nil {
+		return fmt.Errorf("could not decode CA journal: %w", err)
+	}
+
+	if c.forceActive != "" {
+		isX509, err := forceActive(entries, c.forceActive, time.Now())
+		if err != nil {
+			return err
+		}
+		if isX509 {
+			caJournal.ActiveX509AuthorityID = c.forceActive
+		}
+		caJournal.Data, err = proto.Marshal(entries)
+		if err != nil {
+			return fmt.Errorf("could not encode CA journal: %w", err)
+		}
+		caJournal, err = ds.SetCAJournal(ctx, caJournal)
+		if err != nil {
+			return fmt.Errorf("could not update CA journal: %w", err)
+		}
+	}
+
+	return c.printer.PrintStruct(decodeJournal(caJournal, entries))
+}
+
+// forceActive marks the X.509 authority or JWT key with the given authority
+// ID as active, and the one active until then as old. It returns whether the
+// authority is an X.509 authority.
+func forceActive(entries *journal.Entries, authorityID string, now time.Time) (bool, error) {
+	for _, entry := range entries.X509CAs {
+		if entry.AuthorityId == authorityID {
+			if err := checkForceActive(authorityID, entry.Status, entry.NotAfter, now); err != nil {
+				return false, err
+			}
+			for _, other := range entries.X509CAs {
+				if other.Status == journal.Status_ACTIVE {
+					other.Status = journal.Status_OLD
+				}
+			}
+			entry.Status = journal.Status_ACTIVE
+			return true, nil
+		}
+	}
+
+	for _, entry := range entries.JwtKeys {
+		if entry.AuthorityId == authorityID {
+			if err := checkForceActive(authorityID, entry.Status, entry.NotAfter, now); err != nil {
+				return false, err
+			}
+			for _, other := range entries.JwtKeys {
+				if other.Status == journal.Status_ACTIVE {
+					other.Status = journal.Status_OLD
+				}
+			}
+			entry.Status = journal.Status_ACTIVE
+			return false, nil
+		}
+	}
+
+	return false, fmt.Errorf("no authority found in the CA journal with ID %q", authorityID)
+}
+
+func checkForceActive(authorityID string, status journal.Status, notAfter int64, now time.Time) error {
+	switch {
+	case status == journal.Status_ACTIVE:
+		return fmt.Errorf("authority %q is already active", authorityID)
+	case status != journal.Status_PREPARED && status != journal.Status_OLD:
+		return fmt.Errorf("authority %q cannot be marked as active from status %s", authorityID, status)
+	case !now.Before(time.Unix(notAfter, 0)):
+		return fmt.Errorf("authority %q cannot be marked as active since it expired at %s", authorityID, time.Unix(notAfter, 0).UTC())
+	}
+	return nil
+}
+
+func decodeJournal(caJournal *datastore.CAJournal, entries *journal.Entries) *Journal {
+	j := &Journal{
+		ID:                    caJournal.ID,
+		ActiveX509AuthorityID: caJournal.ActiveX509AuthorityID,
+		X509Authorities:       []Authority{},
+		JWTAuthorities:        []Authority{},
+	}
+	for _, entry := range entries.X509CAs {
+		j.X509Authorities = append(j.X509Authorities, Authority{
+			AuthorityID:         entry.AuthorityId,
+			Status:              entry.Status.String(),
+			SlotID:              entry.SlotId,
+			UpstreamAuthorityID: entry.UpstreamAuthorityId,
+			IssuedAt:            time.Unix(entry.IssuedAt, 0).UTC(),
+			NotAfter:            time.Unix(entry.NotAfter, 0).UTC(),
+		})
+	}
+	for _, entry := range entries.JwtKeys {
+		j.JWTAuthorities = append(j.JWTAuthorities, Authority{
+			AuthorityID: entry.AuthorityId,
+			Status:      entry.Status.String(),
+			SlotID:      entry.SlotId,
+			IssuedAt:    time.Unix(entry.IssuedAt, 0).UTC(),
+			NotAfter:    time.Unix(entry.NotAfter, 0).UTC(),
+		})
+	}
+	return j
+}
+
+func prettyPrintJournal(env *commoncli.Env, results ...any) error {
+	structs, ok := results[0].([]any)
+	if !ok {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+	j, ok := structs[0].(*Journal)
+	if !ok {
+		return cliprinter.ErrInternalCustomPrettyFunc
+	}
+
+	env.Printf("CA journal ID: %d\n", j.ID)
+	env.Printf("Active X.509 authority ID: %s\n", j.ActiveX509AuthorityID)
+	env.Println()
+	env.Println("X.509 authorities:")
+	prettyPrintAuthorities(env, j.X509Authorities)
+	env.Println()
+	env.Println("JWT authorities:")
+	prettyPrintAuthorities(env, j.JWTAuthorities)
+	return nil
+}
+
+func prettyPrintAuthorities(env *commoncli.Env, authorities []Authority) {
+	if len(authorities) == 0 {
+		env.Println("  No authorities found")
+		return
+	}
+	for i, authority := range authorities {
+		if i > 0 {
+			env.Println()
+		}
+		env.Printf("  Authority ID: %s\n", authority.AuthorityID)
+		env.Printf("  Status: %s\n", authority.Status)
+		env.Printf("  Slot ID: %s\n", authority.SlotID)
+		if authority.UpstreamAuthorityID != "" {
+			env.Printf("  Upstream authority ID: %s\n", authority.UpstreamAuthorityID)
+		}
+		env.Printf("  Issued at: %s\n", authority.IssuedAt)
+		env.Printf("  Not after: %s\n", authority.NotAfter)
+	}
+}
+
+// checkServerStopped fails if a server answers on the API socket.
+func checkServerStopped(ctx context.Context, client util.ServerClient) error {
+	_, err := client.NewHealthClient().Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if status.Code(err) == codes.Unavailable {
+		return nil
+	}
+	return errors.New("a server is running on the API socket; stop the servers sharing the CA journal before forcing an authority active")
+}
+
+// loadDataStore connects to the SQL datastore configured in the server config
+// file at the given path. The command reads the CA journal while the servers
+// may be stopped, so it can't go through the server API. The schema is never
+// migrated, so loading fails if the server has not migrated the datastore to
+// the schema version of this binary yet.
+func loadDataStore(ctx context.Context, configPath string, expandEnv bool, log logrus.FieldLogger) (dataStore, error) {
+	config, err := run.ParseFile(configPath, expandEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	pluginConfigs, err := catalog.PluginConfigsFromHCLNode(config.Plugins)
+	if err != nil {
+		return nil, err
+	}
+	dsConfig, ok := pluginConfigs.Find("DataStore", sqlstore.PluginName)
+	if !ok || dsConfig.DataSource == nil {
+		return nil, fmt.Errorf("the server config has no %q DataStore plugin", sqlstore.PluginName)
+	}
+	data, err := dsConfig.DataSource.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load DataStore plugin data: %w", err)
+	}
+
+	ds := sqlstore.New(log)
+	if err := ds.Configure(ctx, data+"\ndisable_migration = true\n"); err != nil {
+		return nil, errors.Join(errors.New("failed to connect to the datastore"), err)
+	}
+	return ds, nil
+}
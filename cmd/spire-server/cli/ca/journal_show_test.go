@@ -0,0 +1,230 @@
+package ca
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/private/server/journal"
+	"github.com/spiffe/spire/test/clitest"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	issuedAt  = time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	notAfter  = issuedAt.Add(24 * time.Hour)
+	expiredAt = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func TestJournalShow(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		args           []string
+		expectCode     int
+		expectStdout   string
+		expectStderr   string
+		expectEntries  *journal.Entries
+		expectActiveID string
+	}{
+		{
+			name:       "missing authority ID",
+			expectCode: 1,
+			expectStderr: `Error: an X.509 authority ID is required
+`,
+		},
+		{
+			name:       "unknown authority ID",
+			args:       []string{"-authorityID", "unknown"},
+			expectCode: 1,
+			expectStderr: `Error: no CA journal found with active X.509 authority ID "unknown"
+`,
+		},
+		{
+			name: "pretty",
+			args: []string{"-authorityID", "x509-active"},
+			expectStdout: `CA journal ID: 1
+Active X.509 authority ID: x509-active
+
+X.509 authorities:
+  Authority ID: x509-old
+  Status: OLD
+  Slot ID: A
+  Issued at: 2030-01-01 00:00:00 +0000 UTC
+  Not after: 2030-01-02 00:00:00 +0000 UTC
+
+  Authority ID: x509-active
+  Status: ACTIVE
+  Slot ID: B
+  Upstream authority ID: upstream
+  Issued at: 2030-01-01 00:00:00 +0000 UTC
+  Not after: 2030-01-02 00:00:00 +0000 UTC
+
+  Authority ID: x509-prepared
+  Status: PREPARED
+  Slot ID: A
+  Issued at: 2030-01-01 00:00:00 +0000 UTC
+  Not after: 2030-01-02 00:00:00 +0000 UTC
+
+JWT authorities:
+  Authority ID: jwt-active
+  Status: ACTIVE
+  Slot ID: A
+  Issued at: 2030-01-01 00:00:00 +0000 UTC
+  Not after: 2030-01-02 00:00:00 +0000 UTC
+
+  Authority ID: jwt-expired
+  Status: PREPARED
+  Slot ID: B
+  Issued at: 2030-01-01 00:00:00 +0000 UTC
+  Not after: 2020-01-01 00:00:00 +0000 UTC
+`,
+		},
+		{
+			name: "json",
+			args: []string{"-authorityID", "x509-active", "-output", "json"},
+			expectStdout: `[{"id":1,"active_x509_authority_id":"x509-active","x509_authorities":[` +
+				`{"authority_id":"x509-old","status":"OLD","slot_id":"A","issued_at":"2030-01-01T00:00:00Z","not_after":"2030-01-02T00:00:00Z"},` +
+				`{"authority_id":"x509-active","status":"ACTIVE","slot_id":"B","upstream_authority_id":"upstream","issued_at":"2030-01-01T00:00:00Z","not_after":"2030-01-02T00:00:00Z"},` +
+				`{"authority_id":"x509-prepared","status":"PREPARED","slot_id":"A","issued_at":"2030-01-01T00:00:00Z","not_after":"2030-01-02T00:00:00Z"}],` +
+				`"jwt_authorities":[` +
+				`{"authority_id":"jwt-active","status":"ACTIVE","slot_id":"A","issued_at":"2030-01-01T00:00:00Z","not_after":"2030-01-02T00:00:00Z"},` +
+				`{"authority_id":"jwt-expired","status":"PREPARED","slot_id":"B","issued_at":"2030-01-01T00:00:00Z","not_after":"2020-01-01T00:00:00Z"}]}]
+`,
+		},
+		{
+			name: "force active X.509 authority",
+			args: []string{"-authorityID", "x509-active", "-forceActive", "x509-prepared", "-output", "json"},
+			expectEntries: func() *journal.Entries {
+				entries := syntheticEntries()
+				entries.X509CAs[1].Status = journal.Status_OLD
+				entries.X509CAs[2].Status = journal.Status_ACTIVE
+				return entries
+			}(),
+			expectActiveID: "x509-prepared",
+		},
+		{
+			name:       "force active already active JWT key",
+			args:       []string{"-authorityID", "x509-active", "-forceActive", "jwt-active", "-output", "json"},
+			expectCode: 1,
+			expectStderr: `Error: authority "jwt-active" is already active
+`,
+		},
+		{
+			name:       "force active expired JWT key",
+			args:       []string{"-authorityID", "x509-active", "-forceActive", "jwt-expired"},
+			expectCode: 1,
+			expectStderr: `Error: authority "jwt-expired" cannot be marked as active since it expired at 2020-01-01 00:00:00 +0000 UTC
+`,
+		},
+		{
+			name:       "force active unknown authority",
+			args:       []string{"-authorityID", "x509-active", "-forceActive", "unknown"},
+			expectCode: 1,
+			expectStderr: `Error: no authority found in the CA journal with ID "unknown"
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			data, err := proto.Marshal(syntheticEntries())
+			require.NoError(t, err)
+			_, err = ds.SetCAJournal(context.Background(), &datastore.CAJournal{
+				Data:                  data,
+				ActiveX509AuthorityID: "x509-active",
+			})
+			require.NoError(t, err)
+
+			stdout := new(bytes.Buffer)
+			stderr := new(bytes.Buffer)
+			cmd := newJournalShowCommand(&commoncli.Env{
+				Stdout: stdout,
+				Stderr: stderr,
+			}, func(context.Context, string, bool, logrus.FieldLogger) (dataStore, error) {
+				return nopCloser{ds}, nil
+			})
+
+			code := cmd.Run(append([]string{clitest.AddrArg, clitest.AddrValue}, tt.args...))
+			require.Equal(t, tt.expectCode, code, "exit code")
+			require.Equal(t, tt.expectStderr, stderr.String(), "stderr")
+			if tt.expectStdout != "" {
+				require.Equal(t, tt.expectStdout, stdout.String(), "stdout")
+			}
+
+			if tt.expectEntries == nil {
+				return
+			}
+			caJournal, err := ds.FetchCAJournal(context.Background(), tt.expectActiveID)
+			require.NoError(t, err)
+			require.NotNil(t, caJournal)
+			entries := new(journal.Entries)
+			require.NoError(t, proto.Unmarshal(caJournal.Data, entries))
+			require.True(t, proto.Equal(tt.expectEntries, entries), "unexpected journal entries: %v", entries)
+		})
+	}
+}
+
+func TestJournalShowLoadDataStoreFailure(t *testing.T) {
+	stderr := new(bytes.Buffer)
+	cmd := newJournalShowCommand(&commoncli.Env{
+		Stdout: new(bytes.Buffer),
+		Stderr: stderr,
+	}, func(context.Context, string, bool, logrus.FieldLogger) (dataStore, error) {
+		return nil, errors.New("oh no")
+	})
+
+	require.Equal(t, 1, cmd.Run([]string{clitest.AddrArg, clitest.AddrValue, "-authorityID", "x509-active"}))
+	require.Equal(t, "Error: could not load datastore: oh no\n", stderr.String())
+}
+
+func TestJournalShowForceActiveWhileServerRunning(t *testing.T) {
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+	})
+
+	loaded := false
+	stderr := new(bytes.Buffer)
+	cmd := newJournalShowCommand(&commoncli.Env{
+		Stdout: new(bytes.Buffer),
+		Stderr: stderr,
+	}, func(context.Context, string, bool, logrus.FieldLogger) (dataStore, error) {
+		loaded = true
+		return nil, errors.New("should not be called")
+	})
+
+	require.Equal(t, 1, cmd.Run([]string{clitest.AddrArg, clitest.GetAddr(addr), "-authorityID", "x509-active", "-forceActive", "x509-prepared"}))
+	require.Equal(t, "Error: a server is running on the API socket; stop the servers sharing the CA journal before forcing an authority active\n", stderr.String())
+	require.False(t, loaded, "datastore should not be loaded")
+}
+
+func syntheticEntries() *journal.Entries {
+	return &journal.Entries{
+		X509CAs: []*journal.X509CAEntry{
+			{SlotId: "A", AuthorityId: "x509-old", Status: journal.Status_OLD, IssuedAt: issuedAt.Unix(), NotAfter: notAfter.Unix()},
+			{SlotId: "B", AuthorityId: "x509-active", UpstreamAuthorityId: "upstream", Status: journal.Status_ACTIVE, IssuedAt: issuedAt.Unix(), NotAfter: notAfter.Unix()},
+			{SlotId: "A", AuthorityId: "x509-prepared", Status: journal.Status_PREPARED, IssuedAt: issuedAt.Unix(), NotAfter: notAfter.Unix()},
+		},
+		JwtKeys: []*journal.JWTKeyEntry{
+			{SlotId: "A", AuthorityId: "jwt-active", Kid: "jwt-active", Status: journal.Status_ACTIVE, IssuedAt: issuedAt.Unix(), NotAfter: notAfter.Unix()},
+			{SlotId: "B", AuthorityId: "jwt-expired", Kid: "jwt-expired", Status: journal.Status_PREPARED, IssuedAt: issuedAt.Unix(), NotAfter: expiredAt.Unix()},
+		},
+	}
+}
+
+type nopCloser struct {
+	*fakedatastore.DataStore
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/spire/cmd/spire-server/cli/agent"
 	"github.com/spiffe/spire/cmd/spire-server/cli/bundle"
+	"github.com/spiffe/spire/cmd/spire-server/cli/ca"
 	"github.com/spiffe/spire/cmd/spire-server/cli/entry"
 	"github.com/spiffe/spire/cmd/spire-server/cli/federation"
 	"github.com/spiffe/spire/cmd/spire-server/cli/healthcheck"
@@ -14,6 +15,7 @@ import (
 	localauthority_jwt "github.com/spiffe/spire/cmd/spire-server/cli/localauthority/jwt"
 	localauthority_x509 "github.com/spiffe/spire/cmd/spire-server/cli/localauthority/x509"
 	"github.com/spiffe/spire/cmd/spire-server/cli/logger"
+	"github.com/spiffe/spire/cmd/spire-server/cli/prune"
 	"github.com/spiffe/spire/cmd/spire-server/cli/run"
 	"github.com/spiffe/spire/cmd/spire-server/cli/token"
 	"github.com/spiffe/spire/cmd/spire-server/cli/upstreamauthority"
@@ -34,6 +36,9 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 	c := cli.NewCLI("kirin-server", version.Version())
 	c.Args = args
 	c.Commands = map[string]cli.CommandFactory{
+		"agent alias": func() (cli.Command, error) {
+			return agent.NewAliasCommand(), nil
+		},
 		"agent ban": func() (cli.Command, error) {
 			return agent.NewBanCommand(), nil
 		},
@@ -52,6 +57,9 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"agent purge": func() (cli.Command, error) {
 			return agent.NewPurgeCommand(), nil
 		},
+		"agent reattest": func() (cli.Command, error) {
+			return agent.NewReattestCommand(), nil
+		},
 		"bundle count": func() (cli.Command, error) {
 			return bundle.NewCountCommand(), nil
 		},
@@ -67,6 +75,9 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"bundle delete": func() (cli.Command, error) {
 			return bundle.NewDeleteCommand(), nil
 		},
+		"ca journal show": func() (cli.Command, error) {
+			return ca.NewJournalShowCommand(), nil
+		},
 		"entry count": func() (cli.Command, error) {
 			return entry.NewCountCommand(), nil
 		},
@@ -76,6 +87,15 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"entry update": func() (cli.Command, error) {
 			return entry.NewUpdateCommand(), nil
 		},
+		"entry export": func() (cli.Command, error) {
+			return entry.NewExportCommand(), nil
+		},
+		"entry gc": func() (cli.Command, error) {
+			return entry.NewGCCommand(), nil
+		},
+		"entry import": func() (cli.Command, error) {
+			return entry.NewImportCommand(), nil
+		},
 		"entry delete": func() (cli.Command, error) {
 			return entry.NewDeleteCommand(), nil
 		},
@@ -109,6 +129,9 @@ func (cc *CLI) Run(ctx context.Context, args []string) int {
 		"logger reset": func() (cli.Command, error) {
 			return logger.NewResetCommand(), nil
 		},
+		"prune": func() (cli.Command, error) {
+			return prune.NewPruneCommand(), nil
+		},
 		"run": func() (cli.Command, error) {
 			return run.NewRunCommand(ctx, cc.LogOptions, cc.AllowUnknownConfig), nil
 		},
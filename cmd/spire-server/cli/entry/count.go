@@ -2,6 +2,7 @@ package entry
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 
@@ -11,6 +12,7 @@ import (
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
@@ -26,7 +28,7 @@ type countCommand struct {
 	spiffeID string
 
 	// Entry hint
-	hint string
+	hint stringFilterFlag
 
 	// List of SPIFFE IDs of trust domains the registration entry is federated with
 	federatesWith StringsFlag
@@ -40,6 +42,9 @@ type countCommand struct {
 	// Match used when filtering by selectors
 	matchSelectorsOn string
 
+	// Whether to count entries per trust domain
+	byTrustDomain bool
+
 	printer cliprinter.Printer
 	env     *commoncli.Env
 }
@@ -65,6 +70,10 @@ func (*countCommand) Synopsis() string {
 
 // Run counts attested entries
 func (c *countCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	if c.byTrustDomain {
+		return c.countByTrustDomain(ctx, serverClient)
+	}
+
 	entryClient := serverClient.NewEntryClient()
 
 	filter := &entryv1.CountEntriesRequest_Filter{}
@@ -118,9 +127,7 @@ func (c *countCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient u
 		}
 	}
 
-	if c.hint != "" {
-		filter.ByHint = wrapperspb.String(c.hint)
-	}
+	filter.ByHint = c.hint.StringValue()
 
 	countResponse, err := entryClient.CountEntries(ctx, &entryv1.CountEntriesRequest{
 		Filter: filter,
@@ -141,12 +148,39 @@ func (c *countCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.Var(&c.federatesWith, "federatesWith", "SPIFFE ID of a trust domain an entry is federate with. Can be used more than once")
 	fs.StringVar(&c.matchFederatesWithOn, "matchFederatesWithOn", "superset", "The match mode used when filtering by federates with. Options: exact, any, superset and subset")
 	fs.StringVar(&c.matchSelectorsOn, "matchSelectorsOn", "superset", "The match mode used when filtering by selectors. Options: exact, any, superset and subset")
-	fs.StringVar(&c.hint, "hint", "", "The Hint of the records to count (optional)")
+	fs.Var(&c.hint, "hint", "The Hint of the records to count (optional). Use -hint \"\" to count only entries without a hint")
+	fs.BoolVar(&c.byTrustDomain, "byTrustDomain", false, "If set, all entries are counted per trust domain of their SPIFFE ID. This flag can't be combined with filters")
 
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintCount)
 }
 
+// countByTrustDomain counts all the entries per trust domain with a single
+// grouped query on the server.
+func (c *countCommand) countByTrustDomain(ctx context.Context, serverClient util.ServerClient) error {
+	if c.parentID != "" || c.spiffeID != "" || len(c.selectors) > 0 || len(c.federatesWith) > 0 ||
+		c.downstream || c.hint.StringValue() != nil {
+		return errors.New("-byTrustDomain cannot be combined with filters")
+	}
+
+	resp, err := serverClient.NewEntryExtensionClient().CountEntriesByTrustDomain(ctx, &extensionv1.CountEntriesByTrustDomainRequest{})
+	if err != nil {
+		return err
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
 func (c *countCommand) prettyPrintCount(env *commoncli.Env, results ...any) error {
+	if counts, ok := results[0].(*extensionv1.CountEntriesByTrustDomainResponse); ok {
+		for _, tdCount := range counts.TrustDomains {
+			count := int(tdCount.Count)
+			msg := fmt.Sprintf("%d registration ", count)
+			msg = util.Pluralizer(msg, "entry", "entries", count)
+			env.Printf("%s in %q\n", msg, tdCount.TrustDomain)
+		}
+		return nil
+	}
+
 	countResp, ok := results[0].(*entryv1.CountEntriesResponse)
 	if !ok {
 		return cliprinter.ErrInternalCustomPrettyFunc
@@ -6,6 +6,7 @@ import (
 
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -340,3 +341,57 @@ func TestCount(t *testing.T) {
 		}
 	}
 }
+
+func TestCountByTrustDomain(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		args         []string
+		serverErr    error
+		expOutPretty string
+		expOutJSON   string
+		expErr       string
+	}{
+		{
+			name:         "Count all entries",
+			args:         []string{"-byTrustDomain"},
+			expOutPretty: "1 registration entry in \"domain.test\"\n2 registration entries in \"example.org\"\n",
+			expOutJSON:   `{"trust_domains":[{"trust_domain":"domain.test","count":1},{"trust_domain":"example.org","count":2}]}`,
+		},
+		{
+			name:   "Combined with a filter",
+			args:   []string{"-byTrustDomain", "-parentID", "spiffe://example.org/father"},
+			expErr: "Error: -byTrustDomain cannot be combined with filters\n",
+		},
+		{
+			name:      "Server error",
+			args:      []string{"-byTrustDomain"},
+			serverErr: status.Error(codes.Internal, "internal server error"),
+			expErr:    "Error: rpc error: code = Internal desc = internal server error\n",
+		},
+	} {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				test := setupTest(t, NewCountCommandWithEnv)
+				test.extensionServer.countEntriesByTrustDomainResp = &extensionv1.CountEntriesByTrustDomainResponse{
+					TrustDomains: []*extensionv1.TrustDomainEntryCount{
+						{TrustDomain: "domain.test", Count: 1},
+						{TrustDomain: "example.org", Count: 2},
+					},
+				}
+				test.extensionServer.err = tt.serverErr
+
+				args := tt.args
+				args = append(args, "-output", format)
+
+				rc := test.client.Run(test.args(args...))
+				if tt.expErr != "" {
+					require.Equal(t, 1, rc)
+					require.Equal(t, tt.expErr, test.stderr.String())
+					return
+				}
+				require.Equal(t, 0, rc, test.stderr.String())
+				requireOutputBasedOnFormat(t, format, test.stdout.String(), tt.expOutPretty, tt.expOutJSON)
+			})
+		}
+	}
+}
@@ -2,9 +2,11 @@ package entry
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"slices"
 
 	"github.com/mitchellh/cli"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
@@ -14,7 +16,10 @@ import (
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 // NewCreateCommand creates a new "create" subcommand for "entry" command.
@@ -31,6 +36,10 @@ type createCommand struct {
 	// opts will be ignored.
 	path string
 
+	// Path to an optional file holding a JSON or YAML array of entries. If
+	// set, other opts will be ignored.
+	filePath string
+
 	// Type and value are delimited by a colon (:)
 	// ex. "unix:uid:1000" or "spiffe_id:spiffe://example.org/foo"
 	selectors StringsFlag
@@ -74,6 +83,10 @@ type createCommand struct {
 	// storeSVID determines if the issued SVID must be stored through an SVIDStore plugin
 	storeSVID bool
 
+	// Client-supplied key that makes retried creations return the entry
+	// created by the first attempt
+	idempotencyKey string
+
 	printer cliprinter.Printer
 
 	env *commoncli.Env
@@ -91,9 +104,10 @@ func (c *createCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.entryID, "entryID", "", "A custom ID for this registration entry (optional). If not set, a new entry ID will be generated")
 	f.StringVar(&c.parentID, "parentID", "", "The SPIFFE ID of this record's parent")
 	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID that this record represents")
-	f.IntVar(&c.x509SVIDTTL, "x509SVIDTTL", 0, "The lifetime, in seconds, for x509-SVIDs issued based on this registration entry.")
-	f.IntVar(&c.jwtSVIDTTL, "jwtSVIDTTL", 0, "The lifetime, in seconds, for JWT-SVIDs issued based on this registration entry.")
+	f.Var((*ttlFlag)(&c.x509SVIDTTL), "x509SVIDTTL", "The lifetime, in seconds or as a `duration` (e.g. 30m), for x509-SVIDs issued based on this registration entry.")
+	f.Var((*ttlFlag)(&c.jwtSVIDTTL), "jwtSVIDTTL", "The lifetime, in seconds or as a `duration` (e.g. 30m), for JWT-SVIDs issued based on this registration entry.")
 	f.StringVar(&c.path, "data", "", "Path to a file containing registration JSON (optional). If set to '-', read the JSON from stdin.")
+	f.StringVar(&c.filePath, "file", "", "Path to a file containing a JSON or YAML array of registration entries (optional). Invalid entries are reported and skipped. If set to '-', read from stdin.")
 	f.Var(&c.selectors, "selector", "A colon-delimited type:value selector. Can be used more than once")
 	f.Var(&c.federatesWith, "federatesWith", "SPIFFE ID of a trust domain to federate with. Can be used more than once")
 	f.BoolVar(&c.node, "node", false, "If set, this entry will be applied to matching nodes rather than workloads")
@@ -103,6 +117,7 @@ func (c *createCommand) AppendFlags(f *flag.FlagSet) {
 	f.Int64Var(&c.entryExpiry, "entryExpiry", 0, "An expiry, from epoch in seconds, for the resulting registration entry to be pruned")
 	f.Var(&c.dnsNames, "dns", "A DNS name that will be included in SVIDs issued based on this entry, where appropriate. Can be used more than once")
 	f.StringVar(&c.hint, "hint", "", "The entry hint, used to disambiguate entries with the same SPIFFE ID")
+	f.StringVar(&c.idempotencyKey, "idempotencyKey", "", "A client-supplied key for the entry (optional). If an entry was already created with the same key, that entry is returned instead of creating a new one")
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintCreate)
 }
 
@@ -111,6 +126,10 @@ func (c *createCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient
 		return err
 	}
 
+	if c.filePath != "" {
+		return c.createFromFile(ctx, serverClient.NewEntryClient())
+	}
+
 	var entries []*types.Entry
 	var err error
 	if c.path != "" {
@@ -122,7 +141,15 @@ func (c *createCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient
 		return err
 	}
 
-	resp, err := createEntries(ctx, serverClient.NewEntryClient(), entries)
+	var idempotencyKeys []string
+	if c.idempotencyKey != "" {
+		if len(entries) != 1 {
+			return errors.New("-idempotencyKey can only be used when creating a single entry")
+		}
+		idempotencyKeys = []string{c.idempotencyKey}
+	}
+
+	resp, err := createEntries(ctx, serverClient.NewEntryClient(), entries, idempotencyKeys)
 	if err != nil {
 		return err
 	}
@@ -133,8 +160,16 @@ func (c *createCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient
 // validate performs basic validation, even on fields that we
 // have defaults defined for.
 func (c *createCommand) validate() (err error) {
+	if c.path != "" && c.filePath != "" {
+		return errors.New("only one of -data or -file may be set")
+	}
+
+	if c.filePath != "" && c.idempotencyKey != "" {
+		return errors.New("-idempotencyKey can not be used with -file; set idempotency_key on each entry in the file instead")
+	}
+
 	// If a path is set, we have all we need
-	if c.path != "" {
+	if c.path != "" || c.filePath != "" {
 		return nil
 	}
 
@@ -216,7 +251,16 @@ func (c *createCommand) parseConfig() ([]*types.Entry, error) {
 	return []*types.Entry{e}, nil
 }
 
-func createEntries(ctx context.Context, c entryv1.EntryClient, entries []*types.Entry) (resp *entryv1.BatchCreateEntryResponse, err error) {
+// createEntries creates the given entries. If idempotencyKeys is set, it
+// holds the key of each entry, or an empty string for entries without one.
+// types.Entry can't carry the keys, so they are sent as request metadata.
+func createEntries(ctx context.Context, c entryv1.EntryClient, entries []*types.Entry, idempotencyKeys []string) (resp *entryv1.BatchCreateEntryResponse, err error) {
+	if slices.ContainsFunc(idempotencyKeys, func(key string) bool { return key != "" }) {
+		for _, key := range idempotencyKeys {
+			ctx = metadata.AppendToOutgoingContext(ctx, api.IdempotencyKeyMetadataKey, key)
+		}
+	}
+
 	resp, err = c.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{Entries: entries})
 	if err != nil {
 		return
@@ -233,6 +277,87 @@ func createEntries(ctx context.Context, c entryv1.EntryClient, entries []*types.
 	return
 }
 
+// createFromFile creates the entries listed in the file. Entries that fail
+// validation are reported alongside the creation results instead of aborting
+// the whole batch.
+func (c *createCommand) createFromFile(ctx context.Context, client entryv1.EntryClient) error {
+	specs, err := parseEntrySpecsFile(c.filePath)
+	if err != nil {
+		return err
+	}
+
+	results := make([]*entryv1.BatchCreateEntryResponse_Result, len(specs))
+	var entries []*types.Entry
+	var idempotencyKeys []string
+	var indices []int
+	for i, spec := range specs {
+		entry, idempotencyKey, err := entryFromSpec(spec)
+		if err != nil {
+			results[i] = &entryv1.BatchCreateEntryResponse_Result{
+				Status: &types.Status{
+					Code:    int32(codes.InvalidArgument),
+					Message: fmt.Sprintf("invalid entry at index %d: %v", i, err),
+				},
+				Entry: entry,
+			}
+			continue
+		}
+		entries = append(entries, entry)
+		idempotencyKeys = append(idempotencyKeys, idempotencyKey)
+		indices = append(indices, i)
+	}
+
+	if len(entries) > 0 {
+		resp, err := createEntries(ctx, client, entries, idempotencyKeys)
+		if err != nil {
+			return err
+		}
+		for i, r := range resp.Results {
+			results[indices[i]] = r
+		}
+	}
+
+	resp := &entryv1.BatchCreateEntryResponse{Results: results}
+	if err := c.printer.PrintProto(resp); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if r.Status.Code != int32(codes.OK) {
+			return errors.New("failed to create one or more entries")
+		}
+	}
+	return nil
+}
+
+// entryFromSpec converts an entry read from a file into its API
+// representation, also returning its idempotency key. On failure, the
+// returned entry holds whatever could be parsed so it can still be shown to
+// the user.
+func entryFromSpec(raw json.RawMessage) (*types.Entry, string, error) {
+	spec := &common.RegistrationEntry{}
+	if err := json.Unmarshal(raw, spec); err != nil {
+		return &types.Entry{}, "", err
+	}
+
+	entry, err := api.RegistrationEntryToProto(spec)
+	if err != nil {
+		return &types.Entry{
+			Id:          spec.EntryId,
+			Selectors:   api.ProtoFromSelectors(spec.Selectors),
+			X509SvidTtl: spec.X509SvidTtl,
+			JwtSvidTtl:  spec.JwtSvidTtl,
+			Hint:        spec.Hint,
+		}, "", err
+	}
+
+	if len(entry.Selectors) < 1 {
+		return entry, "", errors.New("at least one selector is required")
+	}
+
+	return entry, spec.IdempotencyKey, nil
+}
+
 func getParentID(config *createCommand, td string) (*types.SPIFFEID, error) {
 	// If the node flag is set, then set the Parent ID to the server's expected SPIFFE ID
 	if config.node {
@@ -261,14 +386,14 @@ func prettyPrintCreate(env *commoncli.Env, results ...any) error {
 	}
 
 	for _, r := range succeeded {
-		printEntry(r.Entry, env.Printf)
+		printEntry(r.Entry, nil, env.Printf)
 	}
 
 	for _, r := range failed {
 		env.ErrPrintf("Failed to create the following entry (code: %s, msg: %q):\n",
 			util.MustCast[codes.Code](r.Status.Code),
 			r.Status.Message)
-		printEntry(r.Entry, env.ErrPrintf)
+		printEntry(r.Entry, nil, env.ErrPrintf)
 	}
 
 	if len(failed) > 0 {
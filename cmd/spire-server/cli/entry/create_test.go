@@ -3,6 +3,8 @@ package entry
 import (
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -698,3 +700,243 @@ Error: failed to create one or more entries
 		}
 	}
 }
+
+func TestCreateFromFile(t *testing.T) {
+	validEntry := &types.Entry{
+		SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/Blog"},
+		ParentId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/join_token/TokenBlog"},
+		Selectors:   []*types.Selector{{Type: "unix", Value: "uid:1111"}},
+		X509SvidTtl: 200,
+		JwtSvidTtl:  30,
+	}
+	createdEntry := &types.Entry{
+		Id:          "entry-id-1",
+		SpiffeId:    validEntry.SpiffeId,
+		ParentId:    validEntry.ParentId,
+		Selectors:   validEntry.Selectors,
+		X509SvidTtl: validEntry.X509SvidTtl,
+		JwtSvidTtl:  validEntry.JwtSvidTtl,
+	}
+	fakeResp := &entryv1.BatchCreateEntryResponse{
+		Results: []*entryv1.BatchCreateEntryResponse_Result{
+			{
+				Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+				Entry:  createdEntry,
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		expReq    *entryv1.BatchCreateEntryRequest
+		fakeResp  *entryv1.BatchCreateEntryResponse
+		serverErr error
+
+		expOutPretty string
+		expOutJSON   string
+		expErr       string
+	}{
+		{
+			name:     "YAML file with a valid and an invalid entry",
+			args:     []string{"-file", "../../../../test/fixture/registration/batch.yaml"},
+			expReq:   &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{validEntry}},
+			fakeResp: fakeResp,
+			expOutPretty: `Entry ID         : entry-id-1
+SPIFFE ID        : spiffe://example.org/Blog
+Parent ID        : spiffe://example.org/spire/agent/join_token/TokenBlog
+Revision         : 0
+X509-SVID TTL    : 200
+JWT-SVID TTL     : 30
+Selector         : unix:uid:1111
+
+`,
+			expOutJSON: `{
+  "results": [
+    {
+      "status": {
+        "code": 0,
+        "message": "OK"
+      },
+      "entry": {
+        "id": "entry-id-1",
+        "spiffe_id": {
+          "trust_domain": "example.org",
+          "path": "/Blog"
+        },
+        "parent_id": {
+          "trust_domain": "example.org",
+          "path": "/spire/agent/join_token/TokenBlog"
+        },
+        "selectors": [
+          {
+            "type": "unix",
+            "value": "uid:1111"
+          }
+        ],
+        "x509_svid_ttl": 200,
+        "federates_with": [],
+        "hint": "",
+        "admin": false,
+        "created_at": "0",
+        "downstream": false,
+        "expires_at": "0",
+        "dns_names": [],
+        "revision_number": "0",
+        "store_svid": false,
+        "jwt_svid_ttl": 30
+      }
+    },
+    {
+      "status": {
+        "code": 3,
+        "message": "invalid entry at index 1: invalid SPIFFE ID: scheme is missing or invalid"
+      },
+      "entry": {
+        "id": "",
+        "selectors": [
+          {
+            "type": "unix",
+            "value": "uid:1112"
+          }
+        ],
+        "x509_svid_ttl": 0,
+        "federates_with": [],
+        "hint": "",
+        "admin": false,
+        "created_at": "0",
+        "downstream": false,
+        "expires_at": "0",
+        "dns_names": [],
+        "revision_number": "0",
+        "store_svid": false,
+        "jwt_svid_ttl": 0
+      }
+    }
+  ]
+}`,
+			expErr: "failed to create one or more entries",
+		},
+		{
+			name:     "JSON file with a valid and an invalid entry",
+			args:     []string{"-file", "../../../../test/fixture/registration/batch.json"},
+			expReq:   &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{validEntry}},
+			fakeResp: fakeResp,
+			expOutPretty: `Entry ID         : entry-id-1
+SPIFFE ID        : spiffe://example.org/Blog
+Parent ID        : spiffe://example.org/spire/agent/join_token/TokenBlog
+Revision         : 0
+X509-SVID TTL    : 200
+JWT-SVID TTL     : 30
+Selector         : unix:uid:1111
+
+`,
+			expErr: "failed to create one or more entries",
+		},
+		{
+			name:   "Data and file set at the same time",
+			args:   []string{"-data", "../../../../test/fixture/registration/good.json", "-file", "../../../../test/fixture/registration/batch.json"},
+			expErr: "only one of -data or -file may be set",
+		},
+		{
+			name:   "File is not an array",
+			args:   []string{"-file", "../../../../test/fixture/registration/good.json"},
+			expErr: "failed to parse entries file",
+		},
+		{
+			name:      "Server error",
+			args:      []string{"-file", "../../../../test/fixture/registration/batch.yaml"},
+			serverErr: errors.New("server-error"),
+			expErr:    "rpc error: code = Unknown desc = server-error",
+		},
+	} {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				test := setupTest(t, newCreateCommand)
+				test.server.err = tt.serverErr
+				test.server.expBatchCreateEntryReq = tt.expReq
+				test.server.batchCreateEntryResp = tt.fakeResp
+				args := tt.args
+				args = append(args, "-output", format)
+
+				rc := test.client.Run(test.args(args...))
+
+				if tt.expErr != "" {
+					require.Equal(t, 1, rc)
+					require.Contains(t, test.stderr.String(), "Error: "+tt.expErr)
+				} else {
+					require.Equal(t, 0, rc)
+				}
+				if format == "pretty" && tt.expOutPretty != "" {
+					require.Equal(t, tt.expOutPretty, test.stdout.String())
+				}
+				if format == "json" && tt.expOutJSON != "" {
+					require.JSONEq(t, tt.expOutJSON, test.stdout.String())
+				}
+			})
+		}
+	}
+}
+
+func TestCreateIdempotencyKey(t *testing.T) {
+	entry := &types.Entry{
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+		ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1111"}},
+	}
+	okResult := &entryv1.BatchCreateEntryResponse_Result{
+		Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+		Entry:  entry,
+	}
+
+	entriesPath := filepath.Join(t.TempDir(), "entries.json")
+	require.NoError(t, os.WriteFile(entriesPath, []byte(`[
+	{"spiffe_id": "spiffe://example.org/workload", "parent_id": "spiffe://example.org/parent", "selectors": [{"type": "unix", "value": "uid:1111"}], "idempotency_key": "key-1"},
+	{"spiffe_id": "spiffe://example.org/workload", "parent_id": "spiffe://example.org/parent", "selectors": [{"type": "unix", "value": "uid:1111"}]}
+]`), 0o600))
+
+	for _, tt := range []struct {
+		name    string
+		args    []string
+		expReq  *entryv1.BatchCreateEntryRequest
+		expKeys []string
+		expErr  string
+	}{
+		{
+			name:    "key from flag",
+			args:    []string{"-spiffeID", "spiffe://example.org/workload", "-parentID", "spiffe://example.org/parent", "-selector", "unix:uid:1111", "-idempotencyKey", "key-1"},
+			expReq:  &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{entry}},
+			expKeys: []string{"key-1"},
+		},
+		{
+			name:    "keys from file",
+			args:    []string{"-file", entriesPath},
+			expReq:  &entryv1.BatchCreateEntryRequest{Entries: []*types.Entry{entry, entry}},
+			expKeys: []string{"key-1", ""},
+		},
+		{
+			name:   "flag with file",
+			args:   []string{"-file", entriesPath, "-idempotencyKey", "key-1"},
+			expErr: "Error: -idempotencyKey can not be used with -file; set idempotency_key on each entry in the file instead\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newCreateCommand)
+			test.server.expBatchCreateEntryReq = tt.expReq
+			test.server.expIdempotencyKeys = tt.expKeys
+			test.server.batchCreateEntryResp = &entryv1.BatchCreateEntryResponse{}
+			for range tt.expKeys {
+				test.server.batchCreateEntryResp.Results = append(test.server.batchCreateEntryResp.Results, okResult)
+			}
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+			require.Equal(t, 0, rc, test.stderr.String())
+		})
+	}
+}
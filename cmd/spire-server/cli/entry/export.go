@@ -0,0 +1,156 @@
+package entry
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"sort"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/diskutil"
+	commonutil "github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/proto/spire/common"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	exportFormatJSON = "json"
+	exportFormatYAML = "yaml"
+)
+
+// NewExportCommand creates a new "export" subcommand for "entry" command.
+func NewExportCommand() cli.Command {
+	return newExportCommand(commoncli.DefaultEnv)
+}
+
+func newExportCommand(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &exportCommand{env: env})
+}
+
+type exportCommand struct {
+	// Filters used to select the entries to export. They have the same
+	// meaning as in "entry show".
+	filter showCommand
+
+	// Path to the file the entries are written to. If empty, the entries
+	// are written to stdout.
+	path string
+
+	// Format of the document, json or yaml
+	format string
+
+	env *commoncli.Env
+}
+
+func (*exportCommand) Name() string {
+	return "entry export"
+}
+
+func (*exportCommand) Synopsis() string {
+	return "Exports registration entries to a JSON or YAML file"
+}
+
+func (c *exportCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.path, "file", "", "Path to the file the entries are written to, readable only by its owner (optional). If not set, the entries are written to stdout")
+	f.StringVar(&c.format, "format", exportFormatJSON, "The format of the document. Options: json and yaml")
+	f.StringVar(&c.filter.parentID, "parentID", "", "The Parent ID of the records to export")
+	f.StringVar(&c.filter.spiffeID, "spiffeID", "", "The SPIFFE ID of the records to export")
+	f.BoolVar(&c.filter.downstream, "downstream", false, "A boolean value that, when set, indicates that the entry describes a downstream SPIRE server")
+	f.Var(&c.filter.selectors, "selector", "A colon-delimited type:value selector. Can be used more than once")
+	f.Var(&c.filter.federatesWith, "federatesWith", "SPIFFE ID of a trust domain an entry is federate with. Can be used more than once")
+	f.StringVar(&c.filter.matchFederatesWithOn, "matchFederatesWithOn", "superset", "The match mode used when filtering by federates with. Options: exact, any, superset and subset")
+	f.StringVar(&c.filter.matchSelectorsOn, "matchSelectorsOn", "superset", "The match mode used when filtering by selectors. Options: exact, any, superset and subset")
+	f.Var(&c.filter.hint, "hint", "The Hint of the records to export (optional). Use -hint \"\" to export only entries without a hint")
+}
+
+// Run executes all logic associated with a single invocation of the
+// `spire-server entry export` CLI command
+func (c *exportCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	if c.format != exportFormatJSON && c.format != exportFormatYAML {
+		return fmt.Errorf("unsupported format %q; options are json and yaml", c.format)
+	}
+
+	resp, err := c.filter.fetchEntries(ctx, serverClient.NewEntryClient())
+	if err != nil {
+		return err
+	}
+
+	data, err := marshalEntryExport(resp.Entries)
+	if err != nil {
+		return err
+	}
+	if c.format == exportFormatYAML {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	}
+
+	if c.path == "" {
+		_, err = c.env.Stdout.Write(data)
+		return err
+	}
+	return diskutil.WritePrivateFile(c.path, data)
+}
+
+// marshalEntryExport serializes the entries in the format read by "entry
+// create -data" and "entry import". Fields assigned by the server, other
+// than the entry ID, are left out, and entries are sorted so that exporting
+// the same entries always gives the same document.
+func marshalEntryExport(entries []*types.Entry) ([]byte, error) {
+	commonutil.SortTypesEntries(entries)
+
+	doc := &common.RegistrationEntries{
+		Entries: make([]*common.RegistrationEntry, 0, len(entries)),
+	}
+	for _, e := range entries {
+		entry, err := entryToExport(e)
+		if err != nil {
+			return nil, fmt.Errorf("cannot export entry %q: %w", e.Id, err)
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func entryToExport(e *types.Entry) (*common.RegistrationEntry, error) {
+	selectors := make([]*common.Selector, 0, len(e.Selectors))
+	for _, s := range e.Selectors {
+		selectors = append(selectors, &common.Selector{Type: s.Type, Value: s.Value})
+	}
+
+	var federatesWith []string
+	for _, name := range e.FederatesWith {
+		td, err := spiffeid.TrustDomainFromString(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid federated trust domain: %w", err)
+		}
+		federatesWith = append(federatesWith, td.IDString())
+	}
+	sort.Strings(federatesWith)
+
+	return &common.RegistrationEntry{
+		EntryId:       e.Id,
+		ParentId:      protoToIDString(e.ParentId),
+		SpiffeId:      protoToIDString(e.SpiffeId),
+		Selectors:     selectors,
+		X509SvidTtl:   e.X509SvidTtl,
+		JwtSvidTtl:    e.JwtSvidTtl,
+		FederatesWith: federatesWith,
+		Admin:         e.Admin,
+		Downstream:    e.Downstream,
+		EntryExpiry:   e.ExpiresAt,
+		DnsNames:      e.DnsNames,
+		StoreSvid:     e.StoreSvid,
+		Hint:          e.Hint,
+	}, nil
+}
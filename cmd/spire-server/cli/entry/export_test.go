@@ -0,0 +1,228 @@
+package entry
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestExportHelp(t *testing.T) {
+	test := setupTest(t, newExportCommand)
+	test.client.Help()
+
+	require.Equal(t, exportUsage, test.stderr.String())
+}
+
+func TestExportSynopsis(t *testing.T) {
+	test := setupTest(t, newExportCommand)
+	require.Equal(t, "Exports registration entries to a JSON or YAML file", test.client.Synopsis())
+}
+
+func TestExport(t *testing.T) {
+	entries := []*types.Entry{
+		{
+			Id:       "entry-2",
+			ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+			SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+			Selectors: []*types.Selector{
+				{Type: "unix", Value: "uid:1000"},
+				{Type: "k8s", Value: "ns:default"},
+			},
+			X509SvidTtl:    3600,
+			JwtSvidTtl:     300,
+			FederatesWith:  []string{"spiffe://domainb.test", "domaina.test"},
+			DnsNames:       []string{"workload.example.org", "alt.example.org"},
+			Hint:           "external",
+			ExpiresAt:      1552410266,
+			RevisionNumber: 3,
+			CreatedAt:      1547583197,
+		},
+		{
+			Id:         "entry-1",
+			ParentId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/server"},
+			SpiffeId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+			Selectors:  []*types.Selector{{Type: "join_token", Value: "token"}},
+			Admin:      true,
+			Downstream: true,
+			StoreSvid:  true,
+			CreatedAt:  1547583197,
+		},
+	}
+
+	expectedExport := `{
+  "entries": [
+    {
+      "selectors": [
+        {
+          "type": "join_token",
+          "value": "token"
+        }
+      ],
+      "parent_id": "spiffe://example.org/spire/server",
+      "spiffe_id": "spiffe://example.org/agent",
+      "entry_id": "entry-1",
+      "admin": true,
+      "downstream": true,
+      "store_svid": true
+    },
+    {
+      "selectors": [
+        {
+          "type": "k8s",
+          "value": "ns:default"
+        },
+        {
+          "type": "unix",
+          "value": "uid:1000"
+        }
+      ],
+      "parent_id": "spiffe://example.org/parent",
+      "spiffe_id": "spiffe://example.org/workload",
+      "x509_svid_ttl": 3600,
+      "federates_with": [
+        "spiffe://domaina.test",
+        "spiffe://domainb.test"
+      ],
+      "entry_id": "entry-2",
+      "entryExpiry": 1552410266,
+      "dns_names": [
+        "workload.example.org",
+        "alt.example.org"
+      ],
+      "jwt_svid_ttl": 300,
+      "hint": "external"
+    }
+  ]
+}
+`
+
+	for _, tt := range []struct {
+		name string
+		args []string
+
+		expListReq   *entryv1.ListEntriesRequest
+		fakeListResp *entryv1.ListEntriesResponse
+		serverErr    error
+
+		expErr string
+	}{
+		{
+			name: "Export all entries",
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			fakeListResp: &entryv1.ListEntriesResponse{Entries: entries},
+		},
+		{
+			name: "Export filtered entries",
+			args: []string{"-parentID", "spiffe://example.org/parent"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByParentId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			fakeListResp: &entryv1.ListEntriesResponse{Entries: entries},
+		},
+		{
+			name:   "Unsupported format",
+			args:   []string{"-format", "xml"},
+			expErr: "Error: unsupported format \"xml\"; options are json and yaml\n",
+		},
+		{
+			name:      "Server error",
+			serverErr: errors.New("server-error"),
+			expErr:    "Error: error fetching entries: rpc error: code = Unknown desc = server-error\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newExportCommand)
+			test.server.err = tt.serverErr
+			test.server.expListEntriesReq = tt.expListReq
+			test.server.listEntriesResp = tt.fakeListResp
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Equal(t, expectedExport, test.stdout.String())
+		})
+	}
+
+	t.Run("Export as YAML", func(t *testing.T) {
+		test := setupTest(t, newExportCommand)
+		test.server.expListEntriesReq = &entryv1.ListEntriesRequest{
+			PageSize: listEntriesRequestPageSize,
+			Filter: &entryv1.ListEntriesRequest_Filter{
+				ByDownstream: wrapperspb.Bool(false),
+			},
+		}
+		test.server.listEntriesResp = &entryv1.ListEntriesResponse{Entries: entries}
+
+		rc := test.client.Run(test.args("-format", "yaml"))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Equal(t, `entries:
+- admin: true
+  downstream: true
+  entry_id: entry-1
+  parent_id: spiffe://example.org/spire/server
+  selectors:
+  - type: join_token
+    value: token
+  spiffe_id: spiffe://example.org/agent
+  store_svid: true
+- dns_names:
+  - workload.example.org
+  - alt.example.org
+  entry_id: entry-2
+  entryExpiry: 1552410266
+  federates_with:
+  - spiffe://domaina.test
+  - spiffe://domainb.test
+  hint: external
+  jwt_svid_ttl: 300
+  parent_id: spiffe://example.org/parent
+  selectors:
+  - type: k8s
+    value: ns:default
+  - type: unix
+    value: uid:1000
+  spiffe_id: spiffe://example.org/workload
+  x509_svid_ttl: 3600
+`, test.stdout.String())
+	})
+
+	t.Run("Export to file", func(t *testing.T) {
+		test := setupTest(t, newExportCommand)
+		test.server.expListEntriesReq = &entryv1.ListEntriesRequest{
+			PageSize: listEntriesRequestPageSize,
+			Filter: &entryv1.ListEntriesRequest_Filter{
+				ByDownstream: wrapperspb.Bool(false),
+			},
+		}
+		test.server.listEntriesResp = &entryv1.ListEntriesResponse{Entries: entries}
+		path := filepath.Join(t.TempDir(), "entries.json")
+
+		rc := test.client.Run(test.args("-file", path))
+		require.Equal(t, 0, rc, test.stderr.String())
+		require.Empty(t, test.stdout.String())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Equal(t, expectedExport, string(data))
+	})
+}
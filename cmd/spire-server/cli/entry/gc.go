@@ -0,0 +1,61 @@
+package entry
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
+)
+
+// NewGCCommand creates a new "gc" subcommand for "entry" command.
+func NewGCCommand() cli.Command {
+	return newGCCommand(commoncli.DefaultEnv)
+}
+
+func newGCCommand(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &gcCommand{})
+}
+
+// gcCommand deletes the selectors and DNS names left behind by registration
+// entries that no longer exist.
+type gcCommand struct {
+	dryRun bool
+}
+
+func (*gcCommand) Name() string {
+	return "entry gc"
+}
+
+func (*gcCommand) Synopsis() string {
+	return "Deletes the selectors and DNS names of registration entries that no longer exist"
+}
+
+func (c *gcCommand) AppendFlags(f *flag.FlagSet) {
+	f.BoolVar(&c.dryRun, "dryRun", false, "Only report the orphaned selectors and DNS names, without deleting them")
+}
+
+// Run deletes the orphaned selectors and DNS names through the server
+func (c *gcCommand) Run(ctx context.Context, env *commoncli.Env, serverClient util.ServerClient) error {
+	resp, err := serverClient.NewEntryExtensionClient().PruneOrphanedEntryChildren(ctx, &extensionv1.PruneOrphanedEntryChildrenRequest{
+		DryRun: c.dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	verb := "Deleted"
+	if c.dryRun {
+		verb = "Found"
+	}
+	return env.Printf("%s %s\n", verb, describeOrphanedEntryChildren(int(resp.Selectors), int(resp.DnsNames)))
+}
+
+func describeOrphanedEntryChildren(selectors, dnsNames int) string {
+	return fmt.Sprintf("%d %s and %d %s",
+		selectors, util.Pluralizer("orphaned selector", "", "s", selectors),
+		dnsNames, util.Pluralizer("orphaned DNS name", "", "s", dnsNames))
+}
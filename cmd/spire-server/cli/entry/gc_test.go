@@ -0,0 +1,68 @@
+package entry
+
+import (
+	"testing"
+
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGCHelp(t *testing.T) {
+	test := setupTest(t, newGCCommand)
+	test.client.Help()
+
+	require.Equal(t, gcUsage, test.stderr.String())
+}
+
+func TestGCSynopsis(t *testing.T) {
+	test := setupTest(t, newGCCommand)
+	require.Equal(t, "Deletes the selectors and DNS names of registration entries that no longer exist", test.client.Synopsis())
+}
+
+func TestGC(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		args           []string
+		resp           *extensionv1.PruneOrphanedEntryChildrenResponse
+		serverErr      error
+		expectReq      *extensionv1.PruneOrphanedEntryChildrenRequest
+		expectStdout   string
+		expectStderr   string
+		expectExitCode int
+	}{
+		{
+			name:         "delete",
+			resp:         &extensionv1.PruneOrphanedEntryChildrenResponse{Selectors: 2, DnsNames: 1},
+			expectReq:    &extensionv1.PruneOrphanedEntryChildrenRequest{},
+			expectStdout: "Deleted 2 orphaned selectors and 1 orphaned DNS name\n",
+		},
+		{
+			name:         "dry run",
+			args:         []string{"-dryRun"},
+			resp:         &extensionv1.PruneOrphanedEntryChildrenResponse{},
+			expectReq:    &extensionv1.PruneOrphanedEntryChildrenRequest{DryRun: true},
+			expectStdout: "Found 0 orphaned selectors and 0 orphaned DNS names\n",
+		},
+		{
+			name:           "server error",
+			serverErr:      status.Error(codes.Internal, "internal server error"),
+			expectReq:      &extensionv1.PruneOrphanedEntryChildrenRequest{},
+			expectStderr:   "Error: rpc error: code = Internal desc = internal server error\n",
+			expectExitCode: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newGCCommand)
+			test.extensionServer.pruneOrphanedEntryChildrenResp = tt.resp
+			test.extensionServer.err = tt.serverErr
+
+			require.Equal(t, tt.expectExitCode, test.client.Run(test.args(tt.args...)))
+			require.Equal(t, tt.expectStdout, test.stdout.String())
+			require.Equal(t, tt.expectStderr, test.stderr.String())
+			spiretest.AssertProtoEqual(t, tt.expectReq, test.extensionServer.gotPruneOrphanedEntryChildrenReq)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package entry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/cliprinter"
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/yaml"
+)
+
+// importBatchSize is the number of entries created with each request, which
+// keeps large imports under the gRPC message size limit.
+const importBatchSize = 500
+
+// NewImportCommand creates a new "import" subcommand for "entry" command.
+func NewImportCommand() cli.Command {
+	return newImportCommand(commoncli.DefaultEnv)
+}
+
+func newImportCommand(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &importCommand{env: env})
+}
+
+type importCommand struct {
+	// Path to the file written by "entry export". If set to "-", the
+	// entries are read from stdin.
+	path string
+
+	// Whether to create the entries with the IDs found in the file instead
+	// of letting the server generate new ones
+	preserveEntryIDs bool
+
+	printer cliprinter.Printer
+
+	env *commoncli.Env
+}
+
+func (*importCommand) Name() string {
+	return "entry import"
+}
+
+func (*importCommand) Synopsis() string {
+	return "Imports registration entries from a JSON or YAML file"
+}
+
+func (c *importCommand) AppendFlags(f *flag.FlagSet) {
+	f.StringVar(&c.path, "file", "", "Path to a file written by \"entry export\". The document can be JSON or YAML. If set to '-', read the document from stdin.")
+	f.BoolVar(&c.preserveEntryIDs, "preserveEntryIDs", false, "If set, entries are created with the entry IDs found in the file instead of new ones generated by the server")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintCreate)
+}
+
+// Run executes all logic associated with a single invocation of the
+// `spire-server entry import` CLI command
+func (c *importCommand) Run(ctx context.Context, env *commoncli.Env, serverClient util.ServerClient) error {
+	if c.path == "" {
+		return errors.New("a file is required")
+	}
+
+	entries, err := parseEntryExport(env.Stdin, c.path)
+	if err != nil {
+		return err
+	}
+
+	if !c.preserveEntryIDs {
+		for _, e := range entries {
+			e.Id = ""
+		}
+	}
+
+	if err := checkFederatedBundles(ctx, serverClient.NewBundleClient(), entries); err != nil {
+		return err
+	}
+
+	resp := &entryv1.BatchCreateEntryResponse{}
+	for start := 0; start < len(entries); start += importBatchSize {
+		end := min(start+importBatchSize, len(entries))
+		batchResp, err := createEntries(ctx, serverClient.NewEntryClient(), entries[start:end], nil)
+		if err != nil {
+			return err
+		}
+		resp.Results = append(resp.Results, batchResp.Results...)
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+// parseEntryExport parses a document written by "entry export", in either
+// of its formats. If path is "-" the document is read from STDIN.
+func parseEntryExport(in io.Reader, path string) ([]*types.Entry, error) {
+	r := in
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	dat, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON is valid YAML, so both formats are read by converting to JSON
+	dat, err = yaml.YAMLToJSON(dat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse entries file: %w", err)
+	}
+
+	entries := &common.RegistrationEntries{}
+	if err := json.Unmarshal(dat, entries); err != nil {
+		return nil, fmt.Errorf("failed to parse entries file: %w", err)
+	}
+	return api.RegistrationEntriesToProto(entries.Entries)
+}
+
+// checkFederatedBundles makes sure that the server has a bundle for every
+// trust domain the entries federate with. Otherwise each of those entries
+// would fail to be created, so nothing is imported.
+func checkFederatedBundles(ctx context.Context, client bundlev1.BundleClient, entries []*types.Entry) error {
+	trustDomains := make(map[string]struct{})
+	for _, e := range entries {
+		for _, td := range e.FederatesWith {
+			trustDomains[td] = struct{}{}
+		}
+	}
+
+	var missing []string
+	for td := range trustDomains {
+		_, err := client.GetFederatedBundle(ctx, &bundlev1.GetFederatedBundleRequest{TrustDomain: td})
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.NotFound:
+			missing = append(missing, fmt.Sprintf("%q", td))
+		default:
+			return fmt.Errorf("error fetching federated bundle for %q: %w", td, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("entries federate with trust domains that have no bundle on this server: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
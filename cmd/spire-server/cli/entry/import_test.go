@@ -0,0 +1,295 @@
+package entry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestImportHelp(t *testing.T) {
+	test := setupTest(t, newImportCommand)
+	test.client.Help()
+
+	require.Equal(t, importUsage, test.stderr.String())
+}
+
+func TestImportSynopsis(t *testing.T) {
+	test := setupTest(t, newImportCommand)
+	require.Equal(t, "Imports registration entries from a JSON or YAML file", test.client.Synopsis())
+}
+
+func TestImport(t *testing.T) {
+	data := `{
+  "entries": [
+    {
+      "selectors": [{"type": "unix", "value": "uid:1000"}],
+      "parent_id": "spiffe://example.org/parent",
+      "spiffe_id": "spiffe://example.org/workload",
+      "federates_with": ["spiffe://domain.test"],
+      "entry_id": "entry-1",
+      "dns_names": ["workload.example.org"],
+      "x509_svid_ttl": 3600,
+      "jwt_svid_ttl": 300,
+      "hint": "external"
+    },
+    {
+      "selectors": [{"type": "unix", "value": "uid:1001"}],
+      "parent_id": "spiffe://example.org/parent",
+      "spiffe_id": "spiffe://example.org/other",
+      "entry_id": "entry-2"
+    }
+  ]
+}`
+	dir := t.TempDir()
+	path := filepath.Join(dir, "entries.json")
+	require.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+
+	entries := func(withIDs bool) []*types.Entry {
+		entries := []*types.Entry{
+			{
+				Id:            "entry-1",
+				ParentId:      &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+				SpiffeId:      &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+				Selectors:     []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+				FederatesWith: []string{"domain.test"},
+				DnsNames:      []string{"workload.example.org"},
+				X509SvidTtl:   3600,
+				JwtSvidTtl:    300,
+				Hint:          "external",
+			},
+			{
+				Id:        "entry-2",
+				ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+				SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/other"},
+				Selectors: []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+				DnsNames:  []string{},
+			},
+		}
+		if !withIDs {
+			for _, e := range entries {
+				e.Id = ""
+			}
+		}
+		return entries
+	}
+	okResult := func(e *types.Entry) *entryv1.BatchCreateEntryResponse_Result {
+		return &entryv1.BatchCreateEntryResponse_Result{
+			Entry:  e,
+			Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+		}
+	}
+
+	for _, tt := range []struct {
+		name                  string
+		args                  []string
+		federatedTrustDomains []string
+
+		expBatchCreateEntryReq *entryv1.BatchCreateEntryRequest
+		fakeBatchCreateResp    *entryv1.BatchCreateEntryResponse
+
+		expOut string
+		expErr string
+	}{
+		{
+			name:                   "Entry IDs are generated by the server",
+			args:                   []string{"-file", path},
+			federatedTrustDomains:  []string{"domain.test"},
+			expBatchCreateEntryReq: &entryv1.BatchCreateEntryRequest{Entries: entries(false)},
+			fakeBatchCreateResp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{
+					okResult(&types.Entry{Id: "new-1"}),
+					okResult(&types.Entry{Id: "new-2"}),
+				},
+			},
+			expOut: "Entry ID         : new-1\n",
+		},
+		{
+			name:                   "Entry IDs are preserved",
+			args:                   []string{"-file", path, "-preserveEntryIDs"},
+			federatedTrustDomains:  []string{"domain.test"},
+			expBatchCreateEntryReq: &entryv1.BatchCreateEntryRequest{Entries: entries(true)},
+			fakeBatchCreateResp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{
+					okResult(entries(true)[0]),
+					okResult(entries(true)[1]),
+				},
+			},
+			expOut: "Entry ID         : entry-1\n",
+		},
+		{
+			name:                   "Conflicting entries are reported",
+			args:                   []string{"-file", path, "-preserveEntryIDs"},
+			federatedTrustDomains:  []string{"domain.test"},
+			expBatchCreateEntryReq: &entryv1.BatchCreateEntryRequest{Entries: entries(true)},
+			fakeBatchCreateResp: &entryv1.BatchCreateEntryResponse{
+				Results: []*entryv1.BatchCreateEntryResponse_Result{
+					okResult(entries(true)[0]),
+					{
+						Status: &types.Status{Code: int32(codes.AlreadyExists), Message: "similar entry already exists"},
+					},
+				},
+			},
+			expOut: "Entry ID         : entry-1\n",
+			expErr: `Failed to create the following entry (code: AlreadyExists, msg: "similar entry already exists"):
+Entry ID         : entry-2
+SPIFFE ID        : spiffe://example.org/other
+Parent ID        : spiffe://example.org/parent
+Revision         : 0
+X509-SVID TTL    : default
+JWT-SVID TTL     : default
+Selector         : unix:uid:1001
+
+Error: failed to create one or more entries
+`,
+		},
+		{
+			name:   "Federated bundle missing on the target",
+			args:   []string{"-file", path},
+			expErr: "Error: entries federate with trust domains that have no bundle on this server: \"domain.test\"\n",
+		},
+		{
+			name:   "File is required",
+			expErr: "Error: a file is required\n",
+		},
+		{
+			name:   "File does not exist",
+			args:   []string{"-file", filepath.Join(dir, "missing.json")},
+			expErr: "Error: open " + filepath.Join(dir, "missing.json"),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newImportCommand)
+			test.bundleServer.federatedTrustDomains = tt.federatedTrustDomains
+			test.server.expBatchCreateEntryReq = tt.expBatchCreateEntryReq
+			test.server.batchCreateEntryResp = tt.fakeBatchCreateResp
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Contains(t, test.stderr.String(), tt.expErr)
+			} else {
+				require.Equal(t, 0, rc, test.stderr.String())
+				require.Empty(t, test.stderr.String())
+			}
+			if tt.expOut != "" {
+				require.Contains(t, test.stdout.String(), tt.expOut)
+			}
+		})
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	entries := []*types.Entry{
+		{
+			Id:       "entry-1",
+			ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+			SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+			Selectors: []*types.Selector{
+				{Type: "unix", Value: "uid:1000"},
+				{Type: "k8s", Value: "ns:default"},
+			},
+			X509SvidTtl:    3600,
+			JwtSvidTtl:     300,
+			FederatesWith:  []string{"domain.test"},
+			DnsNames:       []string{"workload.example.org", "alt.example.org"},
+			Hint:           "external",
+			ExpiresAt:      1552410266,
+			RevisionNumber: 3,
+			CreatedAt:      1547583197,
+		},
+		{
+			Id:         "entry-2",
+			ParentId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/server"},
+			SpiffeId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+			Selectors:  []*types.Selector{{Type: "join_token", Value: "token"}},
+			Admin:      true,
+			Downstream: true,
+			StoreSvid:  true,
+			CreatedAt:  1547583197,
+		},
+	}
+	listReq := &entryv1.ListEntriesRequest{
+		PageSize: listEntriesRequestPageSize,
+		Filter: &entryv1.ListEntriesRequest_Filter{
+			ByDownstream: wrapperspb.Bool(false),
+		},
+	}
+	path := filepath.Join(t.TempDir(), "entries")
+
+	export := func(entries []*types.Entry, format string) []byte {
+		test := setupTest(t, newExportCommand)
+		test.server.expListEntriesReq = listReq
+		test.server.listEntriesResp = &entryv1.ListEntriesResponse{Entries: entries}
+
+		rc := test.client.Run(test.args("-file", path, "-format", format))
+		require.Equal(t, 0, rc, test.stderr.String())
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return data
+	}
+
+	imported := []*types.Entry{
+		{
+			Id:         "entry-2",
+			ParentId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/server"},
+			SpiffeId:   &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+			Selectors:  []*types.Selector{{Type: "join_token", Value: "token"}},
+			Admin:      true,
+			Downstream: true,
+			StoreSvid:  true,
+			DnsNames:   []string{},
+		},
+		{
+			Id:       "entry-1",
+			ParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+			SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+			Selectors: []*types.Selector{
+				{Type: "k8s", Value: "ns:default"},
+				{Type: "unix", Value: "uid:1000"},
+			},
+			X509SvidTtl:   3600,
+			JwtSvidTtl:    300,
+			FederatesWith: []string{"domain.test"},
+			DnsNames:      []string{"workload.example.org", "alt.example.org"},
+			Hint:          "external",
+			ExpiresAt:     1552410266,
+		},
+	}
+
+	// The target server stores the imported entries with its own creation
+	// time and revision, which are not part of the export.
+	var created []*types.Entry
+	var results []*entryv1.BatchCreateEntryResponse_Result
+	for _, e := range imported {
+		e = proto.Clone(e).(*types.Entry)
+		e.CreatedAt = 1700000000
+		created = append(created, e)
+		results = append(results, &entryv1.BatchCreateEntryResponse_Result{
+			Entry:  e,
+			Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+		})
+	}
+
+	for _, format := range []string{"json", "yaml"} {
+		t.Run(format, func(t *testing.T) {
+			exported := export(entries, format)
+
+			test := setupTest(t, newImportCommand)
+			test.bundleServer.federatedTrustDomains = []string{"domain.test"}
+			test.server.expBatchCreateEntryReq = &entryv1.BatchCreateEntryRequest{Entries: imported}
+			test.server.batchCreateEntryResp = &entryv1.BatchCreateEntryResponse{Results: results}
+			rc := test.client.Run(test.args("-file", path, "-preserveEntryIDs"))
+			require.Equal(t, 0, rc, test.stderr.String())
+
+			require.Equal(t, string(exported), string(export(created, format)))
+		})
+	}
+}
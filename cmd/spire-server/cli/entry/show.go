@@ -2,17 +2,24 @@ package entry
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/mitchellh/cli"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/cmd/spire-server/cli/run"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	commonutil "github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/credtemplate"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
@@ -42,7 +49,7 @@ type showCommand struct {
 	spiffeID string
 
 	// Entry hint
-	hint string
+	hint stringFilterFlag
 
 	// List of SPIFFE IDs of trust domains the registration entry is federated with
 	federatesWith StringsFlag
@@ -50,17 +57,48 @@ type showCommand struct {
 	// whether the entry is for a downstream SPIRE server
 	downstream bool
 
+	// whether the entry grants admin access to the Server APIs
+	admin boolFilterFlag
+
+	// whether to show only entries whose SVIDs are stored through an SVIDStore plugin
+	storeSVID bool
+
 	// Match used when filtering by federates with
 	matchFederatesWithOn string
 
 	// Match used when filtering by selectors
 	matchSelectorsOn string
 
+	// Whether to print the plan of the datastore query listing the entries
+	// instead of the entries
+	explain bool
+
+	// Whether to show the entries the entry is delegated from along with it
+	parents bool
+
+	// Path to the SPIRE server config file, used to resolve the default SVID
+	// TTLs
+	configPath string
+
+	// Whether to expand environment variables in the SPIRE server config file
+	expandEnv bool
+
+	// Default SVID TTLs of the server, used to label inherited entry TTLs
+	ttlDefaults svidTTLDefaults
+
 	printer cliprinter.Printer
+	output  *cliprinter.FormatterFlag
 
 	env *commoncli.Env
 }
 
+// svidTTLDefaults holds the TTLs the server gives to SVIDs of entries that
+// don't set their own.
+type svidTTLDefaults struct {
+	x509 time.Duration
+	jwt  time.Duration
+}
+
 func (c *showCommand) Name() string {
 	return "entry show"
 }
@@ -74,12 +112,18 @@ func (c *showCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.parentID, "parentID", "", "The Parent ID of the records to show")
 	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID of the records to show")
 	f.BoolVar(&c.downstream, "downstream", false, "A boolean value that, when set, indicates that the entry describes a downstream SPIRE server")
+	f.Var(&c.admin, "admin", "If set, only admin entries are shown. Use -admin=false to show only the other entries")
+	f.BoolVar(&c.storeSVID, "storeSVID", false, "If set, only entries whose issued SVIDs are stored through an SVIDStore plugin are shown")
 	f.Var(&c.selectors, "selector", "A colon-delimited type:value selector. Can be used more than once")
 	f.Var(&c.federatesWith, "federatesWith", "SPIFFE ID of a trust domain an entry is federate with. Can be used more than once")
 	f.StringVar(&c.matchFederatesWithOn, "matchFederatesWithOn", "superset", "The match mode used when filtering by federates with. Options: exact, any, superset and subset")
 	f.StringVar(&c.matchSelectorsOn, "matchSelectorsOn", "superset", "The match mode used when filtering by selectors. Options: exact, any, superset and subset")
-	f.StringVar(&c.hint, "hint", "", "The Hint of the records to show (optional)")
-	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintShow)
+	f.Var(&c.hint, "hint", "The Hint of the records to show (optional). Use -hint \"\" to show only entries without a hint")
+	f.BoolVar(&c.explain, "explain", false, "If set, the plan of the datastore query listing the matching entries is shown instead of the entries. Requires the explain_queries option of the SQL datastore")
+	f.BoolVar(&c.parents, "parents", false, "If set, the entry given by -entryID is shown followed by the chain of entries it is delegated from, each one having the parent ID of the previous one as SPIFFE ID")
+	f.StringVar(&c.configPath, "config", "", "Path to the SPIRE server config file, used to resolve the default SVID TTLs (optional). If not set, the built-in defaults are assumed")
+	f.BoolVar(&c.expandEnv, "expandEnv", false, "Expand environment variables in the SPIRE server config file")
+	c.output = cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, c.prettyPrintShow)
 }
 
 // Run executes all logic associated with a single invocation of the
@@ -89,15 +133,77 @@ func (c *showCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient ut
 		return err
 	}
 
-	resp, err := c.fetchEntries(ctx, serverClient.NewEntryClient())
+	if c.explain {
+		return c.explainEntries(ctx, serverClient.NewEntryExtensionClient())
+	}
+
+	ttlDefaults, err := c.loadSVIDTTLDefaults()
 	if err != nil {
 		return err
 	}
+	c.ttlDefaults = ttlDefaults
 
-	commonutil.SortTypesEntries(resp.Entries)
+	var resp *entryv1.ListEntriesResponse
+	if c.parents {
+		// The chain is printed in order, from the entry to its furthest parent
+		resp, err = c.fetchEntryWithParents(ctx, serverClient.NewEntryExtensionClient())
+		if err != nil {
+			return err
+		}
+	} else {
+		resp, err = c.fetchEntries(ctx, serverClient.NewEntryClient())
+		if err != nil {
+			return err
+		}
+		commonutil.SortTypesEntries(resp.Entries)
+	}
+
+	if c.output.String() == "json" {
+		out, err := entriesWithTTLSources(resp)
+		if err != nil {
+			return err
+		}
+		return c.printer.PrintProto(out)
+	}
 	return c.printer.PrintProto(resp)
 }
 
+// loadSVIDTTLDefaults resolves the default SVID TTLs from the server config
+// file, falling back to the built-in defaults for the values it doesn't set.
+func (c *showCommand) loadSVIDTTLDefaults() (svidTTLDefaults, error) {
+	defaults := svidTTLDefaults{
+		x509: credtemplate.DefaultX509SVIDTTL,
+		jwt:  credtemplate.DefaultJWTSVIDTTL,
+	}
+	if c.configPath == "" {
+		return defaults, nil
+	}
+
+	config, err := run.ParseFile(c.configPath, c.expandEnv)
+	if err != nil {
+		return svidTTLDefaults{}, err
+	}
+	if config.Server == nil {
+		return defaults, nil
+	}
+
+	if config.Server.DefaultX509SVIDTTL != "" {
+		ttl, err := time.ParseDuration(config.Server.DefaultX509SVIDTTL)
+		if err != nil {
+			return svidTTLDefaults{}, fmt.Errorf("could not parse default X509 SVID ttl %q: %w", config.Server.DefaultX509SVIDTTL, err)
+		}
+		defaults.x509 = ttl
+	}
+	if config.Server.DefaultJWTSVIDTTL != "" {
+		ttl, err := time.ParseDuration(config.Server.DefaultJWTSVIDTTL)
+		if err != nil {
+			return svidTTLDefaults{}, fmt.Errorf("could not parse default JWT SVID ttl %q: %w", config.Server.DefaultJWTSVIDTTL, err)
+		}
+		defaults.jwt = ttl
+	}
+	return defaults, nil
+}
+
 // validate ensures that the values in showCommand are valid
 func (c *showCommand) validate() error {
 	// If entryID is given, it should be the only constraint
@@ -107,9 +213,49 @@ func (c *showCommand) validate() error {
 		}
 	}
 
+	if c.explain {
+		if c.entryID != "" {
+			return errors.New("the -explain flag can't be combined with -entryID")
+		}
+	}
+
+	if c.parents {
+		if c.entryID == "" {
+			return errors.New("the -parents flag requires -entryID")
+		}
+	}
+
 	return nil
 }
 
+// explainEntries prints the plan the datastore would use to list the entries
+// matching the filters.
+func (c *showCommand) explainEntries(ctx context.Context, client extensionv1.EntryExtensionClient) error {
+	filter, err := c.listEntriesFilter()
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.ExplainListEntries(ctx, &extensionv1.ExplainListEntriesRequest{
+		Filter:   filter,
+		PageSize: listEntriesRequestPageSize,
+	})
+	if err != nil {
+		return fmt.Errorf("error explaining entry listing: %w", err)
+	}
+	return c.env.Println(resp.Plan)
+}
+
+// fetchEntryWithParents fetches the entry along with the chain of entries it
+// is delegated from.
+func (c *showCommand) fetchEntryWithParents(ctx context.Context, client extensionv1.EntryExtensionClient) (*entryv1.ListEntriesResponse, error) {
+	resp, err := client.GetEntryWithParents(ctx, &extensionv1.GetEntryWithParentsRequest{Id: c.entryID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching entry ID %s: %w", c.entryID, err)
+	}
+	return &entryv1.ListEntriesResponse{Entries: resp.Entries}, nil
+}
+
 func (c *showCommand) fetchEntries(ctx context.Context, client entryv1.EntryClient) (*entryv1.ListEntriesResponse, error) {
 	listResp := &entryv1.ListEntriesResponse{}
 	// If an Entry ID was specified, look it up directly
@@ -122,6 +268,51 @@ func (c *showCommand) fetchEntries(ctx context.Context, client entryv1.EntryClie
 		return listResp, nil
 	}
 
+	filter, err := c.listEntriesFilter()
+	if err != nil {
+		return nil, err
+	}
+
+	pageToken := ""
+
+	for {
+		resp, err := client.ListEntries(ctx, &entryv1.ListEntriesRequest{
+			PageSize:  listEntriesRequestPageSize,
+			PageToken: pageToken,
+			Filter:    filter,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching entries: %w", err)
+		}
+		listResp.Entries = append(listResp.Entries, resp.Entries...)
+		if pageToken = resp.NextPageToken; pageToken == "" {
+			break
+		}
+	}
+
+	// The entry API has no filter by StoreSvid or Admin, so they are
+	// applied here.
+	if c.storeSVID || c.admin.value != nil {
+		entries := listResp.Entries[:0]
+		for _, entry := range listResp.Entries {
+			if c.storeSVID && !entry.StoreSvid {
+				continue
+			}
+			if c.admin.value != nil && entry.Admin != *c.admin.value {
+				continue
+			}
+			entries = append(entries, entry)
+		}
+		listResp.Entries = entries
+	}
+
+	return listResp, nil
+}
+
+// listEntriesFilter builds the entry API filter matching the flags. The
+// -admin and -storeSVID filters are applied to the listed entries, so they
+// are not part of it.
+func (c *showCommand) listEntriesFilter() (*entryv1.ListEntriesRequest_Filter, error) {
 	filter := &entryv1.ListEntriesRequest_Filter{}
 	if c.parentID != "" {
 		id, err := idStringToProto(c.parentID)
@@ -171,30 +362,11 @@ func (c *showCommand) fetchEntries(ctx context.Context, client entryv1.EntryClie
 		}
 	}
 
-	if c.hint != "" {
-		filter.ByHint = wrapperspb.String(c.hint)
-	}
+	filter.ByHint = c.hint.StringValue()
 
 	filter.ByDownstream = wrapperspb.Bool(c.downstream)
 
-	pageToken := ""
-
-	for {
-		resp, err := client.ListEntries(ctx, &entryv1.ListEntriesRequest{
-			PageSize:  listEntriesRequestPageSize,
-			PageToken: pageToken,
-			Filter:    filter,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("error fetching entries: %w", err)
-		}
-		listResp.Entries = append(listResp.Entries, resp.Entries...)
-		if pageToken = resp.NextPageToken; pageToken == "" {
-			break
-		}
-	}
-
-	return listResp, nil
+	return filter, nil
 }
 
 // fetchByEntryID uses the configured EntryID to fetch the appropriate registration entry
@@ -207,14 +379,47 @@ func (c *showCommand) fetchByEntryID(ctx context.Context, id string, client entr
 	return entry, nil
 }
 
-func printEntries(entries []*types.Entry, env *commoncli.Env) {
+func printEntries(entries []*types.Entry, ttlDefaults *svidTTLDefaults, env *commoncli.Env) {
 	msg := fmt.Sprintf("Found %v ", len(entries))
 	msg = util.Pluralizer(msg, "entry", "entries", len(entries))
 
 	env.Println(msg)
 	for _, e := range entries {
-		printEntry(e, env.Printf)
+		printEntry(e, ttlDefaults, env.Printf)
+	}
+}
+
+// entriesWithTTLSources converts the response into its JSON representation,
+// adding to every entry whether its TTLs are set by the entry itself or
+// inherited from the server defaults.
+func entriesWithTTLSources(resp *entryv1.ListEntriesResponse) (*structpb.Struct, error) {
+	jb, err := protojson.MarshalOptions{
+		UseProtoNames:   true,
+		EmitUnpopulated: true,
+	}.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var out map[string]any
+	if err := json.Unmarshal(jb, &out); err != nil {
+		return nil, err
+	}
+
+	jsonEntries, ok := out["entries"].([]any)
+	if !ok || len(jsonEntries) != len(resp.Entries) {
+		return nil, errors.New("unexpected JSON representation of the entries")
 	}
+	for i, e := range resp.Entries {
+		jsonEntry, ok := jsonEntries[i].(map[string]any)
+		if !ok {
+			return nil, errors.New("unexpected JSON representation of the entries")
+		}
+		jsonEntry["x509SvidTtlSource"] = ttlSource(e.X509SvidTtl)
+		jsonEntry["jwtSvidTtlSource"] = ttlSource(e.JwtSvidTtl)
+	}
+
+	return structpb.NewStruct(out)
 }
 
 func parseToSelectorMatch(match string) (types.SelectorMatch_MatchBehavior, error) {
@@ -247,11 +452,11 @@ func parseToFederatesWithMatch(match string) (types.FederatesWithMatch_MatchBeha
 	}
 }
 
-func prettyPrintShow(env *commoncli.Env, results ...any) error {
+func (c *showCommand) prettyPrintShow(env *commoncli.Env, results ...any) error {
 	listResp, ok := results[0].(*entryv1.ListEntriesResponse)
 	if !ok {
 		return cliprinter.ErrInternalCustomPrettyFunc
 	}
-	printEntries(listResp.Entries, env)
+	printEntries(listResp.Entries, &c.ttlDefaults, env)
 	return nil
 }
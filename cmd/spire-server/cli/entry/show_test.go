@@ -2,11 +2,16 @@ package entry
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
+	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -43,6 +48,18 @@ func TestShow(t *testing.T) {
 		Entries: getEntries(3)[2:],
 	}
 
+	storeSVIDEntry := getEntries(1)[0]
+	storeSVIDEntry.StoreSvid = true
+	fakeRespStoreSVID := &entryv1.ListEntriesResponse{
+		Entries: []*types.Entry{getEntries(2)[1], storeSVIDEntry},
+	}
+
+	adminEntry := getEntries(1)[0]
+	adminEntry.Admin = true
+	fakeRespAdmin := &entryv1.ListEntriesResponse{
+		Entries: []*types.Entry{getEntries(2)[1], adminEntry},
+	}
+
 	for _, tt := range []struct {
 		name string
 		args []string
@@ -144,6 +161,34 @@ func TestShow(t *testing.T) {
 			args:   []string{"-spiffeID", "invalid-id"},
 			expErr: "Error: error parsing SPIFFE ID \"invalid-id\": scheme is missing or invalid\n",
 		},
+		{
+			name: "List by hint",
+			args: []string{"-hint", "internal"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByHint:       wrapperspb.String("internal"),
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			fakeListResp: &entryv1.ListEntriesResponse{Entries: getEntries(1)},
+			expOutPretty: fmt.Sprintf("Found 1 entry\n%s", getPrettyPrintedEntry(0)),
+			expOutJSON:   fmt.Sprintf(`{"entries": [%s],"next_page_token": ""}`, getJSONPrintedEntry(0)),
+		},
+		{
+			name: "List by empty hint",
+			args: []string{"-hint", ""},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByHint:       wrapperspb.String(""),
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			fakeListResp: &entryv1.ListEntriesResponse{Entries: getEntries(4)[2:]},
+			expOutPretty: fmt.Sprintf("Found 2 entries\n%s%s", getPrettyPrintedEntry(2), getPrettyPrintedEntry(3)),
+			expOutJSON:   fmt.Sprintf(`{"entries": [%s,%s],"next_page_token": ""}`, getJSONPrintedEntry(2), getJSONPrintedEntry(3)),
+		},
 		{
 			name: "List by selectors: default matcher",
 			args: []string{"-selector", "foo:bar", "-selector", "bar:baz"},
@@ -372,6 +417,99 @@ func TestShow(t *testing.T) {
 			),
 			expOutJSON: fmt.Sprintf(`{"entries": [%s],"next_page_token": ""}`, getJSONPrintedEntry(2)),
 		},
+		{
+			name: "List by StoreSVID",
+			args: []string{"-storeSVID"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			fakeListResp: fakeRespStoreSVID,
+			expOutPretty: fmt.Sprintf("Found 1 entry\n%s",
+				strings.Replace(getPrettyPrintedEntry(0), "Hint ", "StoreSvid        : true\nHint ", 1),
+			),
+			expOutJSON: fmt.Sprintf(`{"entries": [%s],"next_page_token": ""}`,
+				strings.Replace(getJSONPrintedEntry(0), `"store_svid": false`, `"store_svid": true`, 1),
+			),
+		},
+		{
+			name: "List by StoreSVID without matches",
+			args: []string{"-storeSVID"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			fakeListResp: fakeRespAll,
+			expOutPretty: "Found 0 entries\n",
+			expOutJSON:   `{"entries": [],"next_page_token": ""}`,
+		},
+		{
+			name: "List by Downstream",
+			args: []string{"-downstream"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByDownstream: wrapperspb.Bool(true),
+				},
+			},
+			fakeListResp: fakeRespFather,
+			expOutPretty: fmt.Sprintf("Found 2 entries\n%s%s",
+				getPrettyPrintedEntry(1),
+				getPrettyPrintedEntry(0),
+			),
+			expOutJSON: fmt.Sprintf(`{"entries": [%s,%s],"next_page_token": ""}`, getJSONPrintedEntry(1), getJSONPrintedEntry(0)),
+		},
+		{
+			name: "List by Admin",
+			args: []string{"-admin"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			fakeListResp: fakeRespAdmin,
+			expOutPretty: fmt.Sprintf("Found 1 entry\n%s",
+				strings.Replace(getPrettyPrintedEntry(0), "Hint ", "Admin            : true\nHint ", 1),
+			),
+			expOutJSON: fmt.Sprintf(`{"entries": [%s],"next_page_token": ""}`,
+				strings.Replace(getJSONPrintedEntry(0), `"admin": false`, `"admin": true`, 1),
+			),
+		},
+		{
+			name: "List by Admin false",
+			args: []string{"-admin=false"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			fakeListResp: fakeRespAdmin,
+			expOutPretty: fmt.Sprintf("Found 1 entry\n%s", getPrettyPrintedEntry(1)),
+			expOutJSON:   fmt.Sprintf(`{"entries": [%s],"next_page_token": ""}`, getJSONPrintedEntry(1)),
+		},
+		{
+			name: "List by Admin and Downstream",
+			args: []string{"-admin", "-downstream"},
+			expListReq: &entryv1.ListEntriesRequest{
+				PageSize: listEntriesRequestPageSize,
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByDownstream: wrapperspb.Bool(true),
+				},
+			},
+			fakeListResp: fakeRespAdmin,
+			expOutPretty: fmt.Sprintf("Found 1 entry\n%s",
+				strings.Replace(getPrettyPrintedEntry(0), "Hint ", "Admin            : true\nHint ", 1),
+			),
+			expOutJSON: fmt.Sprintf(`{"entries": [%s],"next_page_token": ""}`,
+				strings.Replace(getJSONPrintedEntry(0), `"admin": false`, `"admin": true`, 1),
+			),
+		},
 		{
 			name:   "List by Federates With: Invalid matcher",
 			args:   []string{"-federatesWith", "spiffe://domain.test", "-matchFederatesWithOn", "NO-MATCHER"},
@@ -402,6 +540,224 @@ func TestShow(t *testing.T) {
 	}
 }
 
+func TestShowTTLSource(t *testing.T) {
+	entry := &types.Entry{
+		ParentId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/father"},
+		SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/son"},
+		Selectors:   []*types.Selector{{Type: "foo", Value: "bar"}},
+		Id:          "00000000-0000-0000-0000-000000000000",
+		X509SvidTtl: 60,
+	}
+
+	configPath := filepath.Join(t.TempDir(), "server.conf")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+server {
+	default_x509_svid_ttl = "2h"
+	default_jwt_svid_ttl = "10m"
+}
+`), 0600))
+
+	badConfigPath := filepath.Join(t.TempDir(), "server.conf")
+	require.NoError(t, os.WriteFile(badConfigPath, []byte(`
+server {
+	default_jwt_svid_ttl = "forever"
+}
+`), 0600))
+
+	for _, tt := range []struct {
+		name         string
+		args         []string
+		expOutPretty string
+		expOutJSON   []string
+		expErr       string
+	}{
+		{
+			name: "built-in defaults",
+			expOutPretty: `X509-SVID TTL    : 60 (entry)
+JWT-SVID TTL     : 300 (default)
+`,
+			expOutJSON: []string{`"x509SvidTtlSource":"entry"`, `"jwtSvidTtlSource":"default"`},
+		},
+		{
+			name: "defaults from server config",
+			args: []string{"-config", configPath},
+			expOutPretty: `X509-SVID TTL    : 60 (entry)
+JWT-SVID TTL     : 600 (default)
+`,
+			expOutJSON: []string{`"x509SvidTtlSource":"entry"`, `"jwtSvidTtlSource":"default"`},
+		},
+		{
+			name:   "invalid server config",
+			args:   []string{"-config", badConfigPath},
+			expErr: "Error: could not parse default JWT SVID ttl \"forever\": time: invalid duration \"forever\"\n",
+		},
+	} {
+		for _, format := range availableFormats {
+			t.Run(fmt.Sprintf("%s using %s format", tt.name, format), func(t *testing.T) {
+				test := setupTest(t, newShowCommand)
+				test.server.expGetEntryReq = &entryv1.GetEntryRequest{Id: entry.Id}
+				test.server.getEntryResp = entry
+				args := append([]string{"-entryID", entry.Id, "-output", format}, tt.args...)
+
+				rc := test.client.Run(test.args(args...))
+				if tt.expErr != "" {
+					require.Equal(t, 1, rc)
+					require.Equal(t, tt.expErr, test.stderr.String())
+					return
+				}
+				require.Equal(t, 0, rc, test.stderr.String())
+				switch format {
+				case "pretty":
+					require.Contains(t, test.stdout.String(), tt.expOutPretty)
+				case "json":
+					for _, field := range tt.expOutJSON {
+						require.Contains(t, test.stdout.String(), field)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestShowExplain(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		serverErr error
+		expReq    *extensionv1.ExplainListEntriesRequest
+		expErr    string
+	}{
+		{
+			name: "Selectors",
+			args: []string{"-explain", "-selector", "unix:uid:1000", "-matchSelectorsOn", "subset"},
+			expReq: &extensionv1.ExplainListEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					BySelectors: &types.SelectorMatch{
+						Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+						Match:     types.SelectorMatch_MATCH_SUBSET,
+					},
+					ByDownstream: wrapperspb.Bool(false),
+				},
+				PageSize: listEntriesRequestPageSize,
+			},
+		},
+		{
+			name: "All filters",
+			args: []string{"-explain", "-parentID", "spiffe://example.org/parent", "-spiffeID", "spiffe://example.org/workload", "-federatesWith", "spiffe://domain.test", "-hint", "internal", "-downstream"},
+			expReq: &extensionv1.ExplainListEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+					BySpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+					ByFederatesWith: &types.FederatesWithMatch{
+						TrustDomains: []string{"spiffe://domain.test"},
+						Match:        types.FederatesWithMatch_MATCH_SUPERSET,
+					},
+					ByHint:       wrapperspb.String("internal"),
+					ByDownstream: wrapperspb.Bool(true),
+				},
+				PageSize: listEntriesRequestPageSize,
+			},
+		},
+		{
+			name:      "Explaining queries disabled",
+			args:      []string{"-explain"},
+			serverErr: status.Error(codes.FailedPrecondition, "failed to explain entry listing: datastore-sql: explaining queries is disabled"),
+			expErr:    "Error: error explaining entry listing: rpc error: code = FailedPrecondition desc = failed to explain entry listing: datastore-sql: explaining queries is disabled\n",
+		},
+		{
+			name:   "Combined with entry ID",
+			args:   []string{"-explain", "-entryID", "00000000-0000-0000-0000-000000000000"},
+			expErr: "Error: the -explain flag can't be combined with -entryID\n",
+		},
+		{
+			name:   "Invalid parent ID",
+			args:   []string{"-explain", "-parentID", "example.org/parent"},
+			expErr: "Error: error parsing parent ID \"example.org/parent\": scheme is missing or invalid\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newShowCommand)
+			test.extensionServer.err = tt.serverErr
+			test.extensionServer.explainListEntriesResp = &extensionv1.ExplainListEntriesResponse{
+				Plan: "SEARCH registered_entries USING INDEX idx_registered_entries_parent_id",
+			}
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Equal(t, tt.expErr, test.stderr.String())
+				return
+			}
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Equal(t, "SEARCH registered_entries USING INDEX idx_registered_entries_parent_id\n", test.stdout.String())
+			spiretest.AssertProtoEqual(t, tt.expReq, test.extensionServer.gotExplainListEntriesReq)
+		})
+	}
+}
+
+func TestShowParents(t *testing.T) {
+	child := &types.Entry{
+		Id:        "child",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+		ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/nested"},
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+	}
+	parent := &types.Entry{
+		Id:        "parent",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/nested"},
+		ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node"},
+		Selectors: []*types.Selector{{Type: "k8s_psat", Value: "cluster:a"}},
+	}
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		serverErr error
+		expErr    string
+	}{
+		{
+			name:   "Missing entry ID",
+			args:   []string{"-parents"},
+			expErr: "Error: the -parents flag requires -entryID\n",
+		},
+		{
+			name:   "Combined with explain",
+			args:   []string{"-parents", "-explain", "-entryID", "child"},
+			expErr: "Error: the -explain flag can't be combined with -entryID\n",
+		},
+		{
+			name:      "Entry not found",
+			args:      []string{"-parents", "-entryID", "00000000-0000-0000-0000-000000000000"},
+			serverErr: status.Error(codes.NotFound, "entry not found"),
+			expErr:    "Error: error fetching entry ID 00000000-0000-0000-0000-000000000000: rpc error: code = NotFound desc = entry not found\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newShowCommand)
+			test.extensionServer.err = tt.serverErr
+			require.Equal(t, 1, test.client.Run(test.args(tt.args...)))
+			require.Equal(t, tt.expErr, test.stderr.String())
+		})
+	}
+
+	test := setupTest(t, newShowCommand)
+	test.extensionServer.getEntryWithParentsResp = &extensionv1.GetEntryWithParentsResponse{
+		Entries: []*types.Entry{child, parent},
+	}
+	rc := test.client.Run(test.args("-parents", "-entryID", "child"))
+	require.Equal(t, 0, rc, test.stderr.String())
+	spiretest.AssertProtoEqual(t, &extensionv1.GetEntryWithParentsRequest{Id: "child"}, test.extensionServer.gotGetEntryWithParentsReq)
+	out := test.stdout.String()
+	require.Contains(t, out, "Found 2 entries")
+
+	// The entry comes first, followed by the entry it is delegated from
+	childIdx := strings.Index(out, "Entry ID         : child")
+	parentIdx := strings.Index(out, "Entry ID         : parent")
+	require.NotEqual(t, -1, childIdx)
+	require.NotEqual(t, -1, parentIdx)
+	require.Less(t, childIdx, parentIdx)
+}
+
 // registrationEntries returns `count` registration entry records. At most 4.
 func getEntries(count int) []*types.Entry {
 	selectors := []*types.Selector{
@@ -459,8 +815,8 @@ func getPrettyPrintedEntry(idx int) string {
 SPIFFE ID        : spiffe://example.org/son
 Parent ID        : spiffe://example.org/father
 Revision         : 0
-X509-SVID TTL    : default
-JWT-SVID TTL     : default
+X509-SVID TTL    : 3600 (default)
+JWT-SVID TTL     : 300 (default)
 Selector         : foo:bar
 Hint             : internal
 
@@ -470,8 +826,8 @@ Hint             : internal
 SPIFFE ID        : spiffe://example.org/daughter
 Parent ID        : spiffe://example.org/father
 Revision         : 0
-X509-SVID TTL    : default
-JWT-SVID TTL     : default
+X509-SVID TTL    : 3600 (default)
+JWT-SVID TTL     : 300 (default)
 Selector         : bar:baz
 Selector         : foo:bar
 Hint             : external
@@ -482,8 +838,8 @@ Hint             : external
 SPIFFE ID        : spiffe://example.org/daughter
 Parent ID        : spiffe://example.org/mother
 Revision         : 0
-X509-SVID TTL    : default
-JWT-SVID TTL     : default
+X509-SVID TTL    : 3600 (default)
+JWT-SVID TTL     : 300 (default)
 Selector         : bar:baz
 Selector         : baz:bat
 FederatesWith    : spiffe://domain.test
@@ -494,8 +850,8 @@ FederatesWith    : spiffe://domain.test
 SPIFFE ID        : spiffe://example.org/son
 Parent ID        : spiffe://example.org/mother
 Revision         : 0
-X509-SVID TTL    : default
-JWT-SVID TTL     : default
+X509-SVID TTL    : 3600 (default)
+JWT-SVID TTL     : 300 (default)
 Expiration time  : %s
 Selector         : baz:bat
 
@@ -534,7 +890,9 @@ func getJSONPrintedEntry(idx int) string {
       "dns_names": [],
       "revision_number": "0",
       "store_svid": false,
-      "jwt_svid_ttl": 0
+      "jwt_svid_ttl": 0,
+      "x509SvidTtlSource": "default",
+      "jwtSvidTtlSource": "default"
     }`
 	case 1:
 		return `{
@@ -567,7 +925,9 @@ func getJSONPrintedEntry(idx int) string {
       "dns_names": [],
       "revision_number": "0",
       "store_svid": false,
-      "jwt_svid_ttl": 0
+      "jwt_svid_ttl": 0,
+      "x509SvidTtlSource": "default",
+      "jwtSvidTtlSource": "default"
     }`
 	case 2:
 		return `{
@@ -602,7 +962,9 @@ func getJSONPrintedEntry(idx int) string {
       "dns_names": [],
       "revision_number": "0",
       "store_svid": false,
-      "jwt_svid_ttl": 0
+      "jwt_svid_ttl": 0,
+      "x509SvidTtlSource": "default",
+      "jwtSvidTtlSource": "default"
     }`
 	case 3:
 		return `{
@@ -631,7 +993,9 @@ func getJSONPrintedEntry(idx int) string {
       "dns_names": [],
       "revision_number": "0",
       "store_svid": false,
-      "jwt_svid_ttl": 0
+      "jwt_svid_ttl": 0,
+      "x509SvidTtlSource": "default",
+      "jwtSvidTtlSource": "default"
     }`
 	default:
 		return "index should be lower than 4"
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"strconv"
 
 	"github.com/mitchellh/cli"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
@@ -13,7 +14,9 @@ import (
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/api"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 )
 
 // NewUpdateCommand creates a new "update" subcommand for "entry" command.
@@ -70,6 +73,14 @@ type updateCommand struct {
 	// Entry hint, used to disambiguate entries with the same SPIFFE ID
 	hint string
 
+	// Whether the entry is activated or deactivated instead of updated
+	enable  bool
+	disable bool
+
+	// Whether the entry federates with all the trust domains known to the
+	// server, if set
+	federatesWithAll commoncli.BoolFlag
+
 	printer cliprinter.Printer
 
 	env *commoncli.Env
@@ -87,8 +98,8 @@ func (c *updateCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.entryID, "entryID", "", "The Registration Entry ID of the record to update")
 	f.StringVar(&c.parentID, "parentID", "", "The SPIFFE ID of this record's parent")
 	f.StringVar(&c.spiffeID, "spiffeID", "", "The SPIFFE ID that this record represents")
-	f.IntVar(&c.x509SvidTTL, "x509SVIDTTL", 0, "The lifetime, in seconds, for x509-SVIDs issued based on this registration entry.")
-	f.IntVar(&c.jwtSvidTTL, "jwtSVIDTTL", 0, "The lifetime, in seconds, for JWT-SVIDs issued based on this registration entry.")
+	f.Var((*ttlFlag)(&c.x509SvidTTL), "x509SVIDTTL", "The lifetime, in seconds or as a `duration` (e.g. 30m), for x509-SVIDs issued based on this registration entry.")
+	f.Var((*ttlFlag)(&c.jwtSvidTTL), "jwtSVIDTTL", "The lifetime, in seconds or as a `duration` (e.g. 30m), for JWT-SVIDs issued based on this registration entry.")
 	f.StringVar(&c.path, "data", "", "Path to a file containing registration JSON (optional). If set to '-', read the JSON from stdin.")
 	f.Var(&c.selectors, "selector", "A colon-delimited type:value selector. Can be used more than once")
 	f.Var(&c.federatesWith, "federatesWith", "SPIFFE ID of a trust domain to federate with. Can be used more than once")
@@ -98,10 +109,23 @@ func (c *updateCommand) AppendFlags(f *flag.FlagSet) {
 	f.Int64Var(&c.entryExpiry, "entryExpiry", 0, "An expiry, from epoch in seconds, for the resulting registration entry to be pruned")
 	f.Var(&c.dnsNames, "dns", "A DNS name that will be included in SVIDs issued based on this entry, where appropriate. Can be used more than once")
 	f.StringVar(&c.hint, "hint", "", "The entry hint, used to disambiguate entries with the same SPIFFE ID")
+	f.BoolVar(&c.enable, "enable", false, "If set, the entry given with -entryID is activated so it is used to issue SVIDs again. No other entry field can be set")
+	f.BoolVar(&c.disable, "disable", false, "If set, the entry given with -entryID is deactivated so it is no longer used to issue SVIDs, keeping its selectors and history. No other entry field can be set")
+	f.Var(&c.federatesWithAll, "federatesWithAll", "Whether the entry given with -entryID federates with all the trust domains known to the server, 'true' or 'false'. When set to 'false', the entry keeps federating with the current trust domains. No other entry field can be set")
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, prettyPrintUpdate)
 }
 
 func (c *updateCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient serverutil.ServerClient) error {
+	if (c.enable || c.disable) && c.federatesWithAll != commoncli.BoolFlagAll {
+		return errors.New("-federatesWithAll cannot be combined with -enable or -disable")
+	}
+	if c.enable || c.disable {
+		return c.setActive(ctx, serverClient.NewEntryClient())
+	}
+	if c.federatesWithAll != commoncli.BoolFlagAll {
+		return c.setFederatesWithAll(ctx, serverClient.NewEntryClient())
+	}
+
 	if err := c.validate(); err != nil {
 		return err
 	}
@@ -117,11 +141,52 @@ func (c *updateCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient
 		return err
 	}
 
-	resp, err := updateEntries(ctx, serverClient.NewEntryClient(), entries)
+	resp, err := updateEntries(ctx, serverClient.NewEntryClient(), entries, nil)
+	if err != nil {
+		return err
+	}
+
+	return c.printer.PrintProto(resp)
+}
+
+// setActive activates or deactivates the entry. types.Entry has no field for
+// the state, so it is sent as metadata of an update that changes no other
+// field of the entry.
+func (c *updateCommand) setActive(ctx context.Context, client entryv1.EntryClient) error {
+	switch {
+	case c.enable && c.disable:
+		return errors.New("only one of -enable or -disable may be set")
+	case c.entryID == "":
+		return errors.New("entry ID is required")
+	case c.path != "" || c.parentID != "" || c.spiffeID != "" || len(c.selectors) > 0:
+		return errors.New("-enable and -disable cannot be combined with other entry fields")
+	}
+
+	ctx = metadata.AppendToOutgoingContext(ctx, api.EntryActiveMetadataKey, strconv.FormatBool(c.enable))
+	resp, err := updateEntries(ctx, client, []*types.Entry{{Id: c.entryID}}, &types.EntryMask{})
 	if err != nil {
 		return err
 	}
+	return c.printer.PrintProto(resp)
+}
+
+// setFederatesWithAll sets whether the entry federates with all the trust
+// domains known to the server. types.Entry has no field for it, so it is sent
+// as metadata of an update that changes no other field of the entry.
+func (c *updateCommand) setFederatesWithAll(ctx context.Context, client entryv1.EntryClient) error {
+	switch {
+	case c.entryID == "":
+		return errors.New("entry ID is required")
+	case c.path != "" || c.parentID != "" || c.spiffeID != "" || len(c.selectors) > 0 || len(c.federatesWith) > 0:
+		return errors.New("-federatesWithAll cannot be combined with other entry fields")
+	}
 
+	federatesWithAll := c.federatesWithAll == commoncli.BoolFlagTrue
+	ctx = metadata.AppendToOutgoingContext(ctx, api.EntryFederatesWithAllMetadataKey, strconv.FormatBool(federatesWithAll))
+	resp, err := updateEntries(ctx, client, []*types.Entry{{Id: c.entryID}}, &types.EntryMask{})
+	if err != nil {
+		return err
+	}
 	return c.printer.PrintProto(resp)
 }
 
@@ -210,9 +275,10 @@ func (c *updateCommand) parseConfig() ([]*types.Entry, error) {
 	return []*types.Entry{e}, nil
 }
 
-func updateEntries(ctx context.Context, c entryv1.EntryClient, entries []*types.Entry) (resp *entryv1.BatchUpdateEntryResponse, err error) {
+func updateEntries(ctx context.Context, c entryv1.EntryClient, entries []*types.Entry, inputMask *types.EntryMask) (resp *entryv1.BatchUpdateEntryResponse, err error) {
 	resp, err = c.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
-		Entries: entries,
+		Entries:   entries,
+		InputMask: inputMask,
 	})
 	if err != nil {
 		return
@@ -246,7 +312,7 @@ func prettyPrintUpdate(env *commoncli.Env, results ...any) error {
 	}
 	// Print entries that succeeded to be updated
 	for _, e := range succeeded {
-		printEntry(e.Entry, env.Printf)
+		printEntry(e.Entry, nil, env.Printf)
 	}
 
 	// Print entries that failed to be updated
@@ -254,7 +320,7 @@ func prettyPrintUpdate(env *commoncli.Env, results ...any) error {
 		env.ErrPrintf("Failed to update the following entry (code: %s, msg: %q):\n",
 			util.MustCast[codes.Code](r.Status.Code),
 			r.Status.Message)
-		printEntry(r.Entry, env.ErrPrintf)
+		printEntry(r.Entry, nil, env.ErrPrintf)
 	}
 
 	if len(failed) > 0 {
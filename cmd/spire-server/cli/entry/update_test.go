@@ -652,3 +652,190 @@ Error: failed to update one or more entries
 		}
 	}
 }
+
+func TestUpdateEnableDisable(t *testing.T) {
+	entry := &types.Entry{
+		Id:        "entry-id",
+		SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+		ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+	}
+	expReq := &entryv1.BatchUpdateEntryRequest{
+		Entries:   []*types.Entry{{Id: "entry-id"}},
+		InputMask: &types.EntryMask{},
+	}
+	okResp := &entryv1.BatchUpdateEntryResponse{
+		Results: []*entryv1.BatchUpdateEntryResponse_Result{
+			{
+				Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+				Entry:  entry,
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name      string
+		args      []string
+		expReq    *entryv1.BatchUpdateEntryRequest
+		expActive []string
+		resp      *entryv1.BatchUpdateEntryResponse
+		expOut    string
+		expErr    string
+	}{
+		{
+			name:      "Disable",
+			args:      []string{"-entryID", "entry-id", "-disable"},
+			expReq:    expReq,
+			expActive: []string{"false"},
+			resp:      okResp,
+			expOut:    "Entry ID         : entry-id\n",
+		},
+		{
+			name:      "Enable",
+			args:      []string{"-entryID", "entry-id", "-enable"},
+			expReq:    expReq,
+			expActive: []string{"true"},
+			resp:      okResp,
+			expOut:    "Entry ID         : entry-id\n",
+		},
+		{
+			name:   "Both flags",
+			args:   []string{"-entryID", "entry-id", "-enable", "-disable"},
+			expErr: "Error: only one of -enable or -disable may be set\n",
+		},
+		{
+			name:   "Missing entry ID",
+			args:   []string{"-disable"},
+			expErr: "Error: entry ID is required\n",
+		},
+		{
+			name:   "Combined with entry fields",
+			args:   []string{"-entryID", "entry-id", "-disable", "-spiffeID", "spiffe://example.org/other"},
+			expErr: "Error: -enable and -disable cannot be combined with other entry fields\n",
+		},
+		{
+			name:      "Unknown entry",
+			args:      []string{"-entryID", "entry-id", "-disable"},
+			expReq:    expReq,
+			expActive: []string{"false"},
+			resp: &entryv1.BatchUpdateEntryResponse{
+				Results: []*entryv1.BatchUpdateEntryResponse_Result{
+					{
+						Status: &types.Status{Code: int32(codes.NotFound), Message: "failed to set entry active state: datastore-sql: record not found"},
+					},
+				},
+			},
+			expErr: `Failed to update the following entry (code: NotFound, msg: "failed to set entry active state: datastore-sql: record not found"):
+Entry ID         : entry-id
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newUpdateCommand)
+			test.server.expBatchUpdateEntryReq = tt.expReq
+			test.server.expEntryActive = tt.expActive
+			test.server.batchUpdateEntryResp = tt.resp
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Contains(t, test.stderr.String(), tt.expErr)
+				return
+			}
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Contains(t, test.stdout.String(), tt.expOut)
+		})
+	}
+}
+
+func TestUpdateFederatesWithAll(t *testing.T) {
+	expReq := &entryv1.BatchUpdateEntryRequest{
+		Entries:   []*types.Entry{{Id: "entry-id"}},
+		InputMask: &types.EntryMask{},
+	}
+	okResp := &entryv1.BatchUpdateEntryResponse{
+		Results: []*entryv1.BatchUpdateEntryResponse_Result{
+			{
+				Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+				Entry: &types.Entry{
+					Id:            "entry-id",
+					SpiffeId:      &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+					ParentId:      &types.SPIFFEID{TrustDomain: "example.org", Path: "/parent"},
+					Selectors:     []*types.Selector{{Type: "unix", Value: "uid:1000"}},
+					FederatesWith: []string{"domain.test"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range []struct {
+		name                string
+		args                []string
+		expReq              *entryv1.BatchUpdateEntryRequest
+		expFederatesWithAll []string
+		resp                *entryv1.BatchUpdateEntryResponse
+		expErr              string
+	}{
+		{
+			name:                "Set",
+			args:                []string{"-entryID", "entry-id", "-federatesWithAll", "true"},
+			expReq:              expReq,
+			expFederatesWithAll: []string{"true"},
+			resp:                okResp,
+		},
+		{
+			name:                "Clear",
+			args:                []string{"-entryID", "entry-id", "-federatesWithAll", "false"},
+			expReq:              expReq,
+			expFederatesWithAll: []string{"false"},
+			resp:                okResp,
+		},
+		{
+			name:   "Missing entry ID",
+			args:   []string{"-federatesWithAll", "true"},
+			expErr: "Error: entry ID is required\n",
+		},
+		{
+			name:   "Combined with entry fields",
+			args:   []string{"-entryID", "entry-id", "-federatesWithAll", "true", "-federatesWith", "spiffe://domain.test"},
+			expErr: "Error: -federatesWithAll cannot be combined with other entry fields\n",
+		},
+		{
+			name:   "Combined with disable",
+			args:   []string{"-entryID", "entry-id", "-federatesWithAll", "true", "-disable"},
+			expErr: "Error: -federatesWithAll cannot be combined with -enable or -disable\n",
+		},
+		{
+			name:                "Unknown entry",
+			args:                []string{"-entryID", "entry-id", "-federatesWithAll", "true"},
+			expReq:              expReq,
+			expFederatesWithAll: []string{"true"},
+			resp: &entryv1.BatchUpdateEntryResponse{
+				Results: []*entryv1.BatchUpdateEntryResponse_Result{
+					{
+						Status: &types.Status{Code: int32(codes.NotFound), Message: "failed to update entry: datastore-sql: record not found"},
+					},
+				},
+			},
+			expErr: `Failed to update the following entry (code: NotFound, msg: "failed to update entry: datastore-sql: record not found"):
+Entry ID         : entry-id
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t, newUpdateCommand)
+			test.server.expBatchUpdateEntryReq = tt.expReq
+			test.server.expFederatesWithAll = tt.expFederatesWithAll
+			test.server.batchUpdateEntryResp = tt.resp
+
+			rc := test.client.Run(test.args(tt.args...))
+			if tt.expErr != "" {
+				require.Equal(t, 1, rc)
+				require.Contains(t, test.stderr.String(), tt.expErr)
+				return
+			}
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Contains(t, test.stdout.String(), "FederatesWith    : domain.test")
+		})
+	}
+}
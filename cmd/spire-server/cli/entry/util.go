@@ -2,18 +2,26 @@ package entry
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"sigs.k8s.io/yaml"
 )
 
-func printEntry(e *types.Entry, printf func(string, ...any) error) {
+// printEntry prints the given entry. When the default SVID TTLs of the server
+// are known, TTLs are printed along with whether they are set by the entry or
+// inherited from the server defaults.
+func printEntry(e *types.Entry, ttlDefaults *svidTTLDefaults, printf func(string, ...any) error) {
 	_ = printf("Entry ID         : %s\n", printableEntryID(e.Id))
 	_ = printf("SPIFFE ID        : %s\n", protoToIDString(e.SpiffeId))
 	_ = printf("Parent ID        : %s\n", protoToIDString(e.ParentId))
@@ -23,15 +31,21 @@ func printEntry(e *types.Entry, printf func(string, ...any) error) {
 		_ = printf("Downstream       : %t\n", e.Downstream)
 	}
 
-	if e.X509SvidTtl == 0 {
+	switch {
+	case ttlDefaults != nil:
+		_ = printf("X509-SVID TTL    : %s\n", formatTTLWithSource(e.X509SvidTtl, ttlDefaults.x509))
+	case e.X509SvidTtl == 0:
 		_ = printf("X509-SVID TTL    : default\n")
-	} else {
+	default:
 		_ = printf("X509-SVID TTL    : %d\n", e.X509SvidTtl)
 	}
 
-	if e.JwtSvidTtl == 0 {
+	switch {
+	case ttlDefaults != nil:
+		_ = printf("JWT-SVID TTL     : %s\n", formatTTLWithSource(e.JwtSvidTtl, ttlDefaults.jwt))
+	case e.JwtSvidTtl == 0:
 		_ = printf("JWT-SVID TTL     : default\n")
-	} else {
+	default:
 		_ = printf("JWT-SVID TTL     : %d\n", e.JwtSvidTtl)
 	}
 
@@ -66,6 +80,25 @@ func printEntry(e *types.Entry, printf func(string, ...any) error) {
 	_ = printf("\n")
 }
 
+// ttlSource returns where an entry TTL comes from: the entry itself, or the
+// server defaults when the TTL is zero.
+func ttlSource(ttl int32) string {
+	if ttl == 0 {
+		return "default"
+	}
+	return "entry"
+}
+
+// formatTTLWithSource formats an entry TTL in seconds labeled with its
+// source, resolving inherited TTLs to the given server default.
+func formatTTLWithSource(ttl int32, defaultTTL time.Duration) string {
+	seconds := int64(ttl)
+	if ttl == 0 {
+		seconds = int64(defaultTTL / time.Second)
+	}
+	return fmt.Sprintf("%d (%s)", seconds, ttlSource(ttl))
+}
+
 // idStringToProto converts a SPIFFE ID from the given string to *types.SPIFFEID
 func idStringToProto(id string) (*types.SPIFFEID, error) {
 	idType, err := spiffeid.FromString(id)
@@ -123,6 +156,36 @@ func parseEntryJSON(in io.Reader, path string) ([]*types.Entry, error) {
 	return api.RegistrationEntriesToProto(entries.Entries)
 }
 
+// parseEntrySpecsFile reads a JSON or YAML array of registration entries and
+// returns each entry undecoded, so that a malformed entry does not prevent
+// the others from being created. If path is "-" the array is read from STDIN.
+func parseEntrySpecsFile(path string) ([]json.RawMessage, error) {
+	return parseEntrySpecs(os.Stdin, path)
+}
+
+func parseEntrySpecs(in io.Reader, path string) ([]json.RawMessage, error) {
+	r := in
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	dat, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []json.RawMessage
+	if err := yaml.Unmarshal(dat, &specs); err != nil {
+		return nil, fmt.Errorf("failed to parse entries file: %w", err)
+	}
+	return specs, nil
+}
+
 // StringsFlag defines a custom type for string lists. Doing
 // this allows us to support repeatable string flags.
 type StringsFlag []string
@@ -137,3 +200,94 @@ func (s *StringsFlag) Set(val string) error {
 	*s = append(*s, val)
 	return nil
 }
+
+// ttlFlag defines a custom type for SVID TTLs. The TTL is kept as a number
+// of seconds, and can be set either as a bare integer number of seconds or
+// as a duration string (e.g. 30m or 1h30m).
+type ttlFlag int
+
+// String returns the TTL in seconds.
+func (t *ttlFlag) String() string {
+	return strconv.Itoa(int(*t))
+}
+
+// Set parses the TTL from a number of seconds or a duration string.
+func (t *ttlFlag) Set(val string) error {
+	seconds, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return errors.New("expected a number of seconds or a duration (e.g. 30m or 1h30m)")
+		}
+		if d%time.Second != 0 {
+			return fmt.Errorf("sub-second TTLs are not supported: %s", d)
+		}
+		seconds = int64(d / time.Second)
+	}
+	if seconds > math.MaxInt32 || seconds < math.MinInt32 {
+		return fmt.Errorf("TTL cannot exceed %d seconds", math.MaxInt32)
+	}
+	*t = ttlFlag(seconds)
+	return nil
+}
+
+// boolFilterFlag defines a custom type for boolean filters. Unlike a plain
+// boolean flag, it tells an unset flag apart from one explicitly set to
+// false, so that e.g. -admin=false matches only entries that are not admin,
+// while omitting the flag does not filter at all.
+type boolFilterFlag struct {
+	value *bool
+}
+
+// String returns the flag value, or an empty string if it is not set.
+func (b *boolFilterFlag) String() string {
+	if b.value == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b.value)
+}
+
+// Set parses the flag value.
+func (b *boolFilterFlag) Set(val string) error {
+	v, err := strconv.ParseBool(val)
+	if err != nil {
+		return err
+	}
+	b.value = &v
+	return nil
+}
+
+// IsBoolFlag allows the flag to be set without a value.
+func (b *boolFilterFlag) IsBoolFlag() bool {
+	return true
+}
+
+// stringFilterFlag defines a custom type for string filters. Unlike a plain
+// string flag, it tells an unset flag apart from one explicitly set to an
+// empty string, so that e.g. -hint "" matches only entries without a hint,
+// while omitting the flag does not filter at all.
+type stringFilterFlag struct {
+	value *string
+}
+
+// String returns the flag value, or an empty string if it is not set.
+func (s *stringFilterFlag) String() string {
+	if s.value == nil {
+		return ""
+	}
+	return *s.value
+}
+
+// Set sets the flag value.
+func (s *stringFilterFlag) Set(val string) error {
+	s.value = &val
+	return nil
+}
+
+// StringValue returns the flag value as a filter, or nil if it is not set.
+func (s *stringFilterFlag) StringValue() *wrapperspb.StringValue {
+	if s.value == nil {
+		return nil
+	}
+	return wrapperspb.String(*s.value)
+}
@@ -2,6 +2,8 @@
 
 package entry
 
+import "github.com/spiffe/spire/test/clitest"
+
 const (
 	createUsage = `Usage of entry create:
   -admin
@@ -18,10 +20,14 @@ const (
     	A custom ID for this registration entry (optional). If not set, a new entry ID will be generated
   -federatesWith value
     	SPIFFE ID of a trust domain to federate with. Can be used more than once
+  -file string
+    	Path to a file containing a JSON or YAML array of registration entries (optional). Invalid entries are reported and skipped. If set to '-', read from stdin.
   -hint string
     	The entry hint, used to disambiguate entries with the same SPIFFE ID
-  -jwtSVIDTTL int
-    	The lifetime, in seconds, for JWT-SVIDs issued based on this registration entry.
+  -idempotencyKey string
+    	A client-supplied key for the entry (optional). If an entry was already created with the same key, that entry is returned instead of creating a new one
+  -jwtSVIDTTL duration
+    	The lifetime, in seconds or as a duration (e.g. 30m), for JWT-SVIDs issued based on this registration entry.
   -node
     	If set, this entry will be applied to matching nodes rather than workloads
   -output value
@@ -36,18 +42,26 @@ const (
     	The SPIFFE ID that this record represents
   -storeSVID
     	A boolean value that, when set, indicates that the resulting issued SVID from this entry must be stored through an SVIDStore plugin
-  -x509SVIDTTL int
-    	The lifetime, in seconds, for x509-SVIDs issued based on this registration entry.
+  -x509SVIDTTL duration
+    	The lifetime, in seconds or as a duration (e.g. 30m), for x509-SVIDs issued based on this registration entry.
 `
 	showUsage = `Usage of entry show:
+  -admin
+    	If set, only admin entries are shown. Use -admin=false to show only the other entries
+  -config string
+    	Path to the SPIRE server config file, used to resolve the default SVID TTLs (optional). If not set, the built-in defaults are assumed
   -downstream
     	A boolean value that, when set, indicates that the entry describes a downstream SPIRE server
   -entryID string
     	The Entry ID of the records to show
+  -expandEnv
+    	Expand environment variables in the SPIRE server config file
+  -explain
+    	If set, the plan of the datastore query listing the matching entries is shown instead of the entries. Requires the explain_queries option of the SQL datastore
   -federatesWith value
     	SPIFFE ID of a trust domain an entry is federate with. Can be used more than once
-  -hint string
-    	The Hint of the records to show (optional)
+  -hint value
+    	The Hint of the records to show (optional). Use -hint "" to show only entries without a hint
   -matchFederatesWithOn string
     	The match mode used when filtering by federates with. Options: exact, any, superset and subset (default "superset")
   -matchSelectorsOn string
@@ -56,32 +70,42 @@ const (
     	Desired output format (pretty, json); default: pretty.
   -parentID string
     	The Parent ID of the records to show
+  -parents
+    	If set, the entry given by -entryID is shown followed by the chain of entries it is delegated from, each one having the parent ID of the previous one as SPIFFE ID
   -selector value
     	A colon-delimited type:value selector. Can be used more than once
   -socketPath string
     	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
   -spiffeID string
     	The SPIFFE ID of the records to show
+  -storeSVID
+    	If set, only entries whose issued SVIDs are stored through an SVIDStore plugin are shown
 `
 	updateUsage = `Usage of entry update:
   -admin
     	If set, the SPIFFE ID in this entry will be granted access to the SPIRE Server's management APIs
   -data string
     	Path to a file containing registration JSON (optional). If set to '-', read the JSON from stdin.
+  -disable
+    	If set, the entry given with -entryID is deactivated so it is no longer used to issue SVIDs, keeping its selectors and history. No other entry field can be set
   -dns value
     	A DNS name that will be included in SVIDs issued based on this entry, where appropriate. Can be used more than once
   -downstream
     	A boolean value that, when set, indicates that the entry describes a downstream SPIRE server
+  -enable
+    	If set, the entry given with -entryID is activated so it is used to issue SVIDs again. No other entry field can be set
   -entryExpiry int
     	An expiry, from epoch in seconds, for the resulting registration entry to be pruned
   -entryID string
     	The Registration Entry ID of the record to update
   -federatesWith value
     	SPIFFE ID of a trust domain to federate with. Can be used more than once
+  -federatesWithAll value
+    	Whether the entry given with -entryID federates with all the trust domains known to the server, 'true' or 'false'. When set to 'false', the entry keeps federating with the current trust domains. No other entry field can be set
   -hint string
     	The entry hint, used to disambiguate entries with the same SPIFFE ID
-  -jwtSVIDTTL int
-    	The lifetime, in seconds, for JWT-SVIDs issued based on this registration entry.
+  -jwtSVIDTTL duration
+    	The lifetime, in seconds or as a duration (e.g. 30m), for JWT-SVIDs issued based on this registration entry.
   -output value
     	Desired output format (pretty, json); default: pretty.
   -parentID string
@@ -94,8 +118,8 @@ const (
     	The SPIFFE ID that this record represents
   -storeSVID
     	A boolean value that, when set, indicates that the resulting issued SVID from this entry must be stored through an SVIDStore plugin
-  -x509SVIDTTL int
-    	The lifetime, in seconds, for x509-SVIDs issued based on this registration entry.
+  -x509SVIDTTL duration
+    	The lifetime, in seconds or as a duration (e.g. 30m), for x509-SVIDs issued based on this registration entry.
 `
 	deleteUsage = `Usage of entry delete:
   -entryID string
@@ -108,12 +132,14 @@ const (
     	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
 `
 	countUsage = `Usage of entry count:
+  -byTrustDomain
+    	If set, all entries are counted per trust domain of their SPIFFE ID. This flag can't be combined with filters
   -downstream
     	A boolean value that, when set, indicates that the entry describes a downstream SPIRE server
   -federatesWith value
     	SPIFFE ID of a trust domain an entry is federate with. Can be used more than once
-  -hint string
-    	The Hint of the records to count (optional)
+  -hint value
+    	The Hint of the records to count (optional). Use -hint "" to count only entries without a hint
   -matchFederatesWithOn string
     	The match mode used when filtering by federates with. Options: exact, any, superset and subset (default "superset")
   -matchSelectorsOn string
@@ -130,3 +156,40 @@ const (
     	The SPIFFE ID of the records to count
 `
 )
+
+var (
+	gcUsage = `Usage of entry gc:
+  -dryRun
+    	Only report the orphaned selectors and DNS names, without deleting them
+` + clitest.AddrUsage
+	exportUsage = `Usage of entry export:
+  -downstream
+    	A boolean value that, when set, indicates that the entry describes a downstream SPIRE server
+  -federatesWith value
+    	SPIFFE ID of a trust domain an entry is federate with. Can be used more than once
+  -file string
+    	Path to the file the entries are written to, readable only by its owner (optional). If not set, the entries are written to stdout
+  -format string
+    	The format of the document. Options: json and yaml (default "json")
+  -hint value
+    	The Hint of the records to export (optional). Use -hint "" to export only entries without a hint
+  -matchFederatesWithOn string
+    	The match mode used when filtering by federates with. Options: exact, any, superset and subset (default "superset")
+  -matchSelectorsOn string
+    	The match mode used when filtering by selectors. Options: exact, any, superset and subset (default "superset")
+  -parentID string
+    	The Parent ID of the records to export
+  -selector value
+    	A colon-delimited type:value selector. Can be used more than once
+` + clitest.AddrUsage + `  -spiffeID string
+    	The SPIFFE ID of the records to export
+`
+	importUsage = `Usage of entry import:
+  -file string
+    	Path to a file written by "entry export". The document can be JSON or YAML. If set to '-', read the document from stdin.
+  -output value
+    	Desired output format (pretty, json); default: pretty.
+  -preserveEntryIDs
+    	If set, entries are created with the entry IDs found in the file instead of new ones generated by the server
+` + clitest.AddrUsage
+)
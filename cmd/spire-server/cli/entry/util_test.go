@@ -3,20 +3,28 @@ package entry
 import (
 	"bytes"
 	"context"
+	"flag"
+	"io"
 	"os"
 	"path"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/api"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/test/clitest"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/util"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 var availableFormats = []string{"pretty", "json"}
@@ -135,13 +143,80 @@ func TestIDStringToProto(t *testing.T) {
 	require.Nil(t, id)
 }
 
+func TestTTLFlag(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		value     string
+		expectTTL int
+		expectErr string
+	}{
+		{
+			name:      "minutes",
+			value:     "30m",
+			expectTTL: 1800,
+		},
+		{
+			name:      "hours and minutes",
+			value:     "1h30m",
+			expectTTL: 5400,
+		},
+		{
+			name:      "bare integer as seconds",
+			value:     "90",
+			expectTTL: 90,
+		},
+		{
+			name:      "negative integer left for validation",
+			value:     "-10",
+			expectTTL: -10,
+		},
+		{
+			name:      "sub-second duration",
+			value:     "1500ms",
+			expectErr: `invalid value "1500ms" for flag -ttl: sub-second TTLs are not supported: 1.5s`,
+		},
+		{
+			name:      "duration out of range",
+			value:     "600000h",
+			expectErr: `invalid value "600000h" for flag -ttl: TTL cannot exceed 2147483647 seconds`,
+		},
+		{
+			name:      "integer out of range",
+			value:     "2147483648",
+			expectErr: `invalid value "2147483648" for flag -ttl: TTL cannot exceed 2147483647 seconds`,
+		},
+		{
+			name:      "malformed",
+			value:     "soon",
+			expectErr: `invalid value "soon" for flag -ttl: expected a number of seconds or a duration (e.g. 30m or 1h30m)`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var ttl int
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.SetOutput(io.Discard)
+			fs.Var((*ttlFlag)(&ttl), "ttl", "")
+
+			err := fs.Parse([]string{"-ttl", tt.value})
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectTTL, ttl)
+		})
+	}
+}
+
 type entryTest struct {
 	stdin  *bytes.Buffer
 	stdout *bytes.Buffer
 	stderr *bytes.Buffer
 
-	addr   string
-	server *fakeEntryServer
+	addr            string
+	server          *fakeEntryServer
+	extensionServer *fakeEntryExtensionServer
+	bundleServer    *fakeBundleServer
 
 	client cli.Command
 }
@@ -168,6 +243,9 @@ type fakeEntryServer struct {
 	expBatchDeleteEntryReq *entryv1.BatchDeleteEntryRequest
 	expBatchCreateEntryReq *entryv1.BatchCreateEntryRequest
 	expBatchUpdateEntryReq *entryv1.BatchUpdateEntryRequest
+	expIdempotencyKeys     []string
+	expEntryActive         []string
+	expFederatesWithAll    []string
 
 	getEntryResp         *types.Entry
 	countEntriesResp     *entryv1.CountEntriesResponse
@@ -208,22 +286,88 @@ func (f fakeEntryServer) BatchDeleteEntry(_ context.Context, req *entryv1.BatchD
 	return f.batchDeleteEntryResp, nil
 }
 
-func (f fakeEntryServer) BatchCreateEntry(_ context.Context, req *entryv1.BatchCreateEntryRequest) (*entryv1.BatchCreateEntryResponse, error) {
+func (f fakeEntryServer) BatchCreateEntry(ctx context.Context, req *entryv1.BatchCreateEntryRequest) (*entryv1.BatchCreateEntryResponse, error) {
 	if f.err != nil {
 		return nil, f.err
 	}
 	spiretest.AssertProtoEqual(f.t, f.expBatchCreateEntryReq, req)
+	md, _ := metadata.FromIncomingContext(ctx)
+	assert.Equal(f.t, f.expIdempotencyKeys, md.Get(api.IdempotencyKeyMetadataKey))
 	return f.batchCreateEntryResp, nil
 }
 
-func (f fakeEntryServer) BatchUpdateEntry(_ context.Context, req *entryv1.BatchUpdateEntryRequest) (*entryv1.BatchUpdateEntryResponse, error) {
+func (f fakeEntryServer) BatchUpdateEntry(ctx context.Context, req *entryv1.BatchUpdateEntryRequest) (*entryv1.BatchUpdateEntryResponse, error) {
 	if f.err != nil {
 		return nil, f.err
 	}
 	spiretest.AssertProtoEqual(f.t, f.expBatchUpdateEntryReq, req)
+	md, _ := metadata.FromIncomingContext(ctx)
+	assert.Equal(f.t, f.expEntryActive, md.Get(api.EntryActiveMetadataKey))
+	assert.Equal(f.t, f.expFederatesWithAll, md.Get(api.EntryFederatesWithAllMetadataKey))
 	return f.batchUpdateEntryResp, nil
 }
 
+type fakeEntryExtensionServer struct {
+	extensionv1.UnimplementedEntryExtensionServer
+
+	err error
+
+	gotPruneOrphanedEntryChildrenReq *extensionv1.PruneOrphanedEntryChildrenRequest
+	pruneOrphanedEntryChildrenResp   *extensionv1.PruneOrphanedEntryChildrenResponse
+	countEntriesByTrustDomainResp    *extensionv1.CountEntriesByTrustDomainResponse
+	gotExplainListEntriesReq         *extensionv1.ExplainListEntriesRequest
+	explainListEntriesResp           *extensionv1.ExplainListEntriesResponse
+	gotGetEntryWithParentsReq        *extensionv1.GetEntryWithParentsRequest
+	getEntryWithParentsResp          *extensionv1.GetEntryWithParentsResponse
+}
+
+func (f *fakeEntryExtensionServer) PruneOrphanedEntryChildren(_ context.Context, req *extensionv1.PruneOrphanedEntryChildrenRequest) (*extensionv1.PruneOrphanedEntryChildrenResponse, error) {
+	f.gotPruneOrphanedEntryChildrenReq = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.pruneOrphanedEntryChildrenResp, nil
+}
+
+func (f *fakeEntryExtensionServer) CountEntriesByTrustDomain(context.Context, *extensionv1.CountEntriesByTrustDomainRequest) (*extensionv1.CountEntriesByTrustDomainResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.countEntriesByTrustDomainResp, nil
+}
+
+func (f *fakeEntryExtensionServer) ExplainListEntries(_ context.Context, req *extensionv1.ExplainListEntriesRequest) (*extensionv1.ExplainListEntriesResponse, error) {
+	f.gotExplainListEntriesReq = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.explainListEntriesResp, nil
+}
+
+func (f *fakeEntryExtensionServer) GetEntryWithParents(_ context.Context, req *extensionv1.GetEntryWithParentsRequest) (*extensionv1.GetEntryWithParentsResponse, error) {
+	f.gotGetEntryWithParentsReq = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.getEntryWithParentsResp, nil
+}
+
+type fakeBundleServer struct {
+	bundlev1.UnimplementedBundleServer
+
+	// Names of the trust domains with a federated bundle
+	federatedTrustDomains []string
+}
+
+func (f *fakeBundleServer) GetFederatedBundle(_ context.Context, req *bundlev1.GetFederatedBundleRequest) (*types.Bundle, error) {
+	for _, td := range f.federatedTrustDomains {
+		if td == req.TrustDomain {
+			return &types.Bundle{TrustDomain: td}, nil
+		}
+	}
+	return nil, status.Error(codes.NotFound, "bundle not found")
+}
+
 func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *entryTest {
 	stdin := new(bytes.Buffer)
 	stdout := new(bytes.Buffer)
@@ -236,17 +380,23 @@ func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *entry
 	})
 
 	server := &fakeEntryServer{t: t}
+	extensionServer := &fakeEntryExtensionServer{}
+	bundleServer := &fakeBundleServer{}
 	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
 		entryv1.RegisterEntryServer(s, server)
+		extensionv1.RegisterEntryExtensionServer(s, extensionServer)
+		bundlev1.RegisterBundleServer(s, bundleServer)
 	})
 
 	test := &entryTest{
-		addr:   clitest.GetAddr(addr),
-		stdin:  stdin,
-		stdout: stdout,
-		stderr: stderr,
-		server: server,
-		client: client,
+		addr:            clitest.GetAddr(addr),
+		stdin:           stdin,
+		stdout:          stdout,
+		stderr:          stderr,
+		server:          server,
+		extensionServer: extensionServer,
+		bundleServer:    bundleServer,
+		client:          client,
 	}
 
 	t.Cleanup(func() {
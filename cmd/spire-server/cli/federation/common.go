@@ -1,16 +1,19 @@
 package federation
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"time"
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 )
 
 // FederationRelationships type is used for parsing federation relationships from file
@@ -211,3 +214,31 @@ func getRelationships(config *federationRelationshipConfig, path string) ([]*typ
 	}
 	return []*types.FederationRelationship{proto}, nil
 }
+
+// printLastPoll prints the outcome of the last bundle poll of a federation
+// relationship. The poll is nil if the server doesn't know the relationship.
+func printLastPoll(poll *extensionv1.FederationRelationshipPoll, printf func(format string, args ...any) error) {
+	if poll == nil || poll.LastPollAt == 0 {
+		_ = printf("Last poll                 : never\n")
+		return
+	}
+	_ = printf("Last poll                 : %s\n", time.Unix(poll.LastPollAt, 0).UTC().Format(time.RFC3339))
+	if poll.LastPollError != "" {
+		_ = printf("Last poll error           : %s\n", poll.LastPollError)
+	}
+}
+
+// loadLastPolls reads the outcome of the last bundle poll of each federation
+// relationship, keyed by trust domain name, since the trust domain API
+// doesn't expose it.
+func loadLastPolls(ctx context.Context, serverClient util.ServerClient) (map[string]*extensionv1.FederationRelationshipPoll, error) {
+	resp, err := serverClient.NewTrustDomainExtensionClient().ListFederationRelationshipPolls(ctx, &extensionv1.ListFederationRelationshipPollsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	lastPolls := make(map[string]*extensionv1.FederationRelationshipPoll, len(resp.Polls))
+	for _, poll := range resp.Polls {
+		lastPolls[poll.TrustDomain] = poll
+	}
+	return lastPolls, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/test/clitest"
 	"github.com/spiffe/spire/test/fakes/fakeserverca"
 	"github.com/spiffe/spire/test/spiretest"
@@ -110,8 +111,9 @@ type cmdTest struct {
 	stdout *bytes.Buffer
 	stderr *bytes.Buffer
 
-	addr   string
-	server *fakeServer
+	addr            string
+	server          *fakeServer
+	extensionServer *fakeExtensionServer
 
 	client cli.Command
 }
@@ -205,6 +207,32 @@ func (f *fakeServer) BatchUpdateFederationRelationship(_ context.Context, req *t
 	return f.updateResp, nil
 }
 
+type fakeExtensionServer struct {
+	extensionv1.UnimplementedTrustDomainExtensionServer
+
+	err   error
+	polls []*extensionv1.FederationRelationshipPoll
+
+	gotRefreshIntervalReqs []*extensionv1.SetFederationRelationshipRefreshIntervalRequest
+}
+
+func (f *fakeExtensionServer) SetFederationRelationshipRefreshInterval(_ context.Context, req *extensionv1.SetFederationRelationshipRefreshIntervalRequest) (*emptypb.Empty, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.gotRefreshIntervalReqs = append(f.gotRefreshIntervalReqs, req)
+	return &emptypb.Empty{}, nil
+}
+
+func (f *fakeExtensionServer) ListFederationRelationshipPolls(context.Context, *extensionv1.ListFederationRelationshipPollsRequest) (*extensionv1.ListFederationRelationshipPollsResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &extensionv1.ListFederationRelationshipPollsResponse{Polls: f.polls}, nil
+}
+
 func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *cmdTest {
 	stdin := new(bytes.Buffer)
 	stdout := new(bytes.Buffer)
@@ -217,17 +245,20 @@ func setupTest(t *testing.T, newClient func(*common_cli.Env) cli.Command) *cmdTe
 	})
 
 	server := &fakeServer{t: t}
+	extensionServer := &fakeExtensionServer{}
 	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
 		trustdomainv1.RegisterTrustDomainServer(s, server)
+		extensionv1.RegisterTrustDomainExtensionServer(s, extensionServer)
 	})
 
 	test := &cmdTest{
-		addr:   clitest.GetAddr(addr),
-		stdin:  stdin,
-		stdout: stdout,
-		stderr: stderr,
-		server: server,
-		client: client,
+		addr:            clitest.GetAddr(addr),
+		stdin:           stdin,
+		stdout:          stdout,
+		stderr:          stderr,
+		server:          server,
+		extensionServer: extensionServer,
+		client:          client,
 	}
 
 	t.Cleanup(func() {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"time"
 
 	"github.com/mitchellh/cli"
 	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
@@ -13,6 +14,7 @@ import (
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/common/util"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"google.golang.org/grpc/codes"
 )
 
@@ -36,6 +38,11 @@ type createCommand struct {
 	env                     *commoncli.Env
 	printer                 cliprinter.Printer
 	federationRelationships []*types.FederationRelationship
+
+	// How often the bundles of the created relationships are refreshed. The
+	// trust domain API can't set it, so it is set through its extension once
+	// the relationships are created.
+	refreshInterval time.Duration
 }
 
 func (*createCommand) Name() string {
@@ -50,6 +57,7 @@ func (c *createCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.path, "data", "", "Path to a file containing federation relationships in JSON format (optional). If set to '-', read the JSON from stdin.")
 	c.config = &federationRelationshipConfig{}
 	appendConfigFlags(c.config, f)
+	f.DurationVar(&c.refreshInterval, "refreshInterval", 0, "How often the trust domain bundle is refreshed from the bundle endpoint, overriding the refresh hint of the bundle (optional)")
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, c.prettyPrintCreate)
 }
 
@@ -60,6 +68,13 @@ func (c *createCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient
 	}
 	c.federationRelationships = federationRelationships
 
+	if c.refreshInterval < 0 {
+		return errors.New("refresh interval cannot be negative")
+	}
+	if c.refreshInterval > 0 && c.refreshInterval < time.Second {
+		return errors.New("refresh interval must be at least one second")
+	}
+
 	client := serverClient.NewTrustDomainClient()
 
 	resp, err := client.BatchCreateFederationRelationship(ctx, &trustdomainv1.BatchCreateFederationRelationshipRequest{
@@ -69,9 +84,32 @@ func (c *createCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient
 		return fmt.Errorf("request failed: %w", err)
 	}
 
+	if c.refreshInterval > 0 {
+		if err := c.setRefreshInterval(ctx, serverClient.NewTrustDomainExtensionClient(), resp); err != nil {
+			return err
+		}
+	}
+
 	return c.printer.PrintProto(resp)
 }
 
+// setRefreshInterval sets the refresh interval of the relationships that
+// were created.
+func (c *createCommand) setRefreshInterval(ctx context.Context, client extensionv1.TrustDomainExtensionClient, resp *trustdomainv1.BatchCreateFederationRelationshipResponse) error {
+	for _, r := range resp.Results {
+		if r.Status.Code != int32(codes.OK) {
+			continue
+		}
+		if _, err := client.SetFederationRelationshipRefreshInterval(ctx, &extensionv1.SetFederationRelationshipRefreshIntervalRequest{
+			TrustDomain:     r.FederationRelationship.TrustDomain,
+			RefreshInterval: int64(c.refreshInterval / time.Second),
+		}); err != nil {
+			return fmt.Errorf("failed to set the refresh interval of %q: %w", r.FederationRelationship.TrustDomain, err)
+		}
+	}
+	return nil
+}
+
 func (c *createCommand) prettyPrintCreate(env *commoncli.Env, results ...any) error {
 	createResp, ok := results[0].(*trustdomainv1.BatchCreateFederationRelationshipResponse)
 	if !ok || len(c.federationRelationships) < len(createResp.Results) {
@@ -96,6 +134,9 @@ func (c *createCommand) prettyPrintCreate(env *commoncli.Env, results ...any) er
 	for _, r := range succeeded {
 		env.Println()
 		printFederationRelationship(r.FederationRelationship, env.Printf)
+		if c.refreshInterval > 0 {
+			env.Printf("Refresh interval          : %s\n", c.refreshInterval)
+		}
 	}
 
 	// Print federation relationships that failed to be created
@@ -13,9 +13,11 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/pemutil"
 	"github.com/spiffe/spire/pkg/server/api"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestCreateHelp(t *testing.T) {
@@ -493,3 +495,76 @@ Endpoint SPIFFE ID        : spiffe://td-3.org/bundle
 		}
 	}
 }
+
+func TestCreateRefreshInterval(t *testing.T) {
+	frWeb := &types.FederationRelationship{
+		TrustDomain:           "td-1.org",
+		BundleEndpointUrl:     "https://td-1.org/bundle",
+		BundleEndpointProfile: &types.FederationRelationship_HttpsWeb{},
+	}
+
+	test := setupTest(t, newCreateCommand)
+	test.server.expectCreateReq = &trustdomainv1.BatchCreateFederationRelationshipRequest{
+		FederationRelationships: []*types.FederationRelationship{frWeb},
+	}
+	test.server.createResp = &trustdomainv1.BatchCreateFederationRelationshipResponse{
+		Results: []*trustdomainv1.BatchCreateFederationRelationshipResponse_Result{
+			{
+				Status:                 api.OK(),
+				FederationRelationship: frWeb,
+			},
+		},
+	}
+
+	rc := test.client.Run(test.args("-trustDomain", "td-1.org", "-bundleEndpointURL", "https://td-1.org/bundle",
+		"-bundleEndpointProfile", profileHTTPSWeb, "-refreshInterval", "1h"))
+	require.Equal(t, 0, rc, test.stderr.String())
+	require.Equal(t, `
+Trust domain              : td-1.org
+Bundle endpoint URL       : https://td-1.org/bundle
+Bundle endpoint profile   : https_web
+Refresh interval          : 1h0m0s
+`, test.stdout.String())
+	require.Len(t, test.extensionServer.gotRefreshIntervalReqs, 1)
+	spiretest.AssertProtoEqual(t, &extensionv1.SetFederationRelationshipRefreshIntervalRequest{
+		TrustDomain:     "td-1.org",
+		RefreshInterval: 3600,
+	}, test.extensionServer.gotRefreshIntervalReqs[0])
+}
+
+func TestCreateRefreshIntervalErrors(t *testing.T) {
+	test := setupTest(t, newCreateCommand)
+	rc := test.client.Run(test.args("-trustDomain", "td-1.org", "-bundleEndpointURL", "https://td-1.org/bundle",
+		"-bundleEndpointProfile", profileHTTPSWeb, "-refreshInterval", "-1h"))
+	require.Equal(t, 1, rc)
+	require.Equal(t, "Error: refresh interval cannot be negative\n", test.stderr.String())
+
+	test = setupTest(t, newCreateCommand)
+	rc = test.client.Run(test.args("-trustDomain", "td-1.org", "-bundleEndpointURL", "https://td-1.org/bundle",
+		"-bundleEndpointProfile", profileHTTPSWeb, "-refreshInterval", "500ms"))
+	require.Equal(t, 1, rc)
+	require.Equal(t, "Error: refresh interval must be at least one second\n", test.stderr.String())
+
+	frWeb := &types.FederationRelationship{
+		TrustDomain:           "td-1.org",
+		BundleEndpointUrl:     "https://td-1.org/bundle",
+		BundleEndpointProfile: &types.FederationRelationship_HttpsWeb{},
+	}
+	test = setupTest(t, newCreateCommand)
+	test.server.expectCreateReq = &trustdomainv1.BatchCreateFederationRelationshipRequest{
+		FederationRelationships: []*types.FederationRelationship{frWeb},
+	}
+	test.server.createResp = &trustdomainv1.BatchCreateFederationRelationshipResponse{
+		Results: []*trustdomainv1.BatchCreateFederationRelationshipResponse_Result{
+			{
+				Status:                 api.OK(),
+				FederationRelationship: frWeb,
+			},
+		},
+	}
+	test.extensionServer.err = status.Error(codes.Internal, "oh no")
+	rc = test.client.Run(test.args("-trustDomain", "td-1.org", "-bundleEndpointURL", "https://td-1.org/bundle",
+		"-bundleEndpointProfile", profileHTTPSWeb, "-refreshInterval", "1h"))
+	require.Equal(t, 1, rc)
+	require.Equal(t, "Error: failed to set the refresh interval of \"td-1.org\": rpc error: code = Internal desc = oh no\n", test.stderr.String())
+}
@@ -7,9 +7,11 @@ import (
 
 	"github.com/mitchellh/cli"
 	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 )
 
 func NewListCommand() cli.Command {
@@ -23,6 +25,17 @@ func newListCommand(env *commoncli.Env) cli.Command {
 type listCommand struct {
 	env     *commoncli.Env
 	printer cliprinter.Printer
+
+	// profile lists only the relationships with this bundle endpoint profile
+	profile string
+
+	// showLastPoll shows the outcome of the last bundle poll of each
+	// relationship
+	showLastPoll bool
+
+	// lastPolls holds the last bundle polls, keyed by trust domain name.
+	// Only read when showLastPoll is set.
+	lastPolls map[string]*extensionv1.FederationRelationshipPoll
 }
 
 func (c *listCommand) Name() string {
@@ -34,20 +47,56 @@ func (c *listCommand) Synopsis() string {
 }
 
 func (c *listCommand) AppendFlags(fs *flag.FlagSet) {
-	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, prettyPrintList)
+	fs.StringVar(&c.profile, "profile", "", fmt.Sprintf("List only the relationships with the given bundle endpoint profile (either %q or %q)", profileHTTPSWeb, profileHTTPSSPIFFE))
+	fs.BoolVar(&c.showLastPoll, "lastPoll", false, "Show the time and error of the last bundle poll of each relationship (only pretty output format supports this flag)")
+	cliprinter.AppendFlagWithCustomPretty(&c.printer, fs, c.env, c.prettyPrintList)
 }
 
 func (c *listCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient util.ServerClient) error {
+	switch c.profile {
+	case "", profileHTTPSWeb, profileHTTPSSPIFFE:
+	default:
+		return fmt.Errorf("unknown bundle endpoint profile type: %q", c.profile)
+	}
+
 	trustDomainClient := serverClient.NewTrustDomainClient()
 
 	resp, err := trustDomainClient.ListFederationRelationships(ctx, &trustdomainv1.ListFederationRelationshipsRequest{})
 	if err != nil {
 		return fmt.Errorf("error listing federation relationship: %w", err)
 	}
+
+	// The API cannot filter by profile, so the relationships are filtered here
+	if c.profile != "" {
+		federationRelationships := []*types.FederationRelationship{}
+		for _, fr := range resp.FederationRelationships {
+			if hasBundleEndpointProfile(fr, c.profile) {
+				federationRelationships = append(federationRelationships, fr)
+			}
+		}
+		resp.FederationRelationships = federationRelationships
+	}
+
+	if c.showLastPoll {
+		if c.lastPolls, err = loadLastPolls(ctx, serverClient); err != nil {
+			return err
+		}
+	}
 	return c.printer.PrintProto(resp)
 }
 
-func prettyPrintList(env *commoncli.Env, results ...any) error {
+func hasBundleEndpointProfile(fr *types.FederationRelationship, profile string) bool {
+	switch fr.BundleEndpointProfile.(type) {
+	case *types.FederationRelationship_HttpsWeb:
+		return profile == profileHTTPSWeb
+	case *types.FederationRelationship_HttpsSpiffe:
+		return profile == profileHTTPSSPIFFE
+	default:
+		return false
+	}
+}
+
+func (c *listCommand) prettyPrintList(env *commoncli.Env, results ...any) error {
 	listResp, ok := results[0].(*trustdomainv1.ListFederationRelationshipsResponse)
 	if !ok {
 		return cliprinter.ErrInternalCustomPrettyFunc
@@ -59,6 +108,9 @@ func prettyPrintList(env *commoncli.Env, results ...any) error {
 	for _, fr := range listResp.FederationRelationships {
 		env.Println()
 		printFederationRelationship(fr, env.Printf)
+		if c.lastPolls != nil {
+			printLastPoll(c.lastPolls[fr.TrustDomain], env.Printf)
+		}
 	}
 
 	return nil
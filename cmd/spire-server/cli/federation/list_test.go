@@ -3,9 +3,11 @@ package federation
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -154,6 +156,69 @@ Endpoint SPIFFE ID        : spiffe://baz.test/id
   "next_page_token": ""
 }`,
 		},
+		{
+			name:          "by https_web profile",
+			args:          []string{"-profile", "https_web"},
+			expectListReq: &trustdomainv1.ListFederationRelationshipsRequest{},
+			listResp: &trustdomainv1.ListFederationRelationshipsResponse{
+				FederationRelationships: []*types.FederationRelationship{
+					federation1,
+					federation2,
+					federation3,
+				},
+			},
+			expectOutPretty: `Found 1 federation relationship
+
+Trust domain              : foh.test
+Bundle endpoint URL       : https://foo.test/endpoint
+Bundle endpoint profile   : https_web
+`,
+			expectOutJSON: `{
+  "federation_relationships": [
+    {
+      "trust_domain": "foh.test",
+      "bundle_endpoint_url": "https://foo.test/endpoint",
+      "https_web": {}
+    }
+  ],
+  "next_page_token": ""
+}`,
+		},
+		{
+			name:          "by https_spiffe profile",
+			args:          []string{"-profile", "https_spiffe"},
+			expectListReq: &trustdomainv1.ListFederationRelationshipsRequest{},
+			listResp: &trustdomainv1.ListFederationRelationshipsResponse{
+				FederationRelationships: []*types.FederationRelationship{
+					federation1,
+					federation3,
+				},
+			},
+			expectOutPretty: `Found 1 federation relationship
+
+Trust domain              : baz.test
+Bundle endpoint URL       : https://baz.test/endpoint
+Bundle endpoint profile   : https_spiffe
+Endpoint SPIFFE ID        : spiffe://baz.test/id
+`,
+			expectOutJSON: `{
+  "federation_relationships": [
+    {
+      "trust_domain": "baz.test",
+      "bundle_endpoint_url": "https://baz.test/endpoint",
+      "https_spiffe": {
+        "endpoint_spiffe_id": "spiffe://baz.test/id"
+      }
+    }
+  ],
+  "next_page_token": ""
+}`,
+		},
+		{
+			name:      "unknown profile",
+			args:      []string{"-profile", "https"},
+			expectErr: "Error: unknown bundle endpoint profile type: \"https\"\n",
+		},
 		{
 			name:      "server fails",
 			serverErr: status.Error(codes.Internal, "oh! no"),
@@ -182,3 +247,53 @@ Endpoint SPIFFE ID        : spiffe://baz.test/id
 		}
 	}
 }
+
+func TestListLastPoll(t *testing.T) {
+	test := setupTest(t, newListCommand)
+	test.server.expectListReq = &trustdomainv1.ListFederationRelationshipsRequest{}
+	test.server.listResp = &trustdomainv1.ListFederationRelationshipsResponse{
+		FederationRelationships: []*types.FederationRelationship{
+			{
+				TrustDomain:           "foh.test",
+				BundleEndpointUrl:     "https://foo.test/endpoint",
+				BundleEndpointProfile: &types.FederationRelationship_HttpsWeb{},
+			},
+			{
+				TrustDomain:           "bar.test",
+				BundleEndpointUrl:     "https://bar.test/endpoint",
+				BundleEndpointProfile: &types.FederationRelationship_HttpsWeb{},
+			},
+		},
+	}
+
+	test.extensionServer.polls = []*extensionv1.FederationRelationshipPoll{
+		{TrustDomain: "foh.test", LastPollAt: time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC).Unix()},
+		{TrustDomain: "bar.test"},
+	}
+
+	rc := test.client.Run(test.args("-lastPoll"))
+	require.Equal(t, 0, rc, test.stderr.String())
+	require.Equal(t, `Found 2 federation relationships
+
+Trust domain              : foh.test
+Bundle endpoint URL       : https://foo.test/endpoint
+Bundle endpoint profile   : https_web
+Last poll                 : 2026-10-15T08:00:00Z
+
+Trust domain              : bar.test
+Bundle endpoint URL       : https://bar.test/endpoint
+Bundle endpoint profile   : https_web
+Last poll                 : never
+`, test.stdout.String())
+}
+
+func TestListLastPollError(t *testing.T) {
+	test := setupTest(t, newListCommand)
+	test.server.expectListReq = &trustdomainv1.ListFederationRelationshipsRequest{}
+	test.server.listResp = &trustdomainv1.ListFederationRelationshipsResponse{}
+	test.extensionServer.err = status.Error(codes.Internal, "oh no")
+
+	rc := test.client.Run(test.args("-lastPoll"))
+	require.Equal(t, 1, rc)
+	require.Equal(t, "Error: rpc error: code = Internal desc = oh no\n", test.stderr.String())
+}
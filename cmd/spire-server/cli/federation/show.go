@@ -12,6 +12,7 @@ import (
 	"github.com/spiffe/spire/cmd/spire-server/util"
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 )
 
 func NewShowCommand() cli.Command {
@@ -27,6 +28,13 @@ type showCommand struct {
 	trustDomain string
 	env         *commoncli.Env
 	printer     cliprinter.Printer
+
+	// showLastPoll shows the outcome of the last bundle poll
+	showLastPoll bool
+
+	// lastPolls holds the last bundle polls, keyed by trust domain name.
+	// Only read when showLastPoll is set.
+	lastPolls map[string]*extensionv1.FederationRelationshipPoll
 }
 
 func (c *showCommand) Name() string {
@@ -39,6 +47,7 @@ func (c *showCommand) Synopsis() string {
 
 func (c *showCommand) AppendFlags(f *flag.FlagSet) {
 	f.StringVar(&c.trustDomain, "trustDomain", "", "The trust domain name of the federation relationship to show")
+	f.BoolVar(&c.showLastPoll, "lastPoll", false, "Show the time and error of the last bundle poll (only pretty output format supports this flag)")
 	cliprinter.AppendFlagWithCustomPretty(&c.printer, f, c.env, c.prettyPrintShow)
 }
 
@@ -56,6 +65,12 @@ func (c *showCommand) Run(ctx context.Context, _ *commoncli.Env, serverClient ut
 		return fmt.Errorf("error showing federation relationship: %w", err)
 	}
 
+	if c.showLastPoll {
+		if c.lastPolls, err = loadLastPolls(ctx, serverClient); err != nil {
+			return err
+		}
+	}
+
 	return c.printer.PrintProto(fr)
 }
 
@@ -66,6 +81,9 @@ func (c *showCommand) prettyPrintShow(env *commoncli.Env, results ...any) error
 	}
 	env.Printf("Found a federation relationship with trust domain %s:\n\n", c.trustDomain)
 	printFederationRelationship(fr, env.Printf)
+	if c.lastPolls != nil {
+		printLastPoll(c.lastPolls[fr.TrustDomain], env.Printf)
+	}
 
 	return nil
 }
@@ -3,9 +3,11 @@ package federation
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	trustdomainv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/trustdomain/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -133,3 +135,32 @@ Endpoint SPIFFE ID        : spiffe://endpoint.test/id
 		}
 	}
 }
+
+func TestShowLastPoll(t *testing.T) {
+	test := setupTest(t, newShowCommand)
+	test.server.expectShowReq = &trustdomainv1.GetFederationRelationshipRequest{}
+	test.server.showResp = &types.FederationRelationship{
+		TrustDomain:           "example-1.test",
+		BundleEndpointUrl:     "https://bundle-endpoint-1.test/endpoint",
+		BundleEndpointProfile: &types.FederationRelationship_HttpsWeb{},
+	}
+
+	test.extensionServer.polls = []*extensionv1.FederationRelationshipPoll{
+		{
+			TrustDomain:   "example-1.test",
+			LastPollAt:    time.Date(2026, 10, 15, 8, 0, 0, 0, time.UTC).Unix(),
+			LastPollError: "connection refused",
+		},
+	}
+
+	rc := test.client.Run(test.args("-trustDomain", "example-1.test", "-lastPoll"))
+	require.Equal(t, 0, rc, test.stderr.String())
+	require.Equal(t, `Found a federation relationship with trust domain example-1.test:
+
+Trust domain              : example-1.test
+Bundle endpoint URL       : https://bundle-endpoint-1.test/endpoint
+Bundle endpoint profile   : https_web
+Last poll                 : 2026-10-15T08:00:00Z
+Last poll error           : connection refused
+`, test.stdout.String())
+}
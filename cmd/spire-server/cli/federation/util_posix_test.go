@@ -14,6 +14,8 @@ const (
     	SPIFFE ID of the SPIFFE bundle endpoint server. Only used for 'spiffe' profile.
   -output value
     	Desired output format (pretty, json); default: pretty.
+  -refreshInterval duration
+    	How often the trust domain bundle is refreshed from the bundle endpoint, overriding the refresh hint of the bundle (optional)
   -socketPath string
     	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
   -trustDomain string
@@ -32,8 +34,12 @@ const (
     	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
 `
 	listUsage = `Usage of federation list:
+  -lastPoll
+    	Show the time and error of the last bundle poll of each relationship (only pretty output format supports this flag)
   -output value
     	Desired output format (pretty, json); default: pretty.
+  -profile string
+    	List only the relationships with the given bundle endpoint profile (either "https_web" or "https_spiffe")
   -socketPath string
     	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
 `
@@ -46,6 +52,8 @@ const (
     	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
 `
 	showUsage = `Usage of federation show:
+  -lastPoll
+    	Show the time and error of the last bundle poll (only pretty output format supports this flag)
   -output value
     	Desired output format (pretty, json); default: pretty.
   -socketPath string
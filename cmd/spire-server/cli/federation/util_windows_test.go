@@ -16,6 +16,8 @@ const (
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -output value
     	Desired output format (pretty, json); default: pretty.
+  -refreshInterval duration
+    	How often the trust domain bundle is refreshed from the bundle endpoint, overriding the refresh hint of the bundle (optional)
   -trustDomain string
     	Name of the trust domain to federate with (e.g., example.org)
   -trustDomainBundleFormat string
@@ -32,10 +34,14 @@ const (
     	Desired output format (pretty, json); default: pretty.
 `
 	listUsage = `Usage of federation list:
+  -lastPoll
+    	Show the time and error of the last bundle poll of each relationship (only pretty output format supports this flag)
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -output value
     	Desired output format (pretty, json); default: pretty.
+  -profile string
+    	List only the relationships with the given bundle endpoint profile (either "https_web" or "https_spiffe")
 `
 	refreshUsage = `Usage of federation refresh:
   -id string
@@ -46,6 +52,8 @@ const (
     	Desired output format (pretty, json); default: pretty.
 `
 	showUsage = `Usage of federation show:
+  -lastPoll
+    	Show the time and error of the last bundle poll (only pretty output format supports this flag)
   -namedPipeName string
     	Pipe name of the SPIRE Server API named pipe (default "\\spire-server\\private\\api")
   -output value
@@ -0,0 +1,106 @@
+package prune
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire/cmd/spire-server/util"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/common/idutil"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
+)
+
+// NewPruneCommand creates a new "prune" command.
+func NewPruneCommand() cli.Command {
+	return newPruneCommand(commoncli.DefaultEnv)
+}
+
+func newPruneCommand(env *commoncli.Env) cli.Command {
+	return util.AdaptCommand(env, &pruneCommand{})
+}
+
+// pruneCommand reports the registration entries, attested nodes and bundle
+// authorities that the server would prune.
+type pruneCommand struct {
+	dryRun                  bool
+	attestedNodesExpiredFor time.Duration
+}
+
+func (*pruneCommand) Name() string {
+	return "prune"
+}
+
+func (*pruneCommand) Synopsis() string {
+	return "Reports the registration entries, agents and bundle authorities the server would prune"
+}
+
+func (c *pruneCommand) AppendFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.dryRun, "dryRun", false, "Only report what the server would prune, without deleting it. Required, since pruning itself is done by the server")
+	fs.DurationVar(&c.attestedNodesExpiredFor, "attestedNodesExpiredFor", 0, "How long ago attested nodes must have expired to be pruned (default the prune_attested_nodes_expired_for value the server runs with)")
+}
+
+func (c *pruneCommand) Run(ctx context.Context, env *commoncli.Env, serverClient util.ServerClient) error {
+	if !c.dryRun {
+		return errors.New("only -dryRun is supported; the server prunes the datastore itself")
+	}
+	if c.attestedNodesExpiredFor < 0 {
+		return errors.New("-attestedNodesExpiredFor cannot be negative")
+	}
+
+	entries, err := serverClient.NewEntryExtensionClient().ListEntriesToPrune(ctx, &extensionv1.ListEntriesToPruneRequest{})
+	if err != nil {
+		return err
+	}
+	if err := printIDs(env, "Registration entries to prune", entries.EntryIds); err != nil {
+		return err
+	}
+
+	agents, err := serverClient.NewAgentExtensionClient().ListAgentsToPrune(ctx, &extensionv1.ListAgentsToPruneRequest{
+		ExpiredFor: int64(c.attestedNodesExpiredFor / time.Second),
+	})
+	if err != nil {
+		return err
+	}
+	if agents.ExpiredFor == 0 {
+		if err := env.Println("Agents to prune: none, attested nodes are not pruned"); err != nil {
+			return err
+		}
+	} else {
+		agentIDs := make([]string, 0, len(agents.Ids))
+		for _, id := range agents.Ids {
+			agentID, err := idutil.IDProtoString(id)
+			if err != nil {
+				return err
+			}
+			agentIDs = append(agentIDs, agentID)
+		}
+		if err := printIDs(env, "Agents to prune", agentIDs); err != nil {
+			return err
+		}
+	}
+
+	authorities, err := serverClient.NewBundleExtensionClient().ListBundleAuthoritiesToPrune(ctx, &extensionv1.ListBundleAuthoritiesToPruneRequest{})
+	if err != nil {
+		return err
+	}
+	if err := printIDs(env, fmt.Sprintf("X.509 authorities to prune from the %q bundle", authorities.TrustDomain), authorities.X509AuthorityIds); err != nil {
+		return err
+	}
+	return printIDs(env, fmt.Sprintf("JWT authorities to prune from the %q bundle", authorities.TrustDomain), authorities.JwtAuthorityIds)
+}
+
+func printIDs(env *commoncli.Env, title string, ids []string) error {
+	if err := env.Printf("%s: %d\n", title, len(ids)); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := env.Printf("  %s\n", id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package prune
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	commoncli "github.com/spiffe/spire/pkg/common/cli"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
+	"github.com/spiffe/spire/test/clitest"
+	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var pruneUsage = `Usage of prune:
+  -attestedNodesExpiredFor duration
+    	How long ago attested nodes must have expired to be pruned (default the prune_attested_nodes_expired_for value the server runs with)
+  -dryRun
+    	Only report what the server would prune, without deleting it. Required, since pruning itself is done by the server
+` + clitest.AddrUsage
+
+func TestPruneHelp(t *testing.T) {
+	test := setupTest(t)
+	test.client.Help()
+
+	require.Equal(t, pruneUsage, test.stderr.String())
+}
+
+func TestPruneSynopsis(t *testing.T) {
+	test := setupTest(t)
+	require.Equal(t, "Reports the registration entries, agents and bundle authorities the server would prune", test.client.Synopsis())
+}
+
+func TestPrune(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		args            []string
+		agentsResp      *extensionv1.ListAgentsToPruneResponse
+		expectAgentsReq *extensionv1.ListAgentsToPruneRequest
+		expectStdout    string
+	}{
+		{
+			name: "server prune setting",
+			agentsResp: &extensionv1.ListAgentsToPruneResponse{
+				Ids: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/spire/agent/expired"},
+				},
+				ExpiredFor: 3600,
+			},
+			expectAgentsReq: &extensionv1.ListAgentsToPruneRequest{},
+			expectStdout: `Registration entries to prune: 1
+  entry-1
+Agents to prune: 1
+  spiffe://example.org/spire/agent/expired
+X.509 authorities to prune from the "example.org" bundle: 1
+  x509-authority-1
+JWT authorities to prune from the "example.org" bundle: 1
+  jwt-authority-1
+`,
+		},
+		{
+			name: "requested expiry",
+			args: []string{"-attestedNodesExpiredFor", "2h"},
+			agentsResp: &extensionv1.ListAgentsToPruneResponse{
+				ExpiredFor: 7200,
+			},
+			expectAgentsReq: &extensionv1.ListAgentsToPruneRequest{ExpiredFor: 7200},
+			expectStdout: `Registration entries to prune: 1
+  entry-1
+Agents to prune: 0
+X.509 authorities to prune from the "example.org" bundle: 1
+  x509-authority-1
+JWT authorities to prune from the "example.org" bundle: 1
+  jwt-authority-1
+`,
+		},
+		{
+			name:            "attested nodes not pruned",
+			agentsResp:      &extensionv1.ListAgentsToPruneResponse{},
+			expectAgentsReq: &extensionv1.ListAgentsToPruneRequest{},
+			expectStdout: `Registration entries to prune: 1
+  entry-1
+Agents to prune: none, attested nodes are not pruned
+X.509 authorities to prune from the "example.org" bundle: 1
+  x509-authority-1
+JWT authorities to prune from the "example.org" bundle: 1
+  jwt-authority-1
+`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t)
+			test.agentServer.resp = tt.agentsResp
+
+			rc := test.client.Run(test.args(append([]string{"-dryRun"}, tt.args...)...))
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Equal(t, tt.expectStdout, test.stdout.String())
+			spiretest.AssertProtoEqual(t, tt.expectAgentsReq, test.agentServer.gotReq)
+		})
+	}
+}
+
+func TestPruneErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		args        []string
+		entryErr    error
+		agentErr    error
+		bundleErr   error
+		expectError string
+	}{
+		{
+			name:        "missing dryRun",
+			expectError: "Error: only -dryRun is supported; the server prunes the datastore itself\n",
+		},
+		{
+			name:        "negative attestedNodesExpiredFor",
+			args:        []string{"-dryRun", "-attestedNodesExpiredFor", "-1h"},
+			expectError: "Error: -attestedNodesExpiredFor cannot be negative\n",
+		},
+		{
+			name:        "entry server error",
+			args:        []string{"-dryRun"},
+			entryErr:    status.Error(codes.Internal, "internal server error"),
+			expectError: "Error: rpc error: code = Internal desc = internal server error\n",
+		},
+		{
+			name:        "agent server error",
+			args:        []string{"-dryRun"},
+			agentErr:    status.Error(codes.Internal, "internal server error"),
+			expectError: "Error: rpc error: code = Internal desc = internal server error\n",
+		},
+		{
+			name:        "bundle server error",
+			args:        []string{"-dryRun"},
+			bundleErr:   status.Error(codes.Internal, "internal server error"),
+			expectError: "Error: rpc error: code = Internal desc = internal server error\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t)
+			test.entryServer.err = tt.entryErr
+			test.agentServer.err = tt.agentErr
+			test.bundleServer.err = tt.bundleErr
+
+			require.Equal(t, 1, test.client.Run(test.args(tt.args...)))
+			require.Equal(t, tt.expectError, test.stderr.String())
+		})
+	}
+}
+
+type pruneTest struct {
+	stdout *bytes.Buffer
+	stderr *bytes.Buffer
+
+	addr         string
+	entryServer  *fakeEntryExtensionServer
+	agentServer  *fakeAgentExtensionServer
+	bundleServer *fakeBundleExtensionServer
+
+	client cli.Command
+}
+
+func (t *pruneTest) args(extra ...string) []string {
+	return append([]string{clitest.AddrArg, t.addr}, extra...)
+}
+
+func setupTest(t *testing.T) *pruneTest {
+	entryServer := &fakeEntryExtensionServer{}
+	agentServer := &fakeAgentExtensionServer{}
+	bundleServer := &fakeBundleExtensionServer{}
+
+	addr := spiretest.StartGRPCServer(t, func(s *grpc.Server) {
+		extensionv1.RegisterEntryExtensionServer(s, entryServer)
+		extensionv1.RegisterAgentExtensionServer(s, agentServer)
+		extensionv1.RegisterBundleExtensionServer(s, bundleServer)
+	})
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+
+	client := newPruneCommand(&commoncli.Env{
+		Stdin:  new(bytes.Buffer),
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+
+	return &pruneTest{
+		addr:         clitest.GetAddr(addr),
+		stdout:       stdout,
+		stderr:       stderr,
+		entryServer:  entryServer,
+		agentServer:  agentServer,
+		bundleServer: bundleServer,
+		client:       client,
+	}
+}
+
+type fakeEntryExtensionServer struct {
+	extensionv1.UnimplementedEntryExtensionServer
+
+	err error
+}
+
+func (f *fakeEntryExtensionServer) ListEntriesToPrune(context.Context, *extensionv1.ListEntriesToPruneRequest) (*extensionv1.ListEntriesToPruneResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &extensionv1.ListEntriesToPruneResponse{
+		EntryIds: []string{"entry-1"},
+	}, nil
+}
+
+type fakeAgentExtensionServer struct {
+	extensionv1.UnimplementedAgentExtensionServer
+
+	gotReq *extensionv1.ListAgentsToPruneRequest
+	resp   *extensionv1.ListAgentsToPruneResponse
+	err    error
+}
+
+func (f *fakeAgentExtensionServer) ListAgentsToPrune(_ context.Context, req *extensionv1.ListAgentsToPruneRequest) (*extensionv1.ListAgentsToPruneResponse, error) {
+	f.gotReq = req
+	if f.err != nil {
+		return nil, f.err
+	}
+	if f.resp == nil {
+		return &extensionv1.ListAgentsToPruneResponse{}, nil
+	}
+	return f.resp, nil
+}
+
+type fakeBundleExtensionServer struct {
+	extensionv1.UnimplementedBundleExtensionServer
+
+	err error
+}
+
+func (f *fakeBundleExtensionServer) ListBundleAuthoritiesToPrune(context.Context, *extensionv1.ListBundleAuthoritiesToPruneRequest) (*extensionv1.ListBundleAuthoritiesToPruneResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &extensionv1.ListBundleAuthoritiesToPruneResponse{
+		TrustDomain:      "example.org",
+		X509AuthorityIds: []string{"x509-authority-1"},
+		JwtAuthorityIds:  []string{"jwt-authority-1"},
+	}, nil
+}
@@ -50,7 +50,9 @@ import (
 const (
 	commandName = "run"
 
-	defaultConfigPath = "conf/server/server.conf"
+	// DefaultConfigPath is the server config file read when -config is not
+	// set.
+	DefaultConfigPath = "conf/server/server.conf"
 	defaultLogLevel   = "INFO"
 )
 
@@ -112,6 +114,17 @@ type experimentalConfig struct {
 	PruneEventsOlderThan  string                      `hcl:"prune_events_older_than"`
 	SQLTransactionTimeout string                      `hcl:"sql_transaction_timeout"`
 	RequirePQKEM          bool                        `hcl:"require_pq_kem"`
+	DisableBundleCache    bool                        `hcl:"disable_bundle_cache"`
+	BundleCacheMaxTTL     string                      `hcl:"bundle_cache_max_ttl"`
+
+	EntryIssuanceMetrics           bool    `hcl:"entry_issuance_metrics"`
+	EntryIssuanceMetricsSampleRate float64 `hcl:"entry_issuance_metrics_sample_rate"`
+
+	EntryExpiryWarningWindow string `hcl:"entry_expiry_warning_window"`
+	EntryExpiryCheckInterval string `hcl:"entry_expiry_check_interval"`
+
+	PruneAttestedNodesExpiredFor string `hcl:"prune_attested_nodes_expired_for"`
+	PruneAttestedNodesInterval   string `hcl:"prune_attested_nodes_interval"`
 
 	Flags fflag.RawConfig `hcl:"feature_flags"`
 
@@ -128,9 +141,11 @@ type caSubjectConfig struct {
 }
 
 type federationConfig struct {
-	BundleEndpoint     *bundleEndpointConfig          `hcl:"bundle_endpoint"`
-	FederatesWith      map[string]federatesWithConfig `hcl:"federates_with"`
-	UnusedKeyPositions map[string][]token.Pos         `hcl:",unusedKeyPositions"`
+	BundleEndpoint       *bundleEndpointConfig          `hcl:"bundle_endpoint"`
+	FederatesWith        map[string]federatesWithConfig `hcl:"federates_with"`
+	PollFailureThreshold int                            `hcl:"poll_failure_threshold"`
+	PollMaxBackoff       string                         `hcl:"poll_max_backoff"`
+	UnusedKeyPositions   map[string][]token.Pos         `hcl:",unusedKeyPositions"`
 }
 
 type bundleEndpointConfig struct {
@@ -298,7 +313,7 @@ func ParseFile(path string, expandEnv bool) (*Config, error) {
 	c := &Config{}
 
 	if path == "" {
-		path = defaultConfigPath
+		path = DefaultConfigPath
 	}
 
 	// Return a friendly error if the file is missing
@@ -505,6 +520,22 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 			federatesWith[td] = *trustDomainConfig
 		}
 		sc.Federation.FederatesWith = federatesWith
+
+		if c.Server.Federation.PollFailureThreshold < 0 {
+			return nil, fmt.Errorf("poll_failure_threshold must be non-negative, got %d", c.Server.Federation.PollFailureThreshold)
+		}
+		sc.Federation.PollFailureThreshold = c.Server.Federation.PollFailureThreshold
+
+		if c.Server.Federation.PollMaxBackoff != "" {
+			pollMaxBackoff, err := time.ParseDuration(c.Server.Federation.PollMaxBackoff)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse poll_max_backoff %q: %w", c.Server.Federation.PollMaxBackoff, err)
+			}
+			if pollMaxBackoff < bundleutil.MinimumRefreshHint {
+				return nil, fmt.Errorf("poll_max_backoff must be at least %s, got %s", bundleutil.MinimumRefreshHint, pollMaxBackoff)
+			}
+			sc.Federation.PollMaxBackoff = pollMaxBackoff
+		}
 	}
 
 	sc.ProfilingEnabled = c.Server.ProfilingEnabled
@@ -714,6 +745,15 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 		sc.SQLTransactionTimeout = interval
 	}
 
+	sc.DisableBundleCache = c.Server.Experimental.DisableBundleCache
+	if c.Server.Experimental.BundleCacheMaxTTL != "" {
+		ttl, err := time.ParseDuration(c.Server.Experimental.BundleCacheMaxTTL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse bundle cache max TTL: %w", err)
+		}
+		sc.BundleCacheMaxTTL = ttl
+	}
+
 	if c.Server.Experimental.EventsBasedCache {
 		sc.Log.Info("Using events based cache")
 	}
@@ -721,6 +761,44 @@ func NewServerConfig(c *Config, logOptions []log.Option, allowUnknownConfig bool
 	sc.EventsBasedCache = c.Server.Experimental.EventsBasedCache
 	sc.AuthOpaPolicyEngineConfig = c.Server.Experimental.AuthOpaPolicyEngine
 
+	if rate := c.Server.Experimental.EntryIssuanceMetricsSampleRate; rate < 0 || rate > 1 {
+		return nil, fmt.Errorf("entry issuance metrics sample rate must be between 0 and 1, got %v", rate)
+	}
+	sc.EntryIssuanceMetrics = c.Server.Experimental.EntryIssuanceMetrics
+	sc.EntryIssuanceMetricsSampleRate = c.Server.Experimental.EntryIssuanceMetricsSampleRate
+
+	if c.Server.Experimental.EntryExpiryWarningWindow != "" {
+		window, err := time.ParseDuration(c.Server.Experimental.EntryExpiryWarningWindow)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse entry expiry warning window: %w", err)
+		}
+		sc.EntryExpiryWarningWindow = window
+	}
+
+	if c.Server.Experimental.EntryExpiryCheckInterval != "" {
+		interval, err := time.ParseDuration(c.Server.Experimental.EntryExpiryCheckInterval)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse entry expiry check interval: %w", err)
+		}
+		sc.EntryExpiryCheckInterval = interval
+	}
+
+	if c.Server.Experimental.PruneAttestedNodesExpiredFor != "" {
+		expiredFor, err := time.ParseDuration(c.Server.Experimental.PruneAttestedNodesExpiredFor)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse prune attested nodes expired for: %w", err)
+		}
+		sc.PruneAttestedNodesExpiredFor = expiredFor
+	}
+
+	if c.Server.Experimental.PruneAttestedNodesInterval != "" {
+		interval, err := time.ParseDuration(c.Server.Experimental.PruneAttestedNodesInterval)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse prune attested nodes interval: %w", err)
+		}
+		sc.PruneAttestedNodesInterval = interval
+	}
+
 	for _, f := range c.Server.Experimental.Flags {
 		sc.Log.Warnf("Developer feature flag %q has been enabled", f)
 	}
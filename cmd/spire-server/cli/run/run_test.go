@@ -668,6 +668,55 @@ func TestNewServerConfig(t *testing.T) {
 				require.Equal(t, 10*time.Minute, c.Federation.BundleEndpoint.RefreshHint)
 			},
 		},
+		{
+			msg: "poll backoff settings are parsed and configured correctly",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					PollFailureThreshold: 5,
+					PollMaxBackoff:       "30m",
+				}
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 5, c.Federation.PollFailureThreshold)
+				require.Equal(t, 30*time.Minute, c.Federation.PollMaxBackoff)
+			},
+		},
+		{
+			msg: "negative poll_failure_threshold returns an error",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					PollFailureThreshold: -1,
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "invalid poll_max_backoff returns an error",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					PollMaxBackoff: "forever",
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "poll_max_backoff below the minimum refresh hint returns an error",
+			input: func(c *Config) {
+				c.Server.Federation = &federationConfig{
+					PollMaxBackoff: "30s",
+				}
+			},
+			expectError: true,
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "bundle endpoint has acme",
 			input: func(c *Config) {
@@ -1144,6 +1193,119 @@ func TestNewServerConfig(t *testing.T) {
 				require.Nil(t, c)
 			},
 		},
+		{
+			msg:   "bundle cache is enabled by default",
+			input: func(c *Config) {},
+			test: func(t *testing.T, c *server.Config) {
+				require.False(t, c.DisableBundleCache)
+				require.Zero(t, c.BundleCacheMaxTTL)
+			},
+		},
+		{
+			msg: "bundle cache options are correctly parsed",
+			input: func(c *Config) {
+				c.Server.Experimental.DisableBundleCache = true
+				c.Server.Experimental.BundleCacheMaxTTL = "30s"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.DisableBundleCache)
+				require.Equal(t, 30*time.Second, c.BundleCacheMaxTTL)
+			},
+		},
+		{
+			msg:         "invalid bundle_cache_max_ttl returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.BundleCacheMaxTTL = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "entry issuance metrics are correctly parsed",
+			input: func(c *Config) {
+				c.Server.Experimental.EntryIssuanceMetrics = true
+				c.Server.Experimental.EntryIssuanceMetricsSampleRate = 0.25
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.True(t, c.EntryIssuanceMetrics)
+				require.Equal(t, 0.25, c.EntryIssuanceMetricsSampleRate)
+			},
+		},
+		{
+			msg: "entry expiry warnings are correctly parsed",
+			input: func(c *Config) {
+				c.Server.Experimental.EntryExpiryWarningWindow = "72h"
+				c.Server.Experimental.EntryExpiryCheckInterval = "30m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 72*time.Hour, c.EntryExpiryWarningWindow)
+				require.Equal(t, 30*time.Minute, c.EntryExpiryCheckInterval)
+			},
+		},
+		{
+			msg:         "invalid entry_expiry_warning_window returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.EntryExpiryWarningWindow = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "invalid entry_expiry_check_interval returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.EntryExpiryCheckInterval = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg: "attested node pruning is correctly parsed",
+			input: func(c *Config) {
+				c.Server.Experimental.PruneAttestedNodesExpiredFor = "168h"
+				c.Server.Experimental.PruneAttestedNodesInterval = "30m"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Equal(t, 168*time.Hour, c.PruneAttestedNodesExpiredFor)
+				require.Equal(t, 30*time.Minute, c.PruneAttestedNodesInterval)
+			},
+		},
+		{
+			msg:         "invalid prune_attested_nodes_expired_for returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.PruneAttestedNodesExpiredFor = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "invalid prune_attested_nodes_interval returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.PruneAttestedNodesInterval = "b"
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
+		{
+			msg:         "invalid entry_issuance_metrics_sample_rate returns an error",
+			expectError: true,
+			input: func(c *Config) {
+				c.Server.Experimental.EntryIssuanceMetrics = true
+				c.Server.Experimental.EntryIssuanceMetricsSampleRate = 1.5
+			},
+			test: func(t *testing.T, c *server.Config) {
+				require.Nil(t, c)
+			},
+		},
 		{
 			msg: "audit_log_enabled is enabled",
 			input: func(c *Config) {
@@ -2,8 +2,10 @@ package token
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"strconv"
 
 	"github.com/mitchellh/cli"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -13,6 +15,8 @@ import (
 	commoncli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/cliprinter"
 	"github.com/spiffe/spire/pkg/common/util"
+	"github.com/spiffe/spire/pkg/server/api"
+	"google.golang.org/grpc/metadata"
 )
 
 func NewGenerateCommand() cli.Command {
@@ -28,7 +32,11 @@ type generateCommand struct {
 	SpiffeID string
 
 	// Token TTL in seconds
-	TTL     int
+	TTL int
+
+	// Number of times the token can be used
+	Uses int
+
 	env     *commoncli.Env
 	printer cliprinter.Printer
 }
@@ -50,6 +58,13 @@ func (g *generateCommand) Run(ctx context.Context, _ *commoncli.Env, serverClien
 	if err != nil {
 		return fmt.Errorf("invalid value for TTL: %w", err)
 	}
+	if g.Uses < 1 {
+		return errors.New("uses must be at least 1")
+	}
+
+	if g.Uses > 1 {
+		ctx = metadata.AppendToOutgoingContext(ctx, api.JoinTokenUsesMetadataKey, strconv.Itoa(g.Uses))
+	}
 
 	c := serverClient.NewAgentClient()
 	resp, err := c.CreateJoinToken(ctx, &agentv1.CreateJoinTokenRequest{
@@ -80,6 +95,7 @@ func getID(spiffeID string) (*prototypes.SPIFFEID, error) {
 func (g *generateCommand) AppendFlags(fs *flag.FlagSet) {
 	fs.IntVar(&g.TTL, "ttl", 600, "Token TTL in seconds")
 	fs.StringVar(&g.SpiffeID, "spiffeID", "", "Additional SPIFFE ID to assign the token owner (optional)")
+	fs.IntVar(&g.Uses, "uses", 1, "Number of times the token can be used to attest an agent")
 	cliprinter.AppendFlagWithCustomPretty(&g.printer, fs, g.env, g.prettyPrintGenerate)
 }
 
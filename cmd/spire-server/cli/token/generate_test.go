@@ -10,11 +10,14 @@ import (
 	agentv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/agent/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
+	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/test/clitest"
 	"github.com/spiffe/spire/test/spiretest"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
@@ -104,6 +107,62 @@ func TestCreateToken(t *testing.T) {
 	}
 }
 
+func TestCreateTokenWithUses(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		args       []string
+		expectUses []string
+	}{
+		{
+			name: "single use",
+			args: []string{"-uses", "1"},
+		},
+		{
+			name:       "multiple uses",
+			args:       []string{"-uses", "3"},
+			expectUses: []string{"3"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupTest(t)
+			test.server.token = "token"
+			test.server.expectReq = &agentv1.CreateJoinTokenRequest{
+				AgentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+				Ttl:     600,
+			}
+			test.server.expectUses = tt.expectUses
+
+			rc := test.client.Run(test.args(append([]string{"-spiffeID", "spiffe://example.org/agent"}, tt.args...)...))
+			require.Equal(t, 0, rc, test.stderr.String())
+			require.Equal(t, "Token: token\n", test.stdout.String())
+		})
+	}
+}
+
+func TestCreateTokenWithUsesErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		args   []string
+		expErr string
+	}{
+		{
+			name:   "no uses",
+			args:   []string{"-uses", "0"},
+			expErr: "Error: uses must be at least 1\n",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// The server is not called, which would fail the request
+			// assertion
+			test := setupTest(t)
+			rc := test.client.Run(test.args(tt.args...))
+			require.Equal(t, 1, rc)
+			require.Contains(t, test.stderr.String(), tt.expErr)
+			require.Empty(t, test.stdout.String())
+		})
+	}
+}
+
 type tokenTest struct {
 	stdin  *bytes.Buffer
 	stdout *bytes.Buffer
@@ -149,17 +208,20 @@ func setupTest(t *testing.T) *tokenTest {
 type fakeAgentServer struct {
 	agentv1.AgentServer
 
-	t         testing.TB
-	expectReq *agentv1.CreateJoinTokenRequest
-	err       error
-	token     string
+	t          testing.TB
+	expectReq  *agentv1.CreateJoinTokenRequest
+	err        error
+	token      string
+	expectUses []string
 }
 
-func (f *fakeAgentServer) CreateJoinToken(_ context.Context, req *agentv1.CreateJoinTokenRequest) (*types.JoinToken, error) {
+func (f *fakeAgentServer) CreateJoinToken(ctx context.Context, req *agentv1.CreateJoinTokenRequest) (*types.JoinToken, error) {
 	if f.err != nil {
 		return nil, f.err
 	}
 	spiretest.AssertProtoEqual(f.t, f.expectReq, req)
+	md, _ := metadata.FromIncomingContext(ctx)
+	assert.Equal(f.t, f.expectUses, md.Get(api.JoinTokenUsesMetadataKey))
 
 	return &types.JoinToken{
 		Value: f.token,
@@ -20,6 +20,7 @@ import (
 	common_cli "github.com/spiffe/spire/pkg/common/cli"
 	"github.com/spiffe/spire/pkg/common/jwtutil"
 	"github.com/spiffe/spire/pkg/common/pemutil"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -43,11 +44,15 @@ func NewGRPCClient(addr string) (*grpc.ClientConn, error) {
 type ServerClient interface {
 	Release()
 	NewAgentClient() agentv1.AgentClient
+	NewAgentExtensionClient() extensionv1.AgentExtensionClient
 	NewBundleClient() bundlev1.BundleClient
+	NewBundleExtensionClient() extensionv1.BundleExtensionClient
 	NewEntryClient() entryv1.EntryClient
+	NewEntryExtensionClient() extensionv1.EntryExtensionClient
 	NewLoggerClient() loggerv1.LoggerClient
 	NewSVIDClient() svidv1.SVIDClient
 	NewTrustDomainClient() trustdomainv1.TrustDomainClient
+	NewTrustDomainExtensionClient() extensionv1.TrustDomainExtensionClient
 	NewLocalAuthorityClient() localauthorityv1.LocalAuthorityClient
 	NewHealthClient() grpc_health_v1.HealthClient
 }
@@ -72,14 +77,26 @@ func (c *serverClient) NewAgentClient() agentv1.AgentClient {
 	return agentv1.NewAgentClient(c.conn)
 }
 
+func (c *serverClient) NewAgentExtensionClient() extensionv1.AgentExtensionClient {
+	return extensionv1.NewAgentExtensionClient(c.conn)
+}
+
 func (c *serverClient) NewBundleClient() bundlev1.BundleClient {
 	return bundlev1.NewBundleClient(c.conn)
 }
 
+func (c *serverClient) NewBundleExtensionClient() extensionv1.BundleExtensionClient {
+	return extensionv1.NewBundleExtensionClient(c.conn)
+}
+
 func (c *serverClient) NewEntryClient() entryv1.EntryClient {
 	return entryv1.NewEntryClient(c.conn)
 }
 
+func (c *serverClient) NewEntryExtensionClient() extensionv1.EntryExtensionClient {
+	return extensionv1.NewEntryExtensionClient(c.conn)
+}
+
 func (c *serverClient) NewLoggerClient() loggerv1.LoggerClient {
 	return loggerv1.NewLoggerClient(c.conn)
 }
@@ -92,6 +109,10 @@ func (c *serverClient) NewTrustDomainClient() trustdomainv1.TrustDomainClient {
 	return trustdomainv1.NewTrustDomainClient(c.conn)
 }
 
+func (c *serverClient) NewTrustDomainExtensionClient() extensionv1.TrustDomainExtensionClient {
+	return extensionv1.NewTrustDomainExtensionClient(c.conn)
+}
+
 func (c *serverClient) NewHealthClient() grpc_health_v1.HealthClient {
 	return grpc_health_v1.NewHealthClient(c.conn)
 }
@@ -93,6 +93,7 @@ require (
 	k8s.io/kube-aggregator v0.32.2
 	k8s.io/mount-utils v0.32.2
 	sigs.k8s.io/controller-runtime v0.20.2
+	sigs.k8s.io/yaml v1.4.0
 )
 
 require (
@@ -304,5 +305,4 @@ require (
 	k8s.io/utils v0.0.0-20241104100929-3ea5e8cea738 // indirect
 	sigs.k8s.io/json v0.0.0-20241010143419-9aa6b5e7a4b3 // indirect
 	sigs.k8s.io/structured-merge-diff/v4 v4.4.2 // indirect
-	sigs.k8s.io/yaml v1.4.0 // indirect
 )
@@ -46,6 +46,10 @@ const (
 
 type Agent struct {
 	c *Config
+
+	// mgr is set once the manager is initialized, before the health checks
+	// are started, so the agent SVID expiry can be reported.
+	mgr manager.Manager
 }
 
 // Run the agent
@@ -163,6 +167,7 @@ func (a *Agent) Run(ctx context.Context) error {
 
 	endpoints := a.newEndpoints(metrics, manager, workloadAttestor)
 
+	a.mgr = manager
 	if err := healthChecker.AddCheck("agent", a); err != nil {
 		return fmt.Errorf("failed adding healthcheck: %w", err)
 	}
@@ -400,6 +405,7 @@ func (a *Agent) CheckHealth() health.State {
 		Live:  err == nil,
 		ReadyDetails: agentHealthDetails{
 			WorkloadAPIErr: errString(err),
+			SVIDExpiresAt:  a.svidExpiresAt(),
 		},
 		LiveDetails: agentHealthDetails{
 			WorkloadAPIErr: errString(err),
@@ -422,8 +428,23 @@ func (a *Agent) checkWorkloadAPI() error {
 	return nil
 }
 
+// svidExpiresAt returns the expiration time of the current agent SVID, or nil
+// if the manager has not been initialized yet.
+func (a *Agent) svidExpiresAt() *time.Time {
+	if a.mgr == nil {
+		return nil
+	}
+	state := a.mgr.GetCurrentCredentials()
+	if len(state.SVID) == 0 {
+		return nil
+	}
+	expiresAt := state.SVID[0].NotAfter.UTC()
+	return &expiresAt
+}
+
 type agentHealthDetails struct {
-	WorkloadAPIErr string `json:"make_new_x509_err,omitempty"`
+	WorkloadAPIErr string     `json:"make_new_x509_err,omitempty"`
+	SVIDExpiresAt  *time.Time `json:"svid_expires_at,omitempty"`
 }
 
 func errString(err error) string {
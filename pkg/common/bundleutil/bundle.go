@@ -1,8 +1,11 @@
 package bundleutil
 
 import (
+	"bytes"
 	"crypto"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -149,32 +152,59 @@ func JWTSigningKeysFromBundleProto(b *common.Bundle) (map[string]crypto.PublicKe
 	return out, nil
 }
 
-func MergeBundles(a, b *common.Bundle) (*common.Bundle, bool) {
+// MergeBundles returns bundle a with the root CAs and JWT signing keys of
+// bundle b that it is missing, and whether any were added. Root CAs are
+// identified by their DER bytes and JWT signing keys by their key ID, so
+// entries that only differ in their expiration or taint are not duplicated.
+// An error is returned if b has a JWT signing key with the key ID of a
+// different key in a.
+func MergeBundles(a, b *common.Bundle) (*common.Bundle, bool, error) {
 	c := cloneBundle(a)
 
 	rootCAs := make(map[string]bool)
 	for _, rootCA := range a.RootCas {
-		rootCAs[rootCA.String()] = true
+		rootCAs[string(rootCA.DerBytes)] = true
 	}
-	jwtSigningKeys := make(map[string]bool)
+	jwtSigningKeys := make(map[string][]byte)
 	for _, jwtSigningKey := range a.JwtSigningKeys {
-		jwtSigningKeys[jwtSigningKey.String()] = true
+		jwtSigningKeys[jwtSigningKey.Kid] = jwtSigningKey.PkixBytes
 	}
 
 	var changed bool
 	for _, rootCA := range b.RootCas {
-		if !rootCAs[rootCA.String()] {
+		if !rootCAs[string(rootCA.DerBytes)] {
+			rootCAs[string(rootCA.DerBytes)] = true
 			c.RootCas = append(c.RootCas, rootCA)
 			changed = true
 		}
 	}
 	for _, jwtSigningKey := range b.JwtSigningKeys {
-		if !jwtSigningKeys[jwtSigningKey.String()] {
+		pkixBytes, ok := jwtSigningKeys[jwtSigningKey.Kid]
+		switch {
+		case !ok:
+			jwtSigningKeys[jwtSigningKey.Kid] = jwtSigningKey.PkixBytes
 			c.JwtSigningKeys = append(c.JwtSigningKeys, jwtSigningKey)
 			changed = true
+		case !bytes.Equal(pkixBytes, jwtSigningKey.PkixBytes):
+			return nil, false, fmt.Errorf("JWT signing key %q conflicts with a different key with the same key ID", jwtSigningKey.Kid)
 		}
 	}
-	return c, changed
+	return c, changed, nil
+}
+
+// ContentHash returns the hex encoded SHA-256 hash of the marshaled bundle.
+// The last refresh time is not part of the bundle contents and is ignored.
+func ContentHash(bundle *common.Bundle) (string, error) {
+	if bundle.LastRefreshedAt != 0 {
+		bundle = cloneBundle(bundle)
+		bundle.LastRefreshedAt = 0
+	}
+	data, err := proto.Marshal(bundle)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal bundle: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
 }
 
 // PruneBundle removes the bundle RootCAs and JWT keys that expired before a given time
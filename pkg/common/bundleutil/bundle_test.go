@@ -102,6 +102,92 @@ func TestPruneBundle(t *testing.T) {
 	}
 }
 
+func TestContentHash(t *testing.T) {
+	bundle := &common.Bundle{
+		TrustDomainId: "spiffe://example.org",
+		RootCas:       []*common.Certificate{{DerBytes: []byte("1")}},
+	}
+
+	hash, err := ContentHash(bundle)
+	require.NoError(t, err)
+	require.Len(t, hash, 64)
+
+	// The last refresh time is not part of the contents
+	refreshed := &common.Bundle{
+		TrustDomainId:   "spiffe://example.org",
+		RootCas:         []*common.Certificate{{DerBytes: []byte("1")}},
+		LastRefreshedAt: 1,
+	}
+	refreshedHash, err := ContentHash(refreshed)
+	require.NoError(t, err)
+	require.Equal(t, hash, refreshedHash)
+	require.Equal(t, int64(1), refreshed.LastRefreshedAt, "bundle should not be modified")
+
+	changed := &common.Bundle{
+		TrustDomainId: "spiffe://example.org",
+		RootCas:       []*common.Certificate{{DerBytes: []byte("2")}},
+	}
+	changedHash, err := ContentHash(changed)
+	require.NoError(t, err)
+	require.NotEqual(t, hash, changedHash)
+}
+
+func TestMergeBundles(t *testing.T) {
+	bundle := &common.Bundle{
+		TrustDomainId:  "spiffe://example.org",
+		RootCas:        []*common.Certificate{{DerBytes: []byte("1"), TaintedKey: true}},
+		JwtSigningKeys: []*common.PublicKey{{Kid: "kid-1", PkixBytes: []byte("key-1"), TaintedKey: true}},
+	}
+
+	t.Run("missing entries are appended once", func(t *testing.T) {
+		merged, changed, err := MergeBundles(bundle, &common.Bundle{
+			RootCas: []*common.Certificate{
+				{DerBytes: []byte("1")},
+				{DerBytes: []byte("2")},
+				{DerBytes: []byte("2")},
+			},
+			JwtSigningKeys: []*common.PublicKey{
+				{Kid: "kid-1", PkixBytes: []byte("key-1")},
+				{Kid: "kid-2", PkixBytes: []byte("key-2")},
+				{Kid: "kid-2", PkixBytes: []byte("key-2")},
+			},
+		})
+		require.NoError(t, err)
+		require.True(t, changed)
+		spiretest.AssertProtoEqual(t, &common.Bundle{
+			TrustDomainId: "spiffe://example.org",
+			RootCas: []*common.Certificate{
+				{DerBytes: []byte("1"), TaintedKey: true},
+				{DerBytes: []byte("2")},
+			},
+			JwtSigningKeys: []*common.PublicKey{
+				{Kid: "kid-1", PkixBytes: []byte("key-1"), TaintedKey: true},
+				{Kid: "kid-2", PkixBytes: []byte("key-2")},
+			},
+		}, merged)
+		require.Len(t, bundle.RootCas, 1, "bundle should not be modified")
+	})
+
+	t.Run("nothing missing", func(t *testing.T) {
+		merged, changed, err := MergeBundles(bundle, &common.Bundle{
+			RootCas:        []*common.Certificate{{DerBytes: []byte("1")}},
+			JwtSigningKeys: []*common.PublicKey{{Kid: "kid-1", PkixBytes: []byte("key-1"), NotAfter: 1}},
+		})
+		require.NoError(t, err)
+		require.False(t, changed)
+		spiretest.AssertProtoEqual(t, bundle, merged)
+	})
+
+	t.Run("conflicting JWT signing key", func(t *testing.T) {
+		merged, changed, err := MergeBundles(bundle, &common.Bundle{
+			JwtSigningKeys: []*common.PublicKey{{Kid: "kid-1", PkixBytes: []byte("other")}},
+		})
+		require.EqualError(t, err, `JWT signing key "kid-1" conflicts with a different key with the same key ID`)
+		require.False(t, changed)
+		require.Nil(t, merged)
+	})
+}
+
 func TestCommonBundleFromProto(t *testing.T) {
 	td := spiffeid.RequireTrustDomainFromString("example.org")
 	ca := testca.New(t, td)
@@ -56,27 +56,39 @@ func Load(rc RawConfig) error {
 		return errors.New("feature flags have already been loaded")
 	}
 
+	if err := validate(rc); err != nil {
+		return err
+	}
+
+	for _, rawFlag := range rc {
+		singleton.flags[Flag(rawFlag)] = true
+	}
+
+	singleton.loaded = true
+	return nil
+}
+
+// Validate returns the error Load would return for the configuration input
+// because of unrecognized flags, without loading it.
+func Validate(rc RawConfig) error {
+	singleton.mtx.RLock()
+	defer singleton.mtx.RUnlock()
+
+	return validate(rc)
+}
+
+func validate(rc RawConfig) error {
 	badFlags := []string{}
-	goodFlags := []Flag{}
 	for _, rawFlag := range rc {
 		if _, ok := singleton.flags[Flag(rawFlag)]; !ok {
 			badFlags = append(badFlags, rawFlag)
-			continue
 		}
-
-		goodFlags = append(goodFlags, Flag(rawFlag))
 	}
 
 	if len(badFlags) > 0 {
 		sort.Strings(badFlags)
 		return fmt.Errorf("unknown feature flag(s): %v", badFlags)
 	}
-
-	for _, f := range goodFlags {
-		singleton.flags[f] = true
-	}
-
-	singleton.loaded = true
 	return nil
 }
 
@@ -97,6 +97,26 @@ func TestLoad(t *testing.T) {
 	reset()
 }
 
+func TestValidate(t *testing.T) {
+	reset()
+
+	if err := Validate([]string{"i_am_a_test_flag"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	err := Validate([]string{"i_am_a_test_flag", "non_existent_flag"})
+	assert.EqualError(t, err, "unknown feature flag(s): [non_existent_flag]")
+
+	if IsSet(FlagTestFlag) {
+		t.Fatal("expected test flag to be unset after validating but it was set")
+	}
+
+	// Validating does not count as loading
+	assert.NoError(t, Load([]string{"i_am_a_test_flag"}))
+
+	reset()
+}
+
 func TestUnload(t *testing.T) {
 	type want struct {
 		errStr        string
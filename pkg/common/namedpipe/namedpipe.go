@@ -26,13 +26,27 @@ func (p *Addr) String() string {
 	return fmt.Sprintf(`\\%s\%s`, p.serverName, filepath.Join("pipe", p.pipeName))
 }
 
+// localPipePrefix is the prefix of the path of named pipes in the local computer
+const localPipePrefix = `\\.\pipe\`
+
 // AddrFromName returns a named pipe in the local
-// computer with the specified pipe name
+// computer with the specified pipe name. The name can
+// be given either bare or as the full pipe path.
 func AddrFromName(pipeName string) net.Addr {
 	return &Addr{
 		serverName: ".",
-		pipeName:   pipeName,
+		pipeName:   NormalizeName(pipeName),
+	}
+}
+
+// NormalizeName returns the pipe name with the local pipe
+// path prefix (\\.\pipe\) removed, if present, so that both
+// `\\.\pipe\name` and `name` refer to the same pipe.
+func NormalizeName(pipeName string) string {
+	if len(pipeName) >= len(localPipePrefix) && strings.EqualFold(pipeName[:len(localPipePrefix)], localPipePrefix) {
+		return pipeName[len(localPipePrefix):]
 	}
+	return pipeName
 }
 
 func GetPipeName(addr string) string {
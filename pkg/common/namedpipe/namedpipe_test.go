@@ -15,6 +15,50 @@ func TestGetNamedPipeAddr(t *testing.T) {
 	require.Equal(t, "\\\\.\\pipe\\my-pipe", addr.String())
 }
 
+func TestGetNamedPipeAddrFromPath(t *testing.T) {
+	addr := namedpipe.AddrFromName("\\\\.\\pipe\\my-pipe")
+	require.Equal(t, "\\\\.\\pipe\\my-pipe", addr.String())
+	require.Equal(t, "my-pipe", addr.(*namedpipe.Addr).PipeName())
+}
+
+func TestNormalizeName(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		pipeName string
+		expected string
+	}{
+		{
+			name:     "bare name",
+			pipeName: "my-pipe",
+			expected: "my-pipe",
+		},
+		{
+			name:     "bare name with leading separator",
+			pipeName: "\\spire-agent\\public\\api",
+			expected: "\\spire-agent\\public\\api",
+		},
+		{
+			name:     "full path",
+			pipeName: "\\\\.\\pipe\\spire-agent\\public\\api",
+			expected: "spire-agent\\public\\api",
+		},
+		{
+			name:     "full path is case insensitive",
+			pipeName: "\\\\.\\PIPE\\my-pipe",
+			expected: "my-pipe",
+		},
+		{
+			name:     "pipe on another computer is left untouched",
+			pipeName: "\\\\server\\pipe\\my-pipe",
+			expected: "\\\\server\\pipe\\my-pipe",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, namedpipe.NormalizeName(tt.pipeName))
+		})
+	}
+}
+
 func TestGetPipeName(t *testing.T) {
 	addr := namedpipe.GetPipeName("\\\\.\\pipe\\my-pipe")
 	require.Equal(t, "\\my-pipe", addr)
@@ -58,6 +58,7 @@ func TestAllTrueMasks(t *testing.T) {
 		NewCertSerialNumber: true,
 		NewCertNotAfter:     true,
 		CanReattest:         true,
+		LastAttestedAt:      true,
 	}, protoutil.AllTrueCommonAgentMask)
 
 	spiretest.AssertProtoEqual(t, &types.FederationRelationshipMask{
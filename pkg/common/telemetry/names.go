@@ -22,6 +22,22 @@ const (
 	// to add clarity
 	Attest = "attest"
 
+	// BatchCreate functionality related to creating several entities at once;
+	// should be used with other tags to add clarity
+	BatchCreate = "batch_create"
+
+	// BatchSet functionality related to setting some entity of several
+	// others at once; should be used with other tags to add clarity
+	BatchSet = "batch_set"
+
+	// Compact functionality related to compacting some entity(ies), like
+	// events; should be used with other tags to add clarity
+	Compact = "compact"
+
+	// Consume functionality related to using up some entity, like a join
+	// token; should be used with other tags to add clarity
+	Consume = "consume"
+
 	// Create functionality related to creating some entity; should be used with other tags
 	// to add clarity
 	Create = "create"
@@ -34,6 +50,14 @@ const (
 	// to add clarity
 	Delete = "delete"
 
+	// DryRun functionality related to evaluating an operation without applying
+	// it; should be used with other tags to add clarity
+	DryRun = "dry_run"
+
+	// Exists functionality related to checking whether some entity exists; should be used
+	// with other tags to add clarity
+	Exists = "exists"
+
 	// Fetch functionality related to fetching some entity; should be used with other tags
 	// to add clarity
 	Fetch = "fetch"
@@ -73,6 +97,10 @@ const (
 	// with other tags to add clarity
 	List = "list"
 
+	// Ping functionality related to checking some entity, like the datastore,
+	// is reachable; should be used with other tags to add clarity
+	Ping = "ping"
+
 	// Prepare functionality related to preparation of some entity; should be used with other tags
 	// to add clarity
 	Prepare = "prepare"
@@ -105,6 +133,11 @@ const (
 	// (server)
 	SignData = "sign_data"
 
+	// Stream functionality related to streaming entities one at a time,
+	// such as registration entries; should be used with other tags to add
+	// clarity
+	Stream = "stream"
+
 	// StorePrivateKey related to storing a private key in the KeyManager plugin interface
 	// (agent or server)
 	StorePrivateKey = "store_private_key"
@@ -133,6 +166,9 @@ const (
 // Attribute metric tags or labels that are typically an attribute of a
 // larger entity or logic path
 const (
+	// Active tags whether something is active
+	Active = "active"
+
 	// Address tags some network address
 	Address = "address"
 
@@ -151,6 +187,9 @@ const (
 	// Audience tags some audience for a token
 	Audience = "audience"
 
+	// Authorities tags a set of X.509 or JWT authorities
+	Authorities = "authorities"
+
 	// AuthorizedAs indicates who an entity was authorized as
 	AuthorizedAs = "authorized_as"
 
@@ -204,6 +243,9 @@ const (
 	// to add clarity
 	CallerPath = "caller_path"
 
+	// CanReattest tags whether an agent can re-attest
+	CanReattest = "can_reattest"
+
 	// CertFilePath tags a certificate file path used for TLS connections.
 	CertFilePath = "cert_file_path"
 
@@ -230,6 +272,9 @@ const (
 	// ContainerName tags some container name, most likely for use in attestation
 	ContainerName = "container_name"
 
+	// ContentHash tags the hash of some entity's contents
+	ContentHash = "content_hash"
+
 	// Count tags some basic count; should be used with other tags and clear messaging to add clarity
 	Count = "count"
 
@@ -263,6 +308,9 @@ const (
 	// DNS name is a name which is resolvable with DNS
 	DNSName = "dns_name"
 
+	// DNSNames tags some group of DNS names
+	DNSNames = "dns_names"
+
 	// Downstream tags if entry is a downstream
 	Downstream = "downstream"
 
@@ -281,6 +329,12 @@ const (
 	// EndpointSpiffeID tags endpoint SPIFFE ID
 	EndpointSpiffeID = "endpoint_spiffe_id"
 
+	// EntityID tags the ID of a datastore entity
+	EntityID = "entity_id"
+
+	// EntityType tags the type of a datastore entity
+	EntityType = "entity_type"
+
 	// Error tag for some error that occurred. Limited usage, such as logging errors at
 	// non-error level.
 	Error = "error"
@@ -326,6 +380,9 @@ const (
 	// FederatesWith tags a federates with list
 	FederatesWith = "federates_with"
 
+	// FederatesWithAll tags whether something federates with all trust domains
+	FederatesWithAll = "federates_with_all"
+
 	// FederatesWithMatch tags a federates with match filter
 	FederatesWithMatch = "federates_with_match"
 
@@ -347,6 +404,9 @@ const (
 	// ImageID tags the image identifier in the format "repository@sha256:digest"
 	ImageID = "image_id"
 
+	// Issued tags something as issued, like an SVID
+	Issued = "issued"
+
 	// IssuedAt tags an issuance timestamp
 	IssuedAt = "issued_at"
 
@@ -372,6 +432,12 @@ const (
 	// Kid tags some key ID
 	Kid = "kid"
 
+	// LastPoll tags the last time something was polled
+	LastPoll = "last_poll"
+
+	// LastRefreshedAt tags the last time a bundle was refreshed
+	LastRefreshedAt = "last_refreshed_at"
+
 	// LaunchLogLevel log level when service started
 	LaunchLogLevel = "launch_log_level"
 
@@ -381,6 +447,9 @@ const (
 	// Mode tags a bundle deletion mode
 	Mode = "mode"
 
+	// Mutation tags the kind of change made to a datastore entity
+	Mutation = "mutation"
+
 	// NewLogLevel tags a new log level
 	NewLogLevel = "new_log_level"
 
@@ -565,6 +634,9 @@ const (
 	// SVIDType tags some type of SVID (eg. X509, JWT)
 	SVIDType = "svid_type"
 
+	// KeyType tags the type of a key (eg. rsa_2048, ec_p256)
+	KeyType = "key_type"
+
 	// SVIDUpdated tags that for some entity the SVID was updated
 	SVIDUpdated = "svid_updated"
 
@@ -614,6 +686,9 @@ const (
 	// UpstreamAuthorityID tags a signing authority ID
 	UpstreamAuthorityID = "upstream_authority_id"
 
+	// Uses tags the number of times something, such as a join token, can be used
+	Uses = "uses"
+
 	// StoreSvid tags if entry is storable
 	StoreSvid = "store_svid"
 
@@ -646,6 +721,10 @@ const (
 	// AgentSVID tag a node (agent) SVID
 	AgentSVID = "agent_svid"
 
+	// Alias functionality related to an alias of an entity, such as a node;
+	// should be used with other tags to add clarity
+	Alias = "alias"
+
 	// Attestor tags an attestor plugin/type (eg. gcp, aws...)
 	Attestor = "attestor"
 
@@ -653,6 +732,9 @@ const (
 	// to add clarity
 	Bundle = "bundle"
 
+	// BundleEvent is a notice a bundle has been created, modified, or deleted
+	BundleEvent = "bundle_event"
+
 	// BundleManager functionality related to a Bundle manager
 	BundleManager = "bundle_manager"
 
@@ -702,6 +784,30 @@ const (
 	// Deleted tags something as deleted
 	Deleted = "deleted"
 
+	// Idle tags something as idle, like the idle connections of a pool
+	Idle = "idle"
+
+	// InUse tags something as in use, like the busy connections of a pool
+	InUse = "in_use"
+
+	// Open tags something as open, like the established connections of a pool
+	Open = "open"
+
+	// WaitCount tags the number of times something had to be waited for
+	WaitCount = "wait_count"
+
+	// DuplicateSelectors tags the selectors repeated within a registration entry
+	DuplicateSelectors = "duplicate_selectors"
+
+	// Explain tags the plan of a datastore query, without running it
+	Explain = "explain"
+
+	// ParentChain tags the chain of entries a registration entry is delegated from
+	ParentChain = "parent_chain"
+
+	// ExcessDNSNames tags the registration entries with more DNS names than allowed
+	ExcessDNSNames = "excess_dns_names"
+
 	// Endpoints functionality related to agent/server endpoints
 	Endpoints = "endpoints"
 
@@ -711,9 +817,17 @@ const (
 	// Event tag some event that has occurred, for a notifier, watcher, listener, etc.
 	Event = "event"
 
+	// Expiring tags something that is about to expire; should be used with
+	// other tags to add clarity
+	Expiring = "expiring"
+
 	// ExpiringSVIDs tags expiring SVID count/list
 	ExpiringSVIDs = "expiring_svids"
 
+	// OrphanedChildren tags the rows left behind by a deleted entity, like the
+	// selectors and DNS names of a registration entry that no longer exists
+	OrphanedChildren = "orphaned_children"
+
 	// OutdatedSVIDs tags SVID with outdated attributes count/list
 	OutdatedSVIDs = "outdated_svids"
 
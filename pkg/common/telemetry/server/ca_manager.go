@@ -27,6 +27,19 @@ func StartServerCAManagerPrepareX509CACall(m telemetry.Metrics) *telemetry.CallC
 
 // End Call Counters
 
+// Latency (timing between two events)
+
+// StartServerCASignLatency returns Latency metric for
+// Server CA signing an SVID of the given type with a key of the given type.
+func StartServerCASignLatency(m telemetry.Metrics, svidType, keyType string) *telemetry.Latency {
+	latency := telemetry.StartLatencyMetric(m, telemetry.ServerCA, telemetry.Sign)
+	latency.AddLabel(telemetry.SVIDType, svidType)
+	latency.AddLabel(telemetry.KeyType, keyType)
+	return latency
+}
+
+// End Latency
+
 // Gauge (remember previous value set)
 
 // SetX509CARotateGauge set gauge for X509 CA rotation,
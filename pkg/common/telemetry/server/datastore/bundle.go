@@ -37,12 +37,24 @@ func StartFetchBundleCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.Fetch)
 }
 
+// StartFetchBundleContentHashCall return metric
+// for server's datastore, on fetching the content hash of a bundle.
+func StartFetchBundleContentHashCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.ContentHash, telemetry.Fetch)
+}
+
 // StartListBundleCall return metric
 // for server's datastore, on listing bundles.
 func StartListBundleCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.List)
 }
 
+// StartPruneBundleDryRunCall return metric
+// for server's datastore, on listing the authorities pruning a bundle would remove.
+func StartPruneBundleDryRunCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.Prune, telemetry.DryRun)
+}
+
 // StartPruneBundleCall return metric
 // for server's datastore, on pruning a bundle.
 func StartPruneBundleCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -55,6 +67,12 @@ func StartSetBundleCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.Set)
 }
 
+// StartSetBundleRefreshedAtCall return metric
+// for server's datastore, on setting when a bundle was last refreshed.
+func StartSetBundleRefreshedAtCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Bundle, telemetry.LastRefreshedAt, telemetry.Set)
+}
+
 // StartUpdateBundleCall return metric
 // for server's datastore, on updating a bundle.
 func StartUpdateBundleCall(m telemetry.Metrics) *telemetry.CallCounter {
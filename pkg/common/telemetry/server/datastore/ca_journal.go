@@ -22,6 +22,12 @@ func StartPruneCAJournalsCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.CAJournal, telemetry.Prune)
 }
 
+// StartPruneCAJournalAuthoritiesCall return metric for server's datastore, on
+// pruning old authorities from CA journals.
+func StartPruneCAJournalAuthoritiesCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.CAJournal, telemetry.Authorities, telemetry.Prune)
+}
+
 // StartListCAJournalsForTesting return metric
 // for server's datastore, on listing CA journals for testing.
 func StartListCAJournalsForTesting(m telemetry.Metrics) *telemetry.CallCounter {
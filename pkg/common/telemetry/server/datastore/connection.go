@@ -0,0 +1,36 @@
+package datastore
+
+import (
+	"database/sql"
+	"strconv"
+
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// Call Counters (timing and success metrics)
+// Allows adding labels in-code
+
+// StartPingCall return metric
+// for server's datastore, on checking the database is reachable.
+func StartPingCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Ping)
+}
+
+// End Call Counters
+
+// Gauge (remember previous value set)
+
+// SetConnectionStatsGauges emits gauges with the connection pool statistics
+// of the SQL database, labeled with whether the database is the read-only
+// replica.
+func SetConnectionStatsGauges(m telemetry.Metrics, stats sql.DBStats, readOnly bool) {
+	labels := []telemetry.Label{
+		{Name: telemetry.ReadOnly, Value: strconv.FormatBool(readOnly)},
+	}
+	m.SetGaugeWithLabels([]string{telemetry.Datastore, telemetry.Connections, telemetry.Open}, float32(stats.OpenConnections), labels)
+	m.SetGaugeWithLabels([]string{telemetry.Datastore, telemetry.Connections, telemetry.Idle}, float32(stats.Idle), labels)
+	m.SetGaugeWithLabels([]string{telemetry.Datastore, telemetry.Connections, telemetry.InUse}, float32(stats.InUse), labels)
+	m.SetGaugeWithLabels([]string{telemetry.Datastore, telemetry.Connections, telemetry.WaitCount}, float32(stats.WaitCount), labels)
+}
+
+// End Gauge
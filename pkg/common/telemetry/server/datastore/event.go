@@ -16,6 +16,12 @@ func StartPruneRegistrationEntryEventsCall(m telemetry.Metrics) *telemetry.CallC
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntryEvent, telemetry.Prune)
 }
 
+// StartCompactRegistrationEntryEventsCall return metric
+// for server's datastore, on compacting registration entry events.
+func StartCompactRegistrationEntryEventsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntryEvent, telemetry.Compact)
+}
+
 // StartCreateRegistrationEntryEventForTestingCall return metric
 // for server's datastore, on creating a registration entry event.
 func StartCreateRegistrationEntryEventForTestingCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -34,6 +40,12 @@ func StartFetchRegistrationEntryEventCall(m telemetry.Metrics) *telemetry.CallCo
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntryEvent, telemetry.Fetch)
 }
 
+// StartPruneEventsCall return metric
+// for server's datastore, on pruning registration entry, attested node and bundle events.
+func StartPruneEventsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Event, telemetry.Prune)
+}
+
 // StartListAttestedNodeEventsCall return metric
 // for server's datastore, on listing attested node events.
 func StartListAttestedNodeEventsCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -46,6 +58,12 @@ func StartPruneAttestedNodeEventsCall(m telemetry.Metrics) *telemetry.CallCounte
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.NodeEvent, telemetry.Prune)
 }
 
+// StartCompactAttestedNodeEventsCall return metric
+// for server's datastore, on compacting attested node events.
+func StartCompactAttestedNodeEventsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.NodeEvent, telemetry.Compact)
+}
+
 // StartCreateAttestedNodeEventForTestingCall return metric
 // for server's datastore, on creating an attested node event.
 func StartCreateAttestedNodeEventForTestingCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -63,3 +81,9 @@ func StartDeleteAttestedNodeEventForTestingCall(m telemetry.Metrics) *telemetry.
 func StartFetchAttestedNodeEventCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.NodeEvent, telemetry.Fetch)
 }
+
+// StartListBundleEventsCall return metric
+// for server's datastore, on listing bundle events.
+func StartListBundleEventsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.BundleEvent, telemetry.List)
+}
@@ -29,6 +29,12 @@ func StartListFederationRelationshipsCall(m telemetry.Metrics) *telemetry.CallCo
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.FederationRelationship, telemetry.List)
 }
 
+// StartSetFederationRelationshipLastPollCall return metric
+// for server's datastore, on recording the last poll of a federation relationship.
+func StartSetFederationRelationshipLastPollCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.FederationRelationship, telemetry.LastPoll, telemetry.Set)
+}
+
 // StartUpdateFederationRelationshipCall return metric
 // for server's datastore, on updating a federation relationship.
 func StartUpdateFederationRelationshipCall(m telemetry.Metrics) *telemetry.CallCounter {
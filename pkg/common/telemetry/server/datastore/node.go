@@ -13,6 +13,24 @@ func StartCountNodeCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Count)
 }
 
+// StartAddNodeAliasCall return metric
+// for server's datastore, on adding an alias of a node.
+func StartAddNodeAliasCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Alias, telemetry.Create)
+}
+
+// StartListNodeAliasesCall return metric
+// for server's datastore, on listing the aliases of a node.
+func StartListNodeAliasesCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Alias, telemetry.List)
+}
+
+// StartRemoveNodeAliasCall return metric
+// for server's datastore, on removing an alias of a node.
+func StartRemoveNodeAliasCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Alias, telemetry.Delete)
+}
+
 // StartCreateNodeCall return metric
 // for server's datastore, on creating a node.
 func StartCreateNodeCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -25,6 +43,12 @@ func StartDeleteNodeCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Delete)
 }
 
+// StartNodeExistsCall return metric
+// for server's datastore, on checking whether a node exists.
+func StartNodeExistsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Exists)
+}
+
 // StartFetchNodeCall return metric
 // for server's datastore, on fetching a node.
 func StartFetchNodeCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -37,6 +61,30 @@ func StartListNodeCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.List)
 }
 
+// StartFetchNodeSerialHistoryCall return metric
+// for server's datastore, on fetching the serial number history of a node.
+func StartFetchNodeSerialHistoryCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Fetch, telemetry.SerialNumber)
+}
+
+// StartPromoteNodeSerialCall return metric
+// for server's datastore, on promoting the new serial number of a node.
+func StartPromoteNodeSerialCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.SerialNumber, telemetry.Rotate)
+}
+
+// StartPruneNodeCall return metric
+// for server's datastore, on pruning expired nodes.
+func StartPruneNodeCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Prune)
+}
+
+// StartPruneNodeDryRunCall return metric
+// for server's datastore, on listing the expired nodes that would be pruned.
+func StartPruneNodeDryRunCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Prune, telemetry.DryRun)
+}
+
 // StartGetNodeSelectorsCall return metric
 // for server's datastore, on getting selectors for a node.
 func StartGetNodeSelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -49,12 +97,30 @@ func StartListNodeSelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Selectors, telemetry.List)
 }
 
+// StartPruneNodeSelectorsCall return metric
+// for server's datastore, on pruning expired node selectors.
+func StartPruneNodeSelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Selectors, telemetry.Prune)
+}
+
 // StartSetNodeSelectorsCall return metric
 // for server's datastore, on setting selectors for a node.
 func StartSetNodeSelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Selectors, telemetry.Set)
 }
 
+// StartBatchSetNodeSelectorsCall return metric
+// for server's datastore, on setting selectors for several nodes.
+func StartBatchSetNodeSelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Selectors, telemetry.BatchSet)
+}
+
+// StartSetNodesReattestCall return metric
+// for server's datastore, on setting the reattest flag of nodes.
+func StartSetNodesReattestCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.Node, telemetry.Reattestable, telemetry.Set)
+}
+
 // StartUpdateNodeCall return metric
 // for server's datastore, on updating a node.
 func StartUpdateNodeCall(m telemetry.Metrics) *telemetry.CallCounter {
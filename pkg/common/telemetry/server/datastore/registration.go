@@ -13,12 +13,24 @@ func StartCountRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Count)
 }
 
+// StartCountRegistrationByTrustDomainCall return metric
+// for server's datastore, on counting registrations by trust domain.
+func StartCountRegistrationByTrustDomainCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Count, telemetry.TrustDomain)
+}
+
 // StartCreateRegistrationCall return metric
 // for server's datastore, on creating a registration.
 func StartCreateRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Create)
 }
 
+// StartBatchCreateRegistrationCall return metric
+// for server's datastore, on creating several registrations at once.
+func StartBatchCreateRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.BatchCreate)
+}
+
 // StartDeleteRegistrationCall return metric
 // for server's datastore, on deleting a registration.
 func StartDeleteRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -31,18 +43,84 @@ func StartFetchRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Fetch)
 }
 
+// StartStreamRegistrationCall return metric
+// for server's datastore, on streaming registrations.
+func StartStreamRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Stream)
+}
+
 // StartListRegistrationCall return metric
 // for server's datastore, on listing registrations.
 func StartListRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.List)
 }
 
+// StartListRegistrationByFederatesWithCall return metric
+// for server's datastore, on listing registrations by federated trust domain.
+func StartListRegistrationByFederatesWithCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.List, telemetry.FederatesWith)
+}
+
+// StartExplainListRegistrationCall return metric
+// for server's datastore, on explaining the query listing registrations.
+func StartExplainListRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.List, telemetry.Explain)
+}
+
+// StartFetchRegistrationWithParentsCall return metric
+// for server's datastore, on fetching a registration along with its parent registrations.
+func StartFetchRegistrationWithParentsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.ParentChain, telemetry.Fetch)
+}
+
+// StartListRegistrationExpiringBeforeCall return metric
+// for server's datastore, on listing the registrations that expire before a given time.
+func StartListRegistrationExpiringBeforeCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.List, telemetry.Expiring)
+}
+
+// StartListOrphanedEntryChildrenCall return metric
+// for server's datastore, on finding the selectors and DNS names of registrations that no longer exist.
+func StartListOrphanedEntryChildrenCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.OrphanedChildren, telemetry.List)
+}
+
+// StartPruneOrphanedEntryChildrenCall return metric
+// for server's datastore, on pruning the selectors and DNS names of registrations that no longer exist.
+func StartPruneOrphanedEntryChildrenCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.OrphanedChildren, telemetry.Prune)
+}
+
+// StartPruneDuplicateEntrySelectorsCall return metric
+// for server's datastore, on deleting the selectors repeated within a registration.
+func StartPruneDuplicateEntrySelectorsCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.DuplicateSelectors, telemetry.Prune)
+}
+
+// StartListRegistrationExcessDNSNamesCall return metric
+// for server's datastore, on listing the registrations with more DNS names than allowed.
+func StartListRegistrationExcessDNSNamesCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.ExcessDNSNames, telemetry.List)
+}
+
+// StartPruneRegistrationDryRunCall return metric
+// for server's datastore, on listing the expired registrations that would be pruned.
+func StartPruneRegistrationDryRunCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Prune, telemetry.DryRun)
+}
+
 // StartPruneRegistrationCall return metric
 // for server's datastore, on pruning registrations.
 func StartPruneRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
 	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Prune)
 }
 
+// StartSetRegistrationActiveCall return metric
+// for server's datastore, on activating or deactivating a registration.
+func StartSetRegistrationActiveCall(m telemetry.Metrics) *telemetry.CallCounter {
+	return telemetry.StartCall(m, telemetry.Datastore, telemetry.RegistrationEntry, telemetry.Active, telemetry.Set)
+}
+
 // StartUpdateRegistrationCall return metric
 // for server's datastore, on updating a registration.
 func StartUpdateRegistrationCall(m telemetry.Metrics) *telemetry.CallCounter {
@@ -29,6 +29,18 @@ func (w metricsWrapper) AppendBundle(ctx context.Context, bundle *common.Bundle)
 	return w.ds.AppendBundle(ctx, bundle)
 }
 
+func (w metricsWrapper) AddAttestedNodeAlias(ctx context.Context, spiffeID, alias string) (err error) {
+	callCounter := StartAddNodeAliasCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.AddAttestedNodeAlias(ctx, spiffeID, alias)
+}
+
+func (w metricsWrapper) AttestedNodeExists(ctx context.Context, spiffeID string) (_ bool, err error) {
+	callCounter := StartNodeExistsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.AttestedNodeExists(ctx, spiffeID)
+}
+
 func (w metricsWrapper) CreateAttestedNode(ctx context.Context, node *common.AttestedNode) (_ *common.AttestedNode, err error) {
 	callCounter := StartCreateNodeCall(w.m)
 	defer callCounter.Done(&err)
@@ -47,12 +59,24 @@ func (w metricsWrapper) CreateBundle(ctx context.Context, bundle *common.Bundle)
 	return w.ds.CreateBundle(ctx, bundle)
 }
 
+func (w metricsWrapper) ConsumeJoinToken(ctx context.Context, token string) (_ *datastore.JoinToken, err error) {
+	callCounter := StartConsumeJoinTokenCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ConsumeJoinToken(ctx, token)
+}
+
 func (w metricsWrapper) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) (err error) {
 	callCounter := StartCreateJoinTokenCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.CreateJoinToken(ctx, token)
 }
 
+func (w metricsWrapper) CreateRegistrationEntries(ctx context.Context, entries []*common.RegistrationEntry) (_ []*datastore.CreateRegistrationEntryResult, err error) {
+	callCounter := StartBatchCreateRegistrationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.CreateRegistrationEntries(ctx, entries)
+}
+
 func (w metricsWrapper) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (_ *common.RegistrationEntry, err error) {
 	callCounter := StartCreateRegistrationCall(w.m)
 	defer callCounter.Done(&err)
@@ -131,6 +155,12 @@ func (w metricsWrapper) FetchAttestedNode(ctx context.Context, spiffeID string)
 	return w.ds.FetchAttestedNode(ctx, spiffeID)
 }
 
+func (w metricsWrapper) FetchAttestedNodeSerialHistory(ctx context.Context, spiffeID string) (_ []*datastore.AttestedNodeSerial, err error) {
+	callCounter := StartFetchNodeSerialHistoryCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.FetchAttestedNodeSerialHistory(ctx, spiffeID)
+}
+
 func (w metricsWrapper) FetchAttestedNodeEvent(ctx context.Context, eventID uint) (_ *datastore.AttestedNodeEvent, err error) {
 	callCounter := StartFetchAttestedNodeEventCall(w.m)
 	defer callCounter.Done(&err)
@@ -143,18 +173,36 @@ func (w metricsWrapper) FetchBundle(ctx context.Context, trustDomain string) (_
 	return w.ds.FetchBundle(ctx, trustDomain)
 }
 
+func (w metricsWrapper) FetchBundleContentHash(ctx context.Context, trustDomain string) (_ string, err error) {
+	callCounter := StartFetchBundleContentHashCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.FetchBundleContentHash(ctx, trustDomain)
+}
+
 func (w metricsWrapper) FetchJoinToken(ctx context.Context, token string) (_ *datastore.JoinToken, err error) {
 	callCounter := StartFetchJoinTokenCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.FetchJoinToken(ctx, token)
 }
 
+func (w metricsWrapper) ExplainListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (_ string, err error) {
+	callCounter := StartExplainListRegistrationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ExplainListRegistrationEntries(ctx, req)
+}
+
 func (w metricsWrapper) FetchRegistrationEntry(ctx context.Context, entryID string) (_ *common.RegistrationEntry, err error) {
 	callCounter := StartFetchRegistrationCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.FetchRegistrationEntry(ctx, entryID)
 }
 
+func (w metricsWrapper) FetchRegistrationEntryWithParents(ctx context.Context, entryID string) (_ []*common.RegistrationEntry, err error) {
+	callCounter := StartFetchRegistrationWithParentsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.FetchRegistrationEntryWithParents(ctx, entryID)
+}
+
 func (w metricsWrapper) FetchRegistrationEntryEvent(ctx context.Context, eventID uint) (_ *datastore.RegistrationEntryEvent, err error) {
 	callCounter := StartFetchRegistrationEntryEventCall(w.m)
 	defer callCounter.Done(&err)
@@ -167,10 +215,16 @@ func (w metricsWrapper) FetchFederationRelationship(ctx context.Context, trustDo
 	return w.ds.FetchFederationRelationship(ctx, trustDomain)
 }
 
-func (w metricsWrapper) GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency datastore.DataConsistency) (_ []*common.Selector, err error) {
+func (w metricsWrapper) GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency datastore.DataConsistency, excludeExpired bool) (_ []*common.Selector, err error) {
 	callCounter := StartGetNodeSelectorsCall(w.m)
 	defer callCounter.Done(&err)
-	return w.ds.GetNodeSelectors(ctx, spiffeID, dataConsistency)
+	return w.ds.GetNodeSelectors(ctx, spiffeID, dataConsistency, excludeExpired)
+}
+
+func (w metricsWrapper) ListAttestedNodeAliases(ctx context.Context, spiffeID string) (_ []string, err error) {
+	callCounter := StartListNodeAliasesCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListAttestedNodeAliases(ctx, spiffeID)
 }
 
 func (w metricsWrapper) ListAttestedNodes(ctx context.Context, req *datastore.ListAttestedNodesRequest) (_ *datastore.ListAttestedNodesResponse, err error) {
@@ -179,12 +233,30 @@ func (w metricsWrapper) ListAttestedNodes(ctx context.Context, req *datastore.Li
 	return w.ds.ListAttestedNodes(ctx, req)
 }
 
+func (w metricsWrapper) ListAttestedNodesToPrune(ctx context.Context, expiredBefore time.Time) (_ []string, err error) {
+	callCounter := StartPruneNodeDryRunCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListAttestedNodesToPrune(ctx, expiredBefore)
+}
+
 func (w metricsWrapper) ListAttestedNodeEvents(ctx context.Context, req *datastore.ListAttestedNodeEventsRequest) (_ *datastore.ListAttestedNodeEventsResponse, err error) {
 	callCounter := StartListAttestedNodeEventsCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.ListAttestedNodeEvents(ctx, req)
 }
 
+func (w metricsWrapper) ListBundleAuthoritiesToPrune(ctx context.Context, trustDomainID string, expiresBefore time.Time) (_ *datastore.BundleAuthoritiesToPrune, err error) {
+	callCounter := StartPruneBundleDryRunCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListBundleAuthoritiesToPrune(ctx, trustDomainID, expiresBefore)
+}
+
+func (w metricsWrapper) ListBundleEvents(ctx context.Context, req *datastore.ListBundleEventsRequest) (_ *datastore.ListBundleEventsResponse, err error) {
+	callCounter := StartListBundleEventsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListBundleEvents(ctx, req)
+}
+
 func (w metricsWrapper) ListBundles(ctx context.Context, req *datastore.ListBundlesRequest) (_ *datastore.ListBundlesResponse, err error) {
 	callCounter := StartListBundleCall(w.m)
 	defer callCounter.Done(&err)
@@ -203,6 +275,48 @@ func (w metricsWrapper) ListRegistrationEntries(ctx context.Context, req *datast
 	return w.ds.ListRegistrationEntries(ctx, req)
 }
 
+func (w metricsWrapper) ListRegistrationEntriesByFederatesWith(ctx context.Context, trustDomain string, pagination *datastore.Pagination) (_ *datastore.ListRegistrationEntriesResponse, err error) {
+	callCounter := StartListRegistrationByFederatesWithCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListRegistrationEntriesByFederatesWith(ctx, trustDomain, pagination)
+}
+
+func (w metricsWrapper) FindOrphanedEntryChildren(ctx context.Context, includeIDs bool) (_ *datastore.OrphanedEntryChildren, err error) {
+	callCounter := StartListOrphanedEntryChildrenCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.FindOrphanedEntryChildren(ctx, includeIDs)
+}
+
+func (w metricsWrapper) PruneOrphanedEntryChildren(ctx context.Context) (_ *datastore.OrphanedEntryChildren, err error) {
+	callCounter := StartPruneOrphanedEntryChildrenCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PruneOrphanedEntryChildren(ctx)
+}
+
+func (w metricsWrapper) DedupeEntrySelectors(ctx context.Context) (_ int, err error) {
+	callCounter := StartPruneDuplicateEntrySelectorsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.DedupeEntrySelectors(ctx)
+}
+
+func (w metricsWrapper) ListRegistrationEntriesExpiringBefore(ctx context.Context, expiresBefore time.Time) (_ []string, err error) {
+	callCounter := StartListRegistrationExpiringBeforeCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListRegistrationEntriesExpiringBefore(ctx, expiresBefore)
+}
+
+func (w metricsWrapper) ListRegistrationEntriesToPrune(ctx context.Context, expiresBefore time.Time) (_ []string, err error) {
+	callCounter := StartPruneRegistrationDryRunCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListRegistrationEntriesToPrune(ctx, expiresBefore)
+}
+
+func (w metricsWrapper) ListRegistrationEntriesWithExcessDNSNames(ctx context.Context) (_ []string, err error) {
+	callCounter := StartListRegistrationExcessDNSNamesCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.ListRegistrationEntriesWithExcessDNSNames(ctx)
+}
+
 func (w metricsWrapper) ListRegistrationEntryEvents(ctx context.Context, req *datastore.ListRegistrationEntryEventsRequest) (_ *datastore.ListRegistrationEntryEventsResponse, err error) {
 	callCounter := StartListRegistrationEntryEventsCall(w.m)
 	defer callCounter.Done(&err)
@@ -227,12 +341,42 @@ func (w metricsWrapper) CountRegistrationEntries(ctx context.Context, req *datas
 	return w.ds.CountRegistrationEntries(ctx, req)
 }
 
+func (w metricsWrapper) CountRegistrationEntriesByTrustDomain(ctx context.Context) (_ map[string]int32, err error) {
+	callCounter := StartCountRegistrationByTrustDomainCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.CountRegistrationEntriesByTrustDomain(ctx)
+}
+
+func (w metricsWrapper) CompactAttestedNodeEvents(ctx context.Context) (_ int, err error) {
+	callCounter := StartCompactAttestedNodeEventsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.CompactAttestedNodeEvents(ctx)
+}
+
 func (w metricsWrapper) PruneAttestedNodeEvents(ctx context.Context, olderThan time.Duration) (err error) {
 	callCounter := StartPruneAttestedNodeEventsCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.PruneAttestedNodeEvents(ctx, olderThan)
 }
 
+func (w metricsWrapper) PromoteAttestedNodeSerial(ctx context.Context, spiffeID, expectedNewSerial string) (_ *common.AttestedNode, err error) {
+	callCounter := StartPromoteNodeSerialCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PromoteAttestedNodeSerial(ctx, spiffeID, expectedNewSerial)
+}
+
+func (w metricsWrapper) Ping(ctx context.Context) (err error) {
+	callCounter := StartPingCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.Ping(ctx)
+}
+
+func (w metricsWrapper) PruneAttestedNodes(ctx context.Context, expiredBefore time.Time) (_ int, err error) {
+	callCounter := StartPruneNodeCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PruneAttestedNodes(ctx, expiredBefore)
+}
+
 func (w metricsWrapper) PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (_ bool, err error) {
 	callCounter := StartPruneBundleCall(w.m)
 	defer callCounter.Done(&err)
@@ -245,30 +389,78 @@ func (w metricsWrapper) PruneJoinTokens(ctx context.Context, expiresBefore time.
 	return w.ds.PruneJoinTokens(ctx, expiresBefore)
 }
 
+func (w metricsWrapper) PruneEvents(ctx context.Context, olderThan time.Time) (err error) {
+	callCounter := StartPruneEventsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PruneEvents(ctx, olderThan)
+}
+
+func (w metricsWrapper) PruneNodeSelectors(ctx context.Context, expiredBefore time.Time) (_ int, err error) {
+	callCounter := StartPruneNodeSelectorsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PruneNodeSelectors(ctx, expiredBefore)
+}
+
 func (w metricsWrapper) PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) (err error) {
 	callCounter := StartPruneRegistrationCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.PruneRegistrationEntries(ctx, expiresBefore)
 }
 
+func (w metricsWrapper) CompactRegistrationEntryEvents(ctx context.Context) (_ int, err error) {
+	callCounter := StartCompactRegistrationEntryEventsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.CompactRegistrationEntryEvents(ctx)
+}
+
 func (w metricsWrapper) PruneRegistrationEntryEvents(ctx context.Context, olderThan time.Duration) (err error) {
 	callCounter := StartPruneRegistrationEntryEventsCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.PruneRegistrationEntryEvents(ctx, olderThan)
 }
 
+func (w metricsWrapper) SetAttestedNodesReattest(ctx context.Context, spiffeIDs []string, canReattest bool) (_ int, err error) {
+	callCounter := StartSetNodesReattestCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.SetAttestedNodesReattest(ctx, spiffeIDs, canReattest)
+}
+
+func (w metricsWrapper) SetRegistrationEntryActive(ctx context.Context, entryID string, active bool) (_ *common.RegistrationEntry, err error) {
+	callCounter := StartSetRegistrationActiveCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.SetRegistrationEntryActive(ctx, entryID, active)
+}
+
+func (w metricsWrapper) StreamRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest, fn func(*common.RegistrationEntry) error) (err error) {
+	callCounter := StartStreamRegistrationCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.StreamRegistrationEntries(ctx, req, fn)
+}
+
 func (w metricsWrapper) SetBundle(ctx context.Context, bundle *common.Bundle) (_ *common.Bundle, err error) {
 	callCounter := StartSetBundleCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.SetBundle(ctx, bundle)
 }
 
+func (w metricsWrapper) SetBundleRefreshedAt(ctx context.Context, trustDomainID string, refreshedAt time.Time) (err error) {
+	callCounter := StartSetBundleRefreshedAtCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.SetBundleRefreshedAt(ctx, trustDomainID, refreshedAt)
+}
+
 func (w metricsWrapper) TaintX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToTaint string) (err error) {
 	callCounter := StartTaintX509CAByKeyCall(w.m)
 	defer callCounter.Done(&err)
 	return w.ds.TaintX509CA(ctx, trustDomainID, subjectKeyIDToTaint)
 }
 
+func (w metricsWrapper) RemoveAttestedNodeAlias(ctx context.Context, alias string) (err error) {
+	callCounter := StartRemoveNodeAliasCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.RemoveAttestedNodeAlias(ctx, alias)
+}
+
 func (w metricsWrapper) RevokeX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToRevoke string) (err error) {
 	callCounter := StartRevokeX509CACall(w.m)
 	defer callCounter.Done(&err)
@@ -293,6 +485,12 @@ func (w metricsWrapper) SetNodeSelectors(ctx context.Context, spiffeID string, s
 	return w.ds.SetNodeSelectors(ctx, spiffeID, selectors)
 }
 
+func (w metricsWrapper) SetNodeSelectorsBulk(ctx context.Context, selectorsBySpiffeID map[string][]*common.Selector) (err error) {
+	callCounter := StartBatchSetNodeSelectorsCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.SetNodeSelectorsBulk(ctx, selectorsBySpiffeID)
+}
+
 func (w metricsWrapper) UpdateAttestedNode(ctx context.Context, node *common.AttestedNode, mask *common.AttestedNodeMask) (_ *common.AttestedNode, err error) {
 	callCounter := StartUpdateNodeCall(w.m)
 	defer callCounter.Done(&err)
@@ -311,6 +509,12 @@ func (w metricsWrapper) UpdateRegistrationEntry(ctx context.Context, entry *comm
 	return w.ds.UpdateRegistrationEntry(ctx, entry, mask)
 }
 
+func (w metricsWrapper) SetFederationRelationshipLastPoll(ctx context.Context, trustDomain spiffeid.TrustDomain, polledAt, nextPollAt time.Time, pollErr string) (err error) {
+	callCounter := StartSetFederationRelationshipLastPollCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.SetFederationRelationshipLastPoll(ctx, trustDomain, polledAt, nextPollAt, pollErr)
+}
+
 func (w metricsWrapper) UpdateFederationRelationship(ctx context.Context, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (_ *datastore.FederationRelationship, err error) {
 	callCounter := StartUpdateFederationRelationshipCall(w.m)
 	defer callCounter.Done(&err)
@@ -335,6 +539,12 @@ func (w metricsWrapper) ListCAJournalsForTesting(ctx context.Context) (_ []*data
 	return w.ds.ListCAJournalsForTesting(ctx)
 }
 
+func (w metricsWrapper) PruneCAJournal(ctx context.Context, retention time.Duration) (err error) {
+	callCounter := StartPruneCAJournalAuthoritiesCall(w.m)
+	defer callCounter.Done(&err)
+	return w.ds.PruneCAJournal(ctx, retention)
+}
+
 func (w metricsWrapper) PruneCAJournals(ctx context.Context, allCAsExpireBefore int64) (err error) {
 	callCounter := StartPruneCAJournalsCall(w.m)
 	defer callCounter.Done(&err)
@@ -37,10 +37,18 @@ func TestWithMetrics(t *testing.T) {
 		key        string
 		methodName string
 	}{
+		{
+			key:        "datastore.node.alias.create",
+			methodName: "AddAttestedNodeAlias",
+		},
 		{
 			key:        "datastore.bundle.append",
 			methodName: "AppendBundle",
 		},
+		{
+			key:        "datastore.node.exists",
+			methodName: "AttestedNodeExists",
+		},
 		{
 			key:        "datastore.node.count",
 			methodName: "CountAttestedNodes",
@@ -53,6 +61,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.registration_entry.count",
 			methodName: "CountRegistrationEntries",
 		},
+		{
+			key:        "datastore.registration_entry.count.trust_domain",
+			methodName: "CountRegistrationEntriesByTrustDomain",
+		},
 		{
 			key:        "datastore.node.create",
 			methodName: "CreateAttestedNode",
@@ -69,10 +81,18 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.federation_relationship.create",
 			methodName: "CreateFederationRelationship",
 		},
+		{
+			key:        "datastore.join_token.consume",
+			methodName: "ConsumeJoinToken",
+		},
 		{
 			key:        "datastore.join_token.create",
 			methodName: "CreateJoinToken",
 		},
+		{
+			key:        "datastore.registration_entry.batch_create",
+			methodName: "CreateRegistrationEntries",
+		},
 		{
 			key:        "datastore.registration_entry.create",
 			methodName: "CreateRegistrationEntry",
@@ -117,6 +137,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.node.fetch",
 			methodName: "FetchAttestedNode",
 		},
+		{
+			key:        "datastore.node.fetch.serial_num",
+			methodName: "FetchAttestedNodeSerialHistory",
+		},
 		{
 			key:        "datastore.node_event.fetch",
 			methodName: "FetchAttestedNodeEvent",
@@ -125,14 +149,26 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.fetch",
 			methodName: "FetchBundle",
 		},
+		{
+			key:        "datastore.bundle.content_hash.fetch",
+			methodName: "FetchBundleContentHash",
+		},
 		{
 			key:        "datastore.join_token.fetch",
 			methodName: "FetchJoinToken",
 		},
+		{
+			key:        "datastore.registration_entry.list.explain",
+			methodName: "ExplainListRegistrationEntries",
+		},
 		{
 			key:        "datastore.registration_entry.fetch",
 			methodName: "FetchRegistrationEntry",
 		},
+		{
+			key:        "datastore.registration_entry.parent_chain.fetch",
+			methodName: "FetchRegistrationEntryWithParents",
+		},
 		{
 			key:        "datastore.registration_entry_event.fetch",
 			methodName: "FetchRegistrationEntryEvent",
@@ -145,14 +181,30 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.node.selectors.fetch",
 			methodName: "GetNodeSelectors",
 		},
+		{
+			key:        "datastore.node.alias.list",
+			methodName: "ListAttestedNodeAliases",
+		},
 		{
 			key:        "datastore.node.list",
 			methodName: "ListAttestedNodes",
 		},
+		{
+			key:        "datastore.node.prune.dry_run",
+			methodName: "ListAttestedNodesToPrune",
+		},
 		{
 			key:        "datastore.node_event.list",
 			methodName: "ListAttestedNodeEvents",
 		},
+		{
+			key:        "datastore.bundle.prune.dry_run",
+			methodName: "ListBundleAuthoritiesToPrune",
+		},
+		{
+			key:        "datastore.bundle_event.list",
+			methodName: "ListBundleEvents",
+		},
 		{
 			key:        "datastore.bundle.list",
 			methodName: "ListBundles",
@@ -165,6 +217,34 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.registration_entry.list",
 			methodName: "ListRegistrationEntries",
 		},
+		{
+			key:        "datastore.registration_entry.list.federates_with",
+			methodName: "ListRegistrationEntriesByFederatesWith",
+		},
+		{
+			key:        "datastore.registration_entry.list.expiring",
+			methodName: "ListRegistrationEntriesExpiringBefore",
+		},
+		{
+			key:        "datastore.registration_entry.orphaned_children.list",
+			methodName: "FindOrphanedEntryChildren",
+		},
+		{
+			key:        "datastore.registration_entry.orphaned_children.prune",
+			methodName: "PruneOrphanedEntryChildren",
+		},
+		{
+			key:        "datastore.registration_entry.duplicate_selectors.prune",
+			methodName: "DedupeEntrySelectors",
+		},
+		{
+			key:        "datastore.registration_entry.prune.dry_run",
+			methodName: "ListRegistrationEntriesToPrune",
+		},
+		{
+			key:        "datastore.registration_entry.excess_dns_names.list",
+			methodName: "ListRegistrationEntriesWithExcessDNSNames",
+		},
 		{
 			key:        "datastore.registration_entry_event.list",
 			methodName: "ListRegistrationEntryEvents",
@@ -173,10 +253,22 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.federation_relationship.list",
 			methodName: "ListFederationRelationships",
 		},
+		{
+			key:        "datastore.node.serial_num.rotate",
+			methodName: "PromoteAttestedNodeSerial",
+		},
 		{
 			key:        "datastore.node_event.prune",
 			methodName: "PruneAttestedNodeEvents",
 		},
+		{
+			key:        "datastore.ping",
+			methodName: "Ping",
+		},
+		{
+			key:        "datastore.node.prune",
+			methodName: "PruneAttestedNodes",
+		},
 		{
 			key:        "datastore.bundle.prune",
 			methodName: "PruneBundle",
@@ -185,6 +277,22 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.join_token.prune",
 			methodName: "PruneJoinTokens",
 		},
+		{
+			key:        "datastore.node.selectors.prune",
+			methodName: "PruneNodeSelectors",
+		},
+		{
+			key:        "datastore.event.prune",
+			methodName: "PruneEvents",
+		},
+		{
+			key:        "datastore.node_event.compact",
+			methodName: "CompactAttestedNodeEvents",
+		},
+		{
+			key:        "datastore.registration_entry_event.compact",
+			methodName: "CompactRegistrationEntryEvents",
+		},
 		{
 			key:        "datastore.registration_entry.prune",
 			methodName: "PruneRegistrationEntries",
@@ -193,10 +301,26 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.registration_entry_event.prune",
 			methodName: "PruneRegistrationEntryEvents",
 		},
+		{
+			key:        "datastore.node.reattestable.set",
+			methodName: "SetAttestedNodesReattest",
+		},
 		{
 			key:        "datastore.bundle.set",
 			methodName: "SetBundle",
 		},
+		{
+			key:        "datastore.bundle.last_refreshed_at.set",
+			methodName: "SetBundleRefreshedAt",
+		},
+		{
+			key:        "datastore.registration_entry.active.set",
+			methodName: "SetRegistrationEntryActive",
+		},
+		{
+			key:        "datastore.registration_entry.stream",
+			methodName: "StreamRegistrationEntries",
+		},
 		{
 			key:        "datastore.bundle.x509.taint",
 			methodName: "TaintX509CA",
@@ -205,6 +329,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.bundle.jwt.revoke",
 			methodName: "RevokeJWTKey",
 		},
+		{
+			key:        "datastore.node.alias.delete",
+			methodName: "RemoveAttestedNodeAlias",
+		},
 		{
 			key:        "datastore.bundle.x509.revoke",
 			methodName: "RevokeX509CA",
@@ -217,6 +345,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.node.selectors.set",
 			methodName: "SetNodeSelectors",
 		},
+		{
+			key:        "datastore.node.selectors.batch_set",
+			methodName: "SetNodeSelectorsBulk",
+		},
 		{
 			key:        "datastore.node.update",
 			methodName: "UpdateAttestedNode",
@@ -229,6 +361,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.federation_relationship.update",
 			methodName: "UpdateFederationRelationship",
 		},
+		{
+			key:        "datastore.federation_relationship.last_poll.set",
+			methodName: "SetFederationRelationshipLastPoll",
+		},
 		{
 			key:        "datastore.registration_entry.update",
 			methodName: "UpdateRegistrationEntry",
@@ -245,6 +381,10 @@ func TestWithMetrics(t *testing.T) {
 			key:        "datastore.ca_journal.prune",
 			methodName: "PruneCAJournals",
 		},
+		{
+			key:        "datastore.ca_journal.authorities.prune",
+			methodName: "PruneCAJournal",
+		},
 		{
 			key:        "datastore.ca_journal.list",
 			methodName: "ListCAJournalsForTesting",
@@ -332,6 +472,14 @@ func (ds *fakeDataStore) AppendBundle(context.Context, *common.Bundle) (*common.
 	return &common.Bundle{}, ds.err
 }
 
+func (ds *fakeDataStore) AddAttestedNodeAlias(context.Context, string, string) error {
+	return ds.err
+}
+
+func (ds *fakeDataStore) AttestedNodeExists(context.Context, string) (bool, error) {
+	return false, ds.err
+}
+
 func (ds *fakeDataStore) CountAttestedNodes(context.Context, *datastore.CountAttestedNodesRequest) (int32, error) {
 	return 0, ds.err
 }
@@ -344,6 +492,10 @@ func (ds *fakeDataStore) CountRegistrationEntries(context.Context, *datastore.Co
 	return 0, ds.err
 }
 
+func (ds *fakeDataStore) CountRegistrationEntriesByTrustDomain(context.Context) (map[string]int32, error) {
+	return map[string]int32{}, ds.err
+}
+
 func (ds *fakeDataStore) CreateAttestedNode(context.Context, *common.AttestedNode) (*common.AttestedNode, error) {
 	return &common.AttestedNode{}, ds.err
 }
@@ -364,10 +516,18 @@ func (ds *fakeDataStore) ListFederationRelationships(context.Context, *datastore
 	return &datastore.ListFederationRelationshipsResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) ConsumeJoinToken(context.Context, string) (*datastore.JoinToken, error) {
+	return &datastore.JoinToken{}, ds.err
+}
+
 func (ds *fakeDataStore) CreateJoinToken(context.Context, *datastore.JoinToken) error {
 	return ds.err
 }
 
+func (ds *fakeDataStore) CreateRegistrationEntries(context.Context, []*common.RegistrationEntry) ([]*datastore.CreateRegistrationEntryResult, error) {
+	return []*datastore.CreateRegistrationEntryResult{}, ds.err
+}
+
 func (ds *fakeDataStore) CreateRegistrationEntry(context.Context, *common.RegistrationEntry) (*common.RegistrationEntry, error) {
 	return &common.RegistrationEntry{}, ds.err
 }
@@ -392,6 +552,10 @@ func (ds *fakeDataStore) DeleteBundle(context.Context, string, datastore.DeleteM
 	return ds.err
 }
 
+func (ds *fakeDataStore) SetFederationRelationshipLastPoll(context.Context, spiffeid.TrustDomain, time.Time, time.Time, string) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) DeleteFederationRelationship(context.Context, spiffeid.TrustDomain) error {
 	return ds.err
 }
@@ -412,6 +576,10 @@ func (ds *fakeDataStore) FetchAttestedNode(context.Context, string) (*common.Att
 	return &common.AttestedNode{}, ds.err
 }
 
+func (ds *fakeDataStore) FetchAttestedNodeSerialHistory(context.Context, string) ([]*datastore.AttestedNodeSerial, error) {
+	return []*datastore.AttestedNodeSerial{}, ds.err
+}
+
 func (ds *fakeDataStore) FetchAttestedNodeEvent(context.Context, uint) (*datastore.AttestedNodeEvent, error) {
 	return &datastore.AttestedNodeEvent{}, ds.err
 }
@@ -420,6 +588,10 @@ func (ds *fakeDataStore) FetchBundle(context.Context, string) (*common.Bundle, e
 	return &common.Bundle{}, ds.err
 }
 
+func (ds *fakeDataStore) FetchBundleContentHash(context.Context, string) (string, error) {
+	return "", ds.err
+}
+
 func (ds *fakeDataStore) FetchFederationRelationship(context.Context, spiffeid.TrustDomain) (*datastore.FederationRelationship, error) {
 	return &datastore.FederationRelationship{}, ds.err
 }
@@ -428,26 +600,50 @@ func (ds *fakeDataStore) FetchJoinToken(context.Context, string) (*datastore.Joi
 	return &datastore.JoinToken{}, ds.err
 }
 
+func (ds *fakeDataStore) ExplainListRegistrationEntries(context.Context, *datastore.ListRegistrationEntriesRequest) (string, error) {
+	return "", ds.err
+}
+
 func (ds *fakeDataStore) FetchRegistrationEntry(context.Context, string) (*common.RegistrationEntry, error) {
 	return &common.RegistrationEntry{}, ds.err
 }
 
+func (ds *fakeDataStore) FetchRegistrationEntryWithParents(context.Context, string) ([]*common.RegistrationEntry, error) {
+	return []*common.RegistrationEntry{}, ds.err
+}
+
 func (ds *fakeDataStore) FetchRegistrationEntryEvent(context.Context, uint) (*datastore.RegistrationEntryEvent, error) {
 	return &datastore.RegistrationEntryEvent{}, ds.err
 }
 
-func (ds *fakeDataStore) GetNodeSelectors(context.Context, string, datastore.DataConsistency) ([]*common.Selector, error) {
+func (ds *fakeDataStore) GetNodeSelectors(context.Context, string, datastore.DataConsistency, bool) ([]*common.Selector, error) {
 	return []*common.Selector{}, ds.err
 }
 
+func (ds *fakeDataStore) ListAttestedNodeAliases(context.Context, string) ([]string, error) {
+	return []string{}, ds.err
+}
+
 func (ds *fakeDataStore) ListAttestedNodes(context.Context, *datastore.ListAttestedNodesRequest) (*datastore.ListAttestedNodesResponse, error) {
 	return &datastore.ListAttestedNodesResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) ListAttestedNodesToPrune(context.Context, time.Time) ([]string, error) {
+	return []string{}, ds.err
+}
+
 func (ds *fakeDataStore) ListAttestedNodeEvents(context.Context, *datastore.ListAttestedNodeEventsRequest) (*datastore.ListAttestedNodeEventsResponse, error) {
 	return &datastore.ListAttestedNodeEventsResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) ListBundleAuthoritiesToPrune(context.Context, string, time.Time) (*datastore.BundleAuthoritiesToPrune, error) {
+	return &datastore.BundleAuthoritiesToPrune{}, ds.err
+}
+
+func (ds *fakeDataStore) ListBundleEvents(context.Context, *datastore.ListBundleEventsRequest) (*datastore.ListBundleEventsResponse, error) {
+	return &datastore.ListBundleEventsResponse{}, ds.err
+}
+
 func (ds *fakeDataStore) ListBundles(context.Context, *datastore.ListBundlesRequest) (*datastore.ListBundlesResponse, error) {
 	return &datastore.ListBundlesResponse{}, ds.err
 }
@@ -460,6 +656,34 @@ func (ds *fakeDataStore) ListRegistrationEntries(context.Context, *datastore.Lis
 	return &datastore.ListRegistrationEntriesResponse{}, ds.err
 }
 
+func (ds *fakeDataStore) ListRegistrationEntriesByFederatesWith(context.Context, string, *datastore.Pagination) (*datastore.ListRegistrationEntriesResponse, error) {
+	return &datastore.ListRegistrationEntriesResponse{}, ds.err
+}
+
+func (ds *fakeDataStore) FindOrphanedEntryChildren(context.Context, bool) (*datastore.OrphanedEntryChildren, error) {
+	return &datastore.OrphanedEntryChildren{}, ds.err
+}
+
+func (ds *fakeDataStore) PruneOrphanedEntryChildren(context.Context) (*datastore.OrphanedEntryChildren, error) {
+	return &datastore.OrphanedEntryChildren{}, ds.err
+}
+
+func (ds *fakeDataStore) DedupeEntrySelectors(context.Context) (int, error) {
+	return 0, ds.err
+}
+
+func (ds *fakeDataStore) ListRegistrationEntriesExpiringBefore(context.Context, time.Time) ([]string, error) {
+	return []string{}, ds.err
+}
+
+func (ds *fakeDataStore) ListRegistrationEntriesToPrune(context.Context, time.Time) ([]string, error) {
+	return []string{}, ds.err
+}
+
+func (ds *fakeDataStore) ListRegistrationEntriesWithExcessDNSNames(context.Context) ([]string, error) {
+	return []string{}, ds.err
+}
+
 func (ds *fakeDataStore) ListRegistrationEntryEvents(context.Context, *datastore.ListRegistrationEntryEventsRequest) (*datastore.ListRegistrationEntryEventsResponse, error) {
 	return &datastore.ListRegistrationEntryEventsResponse{}, ds.err
 }
@@ -468,6 +692,22 @@ func (ds *fakeDataStore) PruneAttestedNodeEvents(context.Context, time.Duration)
 	return ds.err
 }
 
+func (ds *fakeDataStore) CompactAttestedNodeEvents(context.Context) (int, error) {
+	return 0, ds.err
+}
+
+func (ds *fakeDataStore) PromoteAttestedNodeSerial(context.Context, string, string) (*common.AttestedNode, error) {
+	return &common.AttestedNode{}, ds.err
+}
+
+func (ds *fakeDataStore) Ping(context.Context) error {
+	return ds.err
+}
+
+func (ds *fakeDataStore) PruneAttestedNodes(context.Context, time.Time) (int, error) {
+	return 0, ds.err
+}
+
 func (ds *fakeDataStore) PruneBundle(context.Context, string, time.Time) (bool, error) {
 	return false, ds.err
 }
@@ -476,6 +716,14 @@ func (ds *fakeDataStore) PruneJoinTokens(context.Context, time.Time) error {
 	return ds.err
 }
 
+func (ds *fakeDataStore) PruneNodeSelectors(context.Context, time.Time) (int, error) {
+	return 0, ds.err
+}
+
+func (ds *fakeDataStore) PruneEvents(context.Context, time.Time) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) PruneRegistrationEntries(context.Context, time.Time) error {
 	return ds.err
 }
@@ -484,14 +732,38 @@ func (ds *fakeDataStore) PruneRegistrationEntryEvents(context.Context, time.Dura
 	return ds.err
 }
 
+func (ds *fakeDataStore) CompactRegistrationEntryEvents(context.Context) (int, error) {
+	return 0, ds.err
+}
+
+func (ds *fakeDataStore) SetAttestedNodesReattest(context.Context, []string, bool) (int, error) {
+	return 0, ds.err
+}
+
 func (ds *fakeDataStore) SetBundle(context.Context, *common.Bundle) (*common.Bundle, error) {
 	return &common.Bundle{}, ds.err
 }
 
+func (ds *fakeDataStore) SetBundleRefreshedAt(context.Context, string, time.Time) error {
+	return ds.err
+}
+
+func (ds *fakeDataStore) SetRegistrationEntryActive(context.Context, string, bool) (*common.RegistrationEntry, error) {
+	return &common.RegistrationEntry{}, ds.err
+}
+
+func (ds *fakeDataStore) StreamRegistrationEntries(context.Context, *datastore.ListRegistrationEntriesRequest, func(*common.RegistrationEntry) error) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) TaintX509CA(context.Context, string, string) error {
 	return ds.err
 }
 
+func (ds *fakeDataStore) RemoveAttestedNodeAlias(context.Context, string) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) RevokeX509CA(context.Context, string, string) error {
 	return ds.err
 }
@@ -508,6 +780,10 @@ func (ds *fakeDataStore) SetNodeSelectors(context.Context, string, []*common.Sel
 	return ds.err
 }
 
+func (ds *fakeDataStore) SetNodeSelectorsBulk(context.Context, map[string][]*common.Selector) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) UpdateAttestedNode(context.Context, *common.AttestedNode, *common.AttestedNodeMask) (*common.AttestedNode, error) {
 	return &common.AttestedNode{}, ds.err
 }
@@ -536,6 +812,10 @@ func (ds *fakeDataStore) ListCAJournalsForTesting(context.Context) ([]*datastore
 	return []*datastore.CAJournal{}, ds.err
 }
 
+func (ds *fakeDataStore) PruneCAJournal(context.Context, time.Duration) error {
+	return ds.err
+}
+
 func (ds *fakeDataStore) PruneCAJournals(context.Context, int64) error {
 	return ds.err
 }
@@ -8,6 +8,22 @@ func SetEntryDeletedGauge(m telemetry.Metrics, deleted int) {
 	m.SetGauge([]string{telemetry.Entry, telemetry.Deleted}, float32(deleted))
 }
 
+// SetEntryExpiringGauge emits a gauge with the number of registration entries
+// that are about to expire.
+func SetEntryExpiringGauge(m telemetry.Metrics, expiring int) {
+	m.SetGauge([]string{telemetry.Entry, telemetry.Expiring, telemetry.Count}, float32(expiring))
+}
+
+// IncrEntrySVIDIssuedCounter indicates that an SVID of the given type was
+// signed for a registration entry. The value accounts for sampling.
+func IncrEntrySVIDIssuedCounter(m telemetry.Metrics, entryID, trustDomain, svidType string, val float32) {
+	m.IncrCounterWithLabels([]string{telemetry.Entry, telemetry.SVID, telemetry.Issued}, val, []telemetry.Label{
+		{Name: telemetry.RegistrationID, Value: entryID},
+		{Name: telemetry.TrustDomain, Value: trustDomain},
+		{Name: telemetry.SVIDType, Value: svidType},
+	})
+}
+
 // SetAgentsByIDCacheCountGauge emits a gauge with the number of agents by ID that are
 // currently in the node cache.
 func SetAgentsByIDCacheCountGauge(m telemetry.Metrics, size int) {
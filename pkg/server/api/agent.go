@@ -8,6 +8,12 @@ import (
 	"github.com/spiffe/spire/proto/spire/common"
 )
 
+// JoinTokenUsesMetadataKey is the gRPC metadata key holding the number of
+// times the join token created by CreateJoinToken can be used to attest an
+// agent. Tokens are single-use when it is not set. CreateJoinTokenRequest has
+// no field for it, so it travels as metadata.
+const JoinTokenUsesMetadataKey = "spire-join-token-uses"
+
 func ProtoFromAttestedNode(n *common.AttestedNode) (*types.Agent, error) {
 	if n == nil {
 		return nil, errors.New("missing attested node")
@@ -5,6 +5,8 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/andres-erbsen/clock"
@@ -25,9 +27,11 @@ import (
 	"github.com/spiffe/spire/pkg/server/catalog"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/plugin/nodeattestor"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -40,17 +44,24 @@ type Config struct {
 	DataStore   datastore.DataStore
 	ServerCA    ca.ServerCA
 	TrustDomain spiffeid.TrustDomain
+
+	// PruneAttestedNodesExpiredFor is how long ago attested nodes must have
+	// expired for the server to prune them. If zero, they are not pruned.
+	PruneAttestedNodesExpiredFor time.Duration
 }
 
 // Service implements the v1 agent service
 type Service struct {
 	agentv1.UnsafeAgentServer
+	extensionv1.UnsafeAgentExtensionServer
 
 	cat catalog.Catalog
 	clk clock.Clock
 	ds  datastore.DataStore
 	ca  ca.ServerCA
 	td  spiffeid.TrustDomain
+
+	pruneAttestedNodesExpiredFor time.Duration
 }
 
 // New creates a new agent service
@@ -61,12 +72,16 @@ func New(config Config) *Service {
 		ds:  config.DataStore,
 		ca:  config.ServerCA,
 		td:  config.TrustDomain,
+
+		pruneAttestedNodesExpiredFor: config.PruneAttestedNodesExpiredFor,
 	}
 }
 
-// RegisterService registers the agent service on the gRPC server/
+// RegisterService registers the agent service, along with its extension, on
+// the gRPC server/
 func RegisterService(s grpc.ServiceRegistrar, service *Service) {
 	agentv1.RegisterAgentServer(s, service)
+	extensionv1.RegisterAgentExtensionServer(s, service)
 }
 
 // CountAgents returns the total number of agents.
@@ -123,8 +138,9 @@ func (s *Service) ListAgents(ctx context.Context, req *agentv1.ListAgentsRequest
 
 	listReq := &datastore.ListAttestedNodesRequest{}
 
-	if req.OutputMask == nil || req.OutputMask.Selectors {
-		listReq.FetchSelectors = true
+	if req.OutputMask != nil && !req.OutputMask.Selectors {
+		fetchSelectors := false
+		listReq.FetchSelectors = &fetchSelectors
 	}
 	// Parse proto filter into datastore request
 	if req.Filter != nil {
@@ -158,12 +174,11 @@ func (s *Service) ListAgents(ctx context.Context, req *agentv1.ListAgentsRequest
 		}
 	}
 
-	// Set pagination parameters
-	if req.PageSize > 0 {
-		listReq.Pagination = &datastore.Pagination{
-			PageSize: req.PageSize,
-			Token:    req.PageToken,
-		}
+	// Set pagination parameters. A zero page size is capped to the maximum
+	// page size by the datastore, so the list is never unbounded.
+	listReq.Pagination = &datastore.Pagination{
+		PageSize: req.PageSize,
+		Token:    req.PageToken,
 	}
 
 	dsResp, err := s.ds.ListAttestedNodes(ctx, listReq)
@@ -174,7 +189,8 @@ func (s *Service) ListAgents(ctx context.Context, req *agentv1.ListAgentsRequest
 	resp := &agentv1.ListAgentsResponse{}
 
 	if dsResp.Pagination != nil {
-		resp.NextPageToken = dsResp.Pagination.Token
+		resp.NextPageToken = api.NextPageToken(req.PageSize, dsResp.Pagination, len(dsResp.Nodes))
+		api.SetPageSizeHeader(ctx, log, dsResp.Pagination.PageSize)
 	}
 
 	// Parse nodes into proto and apply output mask
@@ -286,6 +302,226 @@ func (s *Service) BanAgent(ctx context.Context, req *agentv1.BanAgentRequest) (*
 	}
 }
 
+// SetAgentsCanReattest sets whether the given agents can re-attest.
+func (s *Service) SetAgentsCanReattest(ctx context.Context, req *extensionv1.SetAgentsCanReattestRequest) (*extensionv1.SetAgentsCanReattestResponse, error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.CanReattest: req.CanReattest})
+
+	if len(req.Ids) == 0 {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "at least one agent ID is required", nil)
+	}
+	spiffeIDs := make([]string, 0, len(req.Ids))
+	for _, protoID := range req.Ids {
+		id, err := api.TrustDomainAgentIDFromProto(ctx, s.td, protoID)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent ID", err)
+		}
+		spiffeIDs = append(spiffeIDs, id.String())
+	}
+
+	updated, err := s.ds.SetAttestedNodesReattest(ctx, spiffeIDs, req.CanReattest)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to set whether agents can reattest", err)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.Count: updated})
+	log.WithFields(logrus.Fields{
+		telemetry.CanReattest: req.CanReattest,
+		telemetry.Count:       updated,
+	}).Info("Set whether agents can reattest")
+	rpccontext.AuditRPC(ctx)
+
+	return &extensionv1.SetAgentsCanReattestResponse{Updated: int32(updated)}, nil
+}
+
+// ListAgentAliases lists the alias SPIFFE IDs of an agent.
+func (s *Service) ListAgentAliases(ctx context.Context, req *extensionv1.ListAgentAliasesRequest) (*extensionv1.ListAgentAliasesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	id, err := api.TrustDomainAgentIDFromProto(ctx, s.td, req.Id)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent ID", err)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.SPIFFEID: id.String()})
+
+	log = log.WithField(telemetry.SPIFFEID, id.String())
+
+	exists, err := s.ds.AttestedNodeExists(ctx, id.String())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch agent", err)
+	}
+	if !exists {
+		return nil, api.MakeErr(log, codes.NotFound, "agent not found", nil)
+	}
+
+	aliases, err := s.ds.ListAttestedNodeAliases(ctx, id.String())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list agent aliases", err)
+	}
+
+	resp := &extensionv1.ListAgentAliasesResponse{}
+	for _, alias := range aliases {
+		aliasID, err := spiffeid.FromString(alias)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "invalid agent alias", err)
+		}
+		resp.Aliases = append(resp.Aliases, api.ProtoFromID(aliasID))
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
+
+// AddAgentAlias adds an alias SPIFFE ID to an agent.
+func (s *Service) AddAgentAlias(ctx context.Context, req *extensionv1.AddAgentAliasRequest) (*emptypb.Empty, error) {
+	log := rpccontext.Logger(ctx)
+
+	id, alias, err := s.agentAliasFromProto(ctx, req.Id, req.Alias)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent alias", err)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.SPIFFEID: id.String(), telemetry.Alias: alias.String()})
+
+	log = log.WithFields(logrus.Fields{telemetry.SPIFFEID: id.String(), telemetry.Alias: alias.String()})
+
+	err = s.ds.AddAttestedNodeAlias(ctx, id.String(), alias.String())
+	switch status.Code(err) {
+	case codes.OK:
+		log.Info("Agent alias added")
+		rpccontext.AuditRPC(ctx)
+		return &emptypb.Empty{}, nil
+	case codes.NotFound:
+		return nil, api.MakeErr(log, codes.NotFound, "agent not found", err)
+	case codes.AlreadyExists:
+		return nil, api.MakeErr(log, codes.AlreadyExists, "alias is the SPIFFE ID of an agent", err)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to add agent alias", err)
+	}
+}
+
+// RemoveAgentAlias removes an alias SPIFFE ID from an agent.
+func (s *Service) RemoveAgentAlias(ctx context.Context, req *extensionv1.RemoveAgentAliasRequest) (*emptypb.Empty, error) {
+	log := rpccontext.Logger(ctx)
+
+	id, alias, err := s.agentAliasFromProto(ctx, req.Id, req.Alias)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent alias", err)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.SPIFFEID: id.String(), telemetry.Alias: alias.String()})
+
+	log = log.WithFields(logrus.Fields{telemetry.SPIFFEID: id.String(), telemetry.Alias: alias.String()})
+
+	// Aliases are unique, so they are removed by themselves; make sure the
+	// alias belongs to the given agent first.
+	aliases, err := s.ds.ListAttestedNodeAliases(ctx, id.String())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list agent aliases", err)
+	}
+	if !slices.Contains(aliases, alias.String()) {
+		return nil, api.MakeErr(log, codes.NotFound, "agent alias not found", nil)
+	}
+
+	if err := s.ds.RemoveAttestedNodeAlias(ctx, alias.String()); err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to remove agent alias", err)
+	}
+	log.Info("Agent alias removed")
+	rpccontext.AuditRPC(ctx)
+
+	return &emptypb.Empty{}, nil
+}
+
+// ListAgentsToPrune lists the agents that pruning attested nodes would
+// delete, without deleting them.
+func (s *Service) ListAgentsToPrune(ctx context.Context, req *extensionv1.ListAgentsToPruneRequest) (*extensionv1.ListAgentsToPruneResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.ExpiredFor < 0 {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "expired_for cannot be negative", nil)
+	}
+	expiredFor := time.Duration(req.ExpiredFor) * time.Second
+	if expiredFor == 0 {
+		expiredFor = s.pruneAttestedNodesExpiredFor
+	}
+
+	resp := &extensionv1.ListAgentsToPruneResponse{
+		ExpiredFor: int64(expiredFor / time.Second),
+	}
+	if expiredFor <= 0 {
+		rpccontext.AuditRPC(ctx)
+		return resp, nil
+	}
+
+	spiffeIDs, err := s.ds.ListAttestedNodesToPrune(ctx, s.clk.Now().Add(-expiredFor))
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list agents to prune", err)
+	}
+	for _, spiffeID := range spiffeIDs {
+		id, err := spiffeid.FromString(spiffeID)
+		if err != nil {
+			return nil, api.MakeErr(log, codes.Internal, "agent has malformed SPIFFE ID", err)
+		}
+		resp.Ids = append(resp.Ids, api.ProtoFromID(id))
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
+
+// GetAgentDetails gets the details of an agent that the agent API doesn't
+// expose.
+func (s *Service) GetAgentDetails(ctx context.Context, req *extensionv1.GetAgentDetailsRequest) (*extensionv1.GetAgentDetailsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	id, err := api.TrustDomainAgentIDFromProto(ctx, s.td, req.Id)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid agent ID", err)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.SPIFFEID: id.String()})
+
+	log = log.WithField(telemetry.SPIFFEID, id.String())
+
+	node, err := s.ds.FetchAttestedNode(ctx, id.String())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch agent", err)
+	}
+	if node == nil {
+		return nil, api.MakeErr(log, codes.NotFound, "agent not found", nil)
+	}
+
+	serialHistory, err := s.ds.FetchAttestedNodeSerialHistory(ctx, id.String())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch agent serial history", err)
+	}
+
+	resp := &extensionv1.GetAgentDetailsResponse{
+		AttestedAt:     node.AttestedAt,
+		LastAttestedAt: node.LastAttestedAt,
+	}
+	for _, serial := range serialHistory {
+		resp.SerialHistory = append(resp.SerialHistory, &extensionv1.AgentSerial{
+			SerialNumber: serial.SerialNumber,
+			SupersededAt: serial.SupersededAt.Unix(),
+			Reason:       serial.Reason,
+		})
+	}
+
+	selectors, err := s.ds.GetNodeSelectors(ctx, id.String(), datastore.RequireCurrent, false)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to get agent selectors", err)
+	}
+	for _, selector := range selectors {
+		if selector.Source == "" {
+			continue
+		}
+		resp.SelectorSources = append(resp.SelectorSources, &extensionv1.AgentSelectorSource{
+			Selector: &types.Selector{Type: selector.Type, Value: selector.Value},
+			Source:   selector.Source,
+		})
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
+
 // AttestAgent attests the authenticity of the given agent.
 func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 	ctx := stream.Context()
@@ -346,11 +582,29 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 		log.WithError(err).Warn("The node attestor produced an invalid agent ID; future releases will enforce that agent IDs are within the reserved agent namesepace for the node attestor")
 	}
 
-	// fetch the agent/node to check if it was already attested or banned
-	attestedNode, err := s.ds.FetchAttestedNode(ctx, agentID.String())
+	// check if the agent/node was already attested, and only fetch it when it
+	// was, to find out if it is banned
+	exists, err := s.ds.AttestedNodeExists(ctx, agentID.String())
 	if err != nil {
 		return api.MakeErr(log, codes.Internal, "failed to fetch agent", err)
 	}
+	var attestedNode *common.AttestedNode
+	if exists {
+		attestedNode, err = s.ds.FetchAttestedNode(ctx, agentID.String())
+		if err != nil {
+			return api.MakeErr(log, codes.Internal, "failed to fetch agent", err)
+		}
+	} else {
+		// An alias of another agent resolves to that agent, and can't be
+		// attested as an agent of its own
+		aliasedNode, err := s.ds.FetchAttestedNode(ctx, agentID.String())
+		if err != nil {
+			return api.MakeErr(log, codes.Internal, "failed to fetch agent", err)
+		}
+		if aliasedNode != nil {
+			return api.MakeErr(log, codes.PermissionDenied, "failed to attest: agent ID is an alias of another agent", nil)
+		}
+	}
 
 	if attestedNode != nil && nodeutil.IsAgentBanned(attestedNode) {
 		return api.MakeErr(log, codes.PermissionDenied, "failed to attest: agent is banned", nil)
@@ -362,8 +616,13 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 		return err
 	}
 
-	// dedupe and store node selectors
-	err = s.ds.SetNodeSelectors(ctx, agentID.String(), selector.Dedupe(attestResult.Selectors))
+	// record which attestor produced the selectors, then dedupe and store them
+	for _, sel := range attestResult.Selectors {
+		sel.Source = params.Data.Type
+	}
+	err = s.ds.SetNodeSelectorsBulk(ctx, map[string][]*common.Selector{
+		agentID.String(): selector.Dedupe(attestResult.Selectors),
+	})
 	if err != nil {
 		return api.MakeErr(log, codes.Internal, "failed to update selectors", err)
 	}
@@ -386,6 +645,7 @@ func (s *Service) AttestAgent(stream agentv1.Agent_AttestAgentServer) error {
 			CertNotAfter:     svid[0].NotAfter.Unix(),
 			CertSerialNumber: svid[0].SerialNumber.String(),
 			CanReattest:      attestResult.CanReattest,
+			LastAttestedAt:   s.clk.Now().Unix(),
 		}
 		if _, err := s.ds.UpdateAttestedNode(ctx, node, nil); err != nil {
 			return api.MakeErr(log, codes.Internal, "failed to update attested agent", err)
@@ -498,9 +758,16 @@ func (s *Service) CreateJoinToken(ctx context.Context, req *agentv1.CreateJoinTo
 		return nil, api.MakeErr(log, codes.InvalidArgument, "ttl is required, you must provide one", nil)
 	}
 
+	uses, err := joinTokenUsesFromContext(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "invalid join token uses", err)
+	}
+	if uses > 1 {
+		rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.Uses: uses})
+	}
+
 	// If provided, check that the AgentID is valid BEFORE creating the join token so we can fail early
 	var agentID spiffeid.ID
-	var err error
 	if req.AgentId != nil {
 		agentID, err = api.TrustDomainWorkloadIDFromProto(ctx, s.td, req.AgentId)
 		if err != nil {
@@ -522,8 +789,9 @@ func (s *Service) CreateJoinToken(ctx context.Context, req *agentv1.CreateJoinTo
 	expiry := s.clk.Now().Add(time.Second * time.Duration(req.Ttl))
 
 	err = s.ds.CreateJoinToken(ctx, &datastore.JoinToken{
-		Token:  req.Token,
-		Expiry: expiry,
+		Token:         req.Token,
+		Expiry:        expiry,
+		RemainingUses: uses,
 	})
 	if err != nil {
 		return nil, api.MakeErr(log, codes.Internal, "failed to create token", err)
@@ -540,6 +808,31 @@ func (s *Service) CreateJoinToken(ctx context.Context, req *agentv1.CreateJoinTo
 	return &types.JoinToken{Value: req.Token, ExpiresAt: expiry.Unix()}, nil
 }
 
+// joinTokenUsesFromContext returns the number of uses of the join token sent
+// in the request metadata, or 1 if there is none.
+func joinTokenUsesFromContext(ctx context.Context) (int, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return 1, nil
+	}
+	values := md.Get(api.JoinTokenUsesMetadataKey)
+	switch len(values) {
+	case 0:
+		return 1, nil
+	case 1:
+	default:
+		return 0, fmt.Errorf("got %d values", len(values))
+	}
+	uses, err := strconv.Atoi(values[0])
+	if err != nil {
+		return 0, err
+	}
+	if uses < 1 {
+		return 0, errors.New("must be at least 1")
+	}
+	return uses, nil
+}
+
 func (s *Service) createJoinTokenRegistrationEntry(ctx context.Context, token string, agentID string) error {
 	parentID, err := joinTokenID(s.td, token)
 	if err != nil {
@@ -589,7 +882,7 @@ func (s *Service) signSvid(ctx context.Context, agentID spiffeid.ID, csr []byte,
 }
 
 func (s *Service) getSelectorsFromAgentID(ctx context.Context, agentID string) ([]*types.Selector, error) {
-	selectors, err := s.ds.GetNodeSelectors(ctx, agentID, datastore.RequireCurrent)
+	selectors, err := s.ds.GetNodeSelectors(ctx, agentID, datastore.RequireCurrent, false)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get node selectors: %w", err)
 	}
@@ -600,18 +893,12 @@ func (s *Service) getSelectorsFromAgentID(ctx context.Context, agentID string) (
 func (s *Service) attestJoinToken(ctx context.Context, token string) (*nodeattestor.AttestResult, error) {
 	log := rpccontext.Logger(ctx).WithField(telemetry.NodeAttestorType, "join_token")
 
-	joinToken, err := s.ds.FetchJoinToken(ctx, token)
+	joinToken, err := s.ds.ConsumeJoinToken(ctx, token)
 	switch {
 	case err != nil:
-		return nil, api.MakeErr(log, codes.Internal, "failed to fetch join token", err)
+		return nil, api.MakeErr(log, codes.Internal, "failed to consume join token", err)
 	case joinToken == nil:
 		return nil, api.MakeErr(log, codes.InvalidArgument, "failed to attest: join token does not exist or has already been used", nil)
-	}
-
-	err = s.ds.DeleteJoinToken(ctx, token)
-	switch {
-	case err != nil:
-		return nil, api.MakeErr(log, codes.Internal, "failed to delete join token", err)
 	case joinToken.Expiry.Before(s.clk.Now()):
 		return nil, api.MakeErr(log, codes.InvalidArgument, "join token expired", nil)
 	}
@@ -773,3 +1060,18 @@ func fieldsFromCountAgentsRequest(filter *agentv1.CountAgentsRequest_Filter) log
 func joinTokenID(td spiffeid.TrustDomain, token string) (spiffeid.ID, error) {
 	return spiffeid.FromSegments(td, "spire", "agent", "join_token", token)
 }
+
+// agentAliasFromProto parses the SPIFFE ID of an agent and an alias of it.
+// The alias must be a member of the trust domain, since the agent presents it
+// as its identity.
+func (s *Service) agentAliasFromProto(ctx context.Context, protoID, protoAlias *types.SPIFFEID) (spiffeid.ID, spiffeid.ID, error) {
+	id, err := api.TrustDomainAgentIDFromProto(ctx, s.td, protoID)
+	if err != nil {
+		return spiffeid.ID{}, spiffeid.ID{}, fmt.Errorf("invalid agent ID: %w", err)
+	}
+	alias, err := api.TrustDomainMemberIDFromProto(ctx, s.td, protoAlias)
+	if err != nil {
+		return spiffeid.ID{}, spiffeid.ID{}, fmt.Errorf("invalid alias: %w", err)
+	}
+	return id, alias, nil
+}
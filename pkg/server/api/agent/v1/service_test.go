@@ -23,6 +23,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -36,6 +37,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -44,6 +46,8 @@ import (
 const (
 	agent1 = "spiffe://example.org/spire/agent/agent-1"
 	agent2 = "spiffe://example.org/spire/agent/agent-2"
+
+	pruneAttestedNodesExpiredFor = 72 * time.Hour
 )
 
 var (
@@ -1173,7 +1177,7 @@ func TestBanAgent(t *testing.T) {
 				NewCertSerialNumber: "1235",
 			}
 
-			_, err := test.ds.CreateAttestedNode(ctx, node)
+			node, err := test.ds.CreateAttestedNode(ctx, node)
 			require.NoError(t, err)
 			test.ds.SetNextError(tt.dsError)
 
@@ -1981,8 +1985,10 @@ func TestRenewAgent(t *testing.T) {
 			test := setupServiceTest(t, tt.agentSVIDTTL)
 			defer test.Cleanup()
 
+			var createdNode *common.AttestedNode
 			if tt.createNode != nil {
-				_, err := test.ds.CreateAttestedNode(ctx, tt.createNode)
+				var err error
+				createdNode, err = test.ds.CreateAttestedNode(ctx, tt.createNode)
 				require.NoError(t, err)
 			}
 			if tt.failSigning {
@@ -2039,7 +2045,7 @@ func TestRenewAgent(t *testing.T) {
 			updatedNode, err := test.ds.FetchAttestedNode(ctx, agentID.String())
 			require.NoError(t, err)
 			require.NotNil(t, updatedNode)
-			expectedNode := tt.createNode
+			expectedNode := createdNode
 			expectedNode.NewCertNotAfter = x509Svid.NotAfter.Unix()
 			expectedNode.NewCertSerialNumber = x509Svid.SerialNumber.String()
 			spiretest.AssertProtoEqual(t, expectedNode, updatedNode)
@@ -2062,8 +2068,10 @@ func TestCreateJoinToken(t *testing.T) {
 	for _, tt := range []struct {
 		name          string
 		request       *agentv1.CreateJoinTokenRequest
+		uses          []string
 		expectLogs    []spiretest.LogEntry
 		expectResults *types.JoinToken
+		expectUses    int
 		err           string
 		code          codes.Code
 		dsError       error
@@ -2084,6 +2092,7 @@ func TestCreateJoinToken(t *testing.T) {
 					},
 				},
 			},
+			expectUses: 1,
 		},
 		{
 			name: "Success Custom Value Join Token",
@@ -2102,6 +2111,85 @@ func TestCreateJoinToken(t *testing.T) {
 					},
 				},
 			},
+			expectUses: 1,
+		},
+		{
+			name: "Success Multiple Uses Join Token",
+			request: &agentv1.CreateJoinTokenRequest{
+				Ttl: 1000,
+			},
+			uses: []string{"3"},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+						telemetry.TTL:    "1000",
+						telemetry.Uses:   "3",
+					},
+				},
+			},
+			expectUses: 3,
+		},
+		{
+			name: "Fail Invalid Uses",
+			request: &agentv1.CreateJoinTokenRequest{
+				Ttl: 1000,
+			},
+			uses: []string{"0"},
+			err:  "invalid join token uses: must be at least 1",
+			code: codes.InvalidArgument,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid join token uses",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "must be at least 1",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "invalid join token uses: must be at least 1",
+						telemetry.TTL:           "1000",
+					},
+				},
+			},
+		},
+		{
+			name: "Fail Malformed Uses",
+			request: &agentv1.CreateJoinTokenRequest{
+				Ttl: 1000,
+			},
+			uses: []string{"many"},
+			err:  "invalid join token uses",
+			code: codes.InvalidArgument,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid join token uses",
+					Data: logrus.Fields{
+						logrus.ErrorKey: `strconv.Atoi: parsing "many": invalid syntax`,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: `invalid join token uses: strconv.Atoi: parsing "many": invalid syntax`,
+						telemetry.TTL:           "1000",
+					},
+				},
+			},
 		},
 		{
 			name: "Fail Negative Ttl",
@@ -2161,7 +2249,12 @@ func TestCreateJoinToken(t *testing.T) {
 			test := setupServiceTest(t, 0)
 			test.ds.SetNextError(tt.dsError)
 
-			result, err := test.client.CreateJoinToken(context.Background(), tt.request)
+			ctx := context.Background()
+			for _, uses := range tt.uses {
+				ctx = metadata.AppendToOutgoingContext(ctx, api.JoinTokenUsesMetadataKey, uses)
+			}
+
+			result, err := test.client.CreateJoinToken(ctx, tt.request)
 			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
 
 			if tt.err != "" {
@@ -2172,6 +2265,10 @@ func TestCreateJoinToken(t *testing.T) {
 			require.NotNil(t, result)
 			require.NotEmpty(t, result.Value)
 			require.NotEmpty(t, result.Value)
+
+			token, err := test.ds.FetchJoinToken(ctx, result.Value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectUses, token.RemainingUses)
 		})
 	}
 }
@@ -2235,67 +2332,65 @@ func TestCreateJoinTokenWithAgentId(t *testing.T) {
 	require.Equal(t, "spiffe://example.org/spire/agent/join_token/"+token.Value, listEntries.Entries[0].Selectors[0].Value)
 }
 
-func TestAttestAgent(t *testing.T) {
-	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testKey)
-	require.NoError(t, err)
-
-	_, expectedCsrErr := x509.ParseCertificateRequest([]byte("not a csr"))
-	require.Error(t, expectedCsrErr)
+func TestSetAgentsCanReattest(t *testing.T) {
+	node1 := &common.AttestedNode{
+		SpiffeId: "spiffe://example.org/spire/agent/node1",
+	}
+	node2 := &common.AttestedNode{
+		SpiffeId:    "spiffe://example.org/spire/agent/node2",
+		CanReattest: true,
+	}
 
 	for _, tt := range []struct {
-		name              string
-		retry             bool
-		request           *agentv1.AttestAgentRequest
-		expectedID        spiffeid.ID
-		expectedSelectors []*common.Selector
-		expectCode        codes.Code
-		expectMsg         string
-		expectLogs        []spiretest.LogEntry
-		rateLimiterErr    error
-		dsError           []error
+		name string
+
+		code        codes.Code
+		dsError     error
+		err         string
+		expectLogs  []spiretest.LogEntry
+		req         *extensionv1.SetAgentsCanReattestRequest
+		expectResp  *extensionv1.SetAgentsCanReattestResponse
+		expectNodes []string
 	}{
 		{
-			name:       "empty request",
-			request:    &agentv1.AttestAgentRequest{},
-			expectCode: codes.InvalidArgument,
-			expectMsg:  "malformed param: missing params",
+			name: "success",
 			expectLogs: []spiretest.LogEntry{
 				{
-					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: malformed param",
+					Level:   logrus.InfoLevel,
+					Message: "Set whether agents can reattest",
 					Data: logrus.Fields{
-						logrus.ErrorKey: "missing params",
+						telemetry.CanReattest: "true",
+						telemetry.Count:       "1",
 					},
 				},
 				{
 					Level:   logrus.InfoLevel,
 					Message: "API accessed",
 					Data: logrus.Fields{
-						telemetry.Status:        "error",
-						telemetry.Type:          "audit",
-						telemetry.StatusCode:    "InvalidArgument",
-						telemetry.StatusMessage: "malformed param: missing params",
+						telemetry.Status:      "success",
+						telemetry.Type:        "audit",
+						telemetry.CanReattest: "true",
+						telemetry.Count:       "1",
 					},
 				},
 			},
-		},
-
-		{
-			name: "empty attestation data",
-			request: &agentv1.AttestAgentRequest{
-				Step: &agentv1.AttestAgentRequest_Params_{
-					Params: &agentv1.AttestAgentRequest_Params{},
+			req: &extensionv1.SetAgentsCanReattestRequest{
+				Ids: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+					{TrustDomain: "example.org", Path: "/spire/agent/node2"},
+					{TrustDomain: "example.org", Path: "/spire/agent/notfound"},
 				},
+				CanReattest: true,
 			},
-			expectCode: codes.InvalidArgument,
-			expectMsg:  "malformed param: missing attestation data",
+			expectResp:  &extensionv1.SetAgentsCanReattestResponse{Updated: 1},
+			expectNodes: []string{node1.SpiffeId, node2.SpiffeId},
+		},
+		{
+			name: "no IDs",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: malformed param",
-					Data: logrus.Fields{
-						logrus.ErrorKey: "missing attestation data",
-					},
+					Message: "Invalid argument: at least one agent ID is required",
 				},
 				{
 					Level:   logrus.InfoLevel,
@@ -2303,32 +2398,25 @@ func TestAttestAgent(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
+						telemetry.CanReattest:   "true",
 						telemetry.StatusCode:    "InvalidArgument",
-						telemetry.StatusMessage: "malformed param: missing attestation data",
+						telemetry.StatusMessage: "at least one agent ID is required",
 					},
 				},
 			},
+			code:        codes.InvalidArgument,
+			err:         "at least one agent ID is required",
+			req:         &extensionv1.SetAgentsCanReattestRequest{CanReattest: true},
+			expectNodes: []string{node2.SpiffeId},
 		},
-
 		{
-			name: "missing parameters",
-			request: &agentv1.AttestAgentRequest{
-				Step: &agentv1.AttestAgentRequest_Params_{
-					Params: &agentv1.AttestAgentRequest_Params{
-						Data: &types.AttestationData{
-							Type: "foo type",
-						},
-					},
-				},
-			},
-			expectCode: codes.InvalidArgument,
-			expectMsg:  "malformed param: missing X509-SVID parameters",
+			name: "not an agent ID",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: malformed param",
+					Message: "Invalid argument: invalid agent ID",
 					Data: logrus.Fields{
-						logrus.ErrorKey: "missing X509-SVID parameters",
+						logrus.ErrorKey: "\"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
 					},
 				},
 				{
@@ -2337,33 +2425,34 @@ func TestAttestAgent(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
+						telemetry.CanReattest:   "true",
 						telemetry.StatusCode:    "InvalidArgument",
-						telemetry.StatusMessage: "malformed param: missing X509-SVID parameters",
+						telemetry.StatusMessage: "invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
 					},
 				},
 			},
-		},
-
-		{
-			name: "missing attestation data type",
-			request: &agentv1.AttestAgentRequest{
-				Step: &agentv1.AttestAgentRequest_Params_{
-					Params: &agentv1.AttestAgentRequest_Params{
-						Data: &types.AttestationData{},
-						Params: &agentv1.AgentX509SVIDParams{
-							Csr: []byte("fake csr"),
-						},
-					},
+			code: codes.InvalidArgument,
+			err:  "invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+			req: &extensionv1.SetAgentsCanReattestRequest{
+				Ids: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+					{TrustDomain: "example.org", Path: "/host"},
 				},
+				CanReattest: true,
 			},
-			expectCode: codes.InvalidArgument,
-			expectMsg:  "malformed param: missing attestation data type",
+			expectNodes: []string{node2.SpiffeId},
+		},
+		{
+			name:    "ds fails",
+			code:    codes.Internal,
+			err:     "failed to set whether agents can reattest: some error",
+			dsError: errors.New("some error"),
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: malformed param",
+					Message: "Failed to set whether agents can reattest",
 					Data: logrus.Fields{
-						logrus.ErrorKey: "missing attestation data type",
+						logrus.ErrorKey: "some error",
 					},
 				},
 				{
@@ -2372,33 +2461,100 @@ func TestAttestAgent(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
-						telemetry.StatusCode:    "InvalidArgument",
-						telemetry.StatusMessage: "malformed param: missing attestation data type",
+						telemetry.CanReattest:   "false",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to set whether agents can reattest: some error",
 					},
 				},
 			},
+			req: &extensionv1.SetAgentsCanReattestRequest{
+				Ids: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/spire/agent/node2"},
+				},
+			},
+			expectNodes: []string{node2.SpiffeId},
 		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			_, err := test.ds.CreateAttestedNode(ctx, node1)
+			require.NoError(t, err)
+			_, err = test.ds.CreateAttestedNode(ctx, node2)
+			require.NoError(t, err)
+			test.ds.SetNextError(tt.dsError)
+
+			resp, err := test.extensionClient.SetAgentsCanReattest(ctx, tt.req)
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.err != "" {
+				require.Nil(t, resp)
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+			} else {
+				require.NoError(t, err)
+				spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+			}
+
+			// Verify which agents can reattest
+			byCanReattest := true
+			listResp, err := test.ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{ByCanReattest: &byCanReattest})
+			require.NoError(t, err)
+			var canReattest []string
+			for _, node := range listResp.Nodes {
+				canReattest = append(canReattest, node.SpiffeId)
+			}
+			require.ElementsMatch(t, tt.expectNodes, canReattest)
+		})
+	}
+}
+
+func TestListAgentAliases(t *testing.T) {
+	node1 := &common.AttestedNode{
+		SpiffeId: "spiffe://example.org/spire/agent/node1",
+	}
+
+	for _, tt := range []struct {
+		name string
 
+		code       codes.Code
+		dsError    error
+		err        string
+		expectLogs []spiretest.LogEntry
+		req        *extensionv1.ListAgentAliasesRequest
+		expectResp *extensionv1.ListAgentAliasesResponse
+	}{
 		{
-			name: "missing csr",
-			request: &agentv1.AttestAgentRequest{
-				Step: &agentv1.AttestAgentRequest_Params_{
-					Params: &agentv1.AttestAgentRequest_Params{
-						Data: &types.AttestationData{
-							Type: "foo type",
-						},
-						Params: &agentv1.AgentX509SVIDParams{},
+			name: "success",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:   "success",
+						telemetry.Type:     "audit",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
 					},
 				},
 			},
-			expectCode: codes.InvalidArgument,
-			expectMsg:  "malformed param: missing CSR",
+			req: &extensionv1.ListAgentAliasesRequest{
+				Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+			},
+			expectResp: &extensionv1.ListAgentAliasesResponse{
+				Aliases: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/node/a"},
+					{TrustDomain: "example.org", Path: "/node/b"},
+				},
+			},
+		},
+		{
+			name: "not found",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Invalid argument: malformed param",
+					Message: "Agent not found",
 					Data: logrus.Fields{
-						logrus.ErrorKey: "missing CSR",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/notfound",
 					},
 				},
 				{
@@ -2407,25 +2563,26 @@ func TestAttestAgent(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
-						telemetry.StatusCode:    "InvalidArgument",
-						telemetry.StatusMessage: "malformed param: missing CSR",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/notfound",
+						telemetry.StatusCode:    "NotFound",
+						telemetry.StatusMessage: "agent not found",
 					},
 				},
 			},
+			code: codes.NotFound,
+			err:  "agent not found",
+			req: &extensionv1.ListAgentAliasesRequest{
+				Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/notfound"},
+			},
 		},
-
 		{
-			name:           "rate limit fails",
-			request:        &agentv1.AttestAgentRequest{},
-			expectCode:     codes.Unknown,
-			expectMsg:      "rate limit fails",
-			rateLimiterErr: status.Error(codes.Unknown, "rate limit fails"),
+			name: "not an agent ID",
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Rejecting request due to attest agent rate limiting",
+					Message: "Invalid argument: invalid agent ID",
 					Data: logrus.Fields{
-						logrus.ErrorKey: "rpc error: code = Unknown desc = rate limit fails",
+						logrus.ErrorKey: "\"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
 					},
 				},
 				{
@@ -2434,9 +2591,961 @@ func TestAttestAgent(t *testing.T) {
 					Data: logrus.Fields{
 						telemetry.Status:        "error",
 						telemetry.Type:          "audit",
-						telemetry.StatusCode:    "Unknown",
-						telemetry.StatusMessage: "rejecting request due to attest agent rate limiting: rate limit fails",
-					},
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+					},
+				},
+			},
+			code: codes.InvalidArgument,
+			err:  "invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+			req: &extensionv1.ListAgentAliasesRequest{
+				Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"},
+			},
+		},
+		{
+			name:    "ds fails",
+			code:    codes.Internal,
+			err:     "failed to fetch agent: some error",
+			dsError: errors.New("some error"),
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to fetch agent",
+					Data: logrus.Fields{
+						logrus.ErrorKey:    "some error",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/node1",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to fetch agent: some error",
+					},
+				},
+			},
+			req: &extensionv1.ListAgentAliasesRequest{
+				Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			_, err := test.ds.CreateAttestedNode(ctx, node1)
+			require.NoError(t, err)
+			require.NoError(t, test.ds.AddAttestedNodeAlias(ctx, node1.SpiffeId, "spiffe://example.org/node/b"))
+			require.NoError(t, test.ds.AddAttestedNodeAlias(ctx, node1.SpiffeId, "spiffe://example.org/node/a"))
+			test.ds.SetNextError(tt.dsError)
+
+			resp, err := test.extensionClient.ListAgentAliases(ctx, tt.req)
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.err != "" {
+				require.Nil(t, resp)
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func TestAddAgentAlias(t *testing.T) {
+	node1 := &common.AttestedNode{
+		SpiffeId: "spiffe://example.org/spire/agent/node1",
+	}
+	node2 := &common.AttestedNode{
+		SpiffeId: "spiffe://example.org/spire/agent/node2",
+	}
+
+	for _, tt := range []struct {
+		name string
+
+		code          codes.Code
+		dsError       error
+		err           string
+		expectLogs    []spiretest.LogEntry
+		req           *extensionv1.AddAgentAliasRequest
+		expectAliases []string
+	}{
+		{
+			name: "success",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Agent alias added",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:    "spiffe://example.org/node/a",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:   "success",
+						telemetry.Type:     "audit",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:    "spiffe://example.org/node/a",
+					},
+				},
+			},
+			req: &extensionv1.AddAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+				Alias: &types.SPIFFEID{TrustDomain: "example.org", Path: "/node/a"},
+			},
+			expectAliases: []string{"spiffe://example.org/node/a"},
+		},
+		{
+			name: "not found",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Agent not found",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/notfound",
+						telemetry.Alias:    "spiffe://example.org/node/a",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/notfound",
+						telemetry.Alias:         "spiffe://example.org/node/a",
+						telemetry.StatusCode:    "NotFound",
+						telemetry.StatusMessage: "agent not found",
+					},
+				},
+			},
+			code: codes.NotFound,
+			err:  "agent not found",
+			req: &extensionv1.AddAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/notfound"},
+				Alias: &types.SPIFFEID{TrustDomain: "example.org", Path: "/node/a"},
+			},
+		},
+		{
+			name: "alias is an agent",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Alias is the SPIFFE ID of an agent",
+					Data: logrus.Fields{
+						logrus.ErrorKey:    "rpc error: code = AlreadyExists desc = datastore-sql: alias \"spiffe://example.org/spire/agent/node2\" is the SPIFFE ID of an attested node",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:    "spiffe://example.org/spire/agent/node2",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:         "spiffe://example.org/spire/agent/node2",
+						telemetry.StatusCode:    "AlreadyExists",
+						telemetry.StatusMessage: "alias is the SPIFFE ID of an agent: datastore-sql: alias \"spiffe://example.org/spire/agent/node2\" is the SPIFFE ID of an attested node",
+					},
+				},
+			},
+			code: codes.AlreadyExists,
+			err:  "alias is the SPIFFE ID of an agent: datastore-sql: alias \"spiffe://example.org/spire/agent/node2\" is the SPIFFE ID of an attested node",
+			req: &extensionv1.AddAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+				Alias: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node2"},
+			},
+		},
+		{
+			name: "alias not member of trust domain",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid agent alias",
+					Data: logrus.Fields{
+						logrus.ErrorKey: `invalid alias: "spiffe://another.org/node/a" is not a member of trust domain "example.org"`,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: `invalid agent alias: invalid alias: "spiffe://another.org/node/a" is not a member of trust domain "example.org"`,
+					},
+				},
+			},
+			code: codes.InvalidArgument,
+			err:  `invalid agent alias: invalid alias: "spiffe://another.org/node/a" is not a member of trust domain "example.org"`,
+			req: &extensionv1.AddAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+				Alias: &types.SPIFFEID{TrustDomain: "another.org", Path: "/node/a"},
+			},
+		},
+		{
+			name:    "ds fails",
+			code:    codes.Internal,
+			err:     "failed to add agent alias: some error",
+			dsError: errors.New("some error"),
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to add agent alias",
+					Data: logrus.Fields{
+						logrus.ErrorKey:    "some error",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:    "spiffe://example.org/node/a",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:         "spiffe://example.org/node/a",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to add agent alias: some error",
+					},
+				},
+			},
+			req: &extensionv1.AddAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+				Alias: &types.SPIFFEID{TrustDomain: "example.org", Path: "/node/a"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			_, err := test.ds.CreateAttestedNode(ctx, node1)
+			require.NoError(t, err)
+			_, err = test.ds.CreateAttestedNode(ctx, node2)
+			require.NoError(t, err)
+			test.ds.SetNextError(tt.dsError)
+
+			resp, err := test.extensionClient.AddAgentAlias(ctx, tt.req)
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.err != "" {
+				require.Nil(t, resp)
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, resp)
+			}
+
+			aliases, err := test.ds.ListAttestedNodeAliases(ctx, node1.SpiffeId)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectAliases, aliases)
+		})
+	}
+}
+
+func TestRemoveAgentAlias(t *testing.T) {
+	node1 := &common.AttestedNode{
+		SpiffeId: "spiffe://example.org/spire/agent/node1",
+	}
+	node2 := &common.AttestedNode{
+		SpiffeId: "spiffe://example.org/spire/agent/node2",
+	}
+
+	for _, tt := range []struct {
+		name string
+
+		code          codes.Code
+		dsError       error
+		err           string
+		expectLogs    []spiretest.LogEntry
+		req           *extensionv1.RemoveAgentAliasRequest
+		expectAliases []string
+	}{
+		{
+			name: "success",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Agent alias removed",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:    "spiffe://example.org/node/a",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:   "success",
+						telemetry.Type:     "audit",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:    "spiffe://example.org/node/a",
+					},
+				},
+			},
+			req: &extensionv1.RemoveAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+				Alias: &types.SPIFFEID{TrustDomain: "example.org", Path: "/node/a"},
+			},
+			expectAliases: []string{"spiffe://example.org/node/b"},
+		},
+		{
+			name: "alias of another agent",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Agent alias not found",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node2",
+						telemetry.Alias:    "spiffe://example.org/node/a",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/node2",
+						telemetry.Alias:         "spiffe://example.org/node/a",
+						telemetry.StatusCode:    "NotFound",
+						telemetry.StatusMessage: "agent alias not found",
+					},
+				},
+			},
+			code: codes.NotFound,
+			err:  "agent alias not found",
+			req: &extensionv1.RemoveAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node2"},
+				Alias: &types.SPIFFEID{TrustDomain: "example.org", Path: "/node/a"},
+			},
+			expectAliases: []string{"spiffe://example.org/node/a", "spiffe://example.org/node/b"},
+		},
+		{
+			name: "not an agent ID",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid agent alias",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "invalid agent alias: invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+					},
+				},
+			},
+			code: codes.InvalidArgument,
+			err:  "invalid agent alias: invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+			req: &extensionv1.RemoveAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"},
+				Alias: &types.SPIFFEID{TrustDomain: "example.org", Path: "/node/a"},
+			},
+			expectAliases: []string{"spiffe://example.org/node/a", "spiffe://example.org/node/b"},
+		},
+		{
+			name:    "ds fails",
+			code:    codes.Internal,
+			err:     "failed to list agent aliases: some error",
+			dsError: errors.New("some error"),
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to list agent aliases",
+					Data: logrus.Fields{
+						logrus.ErrorKey:    "some error",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:    "spiffe://example.org/node/a",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/node1",
+						telemetry.Alias:         "spiffe://example.org/node/a",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to list agent aliases: some error",
+					},
+				},
+			},
+			req: &extensionv1.RemoveAgentAliasRequest{
+				Id:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+				Alias: &types.SPIFFEID{TrustDomain: "example.org", Path: "/node/a"},
+			},
+			expectAliases: []string{"spiffe://example.org/node/a", "spiffe://example.org/node/b"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			_, err := test.ds.CreateAttestedNode(ctx, node1)
+			require.NoError(t, err)
+			_, err = test.ds.CreateAttestedNode(ctx, node2)
+			require.NoError(t, err)
+			require.NoError(t, test.ds.AddAttestedNodeAlias(ctx, node1.SpiffeId, "spiffe://example.org/node/a"))
+			require.NoError(t, test.ds.AddAttestedNodeAlias(ctx, node1.SpiffeId, "spiffe://example.org/node/b"))
+			test.ds.SetNextError(tt.dsError)
+
+			resp, err := test.extensionClient.RemoveAgentAlias(ctx, tt.req)
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.err != "" {
+				require.Nil(t, resp)
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, resp)
+			}
+
+			aliases, err := test.ds.ListAttestedNodeAliases(ctx, node1.SpiffeId)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectAliases, aliases)
+		})
+	}
+}
+
+func TestListAgentsToPrune(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+
+		code       codes.Code
+		dsError    error
+		err        string
+		expectLogs []spiretest.LogEntry
+		req        *extensionv1.ListAgentsToPruneRequest
+		expectResp *extensionv1.ListAgentsToPruneResponse
+	}{
+		{
+			name: "expired for the server setting",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+			req: &extensionv1.ListAgentsToPruneRequest{},
+			expectResp: &extensionv1.ListAgentsToPruneResponse{
+				Ids: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/spire/agent/expired-a-week-ago"},
+				},
+				ExpiredFor: int64(pruneAttestedNodesExpiredFor / time.Second),
+			},
+		},
+		{
+			name: "expired for the requested duration",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+			req: &extensionv1.ListAgentsToPruneRequest{
+				ExpiredFor: int64(time.Minute / time.Second),
+			},
+			expectResp: &extensionv1.ListAgentsToPruneResponse{
+				Ids: []*types.SPIFFEID{
+					{TrustDomain: "example.org", Path: "/spire/agent/expired-a-week-ago"},
+					{TrustDomain: "example.org", Path: "/spire/agent/expired-an-hour-ago"},
+				},
+				ExpiredFor: int64(time.Minute / time.Second),
+			},
+		},
+		{
+			name: "negative duration",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: expired_for cannot be negative",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "expired_for cannot be negative",
+					},
+				},
+			},
+			code: codes.InvalidArgument,
+			err:  "expired_for cannot be negative",
+			req: &extensionv1.ListAgentsToPruneRequest{
+				ExpiredFor: -1,
+			},
+		},
+		{
+			name:    "ds fails",
+			code:    codes.Internal,
+			err:     "failed to list agents to prune: some error",
+			dsError: errors.New("some error"),
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to list agents to prune",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "some error",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to list agents to prune: some error",
+					},
+				},
+			},
+			req: &extensionv1.ListAgentsToPruneRequest{},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			// Agents to prune are listed in the order they were created
+			now := test.clk.Now()
+			for _, node := range []struct {
+				path     string
+				notAfter time.Time
+			}{
+				{path: "/spire/agent/expired-a-week-ago", notAfter: now.Add(-7 * 24 * time.Hour)},
+				{path: "/spire/agent/expired-an-hour-ago", notAfter: now.Add(-time.Hour)},
+				{path: "/spire/agent/valid", notAfter: now.Add(time.Hour)},
+			} {
+				_, err := test.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+					SpiffeId:     spiffeid.RequireFromPath(td, node.path).String(),
+					CertNotAfter: node.notAfter.Unix(),
+				})
+				require.NoError(t, err)
+			}
+			test.ds.SetNextError(tt.dsError)
+
+			resp, err := test.extensionClient.ListAgentsToPrune(ctx, tt.req)
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.err != "" {
+				require.Nil(t, resp)
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func TestGetAgentDetails(t *testing.T) {
+	node1 := &common.AttestedNode{
+		SpiffeId:         "spiffe://example.org/spire/agent/node1",
+		CertSerialNumber: "serial-0",
+	}
+	lastAttestedAt := time.Now().Add(time.Hour)
+
+	for _, tt := range []struct {
+		name string
+
+		code       codes.Code
+		dsError    error
+		err        string
+		expectLogs []spiretest.LogEntry
+		req        *extensionv1.GetAgentDetailsRequest
+		expectResp *extensionv1.GetAgentDetailsResponse
+	}{
+		{
+			name: "success",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:   "success",
+						telemetry.Type:     "audit",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+					},
+				},
+			},
+			req: &extensionv1.GetAgentDetailsRequest{
+				Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+			},
+			expectResp: &extensionv1.GetAgentDetailsResponse{
+				SerialHistory: []*extensionv1.AgentSerial{
+					{SerialNumber: "serial-0", Reason: "rotated"},
+				},
+				SelectorSources: []*extensionv1.AgentSelectorSource{
+					{Selector: &types.Selector{Type: "t", Value: "v1"}, Source: "t"},
+				},
+				LastAttestedAt: lastAttestedAt.Unix(),
+			},
+		},
+		{
+			name: "not found",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Agent not found",
+					Data: logrus.Fields{
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/notfound",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/notfound",
+						telemetry.StatusCode:    "NotFound",
+						telemetry.StatusMessage: "agent not found",
+					},
+				},
+			},
+			code: codes.NotFound,
+			err:  "agent not found",
+			req: &extensionv1.GetAgentDetailsRequest{
+				Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/notfound"},
+			},
+		},
+		{
+			name: "not an agent ID",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: invalid agent ID",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "\"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+					},
+				},
+			},
+			code: codes.InvalidArgument,
+			err:  "invalid agent ID: \"spiffe://example.org/host\" is not an agent in trust domain \"example.org\"; path is not in the agent namespace",
+			req: &extensionv1.GetAgentDetailsRequest{
+				Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"},
+			},
+		},
+		{
+			name:    "ds fails",
+			code:    codes.Internal,
+			err:     "failed to fetch agent: some error",
+			dsError: errors.New("some error"),
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to fetch agent",
+					Data: logrus.Fields{
+						logrus.ErrorKey:    "some error",
+						telemetry.SPIFFEID: "spiffe://example.org/spire/agent/node1",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.SPIFFEID:      "spiffe://example.org/spire/agent/node1",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to fetch agent: some error",
+					},
+				},
+			},
+			req: &extensionv1.GetAgentDetailsRequest{
+				Id: &types.SPIFFEID{TrustDomain: "example.org", Path: "/spire/agent/node1"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, 0)
+			defer test.Cleanup()
+
+			node, err := test.ds.CreateAttestedNode(ctx, node1)
+			require.NoError(t, err)
+			_, err = test.ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+				SpiffeId:            node1.SpiffeId,
+				NewCertSerialNumber: "serial-1",
+				LastAttestedAt:      lastAttestedAt.Unix(),
+			}, &common.AttestedNodeMask{NewCertSerialNumber: true, LastAttestedAt: true})
+			require.NoError(t, err)
+			_, err = test.ds.PromoteAttestedNodeSerial(ctx, node1.SpiffeId, "serial-1")
+			require.NoError(t, err)
+			require.NoError(t, test.ds.SetNodeSelectors(ctx, node1.SpiffeId, []*common.Selector{
+				{Type: "t", Value: "v1", Source: "t"},
+				{Type: "t", Value: "v2"},
+			}))
+			history, err := test.ds.FetchAttestedNodeSerialHistory(ctx, node1.SpiffeId)
+			require.NoError(t, err)
+			test.ds.SetNextError(tt.dsError)
+
+			resp, err := test.extensionClient.GetAgentDetails(ctx, tt.req)
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.err != "" {
+				require.Nil(t, resp)
+				spiretest.RequireGRPCStatus(t, err, tt.code, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			// The datastore sets when the agent first attested and when the
+			// serial numbers were superseded
+			require.NotZero(t, node.AttestedAt)
+			tt.expectResp.AttestedAt = node.AttestedAt
+			require.Len(t, history, len(tt.expectResp.SerialHistory))
+			for i, serial := range history {
+				tt.expectResp.SerialHistory[i].SupersededAt = serial.SupersededAt.Unix()
+			}
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func TestAttestAgent(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testKey)
+	require.NoError(t, err)
+
+	_, expectedCsrErr := x509.ParseCertificateRequest([]byte("not a csr"))
+	require.Error(t, expectedCsrErr)
+
+	for _, tt := range []struct {
+		name              string
+		retry             bool
+		request           *agentv1.AttestAgentRequest
+		expectedID        spiffeid.ID
+		expectedSelectors []*common.Selector
+		expectCode        codes.Code
+		expectMsg         string
+		expectLogs        []spiretest.LogEntry
+		rateLimiterErr    error
+		dsError           []error
+	}{
+		{
+			name:       "empty request",
+			request:    &agentv1.AttestAgentRequest{},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "malformed param: missing params",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: malformed param",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "missing params",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "malformed param: missing params",
+					},
+				},
+			},
+		},
+
+		{
+			name: "empty attestation data",
+			request: &agentv1.AttestAgentRequest{
+				Step: &agentv1.AttestAgentRequest_Params_{
+					Params: &agentv1.AttestAgentRequest_Params{},
+				},
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "malformed param: missing attestation data",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: malformed param",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "missing attestation data",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "malformed param: missing attestation data",
+					},
+				},
+			},
+		},
+
+		{
+			name: "missing parameters",
+			request: &agentv1.AttestAgentRequest{
+				Step: &agentv1.AttestAgentRequest_Params_{
+					Params: &agentv1.AttestAgentRequest_Params{
+						Data: &types.AttestationData{
+							Type: "foo type",
+						},
+					},
+				},
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "malformed param: missing X509-SVID parameters",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: malformed param",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "missing X509-SVID parameters",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "malformed param: missing X509-SVID parameters",
+					},
+				},
+			},
+		},
+
+		{
+			name: "missing attestation data type",
+			request: &agentv1.AttestAgentRequest{
+				Step: &agentv1.AttestAgentRequest_Params_{
+					Params: &agentv1.AttestAgentRequest_Params{
+						Data: &types.AttestationData{},
+						Params: &agentv1.AgentX509SVIDParams{
+							Csr: []byte("fake csr"),
+						},
+					},
+				},
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "malformed param: missing attestation data type",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: malformed param",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "missing attestation data type",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "malformed param: missing attestation data type",
+					},
+				},
+			},
+		},
+
+		{
+			name: "missing csr",
+			request: &agentv1.AttestAgentRequest{
+				Step: &agentv1.AttestAgentRequest_Params_{
+					Params: &agentv1.AttestAgentRequest_Params{
+						Data: &types.AttestationData{
+							Type: "foo type",
+						},
+						Params: &agentv1.AgentX509SVIDParams{},
+					},
+				},
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "malformed param: missing CSR",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: malformed param",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "missing CSR",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "malformed param: missing CSR",
+					},
+				},
+			},
+		},
+
+		{
+			name:           "rate limit fails",
+			request:        &agentv1.AttestAgentRequest{},
+			expectCode:     codes.Unknown,
+			expectMsg:      "rate limit fails",
+			rateLimiterErr: status.Error(codes.Unknown, "rate limit fails"),
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Rejecting request due to attest agent rate limiting",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "rpc error: code = Unknown desc = rate limit fails",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Unknown",
+						telemetry.StatusMessage: "rejecting request due to attest agent rate limiting: rate limit fails",
+					},
 				},
 			},
 		},
@@ -2603,7 +3712,7 @@ func TestAttestAgent(t *testing.T) {
 			request:    getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
 			expectedID: spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_with_result"),
 			expectedSelectors: []*common.Selector{
-				{Type: "test_type", Value: "result"},
+				{Type: "test_type", Value: "result", Source: "test_type"},
 			},
 			expectLogs: []spiretest.LogEntry{
 				{
@@ -2634,7 +3743,7 @@ func TestAttestAgent(t *testing.T) {
 			request:    getAttestAgentRequest("test_type", []byte("payload_with_result"), testCsr),
 			expectedID: spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_with_result"),
 			expectedSelectors: []*common.Selector{
-				{Type: "test_type", Value: "result"},
+				{Type: "test_type", Value: "result", Source: "test_type"},
 			},
 			expectLogs: []spiretest.LogEntry{
 				{
@@ -2683,7 +3792,7 @@ func TestAttestAgent(t *testing.T) {
 			request:    getAttestAgentRequest("test_type", []byte("payload_with_challenge"), testCsr),
 			expectedID: spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_with_challenge"),
 			expectedSelectors: []*common.Selector{
-				{Type: "test_type", Value: "challenge"},
+				{Type: "test_type", Value: "challenge", Source: "test_type"},
 			},
 			expectLogs: []spiretest.LogEntry{
 				{
@@ -2713,7 +3822,7 @@ func TestAttestAgent(t *testing.T) {
 			request:    getAttestAgentRequest("test_type", []byte("payload_attested_before"), testCsr),
 			expectedID: spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_attested_before"),
 			expectedSelectors: []*common.Selector{
-				{Type: "test_type", Value: "attested_before"},
+				{Type: "test_type", Value: "attested_before", Source: "test_type"},
 			},
 			expectLogs: []spiretest.LogEntry{
 				{
@@ -2767,6 +3876,35 @@ func TestAttestAgent(t *testing.T) {
 			},
 		},
 
+		{
+			name:       "attest alias of another agent",
+			request:    getAttestAgentRequest("test_type", []byte("payload_alias"), testCsr),
+			expectCode: codes.PermissionDenied,
+			expectMsg:  "failed to attest: agent ID is an alias of another agent",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to attest: agent ID is an alias of another agent",
+					Data: logrus.Fields{
+						telemetry.NodeAttestorType: "test_type",
+						telemetry.AgentID:          spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_alias").String(),
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.StatusCode:       "PermissionDenied",
+						telemetry.StatusMessage:    "failed to attest: agent ID is an alias of another agent",
+						telemetry.AgentID:          "spiffe://example.org/spire/agent/test_type/id_alias",
+						telemetry.NodeAttestorType: "test_type",
+					},
+				},
+			},
+		},
+
 		{
 			name:       "attest with bad attestor",
 			request:    getAttestAgentRequest("bad_type", []byte("payload_with_result"), testCsr),
@@ -2826,17 +3964,17 @@ func TestAttestAgent(t *testing.T) {
 		},
 
 		{
-			name:       "ds: fails to fetch join token",
+			name:       "ds: fails to consume join token",
 			request:    getAttestAgentRequest("join_token", []byte("test_token"), testCsr),
 			expectCode: codes.Internal,
-			expectMsg:  "failed to fetch join token",
+			expectMsg:  "failed to consume join token",
 			dsError: []error{
 				errors.New("some error"),
 			},
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Failed to fetch join token",
+					Message: "Failed to consume join token",
 					Data: logrus.Fields{
 						telemetry.NodeAttestorType: "join_token",
 						logrus.ErrorKey:            "some error",
@@ -2849,7 +3987,7 @@ func TestAttestAgent(t *testing.T) {
 						telemetry.Status:           "error",
 						telemetry.Type:             "audit",
 						telemetry.StatusCode:       "Internal",
-						telemetry.StatusMessage:    "failed to fetch join token: some error",
+						telemetry.StatusMessage:    "failed to consume join token: some error",
 						telemetry.NodeAttestorType: "join_token",
 					},
 				},
@@ -2857,10 +3995,10 @@ func TestAttestAgent(t *testing.T) {
 		},
 
 		{
-			name:       "ds: fails to delete join token",
+			name:       "ds: fails to fetch agent",
 			request:    getAttestAgentRequest("join_token", []byte("test_token"), testCsr),
 			expectCode: codes.Internal,
-			expectMsg:  "failed to delete join token",
+			expectMsg:  "failed to fetch agent",
 			dsError: []error{
 				nil,
 				errors.New("some error"),
@@ -2868,10 +4006,11 @@ func TestAttestAgent(t *testing.T) {
 			expectLogs: []spiretest.LogEntry{
 				{
 					Level:   logrus.ErrorLevel,
-					Message: "Failed to delete join token",
+					Message: "Failed to fetch agent",
 					Data: logrus.Fields{
 						telemetry.NodeAttestorType: "join_token",
 						logrus.ErrorKey:            "some error",
+						telemetry.AgentID:          spiffeid.RequireFromPath(td, "/spire/agent/join_token/test_token").String(),
 					},
 				},
 				{
@@ -2881,7 +4020,8 @@ func TestAttestAgent(t *testing.T) {
 						telemetry.Status:           "error",
 						telemetry.Type:             "audit",
 						telemetry.StatusCode:       "Internal",
-						telemetry.StatusMessage:    "failed to delete join token: some error",
+						telemetry.StatusMessage:    "failed to fetch agent: some error",
+						telemetry.AgentID:          "spiffe://example.org/spire/agent/join_token/test_token",
 						telemetry.NodeAttestorType: "join_token",
 					},
 				},
@@ -2889,7 +4029,40 @@ func TestAttestAgent(t *testing.T) {
 		},
 
 		{
-			name:       "ds: fails to fetch agent",
+			name:       "ds: fails to fetch attested agent",
+			request:    getAttestAgentRequest("test_type", []byte("payload_attested_before"), testCsr),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to fetch agent",
+			dsError: []error{
+				nil,
+				errors.New("some error"),
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to fetch agent",
+					Data: logrus.Fields{
+						telemetry.NodeAttestorType: "test_type",
+						logrus.ErrorKey:            "some error",
+						telemetry.AgentID:          spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_attested_before").String(),
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:           "error",
+						telemetry.Type:             "audit",
+						telemetry.StatusCode:       "Internal",
+						telemetry.StatusMessage:    "failed to fetch agent: some error",
+						telemetry.AgentID:          "spiffe://example.org/spire/agent/test_type/id_attested_before",
+						telemetry.NodeAttestorType: "test_type",
+					},
+				},
+			},
+		},
+		{
+			name:       "ds: fails to fetch aliased agent",
 			request:    getAttestAgentRequest("join_token", []byte("test_token"), testCsr),
 			expectCode: codes.Internal,
 			expectMsg:  "failed to fetch agent",
@@ -2922,7 +4095,6 @@ func TestAttestAgent(t *testing.T) {
 				},
 			},
 		},
-
 		{
 			name:       "ds: fails to update selectors",
 			request:    getAttestAgentRequest("join_token", []byte("test_token"), testCsr),
@@ -3002,6 +4174,7 @@ func TestAttestAgent(t *testing.T) {
 			expectCode: codes.Internal,
 			expectMsg:  "failed to update attested agent",
 			dsError: []error{
+				nil,
 				nil,
 				nil,
 				errors.New("some error"),
@@ -3098,10 +4271,10 @@ func TestAttestAgent(t *testing.T) {
 			request:    getAttestAgentRequest("test_type", []byte("payload_selector_dups"), testCsr),
 			expectedID: spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_selector_dups"),
 			expectedSelectors: []*common.Selector{
-				{Type: "test_type", Value: "A"},
-				{Type: "test_type", Value: "B"},
-				{Type: "test_type", Value: "C"},
-				{Type: "test_type", Value: "D"},
+				{Type: "test_type", Value: "A", Source: "test_type"},
+				{Type: "test_type", Value: "B", Source: "test_type"},
+				{Type: "test_type", Value: "C", Source: "test_type"},
+				{Type: "test_type", Value: "D", Source: "test_type"},
 			},
 			expectLogs: []spiretest.LogEntry{
 				{
@@ -3194,17 +4367,53 @@ func TestAttestAgent(t *testing.T) {
 	}
 }
 
+func TestAttestAgentRecordsReattestation(t *testing.T) {
+	testCsr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{}, testKey)
+	require.NoError(t, err)
+
+	test := setupServiceTest(t, 0)
+	defer test.Cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	test.setupAttestor(t)
+	test.setupNodes(ctx, t)
+	test.rateLimiter.count = 1
+
+	agentID := spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_attested_before").String()
+	node, err := test.ds.FetchAttestedNode(ctx, agentID)
+	require.NoError(t, err)
+	require.NotNil(t, node)
+
+	test.clk.(*clock.Mock).Add(time.Hour)
+
+	stream, err := test.client.AttestAgent(ctx)
+	require.NoError(t, err)
+	_, err = attest(t, stream, getAttestAgentRequest("test_type", []byte("payload_attested_before"), testCsr))
+	require.NoError(t, err)
+	require.NoError(t, stream.CloseSend())
+
+	// The agent first attested when the node was created
+	reattested, err := test.ds.FetchAttestedNode(ctx, agentID)
+	require.NoError(t, err)
+	require.NotNil(t, reattested)
+	require.Equal(t, node.AttestedAt, reattested.AttestedAt)
+	require.Equal(t, test.clk.Now().Unix(), reattested.LastAttestedAt)
+}
+
 type serviceTest struct {
-	client       agentv1.AgentClient
-	done         func()
-	ds           *fakedatastore.DataStore
-	ca           *fakeserverca.CA
-	cat          *fakeservercatalog.Catalog
-	clk          clock.Clock
-	logHook      *test.Hook
-	rateLimiter  *fakeRateLimiter
-	withCallerID bool
-	pluginCloser func()
+	client          agentv1.AgentClient
+	extensionClient extensionv1.AgentExtensionClient
+	done            func()
+	ds              *fakedatastore.DataStore
+	ca              *fakeserverca.CA
+	cat             *fakeservercatalog.Catalog
+	clk             clock.Clock
+	logHook         *test.Hook
+	rateLimiter     *fakeRateLimiter
+	withCallerID    bool
+	pluginCloser    func()
 }
 
 func (s *serviceTest) Cleanup() {
@@ -3223,11 +4432,12 @@ func setupServiceTest(t *testing.T, agentSVIDTTL time.Duration) *serviceTest {
 	clk := clock.NewMock(t)
 
 	service := agent.New(agent.Config{
-		ServerCA:    ca,
-		DataStore:   ds,
-		TrustDomain: td,
-		Clock:       clk,
-		Catalog:     cat,
+		ServerCA:                     ca,
+		DataStore:                    ds,
+		TrustDomain:                  td,
+		Clock:                        clk,
+		Catalog:                      cat,
+		PruneAttestedNodesExpiredFor: pruneAttestedNodesExpiredFor,
 	})
 
 	log, logHook := test.NewNullLogger()
@@ -3263,6 +4473,7 @@ func setupServiceTest(t *testing.T, agentSVIDTTL time.Duration) *serviceTest {
 	conn := server.NewGRPCClient(t)
 
 	test.client = agentv1.NewAgentClient(conn)
+	test.extensionClient = extensionv1.NewAgentExtensionClient(conn)
 	test.done = server.Stop
 
 	return test
@@ -3279,6 +4490,7 @@ func (s *serviceTest) setupAttestor(t *testing.T) {
 			"payload_return_server_id":            "spiffe://example.org/spire/server",
 			"payload_return_id_outside_namespace": "spiffe://example.org/id_outside_namespace",
 			"payload_selector_dups":               "spiffe://example.org/spire/agent/test_type/id_selector_dups",
+			"payload_alias":                       "spiffe://example.org/spire/agent/test_type/id_alias",
 		},
 		Selectors: map[string][]string{
 			"spiffe://example.org/spire/agent/test_type/id_with_result":     {"result"},
@@ -3286,6 +4498,7 @@ func (s *serviceTest) setupAttestor(t *testing.T) {
 			"spiffe://example.org/spire/agent/test_type/id_with_challenge":  {"challenge"},
 			"spiffe://example.org/spire/agent/test_type/id_banned":          {"banned"},
 			"spiffe://example.org/spire/agent/test_type/id_selector_dups":   {"A", "B", "C", "A", "D"},
+			"spiffe://example.org/spire/agent/test_type/id_alias":           {"alias"},
 		},
 		Challenges: map[string][]string{
 			"id_with_challenge": {"challenge_response"},
@@ -3304,6 +4517,8 @@ func (s *serviceTest) setupNodes(ctx context.Context, t *testing.T) {
 	}
 	_, err := s.ds.CreateAttestedNode(ctx, node)
 	require.NoError(t, err)
+	err = s.ds.AddAttestedNodeAlias(ctx, node.SpiffeId, spiffeid.RequireFromPath(td, "/spire/agent/test_type/id_alias").String())
+	require.NoError(t, err)
 
 	node = &common.AttestedNode{
 		AttestationDataType: "test_type",
@@ -3381,7 +4596,7 @@ func (s *serviceTest) assertAgentWasStored(t *testing.T, expectedID string, expe
 	require.NotNil(t, attestedAgent)
 	require.Equal(t, expectedID, attestedAgent.SpiffeId)
 
-	agentSelectors, err := s.ds.GetNodeSelectors(ctx, expectedID, datastore.RequireCurrent)
+	agentSelectors, err := s.ds.GetNodeSelectors(ctx, expectedID, datastore.RequireCurrent, false)
 	require.NoError(t, err)
 	require.EqualValues(t, expectedSelectors, agentSelectors)
 }
@@ -3,7 +3,9 @@ package bundle
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	bundlev1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/bundle/v1"
@@ -13,12 +15,17 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// pruneAuthoritiesExpiredFor is how long ago authorities must have expired
+// for the CA manager to prune them from the bundle.
+const pruneAuthoritiesExpiredFor = 24 * time.Hour
+
 // UpstreamPublisher defines the publisher interface.
 type UpstreamPublisher interface {
 	PublishJWTKey(ctx context.Context, jwtKey *common.PublicKey) ([]*common.PublicKey, error)
@@ -37,29 +44,41 @@ type Config struct {
 	DataStore         datastore.DataStore
 	TrustDomain       spiffeid.TrustDomain
 	UpstreamPublisher UpstreamPublisher
+
+	// Clock is used to tell which authorities have expired. Defaults to the
+	// real clock.
+	Clock clock.Clock
 }
 
 // Service defines the v1 bundle service properties.
 type Service struct {
 	bundlev1.UnsafeBundleServer
+	extensionv1.UnsafeBundleExtensionServer
 
-	ds datastore.DataStore
-	td spiffeid.TrustDomain
-	up UpstreamPublisher
+	ds  datastore.DataStore
+	td  spiffeid.TrustDomain
+	up  UpstreamPublisher
+	clk clock.Clock
 }
 
 // New creates a new bundle service.
 func New(config Config) *Service {
+	if config.Clock == nil {
+		config.Clock = clock.New()
+	}
 	return &Service{
-		ds: config.DataStore,
-		td: config.TrustDomain,
-		up: config.UpstreamPublisher,
+		ds:  config.DataStore,
+		td:  config.TrustDomain,
+		up:  config.UpstreamPublisher,
+		clk: config.Clock,
 	}
 }
 
-// RegisterService registers the bundle service on the gRPC server.
+// RegisterService registers the bundle service, along with its extension, on
+// the gRPC server.
 func RegisterService(s grpc.ServiceRegistrar, service *Service) {
 	bundlev1.RegisterBundleServer(s, service)
+	extensionv1.RegisterBundleExtensionServer(s, service)
 }
 
 // CountBundles returns the total number of bundles.
@@ -193,14 +212,13 @@ func (s *Service) PublishJWTAuthority(ctx context.Context, req *bundlev1.Publish
 func (s *Service) ListFederatedBundles(ctx context.Context, req *bundlev1.ListFederatedBundlesRequest) (*bundlev1.ListFederatedBundlesResponse, error) {
 	log := rpccontext.Logger(ctx)
 
-	listReq := &datastore.ListBundlesRequest{}
-
-	// Set pagination parameters
-	if req.PageSize > 0 {
-		listReq.Pagination = &datastore.Pagination{
+	// Set pagination parameters. A zero page size is capped to the maximum
+	// page size by the datastore, so the list is never unbounded.
+	listReq := &datastore.ListBundlesRequest{
+		Pagination: &datastore.Pagination{
 			PageSize: req.PageSize,
 			Token:    req.PageToken,
-		}
+		},
 	}
 
 	dsResp, err := s.ds.ListBundles(ctx, listReq)
@@ -211,7 +229,8 @@ func (s *Service) ListFederatedBundles(ctx context.Context, req *bundlev1.ListFe
 	resp := &bundlev1.ListFederatedBundlesResponse{}
 
 	if dsResp.Pagination != nil {
-		resp.NextPageToken = dsResp.Pagination.Token
+		resp.NextPageToken = api.NextPageToken(req.PageSize, dsResp.Pagination, len(dsResp.Bundles))
+		api.SetPageSizeHeader(ctx, log, dsResp.Pagination.PageSize)
 	}
 
 	for _, commonBundle := range dsResp.Bundles {
@@ -573,3 +592,57 @@ func applyBundleMask(b *types.Bundle, mask *types.BundleMask) {
 		b.JwtAuthorities = nil
 	}
 }
+
+// ListBundleAuthoritiesToPrune lists the authorities that pruning the bundle
+// of the server trust domain would remove, without removing them.
+func (s *Service) ListBundleAuthoritiesToPrune(ctx context.Context, _ *extensionv1.ListBundleAuthoritiesToPruneRequest) (*extensionv1.ListBundleAuthoritiesToPruneResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	authorities, err := s.ds.ListBundleAuthoritiesToPrune(ctx, s.td.IDString(), s.clk.Now().Add(-pruneAuthoritiesExpiredFor))
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list bundle authorities to prune", err)
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return &extensionv1.ListBundleAuthoritiesToPruneResponse{
+		TrustDomain:      s.td.Name(),
+		X509AuthorityIds: authorities.X509AuthorityIDs,
+		JwtAuthorityIds:  authorities.JWTAuthorityIDs,
+	}, nil
+}
+
+// ListFederatedBundleRefreshTimes lists when each federated bundle was last
+// refreshed from its bundle endpoint.
+func (s *Service) ListFederatedBundleRefreshTimes(ctx context.Context, _ *extensionv1.ListFederatedBundleRefreshTimesRequest) (*extensionv1.ListFederatedBundleRefreshTimesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	dsResp, err := s.ds.ListBundles(ctx, &datastore.ListBundlesRequest{ExcludeData: true})
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list bundles", err)
+	}
+
+	resp := &extensionv1.ListFederatedBundleRefreshTimesResponse{}
+	for _, metadata := range dsResp.Metadata {
+		td, err := spiffeid.TrustDomainFromString(metadata.TrustDomainID)
+		if err != nil {
+			return nil, api.MakeErr(log.WithField(telemetry.TrustDomainID, metadata.TrustDomainID), codes.Internal, "bundle has an invalid trust domain ID", err)
+		}
+
+		// Filter server bundle
+		if s.td.Compare(td) == 0 {
+			continue
+		}
+
+		var lastRefreshedAt int64
+		if !metadata.LastRefreshedAt.IsZero() {
+			lastRefreshedAt = metadata.LastRefreshedAt.Unix()
+		}
+		resp.Bundles = append(resp.Bundles, &extensionv1.BundleRefreshTime{
+			TrustDomain:     td.Name(),
+			LastRefreshedAt: lastRefreshedAt,
+		})
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
@@ -20,11 +20,13 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/jwtutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/bundle/v1"
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/grpctest"
@@ -1733,6 +1735,205 @@ func createBundle(t *testing.T, test *serviceTest, td string) *common.Bundle {
 	return b
 }
 
+func TestListBundleAuthoritiesToPrune(t *testing.T) {
+	// The X.509 authority of bundleBytes expired long ago, while the one of
+	// the test CA is still valid.
+	expiredBundle, err := spiffebundle.Parse(serverTrustDomain, bundleBytes)
+	require.NoError(t, err)
+	expiredRootCA := expiredBundle.X509Authorities()[0]
+	rootCA := testca.New(t, serverTrustDomain).X509Authorities()[0]
+
+	now := time.Now()
+	serverBundle := &common.Bundle{
+		TrustDomainId: serverTrustDomain.IDString(),
+		RootCas: []*common.Certificate{
+			{DerBytes: expiredRootCA.Raw},
+			{DerBytes: rootCA.Raw},
+		},
+		JwtSigningKeys: []*common.PublicKey{
+			{Kid: "expired-a-while-ago", NotAfter: now.Add(-48 * time.Hour).Unix(), PkixBytes: []byte("key-1")},
+			{Kid: "just-expired", NotAfter: now.Add(-time.Hour).Unix(), PkixBytes: []byte("key-2")},
+			{Kid: "valid", NotAfter: now.Add(time.Hour).Unix(), PkixBytes: []byte("key-3")},
+		},
+	}
+
+	for _, tt := range []struct {
+		name       string
+		bundle     *common.Bundle
+		dsError    error
+		expectResp *extensionv1.ListBundleAuthoritiesToPruneResponse
+		expectCode codes.Code
+		expectMsg  string
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			name:   "authorities expired for a day",
+			bundle: serverBundle,
+			expectResp: &extensionv1.ListBundleAuthoritiesToPruneResponse{
+				TrustDomain:      serverTrustDomain.Name(),
+				X509AuthorityIds: []string{x509util.SubjectKeyIDToString(expiredRootCA.SubjectKeyId)},
+				JwtAuthorityIds:  []string{"expired-a-while-ago"},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "no bundle",
+			expectResp: &extensionv1.ListBundleAuthoritiesToPruneResponse{
+				TrustDomain: serverTrustDomain.Name(),
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:       "ds error",
+			bundle:     serverBundle,
+			dsError:    errors.New("oh no"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to list bundle authorities to prune: oh no",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to list bundle authorities to prune",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "oh no",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to list bundle authorities to prune: oh no",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+
+			if tt.bundle != nil {
+				test.setBundle(t, tt.bundle)
+			}
+
+			test.ds.SetNextError(tt.dsError)
+			resp, err := test.extensionClient.ListBundleAuthoritiesToPrune(ctx, &extensionv1.ListBundleAuthoritiesToPruneRequest{})
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func TestListFederatedBundleRefreshTimes(t *testing.T) {
+	refreshedAt := time.Unix(1700000000, 0)
+	refreshedTD := spiffeid.RequireTrustDomainFromString("refreshed.org")
+
+	for _, tt := range []struct {
+		name       string
+		dsError    error
+		expectResp *extensionv1.ListFederatedBundleRefreshTimesResponse
+		expectCode codes.Code
+		expectMsg  string
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			name: "success",
+			expectResp: &extensionv1.ListFederatedBundleRefreshTimesResponse{
+				Bundles: []*extensionv1.BundleRefreshTime{
+					{TrustDomain: federatedTrustDomain.Name()},
+					{TrustDomain: refreshedTD.Name(), LastRefreshedAt: refreshedAt.Unix()},
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:       "ds error",
+			dsError:    errors.New("oh no"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to list bundles: oh no",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to list bundles",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "oh no",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to list bundles: oh no",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t)
+			defer test.Cleanup()
+
+			createBundle(t, test, serverTrustDomain.IDString())
+			createBundle(t, test, federatedTrustDomain.IDString())
+			createBundle(t, test, refreshedTD.IDString())
+			require.NoError(t, test.ds.SetBundleRefreshedAt(ctx, refreshedTD.IDString(), refreshedAt))
+
+			test.ds.SetNextError(tt.dsError)
+			resp, err := test.extensionClient.ListFederatedBundleRefreshTimes(ctx, &extensionv1.ListFederatedBundleRefreshTimesRequest{})
+
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectCode != codes.OK {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
 func TestBatchCreateFederatedBundle(t *testing.T) {
 	test := setupServiceTest(t)
 	defer test.Cleanup()
@@ -2951,15 +3152,16 @@ func (c *serviceTest) setBundle(t *testing.T, b *common.Bundle) {
 }
 
 type serviceTest struct {
-	client      bundlev1.BundleClient
-	ds          *fakedatastore.DataStore
-	logHook     *test.Hook
-	up          *fakeUpstreamPublisher
-	rateLimiter *fakeRateLimiter
-	done        func()
-	isAdmin     bool
-	isAgent     bool
-	isLocal     bool
+	client          bundlev1.BundleClient
+	extensionClient extensionv1.BundleExtensionClient
+	ds              *fakedatastore.DataStore
+	logHook         *test.Hook
+	up              *fakeUpstreamPublisher
+	rateLimiter     *fakeRateLimiter
+	done            func()
+	isAdmin         bool
+	isAgent         bool
+	isLocal         bool
 }
 
 func (c *serviceTest) Cleanup() {
@@ -3015,6 +3217,7 @@ func setupServiceTest(t *testing.T) *serviceTest {
 	conn := server.NewGRPCClient(t)
 
 	test.client = bundlev1.NewBundleClient(conn)
+	test.extensionClient = extensionv1.NewBundleExtensionClient(conn)
 	test.done = server.Stop
 
 	return test
@@ -14,6 +14,39 @@ import (
 
 const (
 	hintMaximumLength = 1024
+
+	// IdempotencyKeyMetadataKey is the gRPC metadata key holding the
+	// idempotency keys of the entries passed to BatchCreateEntry, one value
+	// per entry in request order. An empty value means the entry has no key.
+	// types.Entry has no field for the key, so it travels as metadata.
+	IdempotencyKeyMetadataKey = "spire-entry-idempotency-key"
+
+	// EffectiveX509SVIDTTLMetadataKey and EffectiveJWTSVIDTTLMetadataKey are
+	// the gRPC response header keys holding the TTLs, in seconds, that the
+	// server gives to the X509-SVIDs and JWT-SVIDs of the entries returned by
+	// BatchCreateEntry, one value per entry in request order. The TTL of an
+	// entry is the server default when the entry doesn't set one, capped to
+	// the maximum TTL the server CA guarantees. An empty value means no entry
+	// was returned. BatchCreateEntryResponse has no field for the TTLs, so
+	// they travel as metadata.
+	EffectiveX509SVIDTTLMetadataKey = "spire-entry-effective-x509-svid-ttl"
+	EffectiveJWTSVIDTTLMetadataKey  = "spire-entry-effective-jwt-svid-ttl"
+
+	// EntryActiveMetadataKey is the gRPC metadata key holding whether the
+	// entries passed to BatchUpdateEntry are active, one value per entry in
+	// request order: "true" activates the entry, "false" deactivates it and
+	// an empty value leaves it as it is. types.Entry has no field for the
+	// state, so it travels as metadata.
+	EntryActiveMetadataKey = "spire-entry-active"
+
+	// EntryFederatesWithAllMetadataKey is the gRPC metadata key holding
+	// whether the entries passed to BatchUpdateEntry federate with all the
+	// trust domains known to the server, one value per entry in request
+	// order: "true" or "false", or an empty value to leave it as it is. An
+	// entry that stops federating with all of them keeps the current ones,
+	// unless the update sets its federated trust domains. types.Entry has no
+	// field for it, so it travels as metadata.
+	EntryFederatesWithAllMetadataKey = "spire-entry-federates-with-all"
 )
 
 // RegistrationEntriesToProto converts RegistrationEntry's into Entry's
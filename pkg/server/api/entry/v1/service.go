@@ -3,23 +3,32 @@ package entry
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	entryv1 "github.com/spiffe/spire-api-sdk/proto/spire/api/server/entry/v1"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/protoutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
+	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
 )
 
 const defaultEntryPageSize = 500
@@ -30,16 +39,38 @@ type Config struct {
 	EntryFetcher  api.AuthorizedEntryFetcher
 	DataStore     datastore.DataStore
 	EntryPageSize int
+
+	// DefaultX509SVIDTTL and DefaultJWTSVIDTTL are the TTLs given to the
+	// SVIDs of entries that don't set their own. If zero, the built-in
+	// defaults are used.
+	DefaultX509SVIDTTL time.Duration
+	DefaultJWTSVIDTTL  time.Duration
+
+	// MaxSVIDTTL is the maximum SVID TTL that the server CA guarantees given
+	// its TTL. It caps the effective TTLs reported when creating entries. If
+	// zero, they are not capped.
+	MaxSVIDTTL time.Duration
+
+	// Clock is used to tell which entries have expired. Defaults to the
+	// real clock.
+	Clock clock.Clock
 }
 
 // Service defines the v1 entry service.
 type Service struct {
 	entryv1.UnsafeEntryServer
+	extensionv1.UnsafeEntryExtensionServer
 
 	td            spiffeid.TrustDomain
 	ds            datastore.DataStore
 	ef            api.AuthorizedEntryFetcher
 	entryPageSize int
+
+	defaultX509SVIDTTL time.Duration
+	defaultJWTSVIDTTL  time.Duration
+	maxSVIDTTL         time.Duration
+
+	clk clock.Clock
 }
 
 // New creates a new v1 entry service.
@@ -47,17 +78,32 @@ func New(config Config) *Service {
 	if config.EntryPageSize == 0 {
 		config.EntryPageSize = defaultEntryPageSize
 	}
+	if config.DefaultX509SVIDTTL == 0 {
+		config.DefaultX509SVIDTTL = credtemplate.DefaultX509SVIDTTL
+	}
+	if config.DefaultJWTSVIDTTL == 0 {
+		config.DefaultJWTSVIDTTL = credtemplate.DefaultJWTSVIDTTL
+	}
+	if config.Clock == nil {
+		config.Clock = clock.New()
+	}
 	return &Service{
-		td:            config.TrustDomain,
-		ds:            config.DataStore,
-		ef:            config.EntryFetcher,
-		entryPageSize: config.EntryPageSize,
+		td:                 config.TrustDomain,
+		ds:                 config.DataStore,
+		ef:                 config.EntryFetcher,
+		entryPageSize:      config.EntryPageSize,
+		defaultX509SVIDTTL: config.DefaultX509SVIDTTL,
+		defaultJWTSVIDTTL:  config.DefaultJWTSVIDTTL,
+		maxSVIDTTL:         config.MaxSVIDTTL,
+		clk:                config.Clock,
 	}
 }
 
-// RegisterService registers the entry service on the gRPC server.
+// RegisterService registers the entry service, along with its extension, on
+// the gRPC server.
 func RegisterService(s grpc.ServiceRegistrar, service *Service) {
 	entryv1.RegisterEntryServer(s, service)
+	extensionv1.RegisterEntryExtensionServer(s, service)
 }
 
 // CountEntries returns the total number of entries.
@@ -68,7 +114,7 @@ func (s *Service) CountEntries(ctx context.Context, req *entryv1.CountEntriesReq
 	if req.Filter != nil {
 		rpccontext.AddRPCAuditFields(ctx, fieldsFromCountEntryFilter(ctx, s.td, req.Filter))
 		if req.Filter.ByHint != nil {
-			countReq.ByHint = req.Filter.ByHint.GetValue()
+			countReq.ByHint = &req.Filter.ByHint.Value
 		}
 
 		if req.Filter.ByParentId != nil {
@@ -119,7 +165,9 @@ func (s *Service) CountEntries(ctx context.Context, req *entryv1.CountEntriesReq
 			}
 		}
 
-		if req.Filter.ByDownstream != nil {
+		// A false downstream filter matches all the entries, as it always
+		// has in this API, so only a true filter is passed down.
+		if req.Filter.ByDownstream.GetValue() {
 			countReq.ByDownstream = &req.Filter.ByDownstream.Value
 		}
 	}
@@ -138,72 +186,20 @@ func (s *Service) CountEntries(ctx context.Context, req *entryv1.CountEntriesReq
 func (s *Service) ListEntries(ctx context.Context, req *entryv1.ListEntriesRequest) (*entryv1.ListEntriesResponse, error) {
 	log := rpccontext.Logger(ctx)
 
-	listReq := &datastore.ListRegistrationEntriesRequest{}
-
-	if req.PageSize > 0 {
-		listReq.Pagination = &datastore.Pagination{
+	listReq := &datastore.ListRegistrationEntriesRequest{
+		// A zero page size is capped to the maximum page size by the
+		// datastore, so the list is never unbounded.
+		Pagination: &datastore.Pagination{
 			PageSize: req.PageSize,
 			Token:    req.PageToken,
-		}
+		},
 	}
 
 	if req.Filter != nil {
 		rpccontext.AddRPCAuditFields(ctx, fieldsFromListEntryFilter(ctx, s.td, req.Filter))
 
-		if req.Filter.ByHint != nil {
-			listReq.ByHint = req.Filter.ByHint.GetValue()
-		}
-
-		if req.Filter.ByParentId != nil {
-			parentID, err := api.TrustDomainMemberIDFromProto(ctx, s.td, req.Filter.ByParentId)
-			if err != nil {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed parent ID filter", err)
-			}
-			listReq.ByParentID = parentID.String()
-		}
-
-		if req.Filter.BySpiffeId != nil {
-			spiffeID, err := api.TrustDomainWorkloadIDFromProto(ctx, s.td, req.Filter.BySpiffeId)
-			if err != nil {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed SPIFFE ID filter", err)
-			}
-			listReq.BySpiffeID = spiffeID.String()
-		}
-
-		if req.Filter.BySelectors != nil {
-			dsSelectors, err := api.SelectorsFromProto(req.Filter.BySelectors.Selectors)
-			if err != nil {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed selectors filter", err)
-			}
-			if len(dsSelectors) == 0 {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed selectors filter", errors.New("empty selector set"))
-			}
-			listReq.BySelectors = &datastore.BySelectors{
-				Match:     datastore.MatchBehavior(req.Filter.BySelectors.Match),
-				Selectors: dsSelectors,
-			}
-		}
-
-		if req.Filter.ByFederatesWith != nil {
-			trustDomains := make([]string, 0, len(req.Filter.ByFederatesWith.TrustDomains))
-			for _, tdStr := range req.Filter.ByFederatesWith.TrustDomains {
-				td, err := spiffeid.TrustDomainFromString(tdStr)
-				if err != nil {
-					return nil, api.MakeErr(log, codes.InvalidArgument, "malformed federates with filter", err)
-				}
-				trustDomains = append(trustDomains, td.IDString())
-			}
-			if len(trustDomains) == 0 {
-				return nil, api.MakeErr(log, codes.InvalidArgument, "malformed federates with filter", errors.New("empty trust domain set"))
-			}
-			listReq.ByFederatesWith = &datastore.ByFederatesWith{
-				Match:        datastore.MatchBehavior(req.Filter.ByFederatesWith.Match),
-				TrustDomains: trustDomains,
-			}
-		}
-
-		if req.Filter.ByDownstream != nil {
-			listReq.ByDownstream = &req.Filter.ByDownstream.Value
+		if err := s.applyListEntriesFilter(ctx, log, listReq, req.Filter); err != nil {
+			return nil, err
 		}
 	}
 
@@ -214,7 +210,8 @@ func (s *Service) ListEntries(ctx context.Context, req *entryv1.ListEntriesReque
 
 	resp := &entryv1.ListEntriesResponse{}
 	if dsResp.Pagination != nil {
-		resp.NextPageToken = dsResp.Pagination.Token
+		resp.NextPageToken = api.NextPageToken(req.PageSize, dsResp.Pagination, len(dsResp.Entries))
+		api.SetPageSizeHeader(ctx, log, dsResp.Pagination.PageSize)
 	}
 
 	for _, regEntry := range dsResp.Entries {
@@ -231,6 +228,70 @@ func (s *Service) ListEntries(ctx context.Context, req *entryv1.ListEntriesReque
 	return resp, nil
 }
 
+// applyListEntriesFilter sets the fields of the datastore request that
+// match the filter of a ListEntries request.
+func (s *Service) applyListEntriesFilter(ctx context.Context, log logrus.FieldLogger, listReq *datastore.ListRegistrationEntriesRequest, filter *entryv1.ListEntriesRequest_Filter) error {
+	if filter.ByHint != nil {
+		listReq.ByHint = &filter.ByHint.Value
+	}
+
+	if filter.ByParentId != nil {
+		parentID, err := api.TrustDomainMemberIDFromProto(ctx, s.td, filter.ByParentId)
+		if err != nil {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed parent ID filter", err)
+		}
+		listReq.ByParentID = parentID.String()
+	}
+
+	if filter.BySpiffeId != nil {
+		spiffeID, err := api.TrustDomainWorkloadIDFromProto(ctx, s.td, filter.BySpiffeId)
+		if err != nil {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed SPIFFE ID filter", err)
+		}
+		listReq.BySpiffeID = spiffeID.String()
+	}
+
+	if filter.BySelectors != nil {
+		dsSelectors, err := api.SelectorsFromProto(filter.BySelectors.Selectors)
+		if err != nil {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed selectors filter", err)
+		}
+		if len(dsSelectors) == 0 {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed selectors filter", errors.New("empty selector set"))
+		}
+		listReq.BySelectors = &datastore.BySelectors{
+			Match:     datastore.MatchBehavior(filter.BySelectors.Match),
+			Selectors: dsSelectors,
+		}
+	}
+
+	if filter.ByFederatesWith != nil {
+		trustDomains := make([]string, 0, len(filter.ByFederatesWith.TrustDomains))
+		for _, tdStr := range filter.ByFederatesWith.TrustDomains {
+			td, err := spiffeid.TrustDomainFromString(tdStr)
+			if err != nil {
+				return api.MakeErr(log, codes.InvalidArgument, "malformed federates with filter", err)
+			}
+			trustDomains = append(trustDomains, td.IDString())
+		}
+		if len(trustDomains) == 0 {
+			return api.MakeErr(log, codes.InvalidArgument, "malformed federates with filter", errors.New("empty trust domain set"))
+		}
+		listReq.ByFederatesWith = &datastore.ByFederatesWith{
+			Match:        datastore.MatchBehavior(filter.ByFederatesWith.Match),
+			TrustDomains: trustDomains,
+		}
+	}
+
+	// A false downstream filter matches all the entries, as it always
+	// has in this API, so only a true filter is passed down.
+	if filter.ByDownstream.GetValue() {
+		listReq.ByDownstream = &filter.ByDownstream.Value
+	}
+
+	return nil
+}
+
 // GetEntry returns the registration entry associated with the given SpiffeID
 func (s *Service) GetEntry(ctx context.Context, req *entryv1.GetEntryRequest) (*types.Entry, error) {
 	log := rpccontext.Logger(ctx)
@@ -259,50 +320,176 @@ func (s *Service) GetEntry(ctx context.Context, req *entryv1.GetEntryRequest) (*
 	return entry, nil
 }
 
-// BatchCreateEntry adds one or more entries to the server.
+// BatchCreateEntry adds one or more entries to the server. The entries are
+// created within a single datastore transaction.
 func (s *Service) BatchCreateEntry(ctx context.Context, req *entryv1.BatchCreateEntryRequest) (*entryv1.BatchCreateEntryResponse, error) {
+	idempotencyKeys, err := idempotencyKeysFromContext(ctx, len(req.Entries))
+	if err != nil {
+		return nil, api.MakeErr(rpccontext.Logger(ctx), codes.InvalidArgument, "invalid idempotency keys", err)
+	}
+
+	cEntries := make([]*common.RegistrationEntry, len(req.Entries))
+	convertErrs := make([]error, len(req.Entries))
+	var toCreate []*common.RegistrationEntry
+	for i, eachEntry := range req.Entries {
+		cEntries[i], convertErrs[i] = s.prepareEntryToCreate(ctx, eachEntry)
+		if convertErrs[i] == nil {
+			if idempotencyKeys != nil {
+				cEntries[i].IdempotencyKey = idempotencyKeys[i]
+			}
+			toCreate = append(toCreate, cEntries[i])
+		}
+	}
+
+	dsResults := s.createEntries(ctx, toCreate)
+
 	var results []*entryv1.BatchCreateEntryResponse_Result
-	for _, eachEntry := range req.Entries {
-		r := s.createEntry(ctx, eachEntry, req.OutputMask)
+	x509SVIDTTLs := make([]string, len(req.Entries))
+	jwtSVIDTTLs := make([]string, len(req.Entries))
+	for i, eachEntry := range req.Entries {
+		var r *entryv1.BatchCreateEntryResponse_Result
+		if convertErrs[i] != nil {
+			r = &entryv1.BatchCreateEntryResponse_Result{
+				Status: api.MakeStatus(rpccontext.Logger(ctx), codes.InvalidArgument, "failed to convert entry", convertErrs[i]),
+			}
+		} else {
+			r = s.createEntryResult(ctx, cEntries[i], dsResults[0], req.OutputMask)
+			if r.Entry != nil {
+				x509SVIDTTLs[i], jwtSVIDTTLs[i] = s.effectiveSVIDTTLs(dsResults[0].Entry)
+			}
+			dsResults = dsResults[1:]
+		}
 		results = append(results, r)
 		rpccontext.AuditRPCWithTypesStatus(ctx, r.Status, func() logrus.Fields {
 			return fieldsFromEntryProto(ctx, eachEntry, nil)
 		})
 	}
 
+	if err := grpc.SetHeader(ctx, metadata.MD{
+		api.EffectiveX509SVIDTTLMetadataKey: x509SVIDTTLs,
+		api.EffectiveJWTSVIDTTLMetadataKey:  jwtSVIDTTLs,
+	}); err != nil {
+		rpccontext.Logger(ctx).WithError(err).Warn("Failed to report the effective SVID TTLs of the entries")
+	}
+
 	return &entryv1.BatchCreateEntryResponse{
 		Results: results,
 	}, nil
 }
 
-func (s *Service) createEntry(ctx context.Context, e *types.Entry, outputMask *types.EntryMask) *entryv1.BatchCreateEntryResponse_Result {
-	log := rpccontext.Logger(ctx)
+// effectiveSVIDTTLs returns the TTLs, in seconds, that the server gives to
+// the X509-SVIDs and JWT-SVIDs of the entry. They are computed like when
+// issuing SVIDs, but the SVIDs actually issued can still be cut short by the
+// expiration of the CA or key signing them.
+func (s *Service) effectiveSVIDTTLs(entry *common.RegistrationEntry) (x509SVIDTTL, jwtSVIDTTL string) {
+	x509TTL := credtemplate.EffectiveSVIDTTL(time.Duration(entry.X509SvidTtl)*time.Second, s.defaultX509SVIDTTL, s.maxSVIDTTL)
+	jwtTTL := credtemplate.EffectiveSVIDTTL(time.Duration(entry.JwtSvidTtl)*time.Second, s.defaultJWTSVIDTTL, s.maxSVIDTTL)
+	return strconv.FormatInt(int64(x509TTL/time.Second), 10), strconv.FormatInt(int64(jwtTTL/time.Second), 10)
+}
+
+// idempotencyKeysFromContext returns the idempotency keys sent in the request
+// metadata, or nil if there are none. When present, there must be exactly one
+// key for each entry in the request.
+func idempotencyKeysFromContext(ctx context.Context, entryCount int) ([]string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	keys := md.Get(api.IdempotencyKeyMetadataKey)
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	if len(keys) != entryCount {
+		return nil, fmt.Errorf("got %d keys for %d entries", len(keys), entryCount)
+	}
+	return keys, nil
+}
 
+// entryBoolsFromContext returns the boolean settings of the entries sent in
+// the request metadata under the given key, with a nil setting for the
+// entries that leave it as it is. When present, there must be exactly one
+// value for each entry in the request.
+func entryBoolsFromContext(ctx context.Context, key string, entryCount int) ([]*bool, error) {
+	settings := make([]*bool, entryCount)
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return settings, nil
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return settings, nil
+	}
+	if len(values) != entryCount {
+		return nil, fmt.Errorf("got %d values for %d entries", len(values), entryCount)
+	}
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		setting, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, err
+		}
+		settings[i] = &setting
+	}
+	return settings, nil
+}
+
+// prepareEntryToCreate converts the entry into a registration entry to be
+// stored in the datastore.
+func (s *Service) prepareEntryToCreate(ctx context.Context, e *types.Entry) (*common.RegistrationEntry, error) {
 	cEntry, err := api.ProtoToRegistrationEntry(ctx, s.td, e)
 	if err != nil {
-		return &entryv1.BatchCreateEntryResponse_Result{
-			Status: api.MakeStatus(log, codes.InvalidArgument, "failed to convert entry", err),
+		return nil, err
+	}
+
+	// Record who created the entry when the caller is authenticated with
+	// an X509-SVID. Local callers (e.g. the CLI) have no identity.
+	if callerID, ok := rpccontext.CallerID(ctx); ok {
+		cEntry.CreatedBy = callerID.String()
+	}
+	return cEntry, nil
+}
+
+// createEntries creates the entries in the datastore, returning a result for
+// each one of them. If the datastore fails to create the batch as a whole,
+// every result carries that error.
+func (s *Service) createEntries(ctx context.Context, entries []*common.RegistrationEntry) []*datastore.CreateRegistrationEntryResult {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	dsResults, err := s.ds.CreateRegistrationEntries(ctx, entries)
+	if err == nil && len(dsResults) != len(entries) {
+		err = status.Errorf(codes.Internal, "datastore returned %d results for %d entries", len(dsResults), len(entries))
+	}
+	if err != nil {
+		dsResults = make([]*datastore.CreateRegistrationEntryResult, 0, len(entries))
+		for range entries {
+			dsResults = append(dsResults, &datastore.CreateRegistrationEntryResult{Err: err})
 		}
 	}
+	return dsResults
+}
 
-	log = log.WithField(telemetry.SPIFFEID, cEntry.SpiffeId)
+func (s *Service) createEntryResult(ctx context.Context, cEntry *common.RegistrationEntry, dsResult *datastore.CreateRegistrationEntryResult, outputMask *types.EntryMask) *entryv1.BatchCreateEntryResponse_Result {
+	log := rpccontext.Logger(ctx).WithField(telemetry.SPIFFEID, cEntry.SpiffeId)
 
 	resultStatus := api.OK()
-	regEntry, existing, err := s.ds.CreateOrReturnRegistrationEntry(ctx, cEntry)
 	switch {
-	case err != nil:
-		statusCode := status.Code(err)
+	case dsResult.Err != nil:
+		statusCode := status.Code(dsResult.Err)
 		if statusCode == codes.Unknown {
 			statusCode = codes.Internal
 		}
 		return &entryv1.BatchCreateEntryResponse_Result{
-			Status: api.MakeStatus(log, statusCode, "failed to create entry", err),
+			Status: api.MakeStatus(log, statusCode, "failed to create entry", dsResult.Err),
 		}
-	case existing:
+	case dsResult.Existing:
 		resultStatus = api.CreateStatus(codes.AlreadyExists, "similar entry already exists")
 	}
 
-	tEntry, err := api.RegistrationEntryToProto(regEntry)
+	tEntry, err := api.RegistrationEntryToProto(dsResult.Entry)
 	if err != nil {
 		return &entryv1.BatchCreateEntryResponse_Result{
 			Status: api.MakeStatus(log, codes.Internal, "failed to convert entry", err),
@@ -319,13 +506,29 @@ func (s *Service) createEntry(ctx context.Context, e *types.Entry, outputMask *t
 
 // BatchUpdateEntry updates one or more entries in the server.
 func (s *Service) BatchUpdateEntry(ctx context.Context, req *entryv1.BatchUpdateEntryRequest) (*entryv1.BatchUpdateEntryResponse, error) {
+	actives, err := entryBoolsFromContext(ctx, api.EntryActiveMetadataKey, len(req.Entries))
+	if err != nil {
+		return nil, api.MakeErr(rpccontext.Logger(ctx), codes.InvalidArgument, "invalid entry active states", err)
+	}
+	federatesWithAll, err := entryBoolsFromContext(ctx, api.EntryFederatesWithAllMetadataKey, len(req.Entries))
+	if err != nil {
+		return nil, api.MakeErr(rpccontext.Logger(ctx), codes.InvalidArgument, "invalid entry federates with all settings", err)
+	}
+
 	var results []*entryv1.BatchUpdateEntryResponse_Result
 
-	for _, eachEntry := range req.Entries {
-		e := s.updateEntry(ctx, eachEntry, req.InputMask, req.OutputMask)
+	for i, eachEntry := range req.Entries {
+		e := s.updateEntry(ctx, eachEntry, req.InputMask, req.OutputMask, actives[i], federatesWithAll[i])
 		results = append(results, e)
 		rpccontext.AuditRPCWithTypesStatus(ctx, e.Status, func() logrus.Fields {
-			return fieldsFromEntryProto(ctx, eachEntry, req.InputMask)
+			fields := fieldsFromEntryProto(ctx, eachEntry, req.InputMask)
+			if actives[i] != nil {
+				fields[telemetry.Active] = *actives[i]
+			}
+			if federatesWithAll[i] != nil {
+				fields[telemetry.FederatesWithAll] = *federatesWithAll[i]
+			}
+			return fields
 		})
 	}
 
@@ -382,6 +585,144 @@ func (s *Service) deleteEntry(ctx context.Context, id string) *entryv1.BatchDele
 	}
 }
 
+// PruneOrphanedEntryChildren deletes the selectors and DNS names left behind
+// by registration entries that no longer exist.
+func (s *Service) PruneOrphanedEntryChildren(ctx context.Context, req *extensionv1.PruneOrphanedEntryChildrenRequest) (*extensionv1.PruneOrphanedEntryChildrenResponse, error) {
+	log := rpccontext.Logger(ctx)
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.DryRun: req.DryRun})
+
+	var orphans *datastore.OrphanedEntryChildren
+	var err error
+	if req.DryRun {
+		orphans, err = s.ds.FindOrphanedEntryChildren(ctx, false)
+	} else {
+		orphans, err = s.ds.PruneOrphanedEntryChildren(ctx)
+	}
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to prune orphaned entry children", err)
+	}
+
+	fields := logrus.Fields{
+		telemetry.DryRun:    req.DryRun,
+		telemetry.Selectors: orphans.Selectors,
+		telemetry.DNSNames:  orphans.DNSNames,
+	}
+	rpccontext.AddRPCAuditFields(ctx, fields)
+	if !req.DryRun {
+		log.WithFields(fields).Info("Pruned orphaned entry children")
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return &extensionv1.PruneOrphanedEntryChildrenResponse{
+		Selectors: int32(orphans.Selectors),
+		DnsNames:  int32(orphans.DNSNames),
+	}, nil
+}
+
+// ListEntriesToPrune lists the IDs of the expired registration entries that
+// pruning would delete, without deleting them.
+func (s *Service) ListEntriesToPrune(ctx context.Context, _ *extensionv1.ListEntriesToPruneRequest) (*extensionv1.ListEntriesToPruneResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	entryIDs, err := s.ds.ListRegistrationEntriesToPrune(ctx, s.clk.Now())
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list entries to prune", err)
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return &extensionv1.ListEntriesToPruneResponse{
+		EntryIds: entryIDs,
+	}, nil
+}
+
+// CountEntriesByTrustDomain counts all the registration entries per trust
+// domain of their SPIFFE ID.
+func (s *Service) CountEntriesByTrustDomain(ctx context.Context, _ *extensionv1.CountEntriesByTrustDomainRequest) (*extensionv1.CountEntriesByTrustDomainResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	counts, err := s.ds.CountRegistrationEntriesByTrustDomain(ctx)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to count entries", err)
+	}
+
+	resp := &extensionv1.CountEntriesByTrustDomainResponse{}
+	for trustDomain, count := range counts {
+		resp.TrustDomains = append(resp.TrustDomains, &extensionv1.TrustDomainEntryCount{
+			TrustDomain: trustDomain,
+			Count:       count,
+		})
+	}
+	sort.Slice(resp.TrustDomains, func(i, j int) bool {
+		return resp.TrustDomains[i].TrustDomain < resp.TrustDomains[j].TrustDomain
+	})
+	rpccontext.AuditRPC(ctx)
+
+	return resp, nil
+}
+
+// ExplainListEntries returns the plan the datastore would use to list the
+// registration entries matching the filter, without running the query.
+func (s *Service) ExplainListEntries(ctx context.Context, req *extensionv1.ExplainListEntriesRequest) (*extensionv1.ExplainListEntriesResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	listReq := &datastore.ListRegistrationEntriesRequest{}
+	if req.PageSize > 0 {
+		listReq.Pagination = &datastore.Pagination{PageSize: req.PageSize}
+	}
+	if req.Filter != nil {
+		rpccontext.AddRPCAuditFields(ctx, fieldsFromListEntryFilter(ctx, s.td, req.Filter))
+
+		if err := s.applyListEntriesFilter(ctx, log, listReq, req.Filter); err != nil {
+			return nil, err
+		}
+	}
+
+	plan, err := s.ds.ExplainListRegistrationEntries(ctx, listReq)
+	switch status.Code(err) {
+	case codes.OK:
+		rpccontext.AuditRPC(ctx)
+		return &extensionv1.ExplainListEntriesResponse{Plan: plan}, nil
+	case codes.FailedPrecondition:
+		// The datastore only explains queries when explain_queries is set
+		return nil, api.MakeErr(log, codes.FailedPrecondition, "failed to explain entry listing", err)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to explain entry listing", err)
+	}
+}
+
+// GetEntryWithParents returns the registration entry with the given ID,
+// followed by the chain of entries it is delegated from.
+func (s *Service) GetEntryWithParents(ctx context.Context, req *extensionv1.GetEntryWithParentsRequest) (*extensionv1.GetEntryWithParentsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	if req.Id == "" {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "missing ID", nil)
+	}
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{telemetry.RegistrationID: req.Id})
+	log = log.WithField(telemetry.RegistrationID, req.Id)
+
+	chain, err := s.ds.FetchRegistrationEntryWithParents(ctx, req.Id)
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.FailedPrecondition:
+		// The parent chain has a cycle or exceeds the maximum depth
+		return nil, api.MakeErr(log, codes.FailedPrecondition, "failed to fetch entry parent chain", err)
+	default:
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch entry parent chain", err)
+	}
+	if chain == nil {
+		return nil, api.MakeErr(log, codes.NotFound, "entry not found", nil)
+	}
+
+	entries, err := api.RegistrationEntriesToProto(chain)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to convert entries", err)
+	}
+	rpccontext.AuditRPC(ctx)
+
+	return &extensionv1.GetEntryWithParentsResponse{Entries: entries}, nil
+}
+
 // GetAuthorizedEntries returns the list of entries authorized for the caller ID in the context.
 func (s *Service) GetAuthorizedEntries(ctx context.Context, req *entryv1.GetAuthorizedEntriesRequest) (*entryv1.GetAuthorizedEntriesResponse, error) {
 	log := rpccontext.Logger(ctx)
@@ -635,7 +976,7 @@ func applyMask(e *types.Entry, mask *types.EntryMask) {
 	}
 }
 
-func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *types.EntryMask, outputMask *types.EntryMask) *entryv1.BatchUpdateEntryResponse_Result {
+func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *types.EntryMask, outputMask *types.EntryMask, active, federatesWithAll *bool) *entryv1.BatchUpdateEntryResponse_Result {
 	log := rpccontext.Logger(ctx)
 	log = log.WithField(telemetry.RegistrationID, e.Id)
 
@@ -646,31 +987,75 @@ func (s *Service) updateEntry(ctx context.Context, e *types.Entry, inputMask *ty
 		}
 	}
 
-	var mask *common.RegistrationEntryMask
-	if inputMask != nil {
-		mask = &common.RegistrationEntryMask{
-			SpiffeId:      inputMask.SpiffeId,
-			ParentId:      inputMask.ParentId,
-			FederatesWith: inputMask.FederatesWith,
-			Admin:         inputMask.Admin,
-			Downstream:    inputMask.Downstream,
-			EntryExpiry:   inputMask.ExpiresAt,
-			DnsNames:      inputMask.DnsNames,
-			Selectors:     inputMask.Selectors,
-			StoreSvid:     inputMask.StoreSvid,
-			X509SvidTtl:   inputMask.X509SvidTtl,
-			JwtSvidTtl:    inputMask.JwtSvidTtl,
-			Hint:          inputMask.Hint,
+	// The mask is always set, so that an update of every field of types.Entry
+	// leaves whether the entry federates with all trust domains as it is
+	if inputMask == nil {
+		inputMask = protoutil.AllTrueEntryMask
+	}
+	mask := &common.RegistrationEntryMask{
+		SpiffeId:      inputMask.SpiffeId,
+		ParentId:      inputMask.ParentId,
+		FederatesWith: inputMask.FederatesWith,
+		Admin:         inputMask.Admin,
+		Downstream:    inputMask.Downstream,
+		EntryExpiry:   inputMask.ExpiresAt,
+		DnsNames:      inputMask.DnsNames,
+		Selectors:     inputMask.Selectors,
+		StoreSvid:     inputMask.StoreSvid,
+		X509SvidTtl:   inputMask.X509SvidTtl,
+		JwtSvidTtl:    inputMask.JwtSvidTtl,
+		Hint:          inputMask.Hint,
+	}
+
+	if federatesWithAll != nil {
+		convEntry.FederatesWithAll = *federatesWithAll
+		mask.FederatesWithAll = true
+		if !*federatesWithAll && !mask.FederatesWith {
+			// The trust domains of an entry that federates with all of them
+			// are resolved when it is read, so they are kept as its explicit
+			// list.
+			current, err := s.ds.FetchRegistrationEntry(ctx, convEntry.EntryId)
+			switch {
+			case err != nil:
+				return &entryv1.BatchUpdateEntryResponse_Result{
+					Status: api.MakeStatus(log, codes.Internal, "failed to fetch entry", err),
+				}
+			case current == nil:
+				return &entryv1.BatchUpdateEntryResponse_Result{
+					Status: api.MakeStatus(log, codes.NotFound, "entry not found", nil),
+				}
+			}
+			convEntry.FederatesWith = current.FederatesWith
+			mask.FederatesWith = true
 		}
 	}
-	dsEntry, err := s.ds.UpdateRegistrationEntry(ctx, convEntry, mask)
-	if err != nil {
-		statusCode := status.Code(err)
-		if statusCode == codes.Unknown {
-			statusCode = codes.Internal
+
+	var dsEntry *common.RegistrationEntry
+	// An entry that only changes state, with nothing else to update, is not
+	// updated otherwise
+	if active == nil || !proto.Equal(mask, &common.RegistrationEntryMask{}) {
+		dsEntry, err = s.ds.UpdateRegistrationEntry(ctx, convEntry, mask)
+		if err != nil {
+			statusCode := status.Code(err)
+			if statusCode == codes.Unknown {
+				statusCode = codes.Internal
+			}
+			return &entryv1.BatchUpdateEntryResponse_Result{
+				Status: api.MakeStatus(log, statusCode, "failed to update entry", err),
+			}
 		}
-		return &entryv1.BatchUpdateEntryResponse_Result{
-			Status: api.MakeStatus(log, statusCode, "failed to update entry", err),
+	}
+
+	if active != nil {
+		dsEntry, err = s.ds.SetRegistrationEntryActive(ctx, convEntry.EntryId, *active)
+		if err != nil {
+			statusCode := status.Code(err)
+			if statusCode == codes.Unknown {
+				statusCode = codes.Internal
+			}
+			return &entryv1.BatchUpdateEntryResponse_Result{
+				Status: api.MakeStatus(log, statusCode, "failed to set entry active state", err),
+			}
 		}
 	}
 
@@ -801,7 +1186,7 @@ func fieldsFromListEntryFilter(ctx context.Context, td spiffeid.TrustDomain, fil
 	}
 
 	if filter.ByDownstream != nil {
-		fields[telemetry.Downstream] = &filter.ByDownstream.Value
+		fields[telemetry.Downstream] = filter.ByDownstream.Value
 	}
 
 	return fields
@@ -837,7 +1222,7 @@ func fieldsFromCountEntryFilter(ctx context.Context, td spiffeid.TrustDomain, fi
 	}
 
 	if filter.ByDownstream != nil {
-		fields[telemetry.Downstream] = &filter.ByDownstream.Value
+		fields[telemetry.Downstream] = filter.ByDownstream.Value
 	}
 
 	return fields
@@ -23,7 +23,9 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/middleware"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/proto/spire/common"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/grpctest"
 	"github.com/spiffe/spire/test/spiretest"
@@ -31,6 +33,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -372,6 +375,49 @@ func TestListEntries(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:            "filter by downstream false",
+			expectedEntries: []*types.Entry{expectedChild, expectedSecondChild},
+			request: &entryv1.ListEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByParentId:   protoParentID,
+					ByDownstream: wrapperspb.Bool(false),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:     "success",
+						telemetry.Type:       "audit",
+						telemetry.Downstream: "false",
+						telemetry.ParentID:   "spiffe://example.org/parent",
+					},
+				},
+			},
+		},
+		{
+			name: "filter by downstream",
+			request: &entryv1.ListEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByParentId:   protoParentID,
+					ByDownstream: wrapperspb.Bool(true),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:     "success",
+						telemetry.Type:       "audit",
+						telemetry.Downstream: "true",
+						telemetry.ParentID:   "spiffe://example.org/parent",
+					},
+				},
+			},
+		},
 		{
 			name:            "filter by SPIFFE ID",
 			expectedEntries: []*types.Entry{expectedChild},
@@ -412,6 +458,33 @@ func TestListEntries(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "filter by empty Hint",
+			request: &entryv1.ListEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByHint: wrapperspb.String(""),
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				// Only the malformed entry has no hint
+				{
+					Level:   logrus.ErrorLevel,
+					Message: fmt.Sprintf("Failed to convert entry: %q", badEntry.EntryId),
+					Data: logrus.Fields{
+						logrus.ErrorKey: `invalid SPIFFE ID: scheme is missing or invalid`,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+						telemetry.Hint:   "",
+					},
+				},
+			},
+		},
 		{
 			name:            "filter by selectors exact match",
 			expectedEntries: []*types.Entry{expectedSecondChild},
@@ -960,7 +1033,7 @@ func TestListEntries(t *testing.T) {
 		{
 			name:                  "page",
 			expectedEntries:       []*types.Entry{expectedChild},
-			expectedNextPageToken: "1",
+			expectedNextPageToken: "1:3",
 			request: &entryv1.ListEntriesRequest{
 				PageSize: 1,
 			},
@@ -1216,6 +1289,60 @@ func TestListEntries(t *testing.T) {
 	}
 }
 
+func TestListEntriesPageSize(t *testing.T) {
+	ds := fakedatastore.New(t)
+	test := setupServiceTest(t, ds)
+	defer test.Cleanup()
+
+	for _, path := range []string{"/foo", "/bar", "/baz"} {
+		_, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			ParentId:  spiffeid.RequireFromSegments(td, "parent").String(),
+			SpiffeId:  spiffeid.RequireFromPath(td, path).String(),
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		})
+		require.NoError(t, err)
+	}
+
+	for _, tt := range []struct {
+		name           string
+		pageSize       int32
+		expectEntries  int
+		expectPageSize string
+		expectNextPage bool
+	}{
+		{
+			name:           "no page size is capped to the datastore maximum",
+			expectEntries:  3,
+			expectPageSize: "1000",
+		},
+		{
+			name:           "page size over the datastore maximum is capped",
+			pageSize:       5000,
+			expectEntries:  3,
+			expectPageSize: "1000",
+			expectNextPage: true,
+		},
+		{
+			name:           "page size within the datastore maximum",
+			pageSize:       2,
+			expectEntries:  2,
+			expectPageSize: "2",
+			expectNextPage: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var header metadata.MD
+			resp, err := test.client.ListEntries(ctx, &entryv1.ListEntriesRequest{
+				PageSize: tt.pageSize,
+			}, grpc.Header(&header))
+			require.NoError(t, err)
+			require.Len(t, resp.Entries, tt.expectEntries)
+			require.Equal(t, tt.expectNextPage, resp.NextPageToken != "")
+			require.Equal(t, []string{tt.expectPageSize}, header.Get(api.PageSizeMetadataKey))
+		})
+	}
+}
+
 func TestGetEntry(t *testing.T) {
 	now := time.Now().Unix()
 	ds := fakedatastore.New(t)
@@ -1527,6 +1654,7 @@ func TestBatchCreateEntry(t *testing.T) {
 		JwtSvidTtl:    30,
 		Hint:          "external",
 		CreatedAt:     1678731397,
+		CreatedBy:     agentID.String(),
 	}
 
 	for _, tt := range []struct {
@@ -1677,7 +1805,7 @@ func TestBatchCreateEntry(t *testing.T) {
 			},
 			expectDsEntries: map[string]*common.RegistrationEntry{
 				"entry1": testDSEntry,
-				"entry2": {EntryId: "entry2", ParentId: "spiffe://example.org/agent", SpiffeId: "spiffe://example.org/workload2", Selectors: []*common.Selector{{Type: "type", Value: "value"}}},
+				"entry2": {EntryId: "entry2", ParentId: "spiffe://example.org/agent", SpiffeId: "spiffe://example.org/workload2", Selectors: []*common.Selector{{Type: "type", Value: "value"}}, CreatedBy: agentID.String()},
 			},
 		},
 		{
@@ -1748,7 +1876,8 @@ func TestBatchCreateEntry(t *testing.T) {
 						{Type: "type", Value: "value1"},
 						{Type: "type", Value: "value2"},
 					},
-					Hint: "internal",
+					Hint:      "internal",
+					CreatedBy: agentID.String(),
 				},
 			},
 		},
@@ -1821,6 +1950,7 @@ func TestBatchCreateEntry(t *testing.T) {
 						{Type: "type", Value: "value2"},
 					},
 					StoreSvid: true,
+					CreatedBy: agentID.String(),
 				},
 			},
 		},
@@ -1960,6 +2090,7 @@ func TestBatchCreateEntry(t *testing.T) {
 					Selectors: []*common.Selector{
 						{Type: "type", Value: "value1"},
 					},
+					CreatedBy: agentID.String(),
 				},
 			},
 			expectLogs: []spiretest.LogEntry{
@@ -2284,6 +2415,142 @@ func TestBatchCreateEntry(t *testing.T) {
 			},
 			noCustomCreate: true,
 		},
+		{
+			name: "entry ID already in use does not prevent other entries from being created",
+			expectResults: []*entryv1.BatchCreateEntryResponse_Result{
+				{
+					Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+					Entry: &types.Entry{
+						Id:       "batch1",
+						SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/batch1"},
+					},
+				},
+				{
+					Status: &types.Status{
+						Code:    int32(codes.AlreadyExists),
+						Message: "failed to create entry: datastore-sql: UNIQUE constraint failed: registered_entries.entry_id",
+					},
+				},
+				{
+					Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
+					Entry: &types.Entry{
+						Id:       "batch3",
+						SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/batch3"},
+					},
+				},
+			},
+			outputMask: &types.EntryMask{
+				SpiffeId: true,
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:         "success",
+						telemetry.Type:           "audit",
+						telemetry.Admin:          "false",
+						telemetry.Downstream:     "false",
+						telemetry.RegistrationID: "batch1",
+						telemetry.ExpiresAt:      "0",
+						telemetry.ParentID:       "spiffe://example.org/foo",
+						telemetry.RevisionNumber: "0",
+						telemetry.Selectors:      "type:value1",
+						telemetry.SPIFFEID:       "spiffe://example.org/batch1",
+						telemetry.X509SVIDTTL:    "45",
+						telemetry.JWTSVIDTTL:     "30",
+						telemetry.Hint:           "",
+						telemetry.CreatedAt:      "0",
+						telemetry.StoreSvid:      "false",
+					},
+				},
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to create entry",
+					Data: logrus.Fields{
+						logrus.ErrorKey:    "rpc error: code = AlreadyExists desc = datastore-sql: UNIQUE constraint failed: registered_entries.entry_id",
+						telemetry.SPIFFEID: "spiffe://example.org/batch2",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:         "error",
+						telemetry.Type:           "audit",
+						telemetry.Admin:          "false",
+						telemetry.Downstream:     "false",
+						telemetry.RegistrationID: "batch1",
+						telemetry.ExpiresAt:      "0",
+						telemetry.ParentID:       "spiffe://example.org/foo",
+						telemetry.RevisionNumber: "0",
+						telemetry.Selectors:      "type:value1",
+						telemetry.SPIFFEID:       "spiffe://example.org/batch2",
+						telemetry.X509SVIDTTL:    "45",
+						telemetry.JWTSVIDTTL:     "30",
+						telemetry.Hint:           "",
+						telemetry.CreatedAt:      "0",
+						telemetry.StoreSvid:      "false",
+						telemetry.StatusCode:     "AlreadyExists",
+						telemetry.StatusMessage:  "failed to create entry: datastore-sql: UNIQUE constraint failed: registered_entries.entry_id",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:         "success",
+						telemetry.Type:           "audit",
+						telemetry.Admin:          "false",
+						telemetry.Downstream:     "false",
+						telemetry.RegistrationID: "batch3",
+						telemetry.ExpiresAt:      "0",
+						telemetry.ParentID:       "spiffe://example.org/foo",
+						telemetry.RevisionNumber: "0",
+						telemetry.Selectors:      "type:value1",
+						telemetry.SPIFFEID:       "spiffe://example.org/batch3",
+						telemetry.X509SVIDTTL:    "45",
+						telemetry.JWTSVIDTTL:     "30",
+						telemetry.Hint:           "",
+						telemetry.CreatedAt:      "0",
+						telemetry.StoreSvid:      "false",
+					},
+				},
+			},
+			reqEntries: []*types.Entry{
+				{
+					Id:          "batch1",
+					ParentId:    api.ProtoFromID(entryParentID),
+					SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/batch1"},
+					X509SvidTtl: 45,
+					JwtSvidTtl:  30,
+					Selectors: []*types.Selector{
+						{Type: "type", Value: "value1"},
+					},
+				},
+				{
+					Id:          "batch1",
+					ParentId:    api.ProtoFromID(entryParentID),
+					SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/batch2"},
+					X509SvidTtl: 45,
+					JwtSvidTtl:  30,
+					Selectors: []*types.Selector{
+						{Type: "type", Value: "value1"},
+					},
+				},
+				{
+					Id:          "batch3",
+					ParentId:    api.ProtoFromID(entryParentID),
+					SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/batch3"},
+					X509SvidTtl: 45,
+					JwtSvidTtl:  30,
+					Selectors: []*types.Selector{
+						{Type: "type", Value: "value1"},
+					},
+				},
+			},
+			noCustomCreate: true,
+		},
 		{
 			name: "fail creating entry",
 			expectLogs: []spiretest.LogEntry{
@@ -2430,6 +2697,93 @@ func TestBatchCreateEntry(t *testing.T) {
 	}
 }
 
+func TestBatchCreateEntryIdempotencyKeys(t *testing.T) {
+	ds := fakedatastore.New(t)
+	test := setupServiceTest(t, ds)
+	defer test.Cleanup()
+
+	newEntry := func(selectorValue string) *types.Entry {
+		return &types.Entry{
+			ParentId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"},
+			SpiffeId:  &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload"},
+			Selectors: []*types.Selector{{Type: "type", Value: selectorValue}},
+		}
+	}
+	createEntries := func(keys []string, entries ...*types.Entry) (*entryv1.BatchCreateEntryResponse, error) {
+		ctx := context.Background()
+		for _, key := range keys {
+			ctx = metadata.AppendToOutgoingContext(ctx, api.IdempotencyKeyMetadataKey, key)
+		}
+		return test.client.BatchCreateEntry(ctx, &entryv1.BatchCreateEntryRequest{Entries: entries})
+	}
+
+	resp, err := createEntries([]string{"key-1", ""}, newEntry("value1"), newEntry("value2"))
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	for _, r := range resp.Results {
+		require.Equal(t, int32(codes.OK), r.Status.Code, r.Status.Message)
+	}
+	entryID := resp.Results[0].Entry.Id
+
+	// A retry with the same key returns the existing entry, even if the
+	// entry itself differs.
+	resp, err = createEntries([]string{"key-1"}, newEntry("value3"))
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 1)
+	require.Equal(t, int32(codes.AlreadyExists), resp.Results[0].Status.Code)
+	require.Equal(t, entryID, resp.Results[0].Entry.Id)
+
+	// Entries without keys are created as before.
+	resp, err = createEntries(nil, newEntry("value3"))
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code, resp.Results[0].Status.Message)
+	require.NotEqual(t, entryID, resp.Results[0].Entry.Id)
+
+	// There must be one key per entry.
+	_, err = createEntries([]string{"key-2"}, newEntry("value4"), newEntry("value5"))
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "invalid idempotency keys: got 1 keys for 2 entries")
+}
+
+func TestBatchCreateEntryEffectiveSVIDTTLs(t *testing.T) {
+	ds := fakedatastore.New(t)
+	test := setupServiceTest(t, ds, withMaxSVIDTTL(2*time.Hour))
+	defer test.Cleanup()
+
+	newEntry := func(path string, x509SVIDTTL, jwtSVIDTTL int32) *types.Entry {
+		return &types.Entry{
+			ParentId:    &types.SPIFFEID{TrustDomain: "example.org", Path: "/host"},
+			SpiffeId:    &types.SPIFFEID{TrustDomain: "example.org", Path: path},
+			Selectors:   []*types.Selector{{Type: "type", Value: "value"}},
+			X509SvidTtl: x509SVIDTTL,
+			JwtSvidTtl:  jwtSVIDTTL,
+		}
+	}
+
+	var header metadata.MD
+	resp, err := test.client.BatchCreateEntry(context.Background(), &entryv1.BatchCreateEntryRequest{
+		Entries: []*types.Entry{
+			// Within the max, or inheriting the defaults
+			newEntry("/within", 60, 0),
+			// Clamped to the max
+			newEntry("/clamped", 3*3600, 3*3600),
+			// Not created
+			newEntry("invalid", 60, 60),
+		},
+	}, grpc.Header(&header))
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 3)
+	require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code, resp.Results[0].Status.Message)
+	require.Equal(t, int32(codes.OK), resp.Results[1].Status.Code, resp.Results[1].Status.Message)
+	require.Equal(t, int32(codes.InvalidArgument), resp.Results[2].Status.Code)
+
+	require.Equal(t, []string{"60", "7200", ""}, header.Get(api.EffectiveX509SVIDTTLMetadataKey))
+	require.Equal(t, []string{"300", "7200", ""}, header.Get(api.EffectiveJWTSVIDTTLMetadataKey))
+
+	// The requested TTLs are stored as they are
+	require.Equal(t, int32(3*3600), resp.Results[1].Entry.X509SvidTtl)
+	require.Equal(t, int32(3*3600), resp.Results[1].Entry.JwtSvidTtl)
+}
+
 func TestBatchDeleteEntry(t *testing.T) {
 	expiresAt := time.Now().Unix()
 	parentID := spiffeid.RequireFromSegments(td, "host").String()
@@ -2545,29 +2899,29 @@ func TestBatchDeleteEntry(t *testing.T) {
 			expectDs: dsEntries,
 			expectResult: func(m map[string]*common.RegistrationEntry) ([]*entryv1.BatchDeleteEntryResponse_Result, []spiretest.LogEntry) {
 				return []*entryv1.BatchDeleteEntryResponse_Result{
-						{
-							Status: &types.Status{
-								Code:    int32(codes.InvalidArgument),
-								Message: "missing entry ID",
-							},
-						},
-					}, []spiretest.LogEntry{
-						{
-							Level:   logrus.ErrorLevel,
-							Message: "Invalid argument: missing entry ID",
+					{
+						Status: &types.Status{
+							Code:    int32(codes.InvalidArgument),
+							Message: "missing entry ID",
 						},
-						{
-							Level:   logrus.InfoLevel,
-							Message: "API accessed",
-							Data: logrus.Fields{
-								telemetry.Status:         "error",
-								telemetry.Type:           "audit",
-								telemetry.RegistrationID: "",
-								telemetry.StatusCode:     "InvalidArgument",
-								telemetry.StatusMessage:  "missing entry ID",
-							},
+					},
+				}, []spiretest.LogEntry{
+					{
+						Level:   logrus.ErrorLevel,
+						Message: "Invalid argument: missing entry ID",
+					},
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "error",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: "",
+							telemetry.StatusCode:     "InvalidArgument",
+							telemetry.StatusMessage:  "missing entry ID",
 						},
-					}
+					},
+				}
 			},
 			ids: func(m map[string]*common.RegistrationEntry) []string {
 				return []string{""}
@@ -2579,106 +2933,707 @@ func TestBatchDeleteEntry(t *testing.T) {
 			expectDs: dsEntries,
 			expectResult: func(m map[string]*common.RegistrationEntry) ([]*entryv1.BatchDeleteEntryResponse_Result, []spiretest.LogEntry) {
 				return []*entryv1.BatchDeleteEntryResponse_Result{
-						{
-							Status: &types.Status{
-								Code:    int32(codes.Internal),
-								Message: "failed to delete entry: some error",
-							},
-							Id: m[fooSpiffeID].EntryId,
-						},
-					}, []spiretest.LogEntry{
-						{
-							Level:   logrus.ErrorLevel,
-							Message: "Failed to delete entry",
-							Data: logrus.Fields{
-								telemetry.RegistrationID: m[fooSpiffeID].EntryId,
-								logrus.ErrorKey:          "some error",
-							},
+					{
+						Status: &types.Status{
+							Code:    int32(codes.Internal),
+							Message: "failed to delete entry: some error",
 						},
-						{
-							Level:   logrus.InfoLevel,
-							Message: "API accessed",
-							Data: logrus.Fields{
-								telemetry.Status:         "error",
-								telemetry.Type:           "audit",
-								telemetry.RegistrationID: m[fooSpiffeID].EntryId,
-								telemetry.StatusCode:     "Internal",
-								telemetry.StatusMessage:  "failed to delete entry: some error",
-							},
+						Id: m[fooSpiffeID].EntryId,
+					},
+				}, []spiretest.LogEntry{
+					{
+						Level:   logrus.ErrorLevel,
+						Message: "Failed to delete entry",
+						Data: logrus.Fields{
+							telemetry.RegistrationID: m[fooSpiffeID].EntryId,
+							logrus.ErrorKey:          "some error",
 						},
-					}
-			},
-			ids: func(m map[string]*common.RegistrationEntry) []string {
-				return []string{m[fooSpiffeID].EntryId}
-			},
-		},
-		{
+					},
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "error",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: m[fooSpiffeID].EntryId,
+							telemetry.StatusCode:     "Internal",
+							telemetry.StatusMessage:  "failed to delete entry: some error",
+						},
+					},
+				}
+			},
+			ids: func(m map[string]*common.RegistrationEntry) []string {
+				return []string{m[fooSpiffeID].EntryId}
+			},
+		},
+		{
 			name:     "entry not found",
 			expectDs: dsEntries,
 			expectResult: func(m map[string]*common.RegistrationEntry) ([]*entryv1.BatchDeleteEntryResponse_Result, []spiretest.LogEntry) {
 				return []*entryv1.BatchDeleteEntryResponse_Result{
-						{
-							Status: &types.Status{
-								Code:    int32(codes.NotFound),
-								Message: "entry not found",
-							},
-							Id: "invalid id",
+					{
+						Status: &types.Status{
+							Code:    int32(codes.NotFound),
+							Message: "entry not found",
 						},
-					}, []spiretest.LogEntry{
-						{
-							Level:   logrus.ErrorLevel,
-							Message: "Entry not found",
-							Data: logrus.Fields{
-								telemetry.RegistrationID: "invalid id",
-							},
+						Id: "invalid id",
+					},
+				}, []spiretest.LogEntry{
+					{
+						Level:   logrus.ErrorLevel,
+						Message: "Entry not found",
+						Data: logrus.Fields{
+							telemetry.RegistrationID: "invalid id",
 						},
-						{
-							Level:   logrus.InfoLevel,
-							Message: "API accessed",
-							Data: logrus.Fields{
-								telemetry.Status:         "error",
-								telemetry.Type:           "audit",
-								telemetry.RegistrationID: "invalid id",
-								telemetry.StatusCode:     "NotFound",
-								telemetry.StatusMessage:  "entry not found",
-							},
+					},
+					{
+						Level:   logrus.InfoLevel,
+						Message: "API accessed",
+						Data: logrus.Fields{
+							telemetry.Status:         "error",
+							telemetry.Type:           "audit",
+							telemetry.RegistrationID: "invalid id",
+							telemetry.StatusCode:     "NotFound",
+							telemetry.StatusMessage:  "entry not found",
 						},
-					}
+					},
+				}
+			},
+			ids: func(m map[string]*common.RegistrationEntry) []string {
+				return []string{"invalid id"}
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			test := setupServiceTest(t, ds)
+			defer test.Cleanup()
+
+			// Create entries
+			entriesMap := createTestEntries(t, ds, fooEntry, barEntry, baz)
+
+			ds.SetNextError(tt.dsError)
+			resp, err := test.client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{
+				Ids: tt.ids(entriesMap),
+			})
+			require.NoError(t, err)
+
+			expectResults, expectLogs := tt.expectResult(entriesMap)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), expectLogs)
+			spiretest.AssertProtoEqual(t, &entryv1.BatchDeleteEntryResponse{
+				Results: expectResults,
+			}, resp)
+
+			// Validate DS contains expected entries
+			listEntries, err := ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+			require.NoError(t, err)
+
+			var spiffeIDs []string
+			for _, e := range listEntries.Entries {
+				spiffeIDs = append(spiffeIDs, e.SpiffeId)
+			}
+			require.Equal(t, tt.expectDs, spiffeIDs)
+		})
+	}
+}
+
+func TestPruneOrphanedEntryChildren(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		dryRun       bool
+		dsErr        error
+		expectPruned bool
+		expectCode   codes.Code
+		expectMsg    string
+		expectResp   *extensionv1.PruneOrphanedEntryChildrenResponse
+		expectLogs   []spiretest.LogEntry
+	}{
+		{
+			name:         "success",
+			expectPruned: true,
+			expectResp:   &extensionv1.PruneOrphanedEntryChildrenResponse{Selectors: 3, DnsNames: 2},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "Pruned orphaned entry children",
+					Data: logrus.Fields{
+						telemetry.DryRun:    "false",
+						telemetry.Selectors: "3",
+						telemetry.DNSNames:  "2",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:    "success",
+						telemetry.Type:      "audit",
+						telemetry.DryRun:    "false",
+						telemetry.Selectors: "3",
+						telemetry.DNSNames:  "2",
+					},
+				},
+			},
+		},
+		{
+			name:       "dry run",
+			dryRun:     true,
+			expectResp: &extensionv1.PruneOrphanedEntryChildrenResponse{Selectors: 3, DnsNames: 2},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:    "success",
+						telemetry.Type:      "audit",
+						telemetry.DryRun:    "true",
+						telemetry.Selectors: "3",
+						telemetry.DNSNames:  "2",
+					},
+				},
+			},
+		},
+		{
+			name:       "ds fails",
+			dsErr:      errors.New("ds error"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to prune orphaned entry children: ds error",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to prune orphaned entry children",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "ds error",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.DryRun:        "false",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to prune orphaned entry children: ds error",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := &orphansDS{
+				DataStore: fakedatastore.New(t),
+				orphans:   &datastore.OrphanedEntryChildren{Selectors: 3, DNSNames: 2},
+				err:       tt.dsErr,
+			}
+			test := setupServiceTest(t, ds)
+			defer test.Cleanup()
+
+			resp, err := test.extensionClient.PruneOrphanedEntryChildren(ctx, &extensionv1.PruneOrphanedEntryChildrenRequest{
+				DryRun: tt.dryRun,
+			})
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			require.Equal(t, tt.expectPruned, ds.pruned)
+			if tt.expectMsg != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func TestListEntriesToPrune(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		dsErr      error
+		expectCode codes.Code
+		expectMsg  string
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			name: "success",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:       "ds fails",
+			dsErr:      errors.New("ds error"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to list entries to prune: ds error",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to list entries to prune",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "ds error",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to list entries to prune: ds error",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewMock(t)
+			ds := fakedatastore.New(t)
+			test := setupServiceTest(t, ds, withClock(clk))
+			defer test.Cleanup()
+
+			entries := createTestEntries(t, ds,
+				&common.RegistrationEntry{
+					ParentId:    "spiffe://example.org/agent",
+					SpiffeId:    "spiffe://example.org/expired",
+					Selectors:   []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+					EntryExpiry: clk.Now().Add(-time.Minute).Unix(),
+				},
+				&common.RegistrationEntry{
+					ParentId:    "spiffe://example.org/agent",
+					SpiffeId:    "spiffe://example.org/valid",
+					Selectors:   []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+					EntryExpiry: clk.Now().Add(time.Minute).Unix(),
+				},
+			)
+			ds.SetNextError(tt.dsErr)
+
+			resp, err := test.extensionClient.ListEntriesToPrune(ctx, &extensionv1.ListEntriesToPruneRequest{})
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectMsg != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, &extensionv1.ListEntriesToPruneResponse{
+				EntryIds: []string{entries["spiffe://example.org/expired"].EntryId},
+			}, resp)
+		})
+	}
+}
+
+func TestCountEntriesByTrustDomain(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		dsErr      error
+		expectResp *extensionv1.CountEntriesByTrustDomainResponse
+		expectCode codes.Code
+		expectMsg  string
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			name: "success",
+			expectResp: &extensionv1.CountEntriesByTrustDomainResponse{
+				TrustDomains: []*extensionv1.TrustDomainEntryCount{
+					{TrustDomain: "domain1.org", Count: 1},
+					{TrustDomain: "example.org", Count: 2},
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:       "ds fails",
+			dsErr:      errors.New("ds error"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to count entries: ds error",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to count entries",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "ds error",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to count entries: ds error",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			test := setupServiceTest(t, ds)
+			defer test.Cleanup()
+
+			for _, spiffeID := range []string{
+				"spiffe://example.org/foo",
+				"spiffe://domain1.org/bar",
+				"spiffe://example.org/baz",
+			} {
+				createTestEntries(t, ds, &common.RegistrationEntry{
+					ParentId:  "spiffe://example.org/agent",
+					SpiffeId:  spiffeID,
+					Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+				})
+			}
+			ds.SetNextError(tt.dsErr)
+
+			resp, err := test.extensionClient.CountEntriesByTrustDomain(ctx, &extensionv1.CountEntriesByTrustDomainRequest{})
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectMsg != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
+func TestExplainListEntries(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		req         *extensionv1.ExplainListEntriesRequest
+		dsErr       error
+		expectDSReq *datastore.ListRegistrationEntriesRequest
+		expectResp  *extensionv1.ExplainListEntriesResponse
+		expectCode  codes.Code
+		expectMsg   string
+		expectLogs  []spiretest.LogEntry
+	}{
+		{
+			name: "success",
+			req: &extensionv1.ExplainListEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByParentId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/agent"},
+				},
+				PageSize: 10,
+			},
+			expectDSReq: &datastore.ListRegistrationEntriesRequest{
+				Pagination: &datastore.Pagination{PageSize: 10},
+				ByParentID: "spiffe://example.org/agent",
+			},
+			expectResp: &extensionv1.ExplainListEntriesResponse{Plan: "SCAN registered_entries"},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:   "success",
+						telemetry.Type:     "audit",
+						telemetry.ParentID: "spiffe://example.org/agent",
+					},
+				},
+			},
+		},
+		{
+			name:        "unpaginated without filter",
+			req:         &extensionv1.ExplainListEntriesRequest{},
+			expectDSReq: &datastore.ListRegistrationEntriesRequest{},
+			expectResp:  &extensionv1.ExplainListEntriesResponse{Plan: "SCAN registered_entries"},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "malformed parent ID filter",
+			req: &extensionv1.ExplainListEntriesRequest{
+				Filter: &entryv1.ListEntriesRequest_Filter{
+					ByParentId: &types.SPIFFEID{Path: "/agent"},
+				},
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "malformed parent ID filter: trust domain is missing",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: malformed parent ID filter",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "trust domain is missing",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "malformed parent ID filter: trust domain is missing",
+					},
+				},
 			},
-			ids: func(m map[string]*common.RegistrationEntry) []string {
-				return []string{"invalid id"}
+		},
+		{
+			name:       "explaining queries disabled",
+			req:        &extensionv1.ExplainListEntriesRequest{},
+			dsErr:      status.Error(codes.FailedPrecondition, "explaining queries is disabled"),
+			expectCode: codes.FailedPrecondition,
+			expectMsg:  "failed to explain entry listing: explaining queries is disabled",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to explain entry listing",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "rpc error: code = FailedPrecondition desc = explaining queries is disabled",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "FailedPrecondition",
+						telemetry.StatusMessage: "failed to explain entry listing: explaining queries is disabled",
+					},
+				},
+			},
+		},
+		{
+			name:       "ds fails",
+			req:        &extensionv1.ExplainListEntriesRequest{},
+			dsErr:      errors.New("ds error"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to explain entry listing: ds error",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to explain entry listing",
+					Data: logrus.Fields{
+						logrus.ErrorKey: "ds error",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to explain entry listing: ds error",
+					},
+				},
 			},
 		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
-			ds := fakedatastore.New(t)
+			ds := &explainDS{
+				DataStore: fakedatastore.New(t),
+				plan:      "SCAN registered_entries",
+				err:       tt.dsErr,
+			}
 			test := setupServiceTest(t, ds)
 			defer test.Cleanup()
 
-			// Create entries
-			entriesMap := createTestEntries(t, ds, fooEntry, barEntry, baz)
-
-			ds.SetNextError(tt.dsError)
-			resp, err := test.client.BatchDeleteEntry(ctx, &entryv1.BatchDeleteEntryRequest{
-				Ids: tt.ids(entriesMap),
-			})
+			resp, err := test.extensionClient.ExplainListEntries(ctx, tt.req)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectMsg != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
+			}
 			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+			require.Equal(t, tt.expectDSReq, ds.gotReq)
+		})
+	}
+}
 
-			expectResults, expectLogs := tt.expectResult(entriesMap)
-			spiretest.AssertLogs(t, test.logHook.AllEntries(), expectLogs)
-			spiretest.AssertProtoEqual(t, &entryv1.BatchDeleteEntryResponse{
-				Results: expectResults,
-			}, resp)
+func TestGetEntryWithParents(t *testing.T) {
+	ds := fakedatastore.New(t)
+	parent := createTestEntries(t, ds, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/spire/agent/node",
+		SpiffeId:  "spiffe://example.org/nested",
+		Selectors: []*common.Selector{{Type: "k8s_psat", Value: "cluster:a"}},
+	})["spiffe://example.org/nested"]
+	child := createTestEntries(t, ds, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/nested",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})["spiffe://example.org/workload"]
+	cycle := createTestEntries(t, ds, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/loop",
+		SpiffeId:  "spiffe://example.org/loop",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+	})["spiffe://example.org/loop"]
+
+	expectEntries, err := api.RegistrationEntriesToProto([]*common.RegistrationEntry{child, parent})
+	require.NoError(t, err)
 
-			// Validate DS contains expected entries
-			listEntries, err := ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
-			require.NoError(t, err)
+	for _, tt := range []struct {
+		name       string
+		id         string
+		dsErr      error
+		expectResp *extensionv1.GetEntryWithParentsResponse
+		expectCode codes.Code
+		expectMsg  string
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			name:       "success",
+			id:         child.EntryId,
+			expectResp: &extensionv1.GetEntryWithParentsResponse{Entries: expectEntries},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:         "success",
+						telemetry.Type:           "audit",
+						telemetry.RegistrationID: child.EntryId,
+					},
+				},
+			},
+		},
+		{
+			name:       "missing ID",
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "missing ID",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: missing ID",
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.Type:          "audit",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "missing ID",
+					},
+				},
+			},
+		},
+		{
+			name:       "entry not found",
+			id:         "invalid",
+			expectCode: codes.NotFound,
+			expectMsg:  "entry not found",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Entry not found",
+					Data: logrus.Fields{
+						telemetry.RegistrationID: "invalid",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:         "error",
+						telemetry.Type:           "audit",
+						telemetry.StatusCode:     "NotFound",
+						telemetry.StatusMessage:  "entry not found",
+						telemetry.RegistrationID: "invalid",
+					},
+				},
+			},
+		},
+		{
+			name:       "parent chain has a cycle",
+			id:         cycle.EntryId,
+			expectCode: codes.FailedPrecondition,
+			expectMsg:  "failed to fetch entry parent chain: registration entry parent chain has a cycle",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to fetch entry parent chain",
+					Data: logrus.Fields{
+						logrus.ErrorKey:          "rpc error: code = FailedPrecondition desc = registration entry parent chain has a cycle",
+						telemetry.RegistrationID: cycle.EntryId,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:         "error",
+						telemetry.Type:           "audit",
+						telemetry.StatusCode:     "FailedPrecondition",
+						telemetry.StatusMessage:  "failed to fetch entry parent chain: registration entry parent chain has a cycle",
+						telemetry.RegistrationID: cycle.EntryId,
+					},
+				},
+			},
+		},
+		{
+			name:       "ds fails",
+			id:         child.EntryId,
+			dsErr:      errors.New("ds error"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to fetch entry parent chain: ds error",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to fetch entry parent chain",
+					Data: logrus.Fields{
+						logrus.ErrorKey:          "ds error",
+						telemetry.RegistrationID: child.EntryId,
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:         "error",
+						telemetry.Type:           "audit",
+						telemetry.StatusCode:     "Internal",
+						telemetry.StatusMessage:  "failed to fetch entry parent chain: ds error",
+						telemetry.RegistrationID: child.EntryId,
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			test := setupServiceTest(t, ds)
+			defer test.Cleanup()
+			ds.SetNextError(tt.dsErr)
 
-			var spiffeIDs []string
-			for _, e := range listEntries.Entries {
-				spiffeIDs = append(spiffeIDs, e.SpiffeId)
+			resp, err := test.extensionClient.GetEntryWithParents(ctx, &extensionv1.GetEntryWithParentsRequest{Id: tt.id})
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectMsg != "" {
+				spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+				require.Nil(t, resp)
+				return
 			}
-			require.Equal(t, tt.expectDs, spiffeIDs)
+			require.NoError(t, err)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
 		})
 	}
 }
@@ -3992,13 +4947,13 @@ func TestBatchUpdateEntry(t *testing.T) {
 			},
 			updateEntries: []*types.Entry{
 				{
-					DnsNames: []string{"dnsUpdated"},
+					DnsNames: []string{"dnsupdated"},
 				},
 			},
 			expectDsEntries: func(id string) []*types.Entry {
 				modifiedEntry := proto.Clone(initialEntry).(*types.Entry)
 				modifiedEntry.Id = id
-				modifiedEntry.DnsNames = []string{"dnsUpdated"}
+				modifiedEntry.DnsNames = []string{"dnsupdated"}
 				modifiedEntry.RevisionNumber = 1
 				return []*types.Entry{modifiedEntry}
 			},
@@ -4006,7 +4961,7 @@ func TestBatchUpdateEntry(t *testing.T) {
 				{
 					Status: &types.Status{Code: int32(codes.OK), Message: "OK"},
 					Entry: &types.Entry{
-						DnsNames: []string{"dnsUpdated"},
+						DnsNames: []string{"dnsupdated"},
 					},
 				},
 			},
@@ -4019,7 +4974,7 @@ func TestBatchUpdateEntry(t *testing.T) {
 							telemetry.Status:         "success",
 							telemetry.Type:           "audit",
 							telemetry.RegistrationID: m[entry1SpiffeID.Path],
-							telemetry.DNSName:        "dnsUpdated",
+							telemetry.DNSName:        "dnsupdated",
 						},
 					},
 				}
@@ -4683,6 +5638,140 @@ func TestBatchUpdateEntry(t *testing.T) {
 	}
 }
 
+func TestBatchUpdateEntryActive(t *testing.T) {
+	ds := fakedatastore.New(t)
+	test := setupServiceTest(t, ds)
+	defer test.Cleanup()
+
+	entries := createTestEntries(t, ds,
+		&common.RegistrationEntry{
+			ParentId:  spiffeid.RequireFromSegments(td, "host").String(),
+			SpiffeId:  spiffeid.RequireFromSegments(td, "foo").String(),
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		},
+		&common.RegistrationEntry{
+			ParentId:  spiffeid.RequireFromSegments(td, "host").String(),
+			SpiffeId:  spiffeid.RequireFromSegments(td, "bar").String(),
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+		},
+	)
+	fooID := entries[spiffeid.RequireFromSegments(td, "foo").String()].EntryId
+	barID := entries[spiffeid.RequireFromSegments(td, "bar").String()].EntryId
+
+	updateEntries := func(actives []string, ids ...string) (*entryv1.BatchUpdateEntryResponse, error) {
+		ctx := context.Background()
+		for _, active := range actives {
+			ctx = metadata.AppendToOutgoingContext(ctx, api.EntryActiveMetadataKey, active)
+		}
+		req := &entryv1.BatchUpdateEntryRequest{InputMask: &types.EntryMask{}}
+		for _, id := range ids {
+			req.Entries = append(req.Entries, &types.Entry{Id: id})
+		}
+		return test.client.BatchUpdateEntry(ctx, req)
+	}
+	requireInactive := func(id string, inactive bool) {
+		entry, err := ds.FetchRegistrationEntry(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, inactive, entry.Inactive)
+	}
+
+	// Only the entries with a state are changed
+	resp, err := updateEntries([]string{"false", ""}, fooID, barID)
+	require.NoError(t, err)
+	require.Len(t, resp.Results, 2)
+	for _, r := range resp.Results {
+		require.Equal(t, int32(codes.OK), r.Status.Code, r.Status.Message)
+	}
+	requireInactive(fooID, true)
+	requireInactive(barID, false)
+
+	resp, err = updateEntries([]string{"true"}, fooID)
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code, resp.Results[0].Status.Message)
+	requireInactive(fooID, false)
+
+	// Unknown entries are reported per entry
+	resp, err = updateEntries([]string{"false"}, "missing")
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.NotFound), resp.Results[0].Status.Code)
+	require.Contains(t, resp.Results[0].Status.Message, "failed to set entry active state")
+
+	// There must be one state per entry
+	_, err = updateEntries([]string{"false"}, fooID, barID)
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "invalid entry active states: got 1 values for 2 entries")
+
+	_, err = updateEntries([]string{"maybe"}, fooID)
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, `invalid entry active states: strconv.ParseBool: parsing "maybe": invalid syntax`)
+}
+
+func TestBatchUpdateEntryFederatesWithAll(t *testing.T) {
+	ds := fakedatastore.New(t)
+	test := setupServiceTest(t, ds)
+	defer test.Cleanup()
+
+	_, err := ds.CreateBundle(ctx, &common.Bundle{
+		TrustDomainId: federatedTd.IDString(),
+		RootCas:       []*common.Certificate{{DerBytes: []byte("cert")}},
+	})
+	require.NoError(t, err)
+	entry, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  spiffeid.RequireFromSegments(td, "host").String(),
+		SpiffeId:  spiffeid.RequireFromSegments(td, "foo").String(),
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	require.NoError(t, err)
+
+	updateEntry := func(settings []string, e *types.Entry, inputMask *types.EntryMask) (*entryv1.BatchUpdateEntryResponse, error) {
+		ctx := context.Background()
+		for _, setting := range settings {
+			ctx = metadata.AppendToOutgoingContext(ctx, api.EntryFederatesWithAllMetadataKey, setting)
+		}
+		return test.client.BatchUpdateEntry(ctx, &entryv1.BatchUpdateEntryRequest{
+			Entries:   []*types.Entry{e},
+			InputMask: inputMask,
+		})
+	}
+	requireFederatesWith := func(federatesWithAll bool, federatesWith []string) {
+		fetched, err := ds.FetchRegistrationEntry(ctx, entry.EntryId)
+		require.NoError(t, err)
+		require.Equal(t, federatesWithAll, fetched.FederatesWithAll)
+		require.Equal(t, federatesWith, fetched.FederatesWith)
+	}
+
+	resp, err := updateEntry([]string{"true"}, &types.Entry{Id: entry.EntryId}, &types.EntryMask{})
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code, resp.Results[0].Status.Message)
+	require.Equal(t, []string{federatedTd.Name()}, resp.Results[0].Entry.FederatesWith)
+	requireFederatesWith(true, []string{federatedTd.IDString()})
+
+	// Updating every field of the entry leaves the setting as it is
+	resp, err = updateEntry(nil, &types.Entry{
+		Id:        entry.EntryId,
+		ParentId:  api.ProtoFromID(spiffeid.RequireFromSegments(td, "host")),
+		SpiffeId:  api.ProtoFromID(spiffeid.RequireFromSegments(td, "foo")),
+		Selectors: []*types.Selector{{Type: "unix", Value: "uid:1001"}},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code, resp.Results[0].Status.Message)
+	requireFederatesWith(true, []string{federatedTd.IDString()})
+
+	// Clearing the setting keeps the current trust domains
+	resp, err = updateEntry([]string{"false"}, &types.Entry{Id: entry.EntryId}, &types.EntryMask{})
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.OK), resp.Results[0].Status.Code, resp.Results[0].Status.Message)
+	requireFederatesWith(false, []string{federatedTd.IDString()})
+
+	// Unknown entries are reported per entry
+	resp, err = updateEntry([]string{"false"}, &types.Entry{Id: "missing"}, &types.EntryMask{})
+	require.NoError(t, err)
+	require.Equal(t, int32(codes.NotFound), resp.Results[0].Status.Code)
+	require.Equal(t, "entry not found", resp.Results[0].Status.Message)
+
+	// There must be one setting per entry
+	_, err = updateEntry([]string{"true", "false"}, &types.Entry{Id: entry.EntryId}, &types.EntryMask{})
+	spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, "invalid entry federates with all settings: got 2 values for 1 entries")
+}
+
 func createFederatedBundles(t *testing.T, ds datastore.DataStore) {
 	_, err := ds.CreateBundle(ctx, &common.Bundle{
 		TrustDomainId: federatedTd.IDString(),
@@ -4725,17 +5814,32 @@ func withEntryPageSize(v int) func(*serviceTestConfig) {
 	}
 }
 
+func withMaxSVIDTTL(v time.Duration) func(*serviceTestConfig) {
+	return func(config *serviceTestConfig) {
+		config.maxSVIDTTL = v
+	}
+}
+
+func withClock(clk clock.Clock) func(*serviceTestConfig) {
+	return func(config *serviceTestConfig) {
+		config.clk = clk
+	}
+}
+
 type serviceTestConfig struct {
 	entryPageSize int
+	maxSVIDTTL    time.Duration
+	clk           clock.Clock
 }
 
 type serviceTest struct {
-	client       entryv1.EntryClient
-	ef           *entryFetcher
-	done         func()
-	ds           datastore.DataStore
-	logHook      *test.Hook
-	omitCallerID bool
+	client          entryv1.EntryClient
+	extensionClient extensionv1.EntryExtensionClient
+	ef              *entryFetcher
+	done            func()
+	ds              datastore.DataStore
+	logHook         *test.Hook
+	omitCallerID    bool
 }
 
 func (s *serviceTest) Cleanup() {
@@ -4757,6 +5861,8 @@ func setupServiceTest(t *testing.T, ds datastore.DataStore, options ...serviceTe
 		DataStore:     ds,
 		EntryFetcher:  ef,
 		EntryPageSize: config.entryPageSize,
+		MaxSVIDTTL:    config.maxSVIDTTL,
+		Clock:         config.clk,
 	})
 
 	log, logHook := test.NewNullLogger()
@@ -4784,11 +5890,53 @@ func setupServiceTest(t *testing.T, ds datastore.DataStore, options ...serviceTe
 	conn := server.NewGRPCClient(t)
 
 	test.client = entryv1.NewEntryClient(conn)
+	test.extensionClient = extensionv1.NewEntryExtensionClient(conn)
 	test.done = server.Stop
 
 	return test
 }
 
+// orphansDS reports a fixed set of orphaned entry children.
+type orphansDS struct {
+	*fakedatastore.DataStore
+
+	orphans *datastore.OrphanedEntryChildren
+	pruned  bool
+	err     error
+}
+
+func (f *orphansDS) FindOrphanedEntryChildren(context.Context, bool) (*datastore.OrphanedEntryChildren, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.orphans, nil
+}
+
+func (f *orphansDS) PruneOrphanedEntryChildren(context.Context) (*datastore.OrphanedEntryChildren, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.pruned = true
+	return f.orphans, nil
+}
+
+// explainDS returns a fixed plan when explaining the entry listing.
+type explainDS struct {
+	*fakedatastore.DataStore
+
+	plan   string
+	gotReq *datastore.ListRegistrationEntriesRequest
+	err    error
+}
+
+func (f *explainDS) ExplainListRegistrationEntries(_ context.Context, req *datastore.ListRegistrationEntriesRequest) (string, error) {
+	f.gotReq = req
+	if f.err != nil {
+		return "", f.err
+	}
+	return f.plan, nil
+}
+
 type fakeDS struct {
 	*fakedatastore.DataStore
 
@@ -4836,6 +5984,27 @@ func (f *fakeDS) CreateOrReturnRegistrationEntry(ctx context.Context, entry *com
 	return res, false, nil
 }
 
+func (f *fakeDS) CreateRegistrationEntries(ctx context.Context, entries []*common.RegistrationEntry) ([]*datastore.CreateRegistrationEntryResult, error) {
+	if !f.customCreate {
+		return f.DataStore.CreateRegistrationEntries(ctx, entries)
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var results []*datastore.CreateRegistrationEntryResult
+	for _, entry := range entries {
+		res, existing, err := f.CreateOrReturnRegistrationEntry(ctx, entry)
+		results = append(results, &datastore.CreateRegistrationEntryResult{
+			Entry:    res,
+			Existing: existing,
+			Err:      err,
+		})
+	}
+	return results, nil
+}
+
 type entryFetcher struct {
 	err     string
 	entries []*types.Entry
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// PageSizeMetadataKey is the gRPC response header key holding the page size
+// the server used for a list request. The list requests are always paginated:
+// a page size that is zero or larger than the maximum page size of the
+// datastore is replaced by that maximum, so clients can tell from the header
+// when the page size was capped. The list responses have no field for it, so
+// it travels as metadata.
+const PageSizeMetadataKey = "spire-effective-page-size"
+
+// SetPageSizeHeader reports the page size used for a list request in the
+// PageSizeMetadataKey response header. A failure is only logged, since the
+// list itself succeeded.
+func SetPageSizeHeader(ctx context.Context, log logrus.FieldLogger, pageSize int32) {
+	if err := grpc.SetHeader(ctx, metadata.Pairs(PageSizeMetadataKey, strconv.FormatInt(int64(pageSize), 10))); err != nil {
+		log.WithError(err).Warn("Failed to report the page size")
+	}
+}
+
+// NextPageToken returns the token of the page that follows the one listed by
+// the datastore with the given pagination and number of items. The datastore
+// returns a token after any non-empty page. A request without a page size
+// only gets one when its list was capped, so clients that don't paginate get
+// the same response as before as long as the list fits in a page.
+func NextPageToken(reqPageSize int32, pagination *datastore.Pagination, count int) string {
+	if reqPageSize <= 0 && count < int(pagination.PageSize) {
+		return ""
+	}
+	return pagination.Token
+}
@@ -0,0 +1,43 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/spiffe/spire/pkg/server/api"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextPageToken(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		reqPageSize int32
+		count       int
+		expectToken string
+	}{
+		{
+			name:        "paginated request",
+			reqPageSize: 10,
+			count:       3,
+			expectToken: "token",
+		},
+		{
+			name:        "unpaginated request that fits in a page",
+			count:       3,
+			expectToken: "",
+		},
+		{
+			name:        "unpaginated request that was capped",
+			count:       10,
+			expectToken: "token",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			token := api.NextPageToken(tt.reqPageSize, &datastore.Pagination{
+				Token:    "token",
+				PageSize: 10,
+			}, tt.count)
+			require.Equal(t, tt.expectToken, token)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package svid
 import (
 	"context"
 	"crypto/x509"
+	"math/rand"
 	"strings"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/idutil"
 	"github.com/spiffe/spire/pkg/common/jwtsvid"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
@@ -35,16 +37,31 @@ type Config struct {
 	TrustDomain                  spiffeid.TrustDomain
 	DataStore                    datastore.DataStore
 	UseLegacyDownstreamX509CATTL bool
+	Metrics                      telemetry.Metrics
+
+	// EntryIssuanceMetrics enables a counter of the SVIDs signed for each
+	// registration entry, labeled with the entry ID. Since there is a label
+	// value per entry, it is emitted for a sample of the signings, given by
+	// EntryIssuanceMetricsSampleRate (defaults to 1, i.e. all of them).
+	EntryIssuanceMetrics           bool
+	EntryIssuanceMetricsSampleRate float64
 }
 
 // New creates a new SVID service
 func New(config Config) *Service {
+	sampleRate := config.EntryIssuanceMetricsSampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
 	return &Service{
 		ca:                           config.ServerCA,
 		ef:                           config.EntryFetcher,
 		td:                           config.TrustDomain,
 		ds:                           config.DataStore,
 		useLegacyDownstreamX509CATTL: config.UseLegacyDownstreamX509CATTL,
+		metrics:                      config.Metrics,
+		entryIssuanceMetrics:         config.EntryIssuanceMetrics && config.Metrics != nil,
+		entryIssuanceSampleRate:      sampleRate,
 	}
 }
 
@@ -57,6 +74,9 @@ type Service struct {
 	td                           spiffeid.TrustDomain
 	ds                           datastore.DataStore
 	useLegacyDownstreamX509CATTL bool
+	metrics                      telemetry.Metrics
+	entryIssuanceMetrics         bool
+	entryIssuanceSampleRate      float64
 }
 
 func (s *Service) MintX509SVID(ctx context.Context, req *svidv1.MintX509SVIDRequest) (*svidv1.MintX509SVIDResponse, error) {
@@ -285,6 +305,7 @@ func (s *Service) newX509SVID(ctx context.Context, param *svidv1.NewX509SVIDPara
 		WithField(telemetry.SerialNumber, x509Svid[0].SerialNumber.String()).
 		WithField(telemetry.RevisionNumber, entry.GetRevisionNumber()).
 		Debug("Signed X509 SVID")
+	s.countEntryIssuance(entry, telemetry.X509)
 
 	return &svidv1.BatchNewX509SVIDResponse_Result{
 		Svid: &types.X509SVID{
@@ -367,6 +388,7 @@ func (s *Service) NewJWTSVID(ctx context.Context, req *svidv1.NewJWTSVIDRequest)
 	if err != nil {
 		return nil, err
 	}
+	s.countEntryIssuance(entry, telemetry.JWT)
 	rpccontext.AuditRPCWithFields(ctx, logrus.Fields{
 		telemetry.TTL: entry.GetJwtSvidTtl(),
 	})
@@ -479,3 +501,17 @@ func parseAndCheckCSR(ctx context.Context, csrBytes []byte) (*x509.CertificateRe
 
 	return csr, nil
 }
+
+// countEntryIssuance counts an SVID of the given type signed for the entry,
+// when the per-entry issuance metrics are enabled. Only a sample of the
+// signings is counted, each one weighted by the inverse of the sample rate so
+// the totals remain accurate.
+func (s *Service) countEntryIssuance(entry *types.Entry, svidType string) {
+	if !s.entryIssuanceMetrics {
+		return
+	}
+	if s.entryIssuanceSampleRate < 1 && rand.Float64() >= s.entryIssuanceSampleRate { //nolint // gosec: no need for cryptographic randomness here
+		return
+	}
+	telemetry_server.IncrEntrySVIDIssuedCounter(s.metrics, entry.GetId(), entry.GetSpiffeId().GetTrustDomain(), svidType, float32(1/s.entryIssuanceSampleRate))
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/fakes/fakeserverca"
 	"github.com/spiffe/spire/test/grpctest"
 	"github.com/spiffe/spire/test/spiretest"
@@ -1812,6 +1813,79 @@ func TestServiceBatchNewX509SVID(t *testing.T) {
 	}
 }
 
+func TestEntryIssuanceMetrics(t *testing.T) {
+	entry := &types.Entry{
+		Id:       "workload",
+		ParentId: api.ProtoFromID(agentID),
+		SpiffeId: &types.SPIFFEID{TrustDomain: "example.org", Path: "/workload1"},
+	}
+	// Label values are sanitized by the metrics implementation
+	labels := func(svidType string) []telemetry.Label {
+		return []telemetry.Label{
+			{Name: telemetry.RegistrationID, Value: "workload"},
+			{Name: telemetry.TrustDomain, Value: "example_org"},
+			{Name: telemetry.SVIDType, Value: svidType},
+		}
+	}
+
+	for _, tt := range []struct {
+		name          string
+		enabled       bool
+		expectMetrics []fakemetrics.MetricItem
+	}{
+		{
+			name: "disabled",
+		},
+		{
+			name:    "enabled",
+			enabled: true,
+			expectMetrics: []fakemetrics.MetricItem{
+				{
+					Type:   fakemetrics.IncrCounterWithLabelsType,
+					Key:    []string{telemetry.Entry, telemetry.SVID, telemetry.Issued},
+					Val:    1,
+					Labels: labels(telemetry.X509),
+				},
+				{
+					Type:   fakemetrics.IncrCounterWithLabelsType,
+					Key:    []string{telemetry.Entry, telemetry.SVID, telemetry.Issued},
+					Val:    1,
+					Labels: labels(telemetry.JWT),
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			metrics := fakemetrics.New()
+			test := setupServiceTest(t, func(c *svid.Config) {
+				c.Metrics = metrics
+				c.EntryIssuanceMetrics = tt.enabled
+			})
+			defer test.Cleanup()
+			test.ef.entries = []*types.Entry{entry}
+			test.withCallerID = true
+			test.rateLimiter.count = 1
+
+			x509Resp, err := test.client.BatchNewX509SVID(context.Background(), &svidv1.BatchNewX509SVIDRequest{
+				Params: []*svidv1.NewX509SVIDParams{
+					{EntryId: entry.Id, Csr: createCSR(t, &x509.CertificateRequest{})},
+				},
+			})
+			require.NoError(t, err)
+			require.Len(t, x509Resp.Results, 1)
+			spiretest.AssertProtoEqual(t, api.OK(), x509Resp.Results[0].Status)
+
+			_, err = test.client.NewJWTSVID(context.Background(), &svidv1.NewJWTSVIDRequest{
+				EntryId:  entry.Id,
+				Audience: []string{"AUDIENCE"},
+			})
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expectMetrics, metrics.AllMetrics())
+		})
+	}
+}
+
 func TestNewDownstreamX509CA(t *testing.T) {
 	type downstreamCaTest struct {
 		name           string
@@ -2079,7 +2153,7 @@ func (c *serviceTest) Cleanup() {
 	c.done()
 }
 
-func setupServiceTest(t *testing.T) *serviceTest {
+func setupServiceTest(t *testing.T, configure ...func(*svid.Config)) *serviceTest {
 	trustDomain := spiffeid.RequireTrustDomainFromString("example.org")
 	ca := fakeserverca.New(t, trustDomain, &fakeserverca.Options{})
 	ef := &entryFetcher{}
@@ -2087,12 +2161,16 @@ func setupServiceTest(t *testing.T) *serviceTest {
 	ds := fakedatastore.New(t)
 
 	rateLimiter := &fakeRateLimiter{}
-	service := svid.New(svid.Config{
+	config := svid.Config{
 		EntryFetcher: ef,
 		ServerCA:     ca,
 		TrustDomain:  trustDomain,
 		DataStore:    ds,
-	})
+	}
+	for _, fn := range configure {
+		fn(&config)
+	}
+	service := svid.New(config)
 
 	log, logHook := test.NewNullLogger()
 	test := &serviceTest{
@@ -3,6 +3,7 @@ package trustdomain
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -11,6 +12,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -39,6 +41,7 @@ type Config struct {
 // Service implements the v1 trustdomain service.
 type Service struct {
 	trustdomainv1.UnsafeTrustDomainServer
+	extensionv1.UnsafeTrustDomainExtensionServer
 
 	ds datastore.DataStore
 	td spiffeid.TrustDomain
@@ -54,20 +57,23 @@ func New(config Config) *Service {
 	}
 }
 
-// RegisterService registers the trustdomain service on the gRPC server.
+// RegisterService registers the trustdomain service, along with its
+// extension, on the gRPC server.
 func RegisterService(s grpc.ServiceRegistrar, service *Service) {
 	trustdomainv1.RegisterTrustDomainServer(s, service)
+	extensionv1.RegisterTrustDomainExtensionServer(s, service)
 }
 
 func (s *Service) ListFederationRelationships(ctx context.Context, req *trustdomainv1.ListFederationRelationshipsRequest) (*trustdomainv1.ListFederationRelationshipsResponse, error) {
 	log := rpccontext.Logger(ctx)
 
-	listReq := &datastore.ListFederationRelationshipsRequest{}
-	if req.PageSize > 0 {
-		listReq.Pagination = &datastore.Pagination{
+	// A zero page size is capped to the maximum page size by the datastore,
+	// so the list is never unbounded.
+	listReq := &datastore.ListFederationRelationshipsRequest{
+		Pagination: &datastore.Pagination{
 			PageSize: req.PageSize,
 			Token:    req.PageToken,
-		}
+		},
 	}
 
 	dsResp, err := s.ds.ListFederationRelationships(ctx, listReq)
@@ -77,7 +83,8 @@ func (s *Service) ListFederationRelationships(ctx context.Context, req *trustdom
 
 	resp := &trustdomainv1.ListFederationRelationshipsResponse{}
 	if dsResp.Pagination != nil {
-		resp.NextPageToken = dsResp.Pagination.Token
+		resp.NextPageToken = api.NextPageToken(req.PageSize, dsResp.Pagination, len(dsResp.FederationRelationships))
+		api.SetPageSizeHeader(ctx, log, dsResp.Pagination.PageSize)
 	}
 
 	for _, fr := range dsResp.FederationRelationships {
@@ -214,6 +221,79 @@ func (s *Service) RefreshBundle(ctx context.Context, req *trustdomainv1.RefreshB
 	return &emptypb.Empty{}, nil
 }
 
+// SetFederationRelationshipRefreshInterval sets how often the bundle of a
+// federation relationship is refreshed, overriding the refresh hint of the
+// bundle.
+func (s *Service) SetFederationRelationshipRefreshInterval(ctx context.Context, req *extensionv1.SetFederationRelationshipRefreshIntervalRequest) (*emptypb.Empty, error) {
+	log := rpccontext.Logger(ctx)
+
+	trustDomain, err := spiffeid.TrustDomainFromString(req.TrustDomain)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "failed to parse trust domain", err)
+	}
+
+	log = log.WithFields(logrus.Fields{
+		telemetry.TrustDomainID: trustDomain.Name(),
+		telemetry.RefreshHint:   req.RefreshInterval,
+	})
+	rpccontext.AddRPCAuditFields(ctx, logrus.Fields{
+		telemetry.TrustDomainID: req.TrustDomain,
+		telemetry.RefreshHint:   req.RefreshInterval,
+	})
+
+	if req.RefreshInterval <= 0 {
+		return nil, api.MakeErr(log, codes.InvalidArgument, "refresh interval must be positive", nil)
+	}
+
+	fr, err := s.ds.FetchFederationRelationship(ctx, trustDomain)
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to fetch federation relationship", err)
+	}
+	if fr == nil {
+		return nil, api.MakeErr(log, codes.NotFound, "federation relationship does not exist", nil)
+	}
+
+	// The datastore only updates the refresh hint when it is non-zero, since
+	// the update mask has no field for it
+	if _, err := s.ds.UpdateFederationRelationship(ctx, &datastore.FederationRelationship{
+		TrustDomain: trustDomain,
+		RefreshHint: time.Duration(req.RefreshInterval) * time.Second,
+	}, &types.FederationRelationshipMask{}); err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to set federation relationship refresh interval", err)
+	}
+	s.br.TriggerConfigReload()
+
+	log.Debug("Federation relationship refresh interval set")
+	rpccontext.AuditRPC(ctx)
+	return &emptypb.Empty{}, nil
+}
+
+// ListFederationRelationshipPolls lists the outcome of the last bundle poll
+// of each federation relationship.
+func (s *Service) ListFederationRelationshipPolls(ctx context.Context, _ *extensionv1.ListFederationRelationshipPollsRequest) (*extensionv1.ListFederationRelationshipPollsResponse, error) {
+	log := rpccontext.Logger(ctx)
+
+	dsResp, err := s.ds.ListFederationRelationships(ctx, &datastore.ListFederationRelationshipsRequest{})
+	if err != nil {
+		return nil, api.MakeErr(log, codes.Internal, "failed to list federation relationships", err)
+	}
+
+	resp := &extensionv1.ListFederationRelationshipPollsResponse{}
+	for _, fr := range dsResp.FederationRelationships {
+		poll := &extensionv1.FederationRelationshipPoll{
+			TrustDomain:   fr.TrustDomain.Name(),
+			LastPollError: fr.LastPollError,
+		}
+		if !fr.LastPollAt.IsZero() {
+			poll.LastPollAt = fr.LastPollAt.Unix()
+		}
+		resp.Polls = append(resp.Polls, poll)
+	}
+
+	rpccontext.AuditRPC(ctx)
+	return resp, nil
+}
+
 func (s *Service) createFederationRelationship(ctx context.Context, f *types.FederationRelationship, outputMask *types.FederationRelationshipMask) *trustdomainv1.BatchCreateFederationRelationshipResponse_Result {
 	log := rpccontext.Logger(ctx)
 	log = log.WithField(telemetry.TrustDomainID, f.TrustDomain)
@@ -322,6 +402,7 @@ func (s *Service) deleteFederationRelationship(ctx context.Context, td string) *
 	switch status.Code(err) {
 	case codes.OK:
 		log.Debug("Federation relationship deleted")
+		s.warnIfEntriesFederate(ctx, log, trustDomain)
 		return &trustdomainv1.BatchDeleteFederationRelationshipResponse_Result{
 			TrustDomain: trustDomain.Name(),
 			Status:      api.OK(),
@@ -339,6 +420,19 @@ func (s *Service) deleteFederationRelationship(ctx context.Context, td string) *
 	}
 }
 
+// warnIfEntriesFederate logs a warning if registration entries still federate
+// with the trust domain of a deleted federation relationship. Those entries
+// keep receiving its bundle, which is no longer refreshed.
+func (s *Service) warnIfEntriesFederate(ctx context.Context, log logrus.FieldLogger, td spiffeid.TrustDomain) {
+	resp, err := s.ds.ListRegistrationEntriesByFederatesWith(ctx, td.Name(), &datastore.Pagination{PageSize: 1})
+	switch {
+	case err != nil:
+		log.WithError(err).Warn("Failed to look up the registration entries federating with the trust domain")
+	case len(resp.Entries) > 0:
+		log.Warn("Registration entries still federate with the trust domain of the deleted federation relationship; its bundle is no longer refreshed")
+	}
+}
+
 func fieldsFromRelationshipProto(proto *types.FederationRelationship, mask *types.FederationRelationshipMask) logrus.Fields {
 	fields := logrus.Fields{}
 
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
@@ -18,6 +19,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/api/rpccontext"
 	"github.com/spiffe/spire/pkg/server/api/trustdomain/v1"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/grpctest"
@@ -1330,6 +1332,57 @@ func TestBatchDeleteFederationRelationship(t *testing.T) {
 	}
 }
 
+func TestBatchDeleteFederationRelationshipWithFederatedEntries(t *testing.T) {
+	ca := testca.New(t, td)
+
+	barURL, err := url.Parse("https://bar.test/path")
+	require.NoError(t, err)
+	barFR := &datastore.FederationRelationship{
+		TrustDomain:           spiffeid.RequireTrustDomainFromString("bar.test"),
+		BundleEndpointURL:     barURL,
+		BundleEndpointProfile: datastore.BundleEndpointWeb,
+		TrustDomainBundle: &common.Bundle{
+			TrustDomainId: "spiffe://bar.test",
+			RootCas:       []*common.Certificate{{DerBytes: ca.X509Authorities()[0].Raw}},
+		},
+	}
+
+	ds := fakedatastore.New(t)
+	test := setupServiceTest(t, ds)
+	defer test.Cleanup()
+
+	createTestRelationships(t, ds, barFR)
+	_, err = ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:      "spiffe://example.org/parent",
+		SpiffeId:      "spiffe://example.org/workload",
+		Selectors:     []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		FederatesWith: []string{"spiffe://bar.test"},
+	})
+	require.NoError(t, err)
+
+	resp, err := test.client.BatchDeleteFederationRelationship(ctx, &trustdomainv1.BatchDeleteFederationRelationshipRequest{
+		TrustDomains: []string{"bar.test"},
+	})
+	require.NoError(t, err)
+	spiretest.AssertProtoEqual(t, &trustdomainv1.BatchDeleteFederationRelationshipResponse{
+		Results: []*trustdomainv1.BatchDeleteFederationRelationshipResponse_Result{
+			{
+				Status:      api.OK(),
+				TrustDomain: "bar.test",
+			},
+		},
+	}, resp)
+	spiretest.AssertLogsContainEntries(t, test.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.WarnLevel,
+			Message: "Registration entries still federate with the trust domain of the deleted federation relationship; its bundle is no longer refreshed",
+			Data: logrus.Fields{
+				telemetry.TrustDomainID: "bar.test",
+			},
+		},
+	})
+}
+
 func TestBatchUpdateFederationRelationship(t *testing.T) {
 	ca := testca.New(t, td)
 	caRaw := ca.X509Authorities()[0].Raw
@@ -2141,6 +2194,276 @@ func TestRefreshBundle(t *testing.T) {
 	}
 }
 
+func TestSetFederationRelationshipRefreshInterval(t *testing.T) {
+	for _, tt := range []struct {
+		name              string
+		req               *extensionv1.SetFederationRelationshipRefreshIntervalRequest
+		dsError           error
+		expectCode        codes.Code
+		expectMsg         string
+		expectRefreshHint time.Duration
+		expectLogs        []spiretest.LogEntry
+	}{
+		{
+			name: "success",
+			req: &extensionv1.SetFederationRelationshipRefreshIntervalRequest{
+				TrustDomain:     "domain1.org",
+				RefreshInterval: 60,
+			},
+			expectRefreshHint: time.Minute,
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.DebugLevel,
+					Message: "Federation relationship refresh interval set",
+					Data: logrus.Fields{
+						telemetry.RefreshHint:   "60",
+						telemetry.TrustDomainID: "domain1.org",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.RefreshHint:   "60",
+						telemetry.Status:        "success",
+						telemetry.TrustDomainID: "domain1.org",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "relationship not found",
+			req: &extensionv1.SetFederationRelationshipRefreshIntervalRequest{
+				TrustDomain:     "unknown.test",
+				RefreshInterval: 60,
+			},
+			expectCode: codes.NotFound,
+			expectMsg:  "federation relationship does not exist",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Federation relationship does not exist",
+					Data: logrus.Fields{
+						telemetry.RefreshHint:   "60",
+						telemetry.TrustDomainID: "unknown.test",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.RefreshHint:   "60",
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "NotFound",
+						telemetry.StatusMessage: "federation relationship does not exist",
+						telemetry.TrustDomainID: "unknown.test",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "refresh interval not positive",
+			req: &extensionv1.SetFederationRelationshipRefreshIntervalRequest{
+				TrustDomain: "domain1.org",
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "refresh interval must be positive",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: refresh interval must be positive",
+					Data: logrus.Fields{
+						telemetry.RefreshHint:   "0",
+						telemetry.TrustDomainID: "domain1.org",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.RefreshHint:   "0",
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "refresh interval must be positive",
+						telemetry.TrustDomainID: "domain1.org",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "trust domain malformed",
+			req: &extensionv1.SetFederationRelationshipRefreshIntervalRequest{
+				TrustDomain:     "http://malformed.test",
+				RefreshInterval: 60,
+			},
+			expectCode: codes.InvalidArgument,
+			expectMsg:  "failed to parse trust domain: scheme is missing or invalid",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Invalid argument: failed to parse trust domain",
+					Data: logrus.Fields{
+						telemetry.Error: "scheme is missing or invalid",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "InvalidArgument",
+						telemetry.StatusMessage: "failed to parse trust domain: scheme is missing or invalid",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+		{
+			name: "datastore fails",
+			req: &extensionv1.SetFederationRelationshipRefreshIntervalRequest{
+				TrustDomain:     "domain1.org",
+				RefreshInterval: 60,
+			},
+			dsError:    errors.New("oh no"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to fetch federation relationship: oh no",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to fetch federation relationship",
+					Data: logrus.Fields{
+						telemetry.Error:         "oh no",
+						telemetry.RefreshHint:   "60",
+						telemetry.TrustDomainID: "domain1.org",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.RefreshHint:   "60",
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to fetch federation relationship: oh no",
+						telemetry.TrustDomainID: "domain1.org",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			test := setupServiceTest(t, ds)
+			defer test.Cleanup()
+
+			createTestRelationships(t, ds, &datastore.FederationRelationship{
+				TrustDomain:           federatedTd,
+				BundleEndpointURL:     &url.URL{Scheme: "https", Host: "domain1.org"},
+				BundleEndpointProfile: datastore.BundleEndpointWeb,
+			})
+			ds.SetNextError(tt.dsError)
+
+			_, err := test.extensionClient.SetFederationRelationshipRefreshInterval(ctx, tt.req)
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			if tt.expectCode != codes.OK {
+				require.Zero(t, test.br.ReloadCount())
+				return
+			}
+
+			fr, err := ds.FetchFederationRelationship(ctx, federatedTd)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectRefreshHint, fr.RefreshHint)
+			require.Equal(t, 1, test.br.ReloadCount())
+		})
+	}
+}
+
+func TestListFederationRelationshipPolls(t *testing.T) {
+	polledAt := time.Unix(1700000000, 0)
+
+	for _, tt := range []struct {
+		name       string
+		dsError    error
+		expectCode codes.Code
+		expectMsg  string
+		expectResp *extensionv1.ListFederationRelationshipPollsResponse
+		expectLogs []spiretest.LogEntry
+	}{
+		{
+			name: "success",
+			expectResp: &extensionv1.ListFederationRelationshipPollsResponse{
+				Polls: []*extensionv1.FederationRelationshipPoll{
+					{TrustDomain: "domain1.org", LastPollAt: polledAt.Unix(), LastPollError: "oh no"},
+					{TrustDomain: "domain2.org"},
+				},
+			},
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status: "success",
+						telemetry.Type:   "audit",
+					},
+				},
+			},
+		},
+		{
+			name:       "datastore fails",
+			dsError:    errors.New("oh no"),
+			expectCode: codes.Internal,
+			expectMsg:  "failed to list federation relationships: oh no",
+			expectLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Failed to list federation relationships",
+					Data: logrus.Fields{
+						telemetry.Error: "oh no",
+					},
+				},
+				{
+					Level:   logrus.InfoLevel,
+					Message: "API accessed",
+					Data: logrus.Fields{
+						telemetry.Status:        "error",
+						telemetry.StatusCode:    "Internal",
+						telemetry.StatusMessage: "failed to list federation relationships: oh no",
+						telemetry.Type:          "audit",
+					},
+				},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := fakedatastore.New(t)
+			test := setupServiceTest(t, ds)
+			defer test.Cleanup()
+
+			createTestRelationships(t, ds, &datastore.FederationRelationship{
+				TrustDomain:           federatedTd,
+				BundleEndpointURL:     &url.URL{Scheme: "https", Host: "domain1.org"},
+				BundleEndpointProfile: datastore.BundleEndpointWeb,
+			}, &datastore.FederationRelationship{
+				TrustDomain:           spiffeid.RequireTrustDomainFromString("domain2.org"),
+				BundleEndpointURL:     &url.URL{Scheme: "https", Host: "domain2.org"},
+				BundleEndpointProfile: datastore.BundleEndpointWeb,
+			})
+			require.NoError(t, ds.SetFederationRelationshipLastPoll(ctx, federatedTd, polledAt, polledAt.Add(time.Minute), "oh no"))
+			ds.SetNextError(tt.dsError)
+
+			resp, err := test.extensionClient.ListFederationRelationshipPolls(ctx, &extensionv1.ListFederationRelationshipPollsRequest{})
+			spiretest.RequireGRPCStatus(t, err, tt.expectCode, tt.expectMsg)
+			spiretest.AssertLogs(t, test.logHook.AllEntries(), tt.expectLogs)
+			spiretest.AssertProtoEqual(t, tt.expectResp, resp)
+		})
+	}
+}
+
 func createTestRelationships(t *testing.T, ds datastore.DataStore, relationships ...*datastore.FederationRelationship) {
 	for _, fr := range relationships {
 		_, err := ds.CreateFederationRelationship(ctx, fr)
@@ -2170,11 +2493,12 @@ func assertFederationRelationshipWithMask(t *testing.T, expected, actual *types.
 }
 
 type serviceTest struct {
-	client  trustdomainv1.TrustDomainClient
-	ds      datastore.DataStore
-	br      *fakeBundleRefresher
-	logHook *test.Hook
-	done    func()
+	client          trustdomainv1.TrustDomainClient
+	extensionClient extensionv1.TrustDomainExtensionClient
+	ds              datastore.DataStore
+	br              *fakeBundleRefresher
+	logHook         *test.Hook
+	done            func()
 }
 
 func (s *serviceTest) Cleanup() {
@@ -2212,6 +2536,7 @@ func setupServiceTest(t *testing.T, ds datastore.DataStore) *serviceTest {
 	conn := server.NewGRPCClient(t)
 
 	test.client = trustdomainv1.NewTrustDomainClient(conn)
+	test.extensionClient = extensionv1.NewTrustDomainExtensionClient(conn)
 	test.done = server.Stop
 
 	return test
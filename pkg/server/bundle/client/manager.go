@@ -31,6 +31,14 @@ const (
 	// for a trust domain if that trust domain does not specify a refresh hint in
 	// its current trust bundle.
 	defaultRefreshInterval = time.Minute * 5
+
+	// defaultPollFailureThreshold is the number of consecutive failed polls
+	// of the bundle of a trust domain after which the polls back off.
+	defaultPollFailureThreshold = 3
+
+	// defaultPollMaxBackoff is the longest the polls of the bundle of a trust
+	// domain are spaced out while backing off.
+	defaultPollMaxBackoff = time.Hour
 )
 
 type TrustDomainConfig struct {
@@ -41,6 +49,10 @@ type TrustDomainConfig struct {
 	// EndpointProfile is the bundle endpoint profile used by the
 	// SPIFFE bundle endpoint server.
 	EndpointProfile EndpointProfileInfo
+
+	// RefreshInterval is how often the bundle is refreshed. When set, it
+	// takes precedence over the refresh hint of the bundle.
+	RefreshInterval time.Duration
 }
 
 type EndpointProfileInfo interface {
@@ -70,6 +82,15 @@ type ManagerConfig struct {
 	Clock     clock.Clock
 	Source    TrustDomainConfigSource
 
+	// PollFailureThreshold is the number of consecutive failed polls of the
+	// bundle of a trust domain after which the polls back off exponentially.
+	// Defaults to 3.
+	PollFailureThreshold int
+
+	// PollMaxBackoff caps the interval between the polls of the bundle of a
+	// trust domain while they back off. Defaults to an hour.
+	PollMaxBackoff time.Duration
+
 	// newBundleUpdater is a test hook to inject updater behavior
 	newBundleUpdater func(BundleUpdaterConfig) BundleUpdater
 
@@ -93,6 +114,9 @@ type Manager struct {
 	updatersMtx      sync.RWMutex
 	updaters         map[spiffeid.TrustDomain]*managedBundleUpdater
 
+	pollFailureThreshold int
+	pollMaxBackoff       time.Duration
+
 	// test hooks
 	newBundleUpdater  func(BundleUpdaterConfig) BundleUpdater
 	configRefreshedCh chan time.Duration
@@ -119,6 +143,12 @@ func NewManager(config ManagerConfig) *Manager {
 	if config.newBundleUpdater == nil {
 		config.newBundleUpdater = NewBundleUpdater
 	}
+	if config.PollFailureThreshold <= 0 {
+		config.PollFailureThreshold = defaultPollFailureThreshold
+	}
+	if config.PollMaxBackoff <= 0 {
+		config.PollMaxBackoff = defaultPollMaxBackoff
+	}
 
 	return &Manager{
 		log:               config.Log,
@@ -131,6 +161,9 @@ func NewManager(config ManagerConfig) *Manager {
 		configRefreshedCh: config.configRefreshedCh,
 		bundleRefreshedCh: config.bundleRefreshedCh,
 		updaters:          make(map[spiffeid.TrustDomain]*managedBundleUpdater),
+
+		pollFailureThreshold: config.PollFailureThreshold,
+		pollMaxBackoff:       config.PollMaxBackoff,
 	}
 }
 
@@ -180,7 +213,9 @@ func (m *Manager) RefreshBundleFor(ctx context.Context, td spiffeid.TrustDomain)
 		return false, nil
 	}
 
+	// The next poll is left as scheduled by the updater
 	_, _, err := updater.UpdateBundle(ctx)
+	m.recordLastPoll(ctx, m.log.WithField(telemetry.TrustDomain, td.Name()), td, time.Time{}, err)
 	return true, err
 }
 
@@ -247,6 +282,7 @@ func (m *Manager) refreshConfigs(ctx context.Context) error {
 				TrustDomainConfig: config,
 				TrustDomain:       td,
 				DataStore:         m.ds,
+				Clock:             m.clock,
 			}),
 			cancel: cancel,
 			runCh:  make(chan chan error),
@@ -268,8 +304,12 @@ func (m *Manager) runUpdater(ctx context.Context, trustDomain spiffeid.TrustDoma
 	defer timer.Stop()
 
 	log := m.log.WithField("trust_domain", trustDomain.Name())
+	breaker := &pollBreaker{
+		failureThreshold: m.pollFailureThreshold,
+		maxBackoff:       m.pollMaxBackoff,
+	}
 	for {
-		nextRefresh := m.runUpdateOnce(ctx, log, trustDomain, updater)
+		nextRefresh := m.runUpdateOnce(ctx, log, trustDomain, updater, breaker)
 
 		log.WithFields(logrus.Fields{
 			"at": m.clock.Now().Add(nextRefresh).UTC().Format(time.RFC3339),
@@ -289,7 +329,7 @@ func (m *Manager) runUpdater(ctx context.Context, trustDomain spiffeid.TrustDoma
 	}
 }
 
-func (m *Manager) runUpdateOnce(ctx context.Context, log *logrus.Entry, trustDomain spiffeid.TrustDomain, updater BundleUpdater) time.Duration {
+func (m *Manager) runUpdateOnce(ctx context.Context, log *logrus.Entry, trustDomain spiffeid.TrustDomain, updater BundleUpdater, breaker *pollBreaker) time.Duration {
 	log.Debug("Polling for bundle update")
 
 	counter := telemetry_server.StartBundleManagerFetchFederatedBundleCall(m.metrics)
@@ -303,24 +343,51 @@ func (m *Manager) runUpdateOnce(ctx context.Context, log *logrus.Entry, trustDom
 		log.WithError(err).Error("Error updating bundle")
 	}
 
-	if endpointBundle != nil {
+	refreshInterval := updater.GetTrustDomainConfig().RefreshInterval
+
+	var nextRefresh time.Duration
+	switch {
+	case endpointBundle != nil:
 		telemetry_server.IncrBundleManagerUpdateFederatedBundleCounter(m.metrics, trustDomain.Name())
 		log.Info("Bundle refreshed")
 
-		return calculateNextUpdate(endpointBundle)
+		nextRefresh = calculateNextUpdate(endpointBundle, refreshInterval)
+	case localBundle != nil:
+		nextRefresh = calculateNextUpdate(localBundle, refreshInterval)
+	default:
+		// We have no bundle to use to calculate the refresh hint. Since
+		// the endpoint cannot be reached without the local bundle (until
+		// we implement web auth), we can retry more aggressively. This
+		// refresh period determines how fast we'll respond to the local
+		// bundle being bootstrapped.
+		// TODO: reevaluate once we support web auth
+		nextRefresh = bundleutil.MinimumRefreshHint
 	}
 
-	if localBundle != nil {
-		return calculateNextUpdate(localBundle)
+	nextRefresh = breaker.next(err, nextRefresh)
+	if breaker.open() {
+		log.WithFields(logrus.Fields{
+			telemetry.Failures:      breaker.failures,
+			telemetry.RetryInterval: nextRefresh,
+		}).Warn("Bundle polls keep failing; backing off")
 	}
 
-	// We have no bundle to use to calculate the refresh hint. Since
-	// the endpoint cannot be reached without the local bundle (until
-	// we implement web auth), we can retry more aggressively. This
-	// refresh period determines how fast we'll respond to the local
-	// bundle being bootstrapped.
-	// TODO: reevaluate once we support web auth
-	return bundleutil.MinimumRefreshHint
+	m.recordLastPoll(ctx, log, trustDomain, m.clock.Now().Add(nextRefresh), err)
+	return nextRefresh
+}
+
+// recordLastPoll stores when the bundle of the trust domain was polled and,
+// if the poll failed, why, along with when it is polled next. The error is
+// cleared when a poll succeeds. A zero nextPollAt leaves the next poll as it
+// was recorded.
+func (m *Manager) recordLastPoll(ctx context.Context, log logrus.FieldLogger, trustDomain spiffeid.TrustDomain, nextPollAt time.Time, pollErr error) {
+	var errText string
+	if pollErr != nil {
+		errText = pollErr.Error()
+	}
+	if err := m.ds.SetFederationRelationshipLastPoll(ctx, trustDomain, m.clock.Now(), nextPollAt, errText); err != nil {
+		log.WithError(err).Warn("Failed to record the last bundle poll")
+	}
 }
 
 func (m *Manager) notifyConfigRefreshed(ctx context.Context, nextRefresh time.Duration) {
@@ -341,13 +408,60 @@ func (m *Manager) notifyBundleRefreshed(ctx context.Context, nextRefresh time.Du
 	}
 }
 
-func calculateNextUpdate(b *spiffebundle.Bundle) time.Duration {
+// calculateNextUpdate returns when the bundle should be refreshed next. A
+// non-zero refresh interval configured for the trust domain wins over the
+// refresh hint of the bundle.
+func calculateNextUpdate(b *spiffebundle.Bundle, refreshInterval time.Duration) time.Duration {
+	if refreshInterval > 0 {
+		return refreshInterval
+	}
 	if _, ok := b.RefreshHint(); !ok {
 		return defaultRefreshInterval
 	}
 	return bundleutil.CalculateRefreshHint(b) / attemptsPerRefreshHint
 }
 
+// pollBreaker spaces out the polls of the bundle of a trust domain once a
+// number of them have failed in a row, so an endpoint that is down is not
+// hammered. The interval doubles with each failure past the threshold, up to
+// a maximum, and goes back to normal as soon as a poll succeeds.
+type pollBreaker struct {
+	failureThreshold int
+	maxBackoff       time.Duration
+
+	failures int
+}
+
+// next returns the interval until the next poll given the outcome of the
+// last one and the interval the polls would normally be spaced by.
+func (b *pollBreaker) next(pollErr error, interval time.Duration) time.Duration {
+	if pollErr == nil {
+		b.failures = 0
+		return interval
+	}
+
+	b.failures++
+	if !b.open() {
+		return interval
+	}
+
+	backoff := interval
+	for range b.failures - b.failureThreshold + 1 {
+		if backoff >= b.maxBackoff {
+			break
+		}
+		backoff *= 2
+	}
+	// The polls are never spaced by less than normal, even if the maximum
+	// backoff is shorter than the normal interval
+	return max(interval, min(backoff, b.maxBackoff))
+}
+
+// open returns whether the polls are backing off.
+func (b *pollBreaker) open() bool {
+	return b.failures >= b.failureThreshold
+}
+
 func cloneTrustDomainConfigs(configs map[spiffeid.TrustDomain]TrustDomainConfig) map[spiffeid.TrustDomain]TrustDomainConfig {
 	clone := make(map[spiffeid.TrustDomain]TrustDomainConfig, len(configs))
 	for k, v := range configs {
@@ -5,6 +5,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net/url"
 	"sync"
 	"testing"
 	"time"
@@ -14,6 +15,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/stretchr/testify/assert"
@@ -29,18 +31,12 @@ func TestManagerPeriodicBundleRefresh(t *testing.T) {
 	endpointBundle.SetRefreshHint(time.Hour * 2)
 	noRefreshBundle := spiffebundle.FromX509Authorities(trustDomain, []*x509.Certificate{createCACertificate(t, "endpoint")})
 
-	source := NewTrustDomainConfigSet(TrustDomainConfigMap{
-		trustDomain: TrustDomainConfig{
-			EndpointURL:     "https://example.org/bundle",
-			EndpointProfile: HTTPSWebProfile{},
-		},
-	})
-
 	testCases := []struct {
-		name           string
-		localBundle    *spiffebundle.Bundle
-		endpointBundle *spiffebundle.Bundle
-		nextRefresh    time.Duration
+		name            string
+		localBundle     *spiffebundle.Bundle
+		endpointBundle  *spiffebundle.Bundle
+		refreshInterval time.Duration
+		nextRefresh     time.Duration
 	}{
 		{
 			name:        "update failed to obtain local bundle",
@@ -49,13 +45,13 @@ func TestManagerPeriodicBundleRefresh(t *testing.T) {
 		{
 			name:        "update failed to obtain endpoint bundle",
 			localBundle: localBundle,
-			nextRefresh: calculateNextUpdate(localBundle),
+			nextRefresh: calculateNextUpdate(localBundle, 0),
 		},
 		{
 			name:           "update obtained endpoint bundle",
 			localBundle:    localBundle,
 			endpointBundle: endpointBundle,
-			nextRefresh:    calculateNextUpdate(endpointBundle),
+			nextRefresh:    calculateNextUpdate(endpointBundle, 0),
 		},
 		{
 			name:           "endpoint bundle does not specify refresh_hint",
@@ -63,10 +59,31 @@ func TestManagerPeriodicBundleRefresh(t *testing.T) {
 			endpointBundle: noRefreshBundle,
 			nextRefresh:    time.Minute * 5,
 		},
+		{
+			name:            "trust domain overrides refresh_hint",
+			localBundle:     localBundle,
+			endpointBundle:  endpointBundle,
+			refreshInterval: time.Hour * 12,
+			nextRefresh:     time.Hour * 12,
+		},
+		{
+			name:            "trust domain overrides refresh_hint of local bundle",
+			localBundle:     localBundle,
+			refreshInterval: time.Hour * 12,
+			nextRefresh:     time.Hour * 12,
+		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
+			source := NewTrustDomainConfigSet(TrustDomainConfigMap{
+				trustDomain: TrustDomainConfig{
+					EndpointURL:     "https://example.org/bundle",
+					EndpointProfile: HTTPSWebProfile{},
+					RefreshInterval: testCase.refreshInterval,
+				},
+			})
+
 			test := newManagerTest(t, source,
 				func(spiffeid.TrustDomain) *spiffebundle.Bundle {
 					return testCase.localBundle
@@ -91,6 +108,53 @@ func TestManagerPeriodicBundleRefresh(t *testing.T) {
 	}
 }
 
+func TestCalculateNextUpdate(t *testing.T) {
+	withRefreshHint := spiffebundle.FromX509Authorities(trustDomain, []*x509.Certificate{createCACertificate(t, "hint")})
+	withRefreshHint.SetRefreshHint(time.Hour)
+	withoutRefreshHint := spiffebundle.FromX509Authorities(trustDomain, []*x509.Certificate{createCACertificate(t, "nohint")})
+	withSmallRefreshHint := spiffebundle.FromX509Authorities(trustDomain, []*x509.Certificate{createCACertificate(t, "smallhint")})
+	withSmallRefreshHint.SetRefreshHint(time.Second)
+
+	for _, tt := range []struct {
+		name            string
+		bundle          *spiffebundle.Bundle
+		refreshInterval time.Duration
+		expected        time.Duration
+	}{
+		{
+			name:     "refresh hint is split across attempts",
+			bundle:   withRefreshHint,
+			expected: time.Hour / attemptsPerRefreshHint,
+		},
+		{
+			name:     "no refresh hint falls back to the default",
+			bundle:   withoutRefreshHint,
+			expected: defaultRefreshInterval,
+		},
+		{
+			name:     "refresh hint is raised to the minimum",
+			bundle:   withSmallRefreshHint,
+			expected: bundleutil.MinimumRefreshHint / attemptsPerRefreshHint,
+		},
+		{
+			name:            "refresh interval overrides the refresh hint",
+			bundle:          withRefreshHint,
+			refreshInterval: time.Minute,
+			expected:        time.Minute,
+		},
+		{
+			name:            "refresh interval overrides the default",
+			bundle:          withoutRefreshHint,
+			refreshInterval: time.Hour * 24,
+			expected:        time.Hour * 24,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, calculateNextUpdate(tt.bundle, tt.refreshInterval))
+		})
+	}
+}
+
 func TestManagerOnDemandBundleRefresh(t *testing.T) {
 	configSet := NewTrustDomainConfigSet(nil)
 
@@ -122,6 +186,177 @@ func TestManagerOnDemandBundleRefresh(t *testing.T) {
 	assert.Greater(t, test.UpdateCount(trustDomain), 0)
 }
 
+func TestManagerRecordsLastPoll(t *testing.T) {
+	configSet := NewTrustDomainConfigSet(TrustDomainConfigMap{
+		trustDomain: TrustDomainConfig{
+			EndpointURL:     "https://some-domain.test/bundle",
+			EndpointProfile: HTTPSWebProfile{},
+		},
+	})
+
+	test := newManagerTest(t, configSet, nil, nil)
+	_, err := test.ds.CreateFederationRelationship(context.Background(), &datastore.FederationRelationship{
+		TrustDomain:           trustDomain,
+		BundleEndpointURL:     &url.URL{Scheme: "https", Host: "some-domain.test", Path: "/bundle"},
+		BundleEndpointProfile: datastore.BundleEndpointWeb,
+	})
+	require.NoError(t, err)
+
+	fetchRelationship := func() *datastore.FederationRelationship {
+		fr, err := test.ds.FetchFederationRelationship(context.Background(), trustDomain)
+		require.NoError(t, err)
+		require.NotNil(t, fr)
+		return fr
+	}
+
+	// The first poll fails
+	test.WaitForConfigRefresh()
+	test.WaitForBundleRefresh(bundleutil.MinimumRefreshHint)
+	fr := fetchRelationship()
+	assert.Equal(t, "OHNO", fr.LastPollError)
+	assert.WithinDuration(t, test.clock.Now(), fr.LastPollAt, time.Second)
+
+	// The error is cleared when the next poll succeeds
+	updater, ok := test.bundleUpdaterFor(trustDomain)
+	require.True(t, ok)
+	updater.SetError(nil)
+	test.AdvanceTime(bundleutil.MinimumRefreshHint + time.Millisecond)
+	test.WaitForBundleRefresh(bundleutil.MinimumRefreshHint)
+	fr = fetchRelationship()
+	assert.Empty(t, fr.LastPollError)
+	assert.WithinDuration(t, test.clock.Now(), fr.LastPollAt, time.Second)
+}
+
+func TestManagerBacksOffFailingPolls(t *testing.T) {
+	configSet := NewTrustDomainConfigSet(TrustDomainConfigMap{
+		trustDomain: TrustDomainConfig{
+			EndpointURL:     "https://some-domain.test/bundle",
+			EndpointProfile: HTTPSWebProfile{},
+		},
+	})
+
+	test := newManagerTest(t, configSet, nil, nil)
+	_, err := test.ds.CreateFederationRelationship(context.Background(), &datastore.FederationRelationship{
+		TrustDomain:           trustDomain,
+		BundleEndpointURL:     &url.URL{Scheme: "https", Host: "some-domain.test", Path: "/bundle"},
+		BundleEndpointProfile: datastore.BundleEndpointWeb,
+	})
+	require.NoError(t, err)
+
+	assertNextPoll := func(nextRefresh time.Duration, pollErr string) {
+		test.WaitForBundleRefresh(nextRefresh)
+		fr, err := test.ds.FetchFederationRelationship(context.Background(), trustDomain)
+		require.NoError(t, err)
+		require.NotNil(t, fr)
+		assert.Equal(t, pollErr, fr.LastPollError)
+		assert.WithinDuration(t, test.clock.Now().Add(nextRefresh), fr.NextPollAt, time.Second)
+	}
+
+	// The polls keep failing. They are retried as usual until the third
+	// failure, then back off exponentially up to an hour.
+	test.WaitForConfigRefresh()
+	var lastRefresh time.Duration
+	for _, nextRefresh := range []time.Duration{
+		time.Minute,
+		time.Minute,
+		2 * time.Minute,
+		4 * time.Minute,
+		8 * time.Minute,
+		16 * time.Minute,
+		32 * time.Minute,
+		time.Hour,
+		time.Hour,
+	} {
+		test.AdvanceTime(lastRefresh)
+		assertNextPoll(nextRefresh, "OHNO")
+		lastRefresh = nextRefresh
+	}
+
+	// A successful poll goes back to the usual schedule
+	updater, ok := test.bundleUpdaterFor(trustDomain)
+	require.True(t, ok)
+	updater.SetError(nil)
+	test.AdvanceTime(lastRefresh)
+	assertNextPoll(time.Minute, "")
+	lastRefresh = time.Minute
+
+	// The backoff starts over when polls fail again
+	updater.SetError(errors.New("OHNO"))
+	for _, nextRefresh := range []time.Duration{time.Minute, time.Minute, 2 * time.Minute} {
+		test.AdvanceTime(lastRefresh)
+		assertNextPoll(nextRefresh, "OHNO")
+		lastRefresh = nextRefresh
+	}
+}
+
+func TestPollBreaker(t *testing.T) {
+	for _, tt := range []struct {
+		name             string
+		failureThreshold int
+		maxBackoff       time.Duration
+		interval         time.Duration
+		pollErrs         []bool
+		expected         []time.Duration
+	}{
+		{
+			name:             "backs off after the threshold",
+			failureThreshold: 2,
+			maxBackoff:       time.Hour,
+			interval:         time.Minute,
+			pollErrs:         []bool{true, true, true, true},
+			expected:         []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute},
+		},
+		{
+			name:             "backs off right away with a threshold of one",
+			failureThreshold: 1,
+			maxBackoff:       time.Hour,
+			interval:         time.Minute,
+			pollErrs:         []bool{true, true},
+			expected:         []time.Duration{2 * time.Minute, 4 * time.Minute},
+		},
+		{
+			name:             "backoff is capped",
+			failureThreshold: 1,
+			maxBackoff:       3 * time.Minute,
+			interval:         time.Minute,
+			pollErrs:         []bool{true, true, true},
+			expected:         []time.Duration{2 * time.Minute, 3 * time.Minute, 3 * time.Minute},
+		},
+		{
+			name:             "polls are never more frequent than usual",
+			failureThreshold: 1,
+			maxBackoff:       time.Minute,
+			interval:         time.Hour,
+			pollErrs:         []bool{true, true},
+			expected:         []time.Duration{time.Hour, time.Hour},
+		},
+		{
+			name:             "success resets the failures",
+			failureThreshold: 2,
+			maxBackoff:       time.Hour,
+			interval:         time.Minute,
+			pollErrs:         []bool{true, true, false, true, true},
+			expected:         []time.Duration{time.Minute, 2 * time.Minute, time.Minute, time.Minute, 2 * time.Minute},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := &pollBreaker{
+				failureThreshold: tt.failureThreshold,
+				maxBackoff:       tt.maxBackoff,
+			}
+			var actual []time.Duration
+			for _, failed := range tt.pollErrs {
+				var pollErr error
+				if failed {
+					pollErr = errors.New("oh no")
+				}
+				actual = append(actual, breaker.next(pollErr, tt.interval))
+			}
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
 func TestManagerConfigPeriodicRefresh(t *testing.T) {
 	td1 := spiffeid.RequireTrustDomainFromString("domain1.test")
 	td2 := spiffeid.RequireTrustDomainFromString("domain2.test")
@@ -238,6 +473,7 @@ type managerTest struct {
 	bundleUpdaters    map[spiffeid.TrustDomain]*fakeBundleUpdater
 	configRefreshedCh chan time.Duration
 	bundleRefreshedCh chan time.Duration
+	ds                *fakedatastore.DataStore
 	manager           *Manager
 }
 
@@ -259,12 +495,13 @@ func newManagerTest(t *testing.T, source TrustDomainConfigSource, localBundles,
 		bundleUpdaters:    make(map[spiffeid.TrustDomain]*fakeBundleUpdater),
 		configRefreshedCh: make(chan time.Duration),
 		bundleRefreshedCh: make(chan time.Duration),
+		ds:                fakedatastore.New(t),
 	}
 
 	test.manager = NewManager(ManagerConfig{
 		Log:               log,
 		Metrics:           telemetry.Blackhole{},
-		DataStore:         fakedatastore.New(t),
+		DataStore:         test.ds,
 		Clock:             test.clock,
 		Source:            source,
 		newBundleUpdater:  test.newBundleUpdater,
@@ -366,15 +603,23 @@ type fakeBundleUpdater struct {
 	localBundle    *spiffebundle.Bundle
 	endpointBundle *spiffebundle.Bundle
 	updateCount    int
+	err            error
 	config         BundleUpdaterConfig
 }
 
 func newFakeBundleUpdater(config BundleUpdaterConfig) *fakeBundleUpdater {
 	return &fakeBundleUpdater{
+		err:    errors.New("OHNO"),
 		config: config,
 	}
 }
 
+func (u *fakeBundleUpdater) SetError(err error) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+	u.err = err
+}
+
 func (u *fakeBundleUpdater) SetBundles(localBundle, endpointBundle *spiffebundle.Bundle) {
 	u.mtx.Lock()
 	defer u.mtx.Unlock()
@@ -392,7 +637,7 @@ func (u *fakeBundleUpdater) UpdateBundle(context.Context) (*spiffebundle.Bundle,
 	u.mtx.Lock()
 	defer u.mtx.Unlock()
 	u.updateCount++
-	return u.localBundle, u.endpointBundle, errors.New("OHNO")
+	return u.localBundle, u.endpointBundle, u.err
 }
 
 func (u *fakeBundleUpdater) GetTrustDomainConfig() TrustDomainConfig {
@@ -88,7 +88,8 @@ func DataStoreTrustDomainConfigSource(log logrus.FieldLogger, ds datastore.DataS
 		configs := make(map[spiffeid.TrustDomain]TrustDomainConfig)
 		for _, fr := range resp.FederationRelationships {
 			config := TrustDomainConfig{
-				EndpointURL: fr.BundleEndpointURL.String(),
+				EndpointURL:     fr.BundleEndpointURL.String(),
+				RefreshInterval: fr.RefreshHint,
 			}
 			switch fr.BundleEndpointProfile {
 			case datastore.BundleEndpointSPIFFE:
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -105,6 +106,7 @@ func TestDataStoreTrustDomainConfigSource(t *testing.T) {
 				BundleEndpointURL:     parseURL(t, "https://domain3.test/bundle"),
 				BundleEndpointProfile: datastore.BundleEndpointSPIFFE,
 				EndpointSPIFFEID:      spiffeid.RequireFromString("spiffe://domain3.test/bundle-server"),
+				RefreshHint:           time.Hour,
 			},
 		}}
 		source := client.DataStoreTrustDomainConfigSource(log, ds)
@@ -119,6 +121,7 @@ func TestDataStoreTrustDomainConfigSource(t *testing.T) {
 				EndpointProfile: client.HTTPSSPIFFEProfile{
 					EndpointSPIFFEID: spiffeid.RequireFromString("spiffe://domain3.test/bundle-server"),
 				},
+				RefreshInterval: time.Hour,
 			},
 		}, configs)
 		assert.NoError(t, err)
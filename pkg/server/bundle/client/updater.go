@@ -6,15 +6,18 @@ import (
 	"fmt"
 	"sync"
 
+	"github.com/andres-erbsen/clock"
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
 )
 
 type BundleUpdaterConfig struct {
 	TrustDomain spiffeid.TrustDomain
 	DataStore   datastore.DataStore
+	Clock       clock.Clock
 
 	TrustDomainConfig TrustDomainConfig
 
@@ -43,6 +46,7 @@ type BundleUpdater interface {
 type bundleUpdater struct {
 	td            spiffeid.TrustDomain
 	ds            datastore.DataStore
+	clock         clock.Clock
 	newClientHook func(ClientConfig) (Client, error)
 
 	trustDomainConfigMtx sync.Mutex
@@ -53,9 +57,13 @@ func NewBundleUpdater(config BundleUpdaterConfig) BundleUpdater {
 	if config.newClientHook == nil {
 		config.newClientHook = NewClient
 	}
+	if config.Clock == nil {
+		config.Clock = clock.New()
+	}
 	return &bundleUpdater{
 		td:                config.TrustDomain,
 		ds:                config.DataStore,
+		clock:             config.Clock,
 		newClientHook:     config.newClientHook,
 		trustDomainConfig: config.TrustDomainConfig,
 	}
@@ -69,24 +77,49 @@ func (u *bundleUpdater) UpdateBundle(ctx context.Context) (*spiffebundle.Bundle,
 		return nil, nil, err
 	}
 
+	// The content hash is compared first so that unchanged bundles are
+	// detected without loading the local bundle.
+	localContentHash, err := u.ds.FetchBundleContentHash(ctx, u.td.IDString())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch local federated bundle content hash: %w", err)
+	}
+
+	var bundle *common.Bundle
+	fetchedFederatedBundle, fetchErr := client.FetchBundle(ctx)
+	if fetchErr == nil {
+		bundle, err = bundleutil.SPIFFEBundleToProto(fetchedFederatedBundle)
+		if err != nil {
+			return nil, nil, err
+		}
+		contentHash, err := bundleutil.ContentHash(bundle)
+		if err != nil {
+			return nil, nil, err
+		}
+		if contentHash == localContentHash {
+			if err := u.ds.SetBundleRefreshedAt(ctx, u.td.IDString(), u.clock.Now()); err != nil {
+				return fetchedFederatedBundle, nil, fmt.Errorf("failed to store federated bundle refresh time: %w", err)
+			}
+			return fetchedFederatedBundle, nil, nil
+		}
+	}
+
 	localFederatedBundleOrNil, err := fetchBundleIfExists(ctx, u.ds, u.td)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to fetch local federated bundle: %w", err)
 	}
 
-	fetchedFederatedBundle, err := client.FetchBundle(ctx)
-	if err != nil {
-		return localFederatedBundleOrNil, nil, fmt.Errorf("failed to fetch federated bundle from endpoint: %w", err)
+	if fetchErr != nil {
+		return localFederatedBundleOrNil, nil, fmt.Errorf("failed to fetch federated bundle from endpoint: %w", fetchErr)
 	}
 
 	if localFederatedBundleOrNil != nil && fetchedFederatedBundle.Equal(localFederatedBundleOrNil) {
+		if err := u.ds.SetBundleRefreshedAt(ctx, u.td.IDString(), u.clock.Now()); err != nil {
+			return localFederatedBundleOrNil, nil, fmt.Errorf("failed to store federated bundle refresh time: %w", err)
+		}
 		return localFederatedBundleOrNil, nil, nil
 	}
 
-	bundle, err := bundleutil.SPIFFEBundleToProto(fetchedFederatedBundle)
-	if err != nil {
-		return nil, nil, err
-	}
+	bundle.LastRefreshedAt = u.clock.Now().Unix()
 	_, err = u.ds.SetBundle(ctx, bundle)
 	if err != nil {
 		return localFederatedBundleOrNil, nil, fmt.Errorf("failed to store fetched federated bundle: %w", err)
@@ -12,6 +12,7 @@ import (
 	"github.com/spiffe/go-spiffe/v2/bundle/spiffebundle"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
+	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/stretchr/testify/assert"
@@ -37,6 +38,8 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 		endpointBundle *spiffebundle.Bundle
 		// the bundle in the datastore after Update()
 		storedBundle *spiffebundle.Bundle
+		// whether the stored bundle is expected to be marked as refreshed
+		refreshed bool
 		// the fake endpoint client
 		client fakeClient
 		// the expected error returned from Update()
@@ -53,6 +56,7 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 			localBundle:    bundle1,
 			endpointBundle: nil,
 			storedBundle:   bundle1,
+			refreshed:      true,
 			client: fakeClient{
 				bundle: bundle1,
 			},
@@ -63,6 +67,7 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 			localBundle:    bundle1,
 			endpointBundle: bundle2,
 			storedBundle:   bundle2,
+			refreshed:      true,
 			client: fakeClient{
 				bundle: bundle2,
 			},
@@ -83,6 +88,7 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
 			ds := fakedatastore.New(t)
+			clk := clock.NewMock(t)
 
 			if testCase.localBundle != nil {
 				localBundleProto, err := bundleutil.SPIFFEBundleToProto(testCase.localBundle)
@@ -93,6 +99,7 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 
 			updater := NewBundleUpdater(BundleUpdaterConfig{
 				DataStore:   ds,
+				Clock:       clk,
 				TrustDomain: testCase.trustDomain,
 				TrustDomainConfig: TrustDomainConfig{
 					EndpointURL: "ENDPOINT_ADDRESS",
@@ -139,6 +146,9 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 				require.NotNil(t, bundle)
 				storedBundleProto, err := bundleutil.SPIFFEBundleToProto(testCase.storedBundle)
 				require.NoError(t, err)
+				if testCase.refreshed {
+					storedBundleProto.LastRefreshedAt = clk.Now().Unix()
+				}
 				spiretest.RequireProtoEqual(t, storedBundleProto, bundle)
 			} else {
 				require.Nil(t, bundle)
@@ -147,6 +157,48 @@ func TestBundleUpdaterUpdateBundle(t *testing.T) {
 	}
 }
 
+func TestBundleUpdaterUpdateBundleComparesContentHash(t *testing.T) {
+	bundle := spiffebundle.FromX509Authorities(trustDomain, []*x509.Certificate{createCACertificate(t, "bundle")})
+	bundle.SetRefreshHint(time.Minute)
+	bundle.SetSequenceNumber(42)
+
+	ds := fakedatastore.New(t)
+	bundleProto, err := bundleutil.SPIFFEBundleToProto(bundle)
+	require.NoError(t, err)
+	_, err = ds.CreateBundle(context.Background(), bundleProto)
+	require.NoError(t, err)
+
+	clk := clock.NewMock(t)
+	updater := NewBundleUpdater(BundleUpdaterConfig{
+		DataStore:   ds,
+		Clock:       clk,
+		TrustDomain: trustDomain,
+		TrustDomainConfig: TrustDomainConfig{
+			EndpointURL:     "ENDPOINT_ADDRESS",
+			EndpointProfile: HTTPSWebProfile{},
+		},
+		newClientHook: func(client ClientConfig) (Client, error) {
+			return fakeClient{bundle: bundle}, nil
+		},
+	})
+
+	// The content hash matches, so the local bundle is not fetched and only
+	// the refresh time is stored
+	ds.AppendNextError(nil)
+	ds.AppendNextError(nil)
+	ds.AppendNextError(errors.New("local bundle should not be fetched"))
+
+	localBundle, endpointBundle, err := updater.UpdateBundle(context.Background())
+	require.NoError(t, err)
+	require.True(t, bundle.Equal(localBundle))
+	require.Nil(t, endpointBundle)
+
+	ds.SetNextError(nil)
+	stored, err := ds.FetchBundle(context.Background(), trustDomain.IDString())
+	require.NoError(t, err)
+	require.Equal(t, clk.Now().Unix(), stored.LastRefreshedAt)
+}
+
 func TestBundleUpdaterConfiguration(t *testing.T) {
 	configs := []TrustDomainConfig{
 		{
@@ -3,6 +3,9 @@ package ca
 import (
 	"context"
 	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"errors"
@@ -23,6 +26,7 @@ import (
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/credvalidator"
+	"github.com/spiffe/spire/pkg/server/plugin/keymanager"
 )
 
 const (
@@ -355,10 +359,12 @@ func (ca *CA) getX509CA() (*X509CA, []*x509.Certificate, error) {
 }
 
 func (ca *CA) signX509SVID(x509CA *X509CA, template *x509.Certificate) ([]*x509.Certificate, error) {
+	latency := telemetry_server.StartServerCASignLatency(ca.c.Metrics, telemetry.X509SVID, signingKeyType(x509CA.Signer))
 	x509SVID, err := x509util.CreateCertificate(template, x509CA.Certificate, template.PublicKey, x509CA.Signer)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign X509 SVID: %w", err)
 	}
+	latency.Measure()
 	telemetry_server.IncrServerCASignX509Counter(ca.c.Metrics)
 	return makeCertChain(x509CA, x509SVID), nil
 }
@@ -383,14 +389,38 @@ func (ca *CA) signJWTSVID(jwtKey *JWTKey, claims map[string]any) (string, error)
 		return "", fmt.Errorf("failed to configure JWT signer: %w", err)
 	}
 
+	latency := telemetry_server.StartServerCASignLatency(ca.c.Metrics, telemetry.JWTSVID, signingKeyType(jwtKey.Signer))
 	signedToken, err := jwt.Signed(jwtSigner).Claims(claims).Serialize()
 	if err != nil {
 		return "", fmt.Errorf("failed to sign JWT SVID: %w", err)
 	}
+	latency.Measure()
 
 	return signedToken, nil
 }
 
+// signingKeyType returns the type of the signing key, as named in the key
+// manager configuration, to tag the signing metrics with.
+func signingKeyType(signer crypto.Signer) string {
+	switch publicKey := signer.Public().(type) {
+	case *rsa.PublicKey:
+		switch publicKey.N.BitLen() {
+		case 2048:
+			return keymanager.RSA2048.String()
+		case 4096:
+			return keymanager.RSA4096.String()
+		}
+	case *ecdsa.PublicKey:
+		switch publicKey.Curve {
+		case elliptic.P256():
+			return keymanager.ECP256.String()
+		case elliptic.P384():
+			return keymanager.ECP384.String()
+		}
+	}
+	return "unknown"
+}
+
 func makeCertChain(x509CA *X509CA, leaf *x509.Certificate) []*x509.Certificate {
 	return append([]*x509.Certificate{leaf}, x509CA.UpstreamChain...)
 }
@@ -20,6 +20,8 @@ import (
 	"github.com/spiffe/spire/pkg/server/credvalidator"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakehealthchecker"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
+	"github.com/spiffe/spire/test/testkey"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
@@ -491,6 +493,43 @@ func (s *CATestSuite) TestSignWorkloadJWTSVIDValidatesJSR() {
 	s.Require().EqualError(err, `invalid JWT-SVID audience: cannot be empty`)
 }
 
+func (s *CATestSuite) TestSignLatencyIsTaggedWithKeyType() {
+	metrics := fakemetrics.New()
+	s.ca.c.Metrics = metrics
+	requireSignLatency := func(svidType, keyType string) {
+		var latencies []fakemetrics.MetricItem
+		for _, item := range metrics.AllMetrics() {
+			if item.Type == fakemetrics.MeasureSinceWithLabelsType {
+				latencies = append(latencies, item)
+			}
+		}
+		s.Require().Equal([]fakemetrics.MetricItem{
+			{
+				Type: fakemetrics.MeasureSinceWithLabelsType,
+				Key:  []string{telemetry.ServerCA, telemetry.Sign, telemetry.ElapsedTime},
+				Labels: []telemetry.Label{
+					{Name: telemetry.SVIDType, Value: svidType},
+					{Name: telemetry.KeyType, Value: keyType},
+				},
+			},
+		}, latencies)
+		metrics.Reset()
+	}
+
+	_, err := s.ca.SignWorkloadX509SVID(ctx, s.createWorkloadX509SVIDParams())
+	s.Require().NoError(err)
+	requireSignLatency(telemetry.X509SVID, "ec_p256")
+
+	s.ca.SetJWTKey(&JWTKey{
+		Signer:   testkey.MustRSA2048(),
+		Kid:      "KID",
+		NotAfter: s.clock.Now().Add(10 * time.Minute),
+	})
+	_, err = s.ca.SignWorkloadJWTSVID(ctx, s.createJWTSVIDParams(trustDomainExample, 0))
+	s.Require().NoError(err)
+	requireSignLatency(telemetry.JWTSVID, "rsa_2048")
+}
+
 func (s *CATestSuite) TestSignDownstreamX509CANoCASet() {
 	s.ca.SetX509CA(nil)
 	_, err := s.ca.SignDownstreamX509CA(ctx, s.createDownstreamX509CAParams())
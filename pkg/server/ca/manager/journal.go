@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/x509"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -184,6 +185,31 @@ func (j *Journal) UpdateJWTKeyStatus(ctx context.Context, authorityID string, st
 	return nil
 }
 
+// pruneExpired removes the X509 CAs and JWT keys that are neither active nor
+// prepared and expired before the given time. It mirrors the pruning of the
+// journals in the datastore, so saving the journal later doesn't restore the
+// pruned authorities.
+func (j *Journal) pruneExpired(expiredBefore time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.entries.X509CAs = slices.DeleteFunc(j.entries.X509CAs, func(entry *journal.X509CAEntry) bool {
+		return canPruneJournalEntry(entry.Status, entry.NotAfter, expiredBefore)
+	})
+	j.entries.JwtKeys = slices.DeleteFunc(j.entries.JwtKeys, func(entry *journal.JWTKeyEntry) bool {
+		return canPruneJournalEntry(entry.Status, entry.NotAfter, expiredBefore)
+	})
+}
+
+func canPruneJournalEntry(status journal.Status, notAfter int64, expiredBefore time.Time) bool {
+	switch status {
+	case journal.Status_ACTIVE, journal.Status_PREPARED:
+		return false
+	default:
+		return notAfter < expiredBefore.Unix()
+	}
+}
+
 func (j *Journal) setEntries(entries *journal.Entries) {
 	j.mu.Lock()
 	defer j.mu.Unlock()
@@ -329,6 +329,37 @@ func TestJWTKeyOverflow(t *testing.T) {
 	require.Equal(t, now, time.Unix(lastEntry.IssuedAt, 0).UTC())
 }
 
+func TestPruneExpired(t *testing.T) {
+	now := time.Now()
+	expired := now.Add(-time.Hour).Unix()
+	valid := now.Add(time.Hour).Unix()
+
+	j := &Journal{
+		entries: &journal.Entries{
+			X509CAs: []*journal.X509CAEntry{
+				{AuthorityId: "old", Status: journal.Status_OLD, NotAfter: expired},
+				{AuthorityId: "active", Status: journal.Status_ACTIVE, NotAfter: expired},
+				{AuthorityId: "valid", Status: journal.Status_OLD, NotAfter: valid},
+			},
+			JwtKeys: []*journal.JWTKeyEntry{
+				{AuthorityId: "old", Status: journal.Status_OLD, NotAfter: expired},
+				{AuthorityId: "prepared", Status: journal.Status_PREPARED, NotAfter: expired},
+			},
+		},
+	}
+
+	j.pruneExpired(now)
+
+	entries := j.getEntries()
+	spiretest.RequireProtoListEqual(t, []*journal.X509CAEntry{
+		{AuthorityId: "active", Status: journal.Status_ACTIVE, NotAfter: expired},
+		{AuthorityId: "valid", Status: journal.Status_OLD, NotAfter: valid},
+	}, entries.X509CAs)
+	spiretest.RequireProtoListEqual(t, []*journal.JWTKeyEntry{
+		{AuthorityId: "prepared", Status: journal.Status_PREPARED, NotAfter: expired},
+	}, entries.JwtKeys)
+}
+
 func TestBadProto(t *testing.T) {
 	test := setupJournalTest(t)
 	j := &Journal{
@@ -479,6 +479,16 @@ func (m *Manager) PruneCAJournals(ctx context.Context) (err error) {
 	if err != nil {
 		return fmt.Errorf("unable to prune CA journals: %w", err)
 	}
+
+	// Drop the authorities that expired before the threshold from the
+	// remaining journals. The journal of this server is pruned in memory as
+	// well, so they aren't written back the next time it is saved.
+	if err := ds.PruneCAJournal(ctx, safetyThresholdCAJournals); err != nil {
+		return fmt.Errorf("unable to prune expired authorities from CA journals: %w", err)
+	}
+	if m.journal != nil {
+		m.journal.pruneExpired(expiresBefore)
+	}
 	return nil
 }
 
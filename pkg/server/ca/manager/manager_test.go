@@ -847,6 +847,8 @@ func TestPruneCAJournals(t *testing.T) {
 	type testJournal struct {
 		Journal
 		shouldBePruned bool
+		// entries left after the expired authorities are pruned, if any
+		prunedEntries *journal.Entries
 	}
 
 	timeNow := test.clock.Now()
@@ -887,7 +889,7 @@ func TestPruneCAJournals(t *testing.T) {
 			},
 		},
 		{
-			name: "some journals with CAs expired before the threshold, but not all - no journals to be pruned",
+			name: "some journals with CAs expired before the threshold, but not all - no journals to be pruned, but their expired CAs are",
 			testJournals: []*testJournal{
 				{
 					Journal: Journal{
@@ -897,6 +899,10 @@ func TestPruneCAJournals(t *testing.T) {
 							JwtKeys: []*journal.JWTKeyEntry{{NotAfter: beforeThreshold}, {NotAfter: tomorrow}},
 						},
 					},
+					prunedEntries: &journal.Entries{
+						X509CAs: []*journal.X509CAEntry{{NotAfter: tomorrow}},
+						JwtKeys: []*journal.JWTKeyEntry{{NotAfter: tomorrow}},
+					},
 				},
 				{
 					Journal: Journal{
@@ -906,6 +912,10 @@ func TestPruneCAJournals(t *testing.T) {
 							JwtKeys: []*journal.JWTKeyEntry{{NotAfter: beforeThreshold}, {NotAfter: tomorrow}},
 						},
 					},
+					prunedEntries: &journal.Entries{
+						X509CAs: []*journal.X509CAEntry{{NotAfter: tomorrow}},
+						JwtKeys: []*journal.JWTKeyEntry{{NotAfter: tomorrow}},
+					},
 				},
 			},
 		},
@@ -930,6 +940,10 @@ func TestPruneCAJournals(t *testing.T) {
 							JwtKeys: []*journal.JWTKeyEntry{{NotAfter: beforeThreshold}, {NotAfter: tomorrow}},
 						},
 					},
+					prunedEntries: &journal.Entries{
+						X509CAs: []*journal.X509CAEntry{{NotAfter: tomorrow}},
+						JwtKeys: []*journal.JWTKeyEntry{{NotAfter: tomorrow}},
+					},
 				},
 			},
 		},
@@ -952,6 +966,10 @@ func TestPruneCAJournals(t *testing.T) {
 				})
 				require.NoError(t, err)
 
+				if j.prunedEntries != nil {
+					caJournal.Data, err = proto.Marshal(j.prunedEntries)
+					require.NoError(t, err)
+				}
 				if !j.shouldBePruned {
 					expectedCAJournals = append(expectedCAJournals, caJournal)
 				}
@@ -11,7 +11,13 @@ import (
 )
 
 const (
+	// datastoreCacheExpiry is how long a cached bundle is served before
+	// checking the bundle events for changes to it. The events are read at
+	// most once per expiry period, whatever the number of cached bundles.
 	datastoreCacheExpiry = time.Second
+
+	// DefaultBundleMaxTTL is the default for Config.BundleMaxTTL.
+	DefaultBundleMaxTTL = time.Minute
 )
 
 type useCache struct{}
@@ -20,29 +26,67 @@ func WithCache(ctx context.Context) context.Context {
 	return context.WithValue(ctx, useCache{}, struct{}{})
 }
 
+// Config configures the datastore cache.
+type Config struct {
+	// DisableBundleCache makes every FetchBundle call read the bundle from
+	// the datastore.
+	DisableBundleCache bool
+
+	// BundleMaxTTL is how long a bundle is served from the cache before it
+	// is reloaded from the datastore, even if no bundle event was read for
+	// its trust domain. Defaults to DefaultBundleMaxTTL.
+	BundleMaxTTL time.Duration
+}
+
 type bundleEntry struct {
-	mu     sync.Mutex
-	ts     time.Time
-	bundle *common.Bundle
+	mu       sync.Mutex
+	ts       time.Time
+	loadedAt time.Time
+	// ID of the last bundle event read before the bundle was loaded
+	eventID uint
+	bundle  *common.Bundle
 }
 
 type DatastoreCache struct {
 	datastore.DataStore
-	clock clock.Clock
+	clock  clock.Clock
+	config Config
 
 	bundlesMu sync.Mutex
 	bundles   map[string]*bundleEntry
+
+	eventsMu     sync.Mutex
+	eventsReadAt time.Time
+	lastEventID  uint
+	// ID of the last event read for each trust domain
+	changedAt map[string]uint
 }
 
-func New(ds datastore.DataStore, clock clock.Clock) *DatastoreCache {
+func New(ds datastore.DataStore, clock clock.Clock, config Config) *DatastoreCache {
+	if config.BundleMaxTTL <= 0 {
+		config.BundleMaxTTL = DefaultBundleMaxTTL
+	}
 	return &DatastoreCache{
 		DataStore: ds,
 		clock:     clock,
+		config:    config,
 		bundles:   make(map[string]*bundleEntry),
+		changedAt: make(map[string]uint),
 	}
 }
 
+// FetchBundle returns the bundle from the cache when the context was created
+// with WithCache. Once a cached bundle is older than a second, the bundle
+// events are read to pick up the writes made by other servers, and the bundle
+// is reloaded if its trust domain has a new event. Bundles are also reloaded
+// once they are older than BundleMaxTTL, which bounds how long a change is
+// missed if its event is committed after an event with a higher ID was read.
+// Bundles written through the cache invalidate it right away.
 func (ds *DatastoreCache) FetchBundle(ctx context.Context, trustDomain string) (*common.Bundle, error) {
+	if ds.config.DisableBundleCache {
+		return ds.DataStore.FetchBundle(ctx, trustDomain)
+	}
+
 	ds.bundlesMu.Lock()
 	entry, ok := ds.bundles[trustDomain]
 	if !ok {
@@ -53,21 +97,76 @@ func (ds *DatastoreCache) FetchBundle(ctx context.Context, trustDomain string) (
 
 	entry.mu.Lock()
 	defer entry.mu.Unlock()
-	if entry.ts.IsZero() || ds.clock.Now().Sub(entry.ts) >= datastoreCacheExpiry || ctx.Value(useCache{}) == nil {
-		bundle, err := ds.DataStore.FetchBundle(ctx, trustDomain)
-		if err != nil {
+
+	now := ds.clock.Now()
+	if ctx.Value(useCache{}) != nil && !entry.ts.IsZero() && now.Sub(entry.loadedAt) < ds.config.BundleMaxTTL {
+		if now.Sub(entry.ts) < datastoreCacheExpiry {
+			return entry.bundle, nil
+		}
+		if _, err := ds.readBundleEvents(ctx); err != nil {
 			return nil, err
 		}
-		// Don't cache bundle "misses"
-		if bundle == nil {
-			return nil, nil
+		if !ds.bundleChangedAfter(trustDomain, entry.eventID) {
+			entry.ts = now
+			return entry.bundle, nil
 		}
-		entry.bundle = bundle
-		entry.ts = ds.clock.Now()
 	}
+
+	// The events are read before loading the bundle so a change made while
+	// the bundle loads is seen as new by the next check.
+	eventID, err := ds.readBundleEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+	bundle, err := ds.DataStore.FetchBundle(ctx, trustDomain)
+	if err != nil {
+		return nil, err
+	}
+	// Don't cache bundle "misses"
+	if bundle == nil {
+		return nil, nil
+	}
+	entry.bundle = bundle
+	entry.eventID = eventID
+	entry.ts = now
+	entry.loadedAt = now
 	return entry.bundle, nil
 }
 
+// readBundleEvents reads the bundle events created since the last read, at
+// most once per datastoreCacheExpiry, and returns the ID of the last event
+// read.
+func (ds *DatastoreCache) readBundleEvents(ctx context.Context) (uint, error) {
+	ds.eventsMu.Lock()
+	defer ds.eventsMu.Unlock()
+
+	now := ds.clock.Now()
+	if !ds.eventsReadAt.IsZero() && now.Sub(ds.eventsReadAt) < datastoreCacheExpiry {
+		return ds.lastEventID, nil
+	}
+
+	resp, err := ds.DataStore.ListBundleEvents(ctx, &datastore.ListBundleEventsRequest{
+		GreaterThanEventID: ds.lastEventID,
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, event := range resp.Events {
+		ds.changedAt[event.TrustDomain] = max(ds.changedAt[event.TrustDomain], event.EventID)
+		ds.lastEventID = max(ds.lastEventID, event.EventID)
+	}
+	ds.eventsReadAt = now
+	return ds.lastEventID, nil
+}
+
+// bundleChangedAfter returns whether an event read for the trust domain is
+// newer than the given event ID.
+func (ds *DatastoreCache) bundleChangedAfter(trustDomain string, eventID uint) bool {
+	ds.eventsMu.Lock()
+	defer ds.eventsMu.Unlock()
+	return ds.changedAt[trustDomain] > eventID
+}
+
 func (ds *DatastoreCache) PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (changed bool, err error) {
 	if changed, err = ds.DataStore.PruneBundle(ctx, trustDomainID, expiresBefore); err == nil {
 		ds.invalidateBundleEntry(trustDomainID)
@@ -3,6 +3,7 @@ package dscache
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -24,7 +25,7 @@ func TestFetchBundleCache(t *testing.T) {
 	bundle2 := &common.Bundle{TrustDomainId: "spiffe://domain.test", RefreshHint: 2}
 	ds := fakedatastore.New(t)
 	clock := clock.NewMock(t)
-	cache := New(ds, clock)
+	cache := New(ds, clock, Config{})
 	ctxWithCache := WithCache(context.Background())
 	ctxWithoutCache := context.Background()
 
@@ -72,6 +73,119 @@ func TestFetchBundleCache(t *testing.T) {
 	spiretest.RequireProtoEqual(t, bundle1, bundle)
 }
 
+func TestFetchBundleCacheReadsBundleEvents(t *testing.T) {
+	td := "spiffe://domain.test"
+	bundle1, bundle2 := getBundles(t, td)
+	otherBundle, _ := getBundles(t, "spiffe://other.test")
+	ds := &countingDataStore{DataStore: fakedatastore.New(t)}
+	clock := clock.NewMock(t)
+	cache := New(ds, clock, Config{})
+	ctxWithCache := WithCache(context.Background())
+
+	_, err := ds.SetBundle(context.Background(), bundle1)
+	require.NoError(t, err)
+
+	// The first fetch reads the events and loads the bundle
+	bundle, err := cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle1, bundle)
+	require.Equal(t, 1, ds.fetchBundleCalls)
+	require.Equal(t, 1, ds.listBundleEventsCalls)
+
+	// Once the cached bundle expires, it is kept if there are no new events
+	clock.Add(datastoreCacheExpiry)
+	bundle, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle1, bundle)
+	require.Equal(t, 1, ds.fetchBundleCalls)
+	require.Equal(t, 2, ds.listBundleEventsCalls)
+
+	// The events are not read again until the bundle expires again
+	bundle, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle1, bundle)
+	require.Equal(t, 2, ds.listBundleEventsCalls)
+
+	// Events of other trust domains keep the bundle
+	_, err = ds.SetBundle(context.Background(), otherBundle)
+	require.NoError(t, err)
+	clock.Add(datastoreCacheExpiry)
+	bundle, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle1, bundle)
+	require.Equal(t, 1, ds.fetchBundleCalls)
+	require.Equal(t, 3, ds.listBundleEventsCalls)
+
+	// A change written by another server creates an event and the bundle
+	// is reloaded
+	_, err = ds.SetBundle(context.Background(), bundle2)
+	require.NoError(t, err)
+	clock.Add(datastoreCacheExpiry)
+	bundle, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle2, bundle)
+	require.Equal(t, 2, ds.fetchBundleCalls)
+	require.Equal(t, 4, ds.listBundleEventsCalls)
+
+	// Failing to read the events fails the fetch
+	clock.Add(datastoreCacheExpiry)
+	ds.DataStore.(*fakedatastore.DataStore).SetNextError(errors.New("oh no"))
+	_, err = cache.FetchBundle(ctxWithCache, td)
+	require.EqualError(t, err, "oh no")
+}
+
+func TestFetchBundleCacheMaxTTL(t *testing.T) {
+	td := "spiffe://domain.test"
+	bundle1, _ := getBundles(t, td)
+	ds := &countingDataStore{DataStore: fakedatastore.New(t)}
+	clock := clock.NewMock(t)
+	cache := New(ds, clock, Config{BundleMaxTTL: time.Minute})
+	ctxWithCache := WithCache(context.Background())
+
+	_, err := ds.SetBundle(context.Background(), bundle1)
+	require.NoError(t, err)
+
+	_, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	require.Equal(t, 1, ds.fetchBundleCalls)
+
+	// Before the max TTL, the unchanged bundle is kept
+	clock.Add(time.Minute - time.Millisecond)
+	_, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	require.Equal(t, 1, ds.fetchBundleCalls)
+	require.Equal(t, 2, ds.listBundleEventsCalls)
+
+	// Past the max TTL, the bundle is reloaded even though it did not change
+	clock.Add(time.Millisecond)
+	bundle, err := cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle1, bundle)
+	require.Equal(t, 2, ds.fetchBundleCalls)
+	require.Equal(t, 2, ds.listBundleEventsCalls)
+}
+
+func TestFetchBundleCacheDisabled(t *testing.T) {
+	td := "spiffe://domain.test"
+	bundle1, bundle2 := getBundles(t, td)
+	ds := fakedatastore.New(t)
+	cache := New(ds, clock.NewMock(t), Config{DisableBundleCache: true})
+	ctxWithCache := WithCache(context.Background())
+
+	_, err := ds.SetBundle(context.Background(), bundle1)
+	require.NoError(t, err)
+	bundle, err := cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle1, bundle)
+
+	// Changes are seen right away
+	_, err = ds.SetBundle(context.Background(), bundle2)
+	require.NoError(t, err)
+	bundle, err = cache.FetchBundle(ctxWithCache, td)
+	require.NoError(t, err)
+	spiretest.RequireProtoEqual(t, bundle2, bundle)
+}
+
 func TestBundleInvalidations(t *testing.T) {
 	td := "spiffe://domain.test"
 	bundle1, bundle2 := getBundles(t, "spiffe://domain.test")
@@ -150,7 +264,7 @@ func TestBundleInvalidations(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Create datastore and cache
 			ds := fakedatastore.New(t)
-			cache := New(ds, clock.NewMock(t))
+			cache := New(ds, clock.NewMock(t), Config{})
 			ctxWithCache := WithCache(context.Background())
 
 			// Add bundle (bundle1)
@@ -229,3 +343,22 @@ func getKeys(t *testing.T) []*common.PublicKey {
 		},
 	}
 }
+
+// countingDataStore counts the calls made to fetch bundles and to list the
+// bundle events.
+type countingDataStore struct {
+	datastore.DataStore
+
+	fetchBundleCalls      int
+	listBundleEventsCalls int
+}
+
+func (ds *countingDataStore) FetchBundle(ctx context.Context, trustDomain string) (*common.Bundle, error) {
+	ds.fetchBundleCalls++
+	return ds.DataStore.FetchBundle(ctx, trustDomain)
+}
+
+func (ds *countingDataStore) ListBundleEvents(ctx context.Context, req *datastore.ListBundleEventsRequest) (*datastore.ListBundleEventsResponse, error) {
+	ds.listBundleEventsCalls++
+	return ds.DataStore.ListBundleEvents(ctx, req)
+}
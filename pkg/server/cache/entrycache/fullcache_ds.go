@@ -46,8 +46,11 @@ func (it *entryIteratorDS) Next(ctx context.Context) bool {
 		return false
 	}
 	if it.entries == nil || (it.next >= len(it.entries) && it.paginationToken != "") {
+		// Inactive entries are not used to issue SVIDs
+		active := true
 		req := &datastore.ListRegistrationEntriesRequest{
 			DataConsistency: datastore.TolerateStale,
+			ByActive:        &active,
 			Pagination: &datastore.Pagination{
 				Token:    it.paginationToken,
 				PageSize: listEntriesRequestPageSize,
@@ -84,6 +84,19 @@ func TestEntryIteratorDS(t *testing.T) {
 		// it.Next() returns false after encountering an error on previous call to Next()
 		assert.False(t, it.Next(ctx))
 	})
+
+	t.Run("inactive entries are skipped", func(t *testing.T) {
+		_, err := ds.SetRegistrationEntryActive(ctx, expectedEntries[0].Id, false)
+		require.NoError(t, err)
+
+		it := makeEntryIteratorDS(ds)
+		var entries []*types.Entry
+		for it.Next(ctx) {
+			entries = append(entries, it.Entry())
+		}
+		assert.NoError(t, it.Err())
+		assert.ElementsMatch(t, expectedEntries[1:], entries)
+	})
 }
 
 func TestAgentIteratorDS(t *testing.T) {
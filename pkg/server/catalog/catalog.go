@@ -66,6 +66,9 @@ type Config struct {
 	IdentityProvider *identityprovider.IdentityProvider
 	AgentStore       *agentstore.AgentStore
 	HealthChecker    health.Checker
+
+	// DataStoreCache configures the cache in front of the datastore.
+	DataStoreCache dscache.Config
 }
 
 type datastoreRepository struct{ datastore.Repository }
@@ -172,7 +175,7 @@ func Load(ctx context.Context, config Config) (_ *Repository, err error) {
 	})
 
 	dataStore = ds_telemetry.WithMetrics(dataStore, config.Metrics)
-	dataStore = dscache.New(dataStore, clock.New())
+	dataStore = dscache.New(dataStore, clock.New(), config.DataStoreCache)
 
 	repo.SetDataStore(dataStore)
 	repo.SetKeyManager(km_telemetry.WithMetrics(repo.GetKeyManager(), config.Metrics))
@@ -202,6 +205,7 @@ func loadSQLDataStore(ctx context.Context, config Config, coreConfig catalog.Cor
 
 	dsLog := config.Log.WithField(telemetry.SubsystemName, sqlConfig.Name)
 	ds := ds_sql.New(dsLog)
+	ds.SetMetrics(config.Metrics)
 	configurer := catalog.ConfigurerFunc(func(ctx context.Context, _ catalog.CoreConfig, configuration string) error {
 		return ds.Configure(ctx, configuration)
 	})
@@ -108,6 +108,14 @@ type Config struct {
 	// SQLTransactionTimeout controls how long to wait for an event before giving up
 	SQLTransactionTimeout time.Duration
 
+	// DisableBundleCache disables the in-memory cache of bundles read from
+	// the datastore
+	DisableBundleCache bool
+
+	// BundleCacheMaxTTL controls how long a bundle can be served from the
+	// in-memory cache before it is reloaded from the datastore
+	BundleCacheMaxTTL time.Duration
+
 	// AuthPolicyEngineConfig determines the config for authz policy
 	AuthOpaPolicyEngineConfig *authpolicy.OpaEngineConfig
 
@@ -122,6 +130,22 @@ type Config struct {
 	// back to the default X509 CA TTL).
 	UseLegacyDownstreamX509CATTL bool
 
+	// EntryIssuanceMetrics enables a counter of the SVIDs signed for each
+	// registration entry, emitted for the sample of the signings given by
+	// EntryIssuanceMetricsSampleRate.
+	EntryIssuanceMetrics           bool
+	EntryIssuanceMetricsSampleRate float64
+
+	// EntryExpiryWarningWindow, if set, reports the registration entries
+	// that expire within this window, every EntryExpiryCheckInterval.
+	EntryExpiryWarningWindow time.Duration
+	EntryExpiryCheckInterval time.Duration
+
+	// PruneAttestedNodesExpiredFor, if set, prunes the attested nodes that
+	// expired at least this long ago, every PruneAttestedNodesInterval.
+	PruneAttestedNodesExpiredFor time.Duration
+	PruneAttestedNodesInterval   time.Duration
+
 	// TLSPolicy determines the policy settings to apply to all TLS connections.
 	TLSPolicy tlspolicy.Policy
 }
@@ -135,6 +159,12 @@ type FederationConfig struct {
 	// FederatesWith holds the federation configuration for trust domains this
 	// server federates with.
 	FederatesWith map[spiffeid.TrustDomain]bundle_client.TrustDomainConfig
+	// PollFailureThreshold is the number of consecutive failed bundle
+	// endpoint polls after which polling backs off. Zero uses the default.
+	PollFailureThreshold int
+	// PollMaxBackoff caps the interval between polls of a failing bundle
+	// endpoint. Zero uses the default.
+	PollMaxBackoff time.Duration
 }
 
 func New(config Config) *Server {
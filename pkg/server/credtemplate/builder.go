@@ -323,10 +323,7 @@ func (b *Builder) BuildWorkloadJWTSVIDClaims(ctx context.Context, params Workloa
 
 	now := b.config.Clock.Now()
 
-	ttl := params.TTL
-	if ttl <= 0 {
-		ttl = b.config.JWTSVIDTTL
-	}
+	ttl := EffectiveSVIDTTL(params.TTL, b.config.JWTSVIDTTL, 0)
 	_, expiresAt := computeCappedLifetime(b.config.Clock, ttl, params.ExpirationCap)
 
 	attributes := credentialcomposer.JWTSVIDAttributes{
@@ -450,9 +447,7 @@ func (b *Builder) computeX509CALifetime(parentChain []*x509.Certificate, ttl tim
 }
 
 func (b *Builder) computeX509SVIDLifetime(parentChain []*x509.Certificate, ttl time.Duration) (notBefore, notAfter time.Time) {
-	if ttl <= 0 {
-		ttl = b.config.X509SVIDTTL
-	}
+	ttl = EffectiveSVIDTTL(ttl, b.config.X509SVIDTTL, 0)
 	return computeCappedLifetime(b.config.Clock, ttl, parentChainExpiration(parentChain))
 }
 
@@ -483,6 +478,20 @@ func applyX509SVIDAttributes(tmpl *x509.Certificate, attribs credentialcomposer.
 	tmpl.ExtraExtensions = attribs.ExtraExtensions
 }
 
+// EffectiveSVIDTTL returns the TTL given to an SVID requested with the given
+// TTL: the default TTL when the requested one is not set, capped to maxTTL,
+// if set. The lifetime of an issued SVID is further capped to the expiration
+// of the CA or key signing it.
+func EffectiveSVIDTTL(ttl, defaultTTL, maxTTL time.Duration) time.Duration {
+	if ttl <= 0 {
+		ttl = defaultTTL
+	}
+	if maxTTL > 0 && ttl > maxTTL {
+		ttl = maxTTL
+	}
+	return ttl
+}
+
 func computeCappedLifetime(clk clock.Clock, ttl time.Duration, expirationCap time.Time) (notBefore, notAfter time.Time) {
 	now := clk.Now()
 	notBefore = now.Add(-NotBeforeCushion)
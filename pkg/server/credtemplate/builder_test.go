@@ -1221,6 +1221,25 @@ func TestBuildWorkloadJWTSVIDClaims(t *testing.T) {
 	}
 }
 
+func TestEffectiveSVIDTTL(t *testing.T) {
+	for _, tc := range []struct {
+		desc      string
+		ttl       time.Duration
+		maxTTL    time.Duration
+		expectTTL time.Duration
+	}{
+		{desc: "requested TTL", ttl: time.Minute, expectTTL: time.Minute},
+		{desc: "default TTL", expectTTL: time.Hour},
+		{desc: "requested TTL within the max", ttl: time.Minute, maxTTL: 2 * time.Minute, expectTTL: time.Minute},
+		{desc: "requested TTL capped to the max", ttl: 3 * time.Minute, maxTTL: 2 * time.Minute, expectTTL: 2 * time.Minute},
+		{desc: "default TTL capped to the max", maxTTL: 2 * time.Minute, expectTTL: 2 * time.Minute},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expectTTL, credtemplate.EffectiveSVIDTTL(tc.ttl, time.Hour, tc.maxTTL))
+		})
+	}
+}
+
 func testBuilder(t *testing.T, overrideConfig func(config *credtemplate.Config), fn func(*testing.T, *credtemplate.Builder)) {
 	config := credtemplate.Config{
 		TrustDomain:     td,
@@ -2,27 +2,39 @@ package datastore
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"time"
 
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	types "github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // DataStore defines the data storage interface.
 type DataStore interface {
+	// Ping checks the database is reachable
+	Ping(context.Context) error
+
 	// Bundles
 	AppendBundle(context.Context, *common.Bundle) (*common.Bundle, error)
 	CountBundles(context.Context) (int32, error)
 	CreateBundle(context.Context, *common.Bundle) (*common.Bundle, error)
 	DeleteBundle(ctx context.Context, trustDomainID string, mode DeleteMode) error
 	FetchBundle(ctx context.Context, trustDomainID string) (*common.Bundle, error)
+	FetchBundleContentHash(ctx context.Context, trustDomainID string) (string, error)
 	ListBundles(context.Context, *ListBundlesRequest) (*ListBundlesResponse, error)
+	ListBundleAuthoritiesToPrune(ctx context.Context, trustDomainID string, expiresBefore time.Time) (*BundleAuthoritiesToPrune, error)
 	PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (changed bool, err error)
 	SetBundle(context.Context, *common.Bundle) (*common.Bundle, error)
+	SetBundleRefreshedAt(ctx context.Context, trustDomainID string, refreshedAt time.Time) error
 	UpdateBundle(context.Context, *common.Bundle, *common.BundleMask) (*common.Bundle, error)
 
+	// Bundles Events
+	ListBundleEvents(ctx context.Context, req *ListBundleEventsRequest) (*ListBundleEventsResponse, error)
+
 	// Keys
 	TaintX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToTaint string) error
 	RevokeX509CA(ctx context.Context, trustDomainID string, subjectKeyIDToRevoke string) error
@@ -31,42 +43,72 @@ type DataStore interface {
 
 	// Entries
 	CountRegistrationEntries(context.Context, *CountRegistrationEntriesRequest) (int32, error)
+	CountRegistrationEntriesByTrustDomain(context.Context) (map[string]int32, error)
+	CreateRegistrationEntries(context.Context, []*common.RegistrationEntry) ([]*CreateRegistrationEntryResult, error)
 	CreateRegistrationEntry(context.Context, *common.RegistrationEntry) (*common.RegistrationEntry, error)
 	CreateOrReturnRegistrationEntry(context.Context, *common.RegistrationEntry) (*common.RegistrationEntry, bool, error)
+	DedupeEntrySelectors(ctx context.Context) (int, error)
 	DeleteRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error)
+	ExplainListRegistrationEntries(context.Context, *ListRegistrationEntriesRequest) (string, error)
 	FetchRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error)
+	FetchRegistrationEntryWithParents(ctx context.Context, entryID string) ([]*common.RegistrationEntry, error)
+	FindOrphanedEntryChildren(ctx context.Context, includeIDs bool) (*OrphanedEntryChildren, error)
 	ListRegistrationEntries(context.Context, *ListRegistrationEntriesRequest) (*ListRegistrationEntriesResponse, error)
+	ListRegistrationEntriesByFederatesWith(ctx context.Context, trustDomain string, pagination *Pagination) (*ListRegistrationEntriesResponse, error)
+	ListRegistrationEntriesExpiringBefore(ctx context.Context, expiresBefore time.Time) ([]string, error)
+	ListRegistrationEntriesToPrune(ctx context.Context, expiresBefore time.Time) ([]string, error)
+	ListRegistrationEntriesWithExcessDNSNames(ctx context.Context) ([]string, error)
+	PruneOrphanedEntryChildren(ctx context.Context) (*OrphanedEntryChildren, error)
 	PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) error
+	SetRegistrationEntryActive(ctx context.Context, entryID string, active bool) (*common.RegistrationEntry, error)
+	StreamRegistrationEntries(ctx context.Context, req *ListRegistrationEntriesRequest, fn func(*common.RegistrationEntry) error) error
 	UpdateRegistrationEntry(context.Context, *common.RegistrationEntry, *common.RegistrationEntryMask) (*common.RegistrationEntry, error)
 
 	// Entries Events
 	ListRegistrationEntryEvents(ctx context.Context, req *ListRegistrationEntryEventsRequest) (*ListRegistrationEntryEventsResponse, error)
 	PruneRegistrationEntryEvents(ctx context.Context, olderThan time.Duration) error
+	CompactRegistrationEntryEvents(ctx context.Context) (int, error)
 	FetchRegistrationEntryEvent(ctx context.Context, eventID uint) (*RegistrationEntryEvent, error)
 	CreateRegistrationEntryEventForTesting(ctx context.Context, event *RegistrationEntryEvent) error
 	DeleteRegistrationEntryEventForTesting(ctx context.Context, eventID uint) error
 
 	// Nodes
+	AddAttestedNodeAlias(ctx context.Context, spiffeID, alias string) error
+	AttestedNodeExists(ctx context.Context, spiffeID string) (bool, error)
 	CountAttestedNodes(context.Context, *CountAttestedNodesRequest) (int32, error)
 	CreateAttestedNode(context.Context, *common.AttestedNode) (*common.AttestedNode, error)
 	DeleteAttestedNode(ctx context.Context, spiffeID string) (*common.AttestedNode, error)
 	FetchAttestedNode(ctx context.Context, spiffeID string) (*common.AttestedNode, error)
+	FetchAttestedNodeSerialHistory(ctx context.Context, spiffeID string) ([]*AttestedNodeSerial, error)
+	ListAttestedNodeAliases(ctx context.Context, spiffeID string) ([]string, error)
 	ListAttestedNodes(context.Context, *ListAttestedNodesRequest) (*ListAttestedNodesResponse, error)
+	ListAttestedNodesToPrune(ctx context.Context, expiredBefore time.Time) ([]string, error)
+	PromoteAttestedNodeSerial(ctx context.Context, spiffeID, expectedNewSerial string) (*common.AttestedNode, error)
+	PruneAttestedNodes(ctx context.Context, expiredBefore time.Time) (int, error)
+	RemoveAttestedNodeAlias(ctx context.Context, alias string) error
+	SetAttestedNodesReattest(ctx context.Context, spiffeIDs []string, canReattest bool) (int, error)
 	UpdateAttestedNode(context.Context, *common.AttestedNode, *common.AttestedNodeMask) (*common.AttestedNode, error)
 
 	// Nodes Events
 	ListAttestedNodeEvents(ctx context.Context, req *ListAttestedNodeEventsRequest) (*ListAttestedNodeEventsResponse, error)
 	PruneAttestedNodeEvents(ctx context.Context, olderThan time.Duration) error
+	CompactAttestedNodeEvents(ctx context.Context) (int, error)
 	FetchAttestedNodeEvent(ctx context.Context, eventID uint) (*AttestedNodeEvent, error)
 	CreateAttestedNodeEventForTesting(ctx context.Context, event *AttestedNodeEvent) error
 	DeleteAttestedNodeEventForTesting(ctx context.Context, eventID uint) error
 
+	// Events
+	PruneEvents(ctx context.Context, olderThan time.Time) error
+
 	// Node selectors
-	GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency DataConsistency) ([]*common.Selector, error)
+	GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency DataConsistency, excludeExpired bool) ([]*common.Selector, error)
 	ListNodeSelectors(context.Context, *ListNodeSelectorsRequest) (*ListNodeSelectorsResponse, error)
+	PruneNodeSelectors(ctx context.Context, expiredBefore time.Time) (int, error)
 	SetNodeSelectors(ctx context.Context, spiffeID string, selectors []*common.Selector) error
+	SetNodeSelectorsBulk(ctx context.Context, selectorsBySpiffeID map[string][]*common.Selector) error
 
 	// Tokens
+	ConsumeJoinToken(ctx context.Context, token string) (*JoinToken, error)
 	CreateJoinToken(context.Context, *JoinToken) error
 	DeleteJoinToken(ctx context.Context, token string) error
 	FetchJoinToken(ctx context.Context, token string) (*JoinToken, error)
@@ -78,10 +120,12 @@ type DataStore interface {
 	ListFederationRelationships(context.Context, *ListFederationRelationshipsRequest) (*ListFederationRelationshipsResponse, error)
 	DeleteFederationRelationship(context.Context, spiffeid.TrustDomain) error
 	UpdateFederationRelationship(context.Context, *FederationRelationship, *types.FederationRelationshipMask) (*FederationRelationship, error)
+	SetFederationRelationshipLastPoll(ctx context.Context, trustDomain spiffeid.TrustDomain, polledAt, nextPollAt time.Time, pollErr string) error
 
 	// CA Journals
 	SetCAJournal(ctx context.Context, caJournal *CAJournal) (*CAJournal, error)
 	FetchCAJournal(ctx context.Context, activeX509AuthorityID string) (*CAJournal, error)
+	PruneCAJournal(ctx context.Context, retention time.Duration) error
 	PruneCAJournals(ctx context.Context, allCAsExpireBefore int64) error
 	ListCAJournalsForTesting(ctx context.Context) ([]*CAJournal, error)
 }
@@ -132,6 +176,12 @@ const (
 	Subset   MatchBehavior = 1
 	Superset MatchBehavior = 2
 	MatchAny MatchBehavior = 3
+
+	// MatchAll matches the attested nodes that have all the given
+	// selectors, and possibly others. The nodes are found with a single
+	// grouped query. It is only supported when listing or counting attested
+	// nodes by selectors.
+	MatchAll MatchBehavior = 4
 )
 
 type ByFederatesWith struct {
@@ -144,9 +194,21 @@ type BySelectors struct {
 	Match     MatchBehavior
 }
 
+// BySelectorValuePrefix matches entries that have at least one selector of
+// the given type whose value starts with the given prefix. When used along
+// with BySelectors, entries must satisfy both filters.
+type BySelectorValuePrefix struct {
+	Type   string
+	Prefix string
+}
+
 type JoinToken struct {
 	Token  string
 	Expiry time.Time
+
+	// RemainingUses is the number of times the token can still be used to
+	// attest an agent. Tokens are created with a single use if unset.
+	RemainingUses int
 }
 
 type Pagination struct {
@@ -158,10 +220,15 @@ type ListAttestedNodesRequest struct {
 	ByAttestationType string
 	ByBanned          *bool
 	ByExpiresBefore   time.Time
+	ByExpiresAfter    time.Time
 	BySelectorMatch   *BySelectors
-	FetchSelectors    bool
-	Pagination        *Pagination
-	ByCanReattest     *bool
+	// FetchSelectors tells whether the selectors of the nodes are returned.
+	// If nil, they are.
+	FetchSelectors *bool
+	Pagination     *Pagination
+	ByCanReattest  *bool
+	// ByUpdatedAfter lists the nodes updated at or after the given time.
+	ByUpdatedAfter time.Time
 }
 
 type ListAttestedNodesResponse struct {
@@ -172,6 +239,11 @@ type ListAttestedNodesResponse struct {
 type ListAttestedNodeEventsRequest struct {
 	GreaterThanEventID uint
 	LessThanEventID    uint
+
+	// RequireCompleteHistory, when set along with GreaterThanEventID, makes
+	// the listing fail with ErrEventHistoryUnavailable if the stored events
+	// may not include every event after GreaterThanEventID.
+	RequireCompleteHistory bool
 }
 
 type AttestedNodeEvent struct {
@@ -179,19 +251,99 @@ type AttestedNodeEvent struct {
 	SpiffeID string
 }
 
+// Reasons for which the serial number of an attested node is superseded
+const (
+	// SerialReasonRotated indicates that the node activated its new serial number
+	SerialReasonRotated = "rotated"
+	// SerialReasonBanned indicates that the node was banned
+	SerialReasonBanned = "banned"
+	// SerialReasonReplaced indicates that the serial number was replaced
+	// without a rotation (e.g. the node re-attested)
+	SerialReasonReplaced = "replaced"
+)
+
+// AttestedNodeSerial is a serial number previously used by an attested node
+type AttestedNodeSerial struct {
+	SerialNumber string
+	SupersededAt time.Time
+	Reason       string
+}
+
 type ListAttestedNodeEventsResponse struct {
+	// Events are the events matching the request, ordered by ID.
 	Events []AttestedNodeEvent
+
+	// FirstEventID and LastEventID are the lowest and highest IDs of all the
+	// stored events, regardless of the request bounds, or zero if there are
+	// none. A reader whose last seen event ID is lower than FirstEventID-1
+	// missed events that were pruned.
+	FirstEventID uint
+	LastEventID  uint
+}
+
+type ListBundleEventsRequest struct {
+	GreaterThanEventID uint
+	LessThanEventID    uint
+}
+
+type BundleEvent struct {
+	EventID     uint
+	TrustDomain string
+}
+
+type ListBundleEventsResponse struct {
+	Events []BundleEvent
 }
 
 type ListBundlesRequest struct {
 	Pagination *Pagination
+
+	// ExcludeData lists only the metadata of the bundles, without loading
+	// their contents. Metadata is returned instead of Bundles.
+	ExcludeData bool
 }
 
 type ListBundlesResponse struct {
 	Bundles    []*common.Bundle
+	Metadata   []*BundleMetadata
 	Pagination *Pagination
 }
 
+// BundleMetadata describes a bundle without its contents.
+type BundleMetadata struct {
+	TrustDomainID string
+
+	// ContentHash is the hash of the bundle contents, as returned by
+	// FetchBundleContentHash.
+	ContentHash string
+
+	// LastRefreshedAt is the last time the bundle was refreshed from its
+	// bundle endpoint, or zero if it never was.
+	LastRefreshedAt time.Time
+}
+
+// BundleAuthoritiesToPrune holds the authorities that pruning a bundle would
+// remove.
+type BundleAuthoritiesToPrune struct {
+	// X509AuthorityIDs are the subject key IDs of the X.509 authorities
+	X509AuthorityIDs []string
+
+	// JWTAuthorityIDs are the key IDs of the JWT authorities
+	JWTAuthorityIDs []string
+}
+
+// OrphanedEntryChildren describes the selectors and DNS names left behind by
+// registration entries that no longer exist.
+type OrphanedEntryChildren struct {
+	Selectors int
+	DNSNames  int
+
+	// SelectorIDs and DNSNameIDs are the database IDs of the orphaned rows.
+	// They are only set when requested.
+	SelectorIDs []uint
+	DNSNameIDs  []uint
+}
+
 type ListNodeSelectorsRequest struct {
 	DataConsistency DataConsistency
 	ValidAt         time.Time
@@ -208,16 +360,146 @@ type ListRegistrationEntriesRequest struct {
 	BySpiffeID      string
 	Pagination      *Pagination
 	ByFederatesWith *ByFederatesWith
-	ByHint          string
+	ByHint          *string
+	ByAdmin         *bool
 	ByDownstream    *bool
+	ByCreatedBy     string
+	ByStoreSvid     *bool
+	ByActive        *bool
+
+	BySelectorValuePrefix *BySelectorValuePrefix
+
+	// ByRevisionGreaterThan, when positive, lists only the entries with a
+	// revision number greater than it. The revision number of an entry
+	// starts at zero and is incremented each time the entry is updated, so
+	// it is monotonic per entry and not across entries: it tells that an
+	// entry changed since a revision of it was seen, but not which entries
+	// changed since a point in time. Entries created or deleted since are
+	// not reported either. The registration entry events should be used to
+	// follow all changes.
+	ByRevisionGreaterThan int64
+
+	// OrderBy sets the order in which entries are listed. Entries are
+	// listed in creation order by default.
+	OrderBy EntryOrder
 }
 
+// EntryOrder is the order in which registration entries are listed.
+type EntryOrder string
+
+const (
+	// OrderByExpiryAsc lists entries with the earliest expiry first.
+	// Entries without an expiry are listed last.
+	OrderByExpiryAsc EntryOrder = "expiry_asc"
+
+	// OrderByExpiryDesc lists entries with the latest expiry first.
+	// Entries without an expiry are listed last.
+	OrderByExpiryDesc EntryOrder = "expiry_desc"
+
+	// OrderByRevisionAsc lists entries with the lowest revision number
+	// first.
+	OrderByRevisionAsc EntryOrder = "revision_asc"
+)
+
 type CAJournal struct {
 	ID                    uint
 	Data                  []byte
 	ActiveX509AuthorityID string
 }
 
+// CreateRegistrationEntryResult is the outcome of creating one of the
+// entries passed to CreateRegistrationEntries.
+type CreateRegistrationEntryResult struct {
+	// Entry is the created entry, or the similar entry that already existed.
+	// It is nil if Err is set.
+	Entry *common.RegistrationEntry
+
+	// Existing is true if a similar entry already existed and was returned
+	// instead of creating a new one.
+	Existing bool
+
+	// Err is set if the entry could not be created.
+	Err error
+}
+
+// ErrEventHistoryUnavailable is returned when listing events with
+// RequireCompleteHistory if events the reader has not seen may have been lost:
+// the events table is empty while the entities it tracks are not (e.g. after
+// restoring a backup without the events), the events following the last seen
+// event were pruned, or the event IDs were reset. The reader should reload
+// every entity and list the events again without a lower bound.
+var ErrEventHistoryUnavailable = status.Error(codes.FailedPrecondition, "event history unavailable")
+
+// ErrEntryParentCycle is returned by FetchRegistrationEntryWithParents when
+// the parent chain of the entry leads back to an entry already in the chain.
+var ErrEntryParentCycle = status.Error(codes.FailedPrecondition, "registration entry parent chain has a cycle")
+
+// ErrEntryParentChainTooDeep is returned by FetchRegistrationEntryWithParents
+// when the entry has more parent entries than the maximum depth walked.
+var ErrEntryParentChainTooDeep = status.Error(codes.FailedPrecondition, "registration entry parent chain exceeds the maximum depth")
+
+// ErrAttestedNodeSerialConflict is returned by PromoteAttestedNodeSerial when
+// the new serial number of the node is not the expected one, e.g. because it
+// was already promoted or replaced by another server.
+var ErrAttestedNodeSerialConflict = status.Error(codes.Aborted, "attested node new serial number does not match the expected one")
+
+// InvalidDNSNamesError is returned when a registration entry is created or
+// updated with DNS names that are not valid hostnames or "*.domain"
+// wildcards. It carries the InvalidArgument code.
+type InvalidDNSNamesError struct {
+	// DNSNames are the offending DNS names, as they were given.
+	DNSNames []string
+}
+
+func (e *InvalidDNSNamesError) Error() string {
+	return fmt.Sprintf("invalid registration entry: invalid DNS names %q", e.DNSNames)
+}
+
+func (e *InvalidDNSNamesError) GRPCStatus() *status.Status {
+	return status.New(codes.InvalidArgument, e.Error())
+}
+
+// TooManyDNSNamesError is returned when a registration entry is created or
+// updated with more DNS names than the maximum per entry of the datastore.
+// It carries the InvalidArgument code.
+type TooManyDNSNamesError struct {
+	// Count is the number of distinct DNS names of the entry.
+	Count int
+
+	// MaxCount is the maximum number of DNS names per entry of the datastore.
+	MaxCount int
+}
+
+func (e *TooManyDNSNamesError) Error() string {
+	return fmt.Sprintf("invalid registration entry: %d DNS names exceeds the maximum of %d", e.Count, e.MaxCount)
+}
+
+func (e *TooManyDNSNamesError) GRPCStatus() *status.Status {
+	return status.New(codes.InvalidArgument, e.Error())
+}
+
+// BundleTooLargeError is returned when a bundle is created or updated with
+// data larger than the maximum bundle size of the datastore. It carries the
+// InvalidArgument code.
+type BundleTooLargeError struct {
+	// TrustDomainID is the trust domain of the bundle.
+	TrustDomainID string
+
+	// Size is the size of the bundle data, in bytes.
+	Size int
+
+	// MaxSize is the maximum bundle size of the datastore, in bytes.
+	MaxSize int
+}
+
+func (e *BundleTooLargeError) Error() string {
+	return fmt.Sprintf("bundle of %q is too large: %d bytes exceeds the maximum of %d bytes", e.TrustDomainID, e.Size, e.MaxSize)
+}
+
+func (e *BundleTooLargeError) GRPCStatus() *status.Status {
+	return status.New(codes.InvalidArgument, e.Error())
+}
+
 type ListRegistrationEntriesResponse struct {
 	Entries    []*common.RegistrationEntry
 	Pagination *Pagination
@@ -226,6 +508,11 @@ type ListRegistrationEntriesResponse struct {
 type ListRegistrationEntryEventsRequest struct {
 	GreaterThanEventID uint
 	LessThanEventID    uint
+
+	// RequireCompleteHistory, when set along with GreaterThanEventID, makes
+	// the listing fail with ErrEventHistoryUnavailable if the stored events
+	// may not include every event after GreaterThanEventID.
+	RequireCompleteHistory bool
 }
 
 type RegistrationEntryEvent struct {
@@ -234,10 +521,24 @@ type RegistrationEntryEvent struct {
 }
 
 type ListRegistrationEntryEventsResponse struct {
+	// Events are the events matching the request, ordered by ID.
 	Events []RegistrationEntryEvent
+
+	// FirstEventID and LastEventID are the lowest and highest IDs of all the
+	// stored events, regardless of the request bounds, or zero if there are
+	// none. A reader whose last seen event ID is lower than FirstEventID-1
+	// missed events that were pruned.
+	FirstEventID uint
+	LastEventID  uint
 }
 
 type ListFederationRelationshipsRequest struct {
+	// ByBundleEndpointProfile lists the relationships with the given bundle
+	// endpoint profile, if set.
+	ByBundleEndpointProfile BundleEndpointType
+	// ByImplicit lists the relationships that implicitly federate, or not,
+	// with all the registration entries, if set.
+	ByImplicit *bool
 	Pagination *Pagination
 }
 
@@ -246,23 +547,36 @@ type ListFederationRelationshipsResponse struct {
 	Pagination              *Pagination
 }
 
+// CountAttestedNodesRequest counts the attested nodes that
+// ListAttestedNodesRequest would list with the same filters.
 type CountAttestedNodesRequest struct {
 	ByAttestationType string
 	ByBanned          *bool
 	ByExpiresBefore   time.Time
+	ByExpiresAfter    time.Time
 	BySelectorMatch   *BySelectors
 	FetchSelectors    bool
 	ByCanReattest     *bool
+	ByUpdatedAfter    time.Time
 }
 
+// CountRegistrationEntriesRequest counts the registration entries that
+// ListRegistrationEntriesRequest would list with the same filters.
 type CountRegistrationEntriesRequest struct {
 	DataConsistency DataConsistency
 	ByParentID      string
 	BySelectors     *BySelectors
 	BySpiffeID      string
 	ByFederatesWith *ByFederatesWith
-	ByHint          string
+	ByHint          *string
+	ByAdmin         *bool
 	ByDownstream    *bool
+	ByCreatedBy     string
+	ByStoreSvid     *bool
+	ByActive        *bool
+
+	BySelectorValuePrefix *BySelectorValuePrefix
+	ByRevisionGreaterThan int64
 }
 
 type BundleEndpointType string
@@ -280,4 +594,24 @@ type FederationRelationship struct {
 
 	// Fields only used for 'https_spiffe' bundle endpoint profile
 	EndpointSPIFFEID spiffeid.ID
+
+	// RefreshHint is how often the bundle of the trust domain is polled. It
+	// overrides the refresh hint of the bundle itself when set. Since the
+	// update mask has no field for it, it is only updated when non-zero.
+	RefreshHint time.Duration
+
+	// LastPollAt is the last time the bundle of the trust domain was polled
+	// from the bundle endpoint. It is zero if it has never been polled.
+	LastPollAt time.Time
+
+	// LastPollError is the error of the last poll, truncated to a maximum
+	// length. It is empty if the last poll succeeded. Both LastPollAt and
+	// LastPollError are only set with SetFederationRelationshipLastPoll.
+	LastPollError string
+
+	// NextPollAt is when the bundle of the trust domain is polled next,
+	// which is later than usual while the polls keep failing. It is zero if
+	// it has never been scheduled. It is only set with
+	// SetFederationRelationshipLastPoll.
+	NextPollAt time.Time
 }
@@ -15,27 +15,23 @@ func (h *Health) CheckHealth() health.State {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	_, err := h.DataStore.ListBundles(ctx, &ListBundlesRequest{})
-
-	// Both liveness and readiness are determined by the datastore's
-	// ability to list all the bundles.
-	ready := err == nil
-	live := err == nil
+	err := h.DataStore.Ping(ctx)
 
+	// Readiness is determined by the datastore's ability to reach the
+	// database. Liveness is not, so a brief outage of the database does not
+	// get the server restarted.
 	return health.State{
-		Live:  live,
-		Ready: ready,
+		Live:  true,
+		Ready: err == nil,
 		ReadyDetails: HealthDetails{
-			ListBundleErr: errString(err),
-		},
-		LiveDetails: HealthDetails{
-			ListBundleErr: errString(err),
+			PingErr: errString(err),
 		},
+		LiveDetails: HealthDetails{},
 	}
 }
 
 type HealthDetails struct {
-	ListBundleErr string `json:"list_bundle_err,omitempty"`
+	PingErr string `json:"ping_err,omitempty"`
 }
 
 func errString(err error) string {
@@ -0,0 +1,43 @@
+package datastore_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spiffe/spire/pkg/common/health"
+	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/test/fakes/fakedatastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	ds := fakedatastore.New(t)
+	h := &datastore.Health{DataStore: ds}
+
+	require.Equal(t, health.State{
+		Live:         true,
+		Ready:        true,
+		ReadyDetails: datastore.HealthDetails{},
+		LiveDetails:  datastore.HealthDetails{},
+	}, h.CheckHealth())
+
+	// The server is not ready while the database is unreachable, but it is
+	// still live
+	ds.SetNextError(errors.New("connection refused"))
+	require.Equal(t, health.State{
+		Live:  true,
+		Ready: false,
+		ReadyDetails: datastore.HealthDetails{
+			PingErr: "connection refused",
+		},
+		LiveDetails: datastore.HealthDetails{},
+	}, h.CheckHealth())
+
+	// The server is ready again once the database is reachable
+	require.Equal(t, health.State{
+		Live:         true,
+		Ready:        true,
+		ReadyDetails: datastore.HealthDetails{},
+		LiveDetails:  datastore.HealthDetails{},
+	}, h.CheckHealth())
+}
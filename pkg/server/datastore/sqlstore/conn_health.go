@@ -0,0 +1,324 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// openWithConnHealth opens the database like gorm.Open, except that the
+// connections of the pool are wrapped by a healthConnector, so connections
+// that failed with a connection error are discarded instead of being reused.
+func openWithConnHealth(dialectName, driverName, dsn string, isConnectionError func(error) bool) (*gorm.DB, error) {
+	// sql.Open does not connect, it is only used to look up the driver
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	probe.Close()
+
+	var connector driver.Connector = dsnConnector{driver: drv, dsn: dsn}
+	if driverCtx, ok := drv.(driver.DriverContext); ok {
+		connector, err = driverCtx.OpenConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	raw := sql.OpenDB(&healthConnector{
+		Connector:         connector,
+		isConnectionError: isConnectionError,
+	})
+	db, err := gorm.Open(dialectName, raw)
+	if err != nil {
+		// gorm only closes the databases it opens itself
+		raw.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// isNetworkError returns true if the error reports a failure of the network
+// connection to the database, regardless of the dialect.
+func isNetworkError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// retryRead runs a read operation, which reports whether it failed with an
+// error that is worth retrying, and runs it again with exponential backoff
+// until it succeeds or the configured maximum number of attempts is reached.
+// Reads have no side effects, so they can be run again after the connection
+// to the database is lost. By then the broken connection has been discarded,
+// so the next attempt gets a new one.
+func (db *sqlDB) retryRead(ctx context.Context, read func() (bool, error)) error {
+	delay := db.readRetryBaseDelay
+	for attempt := 1; ; attempt++ {
+		retryable, err := read()
+		if err == nil || !retryable || attempt >= db.readRetryMaxAttempts {
+			return err
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// isRetryable returns true if a transaction that failed with the error may
+// succeed when run again from scratch. On top of transient errors, read-only
+// transactions are retried when the connection to the database was lost.
+func (db *sqlDB) isRetryable(err error, readOnly bool) bool {
+	return db.dialect.isTransientError(err) || (readOnly && db.dialect.isConnectionError(err))
+}
+
+// dsnConnector is the driver.Connector of drivers that don't provide one.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// healthConnector opens connections that keep track of the errors returned
+// by the driver. Once a connection fails with an error that the dialect
+// reports as a connection error, it reports itself as invalid, so the pool
+// discards it instead of handing it out again. Drivers already do this for
+// the failures they detect, but not for every way a connection can break,
+// like the connection being reset while waiting for a response.
+//
+// Errors returned while reading rows are not tracked, but they are reported
+// by the driver the next time the connection is used.
+type healthConnector struct {
+	driver.Connector
+	isConnectionError func(error) bool
+}
+
+func (c *healthConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &healthConn{conn: conn, isConnectionError: c.isConnectionError}, nil
+}
+
+func (c *healthConnector) Close() error {
+	if closer, ok := c.Connector.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+type healthConn struct {
+	conn              driver.Conn
+	isConnectionError func(error) bool
+	broken            atomic.Bool
+}
+
+// check marks the connection as broken if the error is a connection error.
+// The error is returned as is.
+func (c *healthConn) check(err error) error {
+	if err != nil && c.isConnectionError(err) {
+		c.broken.Store(true)
+	}
+	return err
+}
+
+func (c *healthConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *healthConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, c.check(err)
+	}
+	return &healthStmt{Stmt: stmt, conn: c}, nil
+}
+
+func (c *healthConn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *healthConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *healthConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	var tx driver.Tx
+	var err error
+	if beginner, ok := c.conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(ctx, opts)
+	} else {
+		if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) || opts.ReadOnly {
+			return nil, errors.New("sql driver does not support non-default transaction options")
+		}
+		tx, err = c.conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+	}
+	if err != nil {
+		return nil, c.check(err)
+	}
+	return &healthTx{Tx: tx, conn: c}, nil
+}
+
+func (c *healthConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, c.check(err)
+	}
+	return rows, nil
+}
+
+func (c *healthConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	result, err := execer.ExecContext(ctx, query, args)
+	if err != nil {
+		return nil, c.check(err)
+	}
+	return result, nil
+}
+
+func (c *healthConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return c.check(pinger.Ping(ctx))
+	}
+	return nil
+}
+
+func (c *healthConn) ResetSession(ctx context.Context) error {
+	if c.broken.Load() {
+		return driver.ErrBadConn
+	}
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *healthConn) IsValid() bool {
+	if c.broken.Load() {
+		return false
+	}
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *healthConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type healthStmt struct {
+	driver.Stmt
+	conn *healthConn
+}
+
+func (s *healthStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		result, err := s.Stmt.Exec(values) //nolint:staticcheck // fallback for drivers without ExecContext
+		return result, s.conn.check(err)
+	}
+	result, err := execer.ExecContext(ctx, args)
+	return result, s.conn.check(err)
+}
+
+func (s *healthStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		values, err := namedValuesToValues(args)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := s.Stmt.Query(values) //nolint:staticcheck // fallback for drivers without QueryContext
+		return rows, s.conn.check(err)
+	}
+	rows, err := queryer.QueryContext(ctx, args)
+	return rows, s.conn.check(err)
+}
+
+func (s *healthStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	// The statement checker takes precedence over the connection one, so
+	// the connection one is used when the statement has none.
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return s.conn.CheckNamedValue(nv)
+}
+
+func (s *healthStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if converter, ok := s.Stmt.(driver.ColumnConverter); ok { //nolint:staticcheck // still used by some drivers
+		return converter.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+type healthTx struct {
+	driver.Tx
+	conn *healthConn
+}
+
+func (tx *healthTx) Commit() error {
+	return tx.conn.check(tx.Tx.Commit())
+}
+
+func (tx *healthTx) Rollback() error {
+	return tx.conn.check(tx.Tx.Rollback())
+}
+
+func namedValuesToValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, errors.New("sql driver does not support the use of named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
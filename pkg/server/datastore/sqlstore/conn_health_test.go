@@ -0,0 +1,284 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	resetErr := &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+
+	for _, tt := range []struct {
+		name    string
+		dialect dialect
+		err     error
+		connErr bool
+	}{
+		{name: "postgres connection failure", dialect: postgresDB{}, err: &pq.Error{Code: "08006"}, connErr: true},
+		{name: "postgres admin shutdown", dialect: postgresDB{}, err: &pq.Error{Code: "57P01"}, connErr: true},
+		{name: "postgres wrapped cannot connect now", dialect: postgresDB{}, err: newWrappedSQLError(&pq.Error{Code: "57P03"}), connErr: true},
+		{name: "postgres connection reset", dialect: postgresDB{}, err: resetErr, connErr: true},
+		{name: "postgres bad connection", dialect: postgresDB{}, err: driver.ErrBadConn, connErr: true},
+		{name: "postgres serialization failure", dialect: postgresDB{}, err: &pq.Error{Code: "40001"}},
+		{name: "postgres other error", dialect: postgresDB{}, err: errors.New("oh no")},
+		{name: "mysql invalid connection", dialect: mysqlDB{}, err: mysql.ErrInvalidConn, connErr: true},
+		{name: "mysql server shutdown", dialect: mysqlDB{}, err: &mysql.MySQLError{Number: 1053}, connErr: true},
+		{name: "mysql wrapped connection reset", dialect: mysqlDB{}, err: newWrappedSQLError(resetErr), connErr: true},
+		{name: "mysql unexpected EOF", dialect: mysqlDB{}, err: io.ErrUnexpectedEOF, connErr: true},
+		{name: "mysql deadlock", dialect: mysqlDB{}, err: &mysql.MySQLError{Number: 1213}},
+		{name: "mysql other error", dialect: mysqlDB{}, err: errors.New("oh no")},
+		{name: "sqlite", dialect: sqliteDB{}, err: resetErr},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.connErr, tt.dialect.isConnectionError(tt.err))
+		})
+	}
+}
+
+func TestHealthConnectorDiscardsBrokenConnections(t *testing.T) {
+	t.Run("without health tracking the broken connection is reused", func(t *testing.T) {
+		connector := &resetConnector{failures: 1}
+		db := sql.OpenDB(connector)
+		defer db.Close()
+
+		require.ErrorIs(t, queryOne(db), syscall.ECONNRESET)
+		require.ErrorIs(t, queryOne(db), syscall.ECONNRESET)
+		require.Equal(t, 1, connector.connectCount())
+	})
+
+	t.Run("with health tracking the broken connection is discarded", func(t *testing.T) {
+		connector := &resetConnector{failures: 1}
+		db := sql.OpenDB(&healthConnector{Connector: connector, isConnectionError: mysqlDB{}.isConnectionError})
+		defer db.Close()
+
+		require.ErrorIs(t, queryOne(db), syscall.ECONNRESET)
+		require.NoError(t, queryOne(db))
+		require.Equal(t, 2, connector.connectCount())
+	})
+}
+
+func TestReadRetriesAfterConnectionReset(t *testing.T) {
+	newPlugin := func(t *testing.T, connector *resetConnector) *Plugin {
+		raw := sql.OpenDB(&healthConnector{Connector: connector, isConnectionError: mysqlDB{}.isConnectionError})
+		gormDB, err := gorm.Open("mysql", raw)
+		require.NoError(t, err)
+		t.Cleanup(func() { gormDB.Close() })
+
+		log, _ := test.NewNullLogger()
+		ds := New(log)
+		ds.db = &sqlDB{
+			DB:                   gormDB,
+			raw:                  raw,
+			databaseType:         MySQL,
+			dialect:              mysqlDB{},
+			stmtCache:            newStmtCache(raw),
+			readRetryMaxAttempts: 2,
+			readRetryBaseDelay:   time.Millisecond,
+		}
+		return ds
+	}
+	readTx := func(ds *Plugin) error {
+		return ds.withReadTx(ctx, func(tx *gorm.DB) error {
+			var v int64
+			return tx.Raw("SELECT 1").Row().Scan(&v)
+		})
+	}
+	readQuery := func(ds *Plugin) error {
+		_, err := withStatementTimeout(ctx, ds.db, func(ctx context.Context) (int64, error) {
+			rows, err := ds.db.QueryContext(ctx, "SELECT 1")
+			if err != nil {
+				return 0, err
+			}
+			defer rows.Close()
+
+			var v int64
+			if !rows.Next() {
+				return 0, errors.New("no rows")
+			}
+			return v, rows.Scan(&v)
+		})
+		return err
+	}
+
+	for _, read := range []struct {
+		name string
+		run  func(ds *Plugin) error
+	}{
+		{name: "read transaction", run: readTx},
+		{name: "read query", run: readQuery},
+	} {
+		t.Run(read.name+" succeeds after a connection reset", func(t *testing.T) {
+			connector := &resetConnector{failures: 1}
+			ds := newPlugin(t, connector)
+			require.NoError(t, read.run(ds))
+		})
+
+		t.Run(read.name+" gives up after max attempts", func(t *testing.T) {
+			connector := &resetConnector{failures: 2}
+			ds := newPlugin(t, connector)
+			require.ErrorContains(t, read.run(ds), "connection reset by peer")
+			require.Zero(t, connector.failuresLeft())
+		})
+	}
+
+	t.Run("write transaction is not retried", func(t *testing.T) {
+		connector := &resetConnector{failures: 1}
+		ds := newPlugin(t, connector)
+		err := ds.withWriteTx(ctx, func(tx *gorm.DB) error {
+			var v int64
+			return tx.Raw("SELECT 1").Row().Scan(&v)
+		})
+		require.ErrorContains(t, err, "connection reset by peer")
+	})
+}
+
+func queryOne(db *sql.DB) error {
+	var v int64
+	return db.QueryRow("SELECT 1").Scan(&v)
+}
+
+// resetConnector is a driver.Connector whose connections fail queries with a
+// connection reset, as when the database restarts, for the given number of
+// failures. A connection that failed keeps failing, like a real one would.
+type resetConnector struct {
+	mu       sync.Mutex
+	failures int
+	connects int
+}
+
+func (c *resetConnector) Connect(context.Context) (driver.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.connects++
+	return &resetConn{connector: c}, nil
+}
+
+func (c *resetConnector) Driver() driver.Driver {
+	return resetDriver{connector: c}
+}
+
+func (c *resetConnector) connectCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.connects
+}
+
+func (c *resetConnector) failuresLeft() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.failures
+}
+
+func (c *resetConnector) takeFailure() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failures == 0 {
+		return false
+	}
+	c.failures--
+	return true
+}
+
+type resetDriver struct {
+	connector *resetConnector
+}
+
+func (d resetDriver) Open(string) (driver.Conn, error) {
+	return d.connector.Connect(context.Background())
+}
+
+type resetConn struct {
+	connector *resetConnector
+	broken    bool
+}
+
+func (c *resetConn) query() (driver.Rows, error) {
+	if c.broken || c.connector.takeFailure() {
+		c.broken = true
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	}
+	return &oneRows{}, nil
+}
+
+func (c *resetConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	return c.query()
+}
+
+func (c *resetConn) Prepare(string) (driver.Stmt, error) {
+	return resetStmt{conn: c}, nil
+}
+
+func (c *resetConn) Close() error {
+	return nil
+}
+
+func (c *resetConn) Begin() (driver.Tx, error) {
+	return resetTx{}, nil
+}
+
+type resetStmt struct {
+	conn *resetConn
+}
+
+func (s resetStmt) Close() error {
+	return nil
+}
+
+func (s resetStmt) NumInput() int {
+	return 0
+}
+
+func (s resetStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s resetStmt) Query([]driver.Value) (driver.Rows, error) {
+	return s.conn.query()
+}
+
+type resetTx struct{}
+
+func (resetTx) Commit() error {
+	return nil
+}
+
+func (resetTx) Rollback() error {
+	return nil
+}
+
+// oneRows returns a single row with a single column set to 1.
+type oneRows struct {
+	done bool
+}
+
+func (r *oneRows) Columns() []string {
+	return []string{"v"}
+}
+
+func (r *oneRows) Close() error {
+	return nil
+}
+
+func (r *oneRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+	return nil
+}
@@ -1,8 +1,32 @@
 package sqlstore
 
-import "github.com/jinzhu/gorm"
+import (
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
 
 type dialect interface {
 	connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, version string, supportsCTE bool, err error)
 	isConstraintViolation(err error) bool
+	// isTransientError returns true if the error reports a failure that is
+	// expected to go away if the transaction is retried (e.g. serialization
+	// failures or deadlocks).
+	isTransientError(err error) bool
+	// isConnectionError returns true if the error reports that the
+	// connection to the database was lost or could not be established (e.g.
+	// the database restarted), so the operation may succeed on a new
+	// connection.
+	isConnectionError(err error) bool
+	// newMigrationLock returns the lock held while the database is
+	// initialized or migrated.
+	newMigrationLock(db *gorm.DB, cfg *configuration) migrationLock
+	// setStatementTimeout has the database abort the statements of the
+	// transaction that run for longer than the timeout. A zero timeout
+	// disables it.
+	setStatementTimeout(tx *gorm.DB, timeout time.Duration) error
+	// isStatementTimeout returns true if the error reports a statement
+	// aborted by the database because it ran for longer than the timeout
+	// set with setStatementTimeout.
+	isStatementTimeout(err error) bool
 }
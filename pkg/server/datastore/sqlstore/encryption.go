@@ -0,0 +1,182 @@
+package sqlstore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/jinzhu/gorm"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// aeadProviderKey is the key the AEAD provider is set with on transactions.
+const aeadProviderKey = "sqlstore:aead_provider"
+
+// caJournalAdditionalData is authenticated along with the CA journal data.
+var caJournalAdditionalData = []byte("ca_journals")
+
+// AEADProvider provides the keys the bundle and CA journal data is encrypted
+// with at rest, e.g. backed by a KMS.
+type AEADProvider interface {
+	// CurrentKey returns the key new data is encrypted with, along with the
+	// reference to it that is stored next to the encrypted data.
+	CurrentKey() (keyRef string, aead cipher.AEAD, err error)
+
+	// Key returns the key data stored with the given reference was
+	// encrypted with.
+	Key(keyRef string) (cipher.AEAD, error)
+}
+
+// keyFileAEADProvider encrypts with a single AES-256-GCM key read from a file.
+type keyFileAEADProvider struct {
+	keyRef string
+	aead   cipher.AEAD
+}
+
+// newKeyFileAEADProvider loads the hex encoded 32 byte key in the given
+// file. The key is referenced by a prefix of its SHA-256 hash, so that data
+// encrypted with another key can be told apart.
+func newKeyFileAEADProvider(path string) (*keyFileAEADProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+	}
+	key, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode encryption key file: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes long; got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(key)
+	return &keyFileAEADProvider{
+		keyRef: "sha256:" + hex.EncodeToString(sum[:8]),
+		aead:   aead,
+	}, nil
+}
+
+func (p *keyFileAEADProvider) CurrentKey() (string, cipher.AEAD, error) {
+	return p.keyRef, p.aead, nil
+}
+
+func (p *keyFileAEADProvider) Key(keyRef string) (cipher.AEAD, error) {
+	if keyRef != p.keyRef {
+		return nil, fmt.Errorf("unknown key %q", keyRef)
+	}
+	return p.aead, nil
+}
+
+// withAEADProvider sets the AEAD provider used by sealData and openData on
+// the transaction.
+func withAEADProvider(tx *gorm.DB, provider AEADProvider) *gorm.DB {
+	if provider == nil {
+		return tx
+	}
+	return tx.Set(aeadProviderKey, provider)
+}
+
+// sealData encrypts the data with the current key of the AEAD provider of the
+// transaction, returning the reference to the key and the nonce to store
+// along with it. The data is returned as is, with no key reference, if there
+// is no AEAD provider.
+func sealData(tx *gorm.DB, data, additionalData []byte) (sealed []byte, keyRef string, nonce []byte, err error) {
+	value, ok := tx.Get(aeadProviderKey)
+	if !ok {
+		return data, "", nil, nil
+	}
+	keyRef, aead, err := value.(AEADProvider).CurrentKey()
+	if err != nil {
+		return nil, "", nil, status.Errorf(codes.Internal, "%s: failed to get encryption key: %v", datastoreSQLErrorPrefix, err)
+	}
+
+	nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", nil, status.Errorf(codes.Internal, "%s: failed to generate nonce: %v", datastoreSQLErrorPrefix, err)
+	}
+	return aead.Seal(nil, nonce, data, additionalData), keyRef, nonce, nil
+}
+
+// openData decrypts data sealed by sealData. Data without a key reference is
+// in plaintext, since it was written before encryption was enabled, and is
+// returned as is.
+func openData(tx *gorm.DB, data []byte, keyRef string, nonce, additionalData []byte) ([]byte, error) {
+	if keyRef == "" {
+		return data, nil
+	}
+	value, ok := tx.Get(aeadProviderKey)
+	if !ok {
+		return nil, status.Errorf(codes.Internal, "%s: data is encrypted with key %q but no encryption is configured", datastoreSQLErrorPrefix, keyRef)
+	}
+	aead, err := value.(AEADProvider).Key(keyRef)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: failed to get encryption key %q: %v", datastoreSQLErrorPrefix, keyRef, err)
+	}
+
+	plaintext, err := aead.Open(nil, nonce, data, additionalData)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "%s: failed to decrypt data with key %q: %v", datastoreSQLErrorPrefix, keyRef, err)
+	}
+	return plaintext, nil
+}
+
+// sealBundle returns a copy of the bundle model with its data sealed, to be
+// written in place of it.
+func sealBundle(tx *gorm.DB, model *Bundle) (*Bundle, error) {
+	sealed := *model
+	var err error
+	sealed.Data, sealed.EncryptionKeyRef, sealed.EncryptionNonce, err = sealData(tx, model.Data, []byte(model.TrustDomain))
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// openBundle decrypts the data of the bundle model read from the database in
+// place.
+func openBundle(tx *gorm.DB, model *Bundle) error {
+	data, err := openData(tx, model.Data, model.EncryptionKeyRef, model.EncryptionNonce, []byte(model.TrustDomain))
+	if err != nil {
+		return err
+	}
+	model.Data = data
+	return nil
+}
+
+// sealCAJournal returns a copy of the CA journal model with its data sealed,
+// to be written in place of it.
+func sealCAJournal(tx *gorm.DB, model *CAJournal) (*CAJournal, error) {
+	sealed := *model
+	var err error
+	sealed.Data, sealed.EncryptionKeyRef, sealed.EncryptionNonce, err = sealData(tx, model.Data, caJournalAdditionalData)
+	if err != nil {
+		return nil, err
+	}
+	return &sealed, nil
+}
+
+// openCAJournal decrypts the data of the CA journal model read from the
+// database in place.
+func openCAJournal(tx *gorm.DB, model *CAJournal) error {
+	data, err := openData(tx, model.Data, model.EncryptionKeyRef, model.EncryptionNonce, caJournalAdditionalData)
+	if err != nil {
+		return err
+	}
+	model.Data = data
+	return nil
+}
@@ -0,0 +1,116 @@
+package sqlstore
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyFileAEADProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeKey := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0600))
+		return path
+	}
+
+	key := bytes.Repeat([]byte{0x42}, 32)
+	provider, err := newKeyFileAEADProvider(writeKey("key", hex.EncodeToString(key)+"\n"))
+	require.NoError(t, err)
+
+	keyRef, aead, err := provider.CurrentKey()
+	require.NoError(t, err)
+	sum := sha256.Sum256(key)
+	require.Equal(t, "sha256:"+hex.EncodeToString(sum[:8]), keyRef)
+
+	nonce := make([]byte, aead.NonceSize())
+	sealed := aead.Seal(nil, nonce, []byte("plaintext"), []byte("ad"))
+	require.NotContains(t, string(sealed), "plaintext")
+
+	opener, err := provider.Key(keyRef)
+	require.NoError(t, err)
+	plaintext, err := opener.Open(nil, nonce, sealed, []byte("ad"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("plaintext"), plaintext)
+
+	_, err = opener.Open(nil, nonce, sealed, []byte("other"))
+	require.Error(t, err)
+
+	_, err = provider.Key("sha256:0000000000000000")
+	require.EqualError(t, err, `unknown key "sha256:0000000000000000"`)
+
+	_, err = newKeyFileAEADProvider(filepath.Join(dir, "missing"))
+	require.ErrorContains(t, err, "failed to read encryption key file")
+
+	_, err = newKeyFileAEADProvider(writeKey("not-hex", "not hex"))
+	require.ErrorContains(t, err, "failed to decode encryption key file")
+
+	_, err = newKeyFileAEADProvider(writeKey("short", hex.EncodeToString(key[:16])))
+	require.EqualError(t, err, "encryption key must be 32 bytes long; got 16")
+}
+
+// fakeAEADProvider encrypts with fake keys, one per key reference.
+type fakeAEADProvider struct {
+	currentKeyRef string
+}
+
+func newFakeAEADProvider(currentKeyRef string) *fakeAEADProvider {
+	return &fakeAEADProvider{currentKeyRef: currentKeyRef}
+}
+
+func (p *fakeAEADProvider) CurrentKey() (string, cipher.AEAD, error) {
+	return p.currentKeyRef, fakeAEAD{keyRef: p.currentKeyRef}, nil
+}
+
+func (p *fakeAEADProvider) Key(keyRef string) (cipher.AEAD, error) {
+	return fakeAEAD{keyRef: keyRef}, nil
+}
+
+// fakeAEAD XORs the plaintext with a byte, and appends a tag covering the
+// key reference, the nonce, the additional data and the plaintext.
+type fakeAEAD struct {
+	keyRef string
+}
+
+func (fakeAEAD) NonceSize() int {
+	return 4
+}
+
+func (fakeAEAD) Overhead() int {
+	return sha256.Size
+}
+
+func (a fakeAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	for _, b := range plaintext {
+		dst = append(dst, b^0x5a)
+	}
+	return append(dst, a.tag(nonce, plaintext, additionalData)...)
+}
+
+func (a fakeAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < sha256.Size {
+		return nil, errors.New("ciphertext too short")
+	}
+	sealed, tag := ciphertext[:len(ciphertext)-sha256.Size], ciphertext[len(ciphertext)-sha256.Size:]
+	plaintext := make([]byte, 0, len(sealed))
+	for _, b := range sealed {
+		plaintext = append(plaintext, b^0x5a)
+	}
+	if !bytes.Equal(tag, a.tag(nonce, plaintext, additionalData)) {
+		return nil, errors.New("message authentication failed")
+	}
+	return append(dst, plaintext...), nil
+}
+
+func (a fakeAEAD) tag(nonce, plaintext, additionalData []byte) []byte {
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s|%x|%x|%x", a.keyRef, nonce, additionalData, plaintext))
+	return sum[:]
+}
@@ -4,12 +4,15 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/blang/semver/v4"
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/common/version"
+	"github.com/spiffe/spire/proto/spire/common"
+	"google.golang.org/protobuf/proto"
 )
 
 // Each time the database requires a migration, the "schema" version is
@@ -251,22 +254,72 @@ import (
 // | v1.11.1 |        |                                                                           |
 // |---------|        |                                                                           |
 // | v1.11.2 |        |                                                                           |
+// |*********|********|***************************************************************************|
+// | v1.12.0 | 24     | Added last_refreshed_at column to bundles                                 |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 25     | Added created_by column to entries                                        |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 26     | Added trust_domain column to entries                                      |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 27     | Added index on data_type column of attested nodes                         |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 28     | Added attested_node_serial_history table                                  |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 29     | Added refresh_hint column to federated_trust_domains                      |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 30     | Added idempotency_key column to entries                                   |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 31     | Added bundles_events table                                                |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 32     | Added active column to entries                                            |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 33     | Added last_poll_at and last_poll_error columns to federated_trust_domains |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 34     | Added source column to node_resolver_map_entries                          |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 35     | Added content_hash column to bundles                                      |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 36     | Added expires_at column to node_resolver_map_entries                      |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 37     | Added remaining_uses column to join_tokens                                |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 38     | Lowercased the trust domains of bundles and federated trust domains       |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 39     | Added attested_at and last_attested_at columns to attested_node_entries   |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 40     | Added encryption_key_ref and encryption_nonce columns to bundles and      |
+// |         |        | ca_journals                                                               |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 41     | Added index on updated_at column of attested_node_entries                 |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 42     | Added next_poll_at column to federated_trust_domains                      |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 43     | Added federates_with_all column to registered_entries                     |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 44     | Replaced the index on type and value columns of selectors with one also   |
+// |         |        | covering the registered_entry_id column                                   |
+// |         |--------|---------------------------------------------------------------------------|
+// |         | 45     | Added attested_node_aliases table                                         |
 // ================================================================================================
 
 const (
 	// the latest schema version of the database in the code
-	latestSchemaVersion = 23
+	latestSchemaVersion = 45
 
 	// lastMinorReleaseSchemaVersion is the schema version supported by the
 	// last minor release. When the migrations are opportunistically pruned
 	// from the code after a minor release, this number should be updated.
 	lastMinorReleaseSchemaVersion = 23
+
+	// maximum number of SPIFFE IDs matched by each update populating the
+	// trust domain of the existing entries in migrateToV26
+	migrateToV26BatchSize = 500
 )
 
 // the current code version
 var codeVersion = semver.MustParse(version.Version())
 
-func migrateDB(db *gorm.DB, dbType string, disableMigration bool, log logrus.FieldLogger) (err error) {
+func migrateDB(db *gorm.DB, dbType string, disableMigration, allowSchemaVersionMismatch bool, log logrus.FieldLogger) (err error) {
 	// The version comparison logic in this package supports only 0.x and 1.x versioning semantics.
 	// It will need to be updated prior to releasing 2.x. Ensure that we're still building a pre-2.0
 	// version before continuing, and fail if we're not.
@@ -306,6 +359,17 @@ func migrateDB(db *gorm.DB, dbType string, disableMigration bool, log logrus.Fie
 
 	log = log.WithField(telemetry.VersionInfo, dbCodeVersion.String())
 
+	if err := checkSchemaVersion(schemaVersion, codeVersion, dbCodeVersion); err != nil {
+		// Only a schema that is too new can be run against. A schema that is
+		// too old would have to be migrated first, which this code can't do.
+		if !allowSchemaVersionMismatch || schemaVersion < latestSchemaVersion {
+			log.WithError(err).Error("Incompatible DB schema version")
+			return newWrappedSQLError(err)
+		}
+		log.WithError(err).Warn("Running against a DB schema version newer than supported since allow_schema_version_mismatch is set; data may be corrupted")
+		return nil
+	}
+
 	if schemaVersion == latestSchemaVersion {
 		log.Debug("Code and DB schema versions are the same. No migration needed")
 
@@ -332,13 +396,9 @@ func migrateDB(db *gorm.DB, dbType string, disableMigration bool, log logrus.Fie
 	}
 
 	// The DB schema version can get ahead of us if the cluster is in the middle of
-	// an upgrade. So long as the version is compatible, log a warning and continue.
-	// Otherwise, we should bail out. Migration rollbacks are not supported.
+	// an upgrade. The version was checked to be compatible, so log a warning and
+	// continue. Migration rollbacks are not supported.
 	if schemaVersion > latestSchemaVersion {
-		if !isCompatibleCodeVersion(codeVersion, dbCodeVersion) {
-			log.Error("Incompatible DB schema is too new for code version, upgrade SPIRE Server")
-			return newSQLError("incompatible DB schema and code version")
-		}
 		log.Warn("DB schema is ahead of code version, upgrading SPIRE Server is recommended")
 		return nil
 	}
@@ -367,6 +427,21 @@ func migrateDB(db *gorm.DB, dbType string, disableMigration bool, log logrus.Fie
 	return nil
 }
 
+// checkSchemaVersion returns an error if the code cannot run against a DB
+// with the given schema version. A schema newer than the latest one is only
+// supported if it was migrated by a SPIRE Server within the supported version
+// skew, and a schema older than the one of the last minor release can't be
+// migrated from.
+func checkSchemaVersion(schemaVersion int, thisCodeVersion, dbCodeVersion semver.Version) error {
+	switch {
+	case schemaVersion > latestSchemaVersion && !isCompatibleCodeVersion(thisCodeVersion, dbCodeVersion):
+		return fmt.Errorf("DB schema version %d, migrated by SPIRE Server %s, is newer than the latest schema version %d supported by SPIRE Server %s; upgrade SPIRE Server", schemaVersion, dbCodeVersion, latestSchemaVersion, thisCodeVersion)
+	case schemaVersion < lastMinorReleaseSchemaVersion:
+		return fmt.Errorf("migrating from schema version %d requires a previous SPIRE release; please follow the upgrade strategy at doc/upgrading.md", schemaVersion)
+	}
+	return nil
+}
+
 func isDisabledMigrationAllowed(thisCodeVersion, dbCodeVersion semver.Version) error {
 	// If auto-migrate is disabled, and we are running a compatible version (+/- 1
 	// minor from the stored code version) then we are done here
@@ -404,8 +479,11 @@ func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
 
 	tables := []any{
 		&Bundle{},
+		&BundleEvent{},
 		&AttestedNode{},
 		&AttestedNodeEvent{},
+		&AttestedNodeSerialHistory{},
+		&AttestedNodeAlias{},
 		&NodeSelector{},
 		&RegisteredEntry{},
 		&RegisteredEntryEvent{},
@@ -434,6 +512,14 @@ func initDB(db *gorm.DB, dbType string, log logrus.FieldLogger) (err error) {
 		return err
 	}
 
+	if err := addAttestedNodeEntriesUpdatedAtIndex(tx); err != nil {
+		return err
+	}
+
+	if err := addSelectorsTypeValueEntryIndex(tx); err != nil {
+		return err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return newWrappedSQLError(err)
 	}
@@ -462,10 +548,6 @@ func migrateVersion(tx *gorm.DB, currVersion int, log logrus.FieldLogger) (versi
 		return 0, newWrappedSQLError(err)
 	}
 
-	if currVersion < lastMinorReleaseSchemaVersion {
-		return 0, newSQLError("migrating from schema version %d requires a previous SPIRE release; please follow the upgrade strategy at doc/upgrading.md", currVersion)
-	}
-
 	// Place all migrations handled by the current minor release here. This
 	// list can be opportunistically pruned after every minor release but won't
 	// break things if it isn't.
@@ -485,7 +567,51 @@ func migrateVersion(tx *gorm.DB, currVersion int, log logrus.FieldLogger) (versi
 	//   return nil
 	// }
 	//
-	switch currVersion { //nolint: gocritic // No upgrade required yet, keeping switch for future additions
+	switch currVersion {
+	case 23:
+		err = migrateToV24(tx)
+	case 24:
+		err = migrateToV25(tx)
+	case 25:
+		err = migrateToV26(tx)
+	case 26:
+		err = migrateToV27(tx)
+	case 27:
+		err = migrateToV28(tx)
+	case 28:
+		err = migrateToV29(tx)
+	case 29:
+		err = migrateToV30(tx)
+	case 30:
+		err = migrateToV31(tx)
+	case 31:
+		err = migrateToV32(tx)
+	case 32:
+		err = migrateToV33(tx)
+	case 33:
+		err = migrateToV34(tx)
+	case 34:
+		err = migrateToV35(tx)
+	case 35:
+		err = migrateToV36(tx)
+	case 36:
+		err = migrateToV37(tx)
+	case 37:
+		err = migrateToV38(tx)
+	case 38:
+		err = migrateToV39(tx)
+	case 39:
+		err = migrateToV40(tx)
+	case 40:
+		err = migrateToV41(tx)
+	case 41:
+		err = migrateToV42(tx)
+	case 42:
+		err = migrateToV43(tx)
+	case 43:
+		err = migrateToV44(tx)
+	case 44:
+		err = migrateToV45(tx)
 	default:
 		err = newSQLError("no migration support for unknown schema version %d", currVersion)
 	}
@@ -496,6 +622,317 @@ func migrateVersion(tx *gorm.DB, currVersion int, log logrus.FieldLogger) (versi
 	return nextVersion, nil
 }
 
+func migrateToV24(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Bundle{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	// Backfill the last refreshed timestamp of bundles that are refreshed
+	// through a federation relationship, using the last time they were
+	// updated as the best approximation available. Any other bundle (e.g.
+	// the server's own bundle) has never been refreshed and is left empty.
+	var federatedTrustDomains []string
+	if err := tx.Model(&FederatedTrustDomain{}).Pluck("trust_domain", &federatedTrustDomains).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	if len(federatedTrustDomains) == 0 {
+		return nil
+	}
+
+	trustDomainIDs := make([]string, 0, len(federatedTrustDomains))
+	for _, td := range federatedTrustDomains {
+		trustDomainIDs = append(trustDomainIDs, "spiffe://"+td)
+	}
+	if err := tx.Model(&Bundle{}).
+		Where("trust_domain IN (?)", trustDomainIDs).
+		UpdateColumn("last_refreshed_at", gorm.Expr("updated_at")).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV25(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&RegisteredEntry{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV26(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&RegisteredEntry{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	// Populate the trust domain of existing entries from their SPIFFE IDs.
+	// The entries of a trust domain are updated together, in batches of
+	// SPIFFE IDs, instead of issuing an update per SPIFFE ID.
+	var spiffeIDs []string
+	if err := tx.Model(&RegisteredEntry{}).Pluck("DISTINCT spiffe_id", &spiffeIDs).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	spiffeIDsByTrustDomain := make(map[string][]string)
+	for _, spiffeID := range spiffeIDs {
+		trustDomain := trustDomainFromSPIFFEID(spiffeID)
+		spiffeIDsByTrustDomain[trustDomain] = append(spiffeIDsByTrustDomain[trustDomain], spiffeID)
+	}
+
+	for trustDomain, spiffeIDs := range spiffeIDsByTrustDomain {
+		for len(spiffeIDs) > 0 {
+			batch := spiffeIDs[:min(len(spiffeIDs), migrateToV26BatchSize)]
+			spiffeIDs = spiffeIDs[len(batch):]
+			if err := tx.Model(&RegisteredEntry{}).
+				Where("spiffe_id IN (?)", batch).
+				UpdateColumn("trust_domain", trustDomain).Error; err != nil {
+				return newWrappedSQLError(err)
+			}
+		}
+	}
+	return nil
+}
+
+func migrateToV27(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AttestedNode{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV28(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AttestedNodeSerialHistory{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV29(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&FederatedTrustDomain{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV30(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&RegisteredEntry{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV31(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&BundleEvent{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV32(tx *gorm.DB) error {
+	// Existing entries are active, since the column defaults to true
+	if err := tx.AutoMigrate(&RegisteredEntry{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV33(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&FederatedTrustDomain{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV34(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&NodeSelector{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV35(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&Bundle{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	// Populate the content hash of existing bundles from their data
+	var bundles []Bundle
+	if err := tx.Select("id, data").Find(&bundles).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	for _, bundle := range bundles {
+		if err := tx.Model(&Bundle{}).
+			Where("id = ?", bundle.ID).
+			UpdateColumn("content_hash", bundleContentHash(bundle.Data)).Error; err != nil {
+			return newWrappedSQLError(err)
+		}
+	}
+	return nil
+}
+
+func migrateToV36(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&NodeSelector{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV37(tx *gorm.DB) error {
+	// Existing tokens get the column default, a single use
+	if err := tx.AutoMigrate(&JoinToken{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV38(tx *gorm.DB) error {
+	// Trust domains are now lowercased on write. Rows that only differ in
+	// case would collide on the unique index once lowercased, and there is
+	// no way to tell which one to keep, so the operator must resolve them.
+	for _, table := range []string{"bundles", "federated_trust_domains"} {
+		if err := checkTrustDomainCaseCollisions(tx, table); err != nil {
+			return err
+		}
+	}
+
+	var bundles []Bundle
+	if err := tx.Select("id, trust_domain, data").Find(&bundles).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	for _, model := range bundles {
+		trustDomain := normalizeTrustDomainID(model.TrustDomain)
+		if trustDomain == model.TrustDomain {
+			continue
+		}
+
+		// The trust domain is also part of the bundle data
+		bundle := new(common.Bundle)
+		if err := proto.Unmarshal(model.Data, bundle); err != nil {
+			return newWrappedSQLError(err)
+		}
+		bundle.TrustDomainId = normalizeTrustDomainID(bundle.TrustDomainId)
+		data, err := proto.Marshal(bundle)
+		if err != nil {
+			return newWrappedSQLError(err)
+		}
+
+		if err := tx.Model(&Bundle{}).
+			Where("id = ?", model.ID).
+			UpdateColumns(map[string]any{
+				"trust_domain": trustDomain,
+				"data":         data,
+				"content_hash": bundleContentHash(data),
+			}).Error; err != nil {
+			return newWrappedSQLError(err)
+		}
+	}
+
+	var federatedTrustDomains []FederatedTrustDomain
+	if err := tx.Select("id, trust_domain").Find(&federatedTrustDomains).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	for _, model := range federatedTrustDomains {
+		trustDomain := normalizeTrustDomainID(model.TrustDomain)
+		if trustDomain == model.TrustDomain {
+			continue
+		}
+		if err := tx.Model(&FederatedTrustDomain{}).
+			Where("id = ?", model.ID).
+			UpdateColumn("trust_domain", trustDomain).Error; err != nil {
+			return newWrappedSQLError(err)
+		}
+	}
+	return nil
+}
+
+func migrateToV39(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AttestedNode{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	// The first attestation of existing nodes was not recorded, so use the
+	// time they were created, which is the closest thing
+	if err := tx.Model(&AttestedNode{}).UpdateColumns(map[string]any{
+		"attested_at":      gorm.Expr("created_at"),
+		"last_attested_at": gorm.Expr("created_at"),
+	}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV40(tx *gorm.DB) error {
+	// Existing rows are left in plaintext, which is what rows without a key
+	// reference are read as
+	if err := tx.AutoMigrate(&Bundle{}, &CAJournal{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV41(tx *gorm.DB) error {
+	return addAttestedNodeEntriesUpdatedAtIndex(tx)
+}
+
+func migrateToV42(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&FederatedTrustDomain{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV43(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&RegisteredEntry{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV44(tx *gorm.DB) error {
+	if err := addSelectorsTypeValueEntryIndex(tx); err != nil {
+		return err
+	}
+	// The new index has the same leading columns, so it serves the lookups
+	// the replaced one did
+	if err := tx.Table("selectors").RemoveIndex("idx_selectors_type_value").Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func migrateToV45(tx *gorm.DB) error {
+	if err := tx.AutoMigrate(&AttestedNodeAlias{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+// checkTrustDomainCaseCollisions fails if the given table has trust domains
+// that only differ in case.
+func checkTrustDomainCaseCollisions(tx *gorm.DB, table string) error {
+	rows, err := tx.Raw(fmt.Sprintf("SELECT LOWER(trust_domain) FROM %s GROUP BY LOWER(trust_domain) HAVING COUNT(*) > 1 ORDER BY 1", table)).Rows()
+	if err != nil {
+		return newWrappedSQLError(err)
+	}
+	defer rows.Close()
+
+	var collisions []string
+	for rows.Next() {
+		var trustDomain string
+		if err := rows.Scan(&trustDomain); err != nil {
+			return newWrappedSQLError(err)
+		}
+		collisions = append(collisions, trustDomain)
+	}
+	if err := rows.Err(); err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	if len(collisions) > 0 {
+		return newSQLError("cannot lowercase the trust domains in %s: rows only differing in case exist for %s; delete the duplicates before upgrading", table, strings.Join(collisions, ", "))
+	}
+	return nil
+}
+
 func addFederatedRegistrationEntriesRegisteredEntryIDIndex(tx *gorm.DB) error {
 	// GORM creates the federated_registration_entries implicitly with a primary
 	// key tuple (bundle_id, registered_entry_id). Unfortunately, MySQL5 does
@@ -508,3 +945,23 @@ func addFederatedRegistrationEntriesRegisteredEntryIDIndex(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+func addAttestedNodeEntriesUpdatedAtIndex(tx *gorm.DB) error {
+	// The updated_at column comes from the embedded Model struct, shared by
+	// all the tables, so the index cannot be introduced with a tag on it.
+	if err := tx.Table("attested_node_entries").AddIndex("idx_attested_node_entries_updated_at", "updated_at").Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func addSelectorsTypeValueEntryIndex(tx *gorm.DB) error {
+	// GORM orders the columns of an index declared with tags by the order of
+	// the fields in the struct, which puts registered_entry_id first. The
+	// entry ID must come last for the index to cover the lookups of the
+	// entries matching a selector, so the index is created manually.
+	if err := tx.Table("selectors").AddIndex("idx_selectors_type_value_entry", "type", "value", "registered_entry_id").Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Default time a server waits for another server to release the
+	// migration lock before failing
+	defaultMigrationLockTimeout = 5 * time.Minute
+
+	// Average delay between attempts to acquire the migration lock. The
+	// actual delay is jittered so that servers waiting on the lock don't
+	// poll the database in lockstep.
+	migrationLockPollInterval = time.Second
+)
+
+// migrationLock is held while a server initializes or migrates the database,
+// so that servers started at the same time against the same database don't
+// race to do so.
+type migrationLock interface {
+	// tryLock acquires the lock if it is not held by someone else and
+	// reports whether it did so. It does not wait for the lock to be
+	// released.
+	tryLock(ctx context.Context) (bool, error)
+
+	// unlock releases a lock previously acquired by tryLock.
+	unlock() error
+}
+
+// withMigrationLock runs fn while holding the migration lock. If the lock is
+// held by another server, it is polled until it is acquired or the timeout
+// expires.
+func withMigrationLock(lock migrationLock, timeout time.Duration, log logrus.FieldLogger, fn func() error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	for waiting := false; ; waiting = true {
+		acquired, err := lock.tryLock(ctx)
+		if err != nil {
+			return newSQLError("failed to acquire migration lock: %v", err)
+		}
+		if acquired {
+			break
+		}
+
+		if !waiting {
+			log.Info("Waiting for another server to finish migrating the database")
+		}
+		select {
+		case <-time.After(jitterMigrationLockPollInterval()):
+		case <-ctx.Done():
+			return newSQLError("timed out after %s waiting for another server to finish migrating the database", timeout)
+		}
+	}
+
+	defer func() {
+		if err := lock.unlock(); err != nil {
+			log.WithError(err).Warn("Failed to release migration lock")
+		}
+	}()
+	return fn()
+}
+
+// jitterMigrationLockPollInterval returns a delay within ± 50% of the
+// migration lock poll interval.
+func jitterMigrationLockPollInterval() time.Duration {
+	delta := migrationLockPollInterval / 2
+	return time.Duration(rand.Int63n(int64(delta)*2) + int64(migrationLockPollInterval-delta)) //nolint // gosec: no need for cryptographic randomness here
+}
+
+// advisoryLock is a migration lock backed by a session-level advisory lock
+// of the database server. Since the lock belongs to the session, a dedicated
+// connection is kept for as long as the lock is held.
+type advisoryLock struct {
+	db *sql.DB
+
+	// tryLockQuery returns 1 if the lock was acquired, and 0 or NULL
+	// otherwise.
+	tryLockQuery string
+	unlockQuery  string
+	args         []any
+
+	conn *sql.Conn
+}
+
+func (l *advisoryLock) tryLock(ctx context.Context) (bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, l.tryLockQuery, l.args...).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return false, conn.Close()
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+func (l *advisoryLock) unlock() error {
+	defer l.conn.Close()
+	_, err := l.conn.ExecContext(context.Background(), l.unlockQuery, l.args...)
+	return err
+}
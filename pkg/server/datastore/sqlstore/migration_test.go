@@ -2,6 +2,7 @@ package sqlstore
 
 import (
 	"database/sql"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -57,6 +58,1250 @@ var (
 			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
 			COMMIT;
 			`,
+		24: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 06:54:02.74039119+00:00','2026-10-15 06:54:02.74039119+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712e9020ae6023082016230820108a00302010202080d31ff74f7d8fa59300a06082a8648ce3d040302301d311b301906035504031312434120643331666637346637643866613539301e170d3236313031353036353430325a170d3236313031353037353430325a301d311b3019060355040313124341206433316666373466376438666135393059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d040302034800304502201c73795ccfc62e9f5fd5fc1d2fa1c654ab6a7279a4966b30eefe2bb40aec730f022100e29879b16b0e13177059606d3af8dcff744c46439a40fde48bda73d9680c2735',NULL);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 06:54:02.741751575+00:00','2026-10-15 06:54:02.741751575+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 06:54:02.74178533+00:00','2026-10-15 06:54:02.74178533+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 06:54:02.741338812+00:00','2026-10-15 06:54:02.741338812+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent',0,0,0,0,0,0,'',0);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 06:54:02.741672194+00:00','2026-10-15 06:54:02.741672194+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 06:54:02.741457848+00:00','2026-10-15 06:54:02.741457848+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 06:54:02.73130413+00:00','2026-10-15 06:54:02.73130413+00:00',24,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		25: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 07:10:37.006261616+00:00','2026-10-15 07:10:37.006261616+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010aa00302010202086996ac66e858b593300a06082a8648ce3d040302301e311c301a0603550403131343412036393936616336366538353862353933301e170d3236313031353037313033375a170d3236313031353038313033375a301e311c301a06035504031313434120363939366163363665383538623539333059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020349003046022100d5bcc5bb2448726a08477cd1cfa69611945e04dbe0ba7004c9344ef351fee28102210083f6b06b03d784b6826a65e60a50bd272c673fa4b83ca4a23c7d8966ce8f8485',NULL);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 07:10:37.008685238+00:00','2026-10-15 07:10:37.008685238+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 07:10:37.008761442+00:00','2026-10-15 07:10:37.008761442+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255) );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 07:10:37.007720284+00:00','2026-10-15 07:10:37.007720284+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent',0,0,0,0,0,0,'',0,'');
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 07:10:37.008485754+00:00','2026-10-15 07:10:37.008485754+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 07:10:37.007963947+00:00','2026-10-15 07:10:37.007963947+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 07:10:37.00232467+00:00','2026-10-15 07:10:37.00232467+00:00',25,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		26: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 07:21:46.432747559+00:00','2026-10-15 07:21:46.432747559+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010ba003020102020900b10e5e373730fa38300a06082a8648ce3d040302301e311c301a0603550403131343412062313065356533373337333066613338301e170d3236313031353037323134365a170d3236313031353038323134365a301e311c301a06035504031313434120623130653565333733373330666133383059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d040302034800304502210098bcc12b96f804da46d98dd5e4132898c3001d62bb7c5fc5af61cdfdfc3bbcd702200ad85d97fb124d588b6b42656931153f637d4b298b34d510ac12927e7352e5a7',NULL);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 07:21:46.434146336+00:00','2026-10-15 07:21:46.434146336+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 07:21:46.434186966+00:00','2026-10-15 07:21:46.434186966+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255) );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 07:21:46.433677377+00:00','2026-10-15 07:21:46.433677377+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'');
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 07:21:46.434056949+00:00','2026-10-15 07:21:46.434056949+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 07:21:46.433803733+00:00','2026-10-15 07:21:46.433803733+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 07:21:46.430317175+00:00','2026-10-15 07:21:46.430317175+00:00',26,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		27: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 07:23:41.949556901+00:00','2026-10-15 07:23:41.949556901+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010aa00302010202085d75892fd36b2324300a06082a8648ce3d040302301e311c301a0603550403131343412035643735383932666433366232333234301e170d3236313031353037323334315a170d3236313031353038323334315a301e311c301a06035504031313434120356437353839326664333662323332343059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d04030203490030460221009d5b440117414a59a58710be7d75387e396d8267e65884ec8951581d8ad6fdae0221009c144a0485c25119526af24dd92848d7b0671fe0573285d9e946e7325750de25',NULL);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 07:23:41.950683612+00:00','2026-10-15 07:23:41.950683612+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 07:23:41.950718729+00:00','2026-10-15 07:23:41.950718729+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255) );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 07:23:41.950282984+00:00','2026-10-15 07:23:41.950282984+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'');
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 07:23:41.950613909+00:00','2026-10-15 07:23:41.950613909+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 07:23:41.950391204+00:00','2026-10-15 07:23:41.950391204+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 07:23:41.947544165+00:00','2026-10-15 07:23:41.947544165+00:00',27,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		28: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 07:27:07.155339979+00:00','2026-10-15 07:27:07.155339979+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712e6020ae3023082015f30820106a003020102020800a6bb333a50a9e0300a06082a8648ce3d040302301c311a3018060355040313114341206136626233333361353061396530301e170d3236313031353037323730375a170d3236313031353038323730375a301c311a30180603550403131143412061366262333333613530613965303059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020347003044022059a0eb01a6aad99c52d87e761b6a2cf2b0d16a37902c9aa68820b9c21b0f932c022012314f304e93488a60ce5ae622ce49837ecb8b023f73bf82854b36e3cc9263ac',NULL);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 07:27:07.156598212+00:00','2026-10-15 07:27:07.156598212+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 07:27:07.156632349+00:00','2026-10-15 07:27:07.156632349+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255) );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 07:27:07.156128284+00:00','2026-10-15 07:27:07.156128284+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'');
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 07:27:07.156509031+00:00','2026-10-15 07:27:07.156509031+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 07:27:07.156247947+00:00','2026-10-15 07:27:07.156247947+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 07:27:07.15327122+00:00','2026-10-15 07:27:07.15327122+00:00',28,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		29: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 08:07:53.199589596+00:00','2026-10-15 08:07:53.199589596+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ed020aea02308201663082010ba003020102020900c3a92d609723e6e6300a06082a8648ce3d040302301e311c301a0603550403131343412063336139326436303937323365366536301e170d3236313031353038303735335a170d3236313031353039303735335a301e311c301a06035504031313434120633361393264363039373233653665363059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d04030203490030460221008e71fd4703dcac0084e0a517cf19bc97cfe7083982276162cfb2c31d30d8522e02210093099d8f9cdccfa33bc630c1681acbaadd4a03ded9cab48a5a4090e39a1e8820',NULL);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 08:07:53.200805719+00:00','2026-10-15 08:07:53.200805719+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 08:07:53.200842965+00:00','2026-10-15 08:07:53.200842965+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255) );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 08:07:53.200395873+00:00','2026-10-15 08:07:53.200395873+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'');
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 08:07:53.20072462+00:00','2026-10-15 08:07:53.20072462+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 08:07:53.200511307+00:00','2026-10-15 08:07:53.200511307+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 08:07:53.197631791+00:00','2026-10-15 08:07:53.197631791+00:00',29,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		30: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 08:15:02.355823056+00:00','2026-10-15 08:15:02.355823056+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712eb020ae802308201643082010aa00302010202083cb82e86e4692f32300a06082a8648ce3d040302301e311c301a0603550403131343412033636238326538366534363932663332301e170d3236313031353038313530325a170d3236313031353039313530325a301e311c301a06035504031313434120336362383265383665343639326633323059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020348003045022100c978d05eee7ed03b0081d88606ecfedd3008ec7f1d645edc1c1c44c83f1c596502200aa3c1a680fe590cd76bf7d8e5c63181a4b80d8dff37970c090b91a2349f6db5',NULL);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 08:15:02.357351444+00:00','2026-10-15 08:15:02.357351444+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 08:15:02.357384104+00:00','2026-10-15 08:15:02.357384104+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255) );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 08:15:02.356930258+00:00','2026-10-15 08:15:02.356930258+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 08:15:02.357266267+00:00','2026-10-15 08:15:02.357266267+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 08:15:02.357045287+00:00','2026-10-15 08:15:02.357045287+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 08:15:02.353824203+00:00','2026-10-15 08:15:02.353824203+00:00',30,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		31: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 08:35:39.399280425+00:00','2026-10-15 08:35:39.399280425+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ea020ae7023082016330820108a003020102020806ca9bd60d7e6f03300a06082a8648ce3d040302301d311b301906035504031312434120366361396264363064376536663033301e170d3236313031353038333533395a170d3236313031353039333533395a301d311b3019060355040313124341203663613962643630643765366630333059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d04030203490030460221008942542d33131c1e23d4159c0cc7f9f499d876d29138594728d507e71fbd8842022100fc8e8eb94e1b28f5f6343bb07545aee4f67df69f3735a8d5ba7f8decf3ac83b7',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 08:35:39.399347226+00:00','2026-10-15 08:35:39.399347226+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 08:35:39.400531419+00:00','2026-10-15 08:35:39.400531419+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 08:35:39.400566042+00:00','2026-10-15 08:35:39.400566042+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255) );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 08:35:39.400086284+00:00','2026-10-15 08:35:39.400086284+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 08:35:39.400447574+00:00','2026-10-15 08:35:39.400447574+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 08:35:39.400187587+00:00','2026-10-15 08:35:39.400187587+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 08:35:39.397245622+00:00','2026-10-15 08:35:39.397245622+00:00',31,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		32: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 08:44:36.70129766+00:00','2026-10-15 08:44:36.70129766+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010ba003020102020900a10d224933eb1f29300a06082a8648ce3d040302301e311c301a0603550403131343412061313064323234393333656231663239301e170d3236313031353038343433365a170d3236313031353039343433365a301e311c301a06035504031313434120613130643232343933336562316632393059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020348003045022100af3a3696b17af75c3341dde3d16943a7f9ff61bffea1f25946046dd52b8a36a30220691954f24c3ec2c170611103eb420069e55d6e1797c9a89167f1b7abf02678cf',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 08:44:36.701382865+00:00','2026-10-15 08:44:36.701382865+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 08:44:36.702804452+00:00','2026-10-15 08:44:36.702804452+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 08:44:36.702859764+00:00','2026-10-15 08:44:36.702859764+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 08:44:36.702262255+00:00','2026-10-15 08:44:36.702262255+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 08:44:36.7027121+00:00','2026-10-15 08:44:36.7027121+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 08:44:36.702484591+00:00','2026-10-15 08:44:36.702484591+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 08:44:36.698818491+00:00','2026-10-15 08:44:36.698818491+00:00',32,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		33: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 10:17:32.799160516+00:00','2026-10-15 10:17:32.799160516+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ed020aea02308201663082010ba003020102020900fa841956518e8380300a06082a8648ce3d040302301e311c301a0603550403131343412066613834313935363531386538333830301e170d3236313031353130313733325a170d3236313031353131313733325a301e311c301a06035504031313434120666138343139353635313865383338303059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d04030203490030460221009db7bf5744be04c7d7f18cd84003d5367c8e5057fc20cfc0c8715c994f98a5820221009016b6f4fd0770bf9424dbb246b1a380838d9db36e130add67147fe658e14893',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 10:17:32.799260334+00:00','2026-10-15 10:17:32.799260334+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 10:17:32.800876903+00:00','2026-10-15 10:17:32.800876903+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 10:17:32.800928579+00:00','2026-10-15 10:17:32.800928579+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 10:17:32.801039734+00:00','2026-10-15 10:17:32.801039734+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255) );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 10:17:32.801017869+00:00','2026-10-15 10:17:32.801017869+00:00','spiffe://example.org/agent','join_token','1234');
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 10:17:32.800182962+00:00','2026-10-15 10:17:32.800182962+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 10:17:32.800770516+00:00','2026-10-15 10:17:32.800770516+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 10:17:32.800473434+00:00','2026-10-15 10:17:32.800473434+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 10:17:32.796421102+00:00','2026-10-15 10:17:32.796421102+00:00',33,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		34: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 10:28:02.887915535+00:00','2026-10-15 10:28:02.887915535+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010aa0030201020208310b781a97ee92f8300a06082a8648ce3d040302301e311c301a0603550403131343412033313062373831613937656539326638301e170d3236313031353130323830325a170d3236313031353131323830325a301e311c301a06035504031313434120333130623738316139376565393266383059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020349003046022100b2270eb335ee3c347bdcab1610f60b9e0444ab110309c61ab0f0e9441ec942790221008e376751aaa0df376bdb13c2c27e6455acedb3f2afd7ada990a6e8fcf46bcd21',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 10:28:02.88798806+00:00','2026-10-15 10:28:02.88798806+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 10:28:02.889237111+00:00','2026-10-15 10:28:02.889237111+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 10:28:02.889279756+00:00','2026-10-15 10:28:02.889279756+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 10:28:02.889375092+00:00','2026-10-15 10:28:02.889375092+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255) );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 10:28:02.889354183+00:00','2026-10-15 10:28:02.889354183+00:00','spiffe://example.org/agent','join_token','1234',NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 10:28:02.888772691+00:00','2026-10-15 10:28:02.888772691+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 10:28:02.889155782+00:00','2026-10-15 10:28:02.889155782+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 10:28:02.888951785+00:00','2026-10-15 10:28:02.888951785+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 10:28:02.885745628+00:00','2026-10-15 10:28:02.885745628+00:00',34,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		35: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 12:00:15.154087742+00:00','2026-10-15 12:00:15.154087742+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010ba0030201020209009b316e4ee05e76a8300a06082a8648ce3d040302301e311c301a0603550403131343412039623331366534656530356537366138301e170d3236313031353132303031355a170d3236313031353133303031355a301e311c301a06035504031313434120396233313665346565303565373661383059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d040302034800304502207c61c85c7e6e0c1cb2fb72ddc132759caef4f8eb26962b582fb4ca0c3b87db9d0221008e0d802b8577b055f626b781c5e2e124fd9eae56b65607cf6bd74a3347da1955','aa6db60e1a56024f1339b10cd5370cd0502dd3418ef59c7745bc60b41749cc11',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 12:00:15.154191439+00:00','2026-10-15 12:00:15.154191439+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 12:00:15.1561647+00:00','2026-10-15 12:00:15.1561647+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 12:00:15.156241784+00:00','2026-10-15 12:00:15.156241784+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 12:00:15.156484352+00:00','2026-10-15 12:00:15.156484352+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255) );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 12:00:15.156425954+00:00','2026-10-15 12:00:15.156425954+00:00','spiffe://example.org/agent','join_token','1234',NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 12:00:15.155408678+00:00','2026-10-15 12:00:15.155408678+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 12:00:15.156014349+00:00','2026-10-15 12:00:15.156014349+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 12:00:15.155696406+00:00','2026-10-15 12:00:15.155696406+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 12:00:15.1509703+00:00','2026-10-15 12:00:15.1509703+00:00',35,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		36: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 12:27:12.487486923+00:00','2026-10-15 12:27:12.487486923+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712eb020ae802308201643082010aa00302010202085dccc8e96c5ccad1300a06082a8648ce3d040302301e311c301a0603550403131343412035646363633865393663356363616431301e170d3236313031353132323731325a170d3236313031353133323731325a301e311c301a06035504031313434120356463636338653936633563636164313059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020348003045022100ce3526fc55138ca18e0f68972de7398ee517d82d9aa62a317d7aeefd26681ff6022068c324cd09af36e033f685068963616380add91ac23e4a92de267dd013f888dd','01d5b1bb64e4e8c5427ac475f92e7f613c6e6d8aa00c7b21478ffb80d3c56c8c',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 12:27:12.487580135+00:00','2026-10-15 12:27:12.487580135+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 12:27:12.489298324+00:00','2026-10-15 12:27:12.489298324+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 12:27:12.489340918+00:00','2026-10-15 12:27:12.489340918+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 12:27:12.489457011+00:00','2026-10-15 12:27:12.489457011+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 12:27:12.489432235+00:00','2026-10-15 12:27:12.489432235+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 12:27:12.488745152+00:00','2026-10-15 12:27:12.488745152+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 12:27:12.489187403+00:00','2026-10-15 12:27:12.489187403+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 12:27:12.489560508+00:00','2026-10-15 12:27:12.489560508+00:00','token-1',1893456000);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 12:27:12.488952442+00:00','2026-10-15 12:27:12.488952442+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 12:27:12.485161014+00:00','2026-10-15 12:27:12.485161014+00:00',36,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		37: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 12:52:22.953306534+00:00','2026-10-15 12:52:22.953306534+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712e9020ae6023082016230820108a00302010202080a4268a09064c08e300a06082a8648ce3d040302301d311b301906035504031312434120613432363861303930363463303865301e170d3236313031353132353232325a170d3236313031353133353232325a301d311b3019060355040313124341206134323638613039303634633038653059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d040302034800304502200c151472134c88a205fda97eb2c298f3d8e00e6ef89b8745af78d36ce78d2e33022100ad98f2145c92952b7e970373f6725d13379e3218a20b213928611c4c006bd375','c5e93a8d7faae6a9a07a9bbee489ecce729d8e1f34c5e12e8760ba19ae21ef79',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 12:52:22.953378029+00:00','2026-10-15 12:52:22.953378029+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 12:52:22.954713398+00:00','2026-10-15 12:52:22.954713398+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 12:52:22.954749927+00:00','2026-10-15 12:52:22.954749927+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 12:52:22.954857307+00:00','2026-10-15 12:52:22.954857307+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 12:52:22.954833531+00:00','2026-10-15 12:52:22.954833531+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 12:52:22.954203294+00:00','2026-10-15 12:52:22.954203294+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 12:52:22.954619665+00:00','2026-10-15 12:52:22.954619665+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint,"remaining_uses" integer DEFAULT 1 );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 12:52:22.954900618+00:00','2026-10-15 12:52:22.954900618+00:00','token-1',1893456000,1);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 12:52:22.954378109+00:00','2026-10-15 12:52:22.954378109+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 12:52:22.951000092+00:00','2026-10-15 12:52:22.951000092+00:00',37,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		38: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 13:20:05.405831209+00:00','2026-10-15 13:20:05.405831209+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712eb020ae802308201643082010ba0030201020209008822a54580a34208300a06082a8648ce3d040302301e311c301a0603550403131343412038383232613534353830613334323038301e170d3236313031353133323030355a170d3236313031353134323030355a301e311c301a06035504031313434120383832326135343538306133343230383059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d04030203470030440220720fbffd6e043d7760ed6a3030437d4623f661be7b1fcb7a89f35641fb2591a3022050e812b20a8b7b78e43cc82bb672ec3e241095d6c3129aa2a53ea8da3e097dfa','df5674e919010b957b7506da29f1848f329c348405c9b7636cec9486ad04b0d4',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 13:20:05.405915363+00:00','2026-10-15 13:20:05.405915363+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 13:20:05.407247509+00:00','2026-10-15 13:20:05.407247509+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0);
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 13:20:05.407287989+00:00','2026-10-15 13:20:05.407287989+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 13:20:05.407399703+00:00','2026-10-15 13:20:05.407399703+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 13:20:05.407374561+00:00','2026-10-15 13:20:05.407374561+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 13:20:05.406758352+00:00','2026-10-15 13:20:05.406758352+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 13:20:05.407154258+00:00','2026-10-15 13:20:05.407154258+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint,"remaining_uses" integer DEFAULT 1 );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 13:20:05.407443041+00:00','2026-10-15 13:20:05.407443041+00:00','token-1',1893456000,1);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 13:20:05.406932143+00:00','2026-10-15 13:20:05.406932143+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 13:20:05.400839724+00:00','2026-10-15 13:20:05.400839724+00:00',38,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		39: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime );
+			INSERT INTO bundles VALUES(1,'2026-10-15 13:59:40.312120014+00:00','2026-10-15 13:59:40.312120014+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010ba003020102020900faac392d4a77b057300a06082a8648ce3d040302301e311c301a0603550403131343412066616163333932643461373762303537301e170d3236313031353133353934305a170d3236313031353134353934305a301e311c301a06035504031313434120666161633339326434613737623035373059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d040302034800304502203f3bc9f0503dc056f4860ebd2985d019af75d113e9ae8230d0a69c2e7220227f022100d565d4c7a3ff94bbb1154c4f2961c0466f5d66a734c87bbf067b6914dc1c2574','4a29d2195d7a88f67833dda1b791750fc60787d629979b96546ac03a34f96cd0',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 13:59:40.312183828+00:00','2026-10-15 13:59:40.312183828+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool,"attested_at" datetime,"last_attested_at" datetime );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 13:59:40.313417348+00:00','2026-10-15 13:59:40.313417348+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0,'2026-10-15 13:59:40+00:00','2026-10-15 13:59:40+00:00');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 13:59:40.31345308+00:00','2026-10-15 13:59:40.31345308+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 13:59:40.313546049+00:00','2026-10-15 13:59:40.313546049+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 13:59:40.313523887+00:00','2026-10-15 13:59:40.313523887+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 13:59:40.312967545+00:00','2026-10-15 13:59:40.312967545+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 13:59:40.313328892+00:00','2026-10-15 13:59:40.313328892+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint,"remaining_uses" integer DEFAULT 1 );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 13:59:40.313582437+00:00','2026-10-15 13:59:40.313582437+00:00','token-1',1893456000,1);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 13:59:40.313136613+00:00','2026-10-15 13:59:40.313136613+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 13:59:40.310121752+00:00','2026-10-15 13:59:40.310121752+00:00',39,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255) );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		40: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime,"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO bundles VALUES(1,'2026-10-15 14:43:35.756423926+00:00','2026-10-15 14:43:35.756423926+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ed020aea02308201663082010ba003020102020900d78d925ff578a9fa300a06082a8648ce3d040302301e311c301a0603550403131343412064373864393235666635373861396661301e170d3236313031353134343333355a170d3236313031353135343333355a301e311c301a06035504031313434120643738643932356666353738613966613059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d04030203490030460221008dd5fe3b0eb19aa66fa2d131d334796f6ea3b109f1cd67b944d6392fcc60ca5d022100d3304ab3ec44de68ee5ff942e73de9bf51768b1729856e020c1967335f7a05e8','7612556c32352f792dc864703895630ebb51da4b6c6e7f5096185c4d545bfb2f',NULL,'',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 14:43:35.756537751+00:00','2026-10-15 14:43:35.756537751+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool,"attested_at" datetime,"last_attested_at" datetime );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 14:43:35.758150169+00:00','2026-10-15 14:43:35.758150169+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0,'2026-10-15 14:43:35+00:00','2026-10-15 14:43:35+00:00');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 14:43:35.758201233+00:00','2026-10-15 14:43:35.758201233+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 14:43:35.758319098+00:00','2026-10-15 14:43:35.758319098+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 14:43:35.758291324+00:00','2026-10-15 14:43:35.758291324+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 14:43:35.75757031+00:00','2026-10-15 14:43:35.75757031+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 14:43:35.758031184+00:00','2026-10-15 14:43:35.758031184+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint,"remaining_uses" integer DEFAULT 1 );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 14:43:35.758370899+00:00','2026-10-15 14:43:35.758370899+00:00','token-1',1893456000,1);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 14:43:35.757775252+00:00','2026-10-15 14:43:35.757775252+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 14:43:35.753704512+00:00','2026-10-15 14:43:35.753704512+00:00',40,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255),"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			COMMIT;
+			`,
+		41: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime,"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO bundles VALUES(1,'2026-10-15 15:40:11.783175568+00:00','2026-10-15 15:40:11.783175568+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010ba003020102020900dd037d34cedeade2300a06082a8648ce3d040302301e311c301a0603550403131343412064643033376433346365646561646532301e170d3236313031353135343031315a170d3236313031353136343031315a301e311c301a06035504031313434120646430333764333463656465616465323059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020348003045022100bff0749549db29b615a54e065c0cc600cac0bb7c3343b4997dae925c97d66892022039ff77dba5e0f9095de99d0a77cc468aadfa2d36d532455f07e74945253ca9fd','4b65bc7d72b6736493277722cba2652e27241384bdc0ca5365a18f97f4dd4fab',NULL,'',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 15:40:11.783256958+00:00','2026-10-15 15:40:11.783256958+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool,"attested_at" datetime,"last_attested_at" datetime );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 15:40:11.784533705+00:00','2026-10-15 15:40:11.784533705+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0,'2026-10-15 15:40:11+00:00','2026-10-15 15:40:11+00:00');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 15:40:11.784571115+00:00','2026-10-15 15:40:11.784571115+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 15:40:11.784670653+00:00','2026-10-15 15:40:11.784670653+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 15:40:11.784648202+00:00','2026-10-15 15:40:11.784648202+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 15:40:11.784025106+00:00','2026-10-15 15:40:11.784025106+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 15:40:11.784416989+00:00','2026-10-15 15:40:11.784416989+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint,"remaining_uses" integer DEFAULT 1 );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 15:40:11.78471446+00:00','2026-10-15 15:40:11.78471446+00:00','token-1',1893456000,1);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 15:40:11.784176712+00:00','2026-10-15 15:40:11.784176712+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 15:40:11.780953129+00:00','2026-10-15 15:40:11.780953129+00:00',41,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024) );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255),"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			CREATE INDEX idx_attested_node_entries_updated_at ON "attested_node_entries"(updated_at) ;
+			COMMIT;
+			`,
+		42: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime,"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO bundles VALUES(1,'2026-10-15 16:46:48.867185189+00:00','2026-10-15 16:46:48.867185189+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712eb020ae802308201643082010ba003020102020900eedde37091ab2830300a06082a8648ce3d040302301e311c301a0603550403131343412065656464653337303931616232383330301e170d3236313031353136343634385a170d3236313031353137343634385a301e311c301a06035504031313434120656564646533373039316162323833303059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020347003044022051935d3fea47dae9fc6a05d183f264cd69054ce77609c5f5c1abe497bb374cb302203a799f306741d4da0e5a962371287a65f475b6e61c8460e7d0583ce3709df368','54a163847c9c11af3286ca084061709b9a0b9887eeae056fbb4fc4a02e71864d',NULL,'',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 16:46:48.867304297+00:00','2026-10-15 16:46:48.867304297+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool,"attested_at" datetime,"last_attested_at" datetime );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 16:46:48.868880962+00:00','2026-10-15 16:46:48.868880962+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0,'2026-10-15 16:46:48+00:00','2026-10-15 16:46:48+00:00');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 16:46:48.86892557+00:00','2026-10-15 16:46:48.86892557+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 16:46:48.869039265+00:00','2026-10-15 16:46:48.869039265+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 16:46:48.869014421+00:00','2026-10-15 16:46:48.869014421+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 16:46:48.868265494+00:00','2026-10-15 16:46:48.868265494+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 16:46:48.868732302+00:00','2026-10-15 16:46:48.868732302+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint,"remaining_uses" integer DEFAULT 1 );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 16:46:48.869086892+00:00','2026-10-15 16:46:48.869086892+00:00','token-1',1893456000,1);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 16:46:48.868497966+00:00','2026-10-15 16:46:48.868497966+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 16:46:48.863914065+00:00','2026-10-15 16:46:48.863914065+00:00',42,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024),"next_poll_at" datetime );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255),"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			CREATE INDEX idx_attested_node_entries_updated_at ON "attested_node_entries"(updated_at) ;
+			COMMIT;
+			`,
+		43: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime,"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO bundles VALUES(1,'2026-10-15 17:08:45.238237125+00:00','2026-10-15 17:08:45.238237125+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712ec020ae902308201653082010ba003020102020900866a086e707ba2da300a06082a8648ce3d040302301e311c301a0603550403131343412038363661303836653730376261326461301e170d3236313031353137303834355a170d3236313031353138303834355a301e311c301a06035504031313434120383636613038366537303762613264613059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020348003045022100e0e73a767722c7177595ae53526444893f055ad6b6d5ec5f5fab116d6a61a94402204dda788e1ccc322a928cceb07c0bddd89f018cead913b0a3e4975e81c505f17b','81d93ad7ba3165cbb675e1c352c93021169b737f2d71610733f73fa8c85fbdd0',NULL,'',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 17:08:45.238341668+00:00','2026-10-15 17:08:45.238341668+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool,"attested_at" datetime,"last_attested_at" datetime );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 17:08:45.240197596+00:00','2026-10-15 17:08:45.240197596+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0,'2026-10-15 17:08:45+00:00','2026-10-15 17:08:45+00:00');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 17:08:45.240258054+00:00','2026-10-15 17:08:45.240258054+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 17:08:45.240492106+00:00','2026-10-15 17:08:45.240492106+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 17:08:45.2404542+00:00','2026-10-15 17:08:45.2404542+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true,"federates_with_all" bool );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 17:08:45.239447396+00:00','2026-10-15 17:08:45.239447396+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1,0);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 17:08:45.240053509+00:00','2026-10-15 17:08:45.240053509+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint,"remaining_uses" integer DEFAULT 1 );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 17:08:45.24055824+00:00','2026-10-15 17:08:45.24055824+00:00','token-1',1893456000,1);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 17:08:45.2397305+00:00','2026-10-15 17:08:45.2397305+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 17:08:45.235256305+00:00','2026-10-15 17:08:45.235256305+00:00',43,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024),"next_poll_at" datetime );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255),"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE INDEX idx_selectors_type_value ON "selectors"("type", "value") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			CREATE INDEX idx_attested_node_entries_updated_at ON "attested_node_entries"(updated_at) ;
+			COMMIT;
+			`,
+		44: `
+			PRAGMA foreign_keys=OFF;
+			BEGIN TRANSACTION;
+			CREATE TABLE IF NOT EXISTS "federated_registration_entries" ("bundle_id" integer,"registered_entry_id" integer, PRIMARY KEY ("bundle_id","registered_entry_id"));
+			CREATE TABLE IF NOT EXISTS "bundles" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"data" blob,"content_hash" varchar(255),"last_refreshed_at" datetime,"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO bundles VALUES(1,'2026-10-15 17:21:40.867129549+00:00','2026-10-15 17:21:40.867129549+00:00','spiffe://example.org',X'0a147370696666653a2f2f6578616d706c652e6f726712e8020ae5023082016130820108a00302010202080e0e95f8c065eb68300a06082a8648ce3d040302301d311b301906035504031312434120653065393566386330363565623638301e170d3236313031353137323134305a170d3236313031353138323134305a301d311b3019060355040313124341206530653935663863303635656236383059301306072a8648ce3d020106082a8648ce3d030107034200046cb1675f992dc3e0836ba4b05d481c73b28e643cf497176d49bb39f00ae60a355e4b9f38540379eddbee9ed7c6485d40f197e0a992f098735d0c3ba49883a004a3323030300f0603551d130101ff040530030101ff301d0603551d0e0416041442c702d94031c6bc849ec99fa361802a877bdade300a06082a8648ce3d0403020347003044022070031b597674e38986cbd59545bf355eb0d37cd479aae35a214d86616f2ed68f022053be532a69f850181395e5fa0ea6fc5bd581af090ca2f65018485e1ad4e9e1dd','dfaa1a87a985f429e034e0f1e97597267bddeba6ef04f13e4a6455d7d05016d2',NULL,'',NULL);
+			CREATE TABLE IF NOT EXISTS "bundles_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) );
+			INSERT INTO bundles_events VALUES(1,'2026-10-15 17:21:40.867210517+00:00','2026-10-15 17:21:40.867210517+00:00','spiffe://example.org');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"data_type" varchar(255),"serial_number" varchar(255),"expires_at" datetime,"new_serial_number" varchar(255),"new_expires_at" datetime,"can_reattest" bool,"attested_at" datetime,"last_attested_at" datetime );
+			INSERT INTO attested_node_entries VALUES(1,'2026-10-15 17:21:40.868727709+00:00','2026-10-15 17:21:40.868727709+00:00','spiffe://example.org/agent','join_token','1234','2030-01-01 00:00:00+00:00','',NULL,0,'2026-10-15 17:21:40+00:00','2026-10-15 17:21:40+00:00');
+			CREATE TABLE IF NOT EXISTS "attested_node_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255) );
+			INSERT INTO attested_node_entries_events VALUES(1,'2026-10-15 17:21:40.868794831+00:00','2026-10-15 17:21:40.868794831+00:00','spiffe://example.org/agent');
+			INSERT INTO attested_node_entries_events VALUES(2,'2026-10-15 17:21:40.86890273+00:00','2026-10-15 17:21:40.86890273+00:00','spiffe://example.org/agent');
+			CREATE TABLE IF NOT EXISTS "attested_node_serial_history" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"serial_number" varchar(255),"reason" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "node_resolver_map_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"spiffe_id" varchar(255),"type" varchar(255),"value" varchar(255),"source" varchar(255),"expires_at" datetime );
+			INSERT INTO node_resolver_map_entries VALUES(1,'2026-10-15 17:21:40.868879336+00:00','2026-10-15 17:21:40.868879336+00:00','spiffe://example.org/agent','join_token','1234',NULL,NULL);
+			CREATE TABLE IF NOT EXISTS "registered_entries" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255),"spiffe_id" varchar(255),"parent_id" varchar(255),"trust_domain" varchar(255),"ttl" integer,"admin" bool,"downstream" bool,"expiry" bigint,"revision_number" bigint,"store_svid" bool,"hint" varchar(255),"jwt_svid_ttl" integer,"created_by" varchar(255),"idempotency_key" varchar(255),"active" bool DEFAULT true,"federates_with_all" bool );
+			INSERT INTO registered_entries VALUES(1,'2026-10-15 17:21:40.868122529+00:00','2026-10-15 17:21:40.868122529+00:00','entry-1','spiffe://example.org/workload','spiffe://example.org/agent','example.org',0,0,0,0,0,0,'',0,'',NULL,1,0);
+			CREATE TABLE IF NOT EXISTS "registered_entries_events" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"entry_id" varchar(255) );
+			INSERT INTO registered_entries_events VALUES(1,'2026-10-15 17:21:40.86862234+00:00','2026-10-15 17:21:40.86862234+00:00','entry-1');
+			CREATE TABLE IF NOT EXISTS "join_tokens" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"token" varchar(255),"expiry" bigint,"remaining_uses" integer DEFAULT 1 );
+			INSERT INTO join_tokens VALUES(1,'2026-10-15 17:21:40.868949218+00:00','2026-10-15 17:21:40.868949218+00:00','token-1',1893456000,1);
+			CREATE TABLE IF NOT EXISTS "selectors" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"type" varchar(255),"value" varchar(255) );
+			INSERT INTO selectors VALUES(1,'2026-10-15 17:21:40.868296265+00:00','2026-10-15 17:21:40.868296265+00:00',1,'unix','uid:1000');
+			CREATE TABLE IF NOT EXISTS "migrations" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"version" integer,"code_version" varchar(255) );
+			INSERT INTO migrations VALUES(1,'2026-10-15 17:21:40.864790214+00:00','2026-10-15 17:21:40.864790214+00:00',44,'1.12.0-dev-unk');
+			CREATE TABLE IF NOT EXISTS "dns_names" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"registered_entry_id" integer,"value" varchar(255) );
+			CREATE TABLE IF NOT EXISTS "federated_trust_domains" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"trust_domain" varchar(255) NOT NULL,"bundle_endpoint_url" varchar(255),"bundle_endpoint_profile" varchar(255),"endpoint_spiffe_id" varchar(255),"implicit" bool,"refresh_hint" bigint,"last_poll_at" datetime,"last_poll_error" varchar(1024),"next_poll_at" datetime );
+			CREATE TABLE IF NOT EXISTS "ca_journals" ("id" integer primary key autoincrement,"created_at" datetime,"updated_at" datetime,"data" blob,"active_x509_authority_id" varchar(255),"active_jwt_authority_id" varchar(255),"encryption_key_ref" varchar(255),"encryption_nonce" blob );
+			INSERT INTO sqlite_sequence VALUES('migrations',1);
+			INSERT INTO sqlite_sequence VALUES('bundles',1);
+			INSERT INTO sqlite_sequence VALUES('bundles_events',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries',1);
+			INSERT INTO sqlite_sequence VALUES('selectors',1);
+			INSERT INTO sqlite_sequence VALUES('registered_entries_events',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries',1);
+			INSERT INTO sqlite_sequence VALUES('attested_node_entries_events',2);
+			INSERT INTO sqlite_sequence VALUES('node_resolver_map_entries',1);
+			INSERT INTO sqlite_sequence VALUES('join_tokens',1);
+			CREATE UNIQUE INDEX uix_bundles_trust_domain ON "bundles"(trust_domain) ;
+			CREATE INDEX idx_attested_node_entries_data_type ON "attested_node_entries"(data_type) ;
+			CREATE INDEX idx_attested_node_entries_expires_at ON "attested_node_entries"(expires_at) ;
+			CREATE UNIQUE INDEX uix_attested_node_entries_spiffe_id ON "attested_node_entries"(spiffe_id) ;
+			CREATE INDEX idx_attested_node_serial_history_spiffe_id ON "attested_node_serial_history"(spiffe_id) ;
+			CREATE INDEX idx_node_resolver_map_entries_expires_at ON "node_resolver_map_entries"(expires_at) ;
+			CREATE UNIQUE INDEX idx_node_resolver_map ON "node_resolver_map_entries"(spiffe_id, "type", "value") ;
+			CREATE INDEX idx_registered_entries_spiffe_id ON "registered_entries"(spiffe_id) ;
+			CREATE INDEX idx_registered_entries_parent_id ON "registered_entries"(parent_id) ;
+			CREATE INDEX idx_registered_entries_trust_domain ON "registered_entries"(trust_domain) ;
+			CREATE INDEX idx_registered_entries_expiry ON "registered_entries"("expiry") ;
+			CREATE INDEX idx_registered_entries_hint ON "registered_entries"("hint") ;
+			CREATE INDEX idx_registered_entries_created_by ON "registered_entries"(created_by) ;
+			CREATE UNIQUE INDEX uix_registered_entries_entry_id ON "registered_entries"(entry_id) ;
+			CREATE UNIQUE INDEX uix_registered_entries_idempotency_key ON "registered_entries"(idempotency_key) ;
+			CREATE UNIQUE INDEX uix_join_tokens_token ON "join_tokens"("token") ;
+			CREATE UNIQUE INDEX idx_selector_entry ON "selectors"(registered_entry_id, "type", "value") ;
+			CREATE UNIQUE INDEX idx_dns_entry ON "dns_names"(registered_entry_id, "value") ;
+			CREATE UNIQUE INDEX uix_federated_trust_domains_trust_domain ON "federated_trust_domains"(trust_domain) ;
+			CREATE INDEX idx_ca_journals_active_x509_authority_id ON "ca_journals"(active_x509_authority_id) ;
+			CREATE INDEX idx_ca_journals_active_jwt_authority_id ON "ca_journals"(active_jwt_authority_id) ;
+			CREATE INDEX idx_federated_registration_entries_registered_entry_id ON "federated_registration_entries"(registered_entry_id) ;
+			CREATE INDEX idx_attested_node_entries_updated_at ON "attested_node_entries"(updated_at) ;
+			CREATE INDEX idx_selectors_type_value_entry ON "selectors"("type", "value", registered_entry_id) ;
+			COMMIT;
+			`,
 	}
 )
 
@@ -195,3 +1440,54 @@ func TestIsDisabledMigrationAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSchemaVersion(t *testing.T) {
+	tests := []struct {
+		desc          string
+		schemaVersion int
+		dbCodeVersion semver.Version
+		expectErr     string
+	}{
+		{
+			desc:          "latest schema version",
+			schemaVersion: latestSchemaVersion,
+			dbCodeVersion: codeVersion,
+		},
+		{
+			desc:          "schema version of the last minor release",
+			schemaVersion: lastMinorReleaseSchemaVersion,
+			dbCodeVersion: semver.Version{Major: codeVersion.Major, Minor: (codeVersion.Minor - 1)},
+		},
+		{
+			desc:          "newer schema version migrated by a compatible code version",
+			schemaVersion: latestSchemaVersion + 1,
+			dbCodeVersion: semver.Version{Major: codeVersion.Major, Minor: (codeVersion.Minor + 1)},
+		},
+		{
+			desc:          "newer schema version migrated by an incompatible code version",
+			schemaVersion: latestSchemaVersion + 1,
+			dbCodeVersion: semver.Version{Major: codeVersion.Major, Minor: (codeVersion.Minor + 2)},
+			expectErr:     fmt.Sprintf("DB schema version %d, migrated by SPIRE Server %d.%d.0, is newer than the latest schema version %d supported by SPIRE Server %s; upgrade SPIRE Server", latestSchemaVersion+1, codeVersion.Major, codeVersion.Minor+2, latestSchemaVersion, codeVersion),
+		},
+		{
+			desc:          "schema version older than the one of the last minor release",
+			schemaVersion: lastMinorReleaseSchemaVersion - 1,
+			dbCodeVersion: semver.Version{Major: codeVersion.Major, Minor: (codeVersion.Minor - 2)},
+			expectErr:     fmt.Sprintf("migrating from schema version %d requires a previous SPIRE release; please follow the upgrade strategy at doc/upgrading.md", lastMinorReleaseSchemaVersion-1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			err := checkSchemaVersion(tt.schemaVersion, codeVersion, tt.dbCodeVersion)
+
+			if tt.expectErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectErr, err.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
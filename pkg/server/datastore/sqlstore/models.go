@@ -19,21 +19,52 @@ type Bundle struct {
 	TrustDomain string `gorm:"not null;unique_index"`
 	Data        []byte `gorm:"size:16777215"` // make MySQL to use MEDIUMBLOB (max 16MB) - doesn't affect PostgreSQL/SQLite
 
+	// ContentHash is the hex encoded SHA-256 hash of Data. It allows
+	// detecting bundle changes without loading the bundle data.
+	ContentHash string
+
+	// LastRefreshedAt is the last time the bundle was refreshed from its
+	// bundle endpoint. It is nil if the bundle has never been refreshed.
+	LastRefreshedAt *time.Time
+
+	// EncryptionKeyRef references the key Data is encrypted with, and
+	// EncryptionNonce is the nonce it was encrypted with. Data is in
+	// plaintext if EncryptionKeyRef is empty.
+	EncryptionKeyRef string
+	EncryptionNonce  []byte
+
 	FederatedEntries []RegisteredEntry `gorm:"many2many:federated_registration_entries;"`
 }
 
+// BundleEvent holds the trust domain of a bundle that had an event
+type BundleEvent struct {
+	Model
+
+	TrustDomain string
+}
+
+// TableName gets table name for BundleEvent
+func (BundleEvent) TableName() string {
+	return "bundles_events"
+}
+
 // AttestedNode holds an attested node (agent)
 type AttestedNode struct {
 	Model
 
 	SpiffeID        string `gorm:"unique_index"`
-	DataType        string
+	DataType        string `gorm:"index"`
 	SerialNumber    string
 	ExpiresAt       time.Time `gorm:"index"`
 	NewSerialNumber string
 	NewExpiresAt    *time.Time
 	CanReattest     bool
 
+	// AttestedAt is the time the node first attested. It is left unchanged
+	// when the node reattests, unlike LastAttestedAt.
+	AttestedAt     time.Time
+	LastAttestedAt time.Time
+
 	Selectors []*NodeSelector
 }
 
@@ -54,6 +85,35 @@ func (AttestedNodeEvent) TableName() string {
 	return "attested_node_entries_events"
 }
 
+// AttestedNodeSerialHistory holds a serial number previously used by an
+// attested node. CreatedAt is the time the serial number was superseded.
+type AttestedNodeSerialHistory struct {
+	Model
+
+	SpiffeID     string `gorm:"index"`
+	SerialNumber string
+	Reason       string
+}
+
+// TableName gets table name for AttestedNodeSerialHistory
+func (AttestedNodeSerialHistory) TableName() string {
+	return "attested_node_serial_history"
+}
+
+// AttestedNodeAlias maps an alias SPIFFE ID to the SPIFFE ID of the attested
+// node it stands for
+type AttestedNodeAlias struct {
+	Model
+
+	AliasSpiffeID string `gorm:"unique_index"`
+	SpiffeID      string `gorm:"index"`
+}
+
+// TableName gets table name for AttestedNodeAlias
+func (AttestedNodeAlias) TableName() string {
+	return "attested_node_aliases"
+}
+
 type V3AttestedNode struct {
 	Model
 
@@ -74,6 +134,14 @@ type NodeSelector struct {
 	SpiffeID string `gorm:"unique_index:idx_node_resolver_map"`
 	Type     string `gorm:"unique_index:idx_node_resolver_map"`
 	Value    string `gorm:"unique_index:idx_node_resolver_map"`
+
+	// Source is the name of the plugin that produced the selector. It is
+	// NULL for selectors stored before it was introduced.
+	Source *string
+
+	// ExpiresAt is the time after which the selector is stale. It is NULL
+	// for selectors that never expire.
+	ExpiresAt *time.Time `gorm:"index"`
 }
 
 // TableName gets table name of NodeSelector
@@ -88,6 +156,9 @@ type RegisteredEntry struct {
 	EntryID  string `gorm:"unique_index"`
 	SpiffeID string `gorm:"index"`
 	ParentID string `gorm:"index"`
+	// TrustDomain is the trust domain name of the SPIFFE ID. It is
+	// denormalized from SpiffeID to allow grouping entries by trust domain.
+	TrustDomain string `gorm:"index"`
 	// TTL of identities derived from this entry. This field represents the X509-SVID TTL of the Entry
 	TTL           int32
 	Selectors     []Selector
@@ -112,6 +183,22 @@ type RegisteredEntry struct {
 
 	// TTL of JWT identities derived from this entry
 	JWTSvidTTL int32 `gorm:"column:jwt_svid_ttl"`
+
+	// CreatedBy is the SPIFFE ID of the caller that created the entry. It is
+	// empty when the creator is unknown.
+	CreatedBy string `gorm:"index"`
+
+	// IdempotencyKey is an optional client-supplied key used to detect
+	// retried creations. It is NULL when no key was supplied.
+	IdempotencyKey *string `gorm:"unique_index"`
+
+	// Active determines if the entry is used to issue SVIDs. Inactive
+	// entries are kept, along with their selectors and history.
+	Active bool `gorm:"default:true"`
+
+	// FederatesWithAll determines if the entry federates with every trust
+	// domain that has a bundle, rather than with the FederatesWith list.
+	FederatesWithAll bool
 }
 
 // RegisteredEntryEvent holds the entry id of a registered entry that had an event
@@ -132,14 +219,19 @@ type JoinToken struct {
 
 	Token  string `gorm:"unique_index"`
 	Expiry int64
+
+	RemainingUses int `gorm:"default:1"`
 }
 
 type Selector struct {
 	Model
 
+	// The idx_selectors_type_value_entry index on type, value and
+	// registered_entry_id is created manually, see
+	// addSelectorsTypeValueEntryIndex.
 	RegisteredEntryID uint   `gorm:"unique_index:idx_selector_entry"`
-	Type              string `gorm:"unique_index:idx_selector_entry;index:idx_selectors_type_value"`
-	Value             string `gorm:"unique_index:idx_selector_entry;index:idx_selectors_type_value"`
+	Type              string `gorm:"unique_index:idx_selector_entry"`
+	Value             string `gorm:"unique_index:idx_selector_entry"`
 }
 
 // DNSName holds a DNS for a registration entry
@@ -179,6 +271,21 @@ type FederatedTrustDomain struct {
 	// Implicit indicates whether the trust domain automatically federates with
 	// all registration entries by default or not.
 	Implicit bool
+
+	// RefreshHint is how often, in seconds, the bundle of the trust domain
+	// is polled. Zero means the refresh hint of the bundle is used instead.
+	RefreshHint int64
+
+	// LastPollAt is the last time the bundle of the trust domain was polled.
+	// It is nil if the bundle has never been polled.
+	LastPollAt *time.Time
+
+	// LastPollError is the error of the last poll, or empty if it succeeded.
+	LastPollError string `gorm:"size:1024"`
+
+	// NextPollAt is when the bundle of the trust domain is polled next. It
+	// is nil if the bundle has never been polled.
+	NextPollAt *time.Time
 }
 
 // TableName gets table name of FederatedTrustDomain
@@ -202,6 +309,10 @@ type CAJournal struct {
 	// ActiveJWTAuthorityID is the JWT key ID (i.e. "kid" claim) of the current
 	// active JWT authority in a server.
 	ActiveJWTAuthorityID string `gorm:"index:idx_ca_journals_active_jwt_authority_id"`
+
+	// EncryptionKeyRef and EncryptionNonce are the same as for bundles.
+	EncryptionKeyRef string
+	EncryptionNonce  []byte
 }
 
 // Migration holds database schema version number, and
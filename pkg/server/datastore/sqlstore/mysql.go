@@ -4,8 +4,10 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"errors"
+	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 	"github.com/jinzhu/gorm"
@@ -26,6 +28,12 @@ type mysqlDB struct {
 
 const (
 	tlsConfigName = "spireCustomTLS"
+
+	// TLS modes that can be set with tls_mode
+	tlsModeDisable    = "disable"
+	tlsModeRequire    = "require"
+	tlsModeVerifyCA   = "verify-ca"
+	tlsModeVerifyFull = "verify-full"
 )
 
 func (my mysqlDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, version string, supportsCTE bool, err error) {
@@ -51,9 +59,9 @@ func (my mysqlDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, ver
 		if err != nil {
 			return nil, "", false, err
 		}
-		db, errOpen = gorm.Open(awsrds.MySQLDriverName, dsn)
+		db, errOpen = openWithConnHealth(awsrds.MySQLDriverName, awsrds.MySQLDriverName, dsn, my.isConnectionError)
 	default:
-		db, errOpen = gorm.Open("mysql", mysqlConfig.FormatDSN())
+		db, errOpen = openWithConnHealth("mysql", "mysql", mysqlConfig.FormatDSN(), my.isConnectionError)
 	}
 
 	if errOpen != nil {
@@ -106,6 +114,45 @@ func (my mysqlDB) isConstraintViolation(err error) bool {
 	return ok && e.Number == 1062 // ER_DUP_ENTRY
 }
 
+func (my mysqlDB) isTransientError(err error) bool {
+	var e *mysql.MySQLError
+	ok := errors.As(err, &e)
+	return ok && e.Number == 1213 // ER_LOCK_DEADLOCK
+}
+
+func (my mysqlDB) isConnectionError(err error) bool {
+	if errors.Is(err, mysql.ErrInvalidConn) || isNetworkError(err) {
+		return true
+	}
+	var e *mysql.MySQLError
+	ok := errors.As(err, &e)
+	return ok && e.Number == 1053 // ER_SERVER_SHUTDOWN
+}
+
+func (my mysqlDB) setStatementTimeout(tx *gorm.DB, timeout time.Duration) error {
+	// There is no transaction-level equivalent, so the session variable is
+	// set by every transaction, including those with the timeout disabled.
+	// It only applies to read-only SELECT statements.
+	return tx.Exec(fmt.Sprintf("SET SESSION max_execution_time = %d", timeout.Milliseconds())).Error
+}
+
+func (my mysqlDB) isStatementTimeout(err error) bool {
+	var e *mysql.MySQLError
+	ok := errors.As(err, &e)
+	return ok && e.Number == 3024 // ER_QUERY_TIMEOUT
+}
+
+func (my mysqlDB) newMigrationLock(db *gorm.DB, _ *configuration) migrationLock {
+	// Named locks are global to the server, so the name includes the
+	// database, truncated to the maximum length of a lock name.
+	const name = "LEFT(CONCAT('spire_migration:', DATABASE()), 64)"
+	return &advisoryLock{
+		db:           db.DB(),
+		tryLockQuery: "SELECT GET_LOCK(" + name + ", 0)",
+		unlockQuery:  "SELECT RELEASE_LOCK(" + name + ")",
+	}
+}
+
 // configureConnection modifies the connection string to support features that
 // normally require code changes, like custom Root CAs or client certificates
 func configureConnection(cfg *configuration, isReadOnly bool) (*mysql.Config, error) {
@@ -117,7 +164,11 @@ func configureConnection(cfg *configuration, isReadOnly bool) (*mysql.Config, er
 		return nil, err
 	}
 
-	if !hasTLSConfig(cfg) {
+	switch {
+	case cfg.TLSMode == tlsModeDisable:
+		mysqlConfig.TLSConfig = "false"
+		return mysqlConfig, nil
+	case cfg.TLSMode == "" && !hasTLSConfig(cfg):
 		// connection string doesn't have to be modified
 		return mysqlConfig, nil
 	}
@@ -151,6 +202,18 @@ func configureConnection(cfg *configuration, isReadOnly bool) (*mysql.Config, er
 		tlsConf.Certificates = clientCert
 	}
 
+	switch cfg.TLSMode {
+	case tlsModeRequire:
+		// encrypt the connection without authenticating the server
+		tlsConf.InsecureSkipVerify = true //nolint: gosec // explicitly requested with tls_mode
+	case tlsModeVerifyCA:
+		// the standard verification checks both the certificate chain and
+		// the server name, so it is disabled in favor of checking the chain
+		// only
+		tlsConf.InsecureSkipVerify = true //nolint: gosec // the chain is verified by VerifyPeerCertificate
+		tlsConf.VerifyPeerCertificate = verifyCertificateChain(tlsConf.RootCAs)
+	}
+
 	// register a custom TLS config that uses custom Root CAs with the MySQL driver
 	if err := mysql.RegisterTLSConfig(tlsConfigName, &tlsConf); err != nil {
 		return nil, errors.New("failed to register mysql TLS config")
@@ -162,10 +225,65 @@ func configureConnection(cfg *configuration, isReadOnly bool) (*mysql.Config, er
 	return mysqlConfig, nil
 }
 
+// verifyCertificateChain returns a function that verifies that the peer
+// certificate chains up to the given roots, or to the system roots if nil,
+// without checking the server name.
+func verifyCertificateChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return errors.New("no server certificate presented")
+		}
+
+		certs := make([]*x509.Certificate, 0, len(rawCerts))
+		for _, rawCert := range rawCerts {
+			cert, err := x509.ParseCertificate(rawCert)
+			if err != nil {
+				return fmt.Errorf("failed to parse server certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		return err
+	}
+}
+
 func hasTLSConfig(cfg *configuration) bool {
 	return len(cfg.RootCAPath) > 0 || len(cfg.ClientCertPath) > 0 && len(cfg.ClientKeyPath) > 0
 }
 
+func validateMySQLTLSConfig(cfg *configuration) error {
+	switch cfg.TLSMode {
+	case "", tlsModeRequire, tlsModeVerifyCA, tlsModeVerifyFull:
+	case tlsModeDisable:
+		if cfg.RootCAPath != "" || cfg.ClientCertPath != "" || cfg.ClientKeyPath != "" {
+			return newSQLError("invalid mysql config: root_ca_path, client_cert_path and client_key_path cannot be set when tls_mode is %q", tlsModeDisable)
+		}
+	default:
+		return newSQLError("invalid mysql config: unknown tls_mode %q; expected one of %q, %q, %q or %q", cfg.TLSMode, tlsModeDisable, tlsModeRequire, tlsModeVerifyCA, tlsModeVerifyFull)
+	}
+
+	if (cfg.ClientCertPath == "") != (cfg.ClientKeyPath == "") {
+		return newSQLError("invalid mysql config: client_cert_path and client_key_path must be set together")
+	}
+
+	if cfg.RootCAPath != "" {
+		if _, err := os.Stat(cfg.RootCAPath); err != nil {
+			return newSQLError("invalid mysql config: cannot find Root CA defined in root_ca_path: %v", err)
+		}
+	}
+
+	return nil
+}
+
 func validateMySQLConfig(cfg *configuration, isReadOnly bool) error {
 	opts, err := mysql.ParseDSN(getConnectionString(cfg, isReadOnly))
 	if err != nil {
@@ -176,5 +294,9 @@ func validateMySQLConfig(cfg *configuration, isReadOnly bool) error {
 		return newSQLError("invalid mysql config: missing parseTime=true param in connection_string")
 	}
 
+	if cfg.TLSMode != "" && opts.TLSConfig != "" {
+		return newSQLError("invalid mysql config: tls param in connection_string cannot be used with tls_mode")
+	}
+
 	return nil
 }
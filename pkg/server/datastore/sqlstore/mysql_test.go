@@ -0,0 +1,196 @@
+package sqlstore
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/go-spiffe/v2/svid/x509svid"
+	"github.com/spiffe/spire/pkg/common/pemutil"
+	"github.com/spiffe/spire/test/testca"
+	"github.com/stretchr/testify/require"
+)
+
+const mysqlConnString = "user:pass@tcp(db.example.org:3306)/spire?parseTime=true"
+
+func TestConfigureMySQLTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := testca.New(t, spiffeid.RequireTrustDomainFromString("example.org"))
+	otherCA := testca.New(t, spiffeid.RequireTrustDomainFromString("example.org"))
+	serverSVID := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/mysql"))
+	clientSVID := ca.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/spire-server"))
+	otherServerSVID := otherCA.CreateX509SVID(spiffeid.RequireFromString("spiffe://example.org/mysql"))
+
+	rootCAPath := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(rootCAPath, pemutil.EncodeCertificates(ca.X509Authorities()), 0600))
+	clientCertPath := filepath.Join(dir, "client.pem")
+	require.NoError(t, os.WriteFile(clientCertPath, pemutil.EncodeCertificates(clientSVID.Certificates), 0600))
+	clientKeyPEM, err := pemutil.EncodePKCS8PrivateKey(clientSVID.PrivateKey)
+	require.NoError(t, err)
+	clientKeyPath := filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(clientKeyPath, clientKeyPEM, 0600))
+
+	rawCerts := func(svid *x509svid.SVID) [][]byte {
+		var raw [][]byte
+		for _, cert := range svid.Certificates {
+			raw = append(raw, cert.Raw)
+		}
+		return raw
+	}
+
+	for _, tt := range []struct {
+		name              string
+		tlsMode           string
+		rootCAPath        string
+		clientCert        bool
+		expectTLSConfig   string
+		expectSkipVerify  bool
+		expectVerifyChain bool
+	}{
+		{
+			name: "no tls config",
+		},
+		{
+			name:            "disabled",
+			tlsMode:         tlsModeDisable,
+			expectTLSConfig: "false",
+		},
+		{
+			name:            "root CA without mode",
+			rootCAPath:      rootCAPath,
+			expectTLSConfig: tlsConfigName,
+		},
+		{
+			name:             "require",
+			tlsMode:          tlsModeRequire,
+			expectTLSConfig:  tlsConfigName,
+			expectSkipVerify: true,
+		},
+		{
+			name:              "verify-ca",
+			tlsMode:           tlsModeVerifyCA,
+			rootCAPath:        rootCAPath,
+			expectTLSConfig:   tlsConfigName,
+			expectSkipVerify:  true,
+			expectVerifyChain: true,
+		},
+		{
+			name:            "verify-full with client certificate",
+			tlsMode:         tlsModeVerifyFull,
+			rootCAPath:      rootCAPath,
+			clientCert:      true,
+			expectTLSConfig: tlsConfigName,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &configuration{
+				ConnectionString: mysqlConnString,
+				RootCAPath:       tt.rootCAPath,
+				TLSMode:          tt.tlsMode,
+			}
+			if tt.clientCert {
+				cfg.ClientCertPath = clientCertPath
+				cfg.ClientKeyPath = clientKeyPath
+			}
+
+			mysqlConfig, err := configureConnection(cfg, false)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectTLSConfig, mysqlConfig.TLSConfig)
+
+			// Parsing the DSN back resolves the registered TLS config
+			parsed, err := mysql.ParseDSN(mysqlConfig.FormatDSN())
+			require.NoError(t, err)
+			if tt.expectTLSConfig != tlsConfigName {
+				require.Nil(t, parsed.TLS)
+				return
+			}
+			require.Contains(t, mysqlConfig.FormatDSN(), "tls="+tlsConfigName)
+			require.NotNil(t, parsed.TLS)
+			require.Equal(t, tt.expectSkipVerify, parsed.TLS.InsecureSkipVerify)
+			require.Equal(t, tt.rootCAPath != "", parsed.TLS.RootCAs != nil)
+			if tt.clientCert {
+				require.Len(t, parsed.TLS.Certificates, 1)
+			}
+			if !tt.expectSkipVerify {
+				require.Equal(t, "db.example.org", parsed.TLS.ServerName)
+			}
+
+			if tt.expectVerifyChain {
+				require.NotNil(t, parsed.TLS.VerifyPeerCertificate)
+				// The server name is not checked, only the chain
+				require.NoError(t, parsed.TLS.VerifyPeerCertificate(rawCerts(serverSVID), nil))
+				require.Error(t, parsed.TLS.VerifyPeerCertificate(rawCerts(otherServerSVID), nil))
+			} else {
+				require.Nil(t, parsed.TLS.VerifyPeerCertificate)
+			}
+		})
+	}
+}
+
+func TestValidateMySQLTLSConfig(t *testing.T) {
+	rootCAPath := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(rootCAPath, []byte("ca"), 0600))
+
+	for _, tt := range []struct {
+		name      string
+		cfg       *configuration
+		expectErr string
+	}{
+		{
+			name: "no tls config",
+			cfg:  &configuration{},
+		},
+		{
+			name: "verify-full with root CA",
+			cfg:  &configuration{TLSMode: tlsModeVerifyFull, RootCAPath: rootCAPath},
+		},
+		{
+			name:      "unknown mode",
+			cfg:       &configuration{TLSMode: "maybe"},
+			expectErr: `datastore-sql: invalid mysql config: unknown tls_mode "maybe"; expected one of "disable", "require", "verify-ca" or "verify-full"`,
+		},
+		{
+			name:      "disabled with root CA",
+			cfg:       &configuration{TLSMode: tlsModeDisable, RootCAPath: rootCAPath},
+			expectErr: `datastore-sql: invalid mysql config: root_ca_path, client_cert_path and client_key_path cannot be set when tls_mode is "disable"`,
+		},
+		{
+			name:      "client certificate without key",
+			cfg:       &configuration{ClientCertPath: "client.pem"},
+			expectErr: "datastore-sql: invalid mysql config: client_cert_path and client_key_path must be set together",
+		},
+		{
+			name:      "client key without certificate",
+			cfg:       &configuration{ClientKeyPath: "client.key"},
+			expectErr: "datastore-sql: invalid mysql config: client_cert_path and client_key_path must be set together",
+		},
+		{
+			name:      "missing root CA",
+			cfg:       &configuration{RootCAPath: filepath.Join(t.TempDir(), "missing.pem")},
+			expectErr: "datastore-sql: invalid mysql config: cannot find Root CA defined in root_ca_path",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMySQLTLSConfig(tt.cfg)
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestValidateMySQLConfigTLSParam(t *testing.T) {
+	cfg := &configuration{
+		ConnectionString: mysqlConnString + "&tls=true",
+		TLSMode:          tlsModeRequire,
+	}
+	err := validateMySQLConfig(cfg, false)
+	require.EqualError(t, err, "datastore-sql: invalid mysql config: tls param in connection_string cannot be used with tls_mode")
+
+	cfg.TLSMode = ""
+	require.NoError(t, validateMySQLConfig(cfg, false))
+}
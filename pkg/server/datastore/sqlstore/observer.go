@@ -0,0 +1,109 @@
+package sqlstore
+
+import (
+	"context"
+
+	"github.com/jinzhu/gorm"
+	"github.com/sirupsen/logrus"
+	"github.com/spiffe/spire/pkg/common/telemetry"
+)
+
+// mutationRecorderKey is the key the mutation recorder is set with on write
+// transactions.
+const mutationRecorderKey = "sqlstore:mutation_recorder"
+
+// Operations reported to the DataStoreObserver.
+const (
+	MutationCreate = "create"
+	MutationUpdate = "update"
+	MutationDelete = "delete"
+)
+
+// Types of the entities reported to the DataStoreObserver.
+const (
+	EntityTypeBundle                 = "bundle"
+	EntityTypeAttestedNode           = "attested_node"
+	EntityTypeNodeSelectors          = "node_selectors"
+	EntityTypeRegistrationEntry      = "registration_entry"
+	EntityTypeFederationRelationship = "federation_relationship"
+	EntityTypeCAJournal              = "ca_journal"
+)
+
+// DataStoreObserver observes the changes made to the datastore, e.g. to keep
+// an audit log of them.
+type DataStoreObserver interface {
+	// OnMutation is called once the transaction that created, updated or
+	// deleted the entity of the given type is committed. Bundles and
+	// federation relationships are identified by their trust domain,
+	// attested nodes and their selectors by their SPIFFE ID, registration
+	// entries by their entry ID and CA journals by their ID. Join tokens
+	// are secrets and are not reported.
+	//
+	// Errors are logged, since the change cannot be rolled back anymore.
+	OnMutation(ctx context.Context, op string, entityType string, entityID string) error
+}
+
+type mutation struct {
+	op         string
+	entityType string
+	entityID   string
+}
+
+// mutationRecorder records the mutations made by a write transaction, so they
+// are reported once it is committed.
+type mutationRecorder struct {
+	mutations []mutation
+}
+
+// wrap returns the operation recording the mutations it makes. The mutations
+// recorded by a previous attempt of the operation are discarded.
+func (r *mutationRecorder) wrap(op func(tx *gorm.DB) error) func(tx *gorm.DB) error {
+	return func(tx *gorm.DB) error {
+		r.mutations = r.mutations[:0]
+		return op(tx.Set(mutationRecorderKey, r))
+	}
+}
+
+// recordMutation records a mutation made by the transaction, to be reported
+// to the observer once the transaction is committed.
+func recordMutation(tx *gorm.DB, op, entityType, entityID string) {
+	value, ok := tx.Get(mutationRecorderKey)
+	if !ok {
+		return
+	}
+	r := value.(*mutationRecorder)
+	r.mutations = append(r.mutations, mutation{op: op, entityType: entityType, entityID: entityID})
+}
+
+// SetObserver sets the observer notified of the changes made to the
+// datastore. It can be nil to stop observing the changes.
+func (ds *Plugin) SetObserver(observer DataStoreObserver) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.observer = observer
+}
+
+// notifyObserver reports the mutations made by a committed transaction to
+// the observer, if any.
+func (ds *Plugin) notifyObserver(ctx context.Context, mutations []mutation) {
+	if len(mutations) == 0 {
+		return
+	}
+
+	ds.mu.Lock()
+	observer := ds.observer
+	ds.mu.Unlock()
+	if observer == nil {
+		return
+	}
+
+	for _, m := range mutations {
+		if err := observer.OnMutation(ctx, m.op, m.entityType, m.entityID); err != nil {
+			ds.log.WithError(err).WithFields(logrus.Fields{
+				telemetry.Mutation:   m.op,
+				telemetry.EntityType: m.entityType,
+				telemetry.EntityID:   m.entityID,
+			}).Error("Datastore observer failed to observe a mutation")
+		}
+	}
+}
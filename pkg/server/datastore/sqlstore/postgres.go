@@ -3,6 +3,7 @@ package sqlstore
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jinzhu/gorm"
@@ -13,6 +14,10 @@ import (
 	_ "github.com/jinzhu/gorm/dialects/postgres"
 )
 
+// postgresMigrationLockKey is the key of the advisory lock held while the
+// database is migrated ("SPIRE" in ASCII).
+const postgresMigrationLockKey int64 = 0x5350495245
+
 type postgresDB struct{}
 
 func (p postgresDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, version string, supportsCTE bool, err error) {
@@ -45,9 +50,9 @@ func (p postgresDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, v
 		if err != nil {
 			return nil, "", false, err
 		}
-		db, errOpen = gorm.Open(awsrds.PostgresDriverName, dsn)
+		db, errOpen = openWithConnHealth(awsrds.PostgresDriverName, awsrds.PostgresDriverName, dsn, p.isConnectionError)
 	default:
-		db, errOpen = gorm.Open("postgres", connString)
+		db, errOpen = openWithConnHealth("postgres", "postgres", connString, p.isConnectionError)
 	}
 
 	if errOpen != nil {
@@ -64,9 +69,50 @@ func (p postgresDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, v
 	return db, version, true, nil
 }
 
+func (p postgresDB) newMigrationLock(db *gorm.DB, _ *configuration) migrationLock {
+	// Advisory locks are scoped to the current database
+	return &advisoryLock{
+		db:           db.DB(),
+		tryLockQuery: "SELECT pg_try_advisory_lock($1)::int",
+		unlockQuery:  "SELECT pg_advisory_unlock($1)",
+		args:         []any{postgresMigrationLockKey},
+	}
+}
+
 func (p postgresDB) isConstraintViolation(err error) bool {
 	var e *pq.Error
 	ok := errors.As(err, &e)
 	// "23xxx" is the constraint violation class for PostgreSQL
 	return ok && e.Code.Class() == "23"
 }
+
+func (p postgresDB) isTransientError(err error) bool {
+	var e *pq.Error
+	ok := errors.As(err, &e)
+	// "40001" is serialization_failure and "40P01" is deadlock_detected
+	return ok && (e.Code == "40001" || e.Code == "40P01")
+}
+
+func (p postgresDB) isConnectionError(err error) bool {
+	var e *pq.Error
+	if errors.As(err, &e) {
+		// "08" is the connection exception class, while "57P01", "57P02"
+		// and "57P03" are admin_shutdown, crash_shutdown and
+		// cannot_connect_now
+		return e.Code.Class() == "08" || e.Code == "57P01" || e.Code == "57P02" || e.Code == "57P03"
+	}
+	return isNetworkError(err)
+}
+
+func (p postgresDB) setStatementTimeout(tx *gorm.DB, timeout time.Duration) error {
+	// SET does not take parameters. SET LOCAL only lasts until the end of
+	// the transaction.
+	return tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())).Error
+}
+
+func (p postgresDB) isStatementTimeout(err error) bool {
+	var e *pq.Error
+	ok := errors.As(err, &e)
+	// "57014" is query_canceled
+	return ok && e.Code == "57014"
+}
@@ -3,10 +3,15 @@
 package sqlstore
 
 import (
+	"context"
 	"errors"
 	"net/url"
+	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/mattn/go-sqlite3"
@@ -25,7 +30,7 @@ func (s sqliteDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, ver
 		s.log.Warn("Read-only connection is not applicable for sqlite3. Falling back to primary connection")
 	}
 
-	db, err = openSQLite3(cfg.ConnectionString)
+	db, err = openSQLite3(cfg.ConnectionString, cfg.sqliteWALMode(), cfg.sqliteBusyTimeout())
 	if err != nil {
 		return nil, "", false, err
 	}
@@ -48,8 +53,127 @@ func (s sqliteDB) isConstraintViolation(err error) bool {
 	return ok && e.Code == sqlite3.ErrConstraint
 }
 
-func openSQLite3(connString string) (*gorm.DB, error) {
-	embellished, err := embellishSQLite3ConnString(connString)
+func (s sqliteDB) isTransientError(error) bool {
+	// Writers are serialized by the datastore, so there is nothing to retry
+	return false
+}
+
+func (s sqliteDB) isConnectionError(error) bool {
+	// The database is a local file, there is no connection to lose
+	return false
+}
+
+func (s sqliteDB) setStatementTimeout(*gorm.DB, time.Duration) error {
+	// SQLite has no server-side timeout. Statements are only interrupted
+	// when their context expires.
+	return nil
+}
+
+func (s sqliteDB) isStatementTimeout(error) bool {
+	return false
+}
+
+func (s sqliteDB) newMigrationLock(_ *gorm.DB, cfg *configuration) migrationLock {
+	return &sqliteMigrationLock{
+		connectionString: cfg.ConnectionString,
+		mu:               sqliteMigrationMutex(cfg.ConnectionString),
+	}
+}
+
+// sqliteMigrationMutexes holds a mutex per connection string, which
+// serializes the migration of in-memory databases shared by datastores of the
+// same process.
+var sqliteMigrationMutexes sync.Map
+
+func sqliteMigrationMutex(connectionString string) *sync.Mutex {
+	mu, _ := sqliteMigrationMutexes.LoadOrStore(connectionString, new(sync.Mutex))
+	return mu.(*sync.Mutex)
+}
+
+// sqliteMigrationLock is a migration lock made of a mutex for datastores of
+// the same process and, for databases stored on disk, an exclusive lock on a
+// file next to the database for other processes.
+type sqliteMigrationLock struct {
+	connectionString string
+	mu               *sync.Mutex
+	file             *os.File
+}
+
+func (l *sqliteMigrationLock) tryLock(context.Context) (bool, error) {
+	if !l.mu.TryLock() {
+		return false, nil
+	}
+
+	path, err := sqliteDatabasePath(l.connectionString)
+	if err != nil {
+		l.mu.Unlock()
+		return false, err
+	}
+	if path == "" {
+		return true, nil
+	}
+
+	file, err := os.OpenFile(path+"-migration-lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		l.mu.Unlock()
+		return false, err
+	}
+	acquired, err := tryLockFile(file)
+	if err != nil || !acquired {
+		file.Close()
+		l.mu.Unlock()
+		return false, err
+	}
+
+	l.file = file
+	return true, nil
+}
+
+func (l *sqliteMigrationLock) unlock() error {
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	defer func() {
+		l.file.Close()
+		l.file = nil
+	}()
+	return unlockFile(l.file)
+}
+
+// sqliteDatabasePath returns the path of the database file, or an empty
+// string if the database is kept in memory.
+func sqliteDatabasePath(connectionString string) (string, error) {
+	embellished, err := embellishSQLite3ConnString(connectionString, false, 0)
+	if err != nil {
+		return "", err
+	}
+	u, err := url.Parse(embellished)
+	if err != nil {
+		return "", err
+	}
+
+	// The path of a URI without authority is in the opaque section, which
+	// is left escaped by url.Parse
+	path, err := url.PathUnescape(u.Opaque)
+	if err != nil {
+		return "", err
+	}
+	if path == "" {
+		path = u.Path
+	}
+	if path == "" || path == ":memory:" || u.Query().Get("mode") == "memory" {
+		return "", nil
+	}
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		// Undo the leading slash added to absolute paths like "/c:/tmp/lite"
+		path = path[1:]
+	}
+	return filepath.FromSlash(path), nil
+}
+
+func openSQLite3(connString string, walMode bool, busyTimeout int) (*gorm.DB, error) {
+	embellished, err := embellishSQLite3ConnString(connString, walMode, busyTimeout)
 	if err != nil {
 		return nil, err
 	}
@@ -61,11 +185,12 @@ func openSQLite3(connString string) (*gorm.DB, error) {
 }
 
 // embellishSQLite3ConnString adds query values supported by
-// github.com/mattn/go-sqlite3 to enable journal mode and foreign key support.
-// These query values MUST be part of the connection string in order to be
-// enabled for *each* connection opened by db/sql. If the connection string is
-// not already a file: URI, it is converted first.
-func embellishSQLite3ConnString(connectionString string) (string, error) {
+// github.com/mattn/go-sqlite3 to set the journal mode and busy timeout and to
+// enable foreign key support. The driver issues the corresponding PRAGMAs when
+// it opens a connection, so these query values MUST be part of the connection
+// string in order to be applied to *each* connection opened by db/sql. If the
+// connection string is not already a file: URI, it is converted first.
+func embellishSQLite3ConnString(connectionString string, walMode bool, busyTimeout int) (string, error) {
 	// On Windows, when parsing an absolute path like "c:\tmp\lite",
 	// "c" is parsed as the URL scheme
 	if runtime.GOOS == "windows" && filepath.IsAbs(connectionString) {
@@ -93,7 +218,13 @@ func embellishSQLite3ConnString(connectionString string) (string, error) {
 
 	q := u.Query()
 	q.Set("_foreign_keys", "ON")
-	q.Set("_journal_mode", "WAL")
+	q.Set("_busy_timeout", strconv.Itoa(busyTimeout))
+	if walMode {
+		q.Set("_journal_mode", "WAL")
+	} else {
+		// DELETE is the default journal mode of SQLite
+		q.Set("_journal_mode", "DELETE")
+	}
 	u.RawQuery = q.Encode()
 	return u.String(), nil
 }
@@ -4,6 +4,7 @@ package sqlstore
 
 import (
 	"errors"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/sirupsen/logrus"
@@ -20,3 +21,23 @@ func (s sqliteDB) connect(cfg *configuration, isReadOnly bool) (db *gorm.DB, ver
 func (s sqliteDB) isConstraintViolation(err error) bool {
 	return false
 }
+
+func (s sqliteDB) isTransientError(err error) bool {
+	return false
+}
+
+func (s sqliteDB) isConnectionError(error) bool {
+	return false
+}
+
+func (s sqliteDB) newMigrationLock(*gorm.DB, *configuration) migrationLock {
+	return nil
+}
+
+func (s sqliteDB) setStatementTimeout(*gorm.DB, time.Duration) error {
+	return nil
+}
+
+func (s sqliteDB) isStatementTimeout(error) bool {
+	return false
+}
@@ -1,6 +1,9 @@
 package sqlstore
 
 import (
+	"context"
+	"database/sql"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -15,60 +18,180 @@ func TestEmbellishSQLite3ConnString(t *testing.T) {
 		{
 			name:     "non-URI relative path",
 			in:       "data.db",
-			expected: "file:data.db?_foreign_keys=ON&_journal_mode=WAL",
+			expected: "file:data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL",
 		},
 		{
 			name:     "non-URI relative path with directory component",
 			in:       "./data.db",
-			expected: "file:./data.db?_foreign_keys=ON&_journal_mode=WAL",
+			expected: "file:./data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL",
 		},
 		{
 			name:     "non-URI absolute path",
 			in:       "/home/fred/data.db",
-			expected: "file:/home/fred/data.db?_foreign_keys=ON&_journal_mode=WAL",
+			expected: "file:/home/fred/data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL",
 		},
 		{
 			name:     "URI with no authority and relative",
 			in:       "file:data.db",
-			expected: "file:data.db?_foreign_keys=ON&_journal_mode=WAL",
+			expected: "file:data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL",
 		},
 		{
 			name:     "URI with no authority and absolute path",
 			in:       "file:/home/fred/data.db",
-			expected: "file:/home/fred/data.db?_foreign_keys=ON&_journal_mode=WAL",
+			expected: "file:/home/fred/data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL",
 		},
 		{
 			name:     "URI with empty authority",
 			in:       "file:///home/fred/data.db",
-			expected: "file:///home/fred/data.db?_foreign_keys=ON&_journal_mode=WAL",
+			expected: "file:///home/fred/data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL",
 		},
 		{
 			name:     "URI with localhost authority",
 			in:       "file://localhost/home/fred/data.db",
-			expected: "file://localhost/home/fred/data.db?_foreign_keys=ON&_journal_mode=WAL",
+			expected: "file://localhost/home/fred/data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL",
 		},
 		{
 			name:     "URI with empty authority and windows file path",
 			in:       "file:///C:/Documents%20and%20Settings/fred/Desktop/data.db",
-			expected: "file:///C:/Documents%20and%20Settings/fred/Desktop/data.db?_foreign_keys=ON&_journal_mode=WAL",
+			expected: "file:///C:/Documents%20and%20Settings/fred/Desktop/data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL",
 		},
 		{
 			name:     "URI with no authority, relative path, and query params",
 			in:       "file:data.db?mode=ro",
-			expected: "file:data.db?_foreign_keys=ON&_journal_mode=WAL&mode=ro",
+			expected: "file:data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL&mode=ro",
 		},
 		{
 			name:     "URI with no authority, absolute path, and query params",
 			in:       "file:/home/fred/data.db?vfs=unix-dotfile",
-			expected: "file:/home/fred/data.db?_foreign_keys=ON&_journal_mode=WAL&vfs=unix-dotfile",
+			expected: "file:/home/fred/data.db?_busy_timeout=5000&_foreign_keys=ON&_journal_mode=WAL&vfs=unix-dotfile",
 		},
 	}
 
 	for _, testCase := range testCases {
 		t.Run(testCase.name, func(t *testing.T) {
-			actual, err := embellishSQLite3ConnString(testCase.in)
+			actual, err := embellishSQLite3ConnString(testCase.in, true, defaultSQLiteBusyTimeout)
 			require.NoError(t, err)
 			require.Equal(t, testCase.expected, actual)
 		})
 	}
 }
+
+func TestEmbellishSQLite3ConnStringWithoutWAL(t *testing.T) {
+	actual, err := embellishSQLite3ConnString("data.db", false, 0)
+	require.NoError(t, err)
+	require.Equal(t, "file:data.db?_busy_timeout=0&_foreign_keys=ON&_journal_mode=DELETE", actual)
+}
+
+func TestOpenSQLite3AppliesPragmasToPooledConnections(t *testing.T) {
+	for _, tt := range []struct {
+		name              string
+		walMode           bool
+		busyTimeout       int
+		expectJournalMode string
+	}{
+		{
+			name:              "WAL enabled",
+			walMode:           true,
+			busyTimeout:       defaultSQLiteBusyTimeout,
+			expectJournalMode: "wal",
+		},
+		{
+			name:              "WAL disabled",
+			walMode:           false,
+			busyTimeout:       1234,
+			expectJournalMode: "delete",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dbPath := filepath.ToSlash(filepath.Join(t.TempDir(), "data.db"))
+			db, err := openSQLite3(dbPath, tt.walMode, tt.busyTimeout)
+			require.NoError(t, err)
+			defer db.Close()
+
+			// Hold several connections at once so each one comes from a
+			// different connection in the pool
+			ctx := context.Background()
+			var conns []*sql.Conn
+			for range 3 {
+				conn, err := db.DB().Conn(ctx)
+				require.NoError(t, err)
+				defer conn.Close()
+				conns = append(conns, conn)
+			}
+
+			for _, conn := range conns {
+				var journalMode string
+				require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&journalMode))
+				require.Equal(t, tt.expectJournalMode, journalMode)
+
+				var busyTimeout int
+				require.NoError(t, conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&busyTimeout))
+				require.Equal(t, tt.busyTimeout, busyTimeout)
+			}
+		})
+	}
+}
+
+func TestSQLiteDatabasePath(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		in       string
+		expected string
+	}{
+		{
+			name:     "non-URI relative path",
+			in:       "data.db",
+			expected: "data.db",
+		},
+		{
+			name:     "URI with empty authority",
+			in:       "file:///home/fred/data.db",
+			expected: filepath.FromSlash("/home/fred/data.db"),
+		},
+		{
+			name:     "URI with escaped path",
+			in:       "file:%2Fhome%2Ffred%2Fdata.db",
+			expected: filepath.FromSlash("/home/fred/data.db"),
+		},
+		{
+			name:     "URI with query params",
+			in:       "file:data.db?mode=ro",
+			expected: "data.db",
+		},
+		{
+			name: "in-memory database",
+			in:   "file::memory:?cache=shared",
+		},
+		{
+			name: "shared in-memory database",
+			in:   "file:data?mode=memory&cache=shared",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := sqliteDatabasePath(tt.in)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestSQLiteMigrationLock(t *testing.T) {
+	cfg := &configuration{ConnectionString: filepath.Join(t.TempDir(), "data.db")}
+	lock1 := sqliteDB{}.newMigrationLock(nil, cfg)
+	lock2 := sqliteDB{}.newMigrationLock(nil, cfg)
+
+	acquired, err := lock1.tryLock(context.Background())
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// The lock is held until it is released
+	acquired, err = lock2.tryLock(context.Background())
+	require.NoError(t, err)
+	require.False(t, acquired)
+
+	require.NoError(t, lock1.unlock())
+	acquired, err = lock2.tryLock(context.Background())
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.NoError(t, lock2.unlock())
+}
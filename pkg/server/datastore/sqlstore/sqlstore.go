@@ -3,16 +3,25 @@ package sqlstore
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/x509"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"math"
 	"net/url"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/hashicorp/hcl"
@@ -26,7 +35,9 @@ import (
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/protoutil"
+	"github.com/spiffe/spire/pkg/common/selector"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	ds_telemetry "github.com/spiffe/spire/pkg/common/telemetry/server/datastore"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/private/server/journal"
@@ -47,6 +58,9 @@ var validEntryIDChars = &unicode.RangeTable{
 	LatinOffset: 5,
 }
 
+// dnsLabelRegexp matches a lowercase hostname label
+var dnsLabelRegexp = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
 const (
 	PluginName = "sql"
 
@@ -65,6 +79,58 @@ const (
 
 	// Maximum size for preallocation in a paginated request
 	maxResultPreallocation = 1000
+
+	// Number of registration entries fetched at a time when streaming them
+	streamEntriesPageSize = 500
+
+	// Default number of attested nodes deleted per transaction when pruning
+	defaultPruneBatchSize = 1000
+
+	// Default maximum page size of a paginated list request. Larger or zero
+	// page sizes are clamped to it.
+	defaultMaxPageSize = 1000
+
+	// Number of nodes whose selectors are set per transaction when setting
+	// the selectors of several nodes at once
+	setNodeSelectorsBatchSize = 100
+
+	// Default number of superseded serial numbers kept per attested node
+	defaultNodeSerialHistorySize = 5
+
+	// Default maximum size of the bundle data, which is the size of the
+	// MEDIUMBLOB column it is stored in on MySQL
+	defaultMaxBundleSize = 16777215
+
+	// Default maximum number of DNS names of a registration entry
+	defaultMaxEntryDNSNames = 100
+
+	// Default minimum time events are kept before PruneEvents deletes them
+	defaultEventRetention = 24 * time.Hour
+
+	// Maximum number of parent entries walked by FetchRegistrationEntryWithParents
+	maxEntryParentChainDepth = 32
+
+	// Default number of attempts made to run a retryable transaction
+	defaultTxRetryMaxAttempts = 3
+
+	// Default delay before the first retry of a retryable transaction. The
+	// delay doubles on every subsequent retry.
+	defaultTxRetryBaseDelay = 50 * time.Millisecond
+
+	// Default period at which connection pool statistics are sampled, when
+	// enabled
+	defaultConnectionStatsPeriod = 10 * time.Second
+
+	// Default time, in milliseconds, a SQLite connection waits for a lock to
+	// be released before failing with "database is locked"
+	defaultSQLiteBusyTimeout = 5000
+
+	// Path prefix of the parent ID of join token registration entries
+	joinTokenParentPathPrefix = "/spire/agent/join_token/"
+
+	// Maximum length, in bytes, of the last poll error stored for a
+	// federation relationship. Must match the size of the column.
+	maxPollErrorLength = 1024
 )
 
 // Configuration for the sql datastore implementation.
@@ -76,14 +142,45 @@ type configuration struct {
 	RootCAPath         string   `hcl:"root_ca_path" json:"root_ca_path"`
 	ClientCertPath     string   `hcl:"client_cert_path" json:"client_cert_path"`
 	ClientKeyPath      string   `hcl:"client_key_path" json:"client_key_path"`
+	TLSMode            string   `hcl:"tls_mode" json:"tls_mode"`
 	ConnMaxLifetime    *string  `hcl:"conn_max_lifetime" json:"conn_max_lifetime"`
+	ConnMaxIdleTime    *string  `hcl:"conn_max_idle_time" json:"conn_max_idle_time"`
 	MaxOpenConns       *int     `hcl:"max_open_conns" json:"max_open_conns"`
 	MaxIdleConns       *int     `hcl:"max_idle_conns" json:"max_idle_conns"`
 	DisableMigration   bool     `hcl:"disable_migration" json:"disable_migration"`
+	PruneBatchSize     *int     `hcl:"prune_batch_size" json:"prune_batch_size"`
+	EventRetention     *string  `hcl:"event_retention" json:"event_retention"`
+	MaxPageSize        *int     `hcl:"max_page_size" json:"max_page_size"`
+
+	AllowSchemaVersionMismatch bool    `hcl:"allow_schema_version_mismatch" json:"allow_schema_version_mismatch"`
+	MigrationLockTimeout       *string `hcl:"migration_lock_timeout" json:"migration_lock_timeout"`
+	StatementTimeout           *string `hcl:"statement_timeout" json:"statement_timeout"`
+
+	NodeSerialHistorySize *int    `hcl:"node_serial_history_size" json:"node_serial_history_size"`
+	MaxBundleSize         *int    `hcl:"max_bundle_size" json:"max_bundle_size"`
+	MaxEntryDNSNames      *int    `hcl:"max_dns_names_per_entry" json:"max_dns_names_per_entry"`
+	TxRetryMaxAttempts    *int    `hcl:"tx_retry_max_attempts" json:"tx_retry_max_attempts"`
+	TxRetryBaseDelay      *string `hcl:"tx_retry_base_delay" json:"tx_retry_base_delay"`
+
+	EnableConnectionStats bool    `hcl:"enable_connection_stats" json:"enable_connection_stats"`
+	ConnectionStatsPeriod *string `hcl:"connection_stats_period" json:"connection_stats_period"`
+
+	SQLiteWALMode     *bool `hcl:"sqlite_wal_mode" json:"sqlite_wal_mode"`
+	SQLiteBusyTimeout *int  `hcl:"sqlite_busy_timeout" json:"sqlite_busy_timeout"`
 
-	databaseTypeConfig *dbTypeConfig
+	ExpectedTrustDomain string `hcl:"expected_trust_domain" json:"expected_trust_domain"`
+
+	EncryptionKeyFile string `hcl:"encryption_key_file" json:"encryption_key_file"`
+
+	databaseTypeConfig   *dbTypeConfig
+	migrationLockTimeout time.Duration
+	statementTimeout     time.Duration
+	connMaxLifetime      time.Duration
+	connMaxIdleTime      time.Duration
+	aeadProvider         AEADProvider
 	// Undocumented flags
-	LogSQL bool `hcl:"log_sql" json:"log_sql"`
+	LogSQL         bool `hcl:"log_sql" json:"log_sql"`
+	ExplainQueries bool `hcl:"explain_queries" json:"explain_queries"`
 }
 
 type dbTypeConfig struct {
@@ -115,8 +212,21 @@ type sqlDB struct {
 	stmtCache   *stmtCache
 	supportsCTE bool
 
+	// Time after which the statements of an operation are aborted, unless
+	// the operation is exempt. Zero if there is no timeout.
+	stmtTimeout time.Duration
+
+	// Bounds of the retries of read operations that failed because the
+	// connection to the database was lost. See retryRead.
+	readRetryMaxAttempts int
+	readRetryBaseDelay   time.Duration
+
+	// Provider of the keys the bundle and CA journal data is encrypted with,
+	// or nil if it is stored in plaintext.
+	aeadProvider AEADProvider
+
 	// this lock is only required for synchronized writes with "sqlite3". see
-	// the withTx() implementation for details.
+	// the attemptTx() implementation for details.
 	opMu sync.Mutex
 }
 
@@ -130,25 +240,55 @@ func (db *sqlDB) QueryContext(ctx context.Context, query string, args ...any) (*
 
 // Plugin is a DataStore plugin implemented via a SQL database
 type Plugin struct {
-	mu                  sync.Mutex
-	db                  *sqlDB
-	roDb                *sqlDB
-	log                 logrus.FieldLogger
-	useServerTimestamps bool
+	mu                    sync.Mutex
+	db                    *sqlDB
+	roDb                  *sqlDB
+	log                   logrus.FieldLogger
+	useServerTimestamps   bool
+	pruneBatchSize        int
+	maxPageSize           int32
+	selectorsBatchSize    int
+	nodeSerialHistorySize int
+	maxBundleSize         int
+	maxEntryDNSNames      int
+	eventRetention        time.Duration
+	aeadProvider          AEADProvider
+	observer              DataStoreObserver
+	txRetryMaxAttempts    int
+	txRetryBaseDelay      time.Duration
+	expectedTrustDomain   spiffeid.TrustDomain
+	explainQueries        bool
+
+	metrics             telemetry.Metrics
+	stopConnectionStats func()
 }
 
 // New creates a new sql plugin struct. Configure must be called
 // in order to start the db.
 func New(log logrus.FieldLogger) *Plugin {
 	return &Plugin{
-		log: log,
+		log:                   log,
+		metrics:               telemetry.Blackhole{},
+		pruneBatchSize:        defaultPruneBatchSize,
+		maxPageSize:           defaultMaxPageSize,
+		selectorsBatchSize:    setNodeSelectorsBatchSize,
+		nodeSerialHistorySize: defaultNodeSerialHistorySize,
+		maxBundleSize:         defaultMaxBundleSize,
+		maxEntryDNSNames:      defaultMaxEntryDNSNames,
+		eventRetention:        defaultEventRetention,
+		txRetryMaxAttempts:    defaultTxRetryMaxAttempts,
+		txRetryBaseDelay:      defaultTxRetryBaseDelay,
 	}
 }
 
 // CreateBundle stores the given bundle
 func (ds *Plugin) CreateBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
+	ds.mu.Lock()
+	maxBundleSize := ds.maxBundleSize
+	ds.mu.Unlock()
+
 	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
-		bundle, err = createBundle(tx, b)
+		bundle, err = createBundle(tx, b, maxBundleSize)
 		return err
 	}); err != nil {
 		return nil, err
@@ -159,8 +299,12 @@ func (ds *Plugin) CreateBundle(ctx context.Context, b *common.Bundle) (bundle *c
 // UpdateBundle updates an existing bundle with the given CAs. Overwrites any
 // existing certificates.
 func (ds *Plugin) UpdateBundle(ctx context.Context, b *common.Bundle, mask *common.BundleMask) (bundle *common.Bundle, err error) {
+	ds.mu.Lock()
+	maxBundleSize := ds.maxBundleSize
+	ds.mu.Unlock()
+
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
-		bundle, err = updateBundle(tx, b, mask)
+		bundle, err = updateBundle(tx, b, mask, maxBundleSize)
 		return err
 	}); err != nil {
 		return nil, err
@@ -170,8 +314,12 @@ func (ds *Plugin) UpdateBundle(ctx context.Context, b *common.Bundle, mask *comm
 
 // SetBundle sets bundle contents. If no bundle exists for the trust domain, it is created.
 func (ds *Plugin) SetBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
+	ds.mu.Lock()
+	maxBundleSize := ds.maxBundleSize
+	ds.mu.Unlock()
+
 	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
-		bundle, err = setBundle(tx, b)
+		bundle, err = setBundle(tx, b, maxBundleSize)
 		return err
 	}); err != nil {
 		return nil, err
@@ -179,15 +327,37 @@ func (ds *Plugin) SetBundle(ctx context.Context, b *common.Bundle) (bundle *comm
 	return bundle, nil
 }
 
+// SetBundleRefreshedAt sets when the bundle of the given trust domain was
+// last refreshed from its bundle endpoint, without touching its contents.
+func (ds *Plugin) SetBundleRefreshedAt(ctx context.Context, trustDomainID string, refreshedAt time.Time) error {
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) error {
+		return setBundleRefreshedAt(tx, trustDomainID, refreshedAt)
+	})
+}
+
 // AppendBundle append bundle contents to the existing bundle (by trust domain). If no existing one is present, create it.
 func (ds *Plugin) AppendBundle(ctx context.Context, b *common.Bundle) (bundle *common.Bundle, err error) {
-	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
-		bundle, err = appendBundle(tx, b)
-		return err
-	}); err != nil {
-		return nil, err
+	ds.mu.Lock()
+	maxBundleSize := ds.maxBundleSize
+	ds.mu.Unlock()
+
+	// The bundle row is locked while it is merged, but appends to a bundle
+	// that doesn't exist yet race to create it, and all but one of them fail
+	// on the unique index. The bundle exists by then, so they are run again
+	// to be merged into it.
+	for attempt := 1; ; attempt++ {
+		var created bool
+		err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
+			bundle, created, err = appendBundle(tx, b, maxBundleSize)
+			return err
+		})
+		if err == nil {
+			return bundle, nil
+		}
+		if !created || attempt > 1 || status.Code(err) != codes.AlreadyExists {
+			return nil, err
+		}
 	}
-	return bundle, nil
 }
 
 // DeleteBundle deletes the bundle with the matching TrustDomain. Any CACert data passed is ignored.
@@ -209,6 +379,19 @@ func (ds *Plugin) FetchBundle(ctx context.Context, trustDomainID string) (resp *
 	return resp, nil
 }
 
+// FetchBundleContentHash returns the content hash of the bundle matching the
+// specified Trust Domain, without loading the bundle itself. An empty hash is
+// returned if there is no such bundle.
+func (ds *Plugin) FetchBundleContentHash(ctx context.Context, trustDomainID string) (hash string, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		hash, err = fetchBundleContentHash(tx, trustDomainID)
+		return err
+	}); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
 // CountBundles can be used to count all existing bundles.
 func (ds *Plugin) CountBundles(ctx context.Context) (count int32, err error) {
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
@@ -222,6 +405,11 @@ func (ds *Plugin) CountBundles(ctx context.Context) (count int32, err error) {
 
 // ListBundles can be used to fetch all existing bundles.
 func (ds *Plugin) ListBundles(ctx context.Context, req *datastore.ListBundlesRequest) (resp *datastore.ListBundlesResponse, err error) {
+	if pagination := ds.clampPagination(req.Pagination); pagination != req.Pagination {
+		clamped := *req
+		clamped.Pagination = pagination
+		req = &clamped
+	}
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		resp, err = listBundles(tx, req)
 		return err
@@ -231,6 +419,17 @@ func (ds *Plugin) ListBundles(ctx context.Context, req *datastore.ListBundlesReq
 	return resp, nil
 }
 
+// ListBundleEvents lists all bundle events
+func (ds *Plugin) ListBundleEvents(ctx context.Context, req *datastore.ListBundleEventsRequest) (resp *datastore.ListBundleEventsResponse, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = listBundleEvents(tx, req)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // PruneBundle removes expired certs and keys from a bundle
 func (ds *Plugin) PruneBundle(ctx context.Context, trustDomainID string, expiresBefore time.Time) (changed bool, err error) {
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
@@ -243,6 +442,18 @@ func (ds *Plugin) PruneBundle(ctx context.Context, trustDomainID string, expires
 	return changed, nil
 }
 
+// ListBundleAuthoritiesToPrune returns the authorities that PruneBundle would
+// remove from a bundle, without modifying it
+func (ds *Plugin) ListBundleAuthoritiesToPrune(ctx context.Context, trustDomainID string, expiresBefore time.Time) (resp *datastore.BundleAuthoritiesToPrune, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = listBundleAuthoritiesToPrune(tx, trustDomainID, expiresBefore)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 // TaintX509CAByKey taints an X.509 CA signed using the provided public key
 func (ds *Plugin) TaintX509CA(ctx context.Context, trustDoaminID string, subjectKeyIDToTaint string) error {
 	return ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
@@ -287,11 +498,20 @@ func (ds *Plugin) CreateAttestedNode(ctx context.Context, node *common.AttestedN
 		return nil, newSQLError("invalid request: missing attested node")
 	}
 
+	ds.mu.Lock()
+	expectedTrustDomain := ds.expectedTrustDomain
+	ds.mu.Unlock()
+
+	if err := validateAttestedNodeTrustDomain(node, expectedTrustDomain); err != nil {
+		return nil, ds.gormToGRPCStatus(err)
+	}
+
 	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		attestedNode, err = createAttestedNode(tx, node)
 		if err != nil {
 			return err
 		}
+		recordMutation(tx, MutationCreate, EntityTypeAttestedNode, node.SpiffeId)
 		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
 			SpiffeID: node.SpiffeId,
 		})
@@ -301,7 +521,8 @@ func (ds *Plugin) CreateAttestedNode(ctx context.Context, node *common.AttestedN
 	return attestedNode, nil
 }
 
-// FetchAttestedNode fetches an existing attested node by SPIFFE ID
+// FetchAttestedNode fetches an existing attested node by SPIFFE ID, or by
+// one of its alias SPIFFE IDs
 func (ds *Plugin) FetchAttestedNode(ctx context.Context, spiffeID string) (attestedNode *common.AttestedNode, err error) {
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		attestedNode, err = fetchAttestedNode(tx, spiffeID)
@@ -312,11 +533,67 @@ func (ds *Plugin) FetchAttestedNode(ctx context.Context, spiffeID string) (attes
 	return attestedNode, nil
 }
 
+// AttestedNodeExists reports whether an attested node with the given SPIFFE ID
+// exists, without loading the node or its selectors
+func (ds *Plugin) AttestedNodeExists(ctx context.Context, spiffeID string) (exists bool, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		exists, err = attestedNodeExists(tx, spiffeID)
+		return err
+	}); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// FetchAttestedNodeSerialHistory fetches the serial numbers previously used
+// by the given attested node, most recently superseded first
+func (ds *Plugin) FetchAttestedNodeSerialHistory(ctx context.Context, spiffeID string) (history []*datastore.AttestedNodeSerial, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		history, err = fetchAttestedNodeSerialHistory(tx, spiffeID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// AddAttestedNodeAlias adds an alias SPIFFE ID to the given attested node, so
+// that fetching the node by the alias returns it. The alias cannot be the
+// SPIFFE ID of an attested node, nor an alias of another node.
+func (ds *Plugin) AddAttestedNodeAlias(ctx context.Context, spiffeID, alias string) error {
+	if spiffeID == "" || alias == "" {
+		return ds.gormToGRPCStatus(newValidationError("invalid request: missing SPIFFE ID or alias"))
+	}
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) error {
+		return addAttestedNodeAlias(tx, spiffeID, alias)
+	})
+}
+
+// RemoveAttestedNodeAlias removes the given alias SPIFFE ID
+func (ds *Plugin) RemoveAttestedNodeAlias(ctx context.Context, alias string) error {
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) error {
+		return removeAttestedNodeAlias(tx, alias)
+	})
+}
+
+// ListAttestedNodeAliases lists the alias SPIFFE IDs of the given attested
+// node, sorted
+func (ds *Plugin) ListAttestedNodeAliases(ctx context.Context, spiffeID string) (aliases []string, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		aliases, err = listAttestedNodeAliases(tx, spiffeID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
 // CountAttestedNodes counts all attested nodes
 func (ds *Plugin) CountAttestedNodes(ctx context.Context, req *datastore.CountAttestedNodesRequest) (count int32, err error) {
 	if countAttestedNodesHasFilters(req) {
-		resp, err := countAttestedNodesWithFilters(ctx, ds.db, ds.log, req)
-		return resp, err
+		return withStatementTimeout(ctx, ds.db, func(ctx context.Context) (int32, error) {
+			return countAttestedNodesWithFilters(ctx, ds.db, ds.log, req)
+		})
 	}
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		count, err = countAttestedNodes(tx)
@@ -332,8 +609,15 @@ func (ds *Plugin) CountAttestedNodes(ctx context.Context, req *datastore.CountAt
 func (ds *Plugin) ListAttestedNodes(ctx context.Context,
 	req *datastore.ListAttestedNodesRequest,
 ) (resp *datastore.ListAttestedNodesResponse, err error) {
+	if pagination := ds.clampPagination(req.Pagination); pagination != req.Pagination {
+		clamped := *req
+		clamped.Pagination = pagination
+		req = &clamped
+	}
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
-		resp, err = listAttestedNodes(ctx, ds.db, ds.log, req)
+		resp, err = withStatementTimeout(ctx, ds.db, func(ctx context.Context) (*datastore.ListAttestedNodesResponse, error) {
+			return listAttestedNodes(ctx, ds.db, ds.log, req)
+		})
 		return err
 	}); err != nil {
 		return nil, err
@@ -343,11 +627,16 @@ func (ds *Plugin) ListAttestedNodes(ctx context.Context,
 
 // UpdateAttestedNode updates the given node's cert serial and expiration.
 func (ds *Plugin) UpdateAttestedNode(ctx context.Context, n *common.AttestedNode, mask *common.AttestedNodeMask) (node *common.AttestedNode, err error) {
+	ds.mu.Lock()
+	historySize := ds.nodeSerialHistorySize
+	ds.mu.Unlock()
+
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
-		node, err = updateAttestedNode(tx, n, mask)
+		node, err = updateAttestedNode(tx, n, mask, historySize)
 		if err != nil {
 			return err
 		}
+		recordMutation(tx, MutationUpdate, EntityTypeAttestedNode, n.SpiffeId)
 		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
 			SpiffeID: n.SpiffeId,
 		})
@@ -364,6 +653,7 @@ func (ds *Plugin) DeleteAttestedNode(ctx context.Context, spiffeID string) (atte
 		if err != nil {
 			return err
 		}
+		recordMutation(tx, MutationDelete, EntityTypeAttestedNode, spiffeID)
 		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
 			SpiffeID: spiffeID,
 		})
@@ -373,6 +663,87 @@ func (ds *Plugin) DeleteAttestedNode(ctx context.Context, spiffeID string) (atte
 	return attestedNode, nil
 }
 
+// PromoteAttestedNodeSerial makes the new serial number of the given attested
+// node its current one, along with its expiration, and clears the new serial
+// number. It fails with datastore.ErrAttestedNodeSerialConflict if the new
+// serial number of the node is not the expected one, or if the node changes
+// while it is promoted.
+func (ds *Plugin) PromoteAttestedNodeSerial(ctx context.Context, spiffeID, expectedNewSerial string) (node *common.AttestedNode, err error) {
+	ds.mu.Lock()
+	historySize := ds.nodeSerialHistorySize
+	ds.mu.Unlock()
+
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		node, err = promoteAttestedNodeSerial(tx, spiffeID, expectedNewSerial, historySize)
+		if err != nil {
+			return err
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeAttestedNode, spiffeID)
+		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: spiffeID,
+		})
+	}); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// PruneAttestedNodes deletes all attested nodes, and their associated node
+// selectors, that expired before the given time. Nodes are deleted in batches,
+// each in its own transaction, to avoid holding locks for a long time. It
+// returns the number of nodes removed. On error, nodes removed by previously
+// committed batches are still counted.
+func (ds *Plugin) PruneAttestedNodes(ctx context.Context, expiredBefore time.Time) (pruned int, err error) {
+	ctx = withoutStatementTimeout(ctx)
+
+	ds.mu.Lock()
+	batchSize := ds.pruneBatchSize
+	ds.mu.Unlock()
+
+	for {
+		var n int
+		if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+			n, err = pruneAttestedNodes(tx, expiredBefore, batchSize, ds.log)
+			return err
+		}); err != nil {
+			return pruned, err
+		}
+		pruned += n
+		if n < batchSize {
+			return pruned, nil
+		}
+	}
+}
+
+// ListAttestedNodesToPrune returns the SPIFFE IDs of the attested nodes that
+// PruneAttestedNodes would delete, without deleting them
+func (ds *Plugin) ListAttestedNodesToPrune(ctx context.Context, expiredBefore time.Time) (spiffeIDs []string, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		spiffeIDs, err = listAttestedNodesToPrune(tx, expiredBefore)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return spiffeIDs, nil
+}
+
+// SetAttestedNodesReattest sets the can_reattest flag of the given attested
+// nodes in a single statement. An event is created for every node whose flag
+// changed. It returns the number of affected nodes.
+func (ds *Plugin) SetAttestedNodesReattest(ctx context.Context, spiffeIDs []string, canReattest bool) (affected int, err error) {
+	if len(spiffeIDs) == 0 {
+		return 0, nil
+	}
+
+	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		affected, err = setAttestedNodesReattest(tx, spiffeIDs, canReattest)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return affected, nil
+}
+
 // ListAttestedNodeEvents lists all attested node events
 func (ds *Plugin) ListAttestedNodeEvents(ctx context.Context, req *datastore.ListAttestedNodeEventsRequest) (resp *datastore.ListAttestedNodeEventsResponse, err error) {
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
@@ -386,12 +757,47 @@ func (ds *Plugin) ListAttestedNodeEvents(ctx context.Context, req *datastore.Lis
 
 // PruneAttestedNodeEvents deletes all attested node events older than a specified duration (i.e. more than 24 hours old)
 func (ds *Plugin) PruneAttestedNodeEvents(ctx context.Context, olderThan time.Duration) (err error) {
+	ctx = withoutStatementTimeout(ctx)
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = pruneAttestedNodeEvents(tx, olderThan)
 		return err
 	})
 }
 
+// CompactAttestedNodeEvents deletes every attested node event but the latest
+// one of each node, returning how many were deleted. See
+// CompactRegistrationEntryEvents.
+func (ds *Plugin) CompactAttestedNodeEvents(ctx context.Context) (compacted int, err error) {
+	ctx = withoutStatementTimeout(ctx)
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		compacted, err = compactEvents(tx, "attested_node_entries_events", "spiffe_id")
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return compacted, nil
+}
+
+// PruneEvents deletes all registration entry, attested node and bundle events
+// created before the given time. Events are not tracked per reader, so callers must
+// pick a cutoff conservative enough that every event cache has already
+// consumed the events being removed. Events younger than the configured
+// event retention are kept regardless of the cutoff.
+func (ds *Plugin) PruneEvents(ctx context.Context, olderThan time.Time) (err error) {
+	ds.mu.Lock()
+	eventRetention := ds.eventRetention
+	ds.mu.Unlock()
+
+	if retainedSince := time.Now().Add(-eventRetention); retainedSince.Before(olderThan) {
+		olderThan = retainedSince
+	}
+
+	ctx = withoutStatementTimeout(ctx)
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		return pruneEvents(tx, olderThan)
+	})
+}
+
 // CreateRegistrationEntryEventForTestingForTesting creates an attested node event. Used for unit testing.
 func (ds *Plugin) CreateAttestedNodeEventForTesting(ctx context.Context, event *datastore.AttestedNodeEvent) error {
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) error {
@@ -424,30 +830,165 @@ func (ds *Plugin) SetNodeSelectors(ctx context.Context, spiffeID string, selecto
 		if err = setNodeSelectors(tx, spiffeID, selectors); err != nil {
 			return err
 		}
+		recordMutation(tx, MutationUpdate, EntityTypeNodeSelectors, spiffeID)
 		return createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
 			SpiffeID: spiffeID,
 		})
 	})
 }
 
-// GetNodeSelectors gets node (agent) selectors by SPIFFE ID
+// SetNodeSelectorsBulk sets the selectors of several nodes (agents) by SPIFFE
+// ID, replacing the old selectors of each node and creating an event per
+// node. To bound the size and lock duration of the transactions, the nodes
+// are handled in batches, each in its own transaction, in SPIFFE ID order.
+// If a batch fails, the nodes of the batches committed before it keep their
+// new selectors.
+func (ds *Plugin) SetNodeSelectorsBulk(ctx context.Context, selectorsBySpiffeID map[string][]*common.Selector) (err error) {
+	ds.mu.Lock()
+	batchSize := ds.selectorsBatchSize
+	ds.mu.Unlock()
+
+	// Nodes are handled in a consistent order so concurrent calls lock the
+	// rows in the same order
+	spiffeIDs := slices.Sorted(maps.Keys(selectorsBySpiffeID))
+	for batch := range slices.Chunk(spiffeIDs, batchSize) {
+		if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+			for _, spiffeID := range batch {
+				if err = setNodeSelectors(tx, spiffeID, selectorsBySpiffeID[spiffeID]); err != nil {
+					return err
+				}
+				recordMutation(tx, MutationUpdate, EntityTypeNodeSelectors, spiffeID)
+				if err = createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+					SpiffeID: spiffeID,
+				}); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetNodeSelectors gets node (agent) selectors by SPIFFE ID. If
+// excludeExpired is set, selectors that expired are left out.
 func (ds *Plugin) GetNodeSelectors(ctx context.Context, spiffeID string,
-	dataConsistency datastore.DataConsistency,
+	dataConsistency datastore.DataConsistency, excludeExpired bool,
 ) (selectors []*common.Selector, err error) {
+	db := ds.db
 	if dataConsistency == datastore.TolerateStale && ds.roDb != nil {
-		return getNodeSelectors(ctx, ds.roDb, spiffeID)
+		db = ds.roDb
+	}
+	return withStatementTimeout(ctx, db, func(ctx context.Context) ([]*common.Selector, error) {
+		return getNodeSelectors(ctx, db, spiffeID, excludeExpired)
+	})
+}
+
+// PruneNodeSelectors deletes the node selectors that expired before the given
+// time. An event is created for every node that lost selectors, so caches
+// pick up the change. Like PruneAttestedNodes, selectors are deleted in
+// batches, each in its own transaction, and the number of selectors removed
+// by committed batches is returned.
+func (ds *Plugin) PruneNodeSelectors(ctx context.Context, expiredBefore time.Time) (pruned int, err error) {
+	ctx = withoutStatementTimeout(ctx)
+
+	ds.mu.Lock()
+	batchSize := ds.pruneBatchSize
+	ds.mu.Unlock()
+
+	for {
+		var n int
+		if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+			n, err = pruneNodeSelectors(tx, expiredBefore, batchSize)
+			return err
+		}); err != nil {
+			return pruned, err
+		}
+		pruned += n
+		if n < batchSize {
+			return pruned, nil
+		}
 	}
-	return getNodeSelectors(ctx, ds.db, spiffeID)
 }
 
 // ListNodeSelectors gets node (agent) selectors by SPIFFE ID
 func (ds *Plugin) ListNodeSelectors(ctx context.Context,
 	req *datastore.ListNodeSelectorsRequest,
 ) (resp *datastore.ListNodeSelectorsResponse, err error) {
+	db := ds.db
 	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
-		return listNodeSelectors(ctx, ds.roDb, req)
+		db = ds.roDb
 	}
-	return listNodeSelectors(ctx, ds.db, req)
+	return withStatementTimeout(ctx, db, func(ctx context.Context) (*datastore.ListNodeSelectorsResponse, error) {
+		return listNodeSelectors(ctx, db, req)
+	})
+}
+
+// CreateRegistrationEntries stores the given registration entries within a
+// single transaction, returning a result for each entry in the same order. An
+// entry that cannot be created, for example because its entry ID is already
+// in use, is reported in its result without aborting the creation of the
+// other entries. As with CreateOrReturnRegistrationEntry, a similar entry that
+// already exists is returned instead of creating a new one.
+func (ds *Plugin) CreateRegistrationEntries(ctx context.Context,
+	entries []*common.RegistrationEntry,
+) (results []*datastore.CreateRegistrationEntryResult, err error) {
+	if len(entries) == 0 {
+		return []*datastore.CreateRegistrationEntryResult{}, nil
+	}
+
+	ds.mu.Lock()
+	expectedTrustDomain := ds.expectedTrustDomain
+	maxEntryDNSNames := ds.maxEntryDNSNames
+	ds.mu.Unlock()
+
+	if err = ds.withRetryableWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		results = make([]*datastore.CreateRegistrationEntryResult, 0, len(entries))
+		for _, entry := range entries {
+			result, err := ds.createRegistrationEntryInBatch(ctx, tx, entry, expectedTrustDomain, maxEntryDNSNames)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// createRegistrationEntryInBatch creates the entry within a savepoint, so a
+// failure only rolls back the changes made for that entry. Transient errors
+// are returned so the whole transaction is retried, since databases like
+// MySQL roll back the whole transaction on deadlocks.
+func (ds *Plugin) createRegistrationEntryInBatch(ctx context.Context, tx *gorm.DB, entry *common.RegistrationEntry, expectedTrustDomain spiffeid.TrustDomain, maxEntryDNSNames int) (*datastore.CreateRegistrationEntryResult, error) {
+	if err := tx.Exec("SAVEPOINT create_registration_entry").Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	registrationEntry, existing, err := createOrReturnRegistrationEntry(ctx, ds.db, tx, entry, expectedTrustDomain, maxEntryDNSNames)
+	if err != nil {
+		if ds.db.dialect.isTransientError(err) {
+			return nil, err
+		}
+		if rbErr := tx.Exec("ROLLBACK TO SAVEPOINT create_registration_entry").Error; rbErr != nil {
+			return nil, newWrappedSQLError(rbErr)
+		}
+		return &datastore.CreateRegistrationEntryResult{
+			Err: ds.gormToGRPCStatus(err),
+		}, nil
+	}
+
+	if err := tx.Exec("RELEASE SAVEPOINT create_registration_entry").Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	return &datastore.CreateRegistrationEntryResult{
+		Entry:    registrationEntry,
+		Existing: existing,
+	}, nil
 }
 
 // CreateRegistrationEntry stores the given registration entry
@@ -470,27 +1011,14 @@ func (ds *Plugin) CreateOrReturnRegistrationEntry(ctx context.Context,
 func (ds *Plugin) createOrReturnRegistrationEntry(ctx context.Context,
 	entry *common.RegistrationEntry,
 ) (registrationEntry *common.RegistrationEntry, existing bool, err error) {
-	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
-		if err = validateRegistrationEntry(entry); err != nil {
-			return err
-		}
-
-		registrationEntry, err = lookupSimilarEntry(ctx, ds.db, tx, entry)
-		if err != nil {
-			return err
-		}
-		if registrationEntry != nil {
-			existing = true
-			return nil
-		}
-		registrationEntry, err = createRegistrationEntry(tx, entry)
-		if err != nil {
-			return err
-		}
+	ds.mu.Lock()
+	expectedTrustDomain := ds.expectedTrustDomain
+	maxEntryDNSNames := ds.maxEntryDNSNames
+	ds.mu.Unlock()
 
-		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
-			EntryID: registrationEntry.EntryId,
-		})
+	if err = ds.withRetryableWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		registrationEntry, existing, err = createOrReturnRegistrationEntry(ctx, ds.db, tx, entry, expectedTrustDomain, maxEntryDNSNames)
+		return err
 	}); err != nil {
 		return nil, false, err
 	}
@@ -501,7 +1029,25 @@ func (ds *Plugin) createOrReturnRegistrationEntry(ctx context.Context,
 func (ds *Plugin) FetchRegistrationEntry(ctx context.Context,
 	entryID string,
 ) (*common.RegistrationEntry, error) {
-	return fetchRegistrationEntry(ctx, ds.db, entryID)
+	return withStatementTimeout(ctx, ds.db, func(ctx context.Context) (*common.RegistrationEntry, error) {
+		return fetchRegistrationEntry(ctx, ds.db, entryID)
+	})
+}
+
+// FetchRegistrationEntryWithParents fetches an existing registration by entry
+// ID, along with the chain of entries it is delegated from. The entry comes
+// first, followed by an entry whose SPIFFE ID is its parent ID, and so on until
+// the parent ID is not the SPIFFE ID of any entry, like the ID of a node or of
+// a join token. When several entries have the parent ID as SPIFFE ID, the
+// oldest one is followed. It returns nil if the entry does not exist.
+func (ds *Plugin) FetchRegistrationEntryWithParents(ctx context.Context, entryID string) (chain []*common.RegistrationEntry, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		chain, err = fetchRegistrationEntryWithParents(tx, entryID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return chain, nil
 }
 
 // CountRegistrationEntries counts all registrations (pagination available)
@@ -511,27 +1057,107 @@ func (ds *Plugin) CountRegistrationEntries(ctx context.Context, req *datastore.C
 		actDb = ds.roDb
 	}
 
-	resp, err := countRegistrationEntries(ctx, actDb, ds.log, req)
-	return resp, err
+	return withStatementTimeout(ctx, actDb, func(ctx context.Context) (int32, error) {
+		return countRegistrationEntries(ctx, actDb, ds.log, req)
+	})
+}
+
+// CountRegistrationEntriesByTrustDomain counts registration entries grouped
+// by the trust domain of their SPIFFE ID
+func (ds *Plugin) CountRegistrationEntriesByTrustDomain(ctx context.Context) (counts map[string]int32, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		counts, err = countRegistrationEntriesByTrustDomain(tx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return counts, nil
 }
 
 // ListRegistrationEntries lists all registrations (pagination available)
 func (ds *Plugin) ListRegistrationEntries(ctx context.Context,
 	req *datastore.ListRegistrationEntriesRequest,
 ) (resp *datastore.ListRegistrationEntriesResponse, err error) {
+	if pagination := ds.clampPagination(req.Pagination); pagination != req.Pagination {
+		clamped := *req
+		clamped.Pagination = pagination
+		req = &clamped
+	}
+	db := ds.db
 	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
-		return listRegistrationEntries(ctx, ds.roDb, ds.log, req)
+		db = ds.roDb
 	}
-	return listRegistrationEntries(ctx, ds.db, ds.log, req)
+	return withStatementTimeout(ctx, db, func(ctx context.Context) (*datastore.ListRegistrationEntriesResponse, error) {
+		return listRegistrationEntries(ctx, db, ds.log, req)
+	})
 }
 
-// UpdateRegistrationEntry updates an existing registration entry
+// ExplainListRegistrationEntries returns the plan the database would use to
+// run the query ListRegistrationEntries builds for the request, to diagnose
+// slow listings. The query is not run. It is only available when the
+// explain_queries debug flag is set.
+func (ds *Plugin) ExplainListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (string, error) {
+	ds.mu.Lock()
+	explainQueries := ds.explainQueries
+	ds.mu.Unlock()
+	if !explainQueries {
+		return "", status.Errorf(codes.FailedPrecondition, "%s: explaining queries is disabled", datastoreSQLErrorPrefix)
+	}
+
+	db := ds.db
+	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
+		db = ds.roDb
+	}
+	if err := validateListRegistrationEntriesRequest(req); err != nil {
+		return "", err
+	}
+	return withStatementTimeout(ctx, db, func(ctx context.Context) (string, error) {
+		req, _, err := snapshotListRegistrationEntriesRequest(ctx, db.raw, req)
+		if err != nil {
+			return "", err
+		}
+		query, args, err := buildListRegistrationEntriesQuery(db.databaseType, db.supportsCTE, req)
+		if err != nil {
+			return "", newWrappedSQLError(err)
+		}
+		return explainQuery(ctx, db.raw, db.databaseType, query, args)
+	})
+}
+
+// ListRegistrationEntriesByFederatesWith lists the registration entries that
+// federate with the given trust domain (pagination available)
+func (ds *Plugin) ListRegistrationEntriesByFederatesWith(ctx context.Context, trustDomain string, pagination *datastore.Pagination) (*datastore.ListRegistrationEntriesResponse, error) {
+	td, err := spiffeid.TrustDomainFromString(normalizeTrustDomainID(trustDomain))
+	if err != nil {
+		return nil, newValidationError("invalid trust domain %q: %v", trustDomain, err)
+	}
+
+	return withStatementTimeout(ctx, ds.db, func(ctx context.Context) (*datastore.ListRegistrationEntriesResponse, error) {
+		return listRegistrationEntries(ctx, ds.db, ds.log, &datastore.ListRegistrationEntriesRequest{
+			Pagination: ds.clampPagination(pagination),
+			ByFederatesWith: &datastore.ByFederatesWith{
+				TrustDomains: []string{td.IDString()},
+				Match:        datastore.MatchAny,
+			},
+		})
+	})
+}
+
+// UpdateRegistrationEntry updates an existing registration entry. If a mask
+// is given, only the fields it names are written, and the selectors and DNS
+// names are only replaced, as a set, if named. The revision number of the
+// entry is incremented.
 func (ds *Plugin) UpdateRegistrationEntry(ctx context.Context, e *common.RegistrationEntry, mask *common.RegistrationEntryMask) (entry *common.RegistrationEntry, err error) {
+	ds.mu.Lock()
+	maxEntryDNSNames := ds.maxEntryDNSNames
+	ds.mu.Unlock()
+
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
-		entry, err = updateRegistrationEntry(tx, e, mask)
+		entry, err = updateRegistrationEntry(tx, e, mask, maxEntryDNSNames)
 		if err != nil {
 			return err
 		}
+		recordMutation(tx, MutationUpdate, EntityTypeRegistrationEntry, entry.EntryId)
 
 		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
 			EntryID: entry.EntryId,
@@ -551,6 +1177,7 @@ func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context,
 		if err != nil {
 			return err
 		}
+		recordMutation(tx, MutationDelete, EntityTypeRegistrationEntry, entryID)
 
 		return createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
 			EntryID: entryID,
@@ -561,15 +1188,85 @@ func (ds *Plugin) DeleteRegistrationEntry(ctx context.Context,
 	return registrationEntry, nil
 }
 
+// StreamRegistrationEntries calls fn with each registration entry matching the
+// request, without loading all of them in memory. Pagination and ordering
+// are not supported. Streaming stops at the first error returned by fn, which
+// is returned as is.
+func (ds *Plugin) StreamRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest, fn func(*common.RegistrationEntry) error) error {
+	if req.DataConsistency == datastore.TolerateStale && ds.roDb != nil {
+		return streamRegistrationEntries(ctx, ds.roDb, req, fn)
+	}
+	return streamRegistrationEntries(ctx, ds.db, req, fn)
+}
+
+// SetRegistrationEntryActive activates or deactivates a registration entry.
+// Inactive entries are kept, but are not used to issue SVIDs. An event is
+// created if the entry changed, so caches pick up the change.
+func (ds *Plugin) SetRegistrationEntryActive(ctx context.Context, entryID string, active bool) (registrationEntry *common.RegistrationEntry, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		registrationEntry, err = setRegistrationEntryActive(tx, entryID, active)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return registrationEntry, nil
+}
+
 // PruneRegistrationEntries takes a registration entry message, and deletes all entries which have expired
 // before the date in the message
 func (ds *Plugin) PruneRegistrationEntries(ctx context.Context, expiresBefore time.Time) (err error) {
+	ctx = withoutStatementTimeout(ctx)
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = pruneRegistrationEntries(tx, expiresBefore, ds.log)
 		return err
 	})
 }
 
+// ListRegistrationEntriesExpiringBefore returns the IDs of the registration
+// entries that have an expiry before the given time, soonest first. Entries
+// without an expiry are never returned.
+func (ds *Plugin) ListRegistrationEntriesExpiringBefore(ctx context.Context, expiresBefore time.Time) (entryIDs []string, err error) {
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		entryIDs, err = listRegistrationEntriesExpiringBefore(tx, expiresBefore)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entryIDs, nil
+}
+
+// ListRegistrationEntriesToPrune returns the IDs of the registration entries
+// that PruneRegistrationEntries would delete, without deleting them
+func (ds *Plugin) ListRegistrationEntriesToPrune(ctx context.Context, expiresBefore time.Time) (entryIDs []string, err error) {
+	ctx = withoutStatementTimeout(ctx)
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		entryIDs, err = listRegistrationEntriesToPrune(tx, expiresBefore)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entryIDs, nil
+}
+
+// ListRegistrationEntriesWithExcessDNSNames returns the IDs of the stored
+// registration entries that have more DNS names than the maximum per entry.
+// Such entries are not rejected until their DNS names are updated, so this
+// lets them be found, e.g. after lowering the maximum.
+func (ds *Plugin) ListRegistrationEntriesWithExcessDNSNames(ctx context.Context) (entryIDs []string, err error) {
+	ds.mu.Lock()
+	maxEntryDNSNames := ds.maxEntryDNSNames
+	ds.mu.Unlock()
+
+	ctx = withoutStatementTimeout(ctx)
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		entryIDs, err = listRegistrationEntriesWithExcessDNSNames(tx, maxEntryDNSNames)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return entryIDs, nil
+}
+
 // ListRegistrationEntryEvents lists all registration entry events
 func (ds *Plugin) ListRegistrationEntryEvents(ctx context.Context, req *datastore.ListRegistrationEntryEventsRequest) (resp *datastore.ListRegistrationEntryEventsResponse, err error) {
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
@@ -583,12 +1280,30 @@ func (ds *Plugin) ListRegistrationEntryEvents(ctx context.Context, req *datastor
 
 // PruneRegistrationEntryEvents deletes all registration entry events older than a specified duration (i.e. more than 24 hours old)
 func (ds *Plugin) PruneRegistrationEntryEvents(ctx context.Context, olderThan time.Duration) (err error) {
+	ctx = withoutStatementTimeout(ctx)
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = pruneRegistrationEntryEvents(tx, olderThan)
 		return err
 	})
 }
 
+// CompactRegistrationEntryEvents deletes every registration entry event but
+// the latest one of each entry, returning how many were deleted. Readers only
+// need the latest event of an entry to refresh it, and the highest event ID
+// is always kept, so the last seen event ID of readers stays valid. Readers
+// requiring the complete history may still have to reload everything if the
+// lowest event IDs are deleted.
+func (ds *Plugin) CompactRegistrationEntryEvents(ctx context.Context) (compacted int, err error) {
+	ctx = withoutStatementTimeout(ctx)
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		compacted, err = compactEvents(tx, "registered_entries_events", "entry_id")
+		return err
+	}); err != nil {
+		return 0, err
+	}
+	return compacted, nil
+}
+
 // CreateRegistrationEntryEventForTesting creates a registration entry event. Used for unit testing.
 func (ds *Plugin) CreateRegistrationEntryEventForTesting(ctx context.Context, event *datastore.RegistrationEntryEvent) error {
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
@@ -620,6 +1335,9 @@ func (ds *Plugin) CreateJoinToken(ctx context.Context, token *datastore.JoinToke
 	if token == nil || token.Token == "" || token.Expiry.IsZero() {
 		return errors.New("token and expiry are required")
 	}
+	if token.RemainingUses < 0 {
+		return errors.New("remaining uses cannot be negative")
+	}
 
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = createJoinToken(tx, token)
@@ -640,6 +1358,98 @@ func (ds *Plugin) FetchJoinToken(ctx context.Context, token string) (resp *datas
 	return resp, nil
 }
 
+// FindOrphanedEntryChildren counts the selectors and DNS names whose
+// registration entry no longer exists, and optionally returns their IDs. Rows
+// like these were left behind by older versions of SPIRE.
+func (ds *Plugin) FindOrphanedEntryChildren(ctx context.Context, includeIDs bool) (resp *datastore.OrphanedEntryChildren, err error) {
+	ctx = withoutStatementTimeout(ctx)
+	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = findOrphanedEntryChildren(tx, includeIDs)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PruneOrphanedEntryChildren deletes the selectors and DNS names whose
+// registration entry no longer exists, in batches of the configured prune
+// batch size, and returns how many of each were deleted.
+func (ds *Plugin) PruneOrphanedEntryChildren(ctx context.Context) (*datastore.OrphanedEntryChildren, error) {
+	ctx = withoutStatementTimeout(ctx)
+
+	ds.mu.Lock()
+	batchSize := ds.pruneBatchSize
+	ds.mu.Unlock()
+
+	pruneAll := func(model any) (pruned int, err error) {
+		for {
+			var n int
+			if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+				n, err = pruneOrphanedEntryChildren(tx, model, batchSize)
+				return err
+			}); err != nil {
+				return pruned, err
+			}
+			pruned += n
+			if n < batchSize {
+				return pruned, nil
+			}
+		}
+	}
+
+	resp := new(datastore.OrphanedEntryChildren)
+	var err error
+	if resp.Selectors, err = pruneAll(&Selector{}); err != nil {
+		return nil, err
+	}
+	if resp.DNSNames, err = pruneAll(&DNSName{}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// DedupeEntrySelectors deletes the selectors repeated within a registration
+// entry, keeping the oldest row of each (type, value) pair, in batches of the
+// configured prune batch size. It returns how many selectors were deleted.
+// Rows like these were left behind by older versions of SPIRE.
+func (ds *Plugin) DedupeEntrySelectors(ctx context.Context) (deleted int, err error) {
+	ctx = withoutStatementTimeout(ctx)
+
+	ds.mu.Lock()
+	batchSize := ds.pruneBatchSize
+	ds.mu.Unlock()
+
+	for {
+		var n int
+		if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+			n, err = deleteDuplicateEntrySelectors(tx, batchSize)
+			return err
+		}); err != nil {
+			return deleted, err
+		}
+		deleted += n
+		if n < batchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// ConsumeJoinToken uses the given join token once, deleting it when it has no
+// uses left. It returns the token with the uses it has left, or nil if the
+// token does not exist or has already been used up. Concurrent callers never
+// use a token more times than it allows.
+func (ds *Plugin) ConsumeJoinToken(ctx context.Context, token string) (resp *datastore.JoinToken, err error) {
+	if err = ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
+		resp, err = consumeJoinToken(tx, token)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
 // DeleteJoinToken deletes the given join token
 func (ds *Plugin) DeleteJoinToken(ctx context.Context, token string) (err error) {
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
@@ -651,6 +1461,7 @@ func (ds *Plugin) DeleteJoinToken(ctx context.Context, token string) (err error)
 // PruneJoinTokens takes a Token message, and deletes all tokens which have expired
 // before the date in the message
 func (ds *Plugin) PruneJoinTokens(ctx context.Context, expiry time.Time) (err error) {
+	ctx = withoutStatementTimeout(ctx)
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = pruneJoinTokens(tx, expiry)
 		return err
@@ -667,9 +1478,17 @@ func (ds *Plugin) CreateFederationRelationship(ctx context.Context, fr *datastor
 		return nil, err
 	}
 
+	ds.mu.Lock()
+	maxBundleSize := ds.maxBundleSize
+	ds.mu.Unlock()
+
 	return newFr, ds.withWriteTx(ctx, func(tx *gorm.DB) error {
-		newFr, err = createFederationRelationship(tx, fr)
-		return err
+		newFr, err = createFederationRelationship(tx, fr, maxBundleSize)
+		if err != nil {
+			return err
+		}
+		recordMutation(tx, MutationCreate, EntityTypeFederationRelationship, fr.TrustDomain.IDString())
+		return nil
 	})
 }
 
@@ -682,7 +1501,11 @@ func (ds *Plugin) DeleteFederationRelationship(ctx context.Context, trustDomain
 
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = deleteFederationRelationship(tx, trustDomain)
-		return err
+		if err != nil {
+			return err
+		}
+		recordMutation(tx, MutationDelete, EntityTypeFederationRelationship, trustDomain.IDString())
+		return nil
 	})
 }
 
@@ -705,13 +1528,38 @@ func (ds *Plugin) FetchFederationRelationship(ctx context.Context, trustDomain s
 
 // ListFederationRelationships can be used to list all existing federation relationships
 func (ds *Plugin) ListFederationRelationships(ctx context.Context, req *datastore.ListFederationRelationshipsRequest) (resp *datastore.ListFederationRelationshipsResponse, err error) {
+	if pagination := ds.clampPagination(req.Pagination); pagination != req.Pagination {
+		clamped := *req
+		clamped.Pagination = pagination
+		req = &clamped
+	}
 	if err = ds.withReadTx(ctx, func(tx *gorm.DB) (err error) {
 		resp, err = listFederationRelationships(tx, req)
 		return err
 	}); err != nil {
 		return nil, err
 	}
-	return resp, nil
+	return resp, nil
+}
+
+// clampPagination returns the pagination to use for a list request, with a
+// zero or oversized page size replaced by the configured maximum page size.
+// The effective page size is returned in the response pagination, so callers
+// can detect the clamping. Requests without pagination are not bounded; the
+// server APIs always paginate theirs, with a zero page size when the client
+// asked for none.
+func (ds *Plugin) clampPagination(p *datastore.Pagination) *datastore.Pagination {
+	ds.mu.Lock()
+	maxPageSize := ds.maxPageSize
+	ds.mu.Unlock()
+
+	if p == nil || (p.PageSize > 0 && p.PageSize <= maxPageSize) {
+		return p
+	}
+	return &datastore.Pagination{
+		Token:    p.Token,
+		PageSize: maxPageSize,
+	}
 }
 
 // UpdateFederationRelationship updates the given federation relationship.
@@ -721,9 +1569,32 @@ func (ds *Plugin) UpdateFederationRelationship(ctx context.Context, fr *datastor
 		return nil, err
 	}
 
+	ds.mu.Lock()
+	maxBundleSize := ds.maxBundleSize
+	ds.mu.Unlock()
+
 	return newFr, ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) error {
-		newFr, err = updateFederationRelationship(tx, fr, mask)
-		return err
+		newFr, err = updateFederationRelationship(tx, fr, mask, maxBundleSize)
+		if err != nil {
+			return err
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeFederationRelationship, fr.TrustDomain.IDString())
+		return nil
+	})
+}
+
+// SetFederationRelationshipLastPoll records the time and the outcome of the
+// last poll of the bundle of the given trust domain, along with when it is
+// polled next. An empty pollErr means that the poll succeeded. Long errors
+// are truncated. A zero nextPollAt leaves the next poll time as it was. It
+// is a no-op if there is no federation relationship with the trust domain.
+func (ds *Plugin) SetFederationRelationshipLastPoll(ctx context.Context, trustDomain spiffeid.TrustDomain, polledAt, nextPollAt time.Time, pollErr string) error {
+	if trustDomain.IsZero() {
+		return status.Error(codes.InvalidArgument, "trust domain is required")
+	}
+
+	return ds.withWriteTx(ctx, func(tx *gorm.DB) error {
+		return setFederationRelationshipLastPoll(tx, trustDomain, polledAt, nextPollAt, pollErr)
 	})
 }
 
@@ -773,21 +1644,114 @@ func (ds *Plugin) SetCAJournal(ctx context.Context, caJournal *datastore.CAJourn
 	if err = ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		if caJournal.ID == 0 {
 			caj, err = createCAJournal(tx, caJournal)
-			return err
+			if err != nil {
+				return err
+			}
+			recordMutation(tx, MutationCreate, EntityTypeCAJournal, strconv.FormatUint(uint64(caj.ID), 10))
+			return nil
 		}
 
 		// The CA journal already exists, update it.
 		caj, err = updateCAJournal(tx, caJournal)
-		return err
+		if err != nil {
+			return err
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeCAJournal, strconv.FormatUint(uint64(caj.ID), 10))
+		return nil
 	}); err != nil {
 		return nil, err
 	}
 	return caj, nil
 }
 
+// PruneCAJournal removes from the CA journals the authorities that expired
+// more than the retention period ago. Active and prepared authorities are
+// never removed. Journals are locked while being pruned, so servers sharing
+// the journals do not overwrite each other's changes.
+func (ds *Plugin) PruneCAJournal(ctx context.Context, retention time.Duration) error {
+	if retention < 0 {
+		return status.Error(codes.InvalidArgument, "retention cannot be negative")
+	}
+
+	expiredBefore := time.Now().Add(-retention).Unix()
+	return ds.withReadModifyWriteTx(ctx, func(tx *gorm.DB) error {
+		return ds.pruneCAJournalAuthorities(tx, expiredBefore)
+	})
+}
+
+func (ds *Plugin) pruneCAJournalAuthorities(tx *gorm.DB, expiredBefore int64) error {
+	var caJournals []CAJournal
+	if err := tx.Find(&caJournals).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	for _, model := range caJournals {
+		if err := openCAJournal(tx, &model); err != nil {
+			return err
+		}
+		entries := new(journal.Entries)
+		if err := proto.Unmarshal(model.Data, entries); err != nil {
+			return status.Errorf(codes.Internal, "unable to unmarshal entries from CA journal record: %v", err)
+		}
+
+		x509CAs := slices.DeleteFunc(slices.Clone(entries.X509CAs), func(x509CA *journal.X509CAEntry) bool {
+			return (model.ActiveX509AuthorityID == "" || x509CA.AuthorityId != model.ActiveX509AuthorityID) &&
+				canPruneJournalAuthority(x509CA.Status, x509CA.NotAfter, expiredBefore)
+		})
+		jwtKeys := slices.DeleteFunc(slices.Clone(entries.JwtKeys), func(jwtKey *journal.JWTKeyEntry) bool {
+			return (model.ActiveJWTAuthorityID == "" || jwtKey.AuthorityId != model.ActiveJWTAuthorityID) &&
+				canPruneJournalAuthority(jwtKey.Status, jwtKey.NotAfter, expiredBefore)
+		})
+
+		prunedX509CAs := len(entries.X509CAs) - len(x509CAs)
+		prunedJWTKeys := len(entries.JwtKeys) - len(jwtKeys)
+		if prunedX509CAs == 0 && prunedJWTKeys == 0 {
+			continue
+		}
+
+		entries.X509CAs = x509CAs
+		entries.JwtKeys = jwtKeys
+		data, err := proto.Marshal(entries)
+		if err != nil {
+			return status.Errorf(codes.Internal, "unable to marshal entries for CA journal record: %v", err)
+		}
+		model.Data = data
+		sealed, err := sealCAJournal(tx, &model)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&model).Updates(map[string]any{
+			"data":               sealed.Data,
+			"encryption_key_ref": sealed.EncryptionKeyRef,
+			"encryption_nonce":   sealed.EncryptionNonce,
+		}).Error; err != nil {
+			return newWrappedSQLError(err)
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeCAJournal, strconv.FormatUint(uint64(model.ID), 10))
+		ds.log.WithFields(logrus.Fields{
+			telemetry.CAJournalID: model.ID,
+			telemetry.Count:       prunedX509CAs + prunedJWTKeys,
+		}).Info("Pruned expired authorities from CA journal record")
+	}
+
+	return nil
+}
+
+// canPruneJournalAuthority returns true if the journal authority is neither
+// active nor prepared and expired before the given time.
+func canPruneJournalAuthority(entryStatus journal.Status, notAfter int64, expiredBefore int64) bool {
+	switch entryStatus {
+	case journal.Status_ACTIVE, journal.Status_PREPARED:
+		return false
+	default:
+		return notAfter < expiredBefore
+	}
+}
+
 // PruneCAJournals prunes the CA journals that have all of their authorities
 // expired.
 func (ds *Plugin) PruneCAJournals(ctx context.Context, allAuthoritiesExpireBefore int64) error {
+	ctx = withoutStatementTimeout(ctx)
 	return ds.withWriteTx(ctx, func(tx *gorm.DB) (err error) {
 		err = ds.pruneCAJournals(tx, allAuthoritiesExpireBefore)
 		return err
@@ -802,6 +1766,9 @@ func (ds *Plugin) pruneCAJournals(tx *gorm.DB, allAuthoritiesExpireBefore int64)
 
 checkAuthorities:
 	for _, model := range caJournals {
+		if err := openCAJournal(tx, &model); err != nil {
+			return err
+		}
 		entries := new(journal.Entries)
 		if err := proto.Unmarshal(model.Data, entries); err != nil {
 			return status.Errorf(codes.Internal, "unable to unmarshal entries from CA journal record: %v", err)
@@ -820,6 +1787,7 @@ checkAuthorities:
 		if err := deleteCAJournal(tx, model.ID); err != nil {
 			return status.Errorf(codes.Internal, "failed to delete CA journal: %v", err)
 		}
+		recordMutation(tx, MutationDelete, EntityTypeCAJournal, strconv.FormatUint(uint64(model.ID), 10))
 		ds.log.WithFields(logrus.Fields{
 			telemetry.CAJournalID: model.ID,
 		}).Info("Pruned stale CA journal record")
@@ -847,7 +1815,210 @@ func (ds *Plugin) Configure(_ context.Context, hclConfiguration string) error {
 		return err
 	}
 
-	return ds.openConnections(config)
+	txRetryBaseDelay := defaultTxRetryBaseDelay
+	if config.TxRetryBaseDelay != nil {
+		txRetryBaseDelay, err = time.ParseDuration(*config.TxRetryBaseDelay)
+		if err != nil {
+			return newSQLError("failed to parse tx_retry_base_delay %q: %v", *config.TxRetryBaseDelay, err)
+		}
+		if txRetryBaseDelay < 0 {
+			return newSQLError("tx_retry_base_delay cannot be negative")
+		}
+	}
+
+	eventRetention := defaultEventRetention
+	if config.EventRetention != nil {
+		eventRetention, err = time.ParseDuration(*config.EventRetention)
+		if err != nil {
+			return newSQLError("failed to parse event_retention %q: %v", *config.EventRetention, err)
+		}
+		if eventRetention < 0 {
+			return newSQLError("event_retention cannot be negative")
+		}
+	}
+
+	config.migrationLockTimeout = defaultMigrationLockTimeout
+	if config.MigrationLockTimeout != nil {
+		config.migrationLockTimeout, err = time.ParseDuration(*config.MigrationLockTimeout)
+		if err != nil {
+			return newSQLError("failed to parse migration_lock_timeout %q: %v", *config.MigrationLockTimeout, err)
+		}
+		if config.migrationLockTimeout <= 0 {
+			return newSQLError("migration_lock_timeout must be greater than zero")
+		}
+	}
+
+	if config.StatementTimeout != nil {
+		config.statementTimeout, err = time.ParseDuration(*config.StatementTimeout)
+		if err != nil {
+			return newSQLError("failed to parse statement_timeout %q: %v", *config.StatementTimeout, err)
+		}
+		if config.statementTimeout < 0 {
+			return newSQLError("statement_timeout cannot be negative")
+		}
+	}
+
+	if config.ConnMaxLifetime != nil {
+		config.connMaxLifetime, err = time.ParseDuration(*config.ConnMaxLifetime)
+		if err != nil {
+			return newSQLError("failed to parse conn_max_lifetime %q: %v", *config.ConnMaxLifetime, err)
+		}
+		if config.connMaxLifetime < 0 {
+			return newSQLError("conn_max_lifetime cannot be negative")
+		}
+	}
+
+	if config.ConnMaxIdleTime != nil {
+		config.connMaxIdleTime, err = time.ParseDuration(*config.ConnMaxIdleTime)
+		if err != nil {
+			return newSQLError("failed to parse conn_max_idle_time %q: %v", *config.ConnMaxIdleTime, err)
+		}
+		if config.connMaxIdleTime < 0 {
+			return newSQLError("conn_max_idle_time cannot be negative")
+		}
+	}
+
+	connectionStatsPeriod := defaultConnectionStatsPeriod
+	if config.ConnectionStatsPeriod != nil {
+		connectionStatsPeriod, err = time.ParseDuration(*config.ConnectionStatsPeriod)
+		if err != nil {
+			return newSQLError("failed to parse connection_stats_period %q: %v", *config.ConnectionStatsPeriod, err)
+		}
+		if connectionStatsPeriod <= 0 {
+			return newSQLError("connection_stats_period must be greater than zero")
+		}
+	}
+
+	ds.mu.Lock()
+	config.aeadProvider = ds.aeadProvider
+	ds.mu.Unlock()
+	if config.EncryptionKeyFile != "" {
+		if config.aeadProvider != nil {
+			return newSQLError("encryption_key_file cannot be set when an AEAD provider is used")
+		}
+		config.aeadProvider, err = newKeyFileAEADProvider(config.EncryptionKeyFile)
+		if err != nil {
+			return newSQLError("%v", err)
+		}
+	}
+
+	var expectedTrustDomain spiffeid.TrustDomain
+	if config.ExpectedTrustDomain != "" {
+		expectedTrustDomain, err = spiffeid.TrustDomainFromString(config.ExpectedTrustDomain)
+		if err != nil {
+			return newSQLError("failed to parse expected_trust_domain %q: %v", config.ExpectedTrustDomain, err)
+		}
+	}
+
+	ds.mu.Lock()
+	ds.pruneBatchSize = defaultPruneBatchSize
+	if config.PruneBatchSize != nil {
+		ds.pruneBatchSize = *config.PruneBatchSize
+	}
+	ds.maxPageSize = defaultMaxPageSize
+	if config.MaxPageSize != nil {
+		ds.maxPageSize = int32(*config.MaxPageSize)
+	}
+	ds.nodeSerialHistorySize = defaultNodeSerialHistorySize
+	if config.NodeSerialHistorySize != nil {
+		ds.nodeSerialHistorySize = *config.NodeSerialHistorySize
+	}
+	ds.maxBundleSize = defaultMaxBundleSize
+	if config.MaxBundleSize != nil {
+		ds.maxBundleSize = *config.MaxBundleSize
+	}
+	ds.maxEntryDNSNames = defaultMaxEntryDNSNames
+	if config.MaxEntryDNSNames != nil {
+		ds.maxEntryDNSNames = *config.MaxEntryDNSNames
+	}
+	ds.txRetryMaxAttempts = defaultTxRetryMaxAttempts
+	if config.TxRetryMaxAttempts != nil {
+		ds.txRetryMaxAttempts = *config.TxRetryMaxAttempts
+	}
+	ds.txRetryBaseDelay = txRetryBaseDelay
+	ds.eventRetention = eventRetention
+	ds.expectedTrustDomain = expectedTrustDomain
+	ds.explainQueries = config.ExplainQueries
+	ds.mu.Unlock()
+
+	if err := ds.openConnections(config); err != nil {
+		return err
+	}
+
+	ds.stopConnectionStatsSampling()
+	if config.EnableConnectionStats {
+		ds.startConnectionStatsSampling(connectionStatsPeriod)
+	}
+	return nil
+}
+
+// SetAEADProvider sets the provider of the keys the bundle and CA journal data
+// is encrypted with at rest, instead of the key set by encryption_key_file.
+// It must be called before Configure.
+func (ds *Plugin) SetAEADProvider(provider AEADProvider) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.aeadProvider = provider
+}
+
+// SetMetrics sets the metrics used to report the connection pool statistics
+// of the database, when enabled. It must be called before Configure.
+func (ds *Plugin) SetMetrics(metrics telemetry.Metrics) {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.metrics = metrics
+}
+
+// startConnectionStatsSampling starts emitting the connection pool statistics
+// of the databases every period, until stopConnectionStatsSampling is called.
+func (ds *Plugin) startConnectionStatsSampling(period time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	ds.mu.Lock()
+	ds.stopConnectionStats = func() {
+		cancel()
+		<-done
+	}
+	ds.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				ds.sampleConnectionStats()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (ds *Plugin) stopConnectionStatsSampling() {
+	ds.mu.Lock()
+	stop := ds.stopConnectionStats
+	ds.stopConnectionStats = nil
+	ds.mu.Unlock()
+
+	if stop != nil {
+		stop()
+	}
+}
+
+func (ds *Plugin) sampleConnectionStats() {
+	ds.mu.Lock()
+	db, roDb, metrics := ds.db, ds.roDb, ds.metrics
+	ds.mu.Unlock()
+
+	if db != nil {
+		ds_telemetry.SetConnectionStatsGauges(metrics, db.raw.Stats(), false)
+	}
+	if roDb != nil {
+		ds_telemetry.SetConnectionStatsGauges(metrics, roDb.raw.Stats(), true)
+	}
 }
 
 func (ds *Plugin) openConnections(config *configuration) error {
@@ -911,10 +2082,16 @@ func (ds *Plugin) openConnection(config *configuration, isReadOnly bool) error {
 	}
 
 	sqlDb.LogMode(config.LogSQL)
+	sqlDb.stmtTimeout = config.statementTimeout
+	sqlDb.readRetryMaxAttempts = ds.txRetryMaxAttempts
+	sqlDb.readRetryBaseDelay = ds.txRetryBaseDelay
+	sqlDb.aeadProvider = config.aeadProvider
 	return nil
 }
 
 func (ds *Plugin) Close() error {
+	ds.stopConnectionStatsSampling()
+
 	var errs error
 	if ds.db != nil {
 		errs = errors.Join(errs, ds.db.Close())
@@ -926,13 +2103,23 @@ func (ds *Plugin) Close() error {
 	return errs
 }
 
+// Ping checks the database is reachable, with a ping of the driver. Only the
+// primary database is checked, since the server cannot run without it.
+func (ds *Plugin) Ping(ctx context.Context) error {
+	if err := ds.db.raw.PingContext(ctx); err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
 // withReadModifyWriteTx wraps the operation in a transaction appropriate for
 // operations that will read one or more rows, change one or more columns in
 // those rows, and then set them back. This requires a stronger level of
 // consistency that prevents two transactions from doing read-modify-write
-// concurrently.
+// concurrently. Since the operation reads everything it changes within the
+// transaction, it is retried if the transaction fails with a transient error.
 func (ds *Plugin) withReadModifyWriteTx(ctx context.Context, op func(tx *gorm.DB) error) error {
-	return ds.withTx(ctx, func(tx *gorm.DB) error {
+	return ds.withRetryableTx(ctx, func(tx *gorm.DB) error {
 		switch {
 		case isMySQLDbType(ds.db.databaseType):
 			// MySQL REPEATABLE READ is weaker than that of PostgreSQL. Namely,
@@ -952,7 +2139,7 @@ func (ds *Plugin) withReadModifyWriteTx(ctx context.Context, op func(tx *gorm.DB
 			tx = tx.Set("gorm:query_option", "FOR UPDATE")
 		}
 		return op(tx)
-	}, false)
+	})
 }
 
 // withWriteTx wraps the operation in a transaction appropriate for operations
@@ -962,8 +2149,16 @@ func (ds *Plugin) withWriteTx(ctx context.Context, op func(tx *gorm.DB) error) e
 	return ds.withTx(ctx, op, false)
 }
 
+// withRetryableWriteTx is like withWriteTx, but the transaction is retried if
+// it fails with a transient error. Only operations that are idempotent, and
+// can therefore safely be run again from scratch, should use it.
+func (ds *Plugin) withRetryableWriteTx(ctx context.Context, op func(tx *gorm.DB) error) error {
+	return ds.withRetryableTx(ctx, op)
+}
+
 // withReadTx wraps the operation in a transaction appropriate for operations
-// that only read rows.
+// that only read rows. The transaction is retried if it fails because the
+// connection to the database was lost.
 func (ds *Plugin) withReadTx(ctx context.Context, op func(tx *gorm.DB) error) error {
 	return ds.withTx(ctx, op, true)
 }
@@ -973,6 +2168,55 @@ func (ds *Plugin) withTx(ctx context.Context, op func(tx *gorm.DB) error, readOn
 	db := ds.db
 	ds.mu.Unlock()
 
+	if readOnly {
+		return db.retryRead(ctx, func() (bool, error) {
+			return ds.attemptTx(ctx, db, op, true)
+		})
+	}
+
+	recorder := new(mutationRecorder)
+	if _, err := ds.attemptTx(ctx, db, recorder.wrap(op), false); err != nil {
+		return err
+	}
+	ds.notifyObserver(ctx, recorder.mutations)
+	return nil
+}
+
+// withRetryableTx runs the operation in a write transaction, which is retried
+// with exponential backoff when it fails with an error that the dialect
+// reports as transient, up to the configured maximum number of attempts.
+func (ds *Plugin) withRetryableTx(ctx context.Context, op func(tx *gorm.DB) error) error {
+	ds.mu.Lock()
+	db := ds.db
+	maxAttempts := ds.txRetryMaxAttempts
+	delay := ds.txRetryBaseDelay
+	ds.mu.Unlock()
+
+	recorder := new(mutationRecorder)
+	op = recorder.wrap(op)
+	for attempt := 1; ; attempt++ {
+		transient, err := ds.attemptTx(ctx, db, op, false)
+		if err == nil {
+			ds.notifyObserver(ctx, recorder.mutations)
+			return nil
+		}
+		if !transient || attempt >= maxAttempts {
+			return err
+		}
+
+		ds.log.WithError(err).WithField(telemetry.Attempt, attempt).Debug("Retrying transaction after transient error")
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+		delay *= 2
+	}
+}
+
+// attemptTx runs the operation in a single transaction. Along with the error,
+// it reports whether the transaction can be retried, see isRetryable.
+func (ds *Plugin) attemptTx(ctx context.Context, db *sqlDB, op func(tx *gorm.DB) error, readOnly bool) (bool, error) {
 	if db.databaseType == SQLite && !readOnly {
 		// sqlite3 can only have one writer at a time. since we're in WAL mode,
 		// there can be concurrent reads and writes, so no lock is necessary
@@ -981,23 +2225,45 @@ func (ds *Plugin) withTx(ctx context.Context, op func(tx *gorm.DB) error, readOn
 		defer db.opMu.Unlock()
 	}
 
+	ctx, cancel := db.statementContext(ctx)
+	defer cancel()
+
+	transient, err := ds.runTx(ctx, db, op, readOnly)
+	return transient, db.statementTimeoutError(ctx, err)
+}
+
+// runTx runs the operation in a transaction started with the given context.
+func (ds *Plugin) runTx(ctx context.Context, db *sqlDB, op func(tx *gorm.DB) error, readOnly bool) (bool, error) {
 	tx := db.BeginTx(ctx, nil)
 	if err := tx.Error; err != nil {
-		return newWrappedSQLError(err)
+		return db.isRetryable(err, readOnly), newWrappedSQLError(err)
+	}
+	tx = withAEADProvider(tx, db.aeadProvider)
+
+	if db.stmtTimeout > 0 {
+		// The timeout is set even when the operation is exempt, since it
+		// may otherwise be inherited from a previous transaction.
+		if err := db.dialect.setStatementTimeout(tx, db.statementTimeout(ctx)); err != nil {
+			tx.Rollback()
+			return db.isRetryable(err, readOnly), newWrappedSQLError(err)
+		}
 	}
 
 	if err := op(tx); err != nil {
 		tx.Rollback()
-		return ds.gormToGRPCStatus(err)
+		return db.isRetryable(err, readOnly), ds.gormToGRPCStatus(err)
 	}
 
 	if readOnly {
 		// rolling back makes sure that functions that are invoked with
 		// withReadTx, and then do writes, will not pass unit tests, since the
 		// writes won't be committed.
-		return newWrappedSQLError(tx.Rollback().Error)
+		return false, newWrappedSQLError(tx.Rollback().Error)
 	}
-	return newWrappedSQLError(tx.Commit().Error)
+	if err := tx.Commit().Error; err != nil {
+		return db.isRetryable(err, readOnly), newWrappedSQLError(err)
+	}
+	return false, nil
 }
 
 // gormToGRPCStatus takes an error, and converts it to a GRPC error.  If the
@@ -1058,20 +2324,6 @@ func (ds *Plugin) openDB(cfg *configuration, isReadOnly bool) (*gorm.DB, string,
 	db.SetLogger(gormLogger{
 		log: ds.log.WithField(telemetry.SubsystemName, "gorm"),
 	})
-	db.DB().SetMaxOpenConns(100) // default value
-	if cfg.MaxOpenConns != nil {
-		db.DB().SetMaxOpenConns(*cfg.MaxOpenConns)
-	}
-	if cfg.MaxIdleConns != nil {
-		db.DB().SetMaxIdleConns(*cfg.MaxIdleConns)
-	}
-	if cfg.ConnMaxLifetime != nil {
-		connMaxLifetime, err := time.ParseDuration(*cfg.ConnMaxLifetime)
-		if err != nil {
-			return nil, "", false, nil, fmt.Errorf("failed to parse conn_max_lifetime %q: %w", *cfg.ConnMaxLifetime, err)
-		}
-		db.DB().SetConnMaxLifetime(connMaxLifetime)
-	}
 	if ds.useServerTimestamps {
 		db.SetNowFuncOverride(func() time.Time {
 			// Round to nearest second to be consistent with how timestamps are rounded in CreateRegistrationEntry calls
@@ -1080,12 +2332,29 @@ func (ds *Plugin) openDB(cfg *configuration, isReadOnly bool) (*gorm.DB, string,
 	}
 
 	if !isReadOnly {
-		if err := migrateDB(db, cfg.databaseTypeConfig.databaseType, cfg.DisableMigration, ds.log); err != nil {
+		lock := dialect.newMigrationLock(db, cfg)
+		if err := withMigrationLock(lock, cfg.migrationLockTimeout, ds.log, func() error {
+			return migrateDB(db, cfg.databaseTypeConfig.databaseType, cfg.DisableMigration, cfg.AllowSchemaVersionMismatch, ds.log)
+		}); err != nil {
 			db.Close()
 			return nil, "", false, nil, err
 		}
 	}
 
+	// The connection pool is only limited once the migration is done, since
+	// the migration lock may hold a connection of its own.
+	db.DB().SetMaxOpenConns(100) // default value
+	if cfg.MaxOpenConns != nil {
+		db.DB().SetMaxOpenConns(*cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns != nil {
+		db.DB().SetMaxIdleConns(*cfg.MaxIdleConns)
+	}
+	// Zero durations, the defaults, leave the connections open regardless of
+	// their age or idle time
+	db.DB().SetConnMaxLifetime(cfg.connMaxLifetime)
+	db.DB().SetConnMaxIdleTime(cfg.connMaxIdleTime)
+
 	return db, version, supportsCTE, dialect, nil
 }
 
@@ -1097,20 +2366,37 @@ func (logger gormLogger) Print(v ...any) {
 	logger.log.Debug(gorm.LogFormatter(v...)...)
 }
 
-func createBundle(tx *gorm.DB, bundle *common.Bundle) (*common.Bundle, error) {
+func createBundle(tx *gorm.DB, bundle *common.Bundle, maxBundleSize int) (*common.Bundle, error) {
+	bundle = normalizeBundleTrustDomain(bundle)
 	model, err := bundleToModel(bundle)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkBundleSize(model, maxBundleSize); err != nil {
+		return nil, err
+	}
+	sealed, err := sealBundle(tx, model)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := tx.Create(model).Error; err != nil {
+	if err := tx.Create(sealed).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
+	if err := createBundleEvent(tx, model.TrustDomain); err != nil {
+		return nil, err
+	}
+	if err := createFederatesWithAllEntryEvents(tx); err != nil {
+		return nil, err
+	}
+	recordMutation(tx, MutationCreate, EntityTypeBundle, model.TrustDomain)
+
 	return bundle, nil
 }
 
-func updateBundle(tx *gorm.DB, newBundle *common.Bundle, mask *common.BundleMask) (*common.Bundle, error) {
+func updateBundle(tx *gorm.DB, newBundle *common.Bundle, mask *common.BundleMask, maxBundleSize int) (*common.Bundle, error) {
+	newBundle = normalizeBundleTrustDomain(newBundle)
 	newModel, err := bundleToModel(newBundle)
 	if err != nil {
 		return nil, err
@@ -1120,16 +2406,48 @@ func updateBundle(tx *gorm.DB, newBundle *common.Bundle, mask *common.BundleMask
 	if err := tx.Find(model, "trust_domain = ?", newModel.TrustDomain).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
+	if err := openBundle(tx, model); err != nil {
+		return nil, err
+	}
+
+	oldBundle, err := modelToBundle(model)
+	if err != nil {
+		return nil, err
+	}
 
 	model.Data, newBundle, err = applyBundleMask(model, newBundle, mask)
 	if err != nil {
 		return nil, newWrappedSQLError(err)
 	}
+	if err := checkBundleSize(model, maxBundleSize); err != nil {
+		return nil, err
+	}
+	model.ContentHash = bundleContentHash(model.Data)
+	changed := !proto.Equal(oldBundle, newBundle)
+
+	// Only overwrite the last refreshed timestamp when a new one is
+	// provided, so updates that are not the result of a refresh keep it.
+	if newModel.LastRefreshedAt != nil {
+		model.LastRefreshedAt = newModel.LastRefreshedAt
+		newBundle.LastRefreshedAt = newModel.LastRefreshedAt.Unix()
+	}
 
-	if err := tx.Save(model).Error; err != nil {
+	sealed, err := sealBundle(tx, model)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Save(sealed).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
+	// Refreshes that do not change the bundle contents are not events
+	if changed {
+		if err := createBundleEvent(tx, model.TrustDomain); err != nil {
+			return nil, err
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeBundle, model.TrustDomain)
+	}
+
 	return newBundle, nil
 }
 
@@ -1167,7 +2485,8 @@ func applyBundleMask(model *Bundle, newBundle *common.Bundle, inputMask *common.
 	return newModel.Data, bundle, nil
 }
 
-func setBundle(tx *gorm.DB, b *common.Bundle) (*common.Bundle, error) {
+func setBundle(tx *gorm.DB, b *common.Bundle, maxBundleSize int) (*common.Bundle, error) {
+	b = normalizeBundleTrustDomain(b)
 	newModel, err := bundleToModel(b)
 	if err != nil {
 		return nil, err
@@ -1177,7 +2496,7 @@ func setBundle(tx *gorm.DB, b *common.Bundle) (*common.Bundle, error) {
 	model := &Bundle{}
 	result := tx.Find(model, "trust_domain = ?", newModel.TrustDomain)
 	if result.RecordNotFound() {
-		bundle, err := createBundle(tx, b)
+		bundle, err := createBundle(tx, b, maxBundleSize)
 		if err != nil {
 			return nil, err
 		}
@@ -1186,55 +2505,77 @@ func setBundle(tx *gorm.DB, b *common.Bundle) (*common.Bundle, error) {
 		return nil, newWrappedSQLError(result.Error)
 	}
 
-	bundle, err := updateBundle(tx, b, nil)
+	bundle, err := updateBundle(tx, b, nil, maxBundleSize)
 	if err != nil {
 		return nil, err
 	}
 	return bundle, nil
 }
 
-func appendBundle(tx *gorm.DB, b *common.Bundle) (*common.Bundle, error) {
+// appendBundle merges the bundle into the existing one, or creates it if
+// there is none, in which case it reports that it did so, even on failure.
+func appendBundle(tx *gorm.DB, b *common.Bundle, maxBundleSize int) (*common.Bundle, bool, error) {
+	b = normalizeBundleTrustDomain(b)
 	newModel, err := bundleToModel(b)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	// fetch existing or create new
 	model := &Bundle{}
 	result := tx.Find(model, "trust_domain = ?", newModel.TrustDomain)
 	if result.RecordNotFound() {
-		bundle, err := createBundle(tx, b)
+		bundle, err := createBundle(tx, b, maxBundleSize)
 		if err != nil {
-			return nil, err
+			return nil, true, err
 		}
-		return bundle, nil
+		return bundle, true, nil
 	} else if result.Error != nil {
-		return nil, newWrappedSQLError(result.Error)
+		return nil, false, newWrappedSQLError(result.Error)
 	}
 
 	// parse the bundle data and add missing elements
+	if err := openBundle(tx, model); err != nil {
+		return nil, false, err
+	}
 	bundle, err := modelToBundle(model)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	bundle, changed := bundleutil.MergeBundles(bundle, b)
+	bundle, changed, err := bundleutil.MergeBundles(bundle, b)
+	if err != nil {
+		return nil, false, status.Errorf(codes.FailedPrecondition, "%s: cannot append bundle: %v", datastoreSQLErrorPrefix, err)
+	}
 	if changed {
 		bundle.SequenceNumber++
 		newModel, err := bundleToModel(bundle)
 		if err != nil {
-			return nil, err
+			return nil, false, err
+		}
+		if err := checkBundleSize(newModel, maxBundleSize); err != nil {
+			return nil, false, err
 		}
 		model.Data = newModel.Data
-		if err := tx.Save(model).Error; err != nil {
-			return nil, newWrappedSQLError(err)
+		model.ContentHash = newModel.ContentHash
+		sealed, err := sealBundle(tx, model)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := tx.Save(sealed).Error; err != nil {
+			return nil, false, newWrappedSQLError(err)
 		}
+		if err := createBundleEvent(tx, model.TrustDomain); err != nil {
+			return nil, false, err
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeBundle, model.TrustDomain)
 	}
 
-	return bundle, nil
+	return bundle, false, nil
 }
 
 func deleteBundle(tx *gorm.DB, trustDomainID string, mode datastore.DeleteMode) error {
+	trustDomainID = normalizeTrustDomainID(trustDomainID)
 	model := new(Bundle)
 	if err := tx.Find(model, "trust_domain = ?", trustDomainID).Error; err != nil {
 		return newWrappedSQLError(err)
@@ -1273,11 +2614,17 @@ func deleteBundle(tx *gorm.DB, trustDomainID string, mode datastore.DeleteMode)
 		return newWrappedSQLError(err)
 	}
 
-	return nil
+	if err := createFederatesWithAllEntryEvents(tx); err != nil {
+		return err
+	}
+
+	recordMutation(tx, MutationDelete, EntityTypeBundle, model.TrustDomain)
+	return createBundleEvent(tx, model.TrustDomain)
 }
 
 // fetchBundle returns the bundle matching the specified Trust Domain.
 func fetchBundle(tx *gorm.DB, trustDomainID string) (*common.Bundle, error) {
+	trustDomainID = normalizeTrustDomainID(trustDomainID)
 	model := new(Bundle)
 	err := tx.Find(model, "trust_domain = ?", trustDomainID).Error
 	switch {
@@ -1286,6 +2633,9 @@ func fetchBundle(tx *gorm.DB, trustDomainID string) (*common.Bundle, error) {
 	case err != nil:
 		return nil, newWrappedSQLError(err)
 	}
+	if err := openBundle(tx, model); err != nil {
+		return nil, err
+	}
 
 	bundle, err := modelToBundle(model)
 	if err != nil {
@@ -1295,6 +2645,42 @@ func fetchBundle(tx *gorm.DB, trustDomainID string) (*common.Bundle, error) {
 	return bundle, nil
 }
 
+func fetchBundleContentHash(tx *gorm.DB, trustDomainID string) (string, error) {
+	trustDomainID = normalizeTrustDomainID(trustDomainID)
+	var hashes []string
+	if err := tx.Model(&Bundle{}).Where("trust_domain = ?", trustDomainID).Pluck("content_hash", &hashes).Error; err != nil {
+		return "", newWrappedSQLError(err)
+	}
+	switch {
+	case len(hashes) == 0:
+		return "", nil
+	case hashes[0] != "":
+		return hashes[0], nil
+	}
+
+	// The hash is only missing if the bundle was written by a server that
+	// predates the column, so fall back to hashing the bundle data.
+	model := new(Bundle)
+	if err := tx.Find(model, "trust_domain = ?", trustDomainID).Error; err != nil {
+		return "", newWrappedSQLError(err)
+	}
+	if err := openBundle(tx, model); err != nil {
+		return "", err
+	}
+	return bundleContentHash(model.Data), nil
+}
+
+func setBundleRefreshedAt(tx *gorm.DB, trustDomainID string, refreshedAt time.Time) error {
+	// Only the column is updated: the bundle contents did not change, so
+	// there is nothing to reseal and no event to record.
+	if err := tx.Model(&Bundle{}).
+		Where("trust_domain = ?", normalizeTrustDomainID(trustDomainID)).
+		UpdateColumn("last_refreshed_at", refreshedAt).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
 // countBundles can be used to count existing bundles
 func countBundles(tx *gorm.DB) (int32, error) {
 	tx = tx.Model(&Bundle{})
@@ -1322,6 +2708,10 @@ func listBundles(tx *gorm.DB, req *datastore.ListBundlesRequest) (*datastore.Lis
 		}
 	}
 
+	if req.ExcludeData {
+		tx = tx.Select("id, trust_domain, content_hash, last_refreshed_at")
+	}
+
 	var bundles []Bundle
 	if err := tx.Find(&bundles).Error; err != nil {
 		return nil, newWrappedSQLError(err)
@@ -1340,6 +2730,14 @@ func listBundles(tx *gorm.DB, req *datastore.ListBundlesRequest) (*datastore.Lis
 		Pagination: p,
 	}
 	for _, model := range bundles {
+		if req.ExcludeData {
+			resp.Metadata = append(resp.Metadata, modelToBundleMetadata(&model))
+			continue
+		}
+
+		if err := openBundle(tx, &model); err != nil {
+			return nil, err
+		}
 		bundle, err := modelToBundle(&model)
 		if err != nil {
 			return nil, err
@@ -1372,7 +2770,7 @@ func pruneBundle(tx *gorm.DB, trustDomainID string, expiry time.Time, log logrus
 	// Update only if bundle was modified
 	if changed {
 		newBundle.SequenceNumber = currentBundle.SequenceNumber + 1
-		_, err := updateBundle(tx, newBundle, nil)
+		_, err := updateBundle(tx, newBundle, nil, 0)
 		if err != nil {
 			return false, fmt.Errorf("unable to write new bundle: %w", err)
 		}
@@ -1381,6 +2779,58 @@ func pruneBundle(tx *gorm.DB, trustDomainID string, expiry time.Time, log logrus
 	return changed, nil
 }
 
+func listBundleAuthoritiesToPrune(tx *gorm.DB, trustDomainID string, expiry time.Time) (*datastore.BundleAuthoritiesToPrune, error) {
+	resp := &datastore.BundleAuthoritiesToPrune{}
+
+	currentBundle, err := fetchBundle(tx, trustDomainID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch current bundle: %w", err)
+	}
+
+	if currentBundle == nil {
+		// No bundle to prune
+		return resp, nil
+	}
+
+	// Nothing is pruned, so the logs about pruned authorities are discarded
+	log := logrus.New()
+	log.Out = io.Discard
+	newBundle, changed, err := bundleutil.PruneBundle(currentBundle, expiry, log)
+	if err != nil {
+		return nil, fmt.Errorf("prune failed: %w", err)
+	}
+	if !changed {
+		return resp, nil
+	}
+
+	keptRootCAs := make(map[*common.Certificate]bool, len(newBundle.RootCas))
+	for _, rootCA := range newBundle.RootCas {
+		keptRootCAs[rootCA] = true
+	}
+	for _, rootCA := range currentBundle.RootCas {
+		if keptRootCAs[rootCA] {
+			continue
+		}
+		certs, err := x509.ParseCertificates(rootCA.DerBytes)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to parse rootCA: %v", err)
+		}
+		resp.X509AuthorityIDs = append(resp.X509AuthorityIDs, x509util.SubjectKeyIDToString(certs[0].SubjectKeyId))
+	}
+
+	keptJWTKeys := make(map[*common.PublicKey]bool, len(newBundle.JwtSigningKeys))
+	for _, jwtSigningKey := range newBundle.JwtSigningKeys {
+		keptJWTKeys[jwtSigningKey] = true
+	}
+	for _, jwtSigningKey := range currentBundle.JwtSigningKeys {
+		if !keptJWTKeys[jwtSigningKey] {
+			resp.JWTAuthorityIDs = append(resp.JWTAuthorityIDs, jwtSigningKey.Kid)
+		}
+	}
+
+	return resp, nil
+}
+
 func taintX509CA(tx *gorm.DB, trustDomainID string, subjectKeyIDToTaint string) error {
 	bundle, err := getBundle(tx, trustDomainID)
 	if err != nil {
@@ -1413,7 +2863,7 @@ func taintX509CA(tx *gorm.DB, trustDomainID string, subjectKeyIDToTaint string)
 
 	bundle.SequenceNumber++
 
-	_, err = updateBundle(tx, bundle, nil)
+	_, err = updateBundle(tx, bundle, nil, 0)
 	if err != nil {
 		return err
 	}
@@ -1454,7 +2904,7 @@ func revokeX509CA(tx *gorm.DB, trustDomainID string, subjectKeyIDToRevoke string
 	bundle.RootCas = rootCAs
 	bundle.SequenceNumber++
 
-	if _, err := updateBundle(tx, bundle, nil); err != nil {
+	if _, err := updateBundle(tx, bundle, nil, 0); err != nil {
 		return status.Errorf(codes.Internal, "failed to update bundle: %v", err)
 	}
 
@@ -1492,7 +2942,7 @@ func taintJWTKey(tx *gorm.DB, trustDomainID string, authorityID string) (*common
 	}
 
 	bundle.SequenceNumber++
-	if _, err := updateBundle(tx, bundle, nil); err != nil {
+	if _, err := updateBundle(tx, bundle, nil, 0); err != nil {
 		return nil, err
 	}
 
@@ -1532,7 +2982,7 @@ func revokeJWTKey(tx *gorm.DB, trustDomainID string, authorityID string) (*commo
 	}
 
 	bundle.SequenceNumber++
-	if _, err := updateBundle(tx, bundle, nil); err != nil {
+	if _, err := updateBundle(tx, bundle, nil, 0); err != nil {
 		return nil, err
 	}
 
@@ -1540,10 +2990,14 @@ func revokeJWTKey(tx *gorm.DB, trustDomainID string, authorityID string) (*commo
 }
 
 func getBundle(tx *gorm.DB, trustDomainID string) (*common.Bundle, error) {
+	trustDomainID = normalizeTrustDomainID(trustDomainID)
 	model := &Bundle{}
 	if err := tx.Find(model, "trust_domain = ?", trustDomainID).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
+	if err := openBundle(tx, model); err != nil {
+		return nil, err
+	}
 
 	bundle, err := modelToBundle(model)
 	if err != nil {
@@ -1554,6 +3008,17 @@ func getBundle(tx *gorm.DB, trustDomainID string) (*common.Bundle, error) {
 }
 
 func createAttestedNode(tx *gorm.DB, node *common.AttestedNode) (*common.AttestedNode, error) {
+	isAlias, err := attestedNodeAliasExists(tx, node.SpiffeId)
+	if err != nil {
+		return nil, err
+	}
+	if isAlias {
+		return nil, status.Errorf(codes.AlreadyExists, "%s: SPIFFE ID %q is an alias of another attested node", datastoreSQLErrorPrefix, node.SpiffeId)
+	}
+
+	// The node first attests when it is created, so both attestation times
+	// are set here instead of being taken from the caller
+	attestedAt := time.Now().Truncate(time.Second)
 	model := AttestedNode{
 		SpiffeID:        node.SpiffeId,
 		DataType:        node.AttestationDataType,
@@ -1562,6 +3027,8 @@ func createAttestedNode(tx *gorm.DB, node *common.AttestedNode) (*common.Atteste
 		NewSerialNumber: node.NewCertSerialNumber,
 		NewExpiresAt:    nullableUnixTimeToDBTime(node.NewCertNotAfter),
 		CanReattest:     node.CanReattest,
+		AttestedAt:      attestedAt,
+		LastAttestedAt:  attestedAt,
 	}
 
 	if err := tx.Create(&model).Error; err != nil {
@@ -1575,6 +3042,21 @@ func fetchAttestedNode(tx *gorm.DB, spiffeID string) (*common.AttestedNode, erro
 	var model AttestedNode
 	err := tx.Find(&model, "spiffe_id = ?", spiffeID).Error
 	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return fetchAttestedNodeByAlias(tx, spiffeID)
+	case err != nil:
+		return nil, newWrappedSQLError(err)
+	}
+	return modelToAttestedNode(model), nil
+}
+
+func fetchAttestedNodeByAlias(tx *gorm.DB, alias string) (*common.AttestedNode, error) {
+	var model AttestedNode
+	err := tx.
+		Joins("INNER JOIN attested_node_aliases A ON A.spiffe_id = attested_node_entries.spiffe_id").
+		Where("A.alias_spiffe_id = ?", alias).
+		Find(&model).Error
+	switch {
 	case errors.Is(err, gorm.ErrRecordNotFound):
 		return nil, nil
 	case err != nil:
@@ -1583,6 +3065,22 @@ func fetchAttestedNode(tx *gorm.DB, spiffeID string) (*common.AttestedNode, erro
 	return modelToAttestedNode(model), nil
 }
 
+func attestedNodeExists(tx *gorm.DB, spiffeID string) (bool, error) {
+	// Selecting a constant keeps the lookup on the unique spiffe_id index
+	// without reading the row itself.
+	rows, err := tx.Model(&AttestedNode{}).Select("1").Where("spiffe_id = ?", spiffeID).Limit(1).Rows()
+	if err != nil {
+		return false, newWrappedSQLError(err)
+	}
+	defer rows.Close()
+
+	exists := rows.Next()
+	if err := rows.Err(); err != nil {
+		return false, newWrappedSQLError(err)
+	}
+	return exists, nil
+}
+
 func countAttestedNodes(tx *gorm.DB) (int32, error) {
 	var count int
 	if err := tx.Model(&AttestedNode{}).Count(&count).Error; err != nil {
@@ -1592,11 +3090,17 @@ func countAttestedNodes(tx *gorm.DB) (int32, error) {
 	return util.CheckedCast[int32](count)
 }
 
+// fetchNodeSelectors tells whether the listed nodes are returned with their
+// selectors, which they are unless explicitly disabled.
+func fetchNodeSelectors(req *datastore.ListAttestedNodesRequest) bool {
+	return req.FetchSelectors == nil || *req.FetchSelectors
+}
+
 func countAttestedNodesHasFilters(req *datastore.CountAttestedNodesRequest) bool {
-	if req.ByAttestationType != "" || req.ByBanned != nil || !req.ByExpiresBefore.IsZero() {
+	if req.ByAttestationType != "" || req.ByBanned != nil || !req.ByExpiresBefore.IsZero() || !req.ByExpiresAfter.IsZero() {
 		return true
 	}
-	if req.BySelectorMatch != nil || !req.FetchSelectors || req.ByCanReattest != nil {
+	if req.BySelectorMatch != nil || req.ByCanReattest != nil || !req.ByUpdatedAfter.IsZero() {
 		return true
 	}
 	return false
@@ -1628,7 +3132,7 @@ func listAttestedNodes(ctx context.Context, db *sqlDB, log logrus.FieldLogger, r
 
 		// Now that we've filtered the nodes based on selectors, prune off
 		// selectors from the response if they were not requested.
-		if !req.FetchSelectors {
+		if !fetchNodeSelectors(req) {
 			for _, node := range resp.Nodes {
 				node.Selectors = nil
 			}
@@ -1657,41 +3161,126 @@ func countAttestedNodesWithFilters(ctx context.Context, db *sqlDB, _ logrus.Fiel
 		return -1, status.Error(codes.InvalidArgument, "cannot list by empty selectors set")
 	}
 
-	var val int32
-	listReq := &datastore.ListAttestedNodesRequest{
-		ByAttestationType: req.ByAttestationType,
-		ByBanned:          req.ByBanned,
-		ByExpiresBefore:   req.ByExpiresBefore,
-		BySelectorMatch:   req.BySelectorMatch,
-		FetchSelectors:    req.FetchSelectors,
-		ByCanReattest:     req.ByCanReattest,
-		Pagination: &datastore.Pagination{
-			Token:    "",
-			PageSize: 1000,
-		},
+	query, args, err := buildCountAttestedNodesQuery(db.databaseType, req)
+	if err != nil {
+		return -1, newWrappedSQLError(err)
 	}
-	for {
-		resp, err := listAttestedNodesOnce(ctx, db, listReq)
-		if err != nil {
-			return -1, err
+
+	var count int64
+	if err := db.raw.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return -1, newWrappedSQLError(err)
+	}
+	return util.CheckedCast[int32](count)
+}
+
+// buildCountAttestedNodesQuery builds the query counting the nodes that
+// ListAttestedNodes would list with the same filters, without ordering nor
+// paginating them.
+func buildCountAttestedNodesQuery(dbType string, req *datastore.CountAttestedNodesRequest) (string, []any, error) {
+	builder := new(strings.Builder)
+	var args []any
+
+	builder.WriteString("SELECT COUNT(*) FROM attested_node_entries WHERE true")
+
+	// Filter by expiration. The window is inclusive on the lower bound and
+	// exclusive on the upper bound.
+	if !req.ByExpiresAfter.IsZero() {
+		builder.WriteString(" AND expires_at >= ?")
+		args = append(args, req.ByExpiresAfter)
+	}
+	if !req.ByExpiresBefore.IsZero() {
+		builder.WriteString(" AND expires_at < ?")
+		args = append(args, req.ByExpiresBefore)
+	}
+	if !req.ByUpdatedAfter.IsZero() {
+		builder.WriteString(" AND updated_at >= ?")
+		args = append(args, req.ByUpdatedAfter)
+	}
+	if req.ByAttestationType != "" {
+		builder.WriteString(" AND data_type = ?")
+		args = append(args, req.ByAttestationType)
+	}
+	if req.ByBanned != nil {
+		if *req.ByBanned {
+			builder.WriteString(" AND serial_number = ''")
+		} else {
+			builder.WriteString(" AND serial_number <> ''")
+		}
+	}
+	if req.ByCanReattest != nil {
+		if *req.ByCanReattest {
+			builder.WriteString(" AND can_reattest = true")
+		} else {
+			builder.WriteString(" AND can_reattest = false")
 		}
+	}
 
-		if len(resp.Nodes) == 0 {
-			return val, nil
+	if req.BySelectorMatch != nil && len(req.BySelectorMatch.Selectors) > 0 {
+		// Nodes are matched against the distinct selectors in the request
+		selectors := selector.Dedupe(req.BySelectorMatch.Selectors)
+		conditions := new(strings.Builder)
+		writeSelectorMatchConditions(conditions, "type", "value", len(selectors))
+
+		// The selectors of each node are grouped to count those in the
+		// request, and for exact and subset matching, those that are not.
+		builder.WriteString(" AND spiffe_id IN (SELECT spiffe_id FROM node_resolver_map_entries GROUP BY spiffe_id HAVING ")
+		builder.WriteString("COUNT(CASE WHEN " + conditions.String() + " THEN 1 ELSE NULL END)")
+		switch req.BySelectorMatch.Match {
+		case datastore.Subset, datastore.MatchAny:
+			builder.WriteString(" > 0")
+		case datastore.Exact, datastore.Superset, datastore.MatchAll:
+			builder.WriteString(" = ")
+			builder.WriteString(strconv.Itoa(len(selectors)))
+		default:
+			return "", nil, fmt.Errorf("unhandled match behavior %q", req.BySelectorMatch.Match)
+		}
+		for _, selector := range selectors {
+			args = append(args, selector.Type, selector.Value)
 		}
 
-		if req.BySelectorMatch != nil {
-			switch req.BySelectorMatch.Match {
-			case datastore.Exact, datastore.Subset:
-				resp.Nodes = filterNodesBySelectorSet(resp.Nodes, req.BySelectorMatch.Selectors)
-			default:
+		switch req.BySelectorMatch.Match {
+		case datastore.Exact, datastore.Subset:
+			builder.WriteString(" AND COUNT(CASE WHEN NOT (" + conditions.String() + ") THEN 1 ELSE NULL END) = 0")
+			for _, selector := range selectors {
+				args = append(args, selector.Type, selector.Value)
 			}
 		}
+		builder.WriteString(")")
+	}
+
+	if isPostgresDbType(dbType) {
+		return postgreSQLRebind(builder.String()), args, nil
+	}
+	return builder.String(), args, nil
+}
+
+func setAttestedNodesReattest(tx *gorm.DB, spiffeIDs []string, canReattest bool) (int, error) {
+	var affected []string
+	if err := tx.Model(&AttestedNode{}).
+		Where("spiffe_id IN (?) AND can_reattest <> ?", spiffeIDs, canReattest).
+		Pluck("spiffe_id", &affected).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	if len(affected) == 0 {
+		return 0, nil
+	}
 
-		val += util.MustCast[int32](len(resp.Nodes))
+	if err := tx.Model(&AttestedNode{}).
+		Where("spiffe_id IN (?)", affected).
+		Update("can_reattest", canReattest).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
 
-		listReq.Pagination = resp.Pagination
+	for _, spiffeID := range affected {
+		if err := createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: spiffeID,
+		}); err != nil {
+			return 0, err
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeAttestedNode, spiffeID)
 	}
+
+	return len(affected), nil
 }
 
 func createAttestedNodeEvent(tx *gorm.DB, event *datastore.AttestedNodeEvent) error {
@@ -1716,17 +3305,30 @@ func listAttestedNodeEvents(tx *gorm.DB, req *datastore.ListAttestedNodeEventsRe
 			return nil, newWrappedSQLError(err)
 		}
 
-		if err := tx.Find(&events, query.String(), id).Order("id asc").Error; err != nil {
+		if err := tx.Order("id asc").Find(&events, query.String(), id).Error; err != nil {
 			return nil, newWrappedSQLError(err)
 		}
 	} else {
-		if err := tx.Find(&events).Order("id asc").Error; err != nil {
+		if err := tx.Order("id asc").Find(&events).Error; err != nil {
 			return nil, newWrappedSQLError(err)
 		}
 	}
 
+	firstEventID, lastEventID, err := eventIDRange(tx, &AttestedNodeEvent{})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RequireCompleteHistory && req.GreaterThanEventID != 0 {
+		if err := checkEventHistory(tx, &AttestedNode{}, req.GreaterThanEventID, firstEventID, lastEventID); err != nil {
+			return nil, err
+		}
+	}
+
 	resp := &datastore.ListAttestedNodeEventsResponse{
-		Events: make([]datastore.AttestedNodeEvent, len(events)),
+		FirstEventID: firstEventID,
+		LastEventID:  lastEventID,
+		Events:       make([]datastore.AttestedNodeEvent, len(events)),
 	}
 	for i, event := range events {
 		resp.Events[i].EventID = event.ID
@@ -1744,6 +3346,22 @@ func pruneAttestedNodeEvents(tx *gorm.DB, olderThan time.Duration) error {
 	return nil
 }
 
+func pruneEvents(tx *gorm.DB, olderThan time.Time) error {
+	if err := tx.Where("created_at < ?", olderThan).Delete(&RegisteredEntryEvent{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	if err := tx.Where("created_at < ?", olderThan).Delete(&AttestedNodeEvent{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	if err := tx.Where("created_at < ?", olderThan).Delete(&BundleEvent{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	return nil
+}
+
 func fetchAttestedNodeEvent(db *sqlDB, eventID uint) (*datastore.AttestedNodeEvent, error) {
 	event := AttestedNodeEvent{}
 	if err := db.Find(&event, "id = ?", eventID).Error; err != nil {
@@ -1883,7 +3501,7 @@ func buildListAttestedNodesQueryCTE(req *datastore.ListAttestedNodesRequest, dbT
 	var args []any
 
 	// Selectors will be fetched only when `FetchSelectors` or BySelectorMatch are in request
-	fetchSelectors := req.FetchSelectors || req.BySelectorMatch != nil
+	fetchSelectors := fetchNodeSelectors(req) || req.BySelectorMatch != nil
 
 	// Creates filtered nodes, `true` is added to simplify code, all filters will start with `AND`
 	builder.WriteString("\nWITH filtered_nodes AS (\n")
@@ -1899,12 +3517,23 @@ func buildListAttestedNodesQueryCTE(req *datastore.ListAttestedNodesRequest, dbT
 		args = append(args, token)
 	}
 
-	// Filter by expiration
+	// Filter by expiration. The window is inclusive on the lower bound and
+	// exclusive on the upper bound.
+	if !req.ByExpiresAfter.IsZero() {
+		builder.WriteString("\t\tAND expires_at >= ?\n")
+		args = append(args, req.ByExpiresAfter)
+	}
 	if !req.ByExpiresBefore.IsZero() {
 		builder.WriteString("\t\tAND expires_at < ?\n")
 		args = append(args, req.ByExpiresBefore)
 	}
 
+	// Filter by last update, inclusive
+	if !req.ByUpdatedAfter.IsZero() {
+		builder.WriteString("\t\tAND updated_at >= ?\n")
+		args = append(args, req.ByUpdatedAfter)
+	}
+
 	// Filter by Attestation type
 	if req.ByAttestationType != "" {
 		builder.WriteString("\t\tAND data_type = ?\n")
@@ -1961,7 +3590,9 @@ SELECT
 	expires_at,
 	new_serial_number,
 	new_expires_at,
-	can_reattest,`)
+	can_reattest,
+	attested_at,
+	last_attested_at,`)
 
 	// Add "optional" fields for selectors
 	if fetchSelectors {
@@ -1992,56 +3623,37 @@ SELECT
 
 	// Add filter by selectors
 	if req.BySelectorMatch != nil && len(req.BySelectorMatch.Selectors) > 0 {
+		// Nodes are matched against the distinct selectors in the request
+		selectors := selector.Dedupe(req.BySelectorMatch.Selectors)
+
 		// Select IDs, that will be used to fetch "paged" entrieSelect IDs, that will be used to fetch "paged" entries
 		builder.WriteString("\tSELECT DISTINCT id FROM (\n")
 
-		query := "SELECT id FROM filtered_nodes_and_selectors WHERE selector_type = ? AND selector_value = ?"
-
 		switch req.BySelectorMatch.Match {
 		case datastore.Subset, datastore.MatchAny:
 			// Subset needs a union, so we need to group them and add the group
 			// as a child to the root
-			for i := range req.BySelectorMatch.Selectors {
-				builder.WriteString("\t\t")
-				builder.WriteString(query)
-				if i < (len(req.BySelectorMatch.Selectors) - 1) {
+			for i := range selectors {
+				builder.WriteString("\t\tSELECT id FROM filtered_nodes_and_selectors WHERE selector_type = ? AND selector_value = ?")
+				if i < (len(selectors) - 1) {
 					builder.WriteString("\n\t\tUNION\n")
 				}
 			}
-		case datastore.Exact, datastore.Superset:
-			for i := range req.BySelectorMatch.Selectors {
-				switch {
-				// MySQL does not support INTERSECT, so use INNER JOIN instead
-				case isMySQLDbType(dbType):
-					if len(req.BySelectorMatch.Selectors) > 1 {
-						builder.WriteString("\t\t(")
-					}
-					builder.WriteString(query)
-					if len(req.BySelectorMatch.Selectors) > 1 {
-						fmt.Fprintf(builder, ") c_%d\n", i)
-					}
-					// First subquery does not need USING(ID)
-					if i > 0 {
-						builder.WriteString("\t\tUSING(id)\n")
-					}
-					// Last query does not need INNER JOIN
-					if i < (len(req.BySelectorMatch.Selectors) - 1) {
-						builder.WriteString("\t\tINNER JOIN\n")
-					}
-				default:
-					builder.WriteString("\t\t")
-					builder.WriteString(query)
-					if i < (len(req.BySelectorMatch.Selectors) - 1) {
-						builder.WriteString("\n\t\tINTERSECT\n")
-					}
-				}
-			}
+		case datastore.Exact, datastore.Superset, datastore.MatchAll:
+			// Nodes must have every selector in the request. Group the
+			// matching selectors by node and keep the nodes that have as
+			// many as the request, instead of intersecting a subquery per
+			// selector.
+			builder.WriteString("\t\tSELECT id FROM filtered_nodes_and_selectors WHERE ")
+			writeSelectorMatchConditions(builder, "selector_type", "selector_value", len(selectors))
+			builder.WriteString("\n\t\tGROUP BY id HAVING COUNT(*) = ")
+			builder.WriteString(strconv.Itoa(len(selectors)))
 		default:
 			return "", nil, fmt.Errorf("unhandled match behavior %q", req.BySelectorMatch.Match)
 		}
 
 		// Add all selectors as arguments
-		for _, selector := range req.BySelectorMatch.Selectors {
+		for _, selector := range selectors {
 			args = append(args, selector.Type, selector.Value)
 		}
 
@@ -2057,9 +3669,7 @@ SELECT
 		builder.WriteString(fromQuery)
 	}
 
-	if isPostgresDbType(dbType) ||
-		(req.BySelectorMatch != nil &&
-			(req.BySelectorMatch.Match == datastore.Subset || req.BySelectorMatch.Match == datastore.MatchAny || len(req.BySelectorMatch.Selectors) == 1)) {
+	if isPostgresDbType(dbType) || (req.BySelectorMatch != nil && len(req.BySelectorMatch.Selectors) > 0) {
 		builder.WriteString(" AS result_nodes")
 	}
 
@@ -2082,7 +3692,7 @@ func buildListAttestedNodesQueryMySQL(req *datastore.ListAttestedNodesRequest) (
 	var args []any
 
 	// Selectors will be fetched only when `FetchSelectors` or `BySelectorMatch` are in request
-	fetchSelectors := req.FetchSelectors || req.BySelectorMatch != nil
+	fetchSelectors := fetchNodeSelectors(req) || req.BySelectorMatch != nil
 
 	// Add expected fields
 	builder.WriteString(`
@@ -2094,7 +3704,9 @@ SELECT
 	N.expires_at,
 	N.new_serial_number,
 	N.new_expires_at,
-	N.can_reattest,`)
+	N.can_reattest,
+	N.attested_at,
+	N.last_attested_at,`)
 	// Add "optional" fields for selectors
 	if fetchSelectors {
 		builder.WriteString(`
@@ -2127,12 +3739,23 @@ FROM attested_node_entries N
 			args = append(args, token)
 		}
 
-		// Filter by expiration
+		// Filter by expiration. The window is inclusive on the lower bound
+		// and exclusive on the upper bound.
+		if !req.ByExpiresAfter.IsZero() {
+			builder.WriteString(" AND N.expires_at >= ?")
+			args = append(args, req.ByExpiresAfter)
+		}
 		if !req.ByExpiresBefore.IsZero() {
 			builder.WriteString(" AND N.expires_at < ?")
 			args = append(args, req.ByExpiresBefore)
 		}
 
+		// Filter by last update, inclusive
+		if !req.ByUpdatedAfter.IsZero() {
+			builder.WriteString(" AND N.updated_at >= ?")
+			args = append(args, req.ByUpdatedAfter)
+		}
+
 		// Filter by Attestation type
 		if req.ByAttestationType != "" {
 			builder.WriteString(" AND N.data_type = ?")
@@ -2178,7 +3801,8 @@ FROM attested_node_entries N
 		builder.WriteString(") c_0\n")
 
 		if req.BySelectorMatch != nil && len(req.BySelectorMatch.Selectors) > 0 {
-			query := "SELECT spiffe_id FROM node_resolver_map_entries WHERE type = ? AND value = ?"
+			// Nodes are matched against the distinct selectors in the request
+			selectors := selector.Dedupe(req.BySelectorMatch.Selectors)
 
 			switch req.BySelectorMatch.Match {
 			case datastore.Subset, datastore.MatchAny:
@@ -2187,29 +3811,30 @@ FROM attested_node_entries N
 
 				// subset needs a union, so we need to group them and add the group
 				// as a child to the root.
-				for i := range req.BySelectorMatch.Selectors {
-					builder.WriteString("\t\t\t\t")
-					builder.WriteString(query)
-					if i < (len(req.BySelectorMatch.Selectors) - 1) {
+				for i := range selectors {
+					builder.WriteString("\t\t\t\tSELECT spiffe_id FROM node_resolver_map_entries WHERE type = ? AND value = ?")
+					if i < (len(selectors) - 1) {
 						builder.WriteString("\n\t\t\t\tUNION\n")
 					}
 				}
 
 				builder.WriteString("\t\t\t) s_1) c_2\n")
 				builder.WriteString("\t\t\tUSING(spiffe_id)\n")
-			case datastore.Exact, datastore.Superset:
-				for i := range req.BySelectorMatch.Selectors {
-					builder.WriteString("\t\t\tINNER JOIN\n")
-					builder.WriteString("\t\t\t(")
-					builder.WriteString(query)
-					fmt.Fprintf(builder, ") c_%d\n", i+1)
-					builder.WriteString("\t\t\tUSING(spiffe_id)\n")
-				}
+			case datastore.Exact, datastore.Superset, datastore.MatchAll:
+				// Nodes must have every selector in the request, see
+				// buildListAttestedNodesQueryCTE
+				builder.WriteString("\t\t\tINNER JOIN\n")
+				builder.WriteString("\t\t\t(SELECT spiffe_id FROM node_resolver_map_entries WHERE ")
+				writeSelectorMatchConditions(builder, "type", "value", len(selectors))
+				builder.WriteString(" GROUP BY spiffe_id HAVING COUNT(*) = ")
+				builder.WriteString(strconv.Itoa(len(selectors)))
+				builder.WriteString(") c_1\n")
+				builder.WriteString("\t\t\tUSING(spiffe_id)\n")
 			default:
 				return "", nil, fmt.Errorf("unhandled match behavior %q", req.BySelectorMatch.Match)
 			}
 
-			for _, selector := range req.BySelectorMatch.Selectors {
+			for _, selector := range selectors {
 				args = append(args, selector.Type, selector.Value)
 			}
 		}
@@ -2231,43 +3856,221 @@ FROM attested_node_entries N
 			builder.WriteString(" ORDER BY N.id ASC LIMIT ")
 			builder.WriteString(strconv.FormatInt(int64(req.Pagination.PageSize), 10))
 		}
-		builder.WriteString("\n")
+		builder.WriteString("\n")
+	}
+
+	return builder.String(), args, nil
+}
+
+// writeSelectorMatchConditions writes a condition matching a row against any
+// of count selectors, whose type and value are bound as arguments in order.
+func writeSelectorMatchConditions(builder *strings.Builder, typeColumn, valueColumn string, count int) {
+	for i := range count {
+		if i > 0 {
+			builder.WriteString(" OR ")
+		}
+		fmt.Fprintf(builder, "(%s = ? AND %s = ?)", typeColumn, valueColumn)
+	}
+}
+
+func updateAttestedNode(tx *gorm.DB, n *common.AttestedNode, mask *common.AttestedNodeMask, historySize int) (*common.AttestedNode, error) {
+	var model AttestedNode
+	if err := tx.Find(&model, "spiffe_id = ?", n.SpiffeId).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	if mask == nil {
+		mask = protoutil.AllTrueCommonAgentMask
+	}
+
+	updates := make(map[string]any)
+	if mask.CertNotAfter {
+		updates["expires_at"] = time.Unix(n.CertNotAfter, 0)
+	}
+	if mask.CertSerialNumber {
+		updates["serial_number"] = n.CertSerialNumber
+	}
+	if mask.NewCertNotAfter {
+		updates["new_expires_at"] = nullableUnixTimeToDBTime(n.NewCertNotAfter)
+	}
+	if mask.NewCertSerialNumber {
+		updates["new_serial_number"] = n.NewCertSerialNumber
+	}
+	if mask.CanReattest {
+		updates["can_reattest"] = n.CanReattest
+	}
+	if mask.LastAttestedAt && n.LastAttestedAt != 0 {
+		updates["last_attested_at"] = time.Unix(n.LastAttestedAt, 0)
+	}
+
+	// Keep track of the serial number being superseded, if any. This must
+	// be done before updating the model, which overwrites the serial numbers.
+	var superseded *AttestedNodeSerialHistory
+	if mask.CertSerialNumber && model.SerialNumber != "" && model.SerialNumber != n.CertSerialNumber {
+		superseded = &AttestedNodeSerialHistory{
+			SpiffeID:     model.SpiffeID,
+			SerialNumber: model.SerialNumber,
+		}
+		switch {
+		case n.CertSerialNumber == "":
+			superseded.Reason = datastore.SerialReasonBanned
+		case n.CertSerialNumber == model.NewSerialNumber:
+			superseded.Reason = datastore.SerialReasonRotated
+		default:
+			superseded.Reason = datastore.SerialReasonReplaced
+		}
+	}
+
+	if err := tx.Model(&model).Updates(updates).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	if superseded != nil {
+		if err := appendAttestedNodeSerialHistory(tx, superseded, historySize); err != nil {
+			return nil, err
+		}
+	}
+
+	return modelToAttestedNode(model), nil
+}
+
+func promoteAttestedNodeSerial(tx *gorm.DB, spiffeID, expectedNewSerial string, historySize int) (*common.AttestedNode, error) {
+	var model AttestedNode
+	if err := tx.Find(&model, "spiffe_id = ?", spiffeID).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	if expectedNewSerial == "" || model.NewSerialNumber != expectedNewSerial {
+		return nil, datastore.ErrAttestedNodeSerialConflict
+	}
+
+	// The update is guarded by the serial numbers that were read, so that it
+	// doesn't apply if another server promoted or replaced them in between.
+	previousSerial := model.SerialNumber
+	result := tx.Model(&model).
+		Where("serial_number = ? AND new_serial_number = ?", previousSerial, expectedNewSerial).
+		Updates(map[string]any{
+			"serial_number":     model.NewSerialNumber,
+			"expires_at":        time.Unix(nullableDBTimeToUnixTime(model.NewExpiresAt), 0),
+			"new_serial_number": "",
+			"new_expires_at":    nil,
+		})
+	if result.Error != nil {
+		return nil, newWrappedSQLError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, datastore.ErrAttestedNodeSerialConflict
+	}
+
+	if previousSerial != "" {
+		if err := appendAttestedNodeSerialHistory(tx, &AttestedNodeSerialHistory{
+			SpiffeID:     model.SpiffeID,
+			SerialNumber: previousSerial,
+			Reason:       datastore.SerialReasonRotated,
+		}, historySize); err != nil {
+			return nil, err
+		}
 	}
 
-	return builder.String(), args, nil
+	return modelToAttestedNode(model), nil
 }
 
-func updateAttestedNode(tx *gorm.DB, n *common.AttestedNode, mask *common.AttestedNodeMask) (*common.AttestedNode, error) {
-	var model AttestedNode
-	if err := tx.Find(&model, "spiffe_id = ?", n.SpiffeId).Error; err != nil {
+// appendAttestedNodeSerialHistory records a superseded serial number and
+// prunes the oldest records of the node so at most historySize are kept.
+func appendAttestedNodeSerialHistory(tx *gorm.DB, record *AttestedNodeSerialHistory, historySize int) error {
+	if err := tx.Create(record).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	var ids []uint
+	if err := tx.Model(&AttestedNodeSerialHistory{}).
+		Where("spiffe_id = ?", record.SpiffeID).
+		Order("id DESC").
+		Pluck("id", &ids).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	if len(ids) <= historySize {
+		return nil
+	}
+
+	if err := tx.Where("id IN (?)", ids[historySize:]).Delete(&AttestedNodeSerialHistory{}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+func fetchAttestedNodeSerialHistory(tx *gorm.DB, spiffeID string) ([]*datastore.AttestedNodeSerial, error) {
+	var models []AttestedNodeSerialHistory
+	if err := tx.Where("spiffe_id = ?", spiffeID).Order("id DESC").Find(&models).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
-	if mask == nil {
-		mask = protoutil.AllTrueCommonAgentMask
+	history := make([]*datastore.AttestedNodeSerial, 0, len(models))
+	for _, model := range models {
+		history = append(history, &datastore.AttestedNodeSerial{
+			SerialNumber: model.SerialNumber,
+			SupersededAt: model.CreatedAt,
+			Reason:       model.Reason,
+		})
 	}
+	return history, nil
+}
 
-	updates := make(map[string]any)
-	if mask.CertNotAfter {
-		updates["expires_at"] = time.Unix(n.CertNotAfter, 0)
+func addAttestedNodeAlias(tx *gorm.DB, spiffeID, alias string) error {
+	exists, err := attestedNodeExists(tx, spiffeID)
+	if err != nil {
+		return err
 	}
-	if mask.CertSerialNumber {
-		updates["serial_number"] = n.CertSerialNumber
+	if !exists {
+		return status.Errorf(codes.NotFound, "%s: attested node %q not found", datastoreSQLErrorPrefix, spiffeID)
 	}
-	if mask.NewCertNotAfter {
-		updates["new_expires_at"] = nullableUnixTimeToDBTime(n.NewCertNotAfter)
+
+	// The alias would be shadowed by a node with the same SPIFFE ID
+	aliasIsNode, err := attestedNodeExists(tx, alias)
+	if err != nil {
+		return err
 	}
-	if mask.NewCertSerialNumber {
-		updates["new_serial_number"] = n.NewCertSerialNumber
+	if aliasIsNode {
+		return status.Errorf(codes.AlreadyExists, "%s: alias %q is the SPIFFE ID of an attested node", datastoreSQLErrorPrefix, alias)
 	}
-	if mask.CanReattest {
-		updates["can_reattest"] = n.CanReattest
+
+	// The unique index on the alias rejects aliases of another node
+	if err := tx.Create(&AttestedNodeAlias{
+		AliasSpiffeID: alias,
+		SpiffeID:      spiffeID,
+	}).Error; err != nil {
+		return newWrappedSQLError(err)
 	}
-	if err := tx.Model(&model).Updates(updates).Error; err != nil {
+	return nil
+}
+
+func removeAttestedNodeAlias(tx *gorm.DB, alias string) error {
+	result := tx.Where("alias_spiffe_id = ?", alias).Delete(&AttestedNodeAlias{})
+	if result.Error != nil {
+		return newWrappedSQLError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return status.Errorf(codes.NotFound, "%s: attested node alias %q not found", datastoreSQLErrorPrefix, alias)
+	}
+	return nil
+}
+
+func listAttestedNodeAliases(tx *gorm.DB, spiffeID string) ([]string, error) {
+	var aliases []string
+	if err := tx.Model(&AttestedNodeAlias{}).
+		Where("spiffe_id = ?", spiffeID).
+		Order("alias_spiffe_id").
+		Pluck("alias_spiffe_id", &aliases).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
+	return aliases, nil
+}
 
-	return modelToAttestedNode(model), nil
+func attestedNodeAliasExists(tx *gorm.DB, alias string) (bool, error) {
+	var count int
+	if err := tx.Model(&AttestedNodeAlias{}).Where("alias_spiffe_id = ?", alias).Count(&count).Error; err != nil {
+		return false, newWrappedSQLError(err)
+	}
+	return count > 0, nil
 }
 
 func deleteAttestedNodeAndSelectors(tx *gorm.DB, spiffeID string) (*common.AttestedNode, error) {
@@ -2281,6 +4084,14 @@ func deleteAttestedNodeAndSelectors(tx *gorm.DB, spiffeID string) (*common.Attes
 		return nil, newWrappedSQLError(err)
 	}
 
+	if err := tx.Where("spiffe_id = ?", spiffeID).Delete(&AttestedNodeSerialHistory{}).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	if err := tx.Where("spiffe_id = ?", spiffeID).Delete(&AttestedNodeAlias{}).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
 	if err := tx.Find(&nodeModel, "spiffe_id = ?", spiffeID).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
@@ -2292,6 +4103,75 @@ func deleteAttestedNodeAndSelectors(tx *gorm.DB, spiffeID string) (*common.Attes
 	return modelToAttestedNode(nodeModel), nil
 }
 
+// pruneAttestedNodes deletes up to batchSize attested nodes that expired
+// before the given time, along with their node selectors, and creates an
+// event for each deleted node. It returns the number of nodes deleted.
+func pruneAttestedNodes(tx *gorm.DB, expiredBefore time.Time, batchSize int, logger logrus.FieldLogger) (int, error) {
+	var nodes []AttestedNode
+	if err := expiredAttestedNodes(tx, expiredBefore).
+		Select("id, spiffe_id").
+		Order("id").
+		Limit(batchSize).
+		Find(&nodes).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	if len(nodes) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]uint, 0, len(nodes))
+	spiffeIDs := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		ids = append(ids, node.ID)
+		spiffeIDs = append(spiffeIDs, node.SpiffeID)
+	}
+
+	if err := tx.Where("spiffe_id IN (?)", spiffeIDs).Delete(&NodeSelector{}).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+
+	if err := tx.Where("spiffe_id IN (?)", spiffeIDs).Delete(&AttestedNodeSerialHistory{}).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+
+	if err := tx.Where("spiffe_id IN (?)", spiffeIDs).Delete(&AttestedNodeAlias{}).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+
+	if err := tx.Where("id IN (?)", ids).Delete(&AttestedNode{}).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+
+	for _, spiffeID := range spiffeIDs {
+		if err := createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: spiffeID,
+		}); err != nil {
+			return 0, err
+		}
+		recordMutation(tx, MutationDelete, EntityTypeAttestedNode, spiffeID)
+		logger.WithField(telemetry.SPIFFEID, spiffeID).Info("Pruned an expired attested node")
+	}
+
+	return len(nodes), nil
+}
+
+func listAttestedNodesToPrune(tx *gorm.DB, expiredBefore time.Time) ([]string, error) {
+	var spiffeIDs []string
+	if err := expiredAttestedNodes(tx, expiredBefore).
+		Model(&AttestedNode{}).
+		Order("id").
+		Pluck("spiffe_id", &spiffeIDs).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	return spiffeIDs, nil
+}
+
+// expiredAttestedNodes scopes a query to the attested nodes that are pruned
+// for having expired before the given time
+func expiredAttestedNodes(tx *gorm.DB, expiredBefore time.Time) *gorm.DB {
+	return tx.Where("expires_at < ?", expiredBefore)
+}
+
 func setNodeSelectors(tx *gorm.DB, spiffeID string, selectors []*common.Selector) error {
 	// Previously the deletion of the previous set of node selectors was
 	// implemented via query like DELETE FROM node_resolver_map_entries WHERE
@@ -2320,6 +4200,13 @@ func setNodeSelectors(tx *gorm.DB, spiffeID string, selectors []*common.Selector
 			Type:     selector.Type,
 			Value:    selector.Value,
 		}
+		if selector.Source != "" {
+			model.Source = &selector.Source
+		}
+		if selector.ExpiresAt != 0 {
+			expiresAt := time.Unix(selector.ExpiresAt, 0)
+			model.ExpiresAt = &expiresAt
+		}
 		if err := tx.Create(model).Error; err != nil {
 			return newWrappedSQLError(err)
 		}
@@ -2328,9 +4215,57 @@ func setNodeSelectors(tx *gorm.DB, spiffeID string, selectors []*common.Selector
 	return nil
 }
 
-func getNodeSelectors(ctx context.Context, db *sqlDB, spiffeID string) ([]*common.Selector, error) {
-	query := maybeRebind(db.databaseType, "SELECT type, value FROM node_resolver_map_entries WHERE spiffe_id=? ORDER BY id")
-	rows, err := db.QueryContext(ctx, query, spiffeID)
+func pruneNodeSelectors(tx *gorm.DB, expiredBefore time.Time, batchSize int) (int, error) {
+	var selectors []NodeSelector
+	if err := tx.Select("id, spiffe_id").
+		Where("expires_at < ?", expiredBefore).
+		Order("id").
+		Limit(batchSize).
+		Find(&selectors).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	if len(selectors) == 0 {
+		return 0, nil
+	}
+
+	// As in setNodeSelectors, the rows are deleted by ID to avoid gap locks
+	ids := make([]uint, 0, len(selectors))
+	var spiffeIDs []string
+	seen := make(map[string]bool)
+	for _, selector := range selectors {
+		ids = append(ids, selector.ID)
+		if !seen[selector.SpiffeID] {
+			seen[selector.SpiffeID] = true
+			spiffeIDs = append(spiffeIDs, selector.SpiffeID)
+		}
+	}
+
+	if err := tx.Where("id IN (?)", ids).Delete(&NodeSelector{}).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+
+	for _, spiffeID := range spiffeIDs {
+		if err := createAttestedNodeEvent(tx, &datastore.AttestedNodeEvent{
+			SpiffeID: spiffeID,
+		}); err != nil {
+			return 0, err
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeNodeSelectors, spiffeID)
+	}
+
+	return len(ids), nil
+}
+
+func getNodeSelectors(ctx context.Context, db *sqlDB, spiffeID string, excludeExpired bool) ([]*common.Selector, error) {
+	query := "SELECT type, value, source, expires_at FROM node_resolver_map_entries WHERE spiffe_id=?"
+	args := []any{spiffeID}
+	if excludeExpired {
+		query += " AND (expires_at IS NULL OR expires_at > ?)"
+		args = append(args, time.Now())
+	}
+	query = maybeRebind(db.databaseType, query+" ORDER BY id")
+
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, newWrappedSQLError(err)
 	}
@@ -2339,9 +4274,15 @@ func getNodeSelectors(ctx context.Context, db *sqlDB, spiffeID string) ([]*commo
 	var selectors []*common.Selector
 	for rows.Next() {
 		selector := new(common.Selector)
-		if err := rows.Scan(&selector.Type, &selector.Value); err != nil {
+		var source sql.NullString
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&selector.Type, &selector.Value, &source, &expiresAt); err != nil {
 			return nil, newWrappedSQLError(err)
 		}
+		selector.Source = source.String
+		if expiresAt.Valid {
+			selector.ExpiresAt = expiresAt.Time.Unix()
+		}
 		selectors = append(selectors, selector)
 	}
 
@@ -2423,6 +4364,99 @@ func buildListNodeSelectorsQuery(req *datastore.ListNodeSelectorsRequest) (query
 	return sb.String(), args
 }
 
+func countRegistrationEntriesByTrustDomain(tx *gorm.DB) (map[string]int32, error) {
+	rows, err := tx.Model(&RegisteredEntry{}).Select("trust_domain, COUNT(*)").Group("trust_domain").Rows()
+	if err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int32)
+	for rows.Next() {
+		var trustDomain string
+		var count int32
+		if err := rows.Scan(&trustDomain, &count); err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+		counts[trustDomain] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	return counts, nil
+}
+
+// trustDomainFromSPIFFEID returns the trust domain name of the given SPIFFE
+// ID, or an empty string if it is not a SPIFFE ID.
+func trustDomainFromSPIFFEID(id string) string {
+	rest, ok := strings.CutPrefix(id, "spiffe://")
+	if !ok {
+		return ""
+	}
+	trustDomain, _, _ := strings.Cut(rest, "/")
+	return trustDomain
+}
+
+// createOrReturnRegistrationEntry creates the entry, along with its event,
+// unless a similar entry already exists, in which case that entry is returned.
+func createOrReturnRegistrationEntry(ctx context.Context, db *sqlDB, tx *gorm.DB, entry *common.RegistrationEntry, expectedTrustDomain spiffeid.TrustDomain, maxEntryDNSNames int) (*common.RegistrationEntry, bool, error) {
+	if err := validateRegistrationEntry(entry); err != nil {
+		return nil, false, err
+	}
+
+	if err := validateRegistrationEntryTrustDomain(entry, expectedTrustDomain); err != nil {
+		return nil, false, err
+	}
+
+	dnsNames, err := normalizeDNSNames(entry.DnsNames)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := checkDNSNameCount(dnsNames, maxEntryDNSNames); err != nil {
+		return nil, false, err
+	}
+	if !slices.Equal(dnsNames, entry.DnsNames) {
+		entry = proto.Clone(entry).(*common.RegistrationEntry)
+		entry.DnsNames = dnsNames
+	}
+
+	if selectors := uniqueSelectors(entry.Selectors); len(selectors) != len(entry.Selectors) {
+		entry = proto.Clone(entry).(*common.RegistrationEntry)
+		entry.Selectors = selectors
+	}
+
+	if entry.IdempotencyKey != "" {
+		registrationEntry, err := lookupEntryByIdempotencyKey(tx, entry.IdempotencyKey)
+		if err != nil {
+			return nil, false, err
+		}
+		if registrationEntry != nil {
+			return registrationEntry, true, nil
+		}
+	}
+
+	registrationEntry, err := lookupSimilarEntry(ctx, db, tx, entry)
+	if err != nil {
+		return nil, false, err
+	}
+	if registrationEntry != nil {
+		return registrationEntry, true, nil
+	}
+
+	registrationEntry, err = createRegistrationEntry(tx, entry)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+		EntryID: registrationEntry.EntryId,
+	}); err != nil {
+		return nil, false, err
+	}
+	recordMutation(tx, MutationCreate, EntityTypeRegistrationEntry, registrationEntry.EntryId)
+	return registrationEntry, false, nil
+}
+
 func createRegistrationEntry(tx *gorm.DB, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
 	entryID, err := createOrReturnEntryID(entry)
 	if err != nil {
@@ -2430,29 +4464,48 @@ func createRegistrationEntry(tx *gorm.DB, entry *common.RegistrationEntry) (*com
 	}
 
 	newRegisteredEntry := RegisteredEntry{
-		EntryID:    entryID,
-		SpiffeID:   entry.SpiffeId,
-		ParentID:   entry.ParentId,
-		TTL:        entry.X509SvidTtl,
-		Admin:      entry.Admin,
-		Downstream: entry.Downstream,
-		Expiry:     entry.EntryExpiry,
-		StoreSvid:  entry.StoreSvid,
-		JWTSvidTTL: entry.JwtSvidTtl,
-		Hint:       entry.Hint,
+		EntryID:          entryID,
+		SpiffeID:         entry.SpiffeId,
+		TrustDomain:      trustDomainFromSPIFFEID(entry.SpiffeId),
+		ParentID:         entry.ParentId,
+		TTL:              entry.X509SvidTtl,
+		Admin:            entry.Admin,
+		Downstream:       entry.Downstream,
+		Expiry:           entry.EntryExpiry,
+		StoreSvid:        entry.StoreSvid,
+		JWTSvidTTL:       entry.JwtSvidTtl,
+		Hint:             entry.Hint,
+		CreatedBy:        entry.CreatedBy,
+		FederatesWithAll: entry.FederatesWithAll,
+	}
+	if entry.IdempotencyKey != "" {
+		newRegisteredEntry.IdempotencyKey = &entry.IdempotencyKey
 	}
 
 	if err := tx.Create(&newRegisteredEntry).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
-	federatesWith, err := makeFederatesWith(tx, entry.FederatesWith)
-	if err != nil {
-		return nil, err
+	// GORM leaves blank fields with a default value out of the insert, so
+	// the column default marks every new entry active. Entries created
+	// inactive are deactivated afterwards.
+	if entry.Inactive {
+		if err := tx.Model(&newRegisteredEntry).Update("active", false).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
 	}
 
-	if err := tx.Model(&newRegisteredEntry).Association("FederatesWith").Append(federatesWith).Error; err != nil {
-		return nil, err
+	// Entries that federate with all trust domains have them resolved when
+	// read, so the explicit list is not kept
+	if !entry.FederatesWithAll {
+		federatesWith, err := makeFederatesWith(tx, entry.FederatesWith)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := tx.Model(&newRegisteredEntry).Association("FederatesWith").Append(federatesWith).Error; err != nil {
+			return nil, err
+		}
 	}
 
 	for _, registeredSelector := range entry.Selectors {
@@ -2517,9 +4570,54 @@ func fetchRegistrationEntry(ctx context.Context, db *sqlDB, entryID string) (*co
 		return nil, newWrappedSQLError(err)
 	}
 
+	if entry != nil {
+		if err := resolveFederatesWithAll(ctx, db, []*common.RegistrationEntry{entry}); err != nil {
+			return nil, err
+		}
+	}
+
 	return entry, nil
 }
 
+func fetchRegistrationEntryWithParents(tx *gorm.DB, entryID string) ([]*common.RegistrationEntry, error) {
+	var model RegisteredEntry
+	err := tx.Find(&model, "entry_id = ?", entryID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, newWrappedSQLError(err)
+	}
+
+	var chain []*common.RegistrationEntry
+	seen := make(map[uint]struct{})
+	for {
+		if _, ok := seen[model.ID]; ok {
+			return nil, datastore.ErrEntryParentCycle
+		}
+		if len(chain) > maxEntryParentChainDepth {
+			return nil, datastore.ErrEntryParentChainTooDeep
+		}
+		seen[model.ID] = struct{}{}
+
+		entry, err := modelToEntry(tx, model)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, entry)
+
+		parentID := model.ParentID
+		model = RegisteredEntry{}
+		err = tx.Where("spiffe_id = ?", parentID).Order("id").Limit(1).Find(&model).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return chain, nil
+		case err != nil:
+			return nil, newWrappedSQLError(err)
+		}
+	}
+}
+
 func buildFetchRegistrationEntryQuery(dbType string, supportsCTE bool, entryID string) (string, []any, error) {
 	switch {
 	case isSQLiteDbType(dbType):
@@ -2564,7 +4662,11 @@ SELECT
 	NULL AS dns_name_id,
 	NULL AS dns_name,
 	revision_number,
-	jwt_svid_ttl AS reg_jwt_svid_ttl
+	jwt_svid_ttl AS reg_jwt_svid_ttl,
+	created_by,
+	idempotency_key,
+	active,
+	federates_with_all
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -2572,7 +4674,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -2585,7 +4687,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -2593,7 +4695,7 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -2627,7 +4729,11 @@ SELECT
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
 	revision_number,
-	jwt_svid_ttl AS reg_jwt_svid_ttl
+	jwt_svid_ttl AS reg_jwt_svid_ttl,
+	created_by,
+	idempotency_key,
+	active,
+	federates_with_all
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -2635,7 +4741,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -2648,7 +4754,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -2656,7 +4762,7 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -2687,7 +4793,11 @@ SELECT
 	D.id AS dns_name_id,
 	D.value AS dns_name,
 	E.revision_number,
-	E.jwt_svid_ttl AS reg_jwt_svid_ttl
+	E.jwt_svid_ttl AS reg_jwt_svid_ttl,
+	E.created_by,
+	E.idempotency_key,
+	E.active,
+	E.federates_with_all
 FROM
 	registered_entries E
 LEFT JOIN
@@ -2728,7 +4838,11 @@ SELECT
 	NULL AS dns_name_id,
 	NULL AS dns_name,
 	revision_number,
-	jwt_svid_ttl AS reg_jwt_svid_ttl
+	jwt_svid_ttl AS reg_jwt_svid_ttl,
+	created_by,
+	idempotency_key,
+	active,
+	federates_with_all
 FROM
 	registered_entries
 WHERE id IN (SELECT id FROM listing)
@@ -2736,7 +4850,7 @@ WHERE id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -2749,7 +4863,7 @@ WHERE
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -2757,7 +4871,7 @@ WHERE registered_entry_id IN (SELECT id FROM listing)
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 WHERE registered_entry_id IN (SELECT id FROM listing)
@@ -2767,12 +4881,30 @@ ORDER BY selector_id, dns_name_id
 	return query, []any{entryID}, nil
 }
 
-func listRegistrationEntries(ctx context.Context, db *sqlDB, log logrus.FieldLogger, req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesResponse, error) {
+func validateListRegistrationEntriesRequest(req *datastore.ListRegistrationEntriesRequest) error {
 	if req.Pagination != nil && req.Pagination.PageSize == 0 {
-		return nil, status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
+		return status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
 	}
 	if req.BySelectors != nil && len(req.BySelectors.Selectors) == 0 {
-		return nil, status.Error(codes.InvalidArgument, "cannot list by empty selector set")
+		return status.Error(codes.InvalidArgument, "cannot list by empty selector set")
+	}
+	if req.BySelectorValuePrefix != nil && req.BySelectorValuePrefix.Type == "" {
+		return status.Error(codes.InvalidArgument, "cannot list by selector value prefix without a selector type")
+	}
+	if req.ByRevisionGreaterThan < 0 {
+		return status.Error(codes.InvalidArgument, "cannot list by negative revision number")
+	}
+	switch req.OrderBy {
+	case "", datastore.OrderByExpiryAsc, datastore.OrderByExpiryDesc, datastore.OrderByRevisionAsc:
+	default:
+		return status.Errorf(codes.InvalidArgument, "unsupported order %q", req.OrderBy)
+	}
+	return nil
+}
+
+func listRegistrationEntries(ctx context.Context, db *sqlDB, log logrus.FieldLogger, req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesResponse, error) {
+	if err := validateListRegistrationEntriesRequest(req); err != nil {
+		return nil, err
 	}
 
 	// Exact/subset selector matching requires filtering out all registration
@@ -2814,6 +4946,57 @@ func listRegistrationEntries(ctx context.Context, db *sqlDB, log logrus.FieldLog
 	}
 }
 
+// streamRegistrationEntries calls fn with each registration entry matching the
+// request. Entries are fetched in pages of streamEntriesPageSize, so at most
+// one page is held in memory regardless of the number of entries, and the
+// pages form a consistent snapshot like a paginated listing. The rows of a
+// page are released before fn is called, so fn can use the datastore.
+func streamRegistrationEntries(ctx context.Context, db *sqlDB, req *datastore.ListRegistrationEntriesRequest, fn func(*common.RegistrationEntry) error) error {
+	if req.Pagination != nil {
+		return status.Error(codes.InvalidArgument, "cannot paginate a stream of registration entries")
+	}
+	if req.OrderBy != "" {
+		return status.Error(codes.InvalidArgument, "cannot order a stream of registration entries")
+	}
+	if err := validateListRegistrationEntriesRequest(req); err != nil {
+		return err
+	}
+
+	pageReq := *req
+	pageReq.Pagination = &datastore.Pagination{
+		PageSize: streamEntriesPageSize,
+	}
+	for {
+		// The statement timeout applies to the query of each page, since the
+		// time spent by fn is not spent by the database.
+		resp, err := withStatementTimeout(ctx, db, func(ctx context.Context) (*datastore.ListRegistrationEntriesResponse, error) {
+			return listRegistrationEntriesOnce(ctx, db.raw, db.databaseType, db.supportsCTE, &pageReq)
+		})
+		if err != nil {
+			return err
+		}
+		if len(resp.Entries) == 0 {
+			return nil
+		}
+
+		entries := resp.Entries
+		if req.BySelectors != nil {
+			switch req.BySelectors.Match {
+			case datastore.Exact, datastore.Subset:
+				entries = filterEntriesBySelectorSet(entries, req.BySelectors.Selectors)
+			default:
+			}
+		}
+		for _, entry := range entries {
+			if err := fn(entry); err != nil {
+				return err
+			}
+		}
+
+		pageReq.Pagination = resp.Pagination
+	}
+}
+
 func filterEntriesBySelectorSet(entries []*common.RegistrationEntry, selectors []*common.Selector) []*common.RegistrationEntry {
 	// Nothing to filter
 	if len(entries) == 0 {
@@ -2850,7 +5033,37 @@ type queryContext interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
 }
 
-func listRegistrationEntriesOnce(ctx context.Context, db queryContext, databaseType string, supportsCTE bool, req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesResponse, error) {
+// txBeginner is a queryContext that can begin transactions, i.e. a database
+// as opposed to a transaction.
+type txBeginner interface {
+	queryContext
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// snapshotTxOptions are the options of the transaction paginated listings are
+// read in. The snapshot of the first page must be read from the same state
+// of the database as the page itself, which requires a repeatable read.
+var snapshotTxOptions = &sql.TxOptions{
+	Isolation: sql.LevelRepeatableRead,
+	ReadOnly:  true,
+}
+
+func listRegistrationEntriesOnce(ctx context.Context, db queryContext, databaseType string, supportsCTE bool, req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesResponse, error) {
+	if beginner, ok := db.(txBeginner); ok && req.Pagination != nil {
+		tx, err := beginner.BeginTx(ctx, snapshotTxOptions)
+		if err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+		// The transaction only reads, so it is always rolled back
+		defer func() { _ = tx.Rollback() }()
+		db = tx
+	}
+
+	req, token, err := snapshotListRegistrationEntriesRequest(ctx, db, req)
+	if err != nil {
+		return nil, err
+	}
+
 	query, args, err := buildListRegistrationEntriesQuery(databaseType, supportsCTE, req)
 	if err != nil {
 		return nil, newWrappedSQLError(err)
@@ -2862,7 +5075,9 @@ func listRegistrationEntriesOnce(ctx context.Context, db queryContext, databaseT
 	}
 	defer rows.Close()
 	entries := make([]*common.RegistrationEntry, 0, calculateResultPreallocation(req.Pagination))
-	pushEntry := func(entry *common.RegistrationEntry) {
+	// IDs of the entries, in the same order
+	var entryIDs []uint64
+	pushEntry := func(eid uint64, entry *common.RegistrationEntry) {
 		// Due to previous bugs (i.e. #1191), there can be cruft rows related
 		// to a deleted registration entries that are fetched with the list
 		// query. To avoid hydrating partial entries, append only entries that
@@ -2870,6 +5085,7 @@ func listRegistrationEntriesOnce(ctx context.Context, db queryContext, databaseT
 		// entry id).
 		if entry != nil && entry.EntryId != "" {
 			entries = append(entries, entry)
+			entryIDs = append(entryIDs, eid)
 		}
 	}
 
@@ -2882,8 +5098,8 @@ func listRegistrationEntriesOnce(ctx context.Context, db queryContext, databaseT
 		}
 
 		if entry == nil || lastEID != r.EId {
+			pushEntry(lastEID, entry)
 			lastEID = r.EId
-			pushEntry(entry)
 			entry = new(common.RegistrationEntry)
 		}
 
@@ -2891,12 +5107,22 @@ func listRegistrationEntriesOnce(ctx context.Context, db queryContext, databaseT
 			return nil, err
 		}
 	}
-	pushEntry(entry)
+	pushEntry(lastEID, entry)
 
 	if err := rows.Err(); err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
+	if err := resolveFederatesWithAll(ctx, db, entries); err != nil {
+		return nil, err
+	}
+
+	if req.OrderBy != "" {
+		// The rows are sorted by entry ID so the rows of each entry are
+		// contiguous, the entries are sorted here.
+		sortEntries(entries, entryIDs, req.OrderBy)
+	}
+
 	resp := &datastore.ListRegistrationEntriesResponse{
 		Entries: entries,
 	}
@@ -2906,13 +5132,248 @@ func listRegistrationEntriesOnce(ctx context.Context, db queryContext, databaseT
 			PageSize: req.Pagination.PageSize,
 		}
 		if len(resp.Entries) > 0 {
-			resp.Pagination.Token = strconv.FormatUint(lastEID, 10)
+			token.lastID = lastEID
+			if req.OrderBy != "" {
+				last := len(entries) - 1
+				token.lastID = entryIDs[last]
+				token.lastOrderKey = entryOrderKey(entries[last], req.OrderBy)
+				token.hasOrderKey = true
+			}
+			resp.Pagination.Token = token.String()
 		}
 	}
 
 	return resp, nil
 }
 
+// snapshotListRegistrationEntriesRequest returns the request with its
+// pagination token, if any, parsed and carrying the snapshot the pages are
+// listed from.
+func snapshotListRegistrationEntriesRequest(ctx context.Context, db queryContext, req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesRequest, entryPaginationToken, error) {
+	var token entryPaginationToken
+	if req.Pagination == nil {
+		return req, token, nil
+	}
+
+	token, err := parseEntryPaginationToken(req.Pagination.Token)
+	if err != nil {
+		return nil, token, err
+	}
+	if token.hasOrderKey != (req.OrderBy != "" && token.lastID != 0) {
+		return nil, token, status.Errorf(codes.InvalidArgument, "token '%v' was not issued for the requested order", req.Pagination.Token)
+	}
+	if !token.hasSnapshot {
+		// This is the first page (or the token was issued before
+		// snapshots were tracked). Capture the snapshot so entries
+		// created while paging are never returned.
+		token.snapshotID, err = fetchMaxRegistrationEntryID(ctx, db)
+		if err != nil {
+			return nil, token, err
+		}
+		token.hasSnapshot = true
+	}
+	snapshotReq := *req
+	snapshotReq.Pagination = &datastore.Pagination{
+		Token:    token.String(),
+		PageSize: req.Pagination.PageSize,
+	}
+	return &snapshotReq, token, nil
+}
+
+// explainQuery returns the plan of the query, with a line per row of the
+// EXPLAIN output and the columns of each row separated by tabs.
+func explainQuery(ctx context.Context, db queryContext, dbType string, query string, args []any) (string, error) {
+	var explain string
+	switch {
+	case isSQLiteDbType(dbType):
+		explain = "EXPLAIN QUERY PLAN "
+	case isPostgresDbType(dbType):
+		explain = "EXPLAIN (ANALYZE false) "
+	case isMySQLDbType(dbType):
+		explain = "EXPLAIN "
+	default:
+		return "", newSQLError("unsupported db type: %q", dbType)
+	}
+
+	rows, err := db.QueryContext(ctx, explain+query, args...)
+	if err != nil {
+		return "", newWrappedSQLError(err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return "", newWrappedSQLError(err)
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var plan strings.Builder
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return "", newWrappedSQLError(err)
+		}
+		for i, value := range values {
+			if i > 0 {
+				plan.WriteString("\t")
+			}
+			plan.WriteString(value.String)
+		}
+		plan.WriteString("\n")
+	}
+	if err := rows.Err(); err != nil {
+		return "", newWrappedSQLError(err)
+	}
+	return plan.String(), nil
+}
+
+// sortEntries sorts the entries, and their IDs along with them, in the given
+// order. Entries without an expiry are sorted last when sorting by expiry.
+// Ties are broken by entry ID.
+func sortEntries(entries []*common.RegistrationEntry, entryIDs []uint64, orderBy datastore.EntryOrder) {
+	if orderBy == datastore.OrderByRevisionAsc {
+		sort.Sort(entriesByRevision{entries: entries, entryIDs: entryIDs})
+		return
+	}
+	sort.Sort(entriesByExpiry{entries: entries, entryIDs: entryIDs, desc: orderBy == datastore.OrderByExpiryDesc})
+}
+
+// entryOrderKey returns the value of the entry the entries are sorted by in
+// the given order.
+func entryOrderKey(entry *common.RegistrationEntry, orderBy datastore.EntryOrder) int64 {
+	if orderBy == datastore.OrderByRevisionAsc {
+		return entry.RevisionNumber
+	}
+	return entry.EntryExpiry
+}
+
+type entriesByRevision struct {
+	entries  []*common.RegistrationEntry
+	entryIDs []uint64
+}
+
+func (s entriesByRevision) Len() int { return len(s.entries) }
+
+func (s entriesByRevision) Less(i, j int) bool {
+	ri, rj := s.entries[i].RevisionNumber, s.entries[j].RevisionNumber
+	if ri != rj {
+		return ri < rj
+	}
+	return s.entryIDs[i] < s.entryIDs[j]
+}
+
+func (s entriesByRevision) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	s.entryIDs[i], s.entryIDs[j] = s.entryIDs[j], s.entryIDs[i]
+}
+
+type entriesByExpiry struct {
+	entries  []*common.RegistrationEntry
+	entryIDs []uint64
+	desc     bool
+}
+
+func (s entriesByExpiry) Len() int { return len(s.entries) }
+
+func (s entriesByExpiry) Less(i, j int) bool {
+	ei, ej := s.entries[i].EntryExpiry, s.entries[j].EntryExpiry
+	switch {
+	case (ei == 0) != (ej == 0):
+		return ej == 0
+	case ei != ej && s.desc:
+		return ei > ej
+	case ei != ej:
+		return ei < ej
+	default:
+		return s.entryIDs[i] < s.entryIDs[j]
+	}
+}
+
+func (s entriesByExpiry) Swap(i, j int) {
+	s.entries[i], s.entries[j] = s.entries[j], s.entries[i]
+	s.entryIDs[i], s.entryIDs[j] = s.entryIDs[j], s.entryIDs[i]
+}
+
+// entryPaginationToken is the pagination token used to list registration
+// entries. It holds the ID of the last entry returned and the highest entry
+// ID at the time the listing started, so entries created while paging are
+// excluded and the pages form a consistent snapshot. When entries are listed
+// in a given order, it also holds the value the last entry returned was sorted
+// by, i.e. its expiry or revision number.
+//
+// The token is encoded as "<lastID>:<snapshotID>", or as
+// "<lastID>:<snapshotID>:<lastOrderKey>" when entries are listed in a given
+// order.
+// Tokens holding only the last ID, issued before snapshots were tracked, are
+// still accepted.
+type entryPaginationToken struct {
+	lastID       uint64
+	snapshotID   uint64
+	hasSnapshot  bool
+	lastOrderKey int64
+	hasOrderKey  bool
+}
+
+func parseEntryPaginationToken(s string) (entryPaginationToken, error) {
+	var token entryPaginationToken
+	if s == "" {
+		return token, nil
+	}
+
+	var lastID, snapshotID, lastOrderKey string
+	lastID, snapshotID, token.hasSnapshot = strings.Cut(s, ":")
+	snapshotID, lastOrderKey, token.hasOrderKey = strings.Cut(snapshotID, ":")
+
+	var err error
+	token.lastID, err = strconv.ParseUint(lastID, 10, 32)
+	if err != nil {
+		return token, status.Errorf(codes.InvalidArgument, "could not parse token '%v'", s)
+	}
+	if token.hasSnapshot {
+		token.snapshotID, err = strconv.ParseUint(snapshotID, 10, 32)
+		if err != nil {
+			return token, status.Errorf(codes.InvalidArgument, "could not parse token '%v'", s)
+		}
+	}
+	if token.hasOrderKey {
+		token.lastOrderKey, err = strconv.ParseInt(lastOrderKey, 10, 64)
+		if err != nil {
+			return token, status.Errorf(codes.InvalidArgument, "could not parse token '%v'", s)
+		}
+	}
+	return token, nil
+}
+
+func (t entryPaginationToken) String() string {
+	s := strconv.FormatUint(t.lastID, 10) + ":" + strconv.FormatUint(t.snapshotID, 10)
+	if t.hasOrderKey {
+		s += ":" + strconv.FormatInt(t.lastOrderKey, 10)
+	}
+	return s
+}
+
+func fetchMaxRegistrationEntryID(ctx context.Context, db queryContext) (uint64, error) {
+	rows, err := db.QueryContext(ctx, "SELECT MAX(id) FROM registered_entries")
+	if err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	defer rows.Close()
+
+	var maxID sql.NullInt64
+	if rows.Next() {
+		if err := rows.Scan(&maxID); err != nil {
+			return 0, newWrappedSQLError(err)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	return uint64(maxID.Int64), nil
+}
+
 func buildListRegistrationEntriesQuery(dbType string, supportsCTE bool, req *datastore.ListRegistrationEntriesRequest) (string, []any, error) {
 	switch {
 	case isSQLiteDbType(dbType):
@@ -2936,10 +5397,6 @@ func buildListRegistrationEntriesQuery(dbType string, supportsCTE bool, req *dat
 func buildListRegistrationEntriesQuerySQLite3(req *datastore.ListRegistrationEntriesRequest) (string, []any, error) {
 	builder := new(strings.Builder)
 	filtered, args, err := appendListRegistrationEntriesFilterQuery("\nWITH listing AS (\n", builder, SQLite, req)
-	downstream := false
-	if req.ByDownstream != nil {
-		downstream = *req.ByDownstream
-	}
 
 	if err != nil {
 		return "", nil, err
@@ -2968,7 +5425,11 @@ SELECT
 	NULL AS dns_name_id,
 	NULL AS dns_name,
 	revision_number,
-	jwt_svid_ttl AS reg_jwt_svid_ttl
+	jwt_svid_ttl AS reg_jwt_svid_ttl,
+	created_by,
+	idempotency_key,
+	active,
+	federates_with_all
 FROM
 	registered_entries
 `)
@@ -2976,18 +5437,11 @@ FROM
 	if filtered {
 		builder.WriteString("WHERE id IN (SELECT e_id FROM listing)\n")
 	}
-	if downstream {
-		if !filtered {
-			builder.WriteString("\t\tWHERE downstream = true\n")
-		} else {
-			builder.WriteString("\t\tAND downstream = true\n")
-		}
-	}
 	builder.WriteString(`
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3002,7 +5456,7 @@ ON
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 `)
@@ -3013,7 +5467,7 @@ FROM
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 `)
@@ -3031,10 +5485,6 @@ func buildListRegistrationEntriesQueryPostgreSQL(req *datastore.ListRegistration
 	builder := new(strings.Builder)
 
 	filtered, args, err := appendListRegistrationEntriesFilterQuery("\nWITH listing AS (\n", builder, PostgreSQL, req)
-	downstream := false
-	if req.ByDownstream != nil {
-		downstream = *req.ByDownstream
-	}
 
 	if err != nil {
 		return "", nil, err
@@ -3063,25 +5513,22 @@ SELECT
 	NULL ::integer AS dns_name_id,
 	NULL AS dns_name,
 	revision_number,
-	jwt_svid_ttl AS reg_jwt_svid_ttl
+	jwt_svid_ttl AS reg_jwt_svid_ttl,
+	created_by,
+	idempotency_key,
+	active,
+	federates_with_all
 FROM
 	registered_entries
 `)
 	if filtered {
 		builder.WriteString("WHERE id IN (SELECT e_id FROM listing)\n")
 	}
-	if downstream {
-		if !filtered {
-			builder.WriteString("\t\tWHERE downstream = true\n")
-		} else {
-			builder.WriteString("\t\tAND downstream = true\n")
-		}
-	}
 	builder.WriteString(`
 UNION ALL
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3096,7 +5543,7 @@ ON
 UNION ALL
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 `)
@@ -3107,7 +5554,7 @@ FROM
 UNION ALL
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 `)
@@ -3156,7 +5603,11 @@ SELECT
 	D.id AS dns_name_id,
 	D.value AS dns_name,
 	E.revision_number,
-	E.jwt_svid_ttl AS reg_jwt_svid_ttl
+	E.jwt_svid_ttl AS reg_jwt_svid_ttl,
+	E.created_by,
+	E.idempotency_key,
+	E.active,
+	E.federates_with_all
 FROM
 	registered_entries E
 LEFT JOIN
@@ -3170,10 +5621,6 @@ LEFT JOIN
 `)
 
 	filtered, args, err := appendListRegistrationEntriesFilterQuery("WHERE E.id IN (\n", builder, MySQL, req)
-	downstream := false
-	if req.ByDownstream != nil {
-		downstream = *req.ByDownstream
-	}
 
 	if err != nil {
 		return "", nil, err
@@ -3182,13 +5629,6 @@ LEFT JOIN
 	if filtered {
 		builder.WriteString(")")
 	}
-	if downstream {
-		if !filtered {
-			builder.WriteString("\t\tWHERE downstream = true\n")
-		} else {
-			builder.WriteString("\t\tAND downstream = true\n")
-		}
-	}
 	builder.WriteString("\nORDER BY e_id, selector_id, dns_name_id\n;")
 
 	return builder.String(), args, nil
@@ -3198,10 +5638,6 @@ func buildListRegistrationEntriesQueryMySQLCTE(req *datastore.ListRegistrationEn
 	builder := new(strings.Builder)
 
 	filtered, args, err := appendListRegistrationEntriesFilterQuery("\nWITH listing AS (\n", builder, MySQL, req)
-	downstream := false
-	if req.ByDownstream != nil {
-		downstream = *req.ByDownstream
-	}
 
 	if err != nil {
 		return "", nil, err
@@ -3230,25 +5666,22 @@ SELECT
 	NULL AS dns_name_id,
 	NULL AS dns_name,
 	revision_number,
-	jwt_svid_ttl AS reg_jwt_svid_ttl
+	jwt_svid_ttl AS reg_jwt_svid_ttl,
+	created_by,
+	idempotency_key,
+	active,
+	federates_with_all
 FROM
 	registered_entries
 `)
 	if filtered {
 		builder.WriteString("WHERE id IN (SELECT e_id FROM listing)\n")
 	}
-	if downstream {
-		if !filtered {
-			builder.WriteString("\t\tWHERE downstream = true\n")
-		} else {
-			builder.WriteString("\t\tAND downstream = true\n")
-		}
-	}
 	builder.WriteString(`
 UNION
 
 SELECT
-	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL
+	F.registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, B.trust_domain, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	bundles B
 INNER JOIN
@@ -3263,7 +5696,7 @@ ON
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, value, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	dns_names
 `)
@@ -3274,7 +5707,7 @@ FROM
 UNION
 
 SELECT
-	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL
+	registered_entry_id, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, id, type, value, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL
 FROM
 	selectors
 `)
@@ -3290,47 +5723,76 @@ ORDER BY e_id, selector_id, dns_name_id
 
 // Count Registration Entries
 func countRegistrationEntries(ctx context.Context, db *sqlDB, _ logrus.FieldLogger, req *datastore.CountRegistrationEntriesRequest) (int32, error) {
-	if req.BySelectors != nil && len(req.BySelectors.Selectors) == 0 {
-		return 0, status.Error(codes.InvalidArgument, "cannot list by empty selector set")
+	listReq := &datastore.ListRegistrationEntriesRequest{
+		DataConsistency:       req.DataConsistency,
+		ByParentID:            req.ByParentID,
+		BySelectors:           req.BySelectors,
+		BySpiffeID:            req.BySpiffeID,
+		ByFederatesWith:       req.ByFederatesWith,
+		ByHint:                req.ByHint,
+		ByAdmin:               req.ByAdmin,
+		ByDownstream:          req.ByDownstream,
+		ByCreatedBy:           req.ByCreatedBy,
+		ByStoreSvid:           req.ByStoreSvid,
+		ByActive:              req.ByActive,
+		BySelectorValuePrefix: req.BySelectorValuePrefix,
+		ByRevisionGreaterThan: req.ByRevisionGreaterThan,
+	}
+	if err := validateListRegistrationEntriesRequest(listReq); err != nil {
+		return 0, err
 	}
 
-	var val int32
-	listReq := &datastore.ListRegistrationEntriesRequest{
-		DataConsistency: req.DataConsistency,
-		ByParentID:      req.ByParentID,
-		BySelectors:     req.BySelectors,
-		BySpiffeID:      req.BySpiffeID,
-		ByFederatesWith: req.ByFederatesWith,
-		ByHint:          req.ByHint,
-		ByDownstream:    req.ByDownstream,
-		Pagination: &datastore.Pagination{
-			Token:    "",
-			PageSize: 1000,
-		},
+	query, args, err := buildCountRegistrationEntriesQuery(db.databaseType, listReq)
+	if err != nil {
+		return 0, newWrappedSQLError(err)
 	}
 
-	for {
-		resp, err := listRegistrationEntriesOnce(ctx, db.raw, db.databaseType, db.supportsCTE, listReq)
-		if err != nil {
-			return -1, err
-		}
+	var count int64
+	if err := db.raw.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	return util.CheckedCast[int32](count)
+}
 
-		if len(resp.Entries) == 0 {
-			return val, nil
-		}
+// buildCountRegistrationEntriesQuery builds the query counting the entries
+// that the request would list, without ordering nor paginating them.
+func buildCountRegistrationEntriesQuery(dbType string, req *datastore.ListRegistrationEntriesRequest) (string, []any, error) {
+	root, args, err := buildRegistrationEntriesFilter(req)
+	if err != nil {
+		return "", nil, err
+	}
 
-		if req.BySelectors != nil {
-			switch req.BySelectors.Match {
-			case datastore.Exact, datastore.Subset:
-				resp.Entries = filterEntriesBySelectorSet(resp.Entries, req.BySelectors.Selectors)
-			default:
-			}
+	// Listing leaves out the entries with selectors outside of the request
+	// once they are fetched (see filterEntriesBySelectorSet), which has to
+	// be done here by grouping the selectors of each entry instead.
+	if req.BySelectors != nil && (req.BySelectors.Match == datastore.Exact || req.BySelectors.Match == datastore.Subset) {
+		conditions := new(strings.Builder)
+		writeSelectorMatchConditions(conditions, "type", "value", len(req.BySelectors.Selectors))
+		root.children = append(root.children, idFilterNode{
+			idColumn: "registered_entry_id",
+			query: []string{
+				"SELECT registered_entry_id AS e_id FROM selectors",
+				"GROUP BY registered_entry_id",
+				"HAVING COUNT(CASE WHEN NOT (" + conditions.String() + ") THEN 1 ELSE NULL END) = 0",
+			},
+		})
+		for _, selector := range req.BySelectors.Selectors {
+			args = append(args, selector.Type, selector.Value)
 		}
+	}
 
-		val += util.MustCast[int32](len(resp.Entries))
+	builder := new(strings.Builder)
+	builder.WriteString("SELECT COUNT(*) FROM registered_entries")
+	if len(root.children) > 0 {
+		builder.WriteString(" WHERE id IN (\n")
+		root.Render(builder, dbType, 1, true)
+		builder.WriteString(")")
+	}
 
-		listReq.Pagination = resp.Pagination
+	if isPostgresDbType(dbType) {
+		return postgreSQLRebind(builder.String()), args, nil
 	}
+	return builder.String(), args, nil
 }
 
 type idFilterNode struct {
@@ -3435,27 +5897,134 @@ func (n idFilterNode) render(builder *strings.Builder, dbType string, sibling in
 	}
 }
 
-func indent(builder *strings.Builder, indentation int) {
-	switch indentation {
-	case 0:
-	case 1:
-		builder.WriteString("\t")
-	case 2:
-		builder.WriteString("\t\t")
-	case 3:
-		builder.WriteString("\t\t\t")
-	case 4:
-		builder.WriteString("\t\t\t\t")
-	case 5:
-		builder.WriteString("\t\t\t\t\t")
-	default:
-		for range indentation {
-			builder.WriteString("\t")
+// likeEscapeChar is the character used to escape wildcards in LIKE patterns.
+// A backslash is avoided since it is not treated the same way in string
+// literals by every database.
+const likeEscapeChar = "!"
+
+var likePatternEscaper = strings.NewReplacer(
+	likeEscapeChar, likeEscapeChar+likeEscapeChar,
+	"%", likeEscapeChar+"%",
+	"_", likeEscapeChar+"_",
+)
+
+// escapeLikePattern escapes the LIKE wildcards in s so it is matched
+// literally.
+func escapeLikePattern(s string) string {
+	return likePatternEscaper.Replace(s)
+}
+
+func indent(builder *strings.Builder, indentation int) {
+	switch indentation {
+	case 0:
+	case 1:
+		builder.WriteString("\t")
+	case 2:
+		builder.WriteString("\t\t")
+	case 3:
+		builder.WriteString("\t\t\t")
+	case 4:
+		builder.WriteString("\t\t\t\t")
+	case 5:
+		builder.WriteString("\t\t\t\t\t")
+	default:
+		for range indentation {
+			builder.WriteString("\t")
+		}
+	}
+}
+
+func appendListRegistrationEntriesFilterQuery(filterExp string, builder *strings.Builder, dbType string, req *datastore.ListRegistrationEntriesRequest) (bool, []any, error) {
+	root, args, err := buildRegistrationEntriesFilter(req)
+	if err != nil {
+		return false, nil, err
+	}
+
+	filtered := false
+	filter := func() {
+		if !filtered {
+			builder.WriteString(filterExp)
+		}
+		filtered = true
+	}
+
+	indentation := 1
+	if req.Pagination != nil && isMySQLDbType(dbType) {
+		filter()
+		builder.WriteString("\tSELECT e_id FROM (\n")
+		indentation = 2
+	}
+
+	if req.Pagination != nil && req.OrderBy != "" {
+		filter()
+		orderArgs, err := appendOrderedPaginationQuery(builder, dbType, root, indentation, req)
+		if err != nil {
+			return false, nil, err
+		}
+		args = append(args, orderArgs...)
+		if isMySQLDbType(dbType) {
+			builder.WriteString("\t) workaround_for_mysql_subquery_limit\n")
+		}
+		return filtered, args, nil
+	}
+
+	if len(root.children) > 0 {
+		filter()
+		root.Render(builder, dbType, indentation, req.Pagination == nil)
+	}
+
+	if req.Pagination != nil {
+		filter()
+		var idColumn string
+		switch len(root.children) {
+		case 0:
+			idColumn = "id"
+			indent(builder, indentation)
+			builder.WriteString("SELECT id AS e_id FROM registered_entries")
+		case 1:
+			idColumn = root.children[0].idColumn
+		default:
+			idColumn = "e_id"
+		}
+
+		if len(req.Pagination.Token) > 0 {
+			token, err := parseEntryPaginationToken(req.Pagination.Token)
+			if err != nil {
+				return false, nil, err
+			}
+			if len(root.children) == 1 && len(root.children[0].children) == 0 {
+				builder.WriteString(" AND ")
+			} else {
+				builder.WriteString(" WHERE ")
+			}
+			builder.WriteString(idColumn)
+			builder.WriteString(" > ?")
+			args = append(args, token.lastID)
+			if token.hasSnapshot {
+				builder.WriteString(" AND ")
+				builder.WriteString(idColumn)
+				builder.WriteString(" <= ?")
+				args = append(args, token.snapshotID)
+			}
+		}
+		builder.WriteString(" ORDER BY ")
+		builder.WriteString(idColumn)
+		builder.WriteString(" ASC LIMIT ")
+		builder.WriteString(strconv.FormatInt(int64(req.Pagination.PageSize), 10))
+		builder.WriteString("\n")
+
+		if isMySQLDbType(dbType) {
+			builder.WriteString("\t) workaround_for_mysql_subquery_limit\n")
 		}
 	}
+
+	return filtered, args, nil
 }
 
-func appendListRegistrationEntriesFilterQuery(filterExp string, builder *strings.Builder, dbType string, req *datastore.ListRegistrationEntriesRequest) (bool, []any, error) {
+// buildRegistrationEntriesFilter builds the filter selecting the IDs of the
+// entries matching the request, along with its arguments. The filter
+// intersects its children and has none when the request filters nothing.
+func buildRegistrationEntriesFilter(req *datastore.ListRegistrationEntriesRequest) (idFilterNode, []any, error) {
 	var args []any
 
 	root := idFilterNode{idColumn: "id"}
@@ -3480,59 +6049,131 @@ func appendListRegistrationEntriesFilterQuery(filterExp string, builder *strings
 		})
 	}
 
-	if req.ByHint != "" {
+	if req.ByHint != nil {
+		query := "SELECT id AS e_id FROM registered_entries WHERE hint = ?"
+		if *req.ByHint == "" {
+			// Entries created before the hint column was added have a NULL hint
+			query = "SELECT id AS e_id FROM registered_entries WHERE (hint = ? OR hint IS NULL)"
+		}
+		root.children = append(root.children, idFilterNode{
+			idColumn: "id",
+			query:    []string{query},
+		})
+		args = append(args, *req.ByHint)
+	}
+
+	if req.ByCreatedBy != "" {
+		root.children = append(root.children, idFilterNode{
+			idColumn: "id",
+			query:    []string{"SELECT id AS e_id FROM registered_entries WHERE created_by = ?"},
+		})
+		args = append(args, req.ByCreatedBy)
+	}
+
+	if req.ByStoreSvid != nil {
+		root.children = append(root.children, idFilterNode{
+			idColumn: "id",
+			query:    []string{"SELECT id AS e_id FROM registered_entries WHERE store_svid = ?"},
+		})
+		args = append(args, *req.ByStoreSvid)
+	}
+
+	if req.ByRevisionGreaterThan > 0 {
+		root.children = append(root.children, idFilterNode{
+			idColumn: "id",
+			query:    []string{"SELECT id AS e_id FROM registered_entries WHERE revision_number > ?"},
+		})
+		args = append(args, req.ByRevisionGreaterThan)
+	}
+
+	if req.ByActive != nil {
+		root.children = append(root.children, idFilterNode{
+			idColumn: "id",
+			query:    []string{"SELECT id AS e_id FROM registered_entries WHERE active = ?"},
+		})
+		args = append(args, *req.ByActive)
+	}
+
+	if req.ByAdmin != nil {
 		root.children = append(root.children, idFilterNode{
 			idColumn: "id",
-			query:    []string{"SELECT id AS e_id FROM registered_entries WHERE hint = ?"},
+			query:    []string{"SELECT id AS e_id FROM registered_entries WHERE admin = ?"},
 		})
-		args = append(args, req.ByHint)
+		args = append(args, *req.ByAdmin)
+	}
+
+	if req.ByDownstream != nil {
+		root.children = append(root.children, idFilterNode{
+			idColumn: "id",
+			query:    []string{"SELECT id AS e_id FROM registered_entries WHERE downstream = ?"},
+		})
+		args = append(args, *req.ByDownstream)
 	}
 
 	if req.BySelectors != nil && len(req.BySelectors.Selectors) > 0 {
+		// The entries matching the selectors are looked up with a single
+		// query, covered by the idx_selectors_type_value_entry index,
+		// instead of a subquery per selector. The query must end with a
+		// condition, which the pagination conditions are appended to.
+		type selectorKey struct {
+			Type  string
+			Value string
+		}
+		var selectors []*common.Selector
+		seen := make(map[selectorKey]struct{}, len(req.BySelectors.Selectors))
+		for _, selector := range req.BySelectors.Selectors {
+			key := selectorKey{Type: selector.Type, Value: selector.Value}
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				selectors = append(selectors, selector)
+			}
+		}
+
+		for _, selector := range selectors {
+			args = append(args, selector.Type, selector.Value)
+		}
+		match := "type = ? AND value = ?"
+		if len(selectors) > 1 {
+			match = "(" + strings.TrimSuffix(strings.Repeat("(type = ? AND value = ?) OR ", len(selectors)), " OR ") + ")"
+		}
+
+		var query []string
 		switch req.BySelectors.Match {
 		case datastore.Subset, datastore.MatchAny:
-			// subset needs a union, so we need to group them and add the group
-			// as a child to the root.
-			if len(req.BySelectors.Selectors) < 2 {
-				root.children = append(root.children, idFilterNode{
-					idColumn: "registered_entry_id",
-					query:    []string{"SELECT registered_entry_id AS e_id FROM selectors WHERE type = ? AND value = ?"},
-				})
-			} else {
-				group := idFilterNode{
-					idColumn: "e_id",
-					union:    true,
-				}
-				for range req.BySelectors.Selectors {
-					group.children = append(group.children, idFilterNode{
-						idColumn: "registered_entry_id",
-						query:    []string{"SELECT registered_entry_id AS e_id FROM selectors WHERE type = ? AND value = ?"},
-					})
-				}
-				root.children = append(root.children, group)
-			}
+			// Entries with any of the selectors
+			query = []string{"SELECT DISTINCT registered_entry_id AS e_id FROM selectors WHERE " + match}
 		case datastore.Exact, datastore.Superset:
-			// exact match does use an intersection, so we can just add these
-			// directly to the root idFilterNode, since it is already an intersection
-			for range req.BySelectors.Selectors {
-				root.children = append(root.children, idFilterNode{
-					idColumn: "registered_entry_id",
-					query:    []string{"SELECT registered_entry_id AS e_id FROM selectors WHERE type = ? AND value = ?"},
-				})
+			// Entries with all the selectors. An entry cannot have the same
+			// selector twice, so it has all of them when all are matched.
+			query = []string{
+				"SELECT registered_entry_id AS e_id FROM selectors WHERE " + match,
+				"GROUP BY registered_entry_id HAVING COUNT(*) = ?",
 			}
+			args = append(args, len(selectors))
 		default:
-			return false, nil, fmt.Errorf("unhandled selectors match behavior %q", req.BySelectors.Match)
-		}
-		for _, selector := range req.BySelectors.Selectors {
-			args = append(args, selector.Type, selector.Value)
+			return idFilterNode{}, nil, fmt.Errorf("unhandled selectors match behavior %q", req.BySelectors.Match)
 		}
+		root.children = append(root.children, idFilterNode{
+			idColumn: "registered_entry_id",
+			query:    query,
+		})
+	}
+
+	if req.BySelectorValuePrefix != nil {
+		// A LIKE with a constant prefix can be served by the
+		// idx_selectors_type_value_entry index
+		root.children = append(root.children, idFilterNode{
+			idColumn: "registered_entry_id",
+			query:    []string{"SELECT registered_entry_id AS e_id FROM selectors WHERE type = ? AND value LIKE ? ESCAPE '" + likeEscapeChar + "'"},
+		})
+		args = append(args, req.BySelectorValuePrefix.Type, escapeLikePattern(req.BySelectorValuePrefix.Prefix)+"%")
 	}
 
 	if req.ByFederatesWith != nil && len(req.ByFederatesWith.TrustDomains) > 0 {
 		// Take the trust domains from the request without duplicates
 		tdSet := make(map[string]struct{})
 		for _, td := range req.ByFederatesWith.TrustDomains {
-			tdSet[td] = struct{}{}
+			tdSet[normalizeTrustDomainID(td)] = struct{}{}
 		}
 		trustDomains := make([]string, 0, len(tdSet))
 		for td := range tdSet {
@@ -3594,71 +6235,75 @@ func appendListRegistrationEntriesFilterQuery(filterExp string, builder *strings
 			args = append(args, len(trustDomains))
 
 		default:
-			return false, nil, fmt.Errorf("unhandled federates with match behavior %q", req.ByFederatesWith.Match)
+			return idFilterNode{}, nil, fmt.Errorf("unhandled federates with match behavior %q", req.ByFederatesWith.Match)
 		}
 		root.children = append(root.children, filterNode)
 	}
 
-	filtered := false
-	filter := func() {
-		if !filtered {
-			builder.WriteString(filterExp)
-		}
-		filtered = true
-	}
+	return root, args, nil
+}
 
-	indentation := 1
-	if req.Pagination != nil && isMySQLDbType(dbType) {
-		filter()
-		builder.WriteString("\tSELECT e_id FROM (\n")
-		indentation = 2
-	}
+// appendOrderedPaginationQuery renders the query selecting the IDs of the
+// entries in a page when entries are listed in a given order. The page is
+// selected by the position of the last entry listed, which is held by the
+// token as its ID and expiry or revision number. Entries without an expiry
+// (zero) are sorted last, by ID, when listed by expiry.
+func appendOrderedPaginationQuery(builder *strings.Builder, dbType string, root idFilterNode, indentation int, req *datastore.ListRegistrationEntriesRequest) ([]any, error) {
+	var args []any
+	var conditions []string
 
+	indent(builder, indentation)
+	builder.WriteString("SELECT id AS e_id FROM registered_entries")
 	if len(root.children) > 0 {
-		filter()
-		root.Render(builder, dbType, indentation, req.Pagination == nil)
+		var filterQuery strings.Builder
+		root.Render(&filterQuery, dbType, indentation+1, true)
+		conditions = append(conditions, "id IN (\n"+filterQuery.String()+strings.Repeat("\t", indentation)+")")
 	}
 
-	if req.Pagination != nil {
-		filter()
-		var idColumn string
-		switch len(root.children) {
-		case 0:
-			idColumn = "id"
-			indent(builder, indentation)
-			builder.WriteString("SELECT id AS e_id FROM registered_entries")
-		case 1:
-			idColumn = root.children[0].idColumn
-		default:
-			idColumn = "e_id"
+	if len(req.Pagination.Token) > 0 {
+		token, err := parseEntryPaginationToken(req.Pagination.Token)
+		if err != nil {
+			return nil, err
 		}
-
-		if len(req.Pagination.Token) > 0 {
-			token, err := strconv.ParseUint(req.Pagination.Token, 10, 32)
-			if err != nil {
-				return false, nil, status.Errorf(codes.InvalidArgument, "could not parse token '%v'", req.Pagination.Token)
-			}
-			if len(root.children) == 1 && len(root.children[0].children) == 0 {
-				builder.WriteString(" AND ")
-			} else {
-				builder.WriteString(" WHERE ")
+		if token.hasOrderKey {
+			switch {
+			case req.OrderBy == datastore.OrderByRevisionAsc:
+				conditions = append(conditions, "(revision_number > ? OR (revision_number = ? AND id > ?))")
+				args = append(args, token.lastOrderKey, token.lastOrderKey, token.lastID)
+			case token.lastOrderKey == 0:
+				conditions = append(conditions, "(expiry = 0 AND id > ?)")
+				args = append(args, token.lastID)
+			case req.OrderBy == datastore.OrderByExpiryAsc:
+				conditions = append(conditions, "(expiry = 0 OR expiry > ? OR (expiry = ? AND id > ?))")
+				args = append(args, token.lastOrderKey, token.lastOrderKey, token.lastID)
+			default:
+				conditions = append(conditions, "(expiry = 0 OR (expiry <> 0 AND expiry < ?) OR (expiry = ? AND id > ?))")
+				args = append(args, token.lastOrderKey, token.lastOrderKey, token.lastID)
 			}
-			builder.WriteString(idColumn)
-			builder.WriteString(" > ?")
-			args = append(args, token)
 		}
-		builder.WriteString(" ORDER BY ")
-		builder.WriteString(idColumn)
-		builder.WriteString(" ASC LIMIT ")
-		builder.WriteString(strconv.FormatInt(int64(req.Pagination.PageSize), 10))
-		builder.WriteString("\n")
-
-		if isMySQLDbType(dbType) {
-			builder.WriteString("\t) workaround_for_mysql_subquery_limit\n")
+		if token.hasSnapshot {
+			conditions = append(conditions, "id <= ?")
+			args = append(args, token.snapshotID)
 		}
 	}
 
-	return filtered, args, nil
+	if len(conditions) > 0 {
+		builder.WriteString(" WHERE ")
+		builder.WriteString(strings.Join(conditions, " AND "))
+	}
+
+	switch req.OrderBy {
+	case datastore.OrderByRevisionAsc:
+		builder.WriteString(" ORDER BY revision_number ASC")
+	case datastore.OrderByExpiryDesc:
+		builder.WriteString(" ORDER BY CASE WHEN expiry = 0 THEN 1 ELSE 0 END, expiry DESC")
+	default:
+		builder.WriteString(" ORDER BY CASE WHEN expiry = 0 THEN 1 ELSE 0 END, expiry ASC")
+	}
+	builder.WriteString(", id ASC LIMIT ")
+	builder.WriteString(strconv.FormatInt(int64(req.Pagination.PageSize), 10))
+	builder.WriteString("\n")
+	return args, nil
 }
 
 func buildSliceArg(length int) string {
@@ -3680,6 +6325,8 @@ type nodeRow struct {
 	NewSerialNumber sql.NullString
 	NewExpiresAt    sql.NullTime
 	CanReattest     sql.NullBool
+	AttestedAt      sql.NullTime
+	LastAttestedAt  sql.NullTime
 	SelectorType    sql.NullString
 	SelectorValue   sql.NullString
 }
@@ -3694,6 +6341,8 @@ func scanNodeRow(rs *sql.Rows, r *nodeRow) error {
 		&r.NewSerialNumber,
 		&r.NewExpiresAt,
 		&r.CanReattest,
+		&r.AttestedAt,
+		&r.LastAttestedAt,
 		&r.SelectorType,
 		&r.SelectorValue,
 	))
@@ -3738,6 +6387,14 @@ func fillNodeFromRow(node *common.AttestedNode, r *nodeRow) error {
 		node.CanReattest = r.CanReattest.Bool
 	}
 
+	if r.AttestedAt.Valid {
+		node.AttestedAt = r.AttestedAt.Time.Unix()
+	}
+
+	if r.LastAttestedAt.Valid {
+		node.LastAttestedAt = r.LastAttestedAt.Time.Unix()
+	}
+
 	return nil
 }
 
@@ -3766,25 +6423,29 @@ func fillNodeSelectorFromRow(nodeSelector *common.Selector, r *nodeSelectorRow)
 }
 
 type entryRow struct {
-	EId            uint64
-	EntryID        sql.NullString
-	SpiffeID       sql.NullString
-	ParentID       sql.NullString
-	RegTTL         sql.NullInt64
-	Admin          sql.NullBool
-	Downstream     sql.NullBool
-	Expiry         sql.NullInt64
-	SelectorID     sql.NullInt64
-	SelectorType   sql.NullString
-	SelectorValue  sql.NullString
-	StoreSvid      sql.NullBool
-	Hint           sql.NullString
-	CreatedAt      sql.NullTime
-	TrustDomain    sql.NullString
-	DNSNameID      sql.NullInt64
-	DNSName        sql.NullString
-	RevisionNumber sql.NullInt64
-	RegJwtSvidTTL  sql.NullInt64
+	EId              uint64
+	EntryID          sql.NullString
+	SpiffeID         sql.NullString
+	ParentID         sql.NullString
+	RegTTL           sql.NullInt64
+	Admin            sql.NullBool
+	Downstream       sql.NullBool
+	Expiry           sql.NullInt64
+	SelectorID       sql.NullInt64
+	SelectorType     sql.NullString
+	SelectorValue    sql.NullString
+	StoreSvid        sql.NullBool
+	Hint             sql.NullString
+	CreatedAt        sql.NullTime
+	TrustDomain      sql.NullString
+	DNSNameID        sql.NullInt64
+	DNSName          sql.NullString
+	RevisionNumber   sql.NullInt64
+	RegJwtSvidTTL    sql.NullInt64
+	CreatedBy        sql.NullString
+	IdempotencyKey   sql.NullString
+	Active           sql.NullBool
+	FederatesWithAll sql.NullBool
 }
 
 func scanEntryRow(rs *sql.Rows, r *entryRow) error {
@@ -3808,6 +6469,10 @@ func scanEntryRow(rs *sql.Rows, r *entryRow) error {
 		&r.DNSName,
 		&r.RevisionNumber,
 		&r.RegJwtSvidTTL,
+		&r.CreatedBy,
+		&r.IdempotencyKey,
+		&r.Active,
+		&r.FederatesWithAll,
 	))
 }
 
@@ -3869,6 +6534,18 @@ func fillEntryFromRow(entry *common.RegistrationEntry, r *entryRow) error {
 	if r.CreatedAt.Valid {
 		entry.CreatedAt = roundedInSecondsUnix(r.CreatedAt.Time)
 	}
+	if r.CreatedBy.Valid {
+		entry.CreatedBy = r.CreatedBy.String
+	}
+	if r.IdempotencyKey.Valid {
+		entry.IdempotencyKey = r.IdempotencyKey.String
+	}
+	if r.Active.Valid {
+		entry.Inactive = !r.Active.Bool
+	}
+	if r.FederatesWithAll.Valid {
+		entry.FederatesWithAll = r.FederatesWithAll.Bool
+	}
 
 	return nil
 }
@@ -3890,7 +6567,7 @@ func applyPagination(p *datastore.Pagination, entryTx *gorm.DB) (*gorm.DB, error
 	return entryTx, nil
 }
 
-func updateRegistrationEntry(tx *gorm.DB, e *common.RegistrationEntry, mask *common.RegistrationEntryMask) (*common.RegistrationEntry, error) {
+func updateRegistrationEntry(tx *gorm.DB, e *common.RegistrationEntry, mask *common.RegistrationEntryMask, maxEntryDNSNames int) (*common.RegistrationEntry, error) {
 	if err := validateRegistrationEntryForUpdate(e, mask); err != nil {
 		return nil, err
 	}
@@ -3900,82 +6577,117 @@ func updateRegistrationEntry(tx *gorm.DB, e *common.RegistrationEntry, mask *com
 	if err := tx.Find(&entry, "entry_id = ?", e.EntryId).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
+
+	// Only the columns named in the mask are written, so fields changed by
+	// concurrent updates that are not in the mask are preserved.
+	columns := make(map[string]any)
 	if mask == nil || mask.StoreSvid {
 		entry.StoreSvid = e.StoreSvid
+		columns["store_svid"] = e.StoreSvid
+	}
+
+	var selectors []Selector
+	if mask == nil || mask.Selectors {
+		for _, s := range uniqueSelectors(e.Selectors) {
+			selectors = append(selectors, Selector{
+				RegisteredEntryID: entry.ID,
+				Type:              s.Type,
+				Value:             s.Value,
+			})
+		}
+	} else if entry.StoreSvid {
+		if err := tx.Model(&entry).Related(&selectors).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+	}
+
+	// Verify that final selectors contains the same 'type' when entry is used for store SVIDs
+	if entry.StoreSvid && !equalSelectorTypes(selectors) {
+		return nil, newValidationError("invalid registration entry: selector types must be the same when store SVID is enabled")
 	}
+
 	if mask == nil || mask.Selectors {
 		// Delete existing selectors - we will write new ones
 		if err := tx.Exec("DELETE FROM selectors WHERE registered_entry_id = ?", entry.ID).Error; err != nil {
 			return nil, newWrappedSQLError(err)
 		}
-
-		selectors := []Selector{}
-		for _, s := range e.Selectors {
-			selector := Selector{
-				Type:  s.Type,
-				Value: s.Value,
+		for i := range selectors {
+			if err := tx.Create(&selectors[i]).Error; err != nil {
+				return nil, newWrappedSQLError(err)
 			}
-
-			selectors = append(selectors, selector)
 		}
-		entry.Selectors = selectors
-	}
-
-	// Verify that final selectors contains the same 'type' when entry is used for store SVIDs
-	if entry.StoreSvid && !equalSelectorTypes(entry.Selectors) {
-		return nil, newValidationError("invalid registration entry: selector types must be the same when store SVID is enabled")
 	}
 
 	if mask == nil || mask.DnsNames {
+		dnsNames, err := normalizeDNSNames(e.DnsNames)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkDNSNameCount(dnsNames, maxEntryDNSNames); err != nil {
+			return nil, err
+		}
+
 		// Delete existing DNSs - we will write new ones
 		if err := tx.Exec("DELETE FROM dns_names WHERE registered_entry_id = ?", entry.ID).Error; err != nil {
 			return nil, newWrappedSQLError(err)
 		}
-
-		dnsList := []DNSName{}
-		for _, d := range e.DnsNames {
-			dns := DNSName{
-				Value: d,
+		for _, d := range dnsNames {
+			if err := tx.Create(&DNSName{
+				RegisteredEntryID: entry.ID,
+				Value:             d,
+			}).Error; err != nil {
+				return nil, newWrappedSQLError(err)
 			}
-
-			dnsList = append(dnsList, dns)
 		}
-		entry.DNSList = dnsList
 	}
 
 	if mask == nil || mask.SpiffeId {
-		entry.SpiffeID = e.SpiffeId
+		columns["spiffe_id"] = e.SpiffeId
+		columns["trust_domain"] = trustDomainFromSPIFFEID(e.SpiffeId)
 	}
 	if mask == nil || mask.ParentId {
-		entry.ParentID = e.ParentId
+		columns["parent_id"] = e.ParentId
 	}
 	if mask == nil || mask.X509SvidTtl {
-		entry.TTL = e.X509SvidTtl
+		columns["ttl"] = e.X509SvidTtl
 	}
 	if mask == nil || mask.Admin {
-		entry.Admin = e.Admin
+		columns["admin"] = e.Admin
 	}
 	if mask == nil || mask.Downstream {
-		entry.Downstream = e.Downstream
+		columns["downstream"] = e.Downstream
 	}
 	if mask == nil || mask.EntryExpiry {
-		entry.Expiry = e.EntryExpiry
+		columns["expiry"] = e.EntryExpiry
 	}
 	if mask == nil || mask.JwtSvidTtl {
-		entry.JWTSvidTTL = e.JwtSvidTtl
+		columns["jwt_svid_ttl"] = e.JwtSvidTtl
 	}
 	if mask == nil || mask.Hint {
-		entry.Hint = e.Hint
+		columns["hint"] = e.Hint
+	}
+	if mask == nil || mask.FederatesWithAll {
+		columns["federates_with_all"] = e.FederatesWithAll
 	}
 
 	// Revision number is increased by 1 on every update call
-	entry.RevisionNumber++
+	columns["revision_number"] = gorm.Expr("revision_number + 1")
 
-	if err := tx.Save(&entry).Error; err != nil {
+	if err := tx.Model(&entry).Updates(columns).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	if err := tx.Find(&entry, "id = ?", entry.ID).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
-	if mask == nil || mask.FederatesWith {
+	switch {
+	case entry.FederatesWithAll:
+		// The federated trust domains are resolved when the entry is read,
+		// so the explicit list is not kept
+		if err := tx.Model(&entry).Association("FederatesWith").Clear().Error; err != nil {
+			return nil, err
+		}
+	case mask == nil || mask.FederatesWith:
 		federatesWith, err := makeFederatesWith(tx, e.FederatesWith)
 		if err != nil {
 			return nil, err
@@ -4014,6 +6726,34 @@ func deleteRegistrationEntry(tx *gorm.DB, entryID string) (*common.RegistrationE
 	return registrationEntry, nil
 }
 
+func setRegistrationEntryActive(tx *gorm.DB, entryID string, active bool) (*common.RegistrationEntry, error) {
+	var model RegisteredEntry
+	if err := tx.Find(&model, "entry_id = ?", entryID).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+
+	if model.Active != active {
+		// Revision number is increased by 1 like on any other update
+		if err := tx.Model(&model).Updates(map[string]any{
+			"active":          active,
+			"revision_number": gorm.Expr("revision_number + 1"),
+		}).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+		if err := tx.Find(&model, "id = ?", model.ID).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+		if err := createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entryID,
+		}); err != nil {
+			return nil, err
+		}
+		recordMutation(tx, MutationUpdate, EntityTypeRegistrationEntry, entryID)
+	}
+
+	return modelToEntry(tx, model)
+}
+
 func deleteRegistrationEntrySupport(tx *gorm.DB, entry RegisteredEntry) error {
 	if err := tx.Model(&entry).Association("FederatesWith").Clear().Error; err != nil {
 		return err
@@ -4038,7 +6778,7 @@ func deleteRegistrationEntrySupport(tx *gorm.DB, entry RegisteredEntry) error {
 
 func pruneRegistrationEntries(tx *gorm.DB, expiresBefore time.Time, logger logrus.FieldLogger) error {
 	var registrationEntries []RegisteredEntry
-	if err := tx.Where("expiry != 0").Where("expiry < ?", expiresBefore.Unix()).Find(&registrationEntries).Error; err != nil {
+	if err := expiredRegistrationEntries(tx, expiresBefore).Find(&registrationEntries).Error; err != nil {
 		return err
 	}
 
@@ -4051,14 +6791,161 @@ func pruneRegistrationEntries(tx *gorm.DB, expiresBefore time.Time, logger logru
 		}); err != nil {
 			return err
 		}
-		logger.WithFields(logrus.Fields{
-			telemetry.SPIFFEID:       entry.SpiffeID,
-			telemetry.ParentID:       entry.ParentID,
-			telemetry.RegistrationID: entry.EntryID,
-		}).Info("Pruned an expired registration")
+		recordMutation(tx, MutationDelete, EntityTypeRegistrationEntry, entry.EntryID)
+		logger.WithFields(logrus.Fields{
+			telemetry.SPIFFEID:       entry.SpiffeID,
+			telemetry.ParentID:       entry.ParentID,
+			telemetry.RegistrationID: entry.EntryID,
+		}).Info("Pruned an expired registration")
+	}
+
+	return nil
+}
+
+func listRegistrationEntriesToPrune(tx *gorm.DB, expiresBefore time.Time) ([]string, error) {
+	var entryIDs []string
+	if err := expiredRegistrationEntries(tx, expiresBefore).
+		Model(&RegisteredEntry{}).
+		Order("id").
+		Pluck("entry_id", &entryIDs).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	return entryIDs, nil
+}
+
+func listRegistrationEntriesWithExcessDNSNames(tx *gorm.DB, maxEntryDNSNames int) ([]string, error) {
+	var entryIDs []string
+	if err := tx.Model(&RegisteredEntry{}).
+		Where("id IN (SELECT registered_entry_id FROM dns_names GROUP BY registered_entry_id HAVING COUNT(*) > ?)", maxEntryDNSNames).
+		Order("id").
+		Pluck("entry_id", &entryIDs).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	return entryIDs, nil
+}
+
+func listRegistrationEntriesExpiringBefore(tx *gorm.DB, expiresBefore time.Time) ([]string, error) {
+	var entryIDs []string
+	if err := expiredRegistrationEntries(tx, expiresBefore).
+		Model(&RegisteredEntry{}).
+		Order("expiry, id").
+		Pluck("entry_id", &entryIDs).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	return entryIDs, nil
+}
+
+func findOrphanedEntryChildren(tx *gorm.DB, includeIDs bool) (*datastore.OrphanedEntryChildren, error) {
+	resp := new(datastore.OrphanedEntryChildren)
+	for _, children := range []struct {
+		model any
+		count *int
+		ids   *[]uint
+	}{
+		{model: &Selector{}, count: &resp.Selectors, ids: &resp.SelectorIDs},
+		{model: &DNSName{}, count: &resp.DNSNames, ids: &resp.DNSNameIDs},
+	} {
+		if err := orphanedEntryChildren(tx, children.model).Count(children.count).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+		if includeIDs && *children.count > 0 {
+			if err := orphanedEntryChildren(tx, children.model).Order("id").Pluck("id", children.ids).Error; err != nil {
+				return nil, newWrappedSQLError(err)
+			}
+		}
+	}
+	return resp, nil
+}
+
+func pruneOrphanedEntryChildren(tx *gorm.DB, model any, batchSize int) (int, error) {
+	var ids []uint
+	if err := orphanedEntryChildren(tx, model).Order("id").Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Rows are deleted by ID to avoid gap locks
+	if err := tx.Where("id IN (?)", ids).Delete(model).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	return len(ids), nil
+}
+
+func deleteDuplicateEntrySelectors(tx *gorm.DB, batchSize int) (int, error) {
+	var ids []uint
+	if err := tx.Model(&Selector{}).
+		Where(`EXISTS (SELECT 1 FROM selectors AS kept
+			WHERE kept.registered_entry_id = selectors.registered_entry_id
+			AND kept.type = selectors.type
+			AND kept.value = selectors.value
+			AND kept.id < selectors.id)`).
+		Order("id").Limit(batchSize).Pluck("id", &ids).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	// Rows are deleted by ID to avoid gap locks
+	if err := tx.Where("id IN (?)", ids).Delete(&Selector{}).Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	return len(ids), nil
+}
+
+// orphanedEntryChildren scopes a query to the rows of the given model, a
+// Selector or DNSName, whose registration entry does not exist
+func orphanedEntryChildren(tx *gorm.DB, model any) *gorm.DB {
+	table := tx.NewScope(model).TableName()
+	return tx.Model(model).Where(fmt.Sprintf(
+		"NOT EXISTS (SELECT 1 FROM registered_entries WHERE registered_entries.id = %s.registered_entry_id)", table))
+}
+
+// expiredRegistrationEntries scopes a query to the registration entries that
+// are pruned for having expired before the given time
+func expiredRegistrationEntries(tx *gorm.DB, expiresBefore time.Time) *gorm.DB {
+	return tx.Where("expiry != 0").Where("expiry < ?", expiresBefore.Unix())
+}
+
+func createBundleEvent(tx *gorm.DB, trustDomain string) error {
+	if err := tx.Create(&BundleEvent{
+		TrustDomain: trustDomain,
+	}).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	return nil
+}
+
+func listBundleEvents(tx *gorm.DB, req *datastore.ListBundleEventsRequest) (*datastore.ListBundleEventsResponse, error) {
+	var events []BundleEvent
+
+	if req.GreaterThanEventID != 0 || req.LessThanEventID != 0 {
+		query, id, err := buildListEventsQueryString(req.GreaterThanEventID, req.LessThanEventID)
+		if err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+
+		if err := tx.Order("id asc").Find(&events, query.String(), id).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+	} else {
+		if err := tx.Order("id asc").Find(&events).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
 	}
 
-	return nil
+	resp := &datastore.ListBundleEventsResponse{
+		Events: make([]datastore.BundleEvent, len(events)),
+	}
+	for i, event := range events {
+		resp.Events[i].EventID = event.ID
+		resp.Events[i].TrustDomain = event.TrustDomain
+	}
+
+	return resp, nil
 }
 
 func createRegistrationEntryEvent(tx *gorm.DB, event *datastore.RegistrationEntryEvent) error {
@@ -4107,17 +6994,30 @@ func listRegistrationEntryEvents(tx *gorm.DB, req *datastore.ListRegistrationEnt
 			return nil, newWrappedSQLError(err)
 		}
 
-		if err := tx.Find(&events, query.String(), id).Order("id asc").Error; err != nil {
+		if err := tx.Order("id asc").Find(&events, query.String(), id).Error; err != nil {
 			return nil, newWrappedSQLError(err)
 		}
 	} else {
-		if err := tx.Find(&events).Order("id asc").Error; err != nil {
+		if err := tx.Order("id asc").Find(&events).Error; err != nil {
 			return nil, newWrappedSQLError(err)
 		}
 	}
 
+	firstEventID, lastEventID, err := eventIDRange(tx, &RegisteredEntryEvent{})
+	if err != nil {
+		return nil, err
+	}
+
+	if req.RequireCompleteHistory && req.GreaterThanEventID != 0 {
+		if err := checkEventHistory(tx, &RegisteredEntry{}, req.GreaterThanEventID, firstEventID, lastEventID); err != nil {
+			return nil, err
+		}
+	}
+
 	resp := &datastore.ListRegistrationEntryEventsResponse{
-		Events: make([]datastore.RegistrationEntryEvent, len(events)),
+		FirstEventID: firstEventID,
+		LastEventID:  lastEventID,
+		Events:       make([]datastore.RegistrationEntryEvent, len(events)),
 	}
 	for i, event := range events {
 		resp.Events[i].EventID = event.ID
@@ -4135,6 +7035,55 @@ func pruneRegistrationEntryEvents(tx *gorm.DB, olderThan time.Duration) error {
 	return nil
 }
 
+// compactEvents deletes the events of the given table that are not the latest
+// one of the entity they are about, identified by entityColumn.
+func compactEvents(tx *gorm.DB, table, entityColumn string) (int, error) {
+	// MySQL can't delete from a table selected in a subquery, unless the
+	// subquery is materialized as a derived table
+	result := tx.Exec(fmt.Sprintf(`DELETE FROM %[1]s WHERE id NOT IN (
+	SELECT id FROM (SELECT MAX(id) AS id FROM %[1]s GROUP BY %[2]s) latest_events
+)`, table, entityColumn))
+	if err := result.Error; err != nil {
+		return 0, newWrappedSQLError(err)
+	}
+	return util.CheckedCast[int](result.RowsAffected)
+}
+
+// eventIDRange returns the lowest and highest IDs of the events stored in the
+// table of the given event model, or zeros if the table is empty.
+func eventIDRange(tx *gorm.DB, model any) (first, last uint, err error) {
+	var minID, maxID sql.NullInt64
+	if err := tx.Model(model).Select("MIN(id), MAX(id)").Row().Scan(&minID, &maxID); err != nil {
+		return 0, 0, newWrappedSQLError(err)
+	}
+	return uint(minID.Int64), uint(maxID.Int64), nil
+}
+
+// checkEventHistory returns ErrEventHistoryUnavailable if the stored events,
+// whose IDs range from firstEventID to lastEventID, may not include every
+// event after lastSeenEventID.
+func checkEventHistory(tx *gorm.DB, entityModel any, lastSeenEventID, firstEventID, lastEventID uint) error {
+	switch {
+	case firstEventID == 0:
+		// No events are stored. That is only expected when there is nothing
+		// the events could be about.
+		var ids []uint
+		if err := tx.Model(entityModel).Limit(1).Pluck("id", &ids).Error; err != nil {
+			return newWrappedSQLError(err)
+		}
+		if len(ids) > 0 {
+			return datastore.ErrEventHistoryUnavailable
+		}
+	case firstEventID > lastSeenEventID+1:
+		// Events following the last seen one were pruned
+		return datastore.ErrEventHistoryUnavailable
+	case lastEventID < lastSeenEventID:
+		// The event IDs were reset
+		return datastore.ErrEventHistoryUnavailable
+	}
+	return nil
+}
+
 func buildListEventsQueryString(greaterThanEventID, lessThanEventID uint) (*strings.Builder, uint, error) {
 	if greaterThanEventID != 0 && lessThanEventID != 0 {
 		return nil, 0, errors.New("can't set both greater and less than event id")
@@ -4157,8 +7106,12 @@ func buildListEventsQueryString(greaterThanEventID, lessThanEventID uint) (*stri
 
 func createJoinToken(tx *gorm.DB, token *datastore.JoinToken) error {
 	t := JoinToken{
-		Token:  token.Token,
-		Expiry: token.Expiry.Unix(),
+		Token:         token.Token,
+		Expiry:        token.Expiry.Unix(),
+		RemainingUses: token.RemainingUses,
+	}
+	if t.RemainingUses == 0 {
+		t.RemainingUses = 1
 	}
 
 	if err := tx.Create(&t).Error; err != nil {
@@ -4180,6 +7133,33 @@ func fetchJoinToken(tx *gorm.DB, token string) (*datastore.JoinToken, error) {
 	return modelToJoinToken(model), nil
 }
 
+func consumeJoinToken(tx *gorm.DB, token string) (*datastore.JoinToken, error) {
+	// Decrementing the uses only while some are left is what keeps concurrent
+	// consumers from using the token more times than it allows: the database
+	// lets a single one of them take each use.
+	result := tx.Model(&JoinToken{}).
+		Where("token = ? AND remaining_uses > 0", token).
+		UpdateColumn("remaining_uses", gorm.Expr("remaining_uses - 1"))
+	if result.Error != nil {
+		return nil, newWrappedSQLError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	var model JoinToken
+	if err := tx.Find(&model, "token = ?", token).Error; err != nil {
+		return nil, newWrappedSQLError(err)
+	}
+	if model.RemainingUses == 0 {
+		if err := tx.Delete(&model).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+	}
+
+	return modelToJoinToken(model), nil
+}
+
 func deleteJoinToken(tx *gorm.DB, token string) error {
 	var model JoinToken
 	if err := tx.Find(&model, "token = ?", token).Error; err != nil {
@@ -4201,11 +7181,12 @@ func pruneJoinTokens(tx *gorm.DB, expiresBefore time.Time) error {
 	return nil
 }
 
-func createFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelationship) (*datastore.FederationRelationship, error) {
+func createFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelationship, maxBundleSize int) (*datastore.FederationRelationship, error) {
 	model := FederatedTrustDomain{
 		TrustDomain:           fr.TrustDomain.Name(),
 		BundleEndpointURL:     fr.BundleEndpointURL.String(),
 		BundleEndpointProfile: string(fr.BundleEndpointProfile),
+		RefreshHint:           int64(fr.RefreshHint / time.Second),
 	}
 
 	if fr.BundleEndpointProfile == datastore.BundleEndpointSPIFFE {
@@ -4214,7 +7195,7 @@ func createFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelations
 
 	if fr.TrustDomainBundle != nil {
 		// overwrite current bundle
-		_, err := setBundle(tx, fr.TrustDomainBundle)
+		_, err := setBundle(tx, fr.TrustDomainBundle, maxBundleSize)
 		if err != nil {
 			return nil, fmt.Errorf("unable to set bundle: %w", err)
 		}
@@ -4238,6 +7219,35 @@ func deleteFederationRelationship(tx *gorm.DB, trustDomain spiffeid.TrustDomain)
 	return nil
 }
 
+func setFederationRelationshipLastPoll(tx *gorm.DB, trustDomain spiffeid.TrustDomain, polledAt, nextPollAt time.Time, pollErr string) error {
+	columns := map[string]any{
+		"last_poll_at":    polledAt,
+		"last_poll_error": truncatePollError(pollErr),
+	}
+	if !nextPollAt.IsZero() {
+		columns["next_poll_at"] = nextPollAt
+	}
+	if err := tx.Model(&FederatedTrustDomain{}).
+		Where("trust_domain = ?", trustDomain.Name()).
+		Updates(columns).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+	return nil
+}
+
+// truncatePollError truncates the error to maxPollErrorLength bytes, without
+// splitting a multi-byte character.
+func truncatePollError(pollErr string) string {
+	if len(pollErr) <= maxPollErrorLength {
+		return pollErr
+	}
+	pollErr = pollErr[:maxPollErrorLength]
+	for !utf8.ValidString(pollErr) {
+		pollErr = pollErr[:len(pollErr)-1]
+	}
+	return pollErr
+}
+
 func fetchFederationRelationship(tx *gorm.DB, trustDomain spiffeid.TrustDomain) (*datastore.FederationRelationship, error) {
 	var model FederatedTrustDomain
 	err := tx.Find(&model, "trust_domain = ?", trustDomain.Name()).Error
@@ -4257,17 +7267,31 @@ func listFederationRelationships(tx *gorm.DB, req *datastore.ListFederationRelat
 		return nil, status.Error(codes.InvalidArgument, "cannot paginate with pagesize = 0")
 	}
 
+	// The conditions are kept off tx, which is used to fetch the bundles of
+	// the relationships
+	query := tx
+	switch req.ByBundleEndpointProfile {
+	case "":
+	case datastore.BundleEndpointWeb, datastore.BundleEndpointSPIFFE:
+		query = query.Where("bundle_endpoint_profile = ?", req.ByBundleEndpointProfile)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown bundle endpoint profile %q", req.ByBundleEndpointProfile)
+	}
+	if req.ByImplicit != nil {
+		query = query.Where("implicit = ?", *req.ByImplicit)
+	}
+
 	p := req.Pagination
 	var err error
 	if p != nil {
-		tx, err = applyPagination(p, tx)
+		query, err = applyPagination(p, query)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	var federationRelationships []FederatedTrustDomain
-	if err := tx.Find(&federationRelationships).Error; err != nil {
+	if err := query.Find(&federationRelationships).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
@@ -4296,7 +7320,7 @@ func listFederationRelationships(tx *gorm.DB, req *datastore.ListFederationRelat
 	return resp, nil
 }
 
-func updateFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask) (*datastore.FederationRelationship, error) {
+func updateFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelationship, mask *types.FederationRelationshipMask, maxBundleSize int) (*datastore.FederationRelationship, error) {
 	var model FederatedTrustDomain
 	err := tx.Find(&model, "trust_domain = ?", fr.TrustDomain.Name()).Error
 	if err != nil {
@@ -4317,12 +7341,16 @@ func updateFederationRelationship(tx *gorm.DB, fr *datastore.FederationRelations
 
 	if mask.TrustDomainBundle && fr.TrustDomainBundle != nil {
 		// overwrite current bundle
-		_, err := setBundle(tx, fr.TrustDomainBundle)
+		_, err := setBundle(tx, fr.TrustDomainBundle, maxBundleSize)
 		if err != nil {
 			return nil, fmt.Errorf("unable to set bundle: %w", err)
 		}
 	}
 
+	if fr.RefreshHint != 0 {
+		model.RefreshHint = int64(fr.RefreshHint / time.Second)
+	}
+
 	if err := tx.Save(&model).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
@@ -4343,6 +7371,10 @@ func validateFederationRelationship(fr *datastore.FederationRelationship, mask *
 		return status.Error(codes.InvalidArgument, "bundle endpoint URL is required")
 	}
 
+	if fr.RefreshHint < 0 {
+		return status.Error(codes.InvalidArgument, "refresh hint cannot be negative")
+	}
+
 	if mask.BundleEndpointProfile {
 		switch fr.BundleEndpointProfile {
 		case datastore.BundleEndpointWeb:
@@ -4373,6 +7405,14 @@ func modelToFederationRelationship(tx *gorm.DB, model *FederatedTrustDomain) (*d
 		TrustDomain:           td,
 		BundleEndpointURL:     bundleEndpointURL,
 		BundleEndpointProfile: datastore.BundleEndpointType(model.BundleEndpointProfile),
+		RefreshHint:           time.Duration(model.RefreshHint) * time.Second,
+		LastPollError:         model.LastPollError,
+	}
+	if model.LastPollAt != nil {
+		fr.LastPollAt = *model.LastPollAt
+	}
+	if model.NextPollAt != nil {
+		fr.NextPollAt = *model.NextPollAt
 	}
 
 	switch fr.BundleEndpointProfile {
@@ -4404,9 +7444,86 @@ func modelToBundle(model *Bundle) (*common.Bundle, error) {
 		return nil, newWrappedSQLError(err)
 	}
 
+	bundle.LastRefreshedAt = 0
+	if model.LastRefreshedAt != nil {
+		bundle.LastRefreshedAt = model.LastRefreshedAt.Unix()
+	}
+
 	return bundle, nil
 }
 
+// modelToBundleMetadata returns the metadata of the given bundle model. The
+// bundle data is not read, so it may be left out of the model.
+func modelToBundleMetadata(model *Bundle) *datastore.BundleMetadata {
+	metadata := &datastore.BundleMetadata{
+		TrustDomainID: model.TrustDomain,
+		ContentHash:   model.ContentHash,
+	}
+	if model.LastRefreshedAt != nil {
+		metadata.LastRefreshedAt = *model.LastRefreshedAt
+	}
+	return metadata
+}
+
+// validateRegistrationEntryTrustDomain checks that the SPIFFE ID and parent
+// ID of the entry are members of the expected trust domain, if there is one.
+// IDs in a trust domain the entry federates with, and join token parent
+// IDs, are exempted.
+func validateRegistrationEntryTrustDomain(entry *common.RegistrationEntry, expectedTrustDomain spiffeid.TrustDomain) error {
+	if expectedTrustDomain.IsZero() {
+		return nil
+	}
+
+	isAllowed := func(id spiffeid.ID) bool {
+		if id.MemberOf(expectedTrustDomain) {
+			return true
+		}
+		for _, federatesWith := range entry.FederatesWith {
+			td, err := spiffeid.TrustDomainFromString(federatesWith)
+			if err == nil && id.MemberOf(td) {
+				return true
+			}
+		}
+		return false
+	}
+
+	spiffeID, err := spiffeid.FromString(entry.SpiffeId)
+	if err != nil {
+		return newValidationError("invalid registration entry: invalid SPIFFE ID: %v", err)
+	}
+	if !isAllowed(spiffeID) {
+		return newValidationError("invalid registration entry: SPIFFE ID %q is not a member of trust domain %q", entry.SpiffeId, expectedTrustDomain)
+	}
+
+	parentID, err := spiffeid.FromString(entry.ParentId)
+	if err != nil {
+		return newValidationError("invalid registration entry: invalid parent ID: %v", err)
+	}
+	if !isAllowed(parentID) && !strings.HasPrefix(parentID.Path(), joinTokenParentPathPrefix) {
+		return newValidationError("invalid registration entry: parent ID %q is not a member of trust domain %q", entry.ParentId, expectedTrustDomain)
+	}
+
+	return nil
+}
+
+// validateAttestedNodeTrustDomain checks that the SPIFFE ID of the node is a
+// member of the expected trust domain, if there is one.
+func validateAttestedNodeTrustDomain(node *common.AttestedNode, expectedTrustDomain spiffeid.TrustDomain) error {
+	if expectedTrustDomain.IsZero() {
+		return nil
+	}
+
+	spiffeID, err := spiffeid.FromString(node.SpiffeId)
+	if err != nil {
+		return newValidationError("invalid attested node: invalid SPIFFE ID: %v", err)
+	}
+	if !spiffeID.MemberOf(expectedTrustDomain) {
+		return newValidationError("invalid attested node: SPIFFE ID %q is not a member of trust domain %q", node.SpiffeId, expectedTrustDomain)
+	}
+
+	return nil
+}
+
 func validateRegistrationEntry(entry *common.RegistrationEntry) error {
 	if entry == nil {
 		return newValidationError("invalid request: missing registered entry")
@@ -4454,6 +7571,72 @@ func validateRegistrationEntry(entry *common.RegistrationEntry) error {
 	return nil
 }
 
+// normalizeDNSNames lowercases and trims the given DNS names, and removes
+// duplicates while preserving their order. Names that are not valid hostnames
+// or "*.domain" wildcards are all reported in a datastore.InvalidDNSNamesError,
+// which is returned as is to keep its type.
+func normalizeDNSNames(dnsNames []string) ([]string, error) {
+	if len(dnsNames) == 0 {
+		return dnsNames, nil
+	}
+
+	normalized := make([]string, 0, len(dnsNames))
+	seen := make(map[string]struct{}, len(dnsNames))
+	var invalid []string
+	for _, dnsName := range dnsNames {
+		name := strings.ToLower(strings.TrimSpace(dnsName))
+		if !isValidDNSName(name) {
+			invalid = append(invalid, dnsName)
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		normalized = append(normalized, name)
+	}
+
+	if len(invalid) > 0 {
+		return nil, &datastore.InvalidDNSNamesError{DNSNames: invalid}
+	}
+	return normalized, nil
+}
+
+// uniqueSelectors returns the given selectors without the repeated
+// (type, value) pairs, preserving their order.
+func uniqueSelectors(selectors []*common.Selector) []*common.Selector {
+	type selectorKey struct {
+		typ   string
+		value string
+	}
+
+	unique := make([]*common.Selector, 0, len(selectors))
+	seen := make(map[selectorKey]struct{}, len(selectors))
+	for _, s := range selectors {
+		key := selectorKey{typ: s.Type, value: s.Value}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, s)
+	}
+	return unique
+}
+
+// isValidDNSName returns whether the name is a hostname, or a wildcard made of
+// "*." followed by a hostname.
+func isValidDNSName(name string) bool {
+	if x509util.ValidateLabel(name) != nil {
+		return false
+	}
+	for _, label := range strings.Split(strings.TrimPrefix(name, "*."), ".") {
+		if !dnsLabelRegexp.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
 // equalSelectorTypes validates that all selectors has the same type,
 func equalSelectorTypes(selectors []Selector) bool {
 	typ := ""
@@ -4495,23 +7678,88 @@ func validateRegistrationEntryForUpdate(entry *common.RegistrationEntry, mask *c
 	return nil
 }
 
+// normalizeTrustDomainID returns the form trust domains are stored with, so
+// that lookups don't depend on the case they are written in.
+func normalizeTrustDomainID(trustDomainID string) string {
+	return strings.ToLower(trustDomainID)
+}
+
+// normalizeBundleTrustDomain returns the bundle with its trust domain
+// normalized. The bundle is only copied if its trust domain changes.
+func normalizeBundleTrustDomain(b *common.Bundle) *common.Bundle {
+	if b == nil || b.TrustDomainId == normalizeTrustDomainID(b.TrustDomainId) {
+		return b
+	}
+	b = proto.Clone(b).(*common.Bundle)
+	b.TrustDomainId = normalizeTrustDomainID(b.TrustDomainId)
+	return b
+}
+
 // bundleToModel converts the given Protobuf bundle message to a database model. It
 // performs validation, and fully parses certificates to form CACert embedded models.
 func bundleToModel(pb *common.Bundle) (*Bundle, error) {
 	if pb == nil {
 		return nil, newSQLError("missing bundle in request")
 	}
+
+	// The last refreshed timestamp is stored in its own column and is not
+	// part of the bundle data.
+	var lastRefreshedAt *time.Time
+	if pb.LastRefreshedAt != 0 {
+		lastRefreshedAt = new(time.Time)
+		*lastRefreshedAt = time.Unix(pb.LastRefreshedAt, 0)
+		pb = proto.Clone(pb).(*common.Bundle)
+		pb.LastRefreshedAt = 0
+	}
+
 	data, err := proto.Marshal(pb)
 	if err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
 	return &Bundle{
-		TrustDomain: pb.TrustDomainId,
-		Data:        data,
+		TrustDomain:     pb.TrustDomainId,
+		Data:            data,
+		ContentHash:     bundleContentHash(data),
+		LastRefreshedAt: lastRefreshedAt,
 	}, nil
 }
 
+// checkBundleSize fails with a datastore.BundleTooLargeError if the bundle data
+// is larger than maxBundleSize, instead of letting the database reject or
+// truncate it. A zero maxBundleSize disables the check, which is used when
+// pruning, tainting or revoking keys since those never grow the bundle.
+func checkBundleSize(model *Bundle, maxBundleSize int) error {
+	if maxBundleSize > 0 && len(model.Data) > maxBundleSize {
+		return &datastore.BundleTooLargeError{
+			TrustDomainID: model.TrustDomain,
+			Size:          len(model.Data),
+			MaxSize:       maxBundleSize,
+		}
+	}
+	return nil
+}
+
+// checkDNSNameCount fails with a datastore.TooManyDNSNamesError if there are
+// more DNS names than maxEntryDNSNames. The names are expected to be
+// normalized, so duplicates are only counted once.
+func checkDNSNameCount(dnsNames []string, maxEntryDNSNames int) error {
+	if len(dnsNames) > maxEntryDNSNames {
+		return &datastore.TooManyDNSNamesError{
+			Count:    len(dnsNames),
+			MaxCount: maxEntryDNSNames,
+		}
+	}
+	return nil
+}
+
+// bundleContentHash returns the hex encoded SHA-256 hash of the bundle data,
+// which matches bundleutil.ContentHash for the bundle.
+func bundleContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
 func modelToEntry(tx *gorm.DB, model RegisteredEntry) (*common.RegistrationEntry, error) {
 	var fetchedSelectors []*Selector
 	if err := tx.Model(&model).Related(&fetchedSelectors).Error; err != nil {
@@ -4549,25 +7797,108 @@ func modelToEntry(tx *gorm.DB, model RegisteredEntry) (*common.RegistrationEntry
 		federatesWith = append(federatesWith, bundle.TrustDomain)
 	}
 
+	if model.FederatesWithAll {
+		var trustDomains []string
+		if err := tx.Model(&Bundle{}).Order("trust_domain").Pluck("trust_domain", &trustDomains).Error; err != nil {
+			return nil, newWrappedSQLError(err)
+		}
+		federatesWith = federatedTrustDomainsOf(model.SpiffeID, trustDomains)
+	}
+
+	var idempotencyKey string
+	if model.IdempotencyKey != nil {
+		idempotencyKey = *model.IdempotencyKey
+	}
+
 	return &common.RegistrationEntry{
-		EntryId:        model.EntryID,
-		Selectors:      selectors,
-		SpiffeId:       model.SpiffeID,
-		ParentId:       model.ParentID,
-		X509SvidTtl:    model.TTL,
-		FederatesWith:  federatesWith,
-		Admin:          model.Admin,
-		Downstream:     model.Downstream,
-		EntryExpiry:    model.Expiry,
-		DnsNames:       dnsList,
-		RevisionNumber: model.RevisionNumber,
-		StoreSvid:      model.StoreSvid,
-		JwtSvidTtl:     model.JWTSvidTTL,
-		Hint:           model.Hint,
-		CreatedAt:      roundedInSecondsUnix(model.CreatedAt),
+		EntryId:          model.EntryID,
+		Selectors:        selectors,
+		SpiffeId:         model.SpiffeID,
+		ParentId:         model.ParentID,
+		X509SvidTtl:      model.TTL,
+		FederatesWith:    federatesWith,
+		Admin:            model.Admin,
+		Downstream:       model.Downstream,
+		EntryExpiry:      model.Expiry,
+		DnsNames:         dnsList,
+		RevisionNumber:   model.RevisionNumber,
+		StoreSvid:        model.StoreSvid,
+		JwtSvidTtl:       model.JWTSvidTTL,
+		Hint:             model.Hint,
+		CreatedAt:        roundedInSecondsUnix(model.CreatedAt),
+		CreatedBy:        model.CreatedBy,
+		IdempotencyKey:   idempotencyKey,
+		Inactive:         !model.Active,
+		FederatesWithAll: model.FederatesWithAll,
 	}, nil
 }
 
+// resolveFederatesWithAll sets the federated trust domains of the entries
+// that federate with all trust domains, from the bundles currently stored.
+func resolveFederatesWithAll(ctx context.Context, db queryContext, entries []*common.RegistrationEntry) error {
+	if !slices.ContainsFunc(entries, (*common.RegistrationEntry).GetFederatesWithAll) {
+		return nil
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT trust_domain FROM bundles ORDER BY trust_domain")
+	if err != nil {
+		return newWrappedSQLError(err)
+	}
+	defer rows.Close()
+
+	var trustDomains []string
+	for rows.Next() {
+		var trustDomain string
+		if err := rows.Scan(&trustDomain); err != nil {
+			return newWrappedSQLError(err)
+		}
+		trustDomains = append(trustDomains, trustDomain)
+	}
+	if err := rows.Err(); err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	for _, entry := range entries {
+		if entry.FederatesWithAll {
+			entry.FederatesWith = federatedTrustDomainsOf(entry.SpiffeId, trustDomains)
+		}
+	}
+	return nil
+}
+
+// federatedTrustDomainsOf returns the trust domains that an entry federating
+// with all trust domains federates with, i.e. all of them but its own.
+func federatedTrustDomainsOf(spiffeID string, trustDomains []string) []string {
+	ownTrustDomain := "spiffe://" + trustDomainFromSPIFFEID(spiffeID)
+
+	var federatesWith []string
+	for _, trustDomain := range trustDomains {
+		if trustDomain != ownTrustDomain {
+			federatesWith = append(federatesWith, trustDomain)
+		}
+	}
+	return federatesWith
+}
+
+// createFederatesWithAllEntryEvents creates an event for each entry that
+// federates with all trust domains. The trust domains of these entries change
+// with the bundles stored, without the entries being updated.
+func createFederatesWithAllEntryEvents(tx *gorm.DB) error {
+	var entryIDs []string
+	if err := tx.Model(&RegisteredEntry{}).Where("federates_with_all = ?", true).Pluck("entry_id", &entryIDs).Error; err != nil {
+		return newWrappedSQLError(err)
+	}
+
+	for _, entryID := range entryIDs {
+		if err := createRegistrationEntryEvent(tx, &datastore.RegistrationEntryEvent{
+			EntryID: entryID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func createOrReturnEntryID(entry *common.RegistrationEntry) (string, error) {
 	if entry.EntryId != "" {
 		return entry.EntryId, nil
@@ -4593,13 +7924,16 @@ func modelToAttestedNode(model AttestedNode) *common.AttestedNode {
 		NewCertSerialNumber: model.NewSerialNumber,
 		NewCertNotAfter:     nullableDBTimeToUnixTime(model.NewExpiresAt),
 		CanReattest:         model.CanReattest,
+		AttestedAt:          model.AttestedAt.Unix(),
+		LastAttestedAt:      model.LastAttestedAt.Unix(),
 	}
 }
 
 func modelToJoinToken(model JoinToken) *datastore.JoinToken {
 	return &datastore.JoinToken{
-		Token:  model.Token,
-		Expiry: time.Unix(model.Expiry, 0),
+		Token:         model.Token,
+		Expiry:        time.Unix(model.Expiry, 0),
+		RemainingUses: model.RemainingUses,
 	}
 }
 
@@ -4612,6 +7946,12 @@ func modelToCAJournal(model CAJournal) *datastore.CAJournal {
 }
 
 func makeFederatesWith(tx *gorm.DB, ids []string) ([]*Bundle, error) {
+	normalized := make([]string, 0, len(ids))
+	for _, id := range ids {
+		normalized = append(normalized, normalizeTrustDomainID(id))
+	}
+	ids = normalized
+
 	var bundles []*Bundle
 	if err := tx.Where("trust_domain in (?)", ids).Find(&bundles).Error; err != nil {
 		return nil, err
@@ -4664,6 +8004,10 @@ func (cfg *configuration) Validate() error {
 	}
 
 	if isMySQLDbType(cfg.databaseTypeConfig.databaseType) {
+		if err := validateMySQLTLSConfig(cfg); err != nil {
+			return err
+		}
+
 		if err := validateMySQLConfig(cfg, false); err != nil {
 			return err
 		}
@@ -4675,6 +8019,42 @@ func (cfg *configuration) Validate() error {
 		}
 	}
 
+	if cfg.MaxOpenConns != nil && *cfg.MaxOpenConns < 0 {
+		return newSQLError("max_open_conns cannot be negative")
+	}
+
+	if cfg.MaxIdleConns != nil && *cfg.MaxIdleConns < 0 {
+		return newSQLError("max_idle_conns cannot be negative")
+	}
+
+	if cfg.PruneBatchSize != nil && *cfg.PruneBatchSize <= 0 {
+		return newSQLError("prune_batch_size must be greater than zero")
+	}
+
+	if cfg.MaxPageSize != nil && (*cfg.MaxPageSize <= 0 || *cfg.MaxPageSize > math.MaxInt32) {
+		return newSQLError("max_page_size must be greater than zero and fit in 32 bits")
+	}
+
+	if cfg.NodeSerialHistorySize != nil && *cfg.NodeSerialHistorySize <= 0 {
+		return newSQLError("node_serial_history_size must be greater than zero")
+	}
+
+	if cfg.MaxBundleSize != nil && *cfg.MaxBundleSize <= 0 {
+		return newSQLError("max_bundle_size must be greater than zero")
+	}
+
+	if cfg.MaxEntryDNSNames != nil && *cfg.MaxEntryDNSNames <= 0 {
+		return newSQLError("max_dns_names_per_entry must be greater than zero")
+	}
+
+	if cfg.TxRetryMaxAttempts != nil && *cfg.TxRetryMaxAttempts <= 0 {
+		return newSQLError("tx_retry_max_attempts must be greater than zero")
+	}
+
+	if cfg.SQLiteBusyTimeout != nil && *cfg.SQLiteBusyTimeout < 0 {
+		return newSQLError("sqlite_busy_timeout cannot be negative")
+	}
+
 	if cfg.databaseTypeConfig.AWSMySQL != nil {
 		if err := cfg.databaseTypeConfig.AWSMySQL.validate(); err != nil {
 			return err
@@ -4690,6 +8070,20 @@ func (cfg *configuration) Validate() error {
 	return nil
 }
 
+// sqliteWALMode returns whether the SQLite database uses the WAL journal mode,
+// which is the default.
+func (cfg *configuration) sqliteWALMode() bool {
+	return cfg.SQLiteWALMode == nil || *cfg.SQLiteWALMode
+}
+
+// sqliteBusyTimeout returns the SQLite busy timeout, in milliseconds.
+func (cfg *configuration) sqliteBusyTimeout() int {
+	if cfg.SQLiteBusyTimeout != nil {
+		return *cfg.SQLiteBusyTimeout
+	}
+	return defaultSQLiteBusyTimeout
+}
+
 // getConnectionString returns the connection string corresponding to the database connection.
 func getConnectionString(cfg *configuration, isReadOnly bool) string {
 	connectionString := cfg.ConnectionString
@@ -4750,6 +8144,20 @@ func lookupSimilarEntry(ctx context.Context, db *sqlDB, tx *gorm.DB, entry *comm
 	return nil, nil
 }
 
+// lookupEntryByIdempotencyKey returns the entry created with the given
+// idempotency key, or nil if there is none.
+func lookupEntryByIdempotencyKey(tx *gorm.DB, idempotencyKey string) (*common.RegistrationEntry, error) {
+	var model RegisteredEntry
+	err := tx.Find(&model, "idempotency_key = ?", idempotencyKey).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, newWrappedSQLError(err)
+	}
+	return modelToEntry(tx, model)
+}
+
 // roundedInSecondsUnix rounds the time to the nearest second, and return the time in seconds since the
 // unix epoch. This function is used to avoid issues with databases versions that do not support sub-second precision.
 func roundedInSecondsUnix(t time.Time) int64 {
@@ -4761,12 +8169,17 @@ func createCAJournal(tx *gorm.DB, caJournal *datastore.CAJournal) (*datastore.CA
 		Data:                  caJournal.Data,
 		ActiveX509AuthorityID: caJournal.ActiveX509AuthorityID,
 	}
+	sealed, err := sealCAJournal(tx, &model)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := tx.Create(&model).Error; err != nil {
+	if err := tx.Create(sealed).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
-	return modelToCAJournal(model), nil
+	sealed.Data = model.Data
+	return modelToCAJournal(*sealed), nil
 }
 
 func fetchCAJournal(tx *gorm.DB, activeX509AuthorityID string) (*datastore.CAJournal, error) {
@@ -4778,6 +8191,9 @@ func fetchCAJournal(tx *gorm.DB, activeX509AuthorityID string) (*datastore.CAJou
 	case err != nil:
 		return nil, newWrappedSQLError(err)
 	}
+	if err := openCAJournal(tx, &model); err != nil {
+		return nil, err
+	}
 
 	return modelToCAJournal(model), nil
 }
@@ -4789,6 +8205,9 @@ func listCAJournalsForTesting(tx *gorm.DB) (caJournals []*datastore.CAJournal, e
 	}
 
 	for _, model := range caJournalsModel {
+		if err := openCAJournal(tx, &model); err != nil {
+			return nil, err
+		}
 		caJournals = append(caJournals, modelToCAJournal(model))
 	}
 	return caJournals, nil
@@ -4802,12 +8221,17 @@ func updateCAJournal(tx *gorm.DB, caJournal *datastore.CAJournal) (*datastore.CA
 
 	model.ActiveX509AuthorityID = caJournal.ActiveX509AuthorityID
 	model.Data = caJournal.Data
+	sealed, err := sealCAJournal(tx, &model)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := tx.Save(&model).Error; err != nil {
+	if err := tx.Save(sealed).Error; err != nil {
 		return nil, newWrappedSQLError(err)
 	}
 
-	return modelToCAJournal(model), nil
+	sealed.Data = model.Data
+	return modelToCAJournal(*sealed), nil
 }
 
 func validateCAJournal(caJournal *datastore.CAJournal) error {
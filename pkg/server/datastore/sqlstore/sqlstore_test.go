@@ -12,13 +12,19 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/blang/semver/v4"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -26,12 +32,14 @@ import (
 	"github.com/spiffe/spire/pkg/common/bundleutil"
 	"github.com/spiffe/spire/pkg/common/protoutil"
 	"github.com/spiffe/spire/pkg/common/telemetry"
+	ds_telemetry "github.com/spiffe/spire/pkg/common/telemetry/server/datastore"
 	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/common/x509util"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/private/server/journal"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
+	"github.com/spiffe/spire/test/fakes/fakemetrics"
 	"github.com/spiffe/spire/test/spiretest"
 	"github.com/spiffe/spire/test/testkey"
 	testutil "github.com/spiffe/spire/test/util"
@@ -195,6 +203,167 @@ func (s *PluginSuite) TestInvalidPluginConfiguration() {
 		connection_string = "bad"
 	`)
 	s.RequireErrorContains(err, "datastore-sql: unsupported database_type: wrong")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		prune_batch_size = 0
+	`)
+	s.RequireErrorContains(err, "datastore-sql: prune_batch_size must be greater than zero")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		max_page_size = 0
+	`)
+	s.RequireErrorContains(err, "datastore-sql: max_page_size must be greater than zero")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		node_serial_history_size = 0
+	`)
+	s.RequireErrorContains(err, "datastore-sql: node_serial_history_size must be greater than zero")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		max_bundle_size = 0
+	`)
+	s.RequireErrorContains(err, "datastore-sql: max_bundle_size must be greater than zero")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		max_dns_names_per_entry = 0
+	`)
+	s.RequireErrorContains(err, "datastore-sql: max_dns_names_per_entry must be greater than zero")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		tx_retry_max_attempts = 0
+	`)
+	s.RequireErrorContains(err, "datastore-sql: tx_retry_max_attempts must be greater than zero")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		sqlite_busy_timeout = -1
+	`)
+	s.RequireErrorContains(err, "datastore-sql: sqlite_busy_timeout cannot be negative")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		tx_retry_base_delay = "soon"
+	`)
+	s.RequireErrorContains(err, `datastore-sql: failed to parse tx_retry_base_delay "soon"`)
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		tx_retry_base_delay = "-1s"
+	`)
+	s.RequireErrorContains(err, "datastore-sql: tx_retry_base_delay cannot be negative")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		event_retention = "forever"
+	`)
+	s.RequireErrorContains(err, `datastore-sql: failed to parse event_retention "forever"`)
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		event_retention = "-1h"
+	`)
+	s.RequireErrorContains(err, "datastore-sql: event_retention cannot be negative")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		connection_stats_period = "0s"
+	`)
+	s.RequireErrorContains(err, "datastore-sql: connection_stats_period must be greater than zero")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		migration_lock_timeout = "soon"
+	`)
+	s.RequireErrorContains(err, `datastore-sql: failed to parse migration_lock_timeout "soon"`)
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		migration_lock_timeout = "0s"
+	`)
+	s.RequireErrorContains(err, "datastore-sql: migration_lock_timeout must be greater than zero")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		statement_timeout = "soon"
+	`)
+	s.RequireErrorContains(err, `datastore-sql: failed to parse statement_timeout "soon"`)
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		statement_timeout = "-1s"
+	`)
+	s.RequireErrorContains(err, "datastore-sql: statement_timeout cannot be negative")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		max_open_conns = -1
+	`)
+	s.RequireErrorContains(err, "datastore-sql: max_open_conns cannot be negative")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		max_idle_conns = -1
+	`)
+	s.RequireErrorContains(err, "datastore-sql: max_idle_conns cannot be negative")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		conn_max_lifetime = "forever"
+	`)
+	s.RequireErrorContains(err, `datastore-sql: failed to parse conn_max_lifetime "forever"`)
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		conn_max_lifetime = "-1s"
+	`)
+	s.RequireErrorContains(err, "datastore-sql: conn_max_lifetime cannot be negative")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		conn_max_idle_time = "forever"
+	`)
+	s.RequireErrorContains(err, `datastore-sql: failed to parse conn_max_idle_time "forever"`)
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		conn_max_idle_time = "-1s"
+	`)
+	s.RequireErrorContains(err, "datastore-sql: conn_max_idle_time cannot be negative")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		expected_trust_domain = "Example.org"
+	`)
+	s.RequireErrorContains(err, `datastore-sql: failed to parse expected_trust_domain "Example.org"`)
 }
 
 func (s *PluginSuite) TestInvalidAWSConfiguration() {
@@ -243,6 +412,13 @@ func (s *PluginSuite) TestInvalidMySQLConfiguration() {
 		database_type = "mysql"
 	`)
 	s.RequireErrorContains(err, "datastore-sql: connection_string must be set")
+
+	err = s.ds.Configure(ctx, `
+		database_type = "sqlite3"
+		connection_string = "bad"
+		encryption_key_file = "/does/not/exist"
+	`)
+	s.RequireErrorContains(err, "datastore-sql: failed to read encryption key file")
 }
 
 func (s *PluginSuite) TestBundleCRUD() {
@@ -412,7 +588,11 @@ func (s *PluginSuite) TestListBundlesWithPagination() {
 			pagination: &datastore.Pagination{
 				PageSize: 0,
 			},
-			expectedErr: "rpc error: code = InvalidArgument desc = cannot paginate with pagesize = 0",
+			expectedList: []*common.Bundle{bundle1, bundle2, bundle3, bundle4},
+			expectedPagination: &datastore.Pagination{
+				Token:    "4",
+				PageSize: defaultMaxPageSize,
+			},
 		},
 		{
 			name: "bundles first page",
@@ -481,6 +661,62 @@ func (s *PluginSuite) TestListBundlesWithPagination() {
 	}
 }
 
+func (s *PluginSuite) TestListBundlesExcludeData() {
+	refreshedAt := time.Unix(1700000000, 0)
+	refreshed := bundleutil.BundleProtoFromRootCA("spiffe://example.org", s.cert)
+	refreshed.LastRefreshedAt = refreshedAt.Unix()
+	_, err := s.ds.CreateBundle(ctx, refreshed)
+	s.Require().NoError(err)
+	_, err = s.ds.CreateBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cacert))
+	s.Require().NoError(err)
+
+	listTrustDomains := func(excludeData bool, pageSize int32) (trustDomains []string) {
+		req := &datastore.ListBundlesRequest{ExcludeData: excludeData}
+		if pageSize > 0 {
+			req.Pagination = &datastore.Pagination{PageSize: pageSize}
+		}
+		for {
+			resp, err := s.ds.ListBundles(ctx, req)
+			s.Require().NoError(err)
+			if excludeData {
+				s.Require().Nil(resp.Bundles)
+				for _, metadata := range resp.Metadata {
+					trustDomains = append(trustDomains, metadata.TrustDomainID)
+				}
+			} else {
+				s.Require().Nil(resp.Metadata)
+				for _, bundle := range resp.Bundles {
+					s.Require().NotEmpty(bundle.RootCas)
+					trustDomains = append(trustDomains, bundle.TrustDomainId)
+				}
+			}
+			if resp.Pagination == nil || resp.Pagination.Token == "" {
+				return trustDomains
+			}
+			req.Pagination = resp.Pagination
+		}
+	}
+
+	// Both modes list the same trust domains, with and without pagination
+	expected := []string{"spiffe://example.org", "spiffe://foo"}
+	s.Equal(expected, listTrustDomains(false, 0))
+	s.Equal(expected, listTrustDomains(true, 0))
+	s.Equal(expected, listTrustDomains(false, 1))
+	s.Equal(expected, listTrustDomains(true, 1))
+
+	// The metadata matches the stored bundles
+	resp, err := s.ds.ListBundles(ctx, &datastore.ListBundlesRequest{ExcludeData: true})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Metadata, 2)
+	for _, metadata := range resp.Metadata {
+		hash, err := s.ds.FetchBundleContentHash(ctx, metadata.TrustDomainID)
+		s.Require().NoError(err)
+		s.Equal(hash, metadata.ContentHash)
+	}
+	s.True(refreshedAt.Equal(resp.Metadata[0].LastRefreshedAt))
+	s.True(resp.Metadata[1].LastRefreshedAt.IsZero())
+}
+
 func (s *PluginSuite) TestCountBundles() {
 	// Count empty bundles
 	count, err := s.ds.CountBundles(ctx)
@@ -566,6 +802,201 @@ func (s *PluginSuite) TestCountRegistrationEntries() {
 	s.Require().Equal(int32(2), count)
 }
 
+func (s *PluginSuite) TestCountAttestedNodesMatchesList() {
+	now := time.Now()
+	for _, node := range []*common.AttestedNode{
+		{SpiffeId: "spiffe://example.org/node1", AttestationDataType: "t1", CertSerialNumber: "1", CertNotAfter: now.Add(time.Hour).Unix()},
+		{SpiffeId: "spiffe://example.org/node2", AttestationDataType: "t1", CertSerialNumber: "2", CertNotAfter: now.Add(-time.Hour).Unix(), CanReattest: true},
+		{SpiffeId: "spiffe://example.org/node3", AttestationDataType: "t2", CertSerialNumber: "", CertNotAfter: now.Add(time.Hour).Unix()},
+		{SpiffeId: "spiffe://example.org/node4", AttestationDataType: "t2", CertSerialNumber: "4", CertNotAfter: now.Add(2 * time.Hour).Unix(), CanReattest: true},
+		{SpiffeId: "spiffe://example.org/node5", AttestationDataType: "t1", CertSerialNumber: "5", CertNotAfter: now.Add(time.Hour).Unix()},
+	} {
+		_, err := s.ds.CreateAttestedNode(ctx, node)
+		s.Require().NoError(err)
+	}
+	s.setNodeSelectors("spiffe://example.org/node1", []*common.Selector{{Type: "a", Value: "1"}})
+	s.setNodeSelectors("spiffe://example.org/node2", []*common.Selector{{Type: "a", Value: "1"}, {Type: "b", Value: "2"}})
+	s.setNodeSelectors("spiffe://example.org/node3", []*common.Selector{{Type: "a", Value: "1"}, {Type: "b", Value: "2"}, {Type: "c", Value: "3"}})
+	s.setNodeSelectors("spiffe://example.org/node4", []*common.Selector{{Type: "b", Value: "2"}})
+
+	bySelectors := func(match datastore.MatchBehavior, selectors ...*common.Selector) *datastore.BySelectors {
+		return &datastore.BySelectors{Match: match, Selectors: selectors}
+	}
+	a1 := &common.Selector{Type: "a", Value: "1"}
+	b2 := &common.Selector{Type: "b", Value: "2"}
+	yes, no := true, false
+
+	for _, tt := range []struct {
+		name string
+		req  *datastore.CountAttestedNodesRequest
+	}{
+		{name: "no filter", req: &datastore.CountAttestedNodesRequest{}},
+		{name: "by attestation type", req: &datastore.CountAttestedNodesRequest{ByAttestationType: "t1"}},
+		{name: "by banned", req: &datastore.CountAttestedNodesRequest{ByBanned: &yes}},
+		{name: "by not banned", req: &datastore.CountAttestedNodesRequest{ByBanned: &no}},
+		{name: "by can reattest", req: &datastore.CountAttestedNodesRequest{ByCanReattest: &yes}},
+		{name: "by expiry window", req: &datastore.CountAttestedNodesRequest{ByExpiresAfter: now, ByExpiresBefore: now.Add(90 * time.Minute)}},
+		{name: "by selector match any", req: &datastore.CountAttestedNodesRequest{BySelectorMatch: bySelectors(datastore.MatchAny, a1, b2)}},
+		{name: "by selector superset", req: &datastore.CountAttestedNodesRequest{BySelectorMatch: bySelectors(datastore.Superset, a1, b2)}},
+		{name: "by selector match all", req: &datastore.CountAttestedNodesRequest{BySelectorMatch: bySelectors(datastore.MatchAll, a1, b2)}},
+		{name: "by selector subset", req: &datastore.CountAttestedNodesRequest{BySelectorMatch: bySelectors(datastore.Subset, a1, b2)}},
+		{name: "by selector exact", req: &datastore.CountAttestedNodesRequest{BySelectorMatch: bySelectors(datastore.Exact, a1, b2)}},
+		{name: "by selector exact with duplicates", req: &datastore.CountAttestedNodesRequest{BySelectorMatch: bySelectors(datastore.Exact, a1, a1)}},
+		{name: "by selector and attestation type", req: &datastore.CountAttestedNodesRequest{ByAttestationType: "t2", BySelectorMatch: bySelectors(datastore.MatchAny, b2)}},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			count, err := s.ds.CountAttestedNodes(ctx, tt.req)
+			require.NoError(t, err)
+
+			listReq := &datastore.ListAttestedNodesRequest{
+				ByAttestationType: tt.req.ByAttestationType,
+				ByBanned:          tt.req.ByBanned,
+				ByExpiresBefore:   tt.req.ByExpiresBefore,
+				ByExpiresAfter:    tt.req.ByExpiresAfter,
+				BySelectorMatch:   tt.req.BySelectorMatch,
+				ByCanReattest:     tt.req.ByCanReattest,
+				Pagination:        &datastore.Pagination{PageSize: 2},
+			}
+			var listed int
+			for {
+				resp, err := s.ds.ListAttestedNodes(ctx, listReq)
+				require.NoError(t, err)
+				listed += len(resp.Nodes)
+				if resp.Pagination == nil || resp.Pagination.Token == "" {
+					break
+				}
+				listReq.Pagination = resp.Pagination
+			}
+			require.Equal(t, int32(listed), count)
+		})
+	}
+}
+
+func (s *PluginSuite) TestCountRegistrationEntriesMatchesList() {
+	s.createBundle("spiffe://td1.org")
+	s.createBundle("spiffe://td2.org")
+
+	a1 := &common.Selector{Type: "a", Value: "1"}
+	b2 := &common.Selector{Type: "b", Value: "2"}
+	c3 := &common.Selector{Type: "c", Value: "3"}
+	for _, entry := range []*common.RegistrationEntry{
+		{ParentId: "spiffe://example.org/agent1", SpiffeId: "spiffe://example.org/w1", Selectors: []*common.Selector{a1}, Admin: true},
+		{ParentId: "spiffe://example.org/agent1", SpiffeId: "spiffe://example.org/w2", Selectors: []*common.Selector{a1, b2}, FederatesWith: []string{"spiffe://td1.org"}},
+		{ParentId: "spiffe://example.org/agent1", SpiffeId: "spiffe://example.org/w3", Selectors: []*common.Selector{a1, b2, c3}, FederatesWith: []string{"spiffe://td1.org", "spiffe://td2.org"}},
+		{ParentId: "spiffe://example.org/agent2", SpiffeId: "spiffe://example.org/w4", Selectors: []*common.Selector{b2}, Hint: "internal", Downstream: true},
+		{ParentId: "spiffe://example.org/agent2", SpiffeId: "spiffe://example.org/w5", Selectors: []*common.Selector{{Type: "k8s", Value: "ns:foo"}}, StoreSvid: true},
+		{ParentId: "spiffe://example.org/agent2", SpiffeId: "spiffe://example.org/w6", Selectors: []*common.Selector{{Type: "k8s", Value: "ns:bar"}, a1}},
+	} {
+		s.createRegistrationEntry(entry)
+	}
+
+	bySelectors := func(match datastore.MatchBehavior, selectors ...*common.Selector) *datastore.BySelectors {
+		return &datastore.BySelectors{Match: match, Selectors: selectors}
+	}
+	byFederatesWith := func(match datastore.MatchBehavior, trustDomains ...string) *datastore.ByFederatesWith {
+		return &datastore.ByFederatesWith{Match: match, TrustDomains: trustDomains}
+	}
+	admin := true
+	hint := "internal"
+
+	for _, tt := range []struct {
+		name string
+		req  *datastore.CountRegistrationEntriesRequest
+	}{
+		{name: "no filter", req: &datastore.CountRegistrationEntriesRequest{}},
+		{name: "by parent ID", req: &datastore.CountRegistrationEntriesRequest{ByParentID: "spiffe://example.org/agent1"}},
+		{name: "by SPIFFE ID", req: &datastore.CountRegistrationEntriesRequest{BySpiffeID: "spiffe://example.org/w4"}},
+		{name: "by admin", req: &datastore.CountRegistrationEntriesRequest{ByAdmin: &admin}},
+		{name: "by hint", req: &datastore.CountRegistrationEntriesRequest{ByHint: &hint}},
+		{name: "by selector match any", req: &datastore.CountRegistrationEntriesRequest{BySelectors: bySelectors(datastore.MatchAny, a1, b2)}},
+		{name: "by selector superset", req: &datastore.CountRegistrationEntriesRequest{BySelectors: bySelectors(datastore.Superset, a1, b2)}},
+		{name: "by selector subset", req: &datastore.CountRegistrationEntriesRequest{BySelectors: bySelectors(datastore.Subset, a1, b2)}},
+		{name: "by selector exact", req: &datastore.CountRegistrationEntriesRequest{BySelectors: bySelectors(datastore.Exact, a1, b2)}},
+		{name: "by selector exact and parent ID", req: &datastore.CountRegistrationEntriesRequest{ByParentID: "spiffe://example.org/agent2", BySelectors: bySelectors(datastore.Exact, b2)}},
+		{name: "by selector value prefix", req: &datastore.CountRegistrationEntriesRequest{BySelectorValuePrefix: &datastore.BySelectorValuePrefix{Type: "k8s", Prefix: "ns:"}}},
+		{name: "by federates with subset", req: &datastore.CountRegistrationEntriesRequest{ByFederatesWith: byFederatesWith(datastore.Subset, "spiffe://td1.org")}},
+		{name: "by federates with match any and selectors", req: &datastore.CountRegistrationEntriesRequest{ByFederatesWith: byFederatesWith(datastore.MatchAny, "spiffe://td2.org"), BySelectors: bySelectors(datastore.Superset, c3)}},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			count, err := s.ds.CountRegistrationEntries(ctx, tt.req)
+			require.NoError(t, err)
+
+			listReq := &datastore.ListRegistrationEntriesRequest{
+				ByParentID:            tt.req.ByParentID,
+				BySelectors:           tt.req.BySelectors,
+				BySpiffeID:            tt.req.BySpiffeID,
+				ByFederatesWith:       tt.req.ByFederatesWith,
+				ByHint:                tt.req.ByHint,
+				ByAdmin:               tt.req.ByAdmin,
+				BySelectorValuePrefix: tt.req.BySelectorValuePrefix,
+				Pagination:            &datastore.Pagination{PageSize: 2},
+			}
+			var listed int
+			for {
+				resp, err := s.ds.ListRegistrationEntries(ctx, listReq)
+				require.NoError(t, err)
+				listed += len(resp.Entries)
+				if resp.Pagination == nil || resp.Pagination.Token == "" {
+					break
+				}
+				listReq.Pagination = resp.Pagination
+			}
+			require.Equal(t, int32(listed), count)
+		})
+	}
+}
+
+func (s *PluginSuite) TestListRegistrationEntriesByEmptyHint() {
+	withHint, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/agent",
+		SpiffeId:  "spiffe://example.org/foo",
+		Selectors: []*common.Selector{{Type: "a", Value: "1"}},
+		Hint:      "internal",
+	})
+	s.Require().NoError(err)
+	withoutHint, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/agent",
+		SpiffeId:  "spiffe://example.org/bar",
+		Selectors: []*common.Selector{{Type: "a", Value: "2"}},
+	})
+	s.Require().NoError(err)
+	// Entries created before the hint column was added have a NULL hint
+	nullHint, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/agent",
+		SpiffeId:  "spiffe://example.org/baz",
+		Selectors: []*common.Selector{{Type: "a", Value: "3"}},
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.ds.db.Exec("UPDATE registered_entries SET hint = NULL WHERE entry_id = ?", nullHint.EntryId).Error)
+
+	listByHint := func(hint *string) []string {
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{ByHint: hint})
+		s.Require().NoError(err)
+		var ids []string
+		for _, entry := range resp.Entries {
+			ids = append(ids, entry.EntryId)
+		}
+		return ids
+	}
+	countByHint := func(hint *string) int32 {
+		count, err := s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{ByHint: hint})
+		s.Require().NoError(err)
+		return count
+	}
+
+	empty := ""
+	s.Require().ElementsMatch([]string{withoutHint.EntryId, nullHint.EntryId}, listByHint(&empty))
+	s.Require().Equal(int32(2), countByHint(&empty))
+
+	internal := "internal"
+	s.Require().Equal([]string{withHint.EntryId}, listByHint(&internal))
+	s.Require().Equal(int32(1), countByHint(&internal))
+
+	// A nil hint does not filter
+	s.Require().Len(listByHint(nil), 3)
+	s.Require().Equal(int32(3), countByHint(nil))
+}
+
 func (s *PluginSuite) TestSetBundle() {
 	// create a couple of bundles for tests. the contents don't really matter
 	// as long as they are for the same trust domain but have different contents.
@@ -586,72 +1017,507 @@ func (s *PluginSuite) TestSetBundle() {
 	s.RequireProtoEqual(bundle2, s.fetchBundle("spiffe://foo"))
 }
 
-func (s *PluginSuite) TestBundlePrune() {
-	// Setup
-	// Create new bundle with two cert (one valid and one expired)
-	bundle := bundleutil.BundleProtoFromRootCAs("spiffe://foo", []*x509.Certificate{s.cert, s.cacert})
-	bundle.SequenceNumber = 42
-
-	// Add two JWT signing keys (one valid and one expired)
-	expiredKeyTime, err := time.Parse(time.RFC3339, _expiredNotAfterString)
+func (s *PluginSuite) TestBundleTrustDomainCase() {
+	// Bundles are stored with their trust domain lowercased
+	created, err := s.ds.CreateBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://Domain.Test", s.cert))
 	s.Require().NoError(err)
+	s.Equal("spiffe://domain.test", created.TrustDomainId)
+	s.RequireProtoEqual(created, s.fetchBundle("spiffe://domain.test"))
 
-	nonExpiredKeyTime, err := time.Parse(time.RFC3339, _validNotAfterString)
+	// Lookups match regardless of the case of the trust domain
+	s.RequireProtoEqual(created, s.fetchBundle("spiffe://DOMAIN.test"))
+	hash, err := s.ds.FetchBundleContentHash(ctx, "spiffe://DOMAIN.test")
 	s.Require().NoError(err)
+	s.NotEmpty(hash)
 
-	// middleTime is a point between the two certs expiration time
-	middleTime, err := time.Parse(time.RFC3339, _middleTimeString)
+	// Writing the trust domain in another case updates the same bundle
+	_, err = s.ds.CreateBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://domain.test", s.cert))
+	s.Equal(codes.AlreadyExists, status.Code(err))
+	updated := bundleutil.BundleProtoFromRootCA("spiffe://DOMAIN.TEST", s.cacert)
+	_, err = s.ds.SetBundle(ctx, updated)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(bundleutil.BundleProtoFromRootCA("spiffe://domain.test", s.cacert), s.fetchBundle("spiffe://domain.test"))
+	count, err := s.ds.CountBundles(ctx)
+	s.Require().NoError(err)
+	s.Equal(int32(1), count)
+
+	// Entries can federate with the bundle using any case
+	entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		Selectors:     []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		SpiffeId:      "spiffe://example.org/workload",
+		ParentId:      "spiffe://example.org/agent",
+		FederatesWith: []string{"spiffe://Domain.Test"},
+	})
 	s.Require().NoError(err)
+	resp, err := s.ds.ListRegistrationEntriesByFederatesWith(ctx, "spiffe://DOMAIN.TEST", nil)
+	s.Require().NoError(err)
+	s.Require().Len(resp.Entries, 1)
+	s.Equal(entry.EntryId, resp.Entries[0].EntryId)
+	s.Equal([]string{"spiffe://domain.test"}, resp.Entries[0].FederatesWith)
 
-	bundle.JwtSigningKeys = []*common.PublicKey{
-		{NotAfter: expiredKeyTime.Unix()},
-		{NotAfter: nonExpiredKeyTime.Unix()},
-	}
+	s.Require().NoError(s.ds.DeleteBundle(ctx, "spiffe://Domain.TEST", datastore.Dissociate))
+	s.Require().Nil(s.fetchBundle("spiffe://domain.test"))
+}
 
-	// Store bundle in datastore
-	_, err = s.ds.CreateBundle(ctx, bundle)
-	s.Require().NoError(err)
+func (s *PluginSuite) TestBundleLastRefreshedAt() {
+	bundle := bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cert)
 
-	// Prune
-	// prune non existent bundle should not return error, no bundle to prune
-	expiration := time.Now()
-	changed, err := s.ds.PruneBundle(ctx, "spiffe://notexistent", expiration)
-	s.NoError(err)
-	s.False(changed)
+	// A bundle that has never been refreshed has no last refreshed timestamp
+	_, err := s.ds.CreateBundle(ctx, bundle)
+	s.Require().NoError(err)
+	s.Require().Zero(s.fetchBundle("spiffe://foo").LastRefreshedAt)
 
-	// prune fails if internal prune bundle fails. For instance, if all certs are expired
-	expiration = time.Now()
-	changed, err = s.ds.PruneBundle(ctx, bundle.TrustDomainId, expiration)
-	s.AssertGRPCStatus(err, codes.Unknown, "prune failed: would prune all certificates")
-	s.False(changed)
+	// Setting a refreshed bundle stores the timestamp in its own column
+	refreshedAt := time.Now().Unix()
+	refreshed := bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cacert)
+	refreshed.LastRefreshedAt = refreshedAt
+	setBundle, err := s.ds.SetBundle(ctx, refreshed)
+	s.Require().NoError(err)
+	s.Require().Equal(refreshedAt, setBundle.LastRefreshedAt)
+	s.RequireProtoEqual(refreshed, s.fetchBundle("spiffe://foo"))
+
+	model := new(Bundle)
+	s.Require().NoError(s.ds.db.Find(model, "trust_domain = ?", "spiffe://foo").Error)
+	s.Require().NotNil(model.LastRefreshedAt)
+	s.Require().Equal(refreshedAt, model.LastRefreshedAt.Unix())
+	stored := new(common.Bundle)
+	s.Require().NoError(proto.Unmarshal(model.Data, stored))
+	s.Require().Zero(stored.LastRefreshedAt)
+
+	// Updates that are not the result of a refresh keep the timestamp
+	updated, err := s.ds.UpdateBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cert), nil)
+	s.Require().NoError(err)
+	s.Require().Equal(refreshedAt, updated.LastRefreshedAt)
+	appended, err := s.ds.AppendBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cacert))
+	s.Require().NoError(err)
+	s.Require().Equal(refreshedAt, appended.LastRefreshedAt)
 
-	// prune should remove expired certs
-	changed, err = s.ds.PruneBundle(ctx, bundle.TrustDomainId, middleTime)
-	s.NoError(err)
-	s.True(changed)
+	resp, err := s.ds.ListBundles(ctx, &datastore.ListBundlesRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Bundles, 1)
+	s.Require().Equal(refreshedAt, resp.Bundles[0].LastRefreshedAt)
 
-	// Fetch and verify pruned bundle is the expected
-	expectedPrunedBundle := bundleutil.BundleProtoFromRootCAs("spiffe://foo", []*x509.Certificate{s.cert})
-	expectedPrunedBundle.JwtSigningKeys = []*common.PublicKey{{NotAfter: nonExpiredKeyTime.Unix()}}
-	expectedPrunedBundle.SequenceNumber = 43
-	fb, err := s.ds.FetchBundle(ctx, "spiffe://foo")
+	// Stamping a refresh only updates the timestamp
+	stampedAt := time.Unix(refreshedAt+60, 0)
+	hash, err := s.ds.FetchBundleContentHash(ctx, "spiffe://foo")
 	s.Require().NoError(err)
-	s.AssertProtoEqual(expectedPrunedBundle, fb)
+	s.Require().NoError(s.ds.SetBundleRefreshedAt(ctx, "spiffe://foo", stampedAt))
+	stamped := s.fetchBundle("spiffe://foo")
+	s.Require().Equal(stampedAt.Unix(), stamped.LastRefreshedAt)
+	stamped.LastRefreshedAt = 0
+	appended.LastRefreshedAt = 0
+	s.RequireProtoEqual(appended, stamped)
+	stampedHash, err := s.ds.FetchBundleContentHash(ctx, "spiffe://foo")
+	s.Require().NoError(err)
+	s.Require().Equal(hash, stampedHash)
+
+	// Stamping a bundle that does not exist is a no-op
+	s.Require().NoError(s.ds.SetBundleRefreshedAt(ctx, "spiffe://bar", stampedAt))
 }
 
-func (s *PluginSuite) TestTaintX509CA() {
-	t := s.T()
+func (s *PluginSuite) TestFetchBundleContentHash() {
+	requireHash := func(bundle *common.Bundle) string {
+		expected, err := bundleutil.ContentHash(bundle)
+		s.Require().NoError(err)
+		hash, err := s.ds.FetchBundleContentHash(ctx, "spiffe://foo")
+		s.Require().NoError(err)
+		s.Require().Equal(expected, hash)
+		return hash
+	}
 
-	// Tainted public key on raw format
-	skID := x509util.SubjectKeyIDToString(s.cert.SubjectKeyId)
+	// There is no hash for a bundle that does not exist
+	hash, err := s.ds.FetchBundleContentHash(ctx, "spiffe://foo")
+	s.Require().NoError(err)
+	s.Require().Empty(hash)
 
-	t.Run("bundle not found", func(t *testing.T) {
-		err := s.ds.TaintX509CA(ctx, "spiffe://foo", "foo")
-		spiretest.RequireGRPCStatus(t, err, codes.NotFound, _notFoundErrMsg)
-	})
+	bundle := bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cert)
+	_, err = s.ds.CreateBundle(ctx, bundle)
+	s.Require().NoError(err)
+	created := requireHash(bundle)
 
-	// Create Malformed CA
-	bundle := bundleutil.BundleProtoFromRootCAs("spiffe://foo", []*x509.Certificate{{Raw: []byte("bar")}})
+	// Writes that do not change the contents keep the hash, even when the
+	// bundle is marked as refreshed
+	refreshed := bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cert)
+	refreshed.LastRefreshedAt = time.Now().Unix()
+	_, err = s.ds.SetBundle(ctx, refreshed)
+	s.Require().NoError(err)
+	s.Require().Equal(created, requireHash(bundle))
+	_, err = s.ds.AppendBundle(ctx, bundle)
+	s.Require().NoError(err)
+	s.Require().Equal(created, requireHash(bundle))
+
+	// Content changes update the hash
+	updated := bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cacert)
+	_, err = s.ds.UpdateBundle(ctx, updated, nil)
+	s.Require().NoError(err)
+	updatedHash := requireHash(updated)
+	s.Require().NotEqual(created, updatedHash)
+
+	appended, err := s.ds.AppendBundle(ctx, bundle)
+	s.Require().NoError(err)
+	s.Require().NotEqual(updatedHash, requireHash(appended))
+
+	// Bundles written without a hash are hashed from their data
+	s.Require().NoError(s.ds.db.Model(&Bundle{}).Where("trust_domain = ?", "spiffe://foo").UpdateColumn("content_hash", "").Error)
+	requireHash(appended)
+}
+
+func (s *PluginSuite) TestAppendBundleConflictingJWTKey() {
+	bundle := &common.Bundle{
+		TrustDomainId:  "spiffe://foo",
+		JwtSigningKeys: []*common.PublicKey{{Kid: "kid", PkixBytes: []byte("key")}},
+	}
+	_, err := s.ds.CreateBundle(ctx, bundle)
+	s.Require().NoError(err)
+
+	// The same key is not appended twice, even if tainted since
+	_, err = s.ds.TaintJWTKey(ctx, "spiffe://foo", "kid")
+	s.Require().NoError(err)
+	appended, err := s.ds.AppendBundle(ctx, bundle)
+	s.Require().NoError(err)
+	s.Require().Len(appended.JwtSigningKeys, 1)
+	s.True(appended.JwtSigningKeys[0].TaintedKey)
+
+	// A different key with the same key ID is rejected
+	_, err = s.ds.AppendBundle(ctx, &common.Bundle{
+		TrustDomainId:  "spiffe://foo",
+		JwtSigningKeys: []*common.PublicKey{{Kid: "kid", PkixBytes: []byte("other")}},
+	})
+	s.RequireGRPCStatus(err, codes.FailedPrecondition, `datastore-sql: cannot append bundle: JWT signing key "kid" conflicts with a different key with the same key ID`)
+	s.RequireProtoEqual(appended, s.fetchBundle("spiffe://foo"))
+}
+
+func (s *PluginSuite) TestBundleTooLarge() {
+	bundle := &common.Bundle{
+		TrustDomainId:  "spiffe://foo",
+		JwtSigningKeys: []*common.PublicKey{{Kid: "kid1", PkixBytes: []byte("key1")}},
+	}
+	data, err := proto.Marshal(bundle)
+	s.Require().NoError(err)
+	s.ds.maxBundleSize = len(data)
+
+	created, err := s.ds.CreateBundle(ctx, bundle)
+	s.Require().NoError(err)
+
+	larger := &common.Bundle{
+		TrustDomainId: "spiffe://foo",
+		JwtSigningKeys: []*common.PublicKey{
+			{Kid: "kid1", PkixBytes: []byte("key1")},
+			{Kid: "kid2", PkixBytes: []byte("key2")},
+		},
+	}
+	largerData, err := proto.Marshal(larger)
+	s.Require().NoError(err)
+
+	assertTooLarge := func(err error) {
+		var tooLarge *datastore.BundleTooLargeError
+		s.Require().ErrorAs(err, &tooLarge)
+		s.Equal("spiffe://foo", tooLarge.TrustDomainID)
+		s.Greater(tooLarge.Size, len(data))
+		s.Equal(len(data), tooLarge.MaxSize)
+		s.Equal(codes.InvalidArgument, status.Code(err))
+	}
+
+	_, err = s.ds.AppendBundle(ctx, &common.Bundle{
+		TrustDomainId:  "spiffe://foo",
+		JwtSigningKeys: []*common.PublicKey{{Kid: "kid2", PkixBytes: []byte("key2")}},
+	})
+	assertTooLarge(err)
+
+	_, err = s.ds.SetBundle(ctx, larger)
+	assertTooLarge(err)
+
+	_, err = s.ds.UpdateBundle(ctx, larger, nil)
+	assertTooLarge(err)
+
+	// The stored bundle is left untouched
+	s.RequireProtoEqual(created, s.fetchBundle("spiffe://foo"))
+
+	// Bundles that fit are still written
+	s.ds.maxBundleSize = len(largerData)
+	updated, err := s.ds.SetBundle(ctx, larger)
+	s.Require().NoError(err)
+	s.RequireProtoEqual(larger, updated)
+
+	// Tainting a key is not blocked by the size limit
+	s.ds.maxBundleSize = 1
+	_, err = s.ds.TaintJWTKey(ctx, "spiffe://foo", "kid1")
+	s.Require().NoError(err)
+}
+
+func (s *PluginSuite) TestAppendBundleConcurrently() {
+	const appenders = 10
+
+	// Every appender adds a distinct root CA to a bundle that doesn't exist
+	// yet, so they race to both create and merge into it
+	var wg sync.WaitGroup
+	errs := make(chan error, appenders)
+	var expectedRootCAs [][]byte
+	for i := range appenders {
+		derBytes := fmt.Appendf(nil, "root-%d", i)
+		expectedRootCAs = append(expectedRootCAs, derBytes)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.ds.AppendBundle(ctx, &common.Bundle{
+				TrustDomainId: "spiffe://foo",
+				RootCas:       []*common.Certificate{{DerBytes: derBytes}},
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		s.Require().NoError(err)
+	}
+
+	bundle := s.fetchBundle("spiffe://foo")
+	s.Require().NotNil(bundle)
+	var rootCAs [][]byte
+	for _, rootCA := range bundle.RootCas {
+		rootCAs = append(rootCAs, rootCA.DerBytes)
+	}
+	s.ElementsMatch(expectedRootCAs, rootCAs)
+	s.Equal(uint64(appenders-1), bundle.SequenceNumber)
+}
+
+func (s *PluginSuite) TestBundlePrune() {
+	// Setup
+	// Create new bundle with two cert (one valid and one expired)
+	bundle := bundleutil.BundleProtoFromRootCAs("spiffe://foo", []*x509.Certificate{s.cert, s.cacert})
+	bundle.SequenceNumber = 42
+
+	// Add two JWT signing keys (one valid and one expired)
+	expiredKeyTime, err := time.Parse(time.RFC3339, _expiredNotAfterString)
+	s.Require().NoError(err)
+
+	nonExpiredKeyTime, err := time.Parse(time.RFC3339, _validNotAfterString)
+	s.Require().NoError(err)
+
+	// middleTime is a point between the two certs expiration time
+	middleTime, err := time.Parse(time.RFC3339, _middleTimeString)
+	s.Require().NoError(err)
+
+	bundle.JwtSigningKeys = []*common.PublicKey{
+		{NotAfter: expiredKeyTime.Unix()},
+		{NotAfter: nonExpiredKeyTime.Unix()},
+	}
+
+	// Store bundle in datastore
+	_, err = s.ds.CreateBundle(ctx, bundle)
+	s.Require().NoError(err)
+
+	// Prune
+	// prune non existent bundle should not return error, no bundle to prune
+	expiration := time.Now()
+	changed, err := s.ds.PruneBundle(ctx, "spiffe://notexistent", expiration)
+	s.NoError(err)
+	s.False(changed)
+
+	// prune fails if internal prune bundle fails. For instance, if all certs are expired
+	expiration = time.Now()
+	changed, err = s.ds.PruneBundle(ctx, bundle.TrustDomainId, expiration)
+	s.AssertGRPCStatus(err, codes.Unknown, "prune failed: would prune all certificates")
+	s.False(changed)
+
+	// prune should remove expired certs
+	changed, err = s.ds.PruneBundle(ctx, bundle.TrustDomainId, middleTime)
+	s.NoError(err)
+	s.True(changed)
+
+	// Fetch and verify pruned bundle is the expected
+	expectedPrunedBundle := bundleutil.BundleProtoFromRootCAs("spiffe://foo", []*x509.Certificate{s.cert})
+	expectedPrunedBundle.JwtSigningKeys = []*common.PublicKey{{NotAfter: nonExpiredKeyTime.Unix()}}
+	expectedPrunedBundle.SequenceNumber = 43
+	fb, err := s.ds.FetchBundle(ctx, "spiffe://foo")
+	s.Require().NoError(err)
+	s.AssertProtoEqual(expectedPrunedBundle, fb)
+}
+
+func (s *PluginSuite) TestListBundleAuthoritiesToPrune() {
+	bundle := bundleutil.BundleProtoFromRootCAs("spiffe://foo", []*x509.Certificate{s.cert, s.cacert})
+	bundle.SequenceNumber = 42
+
+	expiredKeyTime, err := time.Parse(time.RFC3339, _expiredNotAfterString)
+	s.Require().NoError(err)
+	nonExpiredKeyTime, err := time.Parse(time.RFC3339, _validNotAfterString)
+	s.Require().NoError(err)
+	middleTime, err := time.Parse(time.RFC3339, _middleTimeString)
+	s.Require().NoError(err)
+
+	bundle.JwtSigningKeys = []*common.PublicKey{
+		{Kid: "expired", NotAfter: expiredKeyTime.Unix()},
+		{Kid: "valid", NotAfter: nonExpiredKeyTime.Unix()},
+	}
+	_, err = s.ds.CreateBundle(ctx, bundle)
+	s.Require().NoError(err)
+
+	// a non existent bundle has nothing to prune
+	toPrune, err := s.ds.ListBundleAuthoritiesToPrune(ctx, "spiffe://notexistent", time.Now())
+	s.Require().NoError(err)
+	s.Empty(toPrune.X509AuthorityIDs)
+	s.Empty(toPrune.JWTAuthorityIDs)
+
+	// the dry run fails the same way the prune does
+	_, err = s.ds.ListBundleAuthoritiesToPrune(ctx, bundle.TrustDomainId, time.Now())
+	s.AssertGRPCStatus(err, codes.Unknown, "prune failed: would prune all certificates")
+
+	// nothing expired before the earliest expiration
+	toPrune, err = s.ds.ListBundleAuthoritiesToPrune(ctx, bundle.TrustDomainId, expiredKeyTime.Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Empty(toPrune.X509AuthorityIDs)
+	s.Empty(toPrune.JWTAuthorityIDs)
+
+	toPrune, err = s.ds.ListBundleAuthoritiesToPrune(ctx, bundle.TrustDomainId, middleTime)
+	s.Require().NoError(err)
+	s.Equal([]string{x509util.SubjectKeyIDToString(s.cacert.SubjectKeyId)}, toPrune.X509AuthorityIDs)
+	s.Equal([]string{"expired"}, toPrune.JWTAuthorityIDs)
+
+	// the bundle is left untouched
+	s.RequireProtoEqual(bundle, s.fetchBundle(bundle.TrustDomainId))
+
+	changed, err := s.ds.PruneBundle(ctx, bundle.TrustDomainId, middleTime)
+	s.Require().NoError(err)
+	s.True(changed)
+	pruned := s.fetchBundle(bundle.TrustDomainId)
+	s.Len(pruned.RootCas, len(bundle.RootCas)-len(toPrune.X509AuthorityIDs))
+	s.Len(pruned.JwtSigningKeys, len(bundle.JwtSigningKeys)-len(toPrune.JWTAuthorityIDs))
+}
+
+func (s *PluginSuite) TestBundleEvents() {
+	listTrustDomains := func(req *datastore.ListBundleEventsRequest) []string {
+		resp, err := s.ds.ListBundleEvents(ctx, req)
+		s.Require().NoError(err)
+		var trustDomains []string
+		for _, event := range resp.Events {
+			trustDomains = append(trustDomains, event.TrustDomain)
+		}
+		return trustDomains
+	}
+	lastEventID := func() uint {
+		resp, err := s.ds.ListBundleEvents(ctx, &datastore.ListBundleEventsRequest{})
+		s.Require().NoError(err)
+		s.Require().NotEmpty(resp.Events)
+		return resp.Events[len(resp.Events)-1].EventID
+	}
+
+	// Creating bundles, either directly or through SetBundle and
+	// AppendBundle, records an event for each trust domain
+	_, err := s.ds.CreateBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cert))
+	s.Require().NoError(err)
+	_, err = s.ds.SetBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://bar", s.cert))
+	s.Require().NoError(err)
+	_, err = s.ds.AppendBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://baz", s.cert))
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"spiffe://foo", "spiffe://bar", "spiffe://baz"}, listTrustDomains(&datastore.ListBundleEventsRequest{}))
+
+	for _, tt := range []struct {
+		name   string
+		mutate func() error
+		expect []string
+	}{
+		{
+			name: "set with new contents",
+			mutate: func() error {
+				_, err := s.ds.SetBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cacert))
+				return err
+			},
+			expect: []string{"spiffe://foo"},
+		},
+		{
+			name: "set with same contents",
+			mutate: func() error {
+				_, err := s.ds.SetBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cacert))
+				return err
+			},
+		},
+		{
+			name: "set with same contents after a refresh",
+			mutate: func() error {
+				bundle := bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cacert)
+				bundle.LastRefreshedAt = time.Now().Unix()
+				_, err := s.ds.SetBundle(ctx, bundle)
+				return err
+			},
+		},
+		{
+			name: "update with new contents",
+			mutate: func() error {
+				bundle := bundleutil.BundleProtoFromRootCA("spiffe://bar", s.cert)
+				bundle.RefreshHint = 60
+				_, err := s.ds.UpdateBundle(ctx, bundle, &common.BundleMask{RefreshHint: true})
+				return err
+			},
+			expect: []string{"spiffe://bar"},
+		},
+		{
+			name: "update with same contents",
+			mutate: func() error {
+				bundle := bundleutil.BundleProtoFromRootCA("spiffe://bar", s.cacert)
+				bundle.RefreshHint = 60
+				_, err := s.ds.UpdateBundle(ctx, bundle, &common.BundleMask{RefreshHint: true})
+				return err
+			},
+		},
+		{
+			name: "append new certificate",
+			mutate: func() error {
+				_, err := s.ds.AppendBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://baz", s.cacert))
+				return err
+			},
+			expect: []string{"spiffe://baz"},
+		},
+		{
+			name: "append existing certificate",
+			mutate: func() error {
+				_, err := s.ds.AppendBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://baz", s.cacert))
+				return err
+			},
+		},
+		{
+			name: "delete",
+			mutate: func() error {
+				return s.ds.DeleteBundle(ctx, "spiffe://baz", datastore.Restrict)
+			},
+			expect: []string{"spiffe://baz"},
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			greaterThan := lastEventID()
+			require.NoError(t, tt.mutate())
+			require.Equal(t, tt.expect, listTrustDomains(&datastore.ListBundleEventsRequest{
+				GreaterThanEventID: greaterThan,
+			}))
+		})
+	}
+
+	// Events can be listed before a given event ID
+	s.Require().Equal([]string{"spiffe://foo"}, listTrustDomains(&datastore.ListBundleEventsRequest{
+		LessThanEventID: 2,
+	}))
+
+	_, err = s.ds.ListBundleEvents(ctx, &datastore.ListBundleEventsRequest{
+		GreaterThanEventID: 1,
+		LessThanEventID:    2,
+	})
+	s.Require().EqualError(err, "rpc error: code = Unknown desc = datastore-sql: can't set both greater and less than event id")
+}
+
+func (s *PluginSuite) TestTaintX509CA() {
+	t := s.T()
+
+	// Tainted public key on raw format
+	skID := x509util.SubjectKeyIDToString(s.cert.SubjectKeyId)
+
+	t.Run("bundle not found", func(t *testing.T) {
+		err := s.ds.TaintX509CA(ctx, "spiffe://foo", "foo")
+		spiretest.RequireGRPCStatus(t, err, codes.NotFound, _notFoundErrMsg)
+	})
+
+	// Create Malformed CA
+	bundle := bundleutil.BundleProtoFromRootCAs("spiffe://foo", []*x509.Certificate{{Raw: []byte("bar")}})
 	_, err := s.ds.CreateBundle(ctx, bundle)
 	require.NoError(t, err)
 
@@ -926,8 +1792,16 @@ func (s *PluginSuite) TestCreateAttestedNode() {
 		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
 	}
 
+	before := time.Now().Unix()
 	attestedNode, err := s.ds.CreateAttestedNode(ctx, node)
 	s.Require().NoError(err)
+
+	// The node first attested when it was created
+	s.GreaterOrEqual(attestedNode.AttestedAt, before)
+	s.LessOrEqual(attestedNode.AttestedAt, time.Now().Unix())
+	s.Equal(attestedNode.AttestedAt, attestedNode.LastAttestedAt)
+	node.AttestedAt = attestedNode.AttestedAt
+	node.LastAttestedAt = attestedNode.LastAttestedAt
 	s.AssertProtoEqual(node, attestedNode)
 
 	attestedNode, err = s.ds.FetchAttestedNode(ctx, node.SpiffeId)
@@ -935,12 +1809,117 @@ func (s *PluginSuite) TestCreateAttestedNode() {
 	s.AssertProtoEqual(node, attestedNode)
 }
 
+func (s *PluginSuite) TestAttestedNodeAttestationTimes() {
+	node, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/foo",
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "badcafe",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	})
+	s.Require().NoError(err)
+	attestedAt := node.AttestedAt
+	s.Require().NotZero(attestedAt)
+	s.Require().Equal(attestedAt, node.LastAttestedAt)
+
+	// Renewing the SVID is not a reattestation
+	node, err = s.ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:         node.SpiffeId,
+		CertSerialNumber: "deadbeef",
+		CertNotAfter:     time.Now().Add(2 * time.Hour).Unix(),
+	}, &common.AttestedNodeMask{CertSerialNumber: true, CertNotAfter: true})
+	s.Require().NoError(err)
+	s.Equal(attestedAt, node.AttestedAt)
+	s.Equal(attestedAt, node.LastAttestedAt)
+
+	// Reattesting only moves the last attestation time, even if the caller
+	// tries to change the first one
+	reattestedAt := attestedAt + 3600
+	node, err = s.ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:         node.SpiffeId,
+		CertSerialNumber: "cafebabe",
+		CertNotAfter:     time.Now().Add(3 * time.Hour).Unix(),
+		AttestedAt:       reattestedAt,
+		LastAttestedAt:   reattestedAt,
+	}, nil)
+	s.Require().NoError(err)
+	s.Equal(attestedAt, node.AttestedAt)
+	s.Equal(reattestedAt, node.LastAttestedAt)
+
+	fetched, err := s.ds.FetchAttestedNode(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.AssertProtoEqual(node, fetched)
+
+	resp, err := s.ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Nodes, 1)
+	s.Equal(attestedAt, resp.Nodes[0].AttestedAt)
+	s.Equal(reattestedAt, resp.Nodes[0].LastAttestedAt)
+
+	// A node attesting again after being deleted attests for the first time
+	_, err = s.ds.DeleteAttestedNode(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	node, err = s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            node.SpiffeId,
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "badcafe",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	})
+	s.Require().NoError(err)
+	s.Less(node.LastAttestedAt, reattestedAt)
+	s.Equal(node.AttestedAt, node.LastAttestedAt)
+}
+
 func (s *PluginSuite) TestFetchAttestedNodeMissing() {
 	attestedNode, err := s.ds.FetchAttestedNode(ctx, "missing")
 	s.Require().NoError(err)
 	s.Require().Nil(attestedNode)
 }
 
+func (s *PluginSuite) TestAttestedNodeExists() {
+	_, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/foo",
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "badcafe",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.ds.SetNodeSelectors(ctx, "spiffe://example.org/foo", []*common.Selector{
+		{Type: "a", Value: "1"},
+	}))
+
+	// Record the statements issued by the existence check
+	var queries []string
+	recordQuery := func(scope *gorm.Scope) {
+		queries = append(queries, scope.SQL)
+	}
+	s.ds.db.Callback().Query().After("gorm:query").Register("test:record_query", recordQuery)
+	defer s.ds.db.Callback().Query().Remove("test:record_query")
+	s.ds.db.Callback().RowQuery().After("gorm:row_query").Register("test:record_row_query", recordQuery)
+	defer s.ds.db.Callback().RowQuery().Remove("test:record_row_query")
+
+	exists, err := s.ds.AttestedNodeExists(ctx, "spiffe://example.org/foo")
+	s.Require().NoError(err)
+	s.True(exists)
+
+	exists, err = s.ds.AttestedNodeExists(ctx, "spiffe://example.org/missing")
+	s.Require().NoError(err)
+	s.False(exists)
+
+	// Selectors are never loaded
+	s.Require().Len(queries, 2)
+	for _, query := range queries {
+		s.Contains(query, "attested_node_entries")
+		s.NotContains(query, "node_resolver_map_entries")
+	}
+
+	// The node is gone once deleted
+	_, err = s.ds.DeleteAttestedNode(ctx, "spiffe://example.org/foo")
+	s.Require().NoError(err)
+	exists, err = s.ds.AttestedNodeExists(ctx, "spiffe://example.org/foo")
+	s.Require().NoError(err)
+	s.False(exists)
+}
+
 func (s *PluginSuite) TestListAttestedNodes() {
 	// Connection is never used, each test creates a connection to a different database
 	s.ds.Close()
@@ -949,6 +1928,11 @@ func (s *PluginSuite) TestListAttestedNodes() {
 	expired := now.Add(-time.Hour)
 	unexpired := now.Add(time.Hour)
 
+	// Node expiration is stored with second precision. These are used to
+	// exercise the window boundaries against nodes expiring exactly on them.
+	expiredBoundary := time.Unix(expired.Unix(), 0)
+	unexpiredBoundary := time.Unix(unexpired.Unix(), 0)
+
 	makeAttestedNode := func(spiffeIDSuffix, attestationType string, notAfter time.Time, sn string, canReattest bool, selectors ...string) *common.AttestedNode {
 		return &common.AttestedNode{
 			SpiffeId:            makeID(spiffeIDSuffix),
@@ -977,12 +1961,14 @@ func (s *PluginSuite) TestListAttestedNodes() {
 	nodeG := makeAttestedNode("G", "T1", unexpired, banned, false, "S2", "S3")
 	nodeH := makeAttestedNode("H", "T2", unexpired, banned, false, "S2", "S3")
 	nodeI := makeAttestedNode("I", "T1", unexpired, unbanned, true, "S1")
+	nodeJ := makeAttestedNode("J", "T1", unexpired, unbanned, false, "S1", "S2", "S3")
 
 	for _, tt := range []struct {
 		test                string
 		nodes               []*common.AttestedNode
 		pageSize            int32
 		byExpiresBefore     time.Time
+		byExpiresAfter      time.Time
 		byAttestationType   string
 		bySelectors         *datastore.BySelectors
 		byBanned            *bool
@@ -1030,6 +2016,43 @@ func (s *PluginSuite) TestListAttestedNodes() {
 			expectPagedTokensIn: []string{"", "1", "3", "6"},
 			expectPagedNodesOut: [][]*common.AttestedNode{{nodeA}, {nodeB}, {nodeC}, {}},
 		},
+		{
+			test:                "by expires after",
+			nodes:               []*common.AttestedNode{nodeA, nodeE, nodeB, nodeF, nodeG, nodeC},
+			byExpiresAfter:      now,
+			expectNodesOut:      []*common.AttestedNode{nodeE, nodeF, nodeG},
+			expectPagedTokensIn: []string{"", "2", "4", "5"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeE}, {nodeF}, {nodeG}, {}},
+		},
+		{
+			test:                "by expiration window inclusive lower and exclusive upper bound",
+			nodes:               []*common.AttestedNode{nodeA, nodeE, nodeB, nodeF, nodeG, nodeC},
+			byExpiresAfter:      expiredBoundary,
+			byExpiresBefore:     unexpiredBoundary,
+			expectNodesOut:      []*common.AttestedNode{nodeA, nodeB, nodeC},
+			expectPagedTokensIn: []string{"", "1", "3", "6"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeA}, {nodeB}, {nodeC}, {}},
+		},
+		{
+			test:                "by empty expiration window",
+			nodes:               []*common.AttestedNode{nodeA, nodeE, nodeB, nodeF, nodeG, nodeC},
+			byExpiresAfter:      expiredBoundary,
+			byExpiresBefore:     expiredBoundary,
+			expectNodesOut:      []*common.AttestedNode{},
+			expectPagedTokensIn: []string{""},
+			expectPagedNodesOut: [][]*common.AttestedNode{{}},
+		},
+		{
+			test:                "by expiration window and attestation type and selector subset",
+			nodes:               []*common.AttestedNode{nodeA, nodeE, nodeB, nodeF, nodeG, nodeC, nodeI},
+			byExpiresAfter:      expiredBoundary,
+			byExpiresBefore:     unexpiredBoundary.Add(time.Second),
+			byAttestationType:   "T1",
+			bySelectors:         bySelectors(datastore.Subset, "S1", "S2"),
+			expectNodesOut:      []*common.AttestedNode{nodeA, nodeE, nodeC, nodeI},
+			expectPagedTokensIn: []string{"", "1", "2", "6", "7"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeA}, {nodeE}, {nodeC}, {nodeI}, {}},
+		},
 		// By attestation type
 		{
 			test:                "by attestation type",
@@ -1132,11 +2155,90 @@ func (s *PluginSuite) TestListAttestedNodes() {
 			expectPagedTokensIn: []string{"", "5"},
 			expectPagedNodesOut: [][]*common.AttestedNode{{nodeE}, {}},
 		},
-		// By CanReattest=true
+		// Exact, subset and superset matching of the same selectors against
+		// the same nodes
 		{
-			test:                "by CanReattest=true",
-			nodes:               []*common.AttestedNode{nodeA, nodeI},
-			byAttestationType:   "T1",
+			test:                "by selectors exact with nodes having more selectors",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			bySelectors:         bySelectors(datastore.Exact, "S1", "S3"),
+			expectNodesOut:      []*common.AttestedNode{nodeF},
+			expectPagedTokensIn: []string{"", "6"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeF}, {}},
+		},
+		{
+			test:                "by selectors subset with nodes having more selectors",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			bySelectors:         bySelectors(datastore.Subset, "S1", "S3"),
+			expectNodesOut:      []*common.AttestedNode{nodeA, nodeB, nodeF},
+			expectPagedTokensIn: []string{"", "1", "2", "6"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeA}, {nodeB}, {nodeF}, {}},
+		},
+		{
+			test:                "by selectors superset with nodes having more selectors",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			bySelectors:         bySelectors(datastore.Superset, "S1", "S3"),
+			expectNodesOut:      []*common.AttestedNode{nodeF, nodeJ},
+			expectPagedTokensIn: []string{"", "6", "9"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeF}, {nodeJ}, {}},
+		},
+		{
+			test:                "by selectors superset with duplicated selectors",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			bySelectors:         bySelectors(datastore.Superset, "S1", "S3", "S1"),
+			expectNodesOut:      []*common.AttestedNode{nodeF, nodeJ},
+			expectPagedTokensIn: []string{"", "6", "9"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeF}, {nodeJ}, {}},
+		},
+		{
+			test:                "by expiration and attestation type and selector superset",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			pageSize:            2,
+			byExpiresAfter:      now,
+			byAttestationType:   "T1",
+			bySelectors:         bySelectors(datastore.Superset, "S2"),
+			expectNodesOut:      []*common.AttestedNode{nodeE, nodeG, nodeJ},
+			expectPagedTokensIn: []string{"", "7", "9"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeE, nodeG}, {nodeJ}, {}},
+		},
+		{
+			test:                "by selectors match all with nodes having more selectors",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			bySelectors:         bySelectors(datastore.MatchAll, "S1", "S3"),
+			expectNodesOut:      []*common.AttestedNode{nodeF, nodeJ},
+			expectPagedTokensIn: []string{"", "6", "9"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeF}, {nodeJ}, {}},
+		},
+		{
+			test:                "by selectors match all with duplicated selectors",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			bySelectors:         bySelectors(datastore.MatchAll, "S3", "S1", "S3"),
+			expectNodesOut:      []*common.AttestedNode{nodeF, nodeJ},
+			expectPagedTokensIn: []string{"", "6", "9"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeF}, {nodeJ}, {}},
+		},
+		{
+			test:                "by selectors match all with a single selector",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			bySelectors:         bySelectors(datastore.MatchAll, "S3"),
+			expectNodesOut:      []*common.AttestedNode{nodeF, nodeG, nodeH, nodeJ},
+			expectPagedTokensIn: []string{"", "6", "7", "8", "9"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeF}, {nodeG}, {nodeH}, {nodeJ}, {}},
+		},
+		{
+			test:                "by banned and selector match all",
+			nodes:               []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD, nodeE, nodeF, nodeG, nodeH, nodeJ},
+			pageSize:            2,
+			byBanned:            &bannedFalse,
+			bySelectors:         bySelectors(datastore.MatchAll, "S2", "S3"),
+			expectNodesOut:      []*common.AttestedNode{nodeJ},
+			expectPagedTokensIn: []string{"", "9"},
+			expectPagedNodesOut: [][]*common.AttestedNode{{nodeJ}, {}},
+		},
+		// By CanReattest=true
+		{
+			test:                "by CanReattest=true",
+			nodes:               []*common.AttestedNode{nodeA, nodeI},
+			byAttestationType:   "T1",
 			bySelectors:         nil,
 			byCanReattest:       &canReattestTrue,
 			expectNodesOut:      []*common.AttestedNode{nodeI},
@@ -1224,11 +2326,12 @@ func (s *PluginSuite) TestListAttestedNodes() {
 					req := &datastore.ListAttestedNodesRequest{
 						Pagination:        pagination,
 						ByExpiresBefore:   tt.byExpiresBefore,
+						ByExpiresAfter:    tt.byExpiresAfter,
 						ByAttestationType: tt.byAttestationType,
 						BySelectorMatch:   tt.bySelectors,
 						ByBanned:          tt.byBanned,
 						ByCanReattest:     tt.byCanReattest,
-						FetchSelectors:    withSelectors,
+						FetchSelectors:    &withSelectors,
 					}
 
 					for i := 0; ; i++ {
@@ -1293,6 +2396,83 @@ func (s *PluginSuite) TestListAttestedNodes() {
 	}
 }
 
+func (s *PluginSuite) TestListAttestedNodesByUpdatedAfter() {
+	// Timestamps are stored with second precision
+	threshold := time.Unix(time.Now().Unix(), 0)
+
+	makeNode := func(suffix, attestationType string, updatedAt time.Time) *common.AttestedNode {
+		node, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:            makeID(suffix),
+			AttestationDataType: attestationType,
+			CertSerialNumber:    "badcafe",
+			CertNotAfter:        threshold.Add(time.Hour).Unix(),
+		})
+		s.Require().NoError(err)
+		s.Require().NoError(s.ds.db.Model(&AttestedNode{}).Where("spiffe_id = ?", node.SpiffeId).UpdateColumn("updated_at", updatedAt).Error)
+		return node
+	}
+
+	nodeA := makeNode("A", "T1", threshold.Add(-time.Hour))
+	nodeB := makeNode("B", "T2", threshold)
+	nodeC := makeNode("C", "T1", threshold)
+	nodeD := makeNode("D", "T1", threshold.Add(time.Hour))
+
+	listNodes := func(req *datastore.ListAttestedNodesRequest) []*common.AttestedNode {
+		var nodes []*common.AttestedNode
+		for {
+			resp, err := s.ds.ListAttestedNodes(ctx, req)
+			s.Require().NoError(err)
+			nodes = append(nodes, resp.Nodes...)
+			if resp.Pagination == nil || resp.Pagination.Token == "" {
+				return nodes
+			}
+			req.Pagination = resp.Pagination
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		req         *datastore.ListAttestedNodesRequest
+		expectNodes []*common.AttestedNode
+	}{
+		{
+			name:        "no filter",
+			req:         &datastore.ListAttestedNodesRequest{},
+			expectNodes: []*common.AttestedNode{nodeA, nodeB, nodeC, nodeD},
+		},
+		{
+			name:        "updated after threshold includes nodes updated on it",
+			req:         &datastore.ListAttestedNodesRequest{ByUpdatedAfter: threshold},
+			expectNodes: []*common.AttestedNode{nodeB, nodeC, nodeD},
+		},
+		{
+			name:        "updated after threshold excludes nodes updated before it",
+			req:         &datastore.ListAttestedNodesRequest{ByUpdatedAfter: threshold.Add(time.Second)},
+			expectNodes: []*common.AttestedNode{nodeD},
+		},
+		{
+			name: "updated after threshold and by attestation type",
+			req: &datastore.ListAttestedNodesRequest{
+				ByUpdatedAfter:    threshold,
+				ByAttestationType: "T1",
+			},
+			expectNodes: []*common.AttestedNode{nodeC, nodeD},
+		},
+		{
+			name: "updated after threshold with selectors and pagination",
+			req: &datastore.ListAttestedNodesRequest{
+				ByUpdatedAfter: threshold,
+				Pagination:     &datastore.Pagination{PageSize: 1},
+			},
+			expectNodes: []*common.AttestedNode{nodeB, nodeC, nodeD},
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			spiretest.AssertProtoListEqual(t, tt.expectNodes, listNodes(tt.req))
+		})
+	}
+}
+
 func (s *PluginSuite) TestUpdateAttestedNode() {
 	// Current nodes values
 	nodeID := "spiffe-id"
@@ -1393,7 +2573,7 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 			s.ds = s.newPlugin()
 			defer s.ds.Close()
 
-			_, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			createdNode, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
 				SpiffeId:            nodeID,
 				AttestationDataType: attestationType,
 				CertSerialNumber:    serial,
@@ -1412,13 +2592,18 @@ func (s *PluginSuite) TestUpdateAttestedNode() {
 			}
 			s.Require().NoError(err)
 			s.Require().NotNil(updatedNode)
-			s.RequireProtoEqual(tt.expUpdatedNode, updatedNode)
+
+			// None of the updates record a reattestation
+			expUpdatedNode := proto.Clone(tt.expUpdatedNode).(*common.AttestedNode)
+			expUpdatedNode.AttestedAt = createdNode.AttestedAt
+			expUpdatedNode.LastAttestedAt = createdNode.LastAttestedAt
+			s.RequireProtoEqual(expUpdatedNode, updatedNode)
 
 			// Check a fresh fetch shows the updated attested node
 			attestedNode, err := s.ds.FetchAttestedNode(ctx, tt.updateNode.SpiffeId)
 			s.Require().NoError(err)
 			s.Require().NotNil(attestedNode)
-			s.RequireProtoEqual(tt.expUpdatedNode, attestedNode)
+			s.RequireProtoEqual(expUpdatedNode, attestedNode)
 		})
 	}
 }
@@ -1443,12 +2628,12 @@ func (s *PluginSuite) TestDeleteAttestedNode() {
 	})
 
 	s.Run("delete attested node that don't have selectors associated", func() {
-		_, err := s.ds.CreateAttestedNode(ctx, entryFoo)
+		createdNode, err := s.ds.CreateAttestedNode(ctx, entryFoo)
 		s.Require().NoError(err)
 
 		deletedNode, err := s.ds.DeleteAttestedNode(ctx, entryFoo.SpiffeId)
 		s.Require().NoError(err)
-		s.AssertProtoEqual(entryFoo, deletedNode)
+		s.AssertProtoEqual(createdNode, deletedNode)
 
 		attestedNode, err := s.ds.FetchAttestedNode(ctx, entryFoo.SpiffeId)
 		s.Require().NoError(err)
@@ -1463,7 +2648,7 @@ func (s *PluginSuite) TestDeleteAttestedNode() {
 			{Type: "TYPE4", Value: "VALUE4"},
 		}
 
-		_, err := s.ds.CreateAttestedNode(ctx, entryFoo)
+		createdNode, err := s.ds.CreateAttestedNode(ctx, entryFoo)
 		s.Require().NoError(err)
 		// create selectors for entryFoo
 		err = s.ds.SetNodeSelectors(ctx, entryFoo.SpiffeId, selectors)
@@ -1472,810 +2657,2415 @@ func (s *PluginSuite) TestDeleteAttestedNode() {
 		err = s.ds.SetNodeSelectors(ctx, entryBar.SpiffeId, selectors)
 		s.Require().NoError(err)
 
-		nodeSelectors, err := s.ds.GetNodeSelectors(ctx, entryFoo.SpiffeId, datastore.RequireCurrent)
+		nodeSelectors, err := s.ds.GetNodeSelectors(ctx, entryFoo.SpiffeId, datastore.RequireCurrent, false)
 		s.Require().NoError(err)
 		s.Equal(selectors, nodeSelectors)
 
 		deletedNode, err := s.ds.DeleteAttestedNode(ctx, entryFoo.SpiffeId)
 		s.Require().NoError(err)
-		s.AssertProtoEqual(entryFoo, deletedNode)
+		s.AssertProtoEqual(createdNode, deletedNode)
 
 		attestedNode, err := s.ds.FetchAttestedNode(ctx, deletedNode.SpiffeId)
 		s.Require().NoError(err)
 		s.Nil(attestedNode)
 
 		// check that selectors for deleted node are gone
-		deletedSelectors, err := s.ds.GetNodeSelectors(ctx, deletedNode.SpiffeId, datastore.RequireCurrent)
+		deletedSelectors, err := s.ds.GetNodeSelectors(ctx, deletedNode.SpiffeId, datastore.RequireCurrent, false)
 		s.Require().NoError(err)
 		s.Nil(deletedSelectors)
 
 		// check that selectors for entryBar are still there
-		nodeSelectors, err = s.ds.GetNodeSelectors(ctx, entryBar.SpiffeId, datastore.RequireCurrent)
+		nodeSelectors, err = s.ds.GetNodeSelectors(ctx, entryBar.SpiffeId, datastore.RequireCurrent, false)
 		s.Require().NoError(err)
 		s.Equal(selectors, nodeSelectors)
 	})
 }
 
-func (s *PluginSuite) TestListAttestedNodeEvents() {
-	var expectedEvents []datastore.AttestedNodeEvent
+func (s *PluginSuite) TestAttestedNodeAliases() {
+	newNode := func(spiffeID string, notAfter time.Time) *common.AttestedNode {
+		node, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:            spiffeID,
+			AttestationDataType: "aws-tag",
+			CertSerialNumber:    "badcafe",
+			CertNotAfter:        notAfter.Unix(),
+		})
+		s.Require().NoError(err)
+		return node
+	}
+	node := newNode("spiffe://example.org/spire/agent/new", time.Now().Add(time.Hour))
+	other := newNode("spiffe://example.org/spire/agent/other", time.Now().Add(time.Hour))
 
-	// Create an attested node
-	node1, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
-		SpiffeId:            "foo",
+	const (
+		alias1 = "spiffe://example.org/spire/agent/old1"
+		alias2 = "spiffe://example.org/spire/agent/old2"
+	)
+	s.Require().NoError(s.ds.AddAttestedNodeAlias(ctx, node.SpiffeId, alias2))
+	s.Require().NoError(s.ds.AddAttestedNodeAlias(ctx, node.SpiffeId, alias1))
+
+	aliases, err := s.ds.ListAttestedNodeAliases(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{alias1, alias2}, aliases)
+
+	s.Run("fetching by alias returns the canonical node", func() {
+		fetched, err := s.ds.FetchAttestedNode(ctx, alias1)
+		s.Require().NoError(err)
+		s.AssertProtoEqual(node, fetched)
+	})
+
+	s.Run("alias of another node", func() {
+		err := s.ds.AddAttestedNodeAlias(ctx, other.SpiffeId, alias1)
+		s.RequireGRPCStatusContains(err, codes.AlreadyExists, "datastore-sql: ")
+	})
+
+	s.Run("alias colliding with a node", func() {
+		err := s.ds.AddAttestedNodeAlias(ctx, node.SpiffeId, other.SpiffeId)
+		s.RequireGRPCStatus(err, codes.AlreadyExists, `datastore-sql: alias "spiffe://example.org/spire/agent/other" is the SPIFFE ID of an attested node`)
+	})
+
+	s.Run("node colliding with an alias", func() {
+		_, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:            alias1,
+			AttestationDataType: "aws-tag",
+			CertSerialNumber:    "badcafe",
+			CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		})
+		s.RequireGRPCStatus(err, codes.AlreadyExists, `datastore-sql: SPIFFE ID "spiffe://example.org/spire/agent/old1" is an alias of another attested node`)
+	})
+
+	s.Run("alias of a missing node", func() {
+		err := s.ds.AddAttestedNodeAlias(ctx, "spiffe://example.org/spire/agent/missing", "spiffe://example.org/spire/agent/alias")
+		s.RequireGRPCStatus(err, codes.NotFound, `datastore-sql: attested node "spiffe://example.org/spire/agent/missing" not found`)
+	})
+
+	s.Run("missing alias", func() {
+		err := s.ds.AddAttestedNodeAlias(ctx, node.SpiffeId, "")
+		s.RequireGRPCStatus(err, codes.InvalidArgument, "datastore-validation: invalid request: missing SPIFFE ID or alias")
+	})
+
+	s.Run("remove alias", func() {
+		s.Require().NoError(s.ds.RemoveAttestedNodeAlias(ctx, alias2))
+
+		fetched, err := s.ds.FetchAttestedNode(ctx, alias2)
+		s.Require().NoError(err)
+		s.Require().Nil(fetched)
+
+		err = s.ds.RemoveAttestedNodeAlias(ctx, alias2)
+		s.RequireGRPCStatus(err, codes.NotFound, `datastore-sql: attested node alias "spiffe://example.org/spire/agent/old2" not found`)
+	})
+
+	s.Run("aliases are deleted with the node", func() {
+		_, err := s.ds.DeleteAttestedNode(ctx, node.SpiffeId)
+		s.Require().NoError(err)
+
+		aliases, err := s.ds.ListAttestedNodeAliases(ctx, node.SpiffeId)
+		s.Require().NoError(err)
+		s.Require().Empty(aliases)
+
+		fetched, err := s.ds.FetchAttestedNode(ctx, alias1)
+		s.Require().NoError(err)
+		s.Require().Nil(fetched)
+
+		// The alias is free to be used again
+		s.Require().NoError(s.ds.AddAttestedNodeAlias(ctx, other.SpiffeId, alias1))
+	})
+
+	s.Run("aliases are deleted when the node is pruned", func() {
+		expired := newNode("spiffe://example.org/spire/agent/expired", time.Now().Add(-time.Hour))
+		s.Require().NoError(s.ds.AddAttestedNodeAlias(ctx, expired.SpiffeId, alias2))
+
+		_, err := s.ds.PruneAttestedNodes(ctx, time.Now())
+		s.Require().NoError(err)
+
+		aliases, err := s.ds.ListAttestedNodeAliases(ctx, expired.SpiffeId)
+		s.Require().NoError(err)
+		s.Require().Empty(aliases)
+
+		fetched, err := s.ds.FetchAttestedNode(ctx, alias2)
+		s.Require().NoError(err)
+		s.Require().Nil(fetched)
+	})
+}
+
+func (s *PluginSuite) TestFetchAttestedNodeSerialHistory() {
+	node := &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/foo",
 		AttestationDataType: "aws-tag",
-		CertSerialNumber:    "badcafe",
+		CertSerialNumber:    "serial-0",
 		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
-	})
+	}
+	_, err := s.ds.CreateAttestedNode(ctx, node)
 	s.Require().NoError(err)
-	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, node1.SpiffeId)
 
-	// Create selectors for attested node
-	selectors1 := []*common.Selector{
-		{Type: "FOO1", Value: "1"},
+	// No serial number has been superseded yet
+	history, err := s.ds.FetchAttestedNodeSerialHistory(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Empty(history)
+
+	s.ds.nodeSerialHistorySize = 3
+
+	// Rotate the serial number through the "new" serial number a few times,
+	// more than the history can hold, so the oldest records are pruned.
+	for i := 1; i <= 4; i++ {
+		newSerial := fmt.Sprintf("serial-%d", i)
+		_, err = s.ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:            node.SpiffeId,
+			NewCertSerialNumber: newSerial,
+		}, &common.AttestedNodeMask{NewCertSerialNumber: true})
+		s.Require().NoError(err)
+
+		_, err = s.ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:         node.SpiffeId,
+			CertSerialNumber: newSerial,
+		}, &common.AttestedNodeMask{CertSerialNumber: true, NewCertSerialNumber: true})
+		s.Require().NoError(err)
 	}
-	s.setNodeSelectors(node1.SpiffeId, selectors1)
-	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, node1.SpiffeId)
 
-	// Create second attested node
-	node2, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
-		SpiffeId:            "bar",
+	history, err = s.ds.FetchAttestedNodeSerialHistory(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Require().Len(history, 3)
+	for i, serial := range []string{"serial-3", "serial-2", "serial-1"} {
+		s.Equal(serial, history[i].SerialNumber)
+		s.Equal(datastore.SerialReasonRotated, history[i].Reason)
+		s.False(history[i].SupersededAt.IsZero())
+	}
+
+	// Updates that do not change the serial number are not recorded
+	_, err = s.ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:         node.SpiffeId,
+		CertSerialNumber: "serial-4",
+		CanReattest:      true,
+	}, &common.AttestedNodeMask{CertSerialNumber: true, CanReattest: true})
+	s.Require().NoError(err)
+
+	// Replacing the serial number without a rotation
+	_, err = s.ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:         node.SpiffeId,
+		CertSerialNumber: "serial-5",
+	}, &common.AttestedNodeMask{CertSerialNumber: true})
+	s.Require().NoError(err)
+
+	// Banning the node
+	_, err = s.ds.UpdateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId: node.SpiffeId,
+	}, &common.AttestedNodeMask{CertSerialNumber: true, NewCertSerialNumber: true})
+	s.Require().NoError(err)
+
+	history, err = s.ds.FetchAttestedNodeSerialHistory(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Require().Len(history, 3)
+	s.Equal("serial-5", history[0].SerialNumber)
+	s.Equal(datastore.SerialReasonBanned, history[0].Reason)
+	s.Equal("serial-4", history[1].SerialNumber)
+	s.Equal(datastore.SerialReasonReplaced, history[1].Reason)
+	s.Equal("serial-3", history[2].SerialNumber)
+	s.Equal(datastore.SerialReasonRotated, history[2].Reason)
+
+	// The history is removed along with the node
+	_, err = s.ds.DeleteAttestedNode(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	history, err = s.ds.FetchAttestedNodeSerialHistory(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Empty(history)
+}
+
+func (s *PluginSuite) TestPromoteAttestedNodeSerial() {
+	now := time.Now()
+	node, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/foo",
 		AttestationDataType: "aws-tag",
-		CertSerialNumber:    "badcafe",
-		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		CertSerialNumber:    "serial-0",
+		CertNotAfter:        now.Add(time.Hour).Unix(),
+		NewCertSerialNumber: "serial-1",
+		NewCertNotAfter:     now.Add(2 * time.Hour).Unix(),
+		CanReattest:         true,
 	})
 	s.Require().NoError(err)
-	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, node2.SpiffeId)
-
-	// Create selectors for second attested node
-	selectors2 := []*common.Selector{
-		{Type: "BAR1", Value: "1"},
-	}
-	s.setNodeSelectors(node2.SpiffeId, selectors2)
-	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, node2.SpiffeId)
 
-	// Update first attested node
-	updatedNode, err := s.ds.UpdateAttestedNode(ctx, node1, nil)
+	resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
 	s.Require().NoError(err)
-	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, updatedNode.SpiffeId)
+	expectedEvents := resp.Events
 
-	// Update selectors for first attested node
-	updatedSelectors := []*common.Selector{
-		{Type: "FOO2", Value: "2"},
+	expectedNode := &common.AttestedNode{
+		SpiffeId:            node.SpiffeId,
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "serial-1",
+		CertNotAfter:        now.Add(2 * time.Hour).Unix(),
+		CanReattest:         true,
+		AttestedAt:          node.AttestedAt,
+		LastAttestedAt:      node.LastAttestedAt,
 	}
-	s.setNodeSelectors(updatedNode.SpiffeId, updatedSelectors)
-	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, updatedNode.SpiffeId)
+	promoted, err := s.ds.PromoteAttestedNodeSerial(ctx, node.SpiffeId, "serial-1")
+	s.Require().NoError(err)
+	s.AssertProtoEqual(expectedNode, promoted)
 
-	// Delete second atttested node
-	deletedNode, err := s.ds.DeleteAttestedNode(ctx, node2.SpiffeId)
+	fetched, err := s.ds.FetchAttestedNode(ctx, node.SpiffeId)
 	s.Require().NoError(err)
-	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, deletedNode.SpiffeId)
+	s.AssertProtoEqual(expectedNode, fetched)
+	s.checkAttestedNodeEvents(expectedEvents, node.SpiffeId)
 
-	// Delete selectors for second attested node
-	s.setNodeSelectors(deletedNode.SpiffeId, nil)
-	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, deletedNode.SpiffeId)
+	// The superseded serial number is recorded as rotated
+	history, err := s.ds.FetchAttestedNodeSerialHistory(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Require().Len(history, 1)
+	s.Equal("serial-0", history[0].SerialNumber)
+	s.Equal(datastore.SerialReasonRotated, history[0].Reason)
 
-	// Check filtering events by id
-	tests := []struct {
-		name                 string
-		greaterThanEventID   uint
-		lessThanEventID      uint
-		expectedEvents       []datastore.AttestedNodeEvent
-		expectedFirstEventID uint
-		expectedLastEventID  uint
-		expectedErr          string
-	}{
-		{
-			name:                 "All Events",
-			greaterThanEventID:   0,
-			expectedFirstEventID: 1,
-			expectedLastEventID:  uint(len(expectedEvents)),
-			expectedEvents:       expectedEvents,
-		},
-		{
-			name:                 "Greater than half of the Events",
-			greaterThanEventID:   uint(len(expectedEvents) / 2),
-			expectedFirstEventID: uint(len(expectedEvents)/2) + 1,
-			expectedLastEventID:  uint(len(expectedEvents)),
-			expectedEvents:       expectedEvents[len(expectedEvents)/2:],
-		},
-		{
-			name:                 "Less than half of the Events",
-			lessThanEventID:      uint(len(expectedEvents) / 2),
-			expectedFirstEventID: 1,
-			expectedLastEventID:  uint(len(expectedEvents)/2) - 1,
-			expectedEvents:       expectedEvents[:len(expectedEvents)/2-1],
-		},
-		{
-			name:               "Greater than largest Event ID",
-			greaterThanEventID: uint(len(expectedEvents)),
-			expectedEvents:     []datastore.AttestedNodeEvent{},
-		},
-		{
-			name:               "Setting both greater and less than",
-			greaterThanEventID: 1,
-			lessThanEventID:    1,
-			expectedErr:        "rpc error: code = Unknown desc = datastore-sql: can't set both greater and less than event id",
-		},
-	}
-	for _, test := range tests {
-		s.T().Run(test.name, func(t *testing.T) {
-			resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{
-				GreaterThanEventID: test.greaterThanEventID,
-				LessThanEventID:    test.lessThanEventID,
-			})
-			if test.expectedErr != "" {
-				require.EqualError(t, err, test.expectedErr)
-				return
-			}
-			s.Require().NoError(err)
+	// The new serial number was already promoted
+	_, err = s.ds.PromoteAttestedNodeSerial(ctx, node.SpiffeId, "serial-1")
+	s.Require().ErrorIs(err, datastore.ErrAttestedNodeSerialConflict)
+	s.RequireGRPCStatus(err, codes.Aborted, "attested node new serial number does not match the expected one")
 
-			s.Require().Equal(test.expectedEvents, resp.Events)
-			if len(resp.Events) > 0 {
-				s.Require().Equal(test.expectedFirstEventID, resp.Events[0].EventID)
-				s.Require().Equal(test.expectedLastEventID, resp.Events[len(resp.Events)-1].EventID)
-			}
-		})
-	}
+	// The node is left untouched
+	fetched, err = s.ds.FetchAttestedNode(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.AssertProtoEqual(expectedNode, fetched)
+
+	_, err = s.ds.PromoteAttestedNodeSerial(ctx, "spiffe://example.org/missing", "serial-1")
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
 }
 
-func (s *PluginSuite) TestPruneAttestedNodeEvents() {
+func (s *PluginSuite) TestPromoteAttestedNodeSerialConcurrently() {
 	node, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
-		SpiffeId:            "foo",
+		SpiffeId:            "spiffe://example.org/foo",
 		AttestationDataType: "aws-tag",
-		CertSerialNumber:    "badcafe",
+		CertSerialNumber:    "serial-0",
 		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		NewCertSerialNumber: "serial-1",
+		NewCertNotAfter:     time.Now().Add(2 * time.Hour).Unix(),
 	})
 	s.Require().NoError(err)
 
-	resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
-	s.Require().NoError(err)
-	s.Require().Equal(node.SpiffeId, resp.Events[0].SpiffeID)
+	// Several servers see the agent present its new SVID at the same time.
+	// Only one of them promotes the serial number.
+	const servers = 5
+	errs := make(chan error, servers)
+	for range servers {
+		go func() {
+			_, err := s.ds.PromoteAttestedNodeSerial(ctx, node.SpiffeId, "serial-1")
+			errs <- err
+		}()
+	}
 
-	for _, tt := range []struct {
-		name           string
-		olderThan      time.Duration
-		expectedEvents []datastore.AttestedNodeEvent
-	}{
-		{
-			name:      "Don't prune valid events",
-			olderThan: 1 * time.Hour,
-			expectedEvents: []datastore.AttestedNodeEvent{
-				{
-					EventID:  1,
-					SpiffeID: node.SpiffeId,
-				},
-			},
-		},
-		{
-			name:           "Prune old events",
-			olderThan:      0 * time.Second,
-			expectedEvents: []datastore.AttestedNodeEvent{},
-		},
-	} {
-		s.T().Run(tt.name, func(t *testing.T) {
-			s.Require().Eventuallyf(func() bool {
-				err = s.ds.PruneAttestedNodeEvents(ctx, tt.olderThan)
-				s.Require().NoError(err)
-				resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
-				s.Require().NoError(err)
-				return reflect.DeepEqual(tt.expectedEvents, resp.Events)
-			}, 10*time.Second, 50*time.Millisecond, "Failed to prune entries correctly")
-		})
+	promoted := 0
+	for range servers {
+		err := <-errs
+		if err == nil {
+			promoted++
+			continue
+		}
+		s.Require().ErrorIs(err, datastore.ErrAttestedNodeSerialConflict)
 	}
+	s.Equal(1, promoted)
+
+	history, err := s.ds.FetchAttestedNodeSerialHistory(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	s.Require().Len(history, 1)
+	s.Equal("serial-0", history[0].SerialNumber)
 }
 
-func (s *PluginSuite) TestNodeSelectors() {
-	foo1 := []*common.Selector{
-		{Type: "FOO1", Value: "1"},
+func (s *PluginSuite) TestPruneAttestedNodes() {
+	now := time.Now()
+	selectors := []*common.Selector{
+		{Type: "TYPE1", Value: "VALUE1"},
+		{Type: "TYPE2", Value: "VALUE2"},
 	}
-	foo2 := []*common.Selector{
-		{Type: "FOO2", Value: "1"},
+
+	var expiredIDs []string
+	for i := range 5 {
+		node := &common.AttestedNode{
+			SpiffeId:            fmt.Sprintf("spiffe://example.org/expired-%d", i),
+			AttestationDataType: "aws-tag",
+			CertSerialNumber:    "badcafe",
+			CertNotAfter:        now.Add(-time.Hour).Unix(),
+		}
+		_, err := s.ds.CreateAttestedNode(ctx, node)
+		s.Require().NoError(err)
+		s.Require().NoError(s.ds.SetNodeSelectors(ctx, node.SpiffeId, selectors))
+		expiredIDs = append(expiredIDs, node.SpiffeId)
 	}
-	bar := []*common.Selector{
-		{Type: "BAR", Value: "FIGHT"},
+
+	activeNode := &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/active",
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "badcafe",
+		CertNotAfter:        now.Add(time.Hour).Unix(),
 	}
+	activeNode, err := s.ds.CreateAttestedNode(ctx, activeNode)
+	s.Require().NoError(err)
+	s.Require().NoError(s.ds.SetNodeSelectors(ctx, activeNode.SpiffeId, selectors))
 
-	// assert there are no selectors for foo
-	selectors := s.getNodeSelectors("foo", datastore.TolerateStale)
-	s.Require().Empty(selectors)
-	selectors = s.getNodeSelectors("foo", datastore.RequireCurrent)
-	s.Require().Empty(selectors)
+	eventsResp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	lastEventID := eventsResp.Events[len(eventsResp.Events)-1].EventID
 
-	// set selectors on foo and bar
-	s.setNodeSelectors("foo", foo1)
-	s.setNodeSelectors("bar", bar)
+	// Use a batch size that does not evenly divide the number of expired
+	// nodes so that multiple batches, including a partial one, are deleted.
+	s.ds.pruneBatchSize = 2
 
-	// get foo selectors
-	selectors = s.getNodeSelectors("foo", datastore.TolerateStale)
-	s.RequireProtoListEqual(foo1, selectors)
-	selectors = s.getNodeSelectors("foo", datastore.RequireCurrent)
-	s.RequireProtoListEqual(foo1, selectors)
+	pruned, err := s.ds.PruneAttestedNodes(ctx, now)
+	s.Require().NoError(err)
+	s.Require().Equal(len(expiredIDs), pruned)
 
-	// replace foo selectors
-	s.setNodeSelectors("foo", foo2)
-	selectors = s.getNodeSelectors("foo", datastore.TolerateStale)
-	s.RequireProtoListEqual(foo2, selectors)
-	selectors = s.getNodeSelectors("foo", datastore.RequireCurrent)
-	s.RequireProtoListEqual(foo2, selectors)
+	for _, spiffeID := range expiredIDs {
+		node, err := s.ds.FetchAttestedNode(ctx, spiffeID)
+		s.Require().NoError(err)
+		s.Nil(node)
 
-	// delete foo selectors
-	s.setNodeSelectors("foo", []*common.Selector{})
-	selectors = s.getNodeSelectors("foo", datastore.TolerateStale)
-	s.Require().Empty(selectors)
-	selectors = s.getNodeSelectors("foo", datastore.RequireCurrent)
-	s.Require().Empty(selectors)
+		nodeSelectors, err := s.ds.GetNodeSelectors(ctx, spiffeID, datastore.RequireCurrent, false)
+		s.Require().NoError(err)
+		s.Empty(nodeSelectors)
+	}
 
-	// get bar selectors (make sure they weren't impacted by deleting foo)
-	selectors = s.getNodeSelectors("bar", datastore.TolerateStale)
-	s.RequireProtoListEqual(bar, selectors)
-	// get bar selectors (make sure they weren't impacted by deleting foo)
-	selectors = s.getNodeSelectors("bar", datastore.RequireCurrent)
-	s.RequireProtoListEqual(bar, selectors)
-}
+	node, err := s.ds.FetchAttestedNode(ctx, activeNode.SpiffeId)
+	s.Require().NoError(err)
+	s.AssertProtoEqual(activeNode, node)
 
-func (s *PluginSuite) TestListNodeSelectors() {
-	s.T().Run("no selectors exist", func(t *testing.T) {
-		req := &datastore.ListNodeSelectorsRequest{}
-		resp := s.listNodeSelectors(req)
-		assertSelectorsEqual(t, nil, resp.Selectors)
-	})
+	nodeSelectors, err := s.ds.GetNodeSelectors(ctx, activeNode.SpiffeId, datastore.RequireCurrent, false)
+	s.Require().NoError(err)
+	s.Equal(selectors, nodeSelectors)
 
-	const numNonExpiredAttNodes = 3
-	const attestationDataType = "fake_nodeattestor"
-	nonExpiredAttNodes := make([]*common.AttestedNode, numNonExpiredAttNodes)
-	now := time.Now()
-	for i := range numNonExpiredAttNodes {
-		nonExpiredAttNodes[i] = &common.AttestedNode{
-			SpiffeId:            fmt.Sprintf("spiffe://example.org/non-expired-node-%d", i),
-			AttestationDataType: attestationDataType,
-			CertSerialNumber:    fmt.Sprintf("non-expired serial %d-1", i),
-			CertNotAfter:        now.Add(time.Hour).Unix(),
-			NewCertSerialNumber: fmt.Sprintf("non-expired serial %d-2", i),
-			NewCertNotAfter:     now.Add(2 * time.Hour).Unix(),
-		}
+	// an event is written for every pruned node
+	eventsResp, err = s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{
+		GreaterThanEventID: lastEventID,
+	})
+	s.Require().NoError(err)
+	var eventIDs []string
+	for _, event := range eventsResp.Events {
+		eventIDs = append(eventIDs, event.SpiffeID)
 	}
+	s.ElementsMatch(expiredIDs, eventIDs)
 
-	const numExpiredAttNodes = 2
-	expiredAttNodes := make([]*common.AttestedNode, numExpiredAttNodes)
-	for i := range numExpiredAttNodes {
-		expiredAttNodes[i] = &common.AttestedNode{
-			SpiffeId:            fmt.Sprintf("spiffe://example.org/expired-node-%d", i),
-			AttestationDataType: attestationDataType,
-			CertSerialNumber:    fmt.Sprintf("expired serial %d-1", i),
-			CertNotAfter:        now.Add(-24 * time.Hour).Unix(),
-			NewCertSerialNumber: fmt.Sprintf("expired serial %d-2", i),
-			NewCertNotAfter:     now.Add(-12 * time.Hour).Unix(),
-		}
-	}
+	// pruning again is a no-op
+	pruned, err = s.ds.PruneAttestedNodes(ctx, now)
+	s.Require().NoError(err)
+	s.Zero(pruned)
+}
 
-	allAttNodesToCreate := make([]*common.AttestedNode, 0, len(nonExpiredAttNodes)+len(expiredAttNodes))
-	allAttNodesToCreate = append(allAttNodesToCreate, nonExpiredAttNodes...)
-	allAttNodesToCreate = append(allAttNodesToCreate, expiredAttNodes...)
-	selectorMap := make(map[string][]*common.Selector)
-	for i, n := range allAttNodesToCreate {
-		_, err := s.ds.CreateAttestedNode(ctx, n)
+func (s *PluginSuite) TestListAttestedNodesToPrune() {
+	now := time.Now()
+	var expiredIDs []string
+	for i, notAfter := range []time.Time{now.Add(-time.Hour), now.Add(time.Hour), now.Add(-time.Minute)} {
+		node := &common.AttestedNode{
+			SpiffeId:            fmt.Sprintf("spiffe://example.org/node-%d", i),
+			AttestationDataType: "aws-tag",
+			CertSerialNumber:    "badcafe",
+			CertNotAfter:        notAfter.Unix(),
+		}
+		_, err := s.ds.CreateAttestedNode(ctx, node)
 		s.Require().NoError(err)
-
-		selectors := []*common.Selector{
-			{
-				Type:  "foo",
-				Value: strconv.Itoa(i),
-			},
+		if notAfter.Before(now) {
+			expiredIDs = append(expiredIDs, node.SpiffeId)
 		}
-
-		s.setNodeSelectors(n.SpiffeId, selectors)
-		selectorMap[n.SpiffeId] = selectors
 	}
 
-	nonExpiredSelectorsMap := make(map[string][]*common.Selector, numNonExpiredAttNodes)
-	for i := range numNonExpiredAttNodes {
-		spiffeID := nonExpiredAttNodes[i].SpiffeId
-		nonExpiredSelectorsMap[spiffeID] = selectorMap[spiffeID]
-	}
+	toPrune, err := s.ds.ListAttestedNodesToPrune(ctx, now)
+	s.Require().NoError(err)
+	s.Equal(expiredIDs, toPrune)
 
-	s.T().Run("list all", func(t *testing.T) {
-		req := &datastore.ListNodeSelectorsRequest{}
-		resp := s.listNodeSelectors(req)
-		assertSelectorsEqual(t, selectorMap, resp.Selectors)
-	})
+	// nothing is deleted by the dry run
+	count, err := s.ds.CountAttestedNodes(ctx, &datastore.CountAttestedNodesRequest{})
+	s.Require().NoError(err)
+	s.Equal(int32(3), count)
 
-	s.T().Run("list unexpired", func(t *testing.T) {
-		req := &datastore.ListNodeSelectorsRequest{
-			ValidAt: now,
-		}
-		resp := s.listNodeSelectors(req)
-		assertSelectorsEqual(t, nonExpiredSelectorsMap, resp.Selectors)
-	})
+	pruned, err := s.ds.PruneAttestedNodes(ctx, now)
+	s.Require().NoError(err)
+	s.Equal(len(toPrune), pruned)
+
+	toPrune, err = s.ds.ListAttestedNodesToPrune(ctx, now)
+	s.Require().NoError(err)
+	s.Empty(toPrune)
 }
 
-func (s *PluginSuite) TestListNodeSelectorsGroupsBySpiffeID() {
-	insertSelector := func(id int, spiffeID, selectorType, selectorValue string) {
-		query := maybeRebind(s.ds.db.databaseType, "INSERT INTO node_resolver_map_entries(id, spiffe_id, type, value) VALUES (?, ?, ?, ?)")
-		_, err := s.ds.db.raw.Exec(query, id, spiffeID, selectorType, selectorValue)
+func (s *PluginSuite) TestSetAttestedNodesReattest() {
+	var spiffeIDs []string
+	for i, canReattest := range []bool{false, false, true, false} {
+		node := &common.AttestedNode{
+			SpiffeId:            fmt.Sprintf("spiffe://example.org/node-%d", i),
+			AttestationDataType: "aws-tag",
+			CertSerialNumber:    "badcafe",
+			CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+			CanReattest:         canReattest,
+		}
+		_, err := s.ds.CreateAttestedNode(ctx, node)
 		s.Require().NoError(err)
+		spiffeIDs = append(spiffeIDs, node.SpiffeId)
 	}
 
-	// Insert selectors out of order in respect to the SPIFFE ID so
-	// that we can assert that the datastore aggregates the results correctly.
-	insertSelector(1, "spiffe://example.org/node3", "A", "a")
-	insertSelector(2, "spiffe://example.org/node2", "B", "b")
-	insertSelector(3, "spiffe://example.org/node3", "C", "c")
-	insertSelector(4, "spiffe://example.org/node1", "D", "d")
-	insertSelector(5, "spiffe://example.org/node2", "E", "e")
-	insertSelector(6, "spiffe://example.org/node3", "F", "f")
+	eventsResp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	lastEventID := eventsResp.Events[len(eventsResp.Events)-1].EventID
+
+	// node-2 already can reattest, node-3 is not included and the unknown
+	// node is ignored
+	affected, err := s.ds.SetAttestedNodesReattest(ctx, []string{
+		spiffeIDs[0],
+		spiffeIDs[1],
+		spiffeIDs[2],
+		"spiffe://example.org/unknown",
+	}, true)
+	s.Require().NoError(err)
+	s.Equal(2, affected)
 
-	resp := s.listNodeSelectors(&datastore.ListNodeSelectorsRequest{})
-	assertSelectorsEqual(s.T(), map[string][]*common.Selector{
-		"spiffe://example.org/node1": {{Type: "D", Value: "d"}},
-		"spiffe://example.org/node2": {{Type: "B", Value: "b"}, {Type: "E", Value: "e"}},
-		"spiffe://example.org/node3": {{Type: "A", Value: "a"}, {Type: "C", Value: "c"}, {Type: "F", Value: "f"}},
-	}, resp.Selectors)
-}
+	canReattest := true
+	resp, err := s.ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{
+		ByCanReattest: &canReattest,
+	})
+	s.Require().NoError(err)
+	var reattestable []string
+	for _, node := range resp.Nodes {
+		reattestable = append(reattestable, node.SpiffeId)
+	}
+	s.ElementsMatch(spiffeIDs[:3], reattestable)
 
-func (s *PluginSuite) TestSetNodeSelectorsUnderLoad() {
-	selectors := []*common.Selector{
-		{Type: "TYPE", Value: "VALUE"},
+	// an event is written for every node whose flag changed
+	eventsResp, err = s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{
+		GreaterThanEventID: lastEventID,
+	})
+	s.Require().NoError(err)
+	var eventIDs []string
+	for _, event := range eventsResp.Events {
+		eventIDs = append(eventIDs, event.SpiffeID)
 	}
+	s.ElementsMatch(spiffeIDs[:2], eventIDs)
 
-	const numWorkers = 20
+	// setting the same value again is a no-op
+	affected, err = s.ds.SetAttestedNodesReattest(ctx, spiffeIDs[:3], true)
+	s.Require().NoError(err)
+	s.Zero(affected)
 
-	resultCh := make(chan error, numWorkers)
-	nextID := int32(0)
+	// the flag can be cleared
+	affected, err = s.ds.SetAttestedNodesReattest(ctx, spiffeIDs, false)
+	s.Require().NoError(err)
+	s.Equal(3, affected)
 
-	for range numWorkers {
-		go func() {
-			id := fmt.Sprintf("ID%d", atomic.AddInt32(&nextID, 1))
-			for range 10 {
-				err := s.ds.SetNodeSelectors(ctx, id, selectors)
-				if err != nil {
-					resultCh <- err
-				}
-			}
-			resultCh <- nil
-		}()
-	}
+	count, err := s.ds.CountAttestedNodes(ctx, &datastore.CountAttestedNodesRequest{
+		ByCanReattest: &canReattest,
+	})
+	s.Require().NoError(err)
+	s.Zero(count)
 
-	for range numWorkers {
-		s.Require().NoError(<-resultCh)
-	}
+	affected, err = s.ds.SetAttestedNodesReattest(ctx, nil, true)
+	s.Require().NoError(err)
+	s.Zero(affected)
 }
 
-func (s *PluginSuite) TestCreateRegistrationEntry() {
-	now := time.Now().Unix()
-	var validRegistrationEntries []*common.RegistrationEntry
-	s.getTestDataFromJSONFile(filepath.Join("testdata", "valid_registration_entries.json"), &validRegistrationEntries)
-
-	for _, validRegistrationEntry := range validRegistrationEntries {
-		registrationEntry, err := s.ds.CreateRegistrationEntry(ctx, validRegistrationEntry)
-		s.Require().NoError(err)
-		s.Require().NotNil(registrationEntry)
-		s.assertEntryEqual(s.T(), validRegistrationEntry, registrationEntry, now)
-	}
-}
+func (s *PluginSuite) TestListAttestedNodeEvents() {
+	var expectedEvents []datastore.AttestedNodeEvent
 
-func (s *PluginSuite) TestCreateOrReturnRegistrationEntry() {
-	now := time.Now().Unix()
+	// Create an attested node
+	node1, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "foo",
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "badcafe",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	})
+	s.Require().NoError(err)
+	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, node1.SpiffeId)
 
-	for _, tt := range []struct {
-		name          string
-		modifyEntry   func(*common.RegistrationEntry) *common.RegistrationEntry
-		expectError   string
-		expectSimilar bool
-		matchEntryID  bool
+	// Create selectors for attested node
+	selectors1 := []*common.Selector{
+		{Type: "FOO1", Value: "1"},
+	}
+	s.setNodeSelectors(node1.SpiffeId, selectors1)
+	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, node1.SpiffeId)
+
+	// Create second attested node
+	node2, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "bar",
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "badcafe",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	})
+	s.Require().NoError(err)
+	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, node2.SpiffeId)
+
+	// Create selectors for second attested node
+	selectors2 := []*common.Selector{
+		{Type: "BAR1", Value: "1"},
+	}
+	s.setNodeSelectors(node2.SpiffeId, selectors2)
+	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, node2.SpiffeId)
+
+	// Update first attested node
+	updatedNode, err := s.ds.UpdateAttestedNode(ctx, node1, nil)
+	s.Require().NoError(err)
+	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, updatedNode.SpiffeId)
+
+	// Update selectors for first attested node
+	updatedSelectors := []*common.Selector{
+		{Type: "FOO2", Value: "2"},
+	}
+	s.setNodeSelectors(updatedNode.SpiffeId, updatedSelectors)
+	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, updatedNode.SpiffeId)
+
+	// Delete second atttested node
+	deletedNode, err := s.ds.DeleteAttestedNode(ctx, node2.SpiffeId)
+	s.Require().NoError(err)
+	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, deletedNode.SpiffeId)
+
+	// Delete selectors for second attested node
+	s.setNodeSelectors(deletedNode.SpiffeId, nil)
+	expectedEvents = s.checkAttestedNodeEvents(expectedEvents, deletedNode.SpiffeId)
+
+	// Check filtering events by id
+	tests := []struct {
+		name                 string
+		greaterThanEventID   uint
+		lessThanEventID      uint
+		expectedEvents       []datastore.AttestedNodeEvent
+		expectedFirstEventID uint
+		expectedLastEventID  uint
+		expectedErr          string
 	}{
 		{
-			name: "no entry provided",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				return nil
-			},
-			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid request: missing registered entry",
-		},
-		{
-			name: "no selectors",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.Selectors = nil
-				return e
-			},
-			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: missing selector list",
-		},
-		{
-			name: "no SPIFFE ID",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.SpiffeId = ""
-				return e
-			},
-			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: missing SPIFFE ID",
-		},
-		{
-			name: "negative X509 ttl",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.X509SvidTtl = -1
-				return e
-			},
-			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: X509SvidTtl is not set",
-		},
-		{
-			name: "negative JWT ttl",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.JwtSvidTtl = -1
-				return e
-			},
-			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: JwtSvidTtl is not set",
-		},
-		{
-			name: "create entry successfully",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				return e
-			},
-		},
-		{
-			name: "subset selectors",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.Selectors = []*common.Selector{
-					{Type: "a", Value: "1"},
-				}
-				return e
-			},
-		},
-		{
-			name: "with superset selectors",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.Selectors = []*common.Selector{
-					{Type: "a", Value: "1"},
-					{Type: "b", Value: "2"},
-					{Type: "c", Value: "3"},
-				}
-				return e
-			},
-		},
-		{
-			name: "same selectors but different SPIFFE IDs",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.SpiffeId = "spiffe://example.org/baz"
-				return e
-			},
-		},
-		{
-			name: "with custom entry ID",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.EntryId = "some_ID_1"
-				// need to change at least one of (parentID, spiffeID, selector)
-				e.SpiffeId = "spiffe://example.org/bar"
-				return e
-			},
-			matchEntryID: true,
+			name:                 "All Events",
+			greaterThanEventID:   0,
+			expectedFirstEventID: 1,
+			expectedLastEventID:  uint(len(expectedEvents)),
+			expectedEvents:       expectedEvents,
 		},
 		{
-			name: "failed to create similar entry",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				return e
-			},
-			expectSimilar: true,
+			name:                 "Greater than half of the Events",
+			greaterThanEventID:   uint(len(expectedEvents) / 2),
+			expectedFirstEventID: uint(len(expectedEvents)/2) + 1,
+			expectedLastEventID:  uint(len(expectedEvents)),
+			expectedEvents:       expectedEvents[len(expectedEvents)/2:],
 		},
 		{
-			name: "failed to create similar entry with different entry ID",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.EntryId = "some_ID_2"
-				return e
-			},
-			expectSimilar: true,
+			name:                 "Less than half of the Events",
+			lessThanEventID:      uint(len(expectedEvents) / 2),
+			expectedFirstEventID: 1,
+			expectedLastEventID:  uint(len(expectedEvents)/2) - 1,
+			expectedEvents:       expectedEvents[:len(expectedEvents)/2-1],
 		},
 		{
-			name: "entry ID too long",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.EntryId = strings.Repeat("e", 256)
-				return e
-			},
-			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: entry ID too long",
+			name:               "Greater than largest Event ID",
+			greaterThanEventID: uint(len(expectedEvents)),
+			expectedEvents:     []datastore.AttestedNodeEvent{},
 		},
 		{
-			name: "entry ID contains invalid characters",
-			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
-				e.EntryId = "éntry😊"
-				return e
-			},
-			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: entry ID contains invalid characters",
+			name:               "Setting both greater and less than",
+			greaterThanEventID: 1,
+			lessThanEventID:    1,
+			expectedErr:        "rpc error: code = Unknown desc = datastore-sql: can't set both greater and less than event id",
 		},
-	} {
-		s.T().Run(tt.name, func(t *testing.T) {
-			entry := &common.RegistrationEntry{
-				SpiffeId: "spiffe://example.org/foo",
-				ParentId: "spiffe://example.org/bar",
-				Selectors: []*common.Selector{
-					{Type: "a", Value: "1"},
-					{Type: "b", Value: "2"},
-				},
-				X509SvidTtl: 1,
-				JwtSvidTtl:  1,
-				DnsNames: []string{
-					"abcd.efg",
-					"somehost",
-				},
-			}
-			entry = tt.modifyEntry(entry)
-
-			createdEntry, alreadyExists, err := s.ds.CreateOrReturnRegistrationEntry(ctx, entry)
-
-			require.Equal(t, tt.expectSimilar, alreadyExists)
-			if tt.expectError != "" {
-				require.EqualError(t, err, tt.expectError)
-				require.Nil(t, createdEntry)
+	}
+	for _, test := range tests {
+		s.T().Run(test.name, func(t *testing.T) {
+			resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{
+				GreaterThanEventID: test.greaterThanEventID,
+				LessThanEventID:    test.lessThanEventID,
+			})
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
 				return
 			}
-			require.NoError(t, err)
-			require.NotNil(t, createdEntry)
-			if tt.matchEntryID {
-				require.Equal(t, entry.EntryId, createdEntry.EntryId)
-			} else {
-				require.NotEqual(t, entry.EntryId, createdEntry.EntryId)
+			s.Require().NoError(err)
+
+			s.Require().Equal(test.expectedEvents, resp.Events)
+			if len(resp.Events) > 0 {
+				s.Require().Equal(test.expectedFirstEventID, resp.Events[0].EventID)
+				s.Require().Equal(test.expectedLastEventID, resp.Events[len(resp.Events)-1].EventID)
 			}
-			s.assertEntryEqual(t, entry, createdEntry, now)
 		})
 	}
 }
 
-func (s *PluginSuite) TestCreateInvalidRegistrationEntry() {
-	var invalidRegistrationEntries []*common.RegistrationEntry
-	s.getTestDataFromJSONFile(filepath.Join("testdata", "invalid_registration_entries.json"), &invalidRegistrationEntries)
+func (s *PluginSuite) TestListAttestedNodeEventsCursor() {
+	eventIDs := func(events []datastore.AttestedNodeEvent) []uint {
+		ids := []uint{}
+		for _, event := range events {
+			ids = append(ids, event.EventID)
+		}
+		return ids
+	}
 
-	for _, invalidRegistrationEntry := range invalidRegistrationEntries {
-		registrationEntry, err := s.ds.CreateRegistrationEntry(ctx, invalidRegistrationEntry)
-		s.Require().Error(err)
-		s.Require().Nil(registrationEntry)
+	// No events yet
+	resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.Events)
+	s.Require().Zero(resp.FirstEventID)
+	s.Require().Zero(resp.LastEventID)
+
+	for i := range 4 {
+		_, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:            fmt.Sprintf("spiffe://example.org/spire/agent/node%d", i),
+			AttestationDataType: "test",
+			CertSerialNumber:    "1234",
+			CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		})
+		s.Require().NoError(err)
 	}
 
-	// TODO: Check that no entries have been created
+	// Events past the high-water mark, along with the stored range
+	resp, err = s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{GreaterThanEventID: 2})
+	s.Require().NoError(err)
+	s.Require().Equal([]uint{3, 4}, eventIDs(resp.Events))
+	s.Require().Equal(uint(1), resp.FirstEventID)
+	s.Require().Equal(uint(4), resp.LastEventID)
+
+	// Nothing past the last event
+	resp, err = s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{GreaterThanEventID: 4})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.Events)
+	s.Require().Equal(uint(1), resp.FirstEventID)
+	s.Require().Equal(uint(4), resp.LastEventID)
+
+	// Prune the first two events
+	s.Require().NoError(s.ds.db.Model(&AttestedNodeEvent{}).Where("id <= ?", 2).Update("created_at", time.Now().Add(-time.Hour)).Error)
+	s.Require().NoError(s.ds.PruneAttestedNodeEvents(ctx, time.Minute))
+
+	// A reader that last saw event 1 detects that event 2 was pruned
+	resp, err = s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{GreaterThanEventID: 1})
+	s.Require().NoError(err)
+	s.Require().Equal([]uint{3, 4}, eventIDs(resp.Events))
+	s.Require().Equal(uint(3), resp.FirstEventID)
+	s.Require().Equal(uint(4), resp.LastEventID)
+	s.Require().Less(uint(1), resp.FirstEventID-1)
 }
 
-func (s *PluginSuite) TestFetchRegistrationEntry() {
-	for _, tt := range []struct {
-		name  string
-		entry *common.RegistrationEntry
-	}{
-		{
-			name: "entry with dns",
-			entry: &common.RegistrationEntry{
-				Selectors: []*common.Selector{
-					{Type: "Type1", Value: "Value1"},
-					{Type: "Type2", Value: "Value2"},
-					{Type: "Type3", Value: "Value3"},
-				},
-				SpiffeId:    "SpiffeId",
-				ParentId:    "ParentId",
-				X509SvidTtl: 1,
-				DnsNames: []string{
-					"abcd.efg",
-					"somehost",
-				},
-			},
-		},
-		{
-			name: "entry with store svid",
-			entry: &common.RegistrationEntry{
-				Selectors: []*common.Selector{
-					{Type: "Type1", Value: "Value1"},
-				},
-				SpiffeId:    "SpiffeId",
-				ParentId:    "ParentId",
-				X509SvidTtl: 1,
-				StoreSvid:   true,
-			},
-		},
-		{
-			name: "entry with hint",
-			entry: &common.RegistrationEntry{
-				Selectors: []*common.Selector{
-					{Type: "Type1", Value: "Value1"},
-				},
-				SpiffeId:    "SpiffeId",
-				ParentId:    "ParentId",
-				X509SvidTtl: 1,
-				Hint:        "external",
-			},
-		},
-	} {
-		s.T().Run(tt.name, func(t *testing.T) {
-			createdEntry, err := s.ds.CreateRegistrationEntry(ctx, tt.entry)
-			s.Require().NoError(err)
-			s.Require().NotNil(createdEntry)
-
-			fetchRegistrationEntry, err := s.ds.FetchRegistrationEntry(ctx, createdEntry.EntryId)
-			s.Require().NoError(err)
-			s.RequireProtoEqual(createdEntry, fetchRegistrationEntry)
+func (s *PluginSuite) TestListAttestedNodeEventsHistoryUnavailable() {
+	list := func(lastSeenEventID uint) error {
+		_, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{
+			GreaterThanEventID:     lastSeenEventID,
+			RequireCompleteHistory: true,
 		})
+		return err
 	}
-}
 
-func (s *PluginSuite) TestPruneRegistrationEntries() {
-	now := time.Now()
-	entry := &common.RegistrationEntry{
-		Selectors: []*common.Selector{
-			{Type: "Type1", Value: "Value1"},
-			{Type: "Type2", Value: "Value2"},
-			{Type: "Type3", Value: "Value3"},
-		},
-		SpiffeId:    "SpiffeId",
-		ParentId:    "ParentId",
-		X509SvidTtl: 1,
-		EntryExpiry: now.Unix(),
+	for i := range 3 {
+		_, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:            fmt.Sprintf("spiffe://example.org/spire/agent/node%d", i),
+			AttestationDataType: "test",
+			CertSerialNumber:    "1234",
+			CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		})
+		s.Require().NoError(err)
 	}
 
-	createdRegistrationEntry, err := s.ds.CreateRegistrationEntry(ctx, entry)
+	// The history is complete
+	s.Require().NoError(list(1))
+	s.Require().NoError(list(3))
+
+	// The events following the last seen one were pruned
+	s.Require().NoError(s.ds.db.Where("id <= ?", 2).Delete(&AttestedNodeEvent{}).Error)
+	s.Require().NoError(list(2))
+	s.Require().ErrorIs(list(1), datastore.ErrEventHistoryUnavailable)
+
+	// The events table was truncated while attested nodes remain
+	s.Require().NoError(s.ds.db.Where("1 = 1").Delete(&AttestedNodeEvent{}).Error)
+	err := list(3)
+	s.Require().ErrorIs(err, datastore.ErrEventHistoryUnavailable)
+	s.Require().Equal(codes.FailedPrecondition, status.Code(err))
+
+	// Listing without a lower bound, or without requiring the complete
+	// history, still succeeds
+	resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{RequireCompleteHistory: true})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.Events)
+	_, err = s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{GreaterThanEventID: 3})
 	s.Require().NoError(err)
-	fetchedRegistrationEntry := &common.RegistrationEntry{}
-	defaultLastLog := spiretest.LogEntry{
-		Message: "Connected to SQL database",
-	}
-	prunedLogMessage := "Pruned an expired registration"
 
-	resp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	// The event IDs were reset
+	s.Require().NoError(s.ds.CreateAttestedNodeEventForTesting(ctx, &datastore.AttestedNodeEvent{
+		EventID:  1,
+		SpiffeID: "spiffe://example.org/spire/agent/node0",
+	}))
+	s.Require().ErrorIs(list(3), datastore.ErrEventHistoryUnavailable)
+	s.Require().NoError(list(1))
+}
+
+func (s *PluginSuite) TestPruneAttestedNodeEvents() {
+	node, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "foo",
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "badcafe",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	})
 	s.Require().NoError(err)
-	s.Require().Equal(1, len(resp.Events))
-	s.Require().Equal(createdRegistrationEntry.EntryId, resp.Events[0].EntryID)
+
+	resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal(node.SpiffeId, resp.Events[0].SpiffeID)
 
 	for _, tt := range []struct {
-		name                      string
-		time                      time.Time
-		expectedRegistrationEntry *common.RegistrationEntry
-		expectedLastLog           spiretest.LogEntry
+		name           string
+		olderThan      time.Duration
+		expectedEvents []datastore.AttestedNodeEvent
 	}{
 		{
-			name:                      "Don't prune valid entries",
-			time:                      now.Add(-10 * time.Second),
-			expectedRegistrationEntry: createdRegistrationEntry,
-			expectedLastLog:           defaultLastLog,
-		},
-		{
-			name:                      "Don't prune exact ExpiresBefore",
-			time:                      now,
-			expectedRegistrationEntry: createdRegistrationEntry,
-			expectedLastLog:           defaultLastLog,
-		},
-		{
-			name:                      "Prune old entries",
-			time:                      now.Add(10 * time.Second),
-			expectedRegistrationEntry: (*common.RegistrationEntry)(nil),
-			expectedLastLog: spiretest.LogEntry{
-				Level:   logrus.InfoLevel,
-				Message: prunedLogMessage,
-				Data: logrus.Fields{
-					telemetry.SPIFFEID:       createdRegistrationEntry.SpiffeId,
-					telemetry.ParentID:       createdRegistrationEntry.ParentId,
-					telemetry.RegistrationID: createdRegistrationEntry.EntryId,
+			name:      "Don't prune valid events",
+			olderThan: 1 * time.Hour,
+			expectedEvents: []datastore.AttestedNodeEvent{
+				{
+					EventID:  1,
+					SpiffeID: node.SpiffeId,
 				},
 			},
 		},
+		{
+			name:           "Prune old events",
+			olderThan:      0 * time.Second,
+			expectedEvents: []datastore.AttestedNodeEvent{},
+		},
 	} {
 		s.T().Run(tt.name, func(t *testing.T) {
-			// Get latest event id
-			resp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
-			require.NoError(t, err)
-			require.Greater(t, len(resp.Events), 0)
-			lastEventID := resp.Events[len(resp.Events)-1].EventID
-
-			// Prune events
-			err = s.ds.PruneRegistrationEntries(ctx, tt.time)
-			require.NoError(t, err)
-			fetchedRegistrationEntry, err = s.ds.FetchRegistrationEntry(ctx, createdRegistrationEntry.EntryId)
-			require.NoError(t, err)
-			assert.Equal(t, tt.expectedRegistrationEntry, fetchedRegistrationEntry)
-
-			// Verify pruning triggers event creation
-			resp, err = s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{
-				GreaterThanEventID: lastEventID,
-			})
-			require.NoError(t, err)
-			if tt.expectedRegistrationEntry != nil {
-				require.Equal(t, 0, len(resp.Events))
-			} else {
-				require.Equal(t, 1, len(resp.Events))
-				require.Equal(t, createdRegistrationEntry.EntryId, resp.Events[0].EntryID)
-			}
-
-			if tt.expectedLastLog.Message == prunedLogMessage {
-				spiretest.AssertLastLogs(t, s.hook.AllEntries(), []spiretest.LogEntry{tt.expectedLastLog})
-			} else {
-				assert.Equal(t, s.hook.LastEntry().Message, tt.expectedLastLog.Message)
-			}
+			s.Require().Eventuallyf(func() bool {
+				err = s.ds.PruneAttestedNodeEvents(ctx, tt.olderThan)
+				s.Require().NoError(err)
+				resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+				s.Require().NoError(err)
+				return reflect.DeepEqual(tt.expectedEvents, resp.Events)
+			}, 10*time.Second, 50*time.Millisecond, "Failed to prune entries correctly")
 		})
 	}
 }
 
-func (s *PluginSuite) TestFetchInexistentRegistrationEntry() {
-	fetchedRegistrationEntry, err := s.ds.FetchRegistrationEntry(ctx, "INEXISTENT")
+func (s *PluginSuite) TestCompactAttestedNodeEvents() {
+	// Nothing to compact
+	compacted, err := s.ds.CompactAttestedNodeEvents(ctx)
 	s.Require().NoError(err)
-	s.Require().Nil(fetchedRegistrationEntry)
+	s.Require().Zero(compacted)
+
+	for i, spiffeID := range []string{"a", "b", "a", "c", "a", "b"} {
+		s.Require().NoError(s.ds.CreateAttestedNodeEventForTesting(ctx, &datastore.AttestedNodeEvent{
+			EventID:  uint(i + 1),
+			SpiffeID: spiffeID,
+		}))
+	}
+
+	compacted, err = s.ds.CompactAttestedNodeEvents(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(3, compacted)
+
+	// Only the latest event of each node is left, and the highest event ID
+	// is unchanged
+	resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal([]datastore.AttestedNodeEvent{
+		{EventID: 4, SpiffeID: "c"},
+		{EventID: 5, SpiffeID: "a"},
+		{EventID: 6, SpiffeID: "b"},
+	}, resp.Events)
+	s.Require().Equal(uint(4), resp.FirstEventID)
+	s.Require().Equal(uint(6), resp.LastEventID)
+
+	// Compacting again has no effect
+	compacted, err = s.ds.CompactAttestedNodeEvents(ctx)
+	s.Require().NoError(err)
+	s.Require().Zero(compacted)
+
+	// Registration entry events are left alone
+	s.Require().NoError(s.ds.CreateRegistrationEntryEventForTesting(ctx, &datastore.RegistrationEntryEvent{EventID: 1, EntryID: "a"}))
+	s.Require().NoError(s.ds.CreateRegistrationEntryEventForTesting(ctx, &datastore.RegistrationEntryEvent{EventID: 2, EntryID: "a"}))
+	_, err = s.ds.CompactAttestedNodeEvents(ctx)
+	s.Require().NoError(err)
+	entryEvents, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(entryEvents.Events, 2)
 }
 
-func (s *PluginSuite) TestListRegistrationEntries() {
-	// Connection is never used, each test creates new connection to a different database
-	s.ds.Close()
+func (s *PluginSuite) TestPruneEvents() {
+	now := time.Now()
+	for eventID := uint(1); eventID <= 2; eventID++ {
+		s.Require().NoError(s.ds.CreateRegistrationEntryEventForTesting(ctx, &datastore.RegistrationEntryEvent{
+			EventID: eventID,
+			EntryID: fmt.Sprintf("entry-%d", eventID),
+		}))
+		s.Require().NoError(s.ds.CreateAttestedNodeEventForTesting(ctx, &datastore.AttestedNodeEvent{
+			EventID:  eventID,
+			SpiffeID: fmt.Sprintf("spiffe://example.org/node-%d", eventID),
+		}))
+	}
+
+	for _, trustDomain := range []string{"spiffe://foo", "spiffe://bar"} {
+		_, err := s.ds.CreateBundle(ctx, bundleutil.BundleProtoFromRootCA(trustDomain, s.cert))
+		s.Require().NoError(err)
+	}
 
-	s.testListRegistrationEntries(datastore.RequireCurrent)
-	s.testListRegistrationEntries(datastore.TolerateStale)
+	// Age the first event of each table past the default event retention
+	s.Require().NoError(s.ds.db.Model(&RegisteredEntryEvent{}).Where("id = ?", 1).UpdateColumn("created_at", now.Add(-48*time.Hour)).Error)
+	s.Require().NoError(s.ds.db.Model(&AttestedNodeEvent{}).Where("id = ?", 1).UpdateColumn("created_at", now.Add(-48*time.Hour)).Error)
+	s.Require().NoError(s.ds.db.Model(&BundleEvent{}).Where("id = ?", 1).UpdateColumn("created_at", now.Add(-48*time.Hour)).Error)
 
-	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
-		Pagination: &datastore.Pagination{
-			PageSize: 0,
-		},
-	})
-	s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot paginate with pagesize = 0")
-	s.Require().Nil(resp)
+	err := s.ds.PruneEvents(ctx, now.Add(-time.Hour))
+	s.Require().NoError(err)
 
-	resp, err = s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
-		Pagination: &datastore.Pagination{
-			Token:    "invalid int",
-			PageSize: 10,
-		},
-	})
-	s.Require().Error(err, "could not parse token 'invalid int'")
-	s.Require().Nil(resp)
+	entryEvents, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal([]datastore.RegistrationEntryEvent{
+		{EventID: 2, EntryID: "entry-2"},
+	}, entryEvents.Events)
 
-	resp, err = s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
-		BySelectors: &datastore.BySelectors{},
-	})
-	s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot list by empty selector set")
-	s.Require().Nil(resp)
+	nodeEvents, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal([]datastore.AttestedNodeEvent{
+		{EventID: 2, SpiffeID: "spiffe://example.org/node-2"},
+	}, nodeEvents.Events)
+
+	bundleEvents, err := s.ds.ListBundleEvents(ctx, &datastore.ListBundleEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal([]datastore.BundleEvent{
+		{EventID: 2, TrustDomain: "spiffe://bar"},
+	}, bundleEvents.Events)
 }
 
-func (s *PluginSuite) testListRegistrationEntries(dataConsistency datastore.DataConsistency) {
-	byFederatesWith := func(match datastore.MatchBehavior, trustDomainIDs ...string) *datastore.ByFederatesWith {
-		return &datastore.ByFederatesWith{
-			TrustDomains: trustDomainIDs,
-			Match:        match,
-		}
+func (s *PluginSuite) TestPruneEventsKeepsRetainedEvents() {
+	now := time.Now()
+	for eventID := uint(1); eventID <= 2; eventID++ {
+		s.Require().NoError(s.ds.CreateRegistrationEntryEventForTesting(ctx, &datastore.RegistrationEntryEvent{
+			EventID: eventID,
+			EntryID: fmt.Sprintf("entry-%d", eventID),
+		}))
 	}
+	s.Require().NoError(s.ds.db.Model(&RegisteredEntryEvent{}).Where("id = ?", 1).UpdateColumn("created_at", now.Add(-2*time.Hour)).Error)
 
-	makeEntry := func(parentIDSuffix, spiffeIDSuffix, hint string, selectors ...string) *common.RegistrationEntry {
-		return &common.RegistrationEntry{
-			EntryId:   fmt.Sprintf("%s%s%s", parentIDSuffix, spiffeIDSuffix, strings.Join(selectors, "")),
-			ParentId:  makeID(parentIDSuffix),
-			SpiffeId:  makeID(spiffeIDSuffix),
-			Selectors: makeSelectors(selectors...),
-			Hint:      hint,
+	listEventIDs := func() []uint {
+		resp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+		s.Require().NoError(err)
+		var eventIDs []uint
+		for _, event := range resp.Events {
+			eventIDs = append(eventIDs, event.EventID)
 		}
+		return eventIDs
 	}
 
-	foobarAB1 := makeEntry("foo", "bar", "external", "A", "B")
-	foobarAB1.FederatesWith = []string{"spiffe://federated1.test"}
-	foobarAD12 := makeEntry("foo", "bar", "", "A", "D")
-	foobarAD12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
-	foobarCB2 := makeEntry("foo", "bar", "internal", "C", "B")
-	foobarCB2.FederatesWith = []string{"spiffe://federated2.test"}
-	foobarCD12 := makeEntry("foo", "bar", "", "C", "D")
-	foobarCD12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
-
-	foobarB := makeEntry("foo", "bar", "", "B")
-
-	foobuzAD1 := makeEntry("foo", "buz", "", "A", "D")
-	foobuzAD1.FederatesWith = []string{"spiffe://federated1.test"}
-	foobuzCD := makeEntry("foo", "buz", "", "C", "D")
+	// Both events are younger than the default retention, so none is pruned
+	// even though the cutoff is in the future
+	s.Require().NoError(s.ds.PruneEvents(ctx, now.Add(time.Hour)))
+	s.Require().Equal([]uint{1, 2}, listEventIDs())
 
-	bazbarAB1 := makeEntry("baz", "bar", "", "A", "B")
-	bazbarAB1.FederatesWith = []string{"spiffe://federated1.test"}
-	bazbarAD12 := makeEntry("baz", "bar", "external", "A", "D")
-	bazbarAD12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
-	bazbarCB2 := makeEntry("baz", "bar", "", "C", "B")
-	bazbarCB2.FederatesWith = []string{"spiffe://federated2.test"}
-	bazbarCD12 := makeEntry("baz", "bar", "", "C", "D")
-	bazbarCD12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
-	bazbarAE3 := makeEntry("baz", "bar", "", "A", "E")
-	bazbarAE3.FederatesWith = []string{"spiffe://federated3.test"}
+	s.ds.eventRetention = time.Hour
 
-	bazbuzAB12 := makeEntry("baz", "buz", "", "A", "B")
-	bazbuzAB12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
-	bazbuzB := makeEntry("baz", "buz", "", "B")
-	bazbuzCD := makeEntry("baz", "buz", "", "C", "D")
+	// Only the event older than the configured retention is pruned
+	s.Require().NoError(s.ds.PruneEvents(ctx, now.Add(time.Hour)))
+	s.Require().Equal([]uint{2}, listEventIDs())
+}
+
+func (s *PluginSuite) TestNodeSelectors() {
+	foo1 := []*common.Selector{
+		{Type: "FOO1", Value: "1"},
+	}
+	foo2 := []*common.Selector{
+		{Type: "FOO2", Value: "1"},
+	}
+	bar := []*common.Selector{
+		{Type: "BAR", Value: "FIGHT"},
+	}
+
+	// assert there are no selectors for foo
+	selectors := s.getNodeSelectors("foo", datastore.TolerateStale)
+	s.Require().Empty(selectors)
+	selectors = s.getNodeSelectors("foo", datastore.RequireCurrent)
+	s.Require().Empty(selectors)
+
+	// set selectors on foo and bar
+	s.setNodeSelectors("foo", foo1)
+	s.setNodeSelectors("bar", bar)
+
+	// get foo selectors
+	selectors = s.getNodeSelectors("foo", datastore.TolerateStale)
+	s.RequireProtoListEqual(foo1, selectors)
+	selectors = s.getNodeSelectors("foo", datastore.RequireCurrent)
+	s.RequireProtoListEqual(foo1, selectors)
+
+	// replace foo selectors
+	s.setNodeSelectors("foo", foo2)
+	selectors = s.getNodeSelectors("foo", datastore.TolerateStale)
+	s.RequireProtoListEqual(foo2, selectors)
+	selectors = s.getNodeSelectors("foo", datastore.RequireCurrent)
+	s.RequireProtoListEqual(foo2, selectors)
+
+	// delete foo selectors
+	s.setNodeSelectors("foo", []*common.Selector{})
+	selectors = s.getNodeSelectors("foo", datastore.TolerateStale)
+	s.Require().Empty(selectors)
+	selectors = s.getNodeSelectors("foo", datastore.RequireCurrent)
+	s.Require().Empty(selectors)
+
+	// get bar selectors (make sure they weren't impacted by deleting foo)
+	selectors = s.getNodeSelectors("bar", datastore.TolerateStale)
+	s.RequireProtoListEqual(bar, selectors)
+	// get bar selectors (make sure they weren't impacted by deleting foo)
+	selectors = s.getNodeSelectors("bar", datastore.RequireCurrent)
+	s.RequireProtoListEqual(bar, selectors)
+}
+
+func (s *PluginSuite) TestNodeSelectorsSource() {
+	selectors := []*common.Selector{
+		{Type: "aws_iid", Value: "tag:foo:bar", Source: "aws_iid"},
+		{Type: "unsourced", Value: "1"},
+		{Type: "x509pop", Value: "subject:cn:foo", Source: "x509pop"},
+	}
+
+	s.setNodeSelectors("foo", selectors)
+
+	// the source is returned along with each selector, and left empty
+	// when it was not provided
+	got := s.getNodeSelectors("foo", datastore.TolerateStale)
+	s.RequireProtoListEqual(selectors, got)
+	got = s.getNodeSelectors("foo", datastore.RequireCurrent)
+	s.RequireProtoListEqual(selectors, got)
+}
+
+func (s *PluginSuite) TestNodeSelectorsExpiry() {
+	now := time.Now().Unix()
+	fresh := &common.Selector{Type: "aws_iid", Value: "tag:foo:bar", ExpiresAt: now + 3600}
+	expired := &common.Selector{Type: "aws_iid", Value: "tag:foo:baz", ExpiresAt: now - 3600}
+	permanent := &common.Selector{Type: "x509pop", Value: "subject:cn:foo"}
+
+	s.setNodeSelectors("foo", []*common.Selector{fresh, expired, permanent})
+
+	// expired selectors are returned, along with their expiry, unless they
+	// are excluded
+	s.RequireProtoListEqual([]*common.Selector{fresh, expired, permanent}, s.getNodeSelectors("foo", datastore.RequireCurrent))
+
+	selectors, err := s.ds.GetNodeSelectors(ctx, "foo", datastore.RequireCurrent, true)
+	s.Require().NoError(err)
+	s.RequireProtoListEqual([]*common.Selector{fresh, permanent}, selectors)
+
+	selectors, err = s.ds.GetNodeSelectors(ctx, "foo", datastore.TolerateStale, true)
+	s.Require().NoError(err)
+	s.RequireProtoListEqual([]*common.Selector{fresh, permanent}, selectors)
+}
+
+func (s *PluginSuite) TestPruneNodeSelectors() {
+	now := time.Now()
+	s.ds.pruneBatchSize = 2
+
+	s.setNodeSelectors("foo", []*common.Selector{
+		{Type: "a", Value: "1", ExpiresAt: now.Add(-2 * time.Hour).Unix()},
+		{Type: "a", Value: "2", ExpiresAt: now.Add(-time.Hour).Unix()},
+		{Type: "a", Value: "3", ExpiresAt: now.Add(time.Hour).Unix()},
+		{Type: "a", Value: "4"},
+	})
+	s.setNodeSelectors("bar", []*common.Selector{
+		{Type: "b", Value: "1", ExpiresAt: now.Add(-time.Hour).Unix()},
+	})
+	s.setNodeSelectors("baz", []*common.Selector{
+		{Type: "c", Value: "1"},
+	})
+
+	// Nothing expired before then
+	pruned, err := s.ds.PruneNodeSelectors(ctx, now.Add(-3*time.Hour))
+	s.Require().NoError(err)
+	s.Require().Zero(pruned)
+
+	// Expired selectors are removed over several batches
+	pruned, err = s.ds.PruneNodeSelectors(ctx, now)
+	s.Require().NoError(err)
+	s.Require().Equal(3, pruned)
+
+	s.RequireProtoListEqual([]*common.Selector{
+		{Type: "a", Value: "3", ExpiresAt: now.Add(time.Hour).Unix()},
+		{Type: "a", Value: "4"},
+	}, s.getNodeSelectors("foo", datastore.RequireCurrent))
+	s.Require().Empty(s.getNodeSelectors("bar", datastore.RequireCurrent))
+	s.RequireProtoListEqual([]*common.Selector{
+		{Type: "c", Value: "1"},
+	}, s.getNodeSelectors("baz", datastore.RequireCurrent))
+
+	// An event is created for each node that lost selectors
+	resp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal([]datastore.AttestedNodeEvent{
+		{EventID: 1, SpiffeID: "foo"},
+		{EventID: 2, SpiffeID: "bar"},
+		{EventID: 3, SpiffeID: "baz"},
+		{EventID: 4, SpiffeID: "foo"},
+		{EventID: 5, SpiffeID: "bar"},
+	}, resp.Events)
+}
+
+func (s *PluginSuite) TestListNodeSelectors() {
+	s.T().Run("no selectors exist", func(t *testing.T) {
+		req := &datastore.ListNodeSelectorsRequest{}
+		resp := s.listNodeSelectors(req)
+		assertSelectorsEqual(t, nil, resp.Selectors)
+	})
+
+	const numNonExpiredAttNodes = 3
+	const attestationDataType = "fake_nodeattestor"
+	nonExpiredAttNodes := make([]*common.AttestedNode, numNonExpiredAttNodes)
+	now := time.Now()
+	for i := range numNonExpiredAttNodes {
+		nonExpiredAttNodes[i] = &common.AttestedNode{
+			SpiffeId:            fmt.Sprintf("spiffe://example.org/non-expired-node-%d", i),
+			AttestationDataType: attestationDataType,
+			CertSerialNumber:    fmt.Sprintf("non-expired serial %d-1", i),
+			CertNotAfter:        now.Add(time.Hour).Unix(),
+			NewCertSerialNumber: fmt.Sprintf("non-expired serial %d-2", i),
+			NewCertNotAfter:     now.Add(2 * time.Hour).Unix(),
+		}
+	}
+
+	const numExpiredAttNodes = 2
+	expiredAttNodes := make([]*common.AttestedNode, numExpiredAttNodes)
+	for i := range numExpiredAttNodes {
+		expiredAttNodes[i] = &common.AttestedNode{
+			SpiffeId:            fmt.Sprintf("spiffe://example.org/expired-node-%d", i),
+			AttestationDataType: attestationDataType,
+			CertSerialNumber:    fmt.Sprintf("expired serial %d-1", i),
+			CertNotAfter:        now.Add(-24 * time.Hour).Unix(),
+			NewCertSerialNumber: fmt.Sprintf("expired serial %d-2", i),
+			NewCertNotAfter:     now.Add(-12 * time.Hour).Unix(),
+		}
+	}
+
+	allAttNodesToCreate := make([]*common.AttestedNode, 0, len(nonExpiredAttNodes)+len(expiredAttNodes))
+	allAttNodesToCreate = append(allAttNodesToCreate, nonExpiredAttNodes...)
+	allAttNodesToCreate = append(allAttNodesToCreate, expiredAttNodes...)
+	selectorMap := make(map[string][]*common.Selector)
+	for i, n := range allAttNodesToCreate {
+		_, err := s.ds.CreateAttestedNode(ctx, n)
+		s.Require().NoError(err)
+
+		selectors := []*common.Selector{
+			{
+				Type:  "foo",
+				Value: strconv.Itoa(i),
+			},
+		}
+
+		s.setNodeSelectors(n.SpiffeId, selectors)
+		selectorMap[n.SpiffeId] = selectors
+	}
+
+	nonExpiredSelectorsMap := make(map[string][]*common.Selector, numNonExpiredAttNodes)
+	for i := range numNonExpiredAttNodes {
+		spiffeID := nonExpiredAttNodes[i].SpiffeId
+		nonExpiredSelectorsMap[spiffeID] = selectorMap[spiffeID]
+	}
+
+	s.T().Run("list all", func(t *testing.T) {
+		req := &datastore.ListNodeSelectorsRequest{}
+		resp := s.listNodeSelectors(req)
+		assertSelectorsEqual(t, selectorMap, resp.Selectors)
+	})
+
+	s.T().Run("list unexpired", func(t *testing.T) {
+		req := &datastore.ListNodeSelectorsRequest{
+			ValidAt: now,
+		}
+		resp := s.listNodeSelectors(req)
+		assertSelectorsEqual(t, nonExpiredSelectorsMap, resp.Selectors)
+	})
+}
+
+func (s *PluginSuite) TestListNodeSelectorsGroupsBySpiffeID() {
+	insertSelector := func(id int, spiffeID, selectorType, selectorValue string) {
+		query := maybeRebind(s.ds.db.databaseType, "INSERT INTO node_resolver_map_entries(id, spiffe_id, type, value) VALUES (?, ?, ?, ?)")
+		_, err := s.ds.db.raw.Exec(query, id, spiffeID, selectorType, selectorValue)
+		s.Require().NoError(err)
+	}
+
+	// Insert selectors out of order in respect to the SPIFFE ID so
+	// that we can assert that the datastore aggregates the results correctly.
+	insertSelector(1, "spiffe://example.org/node3", "A", "a")
+	insertSelector(2, "spiffe://example.org/node2", "B", "b")
+	insertSelector(3, "spiffe://example.org/node3", "C", "c")
+	insertSelector(4, "spiffe://example.org/node1", "D", "d")
+	insertSelector(5, "spiffe://example.org/node2", "E", "e")
+	insertSelector(6, "spiffe://example.org/node3", "F", "f")
+
+	resp := s.listNodeSelectors(&datastore.ListNodeSelectorsRequest{})
+	assertSelectorsEqual(s.T(), map[string][]*common.Selector{
+		"spiffe://example.org/node1": {{Type: "D", Value: "d"}},
+		"spiffe://example.org/node2": {{Type: "B", Value: "b"}, {Type: "E", Value: "e"}},
+		"spiffe://example.org/node3": {{Type: "A", Value: "a"}, {Type: "C", Value: "c"}, {Type: "F", Value: "f"}},
+	}, resp.Selectors)
+}
+
+func (s *PluginSuite) TestSetNodeSelectorsBulk() {
+	// Exercise several batches, the last one being partial
+	s.ds.selectorsBatchSize = 2
+
+	s.Require().NoError(s.ds.SetNodeSelectors(ctx, "spiffe://example.org/node1", []*common.Selector{
+		{Type: "A", Value: "a"},
+		{Type: "B", Value: "b"},
+	}))
+	s.Require().NoError(s.ds.SetNodeSelectors(ctx, "spiffe://example.org/node2", []*common.Selector{
+		{Type: "C", Value: "c"},
+	}))
+
+	eventsResp, err := s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	eventsBefore := len(eventsResp.Events)
+
+	selectorsBySpiffeID := map[string][]*common.Selector{
+		"spiffe://example.org/node1": {{Type: "A", Value: "z"}},
+		"spiffe://example.org/node2": {},
+		"spiffe://example.org/node3": {{Type: "D", Value: "d"}},
+		"spiffe://example.org/node4": {{Type: "E", Value: "e"}, {Type: "F", Value: "f"}},
+		"spiffe://example.org/node5": {{Type: "G", Value: "g"}},
+	}
+	s.Require().NoError(s.ds.SetNodeSelectorsBulk(ctx, selectorsBySpiffeID))
+
+	// The old selectors are replaced, not merged
+	for spiffeID, expected := range selectorsBySpiffeID {
+		selectors := s.getNodeSelectors(spiffeID, datastore.RequireCurrent)
+		spiretest.RequireProtoListEqual(s.T(), expected, selectors)
+	}
+
+	// An event is created per node
+	eventsResp, err = s.ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	s.Require().NoError(err)
+	var spiffeIDs []string
+	for _, event := range eventsResp.Events[eventsBefore:] {
+		spiffeIDs = append(spiffeIDs, event.SpiffeID)
+	}
+	s.Require().Equal([]string{
+		"spiffe://example.org/node1",
+		"spiffe://example.org/node2",
+		"spiffe://example.org/node3",
+		"spiffe://example.org/node4",
+		"spiffe://example.org/node5",
+	}, spiffeIDs)
+
+	// Setting the selectors of no nodes is a no-op
+	s.Require().NoError(s.ds.SetNodeSelectorsBulk(ctx, nil))
+}
+
+func (s *PluginSuite) TestSetNodeSelectorsUnderLoad() {
+	selectors := []*common.Selector{
+		{Type: "TYPE", Value: "VALUE"},
+	}
+
+	const numWorkers = 20
+
+	resultCh := make(chan error, numWorkers)
+	nextID := int32(0)
+
+	for range numWorkers {
+		go func() {
+			id := fmt.Sprintf("ID%d", atomic.AddInt32(&nextID, 1))
+			for range 10 {
+				err := s.ds.SetNodeSelectors(ctx, id, selectors)
+				if err != nil {
+					resultCh <- err
+				}
+			}
+			resultCh <- nil
+		}()
+	}
+
+	for range numWorkers {
+		s.Require().NoError(<-resultCh)
+	}
+}
+
+func (s *PluginSuite) TestCreateRegistrationEntry() {
+	now := time.Now().Unix()
+	var validRegistrationEntries []*common.RegistrationEntry
+	s.getTestDataFromJSONFile(filepath.Join("testdata", "valid_registration_entries.json"), &validRegistrationEntries)
+
+	for _, validRegistrationEntry := range validRegistrationEntries {
+		registrationEntry, err := s.ds.CreateRegistrationEntry(ctx, validRegistrationEntry)
+		s.Require().NoError(err)
+		s.Require().NotNil(registrationEntry)
+		s.assertEntryEqual(s.T(), validRegistrationEntry, registrationEntry, now)
+	}
+}
+
+func (s *PluginSuite) TestCreateOrReturnRegistrationEntry() {
+	now := time.Now().Unix()
+
+	for _, tt := range []struct {
+		name          string
+		modifyEntry   func(*common.RegistrationEntry) *common.RegistrationEntry
+		expectError   string
+		expectSimilar bool
+		matchEntryID  bool
+	}{
+		{
+			name: "no entry provided",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				return nil
+			},
+			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid request: missing registered entry",
+		},
+		{
+			name: "no selectors",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.Selectors = nil
+				return e
+			},
+			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: missing selector list",
+		},
+		{
+			name: "no SPIFFE ID",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.SpiffeId = ""
+				return e
+			},
+			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: missing SPIFFE ID",
+		},
+		{
+			name: "negative X509 ttl",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.X509SvidTtl = -1
+				return e
+			},
+			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: X509SvidTtl is not set",
+		},
+		{
+			name: "negative JWT ttl",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.JwtSvidTtl = -1
+				return e
+			},
+			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: JwtSvidTtl is not set",
+		},
+		{
+			name: "create entry successfully",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				return e
+			},
+		},
+		{
+			name: "subset selectors",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.Selectors = []*common.Selector{
+					{Type: "a", Value: "1"},
+				}
+				return e
+			},
+		},
+		{
+			name: "with superset selectors",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.Selectors = []*common.Selector{
+					{Type: "a", Value: "1"},
+					{Type: "b", Value: "2"},
+					{Type: "c", Value: "3"},
+				}
+				return e
+			},
+		},
+		{
+			name: "same selectors but different SPIFFE IDs",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.SpiffeId = "spiffe://example.org/baz"
+				return e
+			},
+		},
+		{
+			name: "with custom entry ID",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.EntryId = "some_ID_1"
+				// need to change at least one of (parentID, spiffeID, selector)
+				e.SpiffeId = "spiffe://example.org/bar"
+				return e
+			},
+			matchEntryID: true,
+		},
+		{
+			name: "failed to create similar entry",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				return e
+			},
+			expectSimilar: true,
+		},
+		{
+			name: "failed to create similar entry with different entry ID",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.EntryId = "some_ID_2"
+				return e
+			},
+			expectSimilar: true,
+		},
+		{
+			name: "entry ID too long",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.EntryId = strings.Repeat("e", 256)
+				return e
+			},
+			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: entry ID too long",
+		},
+		{
+			name: "entry ID contains invalid characters",
+			modifyEntry: func(e *common.RegistrationEntry) *common.RegistrationEntry {
+				e.EntryId = "éntry😊"
+				return e
+			},
+			expectError: "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: entry ID contains invalid characters",
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			entry := &common.RegistrationEntry{
+				SpiffeId: "spiffe://example.org/foo",
+				ParentId: "spiffe://example.org/bar",
+				Selectors: []*common.Selector{
+					{Type: "a", Value: "1"},
+					{Type: "b", Value: "2"},
+				},
+				X509SvidTtl: 1,
+				JwtSvidTtl:  1,
+				DnsNames: []string{
+					"abcd.efg",
+					"somehost",
+				},
+			}
+			entry = tt.modifyEntry(entry)
+
+			createdEntry, alreadyExists, err := s.ds.CreateOrReturnRegistrationEntry(ctx, entry)
+
+			require.Equal(t, tt.expectSimilar, alreadyExists)
+			if tt.expectError != "" {
+				require.EqualError(t, err, tt.expectError)
+				require.Nil(t, createdEntry)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, createdEntry)
+			if tt.matchEntryID {
+				require.Equal(t, entry.EntryId, createdEntry.EntryId)
+			} else {
+				require.NotEqual(t, entry.EntryId, createdEntry.EntryId)
+			}
+			s.assertEntryEqual(t, entry, createdEntry, now)
+		})
+	}
+}
+
+func (s *PluginSuite) TestCreateRegistrationEntryWithIdempotencyKey() {
+	newEntry := func(path string) *common.RegistrationEntry {
+		return &common.RegistrationEntry{
+			SpiffeId:       makeID(path),
+			ParentId:       makeID("parent"),
+			Selectors:      []*common.Selector{{Type: "a", Value: path}},
+			IdempotencyKey: "provisioning-request-1",
+		}
+	}
+
+	first, existing, err := s.ds.CreateOrReturnRegistrationEntry(ctx, newEntry("first"))
+	s.Require().NoError(err)
+	s.Require().False(existing)
+	s.Require().Equal("provisioning-request-1", first.IdempotencyKey)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, first.EntryId)
+	s.Require().NoError(err)
+	s.AssertProtoEqual(first, fetched)
+
+	// Retrying with the same key returns the existing entry, even if the
+	// entry differs from the one originally created
+	for _, path := range []string{"first", "retried"} {
+		retried, existing, err := s.ds.CreateOrReturnRegistrationEntry(ctx, newEntry(path))
+		s.Require().NoError(err)
+		s.Require().True(existing)
+		s.AssertProtoEqual(first, retried)
+	}
+
+	retried, err := s.ds.CreateRegistrationEntry(ctx, newEntry("retried"))
+	s.Require().NoError(err)
+	s.Require().Equal(first.EntryId, retried.EntryId)
+
+	// A different key creates a new entry
+	other := newEntry("other")
+	other.IdempotencyKey = "provisioning-request-2"
+	created, existing, err := s.ds.CreateOrReturnRegistrationEntry(ctx, other)
+	s.Require().NoError(err)
+	s.Require().False(existing)
+	s.Require().NotEqual(first.EntryId, created.EntryId)
+
+	// Entries without a key are not affected by the unique index
+	for _, path := range []string{"no-key-1", "no-key-2"} {
+		entry := newEntry(path)
+		entry.IdempotencyKey = ""
+		created, existing, err := s.ds.CreateOrReturnRegistrationEntry(ctx, entry)
+		s.Require().NoError(err)
+		s.Require().False(existing)
+		s.Require().Empty(created.IdempotencyKey)
+	}
+
+	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Entries, 4)
+}
+
+func (s *PluginSuite) TestRegistrationEntryDNSNames() {
+	newEntry := func(name string, dnsNames ...string) *common.RegistrationEntry {
+		return &common.RegistrationEntry{
+			SpiffeId:  makeID(name),
+			ParentId:  makeID("parent"),
+			Selectors: []*common.Selector{{Type: "a", Value: name}},
+			DnsNames:  dnsNames,
+		}
+	}
+
+	for _, tt := range []struct {
+		name           string
+		dnsNames       []string
+		expectDNSNames []string
+		expectInvalid  []string
+	}{
+		{
+			name:           "valid hostnames",
+			dnsNames:       []string{"api.example.org", "example.org", "localhost"},
+			expectDNSNames: []string{"api.example.org", "example.org", "localhost"},
+		},
+		{
+			name:           "wildcard",
+			dnsNames:       []string{"*.example.org"},
+			expectDNSNames: []string{"*.example.org"},
+		},
+		{
+			name:           "normalized and deduplicated",
+			dnsNames:       []string{" API.Example.org ", "api.example.org", "Example.ORG"},
+			expectDNSNames: []string{"api.example.org", "example.org"},
+		},
+		{
+			name:          "invalid forms",
+			dnsNames:      []string{"example.org", ".example.org", "example.org.", "foo.*.example.org", "*.*.example.org", "exa mple.org", "under_score.org", "  "},
+			expectInvalid: []string{".example.org", "example.org.", "foo.*.example.org", "*.*.example.org", "exa mple.org", "under_score.org", "  "},
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			entry := newEntry(tt.name, tt.dnsNames...)
+			created, err := s.ds.CreateRegistrationEntry(ctx, entry)
+			if tt.expectInvalid != nil {
+				var dnsErr *datastore.InvalidDNSNamesError
+				require.ErrorAs(t, err, &dnsErr)
+				require.Equal(t, tt.expectInvalid, dnsErr.DNSNames)
+				spiretest.RequireGRPCStatusContains(t, err, codes.InvalidArgument, "invalid registration entry: invalid DNS names")
+				require.Nil(t, created)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectDNSNames, created.DnsNames)
+			// The given entry is not modified
+			require.Equal(t, tt.dnsNames, entry.DnsNames)
+
+			fetched, err := s.ds.FetchRegistrationEntry(ctx, created.EntryId)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectDNSNames, fetched.DnsNames)
+		})
+	}
+
+	entry := s.createRegistrationEntry(newEntry("update"))
+
+	entry.DnsNames = []string{"Updated.Example.org", "updated.example.org "}
+	updated, err := s.ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{DnsNames: true})
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"updated.example.org"}, updated.DnsNames)
+
+	entry.DnsNames = []string{"updated.example.org", "-bad-.example.org"}
+	_, err = s.ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{DnsNames: true})
+	var dnsErr *datastore.InvalidDNSNamesError
+	s.Require().ErrorAs(err, &dnsErr)
+	s.Require().Equal([]string{"-bad-.example.org"}, dnsErr.DNSNames)
+	s.RequireGRPCStatus(err, codes.InvalidArgument, `invalid registration entry: invalid DNS names ["-bad-.example.org"]`)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Require().Equal([]string{"updated.example.org"}, fetched.DnsNames)
+}
+
+func (s *PluginSuite) TestRegistrationEntryDNSNameLimit() {
+	s.ds.maxEntryDNSNames = 3
+
+	newEntry := func(name string, dnsNames ...string) *common.RegistrationEntry {
+		return &common.RegistrationEntry{
+			SpiffeId:  makeID(name),
+			ParentId:  makeID("parent"),
+			Selectors: []*common.Selector{{Type: "a", Value: name}},
+			DnsNames:  dnsNames,
+		}
+	}
+	requireTooMany := func(t *testing.T, err error, count int) {
+		var tooMany *datastore.TooManyDNSNamesError
+		require.ErrorAs(t, err, &tooMany)
+		require.Equal(t, count, tooMany.Count)
+		require.Equal(t, 3, tooMany.MaxCount)
+		spiretest.RequireGRPCStatus(t, err, codes.InvalidArgument, fmt.Sprintf("invalid registration entry: %d DNS names exceeds the maximum of 3", count))
+	}
+
+	s.T().Run("create at the limit", func(t *testing.T) {
+		created, err := s.ds.CreateRegistrationEntry(ctx, newEntry("at-limit", "a.org", "b.org", "c.org"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a.org", "b.org", "c.org"}, created.DnsNames)
+	})
+
+	s.T().Run("create over the limit", func(t *testing.T) {
+		created, err := s.ds.CreateRegistrationEntry(ctx, newEntry("over-limit", "a.org", "b.org", "c.org", "d.org"))
+		requireTooMany(t, err, 4)
+		require.Nil(t, created)
+	})
+
+	s.T().Run("duplicates are counted once", func(t *testing.T) {
+		created, err := s.ds.CreateRegistrationEntry(ctx, newEntry("duplicates", "a.org", "b.org", "c.org", "A.org"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"a.org", "b.org", "c.org"}, created.DnsNames)
+	})
+
+	s.T().Run("batch create over the limit", func(t *testing.T) {
+		results, err := s.ds.CreateRegistrationEntries(ctx, []*common.RegistrationEntry{
+			newEntry("batch-over-limit", "a.org", "b.org", "c.org", "d.org"),
+			newEntry("batch-at-limit", "a.org", "b.org", "c.org"),
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		requireTooMany(t, results[0].Err, 4)
+		require.NoError(t, results[1].Err)
+	})
+
+	entry := s.createRegistrationEntry(newEntry("update", "a.org"))
+
+	s.T().Run("update at the limit", func(t *testing.T) {
+		entry.DnsNames = []string{"a.org", "b.org", "c.org"}
+		updated, err := s.ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{DnsNames: true})
+		require.NoError(t, err)
+		require.Equal(t, []string{"a.org", "b.org", "c.org"}, updated.DnsNames)
+	})
+
+	s.T().Run("update over the limit", func(t *testing.T) {
+		entry.DnsNames = []string{"a.org", "b.org", "c.org", "d.org"}
+		_, err := s.ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{DnsNames: true})
+		requireTooMany(t, err, 4)
+
+		fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a.org", "b.org", "c.org"}, fetched.DnsNames)
+	})
+
+	s.T().Run("existing oversized entries are left alone", func(t *testing.T) {
+		toEntryIDs := func(entries ...*common.RegistrationEntry) []string {
+			var ids []string
+			for _, e := range entries {
+				ids = append(ids, e.EntryId)
+			}
+			return ids
+		}
+
+		s.ds.maxEntryDNSNames = 4
+		oversized := s.createRegistrationEntry(newEntry("oversized", "a.org", "b.org", "c.org", "d.org"))
+		oversized2 := s.createRegistrationEntry(newEntry("oversized2", "a.org", "b.org", "c.org", "d.org"))
+
+		excess, err := s.ds.ListRegistrationEntriesWithExcessDNSNames(ctx)
+		require.NoError(t, err)
+		require.Empty(t, excess)
+
+		s.ds.maxEntryDNSNames = 3
+		excess, err = s.ds.ListRegistrationEntriesWithExcessDNSNames(ctx)
+		require.NoError(t, err)
+		require.Equal(t, toEntryIDs(oversized, oversized2), excess)
+
+		// Updates that don't touch the DNS names are still allowed
+		oversized.Admin = true
+		updated, err := s.ds.UpdateRegistrationEntry(ctx, oversized, &common.RegistrationEntryMask{Admin: true})
+		require.NoError(t, err)
+		require.True(t, updated.Admin)
+		require.Equal(t, []string{"a.org", "b.org", "c.org", "d.org"}, updated.DnsNames)
+
+		// Replacing the DNS names enforces the limit
+		_, err = s.ds.UpdateRegistrationEntry(ctx, oversized, nil)
+		requireTooMany(t, err, 4)
+	})
+}
+
+func (s *PluginSuite) TestExpectedTrustDomain() {
+	s.ds.expectedTrustDomain = spiffeid.RequireTrustDomainFromString("example.org")
+
+	for _, tt := range []struct {
+		name        string
+		entry       *common.RegistrationEntry
+		expectError string
+	}{
+		{
+			name: "in domain",
+			entry: &common.RegistrationEntry{
+				SpiffeId: "spiffe://example.org/workload",
+				ParentId: "spiffe://example.org/agent",
+			},
+		},
+		{
+			name: "SPIFFE ID out of domain",
+			entry: &common.RegistrationEntry{
+				SpiffeId: "spiffe://other.org/workload",
+				ParentId: "spiffe://example.org/agent",
+			},
+			expectError: `rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: SPIFFE ID "spiffe://other.org/workload" is not a member of trust domain "example.org"`,
+		},
+		{
+			name: "parent ID out of domain",
+			entry: &common.RegistrationEntry{
+				SpiffeId: "spiffe://example.org/workload",
+				ParentId: "spiffe://other.org/agent",
+			},
+			expectError: `rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: parent ID "spiffe://other.org/agent" is not a member of trust domain "example.org"`,
+		},
+		{
+			name: "federated trust domain",
+			entry: &common.RegistrationEntry{
+				SpiffeId:      "spiffe://other.org/workload",
+				ParentId:      "spiffe://other.org/agent",
+				FederatesWith: []string{"spiffe://other.org"},
+			},
+		},
+		{
+			name: "join token parent",
+			entry: &common.RegistrationEntry{
+				SpiffeId: "spiffe://example.org/agent",
+				ParentId: "spiffe://example.org/spire/agent/join_token/token",
+			},
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			tt.entry.Selectors = []*common.Selector{{Type: "a", Value: tt.name}}
+			if len(tt.entry.FederatesWith) > 0 {
+				s.createBundle(tt.entry.FederatesWith[0])
+			}
+
+			entry, err := s.ds.CreateRegistrationEntry(ctx, tt.entry)
+			if tt.expectError != "" {
+				require.EqualError(t, err, tt.expectError)
+				require.Nil(t, entry)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.entry.SpiffeId, entry.SpiffeId)
+		})
+	}
+
+	// Entries created in a batch are validated individually
+	results, err := s.ds.CreateRegistrationEntries(ctx, []*common.RegistrationEntry{
+		{
+			SpiffeId:  "spiffe://other.org/batch",
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "a", Value: "batch"}},
+		},
+		{
+			SpiffeId:  "spiffe://example.org/batch",
+			ParentId:  "spiffe://example.org/agent",
+			Selectors: []*common.Selector{{Type: "a", Value: "batch"}},
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(results, 2)
+	s.RequireGRPCStatus(results[0].Err, codes.InvalidArgument, `datastore-validation: invalid registration entry: SPIFFE ID "spiffe://other.org/batch" is not a member of trust domain "example.org"`)
+	s.Require().NoError(results[1].Err)
+
+	_, err = s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/test",
+		AttestationDataType: "test",
+		CertSerialNumber:    "1234",
+	})
+	s.Require().NoError(err)
+
+	node, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:            "spiffe://other.org/spire/agent/test",
+		AttestationDataType: "test",
+		CertSerialNumber:    "1234",
+	})
+	s.RequireGRPCStatus(err, codes.InvalidArgument, `datastore-validation: invalid attested node: SPIFFE ID "spiffe://other.org/spire/agent/test" is not a member of trust domain "example.org"`)
+	s.Require().Nil(node)
+}
+
+func (s *PluginSuite) TestCreateRegistrationEntries() {
+	existing := s.createRegistrationEntry(&common.RegistrationEntry{
+		EntryId:   "existing",
+		SpiffeId:  "spiffe://example.org/existing",
+		ParentId:  "spiffe://example.org/parent",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+
+	newEntry := func(entryID, path string) *common.RegistrationEntry {
+		return &common.RegistrationEntry{
+			EntryId:   entryID,
+			SpiffeId:  "spiffe://example.org/" + path,
+			ParentId:  "spiffe://example.org/parent",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1001"}},
+			DnsNames:  []string{path + ".example.org"},
+		}
+	}
+
+	first := newEntry("first", "first")
+	duplicateID := newEntry("existing", "duplicate")
+	similar := newEntry("", "similar")
+	similar.Selectors = existing.Selectors
+	similar.SpiffeId = existing.SpiffeId
+	similar.DnsNames = nil
+	invalid := newEntry("invalid", "invalid")
+	invalid.Selectors = nil
+	last := newEntry("last", "last")
+
+	results, err := s.ds.CreateRegistrationEntries(ctx, []*common.RegistrationEntry{first, duplicateID, similar, invalid, last})
+	s.Require().NoError(err)
+	s.Require().Len(results, 5)
+
+	s.Require().NoError(results[0].Err)
+	s.Require().False(results[0].Existing)
+	s.Require().Equal("first", results[0].Entry.EntryId)
+	s.Require().Equal(first.DnsNames, results[0].Entry.DnsNames)
+
+	s.Require().Nil(results[1].Entry)
+	s.Require().Equal(codes.AlreadyExists, status.Code(results[1].Err))
+
+	s.Require().NoError(results[2].Err)
+	s.Require().True(results[2].Existing)
+	spiretest.AssertProtoEqual(s.T(), existing, results[2].Entry)
+
+	s.Require().Nil(results[3].Entry)
+	s.Require().EqualError(results[3].Err, "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: missing selector list")
+
+	s.Require().NoError(results[4].Err)
+	s.Require().False(results[4].Existing)
+	s.Require().Equal("last", results[4].Entry.EntryId)
+	s.Require().Equal(last.DnsNames, results[4].Entry.DnsNames)
+
+	// Entries that failed did not prevent the others from being stored, nor
+	// modified the existing entry
+	spiretest.AssertProtoEqual(s.T(), results[0].Entry, s.fetchRegistrationEntry("first"))
+	spiretest.AssertProtoEqual(s.T(), results[4].Entry, s.fetchRegistrationEntry("last"))
+	spiretest.AssertProtoEqual(s.T(), existing, s.fetchRegistrationEntry("existing"))
+
+	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Entries, 3)
+
+	// Events are only created for the new entries
+	eventsResp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	s.Require().NoError(err)
+	var eventEntryIDs []string
+	for _, event := range eventsResp.Events {
+		eventEntryIDs = append(eventEntryIDs, event.EntryID)
+	}
+	s.Require().Equal([]string{"existing", "first", "last"}, eventEntryIDs)
+}
+
+func (s *PluginSuite) TestCreateRegistrationEntriesEmpty() {
+	results, err := s.ds.CreateRegistrationEntries(ctx, nil)
+	s.Require().NoError(err)
+	s.Require().Empty(results)
+}
+
+func (s *PluginSuite) TestCreateInvalidRegistrationEntry() {
+	var invalidRegistrationEntries []*common.RegistrationEntry
+	s.getTestDataFromJSONFile(filepath.Join("testdata", "invalid_registration_entries.json"), &invalidRegistrationEntries)
+
+	for _, invalidRegistrationEntry := range invalidRegistrationEntries {
+		registrationEntry, err := s.ds.CreateRegistrationEntry(ctx, invalidRegistrationEntry)
+		s.Require().Error(err)
+		s.Require().Nil(registrationEntry)
+	}
+
+	// TODO: Check that no entries have been created
+}
+
+func (s *PluginSuite) TestFetchRegistrationEntry() {
+	for _, tt := range []struct {
+		name  string
+		entry *common.RegistrationEntry
+	}{
+		{
+			name: "entry with dns",
+			entry: &common.RegistrationEntry{
+				Selectors: []*common.Selector{
+					{Type: "Type1", Value: "Value1"},
+					{Type: "Type2", Value: "Value2"},
+					{Type: "Type3", Value: "Value3"},
+				},
+				SpiffeId:    "SpiffeId",
+				ParentId:    "ParentId",
+				X509SvidTtl: 1,
+				DnsNames: []string{
+					"abcd.efg",
+					"somehost",
+				},
+			},
+		},
+		{
+			name: "entry with store svid",
+			entry: &common.RegistrationEntry{
+				Selectors: []*common.Selector{
+					{Type: "Type1", Value: "Value1"},
+				},
+				SpiffeId:    "SpiffeId",
+				ParentId:    "ParentId",
+				X509SvidTtl: 1,
+				StoreSvid:   true,
+			},
+		},
+		{
+			name: "entry with hint",
+			entry: &common.RegistrationEntry{
+				Selectors: []*common.Selector{
+					{Type: "Type1", Value: "Value1"},
+				},
+				SpiffeId:    "SpiffeId",
+				ParentId:    "ParentId",
+				X509SvidTtl: 1,
+				Hint:        "external",
+			},
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			createdEntry, err := s.ds.CreateRegistrationEntry(ctx, tt.entry)
+			s.Require().NoError(err)
+			s.Require().NotNil(createdEntry)
+
+			fetchRegistrationEntry, err := s.ds.FetchRegistrationEntry(ctx, createdEntry.EntryId)
+			s.Require().NoError(err)
+			s.RequireProtoEqual(createdEntry, fetchRegistrationEntry)
+		})
+	}
+}
+
+func (s *PluginSuite) TestPruneRegistrationEntries() {
+	now := time.Now()
+	entry := &common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type1", Value: "Value1"},
+			{Type: "Type2", Value: "Value2"},
+			{Type: "Type3", Value: "Value3"},
+		},
+		SpiffeId:    "SpiffeId",
+		ParentId:    "ParentId",
+		X509SvidTtl: 1,
+		EntryExpiry: now.Unix(),
+	}
+
+	createdRegistrationEntry, err := s.ds.CreateRegistrationEntry(ctx, entry)
+	s.Require().NoError(err)
+	fetchedRegistrationEntry := &common.RegistrationEntry{}
+	defaultLastLog := spiretest.LogEntry{
+		Message: "Connected to SQL database",
+	}
+	prunedLogMessage := "Pruned an expired registration"
+
+	resp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal(1, len(resp.Events))
+	s.Require().Equal(createdRegistrationEntry.EntryId, resp.Events[0].EntryID)
+
+	for _, tt := range []struct {
+		name                      string
+		time                      time.Time
+		expectedRegistrationEntry *common.RegistrationEntry
+		expectedLastLog           spiretest.LogEntry
+	}{
+		{
+			name:                      "Don't prune valid entries",
+			time:                      now.Add(-10 * time.Second),
+			expectedRegistrationEntry: createdRegistrationEntry,
+			expectedLastLog:           defaultLastLog,
+		},
+		{
+			name:                      "Don't prune exact ExpiresBefore",
+			time:                      now,
+			expectedRegistrationEntry: createdRegistrationEntry,
+			expectedLastLog:           defaultLastLog,
+		},
+		{
+			name:                      "Prune old entries",
+			time:                      now.Add(10 * time.Second),
+			expectedRegistrationEntry: (*common.RegistrationEntry)(nil),
+			expectedLastLog: spiretest.LogEntry{
+				Level:   logrus.InfoLevel,
+				Message: prunedLogMessage,
+				Data: logrus.Fields{
+					telemetry.SPIFFEID:       createdRegistrationEntry.SpiffeId,
+					telemetry.ParentID:       createdRegistrationEntry.ParentId,
+					telemetry.RegistrationID: createdRegistrationEntry.EntryId,
+				},
+			},
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			// Get latest event id
+			resp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+			require.NoError(t, err)
+			require.Greater(t, len(resp.Events), 0)
+			lastEventID := resp.Events[len(resp.Events)-1].EventID
+
+			// Prune events
+			err = s.ds.PruneRegistrationEntries(ctx, tt.time)
+			require.NoError(t, err)
+			fetchedRegistrationEntry, err = s.ds.FetchRegistrationEntry(ctx, createdRegistrationEntry.EntryId)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedRegistrationEntry, fetchedRegistrationEntry)
+
+			// Verify pruning triggers event creation
+			resp, err = s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{
+				GreaterThanEventID: lastEventID,
+			})
+			require.NoError(t, err)
+			if tt.expectedRegistrationEntry != nil {
+				require.Equal(t, 0, len(resp.Events))
+			} else {
+				require.Equal(t, 1, len(resp.Events))
+				require.Equal(t, createdRegistrationEntry.EntryId, resp.Events[0].EntryID)
+			}
+
+			if tt.expectedLastLog.Message == prunedLogMessage {
+				spiretest.AssertLastLogs(t, s.hook.AllEntries(), []spiretest.LogEntry{tt.expectedLastLog})
+			} else {
+				assert.Equal(t, s.hook.LastEntry().Message, tt.expectedLastLog.Message)
+			}
+		})
+	}
+}
+
+func (s *PluginSuite) TestListRegistrationEntriesToPrune() {
+	now := time.Now()
+	var expiredIDs []string
+	for i, expiry := range []int64{now.Add(-time.Hour).Unix(), 0, now.Add(time.Hour).Unix(), now.Add(-time.Minute).Unix()} {
+		entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			Selectors:   []*common.Selector{{Type: "Type1", Value: "Value1"}},
+			SpiffeId:    fmt.Sprintf("spiffe://example.org/workload-%d", i),
+			ParentId:    "spiffe://example.org/agent",
+			EntryExpiry: expiry,
+		})
+		s.Require().NoError(err)
+		if expiry != 0 && expiry < now.Unix() {
+			expiredIDs = append(expiredIDs, entry.EntryId)
+		}
+	}
+
+	toPrune, err := s.ds.ListRegistrationEntriesToPrune(ctx, now)
+	s.Require().NoError(err)
+	s.Equal(expiredIDs, toPrune)
+
+	// nothing is deleted by the dry run
+	count, err := s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{})
+	s.Require().NoError(err)
+	s.Equal(int32(4), count)
+
+	s.Require().NoError(s.ds.PruneRegistrationEntries(ctx, now))
+	count, err = s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{})
+	s.Require().NoError(err)
+	s.Equal(int32(4-len(toPrune)), count)
+	for _, entryID := range toPrune {
+		entry, err := s.ds.FetchRegistrationEntry(ctx, entryID)
+		s.Require().NoError(err)
+		s.Nil(entry)
+	}
+}
+
+func (s *PluginSuite) TestListRegistrationEntriesExpiringBefore() {
+	now := time.Unix(time.Now().Unix(), 0)
+	entryIDs := make(map[string]string)
+	for name, expiry := range map[string]int64{
+		"no-expiry":  0,
+		"expired":    now.Add(-time.Hour).Unix(),
+		"soon":       now.Add(time.Minute).Unix(),
+		"later":      now.Add(30 * time.Minute).Unix(),
+		"at-window":  now.Add(time.Hour).Unix(),
+		"far-future": now.Add(24 * time.Hour).Unix(),
+	} {
+		entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			Selectors:   []*common.Selector{{Type: "Type1", Value: "Value1"}},
+			SpiffeId:    "spiffe://example.org/" + name,
+			ParentId:    "spiffe://example.org/agent",
+			EntryExpiry: expiry,
+		})
+		s.Require().NoError(err)
+		entryIDs[name] = entry.EntryId
+	}
+
+	for _, tt := range []struct {
+		name          string
+		expiresBefore time.Time
+		expected      []string
+	}{
+		{
+			name:          "entries are listed soonest first",
+			expiresBefore: now.Add(time.Hour),
+			expected:      []string{"expired", "soon", "later"},
+		},
+		{
+			name:          "entries expiring right at the given time are excluded",
+			expiresBefore: now.Add(30 * time.Minute),
+			expected:      []string{"expired", "soon"},
+		},
+		{
+			name:          "entries expiring a second before the given time are included",
+			expiresBefore: now.Add(time.Hour + time.Second),
+			expected:      []string{"expired", "soon", "later", "at-window"},
+		},
+		{
+			name:          "entries without an expiry are never listed",
+			expiresBefore: now.Add(365 * 24 * time.Hour),
+			expected:      []string{"expired", "soon", "later", "at-window", "far-future"},
+		},
+		{
+			name:          "no entries",
+			expiresBefore: now.Add(-2 * time.Hour),
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			var expected []string
+			for _, name := range tt.expected {
+				expected = append(expected, entryIDs[name])
+			}
+
+			actual, err := s.ds.ListRegistrationEntriesExpiringBefore(ctx, tt.expiresBefore)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+		})
+	}
+}
+
+func (s *PluginSuite) TestRegistrationEntryDuplicateSelectorsOnWrite() {
+	entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "unix", Value: "uid:1000"},
+			{Type: "unix", Value: "gid:1000"},
+			{Type: "unix", Value: "uid:1000"},
+		},
+		SpiffeId: "spiffe://example.org/workload",
+		ParentId: "spiffe://example.org/agent",
+	})
+	s.Require().NoError(err)
+	s.AssertProtoListEqual([]*common.Selector{
+		{Type: "unix", Value: "gid:1000"},
+		{Type: "unix", Value: "uid:1000"},
+	}, entry.Selectors)
+
+	// Creating the same entry with its selectors repeated returns it
+	existing, ok, err := s.ds.CreateOrReturnRegistrationEntry(ctx, &common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "unix", Value: "gid:1000"},
+			{Type: "unix", Value: "gid:1000"},
+			{Type: "unix", Value: "uid:1000"},
+		},
+		SpiffeId: "spiffe://example.org/workload",
+		ParentId: "spiffe://example.org/agent",
+	})
+	s.Require().NoError(err)
+	s.True(ok)
+	s.Equal(entry.EntryId, existing.EntryId)
+
+	entry.Selectors = []*common.Selector{
+		{Type: "unix", Value: "uid:1001"},
+		{Type: "unix", Value: "uid:1001"},
+	}
+	updated, err := s.ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{Selectors: true})
+	s.Require().NoError(err)
+	s.AssertProtoListEqual([]*common.Selector{
+		{Type: "unix", Value: "uid:1001"},
+	}, updated.Selectors)
+
+	var count int
+	s.Require().NoError(s.ds.db.Model(&Selector{}).Count(&count).Error)
+	s.Equal(1, count)
+}
+
+func (s *PluginSuite) TestDedupeEntrySelectors() {
+	s.ds.pruneBatchSize = 2
+
+	createEntry := func(name string) (*common.RegistrationEntry, uint) {
+		entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			Selectors: []*common.Selector{
+				{Type: "unix", Value: "gid:1000"},
+				{Type: "unix", Value: "uid:1000"},
+			},
+			SpiffeId: "spiffe://example.org/" + name,
+			ParentId: "spiffe://example.org/agent",
+		})
+		s.Require().NoError(err)
+
+		var model RegisteredEntry
+		s.Require().NoError(s.ds.db.Find(&model, "entry_id = ?", entry.EntryId).Error)
+		return entry, model.ID
+	}
+	selectorIDs := func(registeredEntryID uint) []uint {
+		var ids []uint
+		s.Require().NoError(s.ds.db.Model(&Selector{}).Where("registered_entry_id = ?", registeredEntryID).Order("id").Pluck("id", &ids).Error)
+		return ids
+	}
+
+	valid, validID := createEntry("valid")
+	duplicated, duplicatedID := createEntry("duplicated")
+	expectedValidIDs := selectorIDs(validID)
+	expectedDuplicatedIDs := selectorIDs(duplicatedID)
+
+	// Nothing to deduplicate yet
+	deleted, err := s.ds.DedupeEntrySelectors(ctx)
+	s.Require().NoError(err)
+	s.Zero(deleted)
+
+	// Insert duplicate selectors, as older versions could before the unique
+	// index was in place
+	s.Require().NoError(s.ds.db.Model(&Selector{}).RemoveIndex("idx_selector_entry").Error)
+	for _, selector := range []Selector{
+		{RegisteredEntryID: duplicatedID, Type: "unix", Value: "uid:1000"},
+		{RegisteredEntryID: duplicatedID, Type: "unix", Value: "uid:1000"},
+		{RegisteredEntryID: duplicatedID, Type: "unix", Value: "gid:1000"},
+	} {
+		s.Require().NoError(s.ds.db.Create(&selector).Error)
+	}
+	s.Len(selectorIDs(duplicatedID), 5)
+
+	// Duplicates are deleted over several batches, keeping the oldest row
+	// of each selector
+	deleted, err = s.ds.DedupeEntrySelectors(ctx)
+	s.Require().NoError(err)
+	s.Equal(3, deleted)
+	s.Equal(expectedDuplicatedIDs, selectorIDs(duplicatedID))
+	s.Equal(expectedValidIDs, selectorIDs(validID))
+
+	deleted, err = s.ds.DedupeEntrySelectors(ctx)
+	s.Require().NoError(err)
+	s.Zero(deleted)
+
+	for _, entry := range []*common.RegistrationEntry{valid, duplicated} {
+		fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+		s.Require().NoError(err)
+		s.AssertProtoEqual(entry, fetched)
+	}
+}
+
+func (s *PluginSuite) TestOrphanedEntryChildren() {
+	s.ds.pruneBatchSize = 2
+
+	createEntry := func(name string, dnsNames ...string) (*common.RegistrationEntry, uint) {
+		entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			Selectors: []*common.Selector{
+				{Type: "unix", Value: "gid:1000"},
+				{Type: "unix", Value: "uid:1000"},
+			},
+			SpiffeId: "spiffe://example.org/" + name,
+			ParentId: "spiffe://example.org/agent",
+			DnsNames: dnsNames,
+		})
+		s.Require().NoError(err)
+
+		var model RegisteredEntry
+		s.Require().NoError(s.ds.db.Find(&model, "entry_id = ?", entry.EntryId).Error)
+		return entry, model.ID
+	}
+	childIDs := func(model any, registeredEntryID uint) []uint {
+		var ids []uint
+		s.Require().NoError(s.ds.db.Model(model).Where("registered_entry_id = ?", registeredEntryID).Order("id").Pluck("id", &ids).Error)
+		return ids
+	}
+
+	valid, _ := createEntry("valid", "valid.example.org")
+	_, deletedID := createEntry("deleted", "deleted.example.org", "other.example.org")
+	expectedSelectorIDs := childIDs(&Selector{}, deletedID)
+	expectedDNSNameIDs := childIDs(&DNSName{}, deletedID)
+
+	// Nothing is orphaned yet
+	orphans, err := s.ds.FindOrphanedEntryChildren(ctx, true)
+	s.Require().NoError(err)
+	s.Equal(&datastore.OrphanedEntryChildren{}, orphans)
+
+	// Delete an entry row without its selectors and DNS names, as older
+	// versions could, and add a selector for an entry that never existed
+	s.Require().NoError(s.ds.db.Exec("DELETE FROM registered_entries WHERE id = ?", deletedID).Error)
+	stray := Selector{RegisteredEntryID: deletedID + 100, Type: "unix", Value: "uid:1001"}
+	s.Require().NoError(s.ds.db.Create(&stray).Error)
+	expectedSelectorIDs = append(expectedSelectorIDs, stray.ID)
+
+	orphans, err = s.ds.FindOrphanedEntryChildren(ctx, false)
+	s.Require().NoError(err)
+	s.Equal(&datastore.OrphanedEntryChildren{Selectors: 3, DNSNames: 2}, orphans)
+
+	orphans, err = s.ds.FindOrphanedEntryChildren(ctx, true)
+	s.Require().NoError(err)
+	s.Equal(&datastore.OrphanedEntryChildren{
+		Selectors:   3,
+		DNSNames:    2,
+		SelectorIDs: expectedSelectorIDs,
+		DNSNameIDs:  expectedDNSNameIDs,
+	}, orphans)
+
+	// Orphans are deleted over several batches
+	pruned, err := s.ds.PruneOrphanedEntryChildren(ctx)
+	s.Require().NoError(err)
+	s.Equal(&datastore.OrphanedEntryChildren{Selectors: 3, DNSNames: 2}, pruned)
+
+	orphans, err = s.ds.FindOrphanedEntryChildren(ctx, true)
+	s.Require().NoError(err)
+	s.Equal(&datastore.OrphanedEntryChildren{}, orphans)
+
+	pruned, err = s.ds.PruneOrphanedEntryChildren(ctx)
+	s.Require().NoError(err)
+	s.Equal(&datastore.OrphanedEntryChildren{}, pruned)
+
+	// Valid entries keep their selectors and DNS names
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, valid.EntryId)
+	s.Require().NoError(err)
+	s.AssertProtoEqual(valid, fetched)
+}
+
+func (s *PluginSuite) TestFetchInexistentRegistrationEntry() {
+	fetchedRegistrationEntry, err := s.ds.FetchRegistrationEntry(ctx, "INEXISTENT")
+	s.Require().NoError(err)
+	s.Require().Nil(fetchedRegistrationEntry)
+}
+
+func (s *PluginSuite) TestFetchRegistrationEntryWithParents() {
+	newEntry := func(name, parentID string) *common.RegistrationEntry {
+		return s.createRegistrationEntry(&common.RegistrationEntry{
+			SpiffeId:  makeID(name),
+			ParentId:  parentID,
+			Selectors: []*common.Selector{{Type: "a", Value: name}},
+		})
+	}
+	nodeID := "spiffe://example.org/spire/agent/join_token/token"
+
+	s.T().Run("node parented entry", func(t *testing.T) {
+		entry := newEntry("workload", nodeID)
+
+		chain, err := s.ds.FetchRegistrationEntryWithParents(ctx, entry.EntryId)
+		require.NoError(t, err)
+		spiretest.AssertProtoListEqual(t, []*common.RegistrationEntry{entry}, chain)
+	})
+
+	s.T().Run("two level chain", func(t *testing.T) {
+		node := newEntry("node", nodeID)
+		delegate := newEntry("delegate", node.SpiffeId)
+		workload := newEntry("delegated-workload", delegate.SpiffeId)
+		// Another entry for the parent SPIFFE ID is not followed, since it is
+		// newer than the first one
+		newEntry("delegate", "spiffe://example.org/spire/agent/join_token/other")
+
+		chain, err := s.ds.FetchRegistrationEntryWithParents(ctx, workload.EntryId)
+		require.NoError(t, err)
+		spiretest.AssertProtoListEqual(t, []*common.RegistrationEntry{workload, delegate, node}, chain)
+	})
+
+	s.T().Run("cycle", func(t *testing.T) {
+		first := newEntry("cycle-first", makeID("cycle-second"))
+		newEntry("cycle-second", first.SpiffeId)
+
+		chain, err := s.ds.FetchRegistrationEntryWithParents(ctx, first.EntryId)
+		require.ErrorIs(t, err, datastore.ErrEntryParentCycle)
+		spiretest.RequireGRPCStatus(t, err, codes.FailedPrecondition, "registration entry parent chain has a cycle")
+		require.Nil(t, chain)
+	})
+
+	s.T().Run("chain too deep", func(t *testing.T) {
+		parentID := nodeID
+		var entry *common.RegistrationEntry
+		for i := range maxEntryParentChainDepth + 2 {
+			entry = newEntry(fmt.Sprintf("deep-%d", i), parentID)
+			parentID = entry.SpiffeId
+		}
+
+		chain, err := s.ds.FetchRegistrationEntryWithParents(ctx, entry.EntryId)
+		require.ErrorIs(t, err, datastore.ErrEntryParentChainTooDeep)
+		require.Nil(t, chain)
+	})
+
+	s.T().Run("inexistent entry", func(t *testing.T) {
+		chain, err := s.ds.FetchRegistrationEntryWithParents(ctx, "INEXISTENT")
+		require.NoError(t, err)
+		require.Nil(t, chain)
+	})
+}
+
+func (s *PluginSuite) TestListRegistrationEntries() {
+	// Connection is never used, each test creates new connection to a different database
+	s.ds.Close()
+
+	s.testListRegistrationEntries(datastore.RequireCurrent)
+	s.testListRegistrationEntries(datastore.TolerateStale)
+
+	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		Pagination: &datastore.Pagination{
+			Token:    "invalid int",
+			PageSize: 10,
+		},
+	})
+	s.Require().Error(err, "could not parse token 'invalid int'")
+	s.Require().Nil(resp)
+
+	resp, err = s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{},
+	})
+	s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot list by empty selector set")
+	s.Require().Nil(resp)
+}
+
+func (s *PluginSuite) testListRegistrationEntries(dataConsistency datastore.DataConsistency) {
+	byFederatesWith := func(match datastore.MatchBehavior, trustDomainIDs ...string) *datastore.ByFederatesWith {
+		return &datastore.ByFederatesWith{
+			TrustDomains: trustDomainIDs,
+			Match:        match,
+		}
+	}
+
+	makeEntry := func(parentIDSuffix, spiffeIDSuffix, hint string, selectors ...string) *common.RegistrationEntry {
+		return &common.RegistrationEntry{
+			EntryId:   fmt.Sprintf("%s%s%s", parentIDSuffix, spiffeIDSuffix, strings.Join(selectors, "")),
+			ParentId:  makeID(parentIDSuffix),
+			SpiffeId:  makeID(spiffeIDSuffix),
+			Selectors: makeSelectors(selectors...),
+			Hint:      hint,
+		}
+	}
+
+	foobarAB1 := makeEntry("foo", "bar", "external", "A", "B")
+	foobarAB1.FederatesWith = []string{"spiffe://federated1.test"}
+	foobarAD12 := makeEntry("foo", "bar", "", "A", "D")
+	foobarAD12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
+	foobarCB2 := makeEntry("foo", "bar", "internal", "C", "B")
+	foobarCB2.FederatesWith = []string{"spiffe://federated2.test"}
+	foobarCB2.CreatedBy = makeID("admin")
+	foobarCD12 := makeEntry("foo", "bar", "", "C", "D")
+	foobarCD12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
+
+	foobarB := makeEntry("foo", "bar", "", "B")
+
+	foobuzAD1 := makeEntry("foo", "buz", "", "A", "D")
+	foobuzAD1.FederatesWith = []string{"spiffe://federated1.test"}
+	foobuzCD := makeEntry("foo", "buz", "", "C", "D")
+
+	bazbarAB1 := makeEntry("baz", "bar", "", "A", "B")
+	bazbarAB1.FederatesWith = []string{"spiffe://federated1.test"}
+	bazbarAD12 := makeEntry("baz", "bar", "external", "A", "D")
+	bazbarAD12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
+	bazbarCB2 := makeEntry("baz", "bar", "", "C", "B")
+	bazbarCB2.FederatesWith = []string{"spiffe://federated2.test"}
+	bazbarCD12 := makeEntry("baz", "bar", "", "C", "D")
+	bazbarCD12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
+	bazbarCD12.CreatedBy = makeID("admin")
+	bazbarAE3 := makeEntry("baz", "bar", "", "A", "E")
+	bazbarAE3.FederatesWith = []string{"spiffe://federated3.test"}
+
+	bazbuzAB12 := makeEntry("baz", "buz", "", "A", "B")
+	bazbuzAB12.FederatesWith = []string{"spiffe://federated1.test", "spiffe://federated2.test"}
+	bazbuzB := makeEntry("baz", "buz", "", "B")
+	bazbuzCD := makeEntry("baz", "buz", "", "C", "D")
 
 	zizzazX := makeEntry("ziz", "zaz", "", "X")
 
+	foosvidB1 := makeEntry("foo", "svid", "", "B")
+	foosvidB1.FederatesWith = []string{"spiffe://federated1.test"}
+	foosvidB1.StoreSvid = true
+	bazsvidC := makeEntry("baz", "svid", "", "C")
+	bazsvidC.StoreSvid = true
+	storeSvidTrue := true
+	storeSvidFalse := false
+
+	fooadminA := makeEntry("foo", "admin", "", "A")
+	fooadminA.Admin = true
+	foodownstreamB1 := makeEntry("foo", "downstream", "", "B")
+	foodownstreamB1.FederatesWith = []string{"spiffe://federated1.test"}
+	foodownstreamB1.Downstream = true
+	bazbothC := makeEntry("baz", "both", "", "C")
+	bazbothC.Admin = true
+	bazbothC.Downstream = true
+	flagTrue := true
+	flagFalse := false
+	hintExternal := "external"
+	hintNone := "none"
+	hintEmpty := ""
+
 	for _, tt := range []struct {
 		test                  string
 		entries               []*common.RegistrationEntry
 		pageSize              int32
 		byParentID            string
 		bySpiffeID            string
-		byHint                string
+		byHint                *string
+		byCreatedBy           string
+		byStoreSvid           *bool
+		byAdmin               *bool
+		byDownstream          *bool
 		bySelectors           *datastore.BySelectors
 		byFederatesWith       *datastore.ByFederatesWith
 		expectEntriesOut      []*common.RegistrationEntry
@@ -2334,7 +5124,7 @@ func (s *PluginSuite) testListRegistrationEntries(dataConsistency datastore.Data
 		{
 			test:                  "by Hint, two matches",
 			entries:               []*common.RegistrationEntry{foobarAB1, bazbarAD12, foobarCB2, bazbarCD12},
-			byHint:                "external",
+			byHint:                &hintExternal,
 			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, bazbarAD12},
 			expectPagedTokensIn:   []string{"", "1", "2"},
 			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {bazbarAD12}, {}},
@@ -2342,11 +5132,165 @@ func (s *PluginSuite) testListRegistrationEntries(dataConsistency datastore.Data
 		{
 			test:                  "by Hint, no match",
 			entries:               []*common.RegistrationEntry{foobarAB1, bazbarAD12, foobarCB2, bazbarCD12},
-			byHint:                "none",
+			byHint:                &hintNone,
+			expectEntriesOut:      []*common.RegistrationEntry{},
+			expectPagedTokensIn:   []string{""},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+		},
+		{
+			test:                  "by Hint, empty",
+			entries:               []*common.RegistrationEntry{foobarAB1, bazbarAD12, foobarCB2, bazbarCD12},
+			byHint:                &hintEmpty,
+			expectEntriesOut:      []*common.RegistrationEntry{bazbarCD12},
+			expectPagedTokensIn:   []string{"", "4"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{bazbarCD12}, {}},
+		},
+		// by CreatedBy
+		{
+			test:                  "by CreatedBy, two matches",
+			entries:               []*common.RegistrationEntry{foobarAB1, bazbarAD12, foobarCB2, bazbarCD12},
+			byCreatedBy:           makeID("admin"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarCB2, bazbarCD12},
+			expectPagedTokensIn:   []string{"", "3", "4"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarCB2}, {bazbarCD12}, {}},
+		},
+		{
+			test:                  "by CreatedBy and parent ID",
+			entries:               []*common.RegistrationEntry{foobarAB1, bazbarAD12, foobarCB2, bazbarCD12},
+			byParentID:            makeID("baz"),
+			byCreatedBy:           makeID("admin"),
+			expectEntriesOut:      []*common.RegistrationEntry{bazbarCD12},
+			expectPagedTokensIn:   []string{"", "4"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{bazbarCD12}, {}},
+		},
+		{
+			test:                  "by CreatedBy, no match",
+			entries:               []*common.RegistrationEntry{foobarAB1, bazbarAD12, foobarCB2, bazbarCD12},
+			byCreatedBy:           makeID("nobody"),
 			expectEntriesOut:      []*common.RegistrationEntry{},
 			expectPagedTokensIn:   []string{""},
 			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
 		},
+		{
+			test:                  "by StoreSvid true",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarB, foosvidB1, bazsvidC},
+			byStoreSvid:           &storeSvidTrue,
+			expectEntriesOut:      []*common.RegistrationEntry{foosvidB1, bazsvidC},
+			expectPagedTokensIn:   []string{"", "3", "4"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foosvidB1}, {bazsvidC}, {}},
+		},
+		{
+			test:                  "by StoreSvid false",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarB, foosvidB1, bazsvidC},
+			byStoreSvid:           &storeSvidFalse,
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, foobarB},
+			expectPagedTokensIn:   []string{"", "1", "2"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {foobarB}, {}},
+		},
+		{
+			test:                  "by StoreSvid and parent ID",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarB, foosvidB1, bazsvidC},
+			byParentID:            makeID("baz"),
+			byStoreSvid:           &storeSvidTrue,
+			expectEntriesOut:      []*common.RegistrationEntry{bazsvidC},
+			expectPagedTokensIn:   []string{"", "4"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{bazsvidC}, {}},
+		},
+		{
+			test:                  "by StoreSvid and selector",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarB, foosvidB1, bazsvidC},
+			bySelectors:           bySelectors(datastore.Superset, "B"),
+			byStoreSvid:           &storeSvidTrue,
+			expectEntriesOut:      []*common.RegistrationEntry{foosvidB1},
+			expectPagedTokensIn:   []string{"", "3"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foosvidB1}, {}},
+		},
+		{
+			test:                  "by StoreSvid and federatesWith",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarB, foosvidB1, bazsvidC},
+			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated1.test"),
+			byStoreSvid:           &storeSvidTrue,
+			expectEntriesOut:      []*common.RegistrationEntry{foosvidB1},
+			expectPagedTokensIn:   []string{"", "3"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foosvidB1}, {}},
+		},
+		{
+			test:                  "by StoreSvid false and federatesWith",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarB, foosvidB1, bazsvidC},
+			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated1.test"),
+			byStoreSvid:           &storeSvidFalse,
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1},
+			expectPagedTokensIn:   []string{"", "1"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {}},
+		},
+		{
+			test:                  "by Admin true",
+			entries:               []*common.RegistrationEntry{foobarAB1, fooadminA, foodownstreamB1, bazbothC},
+			byAdmin:               &flagTrue,
+			expectEntriesOut:      []*common.RegistrationEntry{fooadminA, bazbothC},
+			expectPagedTokensIn:   []string{"", "2", "4"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{fooadminA}, {bazbothC}, {}},
+		},
+		{
+			test:                  "by Admin false",
+			entries:               []*common.RegistrationEntry{foobarAB1, fooadminA, foodownstreamB1, bazbothC},
+			byAdmin:               &flagFalse,
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, foodownstreamB1},
+			expectPagedTokensIn:   []string{"", "1", "3"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {foodownstreamB1}, {}},
+		},
+		{
+			test:                  "by Downstream true",
+			entries:               []*common.RegistrationEntry{foobarAB1, fooadminA, foodownstreamB1, bazbothC},
+			byDownstream:          &flagTrue,
+			expectEntriesOut:      []*common.RegistrationEntry{foodownstreamB1, bazbothC},
+			expectPagedTokensIn:   []string{"", "3", "4"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foodownstreamB1}, {bazbothC}, {}},
+		},
+		{
+			test:                  "by Downstream false",
+			entries:               []*common.RegistrationEntry{foobarAB1, fooadminA, foodownstreamB1, bazbothC},
+			byDownstream:          &flagFalse,
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, fooadminA},
+			expectPagedTokensIn:   []string{"", "1", "2"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {fooadminA}, {}},
+		},
+		{
+			test:                  "by Admin and Downstream true",
+			entries:               []*common.RegistrationEntry{foobarAB1, fooadminA, foodownstreamB1, bazbothC},
+			byAdmin:               &flagTrue,
+			byDownstream:          &flagTrue,
+			expectEntriesOut:      []*common.RegistrationEntry{bazbothC},
+			expectPagedTokensIn:   []string{"", "4"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{bazbothC}, {}},
+		},
+		{
+			test:                  "by Admin true and Downstream false",
+			entries:               []*common.RegistrationEntry{foobarAB1, fooadminA, foodownstreamB1, bazbothC},
+			byAdmin:               &flagTrue,
+			byDownstream:          &flagFalse,
+			expectEntriesOut:      []*common.RegistrationEntry{fooadminA},
+			expectPagedTokensIn:   []string{"", "2"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{fooadminA}, {}},
+		},
+		{
+			test:                  "by Admin and Downstream false",
+			entries:               []*common.RegistrationEntry{foobarAB1, fooadminA, foodownstreamB1, bazbothC},
+			byAdmin:               &flagFalse,
+			byDownstream:          &flagFalse,
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1},
+			expectPagedTokensIn:   []string{"", "1"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {}},
+		},
+		{
+			test:                  "by Downstream and federatesWith",
+			entries:               []*common.RegistrationEntry{foobarAB1, fooadminA, foodownstreamB1, bazbothC},
+			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated1.test"),
+			byDownstream:          &flagTrue,
+			expectEntriesOut:      []*common.RegistrationEntry{foodownstreamB1},
+			expectPagedTokensIn:   []string{"", "3"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foodownstreamB1}, {}},
+		},
 		// by federates with
 		{
 			test:                  "by federatesWith one subset",
@@ -2678,283 +5622,1045 @@ func (s *PluginSuite) testListRegistrationEntries(dataConsistency datastore.Data
 			expectPagedTokensIn:   []string{"", "1", "2"},
 			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarB}, {foobarAB1}, {}},
 		},
-		{
-			test:                  "by SPIFFE ID and superset selectors",
-			entries:               []*common.RegistrationEntry{foobarB, foobarAB1, bazbarCB2, bazbuzCD},
-			bySpiffeID:            makeID("bar"),
-			bySelectors:           bySelectors(datastore.Superset, "A", "B"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1},
-			expectPagedTokensIn:   []string{"", "2"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {}},
+		{
+			test:                  "by SPIFFE ID and superset selectors",
+			entries:               []*common.RegistrationEntry{foobarB, foobarAB1, bazbarCB2, bazbuzCD},
+			bySpiffeID:            makeID("bar"),
+			bySelectors:           bySelectors(datastore.Superset, "A", "B"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1},
+			expectPagedTokensIn:   []string{"", "2"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and superset selectors no match",
+			entries:               []*common.RegistrationEntry{foobarB, foobarAB1, bazbarCB2, bazbuzCD},
+			bySpiffeID:            makeID("bar"),
+			bySelectors:           bySelectors(datastore.Superset, "A", "B", "Z"),
+			expectEntriesOut:      []*common.RegistrationEntry{},
+			expectPagedTokensIn:   []string{""},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+		},
+		// by spiffe ID and federates with
+		{
+			test:                  "by SPIFFE ID and federatesWith one subset",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("bar"),
+			byFederatesWith:       byFederatesWith(datastore.Subset, "spiffe://federated1.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, bazbarAB1},
+			expectPagedTokensIn:   []string{"", "1", "6"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {bazbarAB1}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith many subset",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("bar"),
+			byFederatesWith:       byFederatesWith(datastore.Subset, "spiffe://federated2.test", "spiffe://federated3.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarCB2, bazbarCB2},
+			expectPagedTokensIn:   []string{"", "3", "8"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarCB2}, {bazbarCB2}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith one exact",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("bar"),
+			byFederatesWith:       byFederatesWith(datastore.Exact, "spiffe://federated1.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, bazbarAB1},
+			expectPagedTokensIn:   []string{"", "1", "6"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {bazbarAB1}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith many exact",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("bar"),
+			byFederatesWith:       byFederatesWith(datastore.Exact, "spiffe://federated1.test", "spiffe://federated2.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAD12, foobarCD12, bazbarAD12, bazbarCD12},
+			expectPagedTokensIn:   []string{"", "2", "4", "7", "9"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAD12}, {foobarCD12}, {bazbarAD12}, {bazbarCD12}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith subset no results",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("buz"),
+			byFederatesWith:       byFederatesWith(datastore.Subset, "spiffe://federated2.test", "spiffe://federated3.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{},
+			expectPagedTokensIn:   []string{""},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith match any",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("bar"),
+			byFederatesWith:       byFederatesWith(datastore.MatchAny, "spiffe://federated1.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCD12, bazbarAB1, bazbarAD12, bazbarCD12},
+			expectPagedTokensIn:   []string{"", "1", "2", "4", "6", "7", "9"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {foobarAD12}, {foobarCD12}, {bazbarAB1}, {bazbarAD12}, {bazbarCD12}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith many match any",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("bar"),
+			byFederatesWith:       byFederatesWith(datastore.MatchAny, "spiffe://federated1.test", "spiffe://federated2.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12},
+			expectPagedTokensIn:   []string{"", "1", "2", "3", "4", "6", "7", "8", "9"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {foobarAD12}, {foobarCB2}, {foobarCD12}, {bazbarAB1}, {bazbarAD12}, {bazbarCB2}, {bazbarCD12}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith match any no results",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("buz"),
+			byFederatesWith:       byFederatesWith(datastore.MatchAny, "spiffe://federated3.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{},
+			expectPagedTokensIn:   []string{""},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith superset",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("bar"),
+			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated1.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCD12, bazbarAB1, bazbarAD12, bazbarCD12},
+			expectPagedTokensIn:   []string{"", "1", "2", "4", "6", "7", "9"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {foobarAD12}, {foobarCD12}, {bazbarAB1}, {bazbarAD12}, {bazbarCD12}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith many superset",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("bar"),
+			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated1.test", "spiffe://federated2.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAD12, foobarCD12, bazbarAD12, bazbarCD12},
+			expectPagedTokensIn:   []string{"", "2", "4", "7", "9"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAD12}, {foobarCD12}, {bazbarAD12}, {bazbarCD12}, {}},
+		},
+		{
+			test:                  "by SPIFFE ID and federatesWith superset no results",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
+			bySpiffeID:            makeID("buz"),
+			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated2.test", "spiffe://federated3.test"),
+			expectEntriesOut:      []*common.RegistrationEntry{},
+			expectPagedTokensIn:   []string{""},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+		},
+		// Make sure ByFedaratesWith and BySelectors can be used together
+		{
+			test:                  "by Parent ID, federatesWith and selectors",
+			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12},
+			byParentID:            makeID("foo"),
+			byFederatesWith:       byFederatesWith(datastore.Subset, "spiffe://federated1.test", "spiffe://federated2.test"),
+			bySelectors:           bySelectors(datastore.Subset, "A", "D"),
+			expectEntriesOut:      []*common.RegistrationEntry{foobarAD12},
+			expectPagedTokensIn:   []string{"", "2"},
+			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAD12}, {}},
+		},
+	} {
+		for _, withPagination := range []bool{true, false} {
+			name := tt.test
+			if withPagination {
+				name += " with pagination"
+			} else {
+				name += " without pagination"
+			}
+			if dataConsistency == datastore.TolerateStale {
+				name += " read-only"
+			}
+			s.T().Run(name, func(t *testing.T) {
+				s.ds = s.newPlugin()
+				defer s.ds.Close()
+
+				s.createBundle("spiffe://federated1.test")
+				s.createBundle("spiffe://federated2.test")
+				s.createBundle("spiffe://federated3.test")
+
+				// Create entries for the test. For convenience, map the actual
+				// entry ID to the "test" entry ID, so we can easily pinpoint
+				// which entries were unexpectedly missing or included in the
+				// listing.
+				entryIDMap := map[string]string{}
+				for _, entryIn := range tt.entries {
+					entryOut := s.createRegistrationEntry(entryIn)
+					entryIDMap[entryOut.EntryId] = entryIn.EntryId
+				}
+
+				// Optionally sleep to give time for the entries to propagate to
+				// the replicas.
+				if dataConsistency == datastore.TolerateStale && s.readOnlyDelay > 0 {
+					time.Sleep(s.readOnlyDelay)
+				}
+
+				var pagination *datastore.Pagination
+				if withPagination {
+					pagination = &datastore.Pagination{
+						PageSize: tt.pageSize,
+					}
+					if pagination.PageSize == 0 {
+						pagination.PageSize = 1
+					}
+				}
+
+				var tokensIn []string
+				actualEntriesOut := make(map[string]*common.RegistrationEntry)
+				expectedEntriesOut := make(map[string]*common.RegistrationEntry)
+				req := &datastore.ListRegistrationEntriesRequest{
+					Pagination:      pagination,
+					ByParentID:      tt.byParentID,
+					BySpiffeID:      tt.bySpiffeID,
+					BySelectors:     tt.bySelectors,
+					ByFederatesWith: tt.byFederatesWith,
+					ByHint:          tt.byHint,
+					ByCreatedBy:     tt.byCreatedBy,
+					ByStoreSvid:     tt.byStoreSvid,
+					ByAdmin:         tt.byAdmin,
+					ByDownstream:    tt.byDownstream,
+				}
+
+				for i := 0; ; i++ {
+					// Don't loop forever if there is a bug
+					if i > len(tt.entries) {
+						require.FailNowf(t, "Exhausted paging limit in test", "tokens=%q spiffeids=%q", tokensIn, actualEntriesOut)
+					}
+					if req.Pagination != nil {
+						tokensIn = append(tokensIn, req.Pagination.Token)
+					}
+					resp, err := s.ds.ListRegistrationEntries(ctx, req)
+					require.NoError(t, err)
+					require.NotNil(t, resp)
+					if withPagination {
+						require.NotNil(t, resp.Pagination, "response missing pagination")
+						assert.Equal(t, req.Pagination.PageSize, resp.Pagination.PageSize, "response page size did not match request")
+					} else {
+						assert.Nil(t, resp.Pagination, "response has pagination")
+					}
+
+					for _, entry := range resp.Entries {
+						entryID, ok := entryIDMap[entry.EntryId]
+						require.True(t, ok, "entry with id %q was not created by this test", entry.EntryId)
+						entry.EntryId = entryID
+						actualEntriesOut[entryID] = entry
+					}
+
+					if resp.Pagination == nil || resp.Pagination.Token == "" {
+						break
+					}
+					req.Pagination = resp.Pagination
+				}
+
+				expectEntriesOut := tt.expectPagedEntriesOut
+				if !withPagination {
+					expectEntriesOut = [][]*common.RegistrationEntry{tt.expectEntriesOut}
+				}
+
+				for _, entrySet := range expectEntriesOut {
+					for _, entry := range entrySet {
+						expectedEntriesOut[entry.EntryId] = entry
+					}
+				}
+
+				if withPagination {
+					// Tokens also carry the snapshot taken when the listing
+					// started, which is the ID of the last entry created.
+					var expectTokensIn []string
+					for _, token := range tt.expectPagedTokensIn {
+						if token != "" {
+							token = fmt.Sprintf("%s:%d", token, len(tt.entries))
+						}
+						expectTokensIn = append(expectTokensIn, token)
+					}
+					assert.Equal(t, expectTokensIn, tokensIn, "unexpected request tokens")
+				} else {
+					assert.Empty(t, tokensIn, "unexpected request tokens")
+				}
+
+				assert.Len(t, actualEntriesOut, len(expectedEntriesOut), "unexpected number of entries returned")
+				for id, expectedEntry := range expectedEntriesOut {
+					if _, ok := actualEntriesOut[id]; !ok {
+						t.Errorf("Expected entry %q not found", id)
+						continue
+					}
+					// Some databases are not returning federated IDs in the same order (e.g. mysql)
+					sort.Strings(actualEntriesOut[id].FederatesWith)
+					s.assertCreatedAtField(actualEntriesOut[id], expectedEntry.CreatedAt)
+					spiretest.AssertProtoEqual(t, expectedEntry, actualEntriesOut[id])
+				}
+			})
+		}
+	}
+}
+
+func (s *PluginSuite) TestCountRegistrationEntriesByTrustDomain() {
+	counts, err := s.ds.CountRegistrationEntriesByTrustDomain(ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(counts)
+
+	createEntry := func(spiffeID string) *common.RegistrationEntry {
+		entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			Selectors: []*common.Selector{{Type: "TYPE", Value: spiffeID}},
+			SpiffeId:  spiffeID,
+			ParentId:  "spiffe://example.org/parent",
+		})
+		s.Require().NoError(err)
+		return entry
+	}
+
+	createEntry("spiffe://example.org/foo")
+	createEntry("spiffe://example.org/bar")
+	createEntry("spiffe://example.org")
+	updated := createEntry("spiffe://other.test/foo")
+	createEntry("spiffe://other.test/bar")
+	createEntry("not-a-spiffe-id")
+
+	counts, err = s.ds.CountRegistrationEntriesByTrustDomain(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(map[string]int32{
+		"example.org": 3,
+		"other.test":  2,
+		"":            1,
+	}, counts)
+
+	// Updating the SPIFFE ID moves the entry to the new trust domain
+	updated.SpiffeId = "spiffe://third.test/foo"
+	_, err = s.ds.UpdateRegistrationEntry(ctx, updated, &common.RegistrationEntryMask{SpiffeId: true})
+	s.Require().NoError(err)
+
+	counts, err = s.ds.CountRegistrationEntriesByTrustDomain(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(map[string]int32{
+		"example.org": 3,
+		"other.test":  1,
+		"third.test":  1,
+		"":            1,
+	}, counts)
+}
+
+func (s *PluginSuite) TestListRegistrationEntriesWhenCruftRowsExist() {
+	_, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "TYPE", Value: "VALUE"},
+		},
+		SpiffeId: "SpiffeId",
+		ParentId: "ParentId",
+		DnsNames: []string{
+			"abcd.efg",
+			"somehost",
 		},
+	})
+	s.Require().NoError(err)
+
+	// This is gross. Since the bug that left selectors around has been fixed
+	// (#1191), I'm not sure how else to test this other than just sneaking in
+	// there and removing the registered_entries row.
+	res, err := s.ds.db.raw.Exec("DELETE FROM registered_entries")
+	s.Require().NoError(err)
+	rowsAffected, err := res.RowsAffected()
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), rowsAffected)
+
+	// Assert that no rows are returned.
+	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.Entries)
+}
+
+func (s *PluginSuite) TestListRegistrationEntriesBySelectorValuePrefix() {
+	makeEntry := func(name string, selectors ...*common.Selector) string {
+		entry := s.createRegistrationEntry(&common.RegistrationEntry{
+			EntryId:   name,
+			ParentId:  makeID("parent"),
+			SpiffeId:  makeID(name),
+			Selectors: selectors,
+		})
+		return entry.EntryId
+	}
+	k8s := func(value string) *common.Selector {
+		return &common.Selector{Type: "k8s", Value: value}
+	}
+
+	makeEntry("prod", k8s("ns:prod"))
+	makeEntry("prod-web", k8s("ns:prod-web"), k8s("sa:web"))
+	makeEntry("prod-api", k8s("ns:prod-api"), k8s("sa:api"))
+	makeEntry("production", k8s("ns:production"))
+	makeEntry("pro_d", k8s("ns:pro_d"))
+	makeEntry("percent", k8s("ns:100%-a"))
+	makeEntry("thousand", k8s("ns:1000-a"))
+	makeEntry("bang", k8s("ns:!prod"))
+	makeEntry("unix", &common.Selector{Type: "unix", Value: "ns:prod"})
+
+	for _, tt := range []struct {
+		name          string
+		selectorType  string
+		prefix        string
+		bySelectors   *datastore.BySelectors
+		expectEntries []string
+	}{
 		{
-			test:                  "by SPIFFE ID and superset selectors no match",
-			entries:               []*common.RegistrationEntry{foobarB, foobarAB1, bazbarCB2, bazbuzCD},
-			bySpiffeID:            makeID("bar"),
-			bySelectors:           bySelectors(datastore.Superset, "A", "B", "Z"),
-			expectEntriesOut:      []*common.RegistrationEntry{},
-			expectPagedTokensIn:   []string{""},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+			name:          "matching prefix",
+			selectorType:  "k8s",
+			prefix:        "ns:prod-",
+			expectEntries: []string{"prod-api", "prod-web"},
 		},
-		// by spiffe ID and federates with
 		{
-			test:                  "by SPIFFE ID and federatesWith one subset",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("bar"),
-			byFederatesWith:       byFederatesWith(datastore.Subset, "spiffe://federated1.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, bazbarAB1},
-			expectPagedTokensIn:   []string{"", "1", "6"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {bazbarAB1}, {}},
+			name:          "prefix matching whole value",
+			selectorType:  "k8s",
+			prefix:        "ns:prod",
+			expectEntries: []string{"prod", "prod-api", "prod-web", "production"},
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith many subset",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("bar"),
-			byFederatesWith:       byFederatesWith(datastore.Subset, "spiffe://federated2.test", "spiffe://federated3.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarCB2, bazbarCB2},
-			expectPagedTokensIn:   []string{"", "3", "8"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarCB2}, {bazbarCB2}, {}},
+			name:         "non-matching prefix",
+			selectorType: "k8s",
+			prefix:       "ns:staging",
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith one exact",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("bar"),
-			byFederatesWith:       byFederatesWith(datastore.Exact, "spiffe://federated1.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, bazbarAB1},
-			expectPagedTokensIn:   []string{"", "1", "6"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {bazbarAB1}, {}},
+			name:         "non-matching type",
+			selectorType: "docker",
+			prefix:       "ns:prod",
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith many exact",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("bar"),
-			byFederatesWith:       byFederatesWith(datastore.Exact, "spiffe://federated1.test", "spiffe://federated2.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAD12, foobarCD12, bazbarAD12, bazbarCD12},
-			expectPagedTokensIn:   []string{"", "2", "4", "7", "9"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAD12}, {foobarCD12}, {bazbarAD12}, {bazbarCD12}, {}},
+			name:          "empty prefix",
+			selectorType:  "unix",
+			expectEntries: []string{"unix"},
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith subset no results",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("buz"),
-			byFederatesWith:       byFederatesWith(datastore.Subset, "spiffe://federated2.test", "spiffe://federated3.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{},
-			expectPagedTokensIn:   []string{""},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+			name:          "underscore is matched literally",
+			selectorType:  "k8s",
+			prefix:        "ns:pro_",
+			expectEntries: []string{"pro_d"},
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith match any",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("bar"),
-			byFederatesWith:       byFederatesWith(datastore.MatchAny, "spiffe://federated1.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCD12, bazbarAB1, bazbarAD12, bazbarCD12},
-			expectPagedTokensIn:   []string{"", "1", "2", "4", "6", "7", "9"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {foobarAD12}, {foobarCD12}, {bazbarAB1}, {bazbarAD12}, {bazbarCD12}, {}},
+			name:          "percent sign is matched literally",
+			selectorType:  "k8s",
+			prefix:        "ns:100%",
+			expectEntries: []string{"percent"},
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith many match any",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("bar"),
-			byFederatesWith:       byFederatesWith(datastore.MatchAny, "spiffe://federated1.test", "spiffe://federated2.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12},
-			expectPagedTokensIn:   []string{"", "1", "2", "3", "4", "6", "7", "8", "9"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {foobarAD12}, {foobarCB2}, {foobarCD12}, {bazbarAB1}, {bazbarAD12}, {bazbarCB2}, {bazbarCD12}, {}},
+			name:          "escape character is matched literally",
+			selectorType:  "k8s",
+			prefix:        "ns:!",
+			expectEntries: []string{"bang"},
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith match any no results",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("buz"),
-			byFederatesWith:       byFederatesWith(datastore.MatchAny, "spiffe://federated3.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{},
-			expectPagedTokensIn:   []string{""},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+			name:          "composes with selectors",
+			selectorType:  "k8s",
+			prefix:        "ns:prod",
+			bySelectors:   &datastore.BySelectors{Match: datastore.Superset, Selectors: []*common.Selector{k8s("sa:web")}},
+			expectEntries: []string{"prod-web"},
 		},
+	} {
+		for _, withPagination := range []bool{true, false} {
+			name := tt.name
+			if withPagination {
+				name += " with pagination"
+			}
+			s.T().Run(name, func(t *testing.T) {
+				req := &datastore.ListRegistrationEntriesRequest{
+					BySelectors: tt.bySelectors,
+					BySelectorValuePrefix: &datastore.BySelectorValuePrefix{
+						Type:   tt.selectorType,
+						Prefix: tt.prefix,
+					},
+				}
+				if withPagination {
+					req.Pagination = &datastore.Pagination{PageSize: 1}
+				}
+
+				var entryIDs []string
+				for {
+					resp, err := s.ds.ListRegistrationEntries(ctx, req)
+					require.NoError(t, err)
+					for _, entry := range resp.Entries {
+						entryIDs = append(entryIDs, entry.EntryId)
+					}
+					if resp.Pagination == nil || resp.Pagination.Token == "" {
+						break
+					}
+					req.Pagination = resp.Pagination
+				}
+				sort.Strings(entryIDs)
+				require.Equal(t, tt.expectEntries, entryIDs)
+			})
+		}
+	}
+
+	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		BySelectorValuePrefix: &datastore.BySelectorValuePrefix{Prefix: "ns:"},
+	})
+	s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot list by selector value prefix without a selector type")
+	s.Require().Nil(resp)
+}
+
+func (s *PluginSuite) TestListRegistrationEntriesPaginationSnapshot() {
+	createEntry := func(name string) string {
+		entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			Selectors: []*common.Selector{{Type: "TYPE", Value: name}},
+			SpiffeId:  makeID(name),
+			ParentId:  makeID("parent"),
+		})
+		s.Require().NoError(err)
+		return entry.EntryId
+	}
+
+	var expectedIDs []string
+	for i := range 5 {
+		expectedIDs = append(expectedIDs, createEntry(fmt.Sprintf("original-%d", i)))
+	}
+
+	listAll := func(token string, createWhilePaging bool) []string {
+		var entryIDs []string
+		req := &datastore.ListRegistrationEntriesRequest{
+			Pagination: &datastore.Pagination{
+				Token:    token,
+				PageSize: 2,
+			},
+		}
+		for i := 0; ; i++ {
+			resp, err := s.ds.ListRegistrationEntries(ctx, req)
+			s.Require().NoError(err)
+			for _, entry := range resp.Entries {
+				entryIDs = append(entryIDs, entry.EntryId)
+			}
+			if resp.Pagination.Token == "" {
+				return entryIDs
+			}
+			if createWhilePaging {
+				createEntry(fmt.Sprintf("concurrent-%s-%d", token, i))
+			}
+			req.Pagination = resp.Pagination
+		}
+	}
+
+	s.Run("entries created while paging are not returned", func() {
+		s.Require().Equal(expectedIDs, listAll("", true))
+	})
+
+	s.Run("legacy token takes a snapshot when resumed", func() {
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+		s.Require().NoError(err)
+		var allIDs []string
+		for _, entry := range resp.Entries {
+			allIDs = append(allIDs, entry.EntryId)
+		}
+
+		// A token holding only the last entry ID resumes after that entry
+		entryIDs := listAll("1", true)
+		s.Require().Equal(allIDs[1:], entryIDs)
+	})
+
+	s.Run("invalid snapshot", func() {
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			Pagination: &datastore.Pagination{
+				Token:    "1:invalid",
+				PageSize: 2,
+			},
+		})
+		s.RequireGRPCStatus(err, codes.InvalidArgument, "could not parse token '1:invalid'")
+		s.Require().Nil(resp)
+	})
+
+	s.Run("snapshot is read in the transaction of the first page", func() {
+		db := &recordingTxBeginner{DB: s.ds.db.raw}
+		resp, err := listRegistrationEntriesOnce(ctx, db, s.ds.db.databaseType, s.ds.db.supportsCTE, &datastore.ListRegistrationEntriesRequest{
+			Pagination: &datastore.Pagination{PageSize: 2},
+		})
+		s.Require().NoError(err)
+		s.Require().Len(resp.Entries, 2)
+		s.Require().Equal([]*sql.TxOptions{snapshotTxOptions}, db.txOptions)
+		s.Require().Empty(db.queries, "no query should be run outside of the transaction")
+	})
+}
+
+// recordingTxBeginner records the transactions begun on a database and the
+// queries run on it outside of them.
+type recordingTxBeginner struct {
+	*sql.DB
+	txOptions []*sql.TxOptions
+	queries   []string
+}
+
+func (r *recordingTxBeginner) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	r.queries = append(r.queries, query)
+	return r.DB.QueryContext(ctx, query, args...)
+}
+
+func (r *recordingTxBeginner) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	r.txOptions = append(r.txOptions, opts)
+	return r.DB.BeginTx(ctx, opts)
+}
+
+func (s *PluginSuite) TestListClampsPageSizeToMaximum() {
+	s.ds.maxPageSize = 2
+
+	var expectedEntryIDs, expectedNodeIDs []string
+	for i := range 5 {
+		entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			Selectors: []*common.Selector{{Type: "TYPE", Value: fmt.Sprintf("value-%d", i)}},
+			SpiffeId:  makeID(fmt.Sprintf("workload-%d", i)),
+			ParentId:  makeID("parent"),
+		})
+		s.Require().NoError(err)
+		expectedEntryIDs = append(expectedEntryIDs, entry.EntryId)
+
+		node, err := s.ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:            makeID(fmt.Sprintf("agent-%d", i)),
+			AttestationDataType: "aws-tag",
+			CertSerialNumber:    "badcafe",
+			CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		})
+		s.Require().NoError(err)
+		expectedNodeIDs = append(expectedNodeIDs, node.SpiffeId)
+	}
+
+	s.Run("registration entries", func() {
+		pagination := &datastore.Pagination{PageSize: 10000}
+		var entryIDs []string
+		for {
+			resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+				Pagination: pagination,
+			})
+			s.Require().NoError(err)
+			s.Require().LessOrEqual(len(resp.Entries), 2)
+			s.Require().Equal(int32(2), resp.Pagination.PageSize)
+			for _, entry := range resp.Entries {
+				entryIDs = append(entryIDs, entry.EntryId)
+			}
+			if resp.Pagination.Token == "" {
+				break
+			}
+			pagination = &datastore.Pagination{Token: resp.Pagination.Token, PageSize: 10000}
+		}
+		s.Require().Equal(expectedEntryIDs, entryIDs)
+	})
+
+	s.Run("attested nodes", func() {
+		pagination := &datastore.Pagination{PageSize: 10000}
+		var nodeIDs []string
+		for {
+			resp, err := s.ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{
+				Pagination: pagination,
+			})
+			s.Require().NoError(err)
+			s.Require().LessOrEqual(len(resp.Nodes), 2)
+			s.Require().Equal(int32(2), resp.Pagination.PageSize)
+			for _, node := range resp.Nodes {
+				nodeIDs = append(nodeIDs, node.SpiffeId)
+			}
+			if resp.Pagination.Token == "" {
+				break
+			}
+			pagination = &datastore.Pagination{Token: resp.Pagination.Token, PageSize: 10000}
+		}
+		s.Require().Equal(expectedNodeIDs, nodeIDs)
+	})
+
+	s.Run("request is not modified", func() {
+		req := &datastore.ListRegistrationEntriesRequest{
+			Pagination: &datastore.Pagination{PageSize: 10000},
+		}
+		_, err := s.ds.ListRegistrationEntries(ctx, req)
+		s.Require().NoError(err)
+		s.Require().Equal(int32(10000), req.Pagination.PageSize)
+	})
+}
+
+func (s *PluginSuite) TestListRegistrationEntriesOrderedByExpiry() {
+	entryIDs := make(map[string]string)
+	for i, expiry := range []int64{300, 0, 100, 200, 0, 100, 300} {
+		name := fmt.Sprintf("entry-%d", i)
+		selectorValue := "odd"
+		if i%2 == 0 {
+			selectorValue = "even"
+		}
+		entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			Selectors:   []*common.Selector{{Type: "TYPE", Value: selectorValue}},
+			SpiffeId:    makeID(name),
+			ParentId:    makeID("parent"),
+			EntryExpiry: expiry,
+		})
+		s.Require().NoError(err)
+		entryIDs[entry.EntryId] = name
+	}
+
+	listAll := func(req *datastore.ListRegistrationEntriesRequest) []string {
+		var names []string
+		for {
+			resp, err := s.ds.ListRegistrationEntries(ctx, req)
+			s.Require().NoError(err)
+			for _, entry := range resp.Entries {
+				names = append(names, entryIDs[entry.EntryId])
+			}
+			if resp.Pagination == nil || resp.Pagination.Token == "" {
+				return names
+			}
+			req.Pagination = resp.Pagination
+		}
+	}
+
+	for _, tt := range []struct {
+		name        string
+		orderBy     datastore.EntryOrder
+		bySelectors *datastore.BySelectors
+		expected    []string
+	}{
 		{
-			test:                  "by SPIFFE ID and federatesWith superset",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("bar"),
-			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated1.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCD12, bazbarAB1, bazbarAD12, bazbarCD12},
-			expectPagedTokensIn:   []string{"", "1", "2", "4", "6", "7", "9"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAB1}, {foobarAD12}, {foobarCD12}, {bazbarAB1}, {bazbarAD12}, {bazbarCD12}, {}},
+			name:     "expiry ascending",
+			orderBy:  datastore.OrderByExpiryAsc,
+			expected: []string{"entry-2", "entry-5", "entry-3", "entry-0", "entry-6", "entry-1", "entry-4"},
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith many superset",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("bar"),
-			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated1.test", "spiffe://federated2.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAD12, foobarCD12, bazbarAD12, bazbarCD12},
-			expectPagedTokensIn:   []string{"", "2", "4", "7", "9"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAD12}, {foobarCD12}, {bazbarAD12}, {bazbarCD12}, {}},
+			name:     "expiry descending",
+			orderBy:  datastore.OrderByExpiryDesc,
+			expected: []string{"entry-0", "entry-6", "entry-3", "entry-2", "entry-5", "entry-1", "entry-4"},
 		},
 		{
-			test:                  "by SPIFFE ID and federatesWith superset no results",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12, foobuzAD1, bazbuzAB12},
-			bySpiffeID:            makeID("buz"),
-			byFederatesWith:       byFederatesWith(datastore.Superset, "spiffe://federated2.test", "spiffe://federated3.test"),
-			expectEntriesOut:      []*common.RegistrationEntry{},
-			expectPagedTokensIn:   []string{""},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{}},
+			name:    "expiry ascending with filter",
+			orderBy: datastore.OrderByExpiryAsc,
+			bySelectors: &datastore.BySelectors{
+				Selectors: []*common.Selector{{Type: "TYPE", Value: "even"}},
+				Match:     datastore.Exact,
+			},
+			expected: []string{"entry-2", "entry-0", "entry-6", "entry-4"},
 		},
-		// Make sure ByFedaratesWith and BySelectors can be used together
 		{
-			test:                  "by Parent ID, federatesWith and selectors",
-			entries:               []*common.RegistrationEntry{foobarAB1, foobarAD12, foobarCB2, foobarCD12, zizzazX, bazbarAB1, bazbarAD12, bazbarCB2, bazbarCD12},
-			byParentID:            makeID("foo"),
-			byFederatesWith:       byFederatesWith(datastore.Subset, "spiffe://federated1.test", "spiffe://federated2.test"),
-			bySelectors:           bySelectors(datastore.Subset, "A", "D"),
-			expectEntriesOut:      []*common.RegistrationEntry{foobarAD12},
-			expectPagedTokensIn:   []string{"", "2"},
-			expectPagedEntriesOut: [][]*common.RegistrationEntry{{foobarAD12}, {}},
+			name:    "expiry descending with filter",
+			orderBy: datastore.OrderByExpiryDesc,
+			bySelectors: &datastore.BySelectors{
+				Selectors: []*common.Selector{{Type: "TYPE", Value: "odd"}},
+				Match:     datastore.Exact,
+			},
+			expected: []string{"entry-3", "entry-5", "entry-1"},
 		},
 	} {
-		for _, withPagination := range []bool{true, false} {
-			name := tt.test
-			if withPagination {
-				name += " with pagination"
-			} else {
-				name += " without pagination"
-			}
-			if dataConsistency == datastore.TolerateStale {
-				name += " read-only"
+		s.Run(tt.name, func() {
+			s.Require().Equal(tt.expected, listAll(&datastore.ListRegistrationEntriesRequest{
+				BySelectors: tt.bySelectors,
+				OrderBy:     tt.orderBy,
+			}))
+
+			for _, pageSize := range []int32{1, 2, 3} {
+				s.Require().Equal(tt.expected, listAll(&datastore.ListRegistrationEntriesRequest{
+					BySelectors: tt.bySelectors,
+					OrderBy:     tt.orderBy,
+					Pagination:  &datastore.Pagination{PageSize: pageSize},
+				}), "page size %d", pageSize)
 			}
-			s.T().Run(name, func(t *testing.T) {
-				s.ds = s.newPlugin()
-				defer s.ds.Close()
+		})
+	}
 
-				s.createBundle("spiffe://federated1.test")
-				s.createBundle("spiffe://federated2.test")
-				s.createBundle("spiffe://federated3.test")
+	s.Run("unsupported order", func() {
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			OrderBy: "spiffe_id",
+		})
+		s.RequireGRPCStatus(err, codes.InvalidArgument, `unsupported order "spiffe_id"`)
+		s.Require().Nil(resp)
+	})
 
-				// Create entries for the test. For convenience, map the actual
-				// entry ID to the "test" entry ID, so we can easily pinpoint
-				// which entries were unexpectedly missing or included in the
-				// listing.
-				entryIDMap := map[string]string{}
-				for _, entryIn := range tt.entries {
-					entryOut := s.createRegistrationEntry(entryIn)
-					entryIDMap[entryOut.EntryId] = entryIn.EntryId
-				}
+	s.Run("token issued for another order", func() {
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			OrderBy: datastore.OrderByExpiryAsc,
+			Pagination: &datastore.Pagination{
+				Token:    "1:7",
+				PageSize: 2,
+			},
+		})
+		s.RequireGRPCStatus(err, codes.InvalidArgument, "token '1:7' was not issued for the requested order")
+		s.Require().Nil(resp)
 
-				// Optionally sleep to give time for the entries to propagate to
-				// the replicas.
-				if dataConsistency == datastore.TolerateStale && s.readOnlyDelay > 0 {
-					time.Sleep(s.readOnlyDelay)
-				}
+		resp, err = s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			Pagination: &datastore.Pagination{
+				Token:    "1:7:300",
+				PageSize: 2,
+			},
+		})
+		s.RequireGRPCStatus(err, codes.InvalidArgument, "token '1:7:300' was not issued for the requested order")
+		s.Require().Nil(resp)
+	})
+}
 
-				var pagination *datastore.Pagination
-				if withPagination {
-					pagination = &datastore.Pagination{
-						PageSize: tt.pageSize,
-					}
-					if pagination.PageSize == 0 {
-						pagination.PageSize = 1
-					}
-				}
+func (s *PluginSuite) TestExplainListRegistrationEntries() {
+	req := &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+			Match:     datastore.Subset,
+		},
+	}
 
-				var tokensIn []string
-				actualEntriesOut := make(map[string]*common.RegistrationEntry)
-				expectedEntriesOut := make(map[string]*common.RegistrationEntry)
-				req := &datastore.ListRegistrationEntriesRequest{
-					Pagination:      pagination,
-					ByParentID:      tt.byParentID,
-					BySpiffeID:      tt.bySpiffeID,
-					BySelectors:     tt.bySelectors,
-					ByFederatesWith: tt.byFederatesWith,
-					ByHint:          tt.byHint,
-				}
+	_, err := s.ds.ExplainListRegistrationEntries(ctx, req)
+	s.RequireGRPCStatus(err, codes.FailedPrecondition, "datastore-sql: explaining queries is disabled")
 
-				for i := 0; ; i++ {
-					// Don't loop forever if there is a bug
-					if i > len(tt.entries) {
-						require.FailNowf(t, "Exhausted paging limit in test", "tokens=%q spiffeids=%q", tokensIn, actualEntriesOut)
-					}
-					if req.Pagination != nil {
-						tokensIn = append(tokensIn, req.Pagination.Token)
-					}
-					resp, err := s.ds.ListRegistrationEntries(ctx, req)
-					require.NoError(t, err)
-					require.NotNil(t, resp)
-					if withPagination {
-						require.NotNil(t, resp.Pagination, "response missing pagination")
-						assert.Equal(t, req.Pagination.PageSize, resp.Pagination.PageSize, "response page size did not match request")
-					} else {
-						assert.Nil(t, resp.Pagination, "response has pagination")
-					}
+	s.ds.explainQueries = true
 
-					for _, entry := range resp.Entries {
-						entryID, ok := entryIDMap[entry.EntryId]
-						require.True(t, ok, "entry with id %q was not created by this test", entry.EntryId)
-						entry.EntryId = entryID
-						actualEntriesOut[entryID] = entry
-					}
+	s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		SpiffeId:  "spiffe://example.org/workload",
+		ParentId:  "spiffe://example.org/agent",
+	})
 
-					if resp.Pagination == nil || resp.Pagination.Token == "" {
-						break
-					}
-					req.Pagination = resp.Pagination
-				}
+	plan, err := s.ds.ExplainListRegistrationEntries(ctx, req)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(plan)
+	if TestDialect == "" {
+		// Plans of the other databases depend on the table statistics
+		s.Require().Contains(plan, "idx_selectors_type_value_entry")
+	}
 
-				expectEntriesOut := tt.expectPagedEntriesOut
-				if !withPagination {
-					expectEntriesOut = [][]*common.RegistrationEntry{tt.expectEntriesOut}
-				}
+	// The query of a paginated listing is explained
+	req.Pagination = &datastore.Pagination{PageSize: 10}
+	plan, err = s.ds.ExplainListRegistrationEntries(ctx, req)
+	s.Require().NoError(err)
+	s.Require().NotEmpty(plan)
 
-				for _, entrySet := range expectEntriesOut {
-					for _, entry := range entrySet {
-						expectedEntriesOut[entry.EntryId] = entry
-					}
-				}
+	// The request is validated like when listing
+	_, err = s.ds.ExplainListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{},
+	})
+	s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot list by empty selector set")
+}
 
-				if withPagination {
-					assert.Equal(t, tt.expectPagedTokensIn, tokensIn, "unexpected request tokens")
-				} else {
-					assert.Empty(t, tokensIn, "unexpected request tokens")
-				}
+func (s *PluginSuite) TestExplainListRegistrationEntriesByMultipleSelectors() {
+	s.ds.explainQueries = true
+
+	// Seed entries sharing some of their selectors, so that matching
+	// several selectors has to combine the matches of each of them
+	for i := range 200 {
+		s.createRegistrationEntry(&common.RegistrationEntry{
+			Selectors: []*common.Selector{
+				{Type: "unix", Value: fmt.Sprintf("uid:%d", i)},
+				{Type: "unix", Value: fmt.Sprintf("gid:%d", i%10)},
+				{Type: "k8s", Value: fmt.Sprintf("ns:%d", i%20)},
+			},
+			SpiffeId: fmt.Sprintf("spiffe://example.org/workload-%d", i),
+			ParentId: "spiffe://example.org/agent",
+		})
+	}
 
-				assert.Len(t, actualEntriesOut, len(expectedEntriesOut), "unexpected number of entries returned")
-				for id, expectedEntry := range expectedEntriesOut {
-					if _, ok := actualEntriesOut[id]; !ok {
-						t.Errorf("Expected entry %q not found", id)
-						continue
-					}
-					// Some databases are not returning federated IDs in the same order (e.g. mysql)
-					sort.Strings(actualEntriesOut[id].FederatesWith)
-					s.assertCreatedAtField(actualEntriesOut[id], expectedEntry.CreatedAt)
-					spiretest.AssertProtoEqual(t, expectedEntry, actualEntriesOut[id])
-				}
-			})
+	selectors := []*common.Selector{
+		{Type: "unix", Value: "gid:3"},
+		{Type: "k8s", Value: "ns:3"},
+	}
+	for _, tt := range []struct {
+		name          string
+		match         datastore.MatchBehavior
+		expectedCount int
+	}{
+		// Every entry has a third selector
+		{name: "exact", match: datastore.Exact, expectedCount: 0},
+		{name: "subset", match: datastore.Subset, expectedCount: 0},
+		// Entries with i%20 == 3
+		{name: "superset", match: datastore.Superset, expectedCount: 10},
+		// Entries with i%10 == 3
+		{name: "match any", match: datastore.MatchAny, expectedCount: 20},
+	} {
+		s.Run(tt.name, func() {
+			req := &datastore.ListRegistrationEntriesRequest{
+				BySelectors: &datastore.BySelectors{
+					Selectors: selectors,
+					Match:     tt.match,
+				},
+				Pagination: &datastore.Pagination{PageSize: 10},
+			}
+			plan, err := s.ds.ExplainListRegistrationEntries(ctx, req)
+			s.Require().NoError(err)
+			if TestDialect == "" {
+				// Plans of the other databases depend on the table statistics
+				s.Require().Contains(plan, "USING COVERING INDEX idx_selectors_type_value_entry")
+			}
+
+			req.Pagination = nil
+			resp, err := s.ds.ListRegistrationEntries(ctx, req)
+			s.Require().NoError(err)
+			s.Require().Len(resp.Entries, tt.expectedCount)
+		})
+	}
+
+	// Selectors repeated in the request are only required once
+	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{
+			Selectors: append(selectors, selectors...),
+			Match:     datastore.Superset,
+		},
+	})
+	s.Require().NoError(err)
+	s.Require().Len(resp.Entries, 10)
+}
+
+func (s *PluginSuite) TestListRegistrationEntriesByRevision() {
+	var entries []*common.RegistrationEntry
+	for i := range 5 {
+		entries = append(entries, s.createRegistrationEntry(&common.RegistrationEntry{
+			Selectors: []*common.Selector{{Type: "TYPE", Value: "VALUE"}},
+			SpiffeId:  makeID(fmt.Sprintf("entry-%d", i)),
+			ParentId:  makeID("parent"),
+		}))
+	}
+
+	// Update the entries a different number of times, so that entry-2 ends
+	// up with revision 3, entry-0 with revision 2, entry-4 with revision 1
+	// and the others with revision 0.
+	for _, i := range []int{2, 0, 2, 4, 2, 0} {
+		entries[i].X509SvidTtl++
+		updated, err := s.ds.UpdateRegistrationEntry(ctx, entries[i], nil)
+		s.Require().NoError(err)
+		s.Require().Equal(entries[i].RevisionNumber+1, updated.RevisionNumber)
+		entries[i] = updated
+	}
+	for i, revision := range []int64{2, 0, 3, 0, 1} {
+		fetched, err := s.ds.FetchRegistrationEntry(ctx, entries[i].EntryId)
+		s.Require().NoError(err)
+		s.Require().Equal(revision, fetched.RevisionNumber)
+	}
+
+	names := make(map[string]string)
+	for i, entry := range entries {
+		names[entry.EntryId] = fmt.Sprintf("entry-%d", i)
+	}
+	listAll := func(req *datastore.ListRegistrationEntriesRequest) []string {
+		var listed []string
+		for {
+			resp, err := s.ds.ListRegistrationEntries(ctx, req)
+			s.Require().NoError(err)
+			for _, entry := range resp.Entries {
+				listed = append(listed, names[entry.EntryId])
+			}
+			if resp.Pagination == nil || resp.Pagination.Token == "" {
+				return listed
+			}
+			req.Pagination = resp.Pagination
 		}
 	}
+
+	for _, tt := range []struct {
+		name     string
+		revision int64
+		orderBy  datastore.EntryOrder
+		expected []string
+	}{
+		{
+			name:     "no revision",
+			expected: []string{"entry-0", "entry-1", "entry-2", "entry-3", "entry-4"},
+		},
+		{
+			name:     "revision greater than 1",
+			revision: 1,
+			expected: []string{"entry-0", "entry-2"},
+		},
+		{
+			name:     "revision greater than 3",
+			revision: 3,
+		},
+		{
+			name:     "ordered by revision",
+			orderBy:  datastore.OrderByRevisionAsc,
+			expected: []string{"entry-1", "entry-3", "entry-4", "entry-0", "entry-2"},
+		},
+		{
+			name:     "revision greater than 1 ordered by revision",
+			revision: 1,
+			orderBy:  datastore.OrderByRevisionAsc,
+			expected: []string{"entry-0", "entry-2"},
+		},
+	} {
+		s.Run(tt.name, func() {
+			s.Require().Equal(tt.expected, listAll(&datastore.ListRegistrationEntriesRequest{
+				ByRevisionGreaterThan: tt.revision,
+				OrderBy:               tt.orderBy,
+			}))
+
+			for _, pageSize := range []int32{1, 2, 3} {
+				s.Require().Equal(tt.expected, listAll(&datastore.ListRegistrationEntriesRequest{
+					ByRevisionGreaterThan: tt.revision,
+					OrderBy:               tt.orderBy,
+					Pagination:            &datastore.Pagination{PageSize: pageSize},
+				}), "page size %d", pageSize)
+			}
+		})
+	}
+
+	s.Run("negative revision", func() {
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			ByRevisionGreaterThan: -1,
+		})
+		s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot list by negative revision number")
+		s.Require().Nil(resp)
+	})
 }
 
-func (s *PluginSuite) TestListRegistrationEntriesWhenCruftRowsExist() {
-	_, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
-		Selectors: []*common.Selector{
-			{Type: "TYPE", Value: "VALUE"},
-		},
-		SpiffeId: "SpiffeId",
-		ParentId: "ParentId",
-		DnsNames: []string{
-			"abcd.efg",
-			"somehost",
-		},
+func (s *PluginSuite) TestStreamRegistrationEntries() {
+	// Enough entries to span several pages
+	const numEntries = 2*streamEntriesPageSize + 7
+
+	expected := make(map[string]*common.RegistrationEntry, numEntries)
+	for i := range numEntries {
+		entry := s.createRegistrationEntry(&common.RegistrationEntry{
+			SpiffeId: fmt.Sprintf("spiffe://example.org/workload-%d", i),
+			ParentId: "spiffe://example.org/parent",
+			Selectors: []*common.Selector{
+				{Type: "unix", Value: "gid:1000"},
+				{Type: "unix", Value: fmt.Sprintf("uid:%d", i)},
+			},
+			DnsNames: []string{fmt.Sprintf("workload-%d.example.org", i)},
+		})
+		expected[entry.EntryId] = entry
+	}
+
+	seen := make(map[string]bool, numEntries)
+	err := s.ds.StreamRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{}, func(entry *common.RegistrationEntry) error {
+		s.Require().False(seen[entry.EntryId], "entry %q streamed more than once", entry.EntryId)
+		seen[entry.EntryId] = true
+		s.AssertProtoEqual(expected[entry.EntryId], entry)
+		return nil
 	})
 	s.Require().NoError(err)
+	s.Require().Len(seen, numEntries)
 
-	// This is gross. Since the bug that left selectors around has been fixed
-	// (#1191), I'm not sure how else to test this other than just sneaking in
-	// there and removing the registered_entries row.
-	res, err := s.ds.db.raw.Exec("DELETE FROM registered_entries")
-	s.Require().NoError(err)
-	rowsAffected, err := res.RowsAffected()
-	s.Require().NoError(err)
-	s.Require().Equal(int64(1), rowsAffected)
+	s.Run("filtered", func() {
+		var streamed []*common.RegistrationEntry
+		err := s.ds.StreamRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			BySelectors: &datastore.BySelectors{
+				Selectors: []*common.Selector{
+					{Type: "unix", Value: "uid:42"},
+					{Type: "unix", Value: "gid:1000"},
+				},
+				Match: datastore.Exact,
+			},
+		}, func(entry *common.RegistrationEntry) error {
+			streamed = append(streamed, entry)
+			return nil
+		})
+		s.Require().NoError(err)
+		s.Require().Len(streamed, 1)
+		s.Require().Equal("spiffe://example.org/workload-42", streamed[0].SpiffeId)
+	})
 
-	// Assert that no rows are returned.
-	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
-	s.Require().NoError(err)
-	s.Require().Empty(resp.Entries)
+	s.Run("callback error stops the stream", func() {
+		calls := 0
+		errStop := errors.New("stop")
+		err := s.ds.StreamRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{}, func(*common.RegistrationEntry) error {
+			calls++
+			if calls == 3 {
+				return errStop
+			}
+			return nil
+		})
+		s.Require().Same(errStop, err)
+		s.Require().Equal(3, calls)
+	})
+
+	s.Run("pagination is not supported", func() {
+		err := s.ds.StreamRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			Pagination: &datastore.Pagination{PageSize: 10},
+		}, func(*common.RegistrationEntry) error { return nil })
+		s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot paginate a stream of registration entries")
+	})
+
+	s.Run("ordering is not supported", func() {
+		err := s.ds.StreamRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			OrderBy: datastore.OrderByExpiryAsc,
+		}, func(*common.RegistrationEntry) error { return nil })
+		s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot order a stream of registration entries")
+	})
+
+	s.Run("invalid filter", func() {
+		err := s.ds.StreamRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			BySelectors: &datastore.BySelectors{},
+		}, func(*common.RegistrationEntry) error { return nil })
+		s.RequireGRPCStatus(err, codes.InvalidArgument, "cannot list by empty selector set")
+	})
 }
 
 func (s *PluginSuite) TestUpdateRegistrationEntry() {
@@ -3031,6 +6737,56 @@ func (s *PluginSuite) TestUpdateRegistrationEntryWithStoreSvid() {
 	s.Require().EqualError(err, "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: selector types must be the same when store SVID is enabled")
 }
 
+func (s *PluginSuite) TestUpdateRegistrationEntryWithMaskPreservesConcurrentChanges() {
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type1", Value: "Value1"},
+			{Type: "Type2", Value: "Value2"},
+		},
+		SpiffeId:    "spiffe://example.org/foo",
+		ParentId:    "spiffe://example.org/bar",
+		X509SvidTtl: 1000,
+		DnsNames:    []string{"dns1"},
+	})
+
+	// Both clients start from the same copy of the entry
+	stale := proto.Clone(entry).(*common.RegistrationEntry)
+
+	// The first client replaces the selectors and DNS names
+	entry.Selectors = []*common.Selector{{Type: "Type3", Value: "Value3"}}
+	entry.DnsNames = []string{"dns2", "dns3"}
+	updated, err := s.ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{Selectors: true, DnsNames: true})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), updated.RevisionNumber)
+
+	// The second client only changes the TTL, from its stale copy
+	stale.X509SvidTtl = 2000
+	updated, err = s.ds.UpdateRegistrationEntry(ctx, stale, &common.RegistrationEntryMask{X509SvidTtl: true})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(2), updated.RevisionNumber)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Require().Equal(int32(2000), fetched.X509SvidTtl)
+	s.Require().Equal(int64(2), fetched.RevisionNumber)
+	spiretest.AssertProtoListEqual(s.T(), []*common.Selector{{Type: "Type3", Value: "Value3"}}, fetched.Selectors)
+	s.Require().Equal([]string{"dns2", "dns3"}, fetched.DnsNames)
+
+	// Enabling store SVID is validated against the stored selectors
+	mixed := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{
+			{Type: "Type1", Value: "Value1"},
+			{Type: "Type2", Value: "Value2"},
+		},
+		SpiffeId:    "spiffe://example.org/mixed",
+		ParentId:    "spiffe://example.org/bar",
+		X509SvidTtl: 1000,
+	})
+	mixed.StoreSvid = true
+	_, err = s.ds.UpdateRegistrationEntry(ctx, mixed, &common.RegistrationEntryMask{StoreSvid: true})
+	s.Require().EqualError(err, "rpc error: code = InvalidArgument desc = datastore-validation: invalid registration entry: selector types must be the same when store SVID is enabled")
+}
+
 func (s *PluginSuite) TestUpdateRegistrationEntryWithMask() {
 	// There are 11 fields in a registration entry. Of these, 5 have some validation in the SQL
 	// layer. In this test, we update each of the 11 fields and make sure update works, and also check
@@ -3353,6 +7109,105 @@ func (s *PluginSuite) TestUpdateRegistrationEntryWithMask() {
 	}
 }
 
+func (s *PluginSuite) TestSetRegistrationEntryActive() {
+	// update non-existing
+	_, err := s.ds.SetRegistrationEntryActive(ctx, "badid", false)
+	s.RequireGRPCStatus(err, codes.NotFound, _notFoundErrMsg)
+
+	entry1 := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:  "spiffe://example.org/foo",
+		ParentId:  "spiffe://example.org/bar",
+	})
+	entry2 := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type2", Value: "Value2"}},
+		SpiffeId:  "spiffe://example.org/baz",
+		ParentId:  "spiffe://example.org/bar",
+	})
+
+	// Entries are active when created
+	s.Require().False(entry1.Inactive)
+	s.Require().False(entry2.Inactive)
+
+	eventsResp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	s.Require().NoError(err)
+	lastEventID := eventsResp.Events[len(eventsResp.Events)-1].EventID
+
+	listEntryIDs := func(byActive *bool) []string {
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			ByActive: byActive,
+		})
+		s.Require().NoError(err)
+		var entryIDs []string
+		for _, entry := range resp.Entries {
+			entryIDs = append(entryIDs, entry.EntryId)
+		}
+		return entryIDs
+	}
+	active, inactive := true, false
+
+	deactivated, err := s.ds.SetRegistrationEntryActive(ctx, entry1.EntryId, false)
+	s.Require().NoError(err)
+	s.Require().True(deactivated.Inactive)
+	s.Require().Equal(entry1.Selectors, deactivated.Selectors)
+	s.Require().Equal(entry1.RevisionNumber+1, deactivated.RevisionNumber)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry1.EntryId)
+	s.Require().NoError(err)
+	s.Require().True(fetched.Inactive)
+
+	s.Require().ElementsMatch([]string{entry1.EntryId, entry2.EntryId}, listEntryIDs(nil))
+	s.Require().Equal([]string{entry2.EntryId}, listEntryIDs(&active))
+	s.Require().Equal([]string{entry1.EntryId}, listEntryIDs(&inactive))
+
+	// An event is created so caches drop the entry
+	eventsResp, err = s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{
+		GreaterThanEventID: lastEventID,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(eventsResp.Events, 1)
+	s.Require().Equal(entry1.EntryId, eventsResp.Events[0].EntryID)
+	lastEventID = eventsResp.Events[0].EventID
+
+	// Deactivating again is a no-op
+	_, err = s.ds.SetRegistrationEntryActive(ctx, entry1.EntryId, false)
+	s.Require().NoError(err)
+	eventsResp, err = s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{
+		GreaterThanEventID: lastEventID,
+	})
+	s.Require().NoError(err)
+	s.Require().Empty(eventsResp.Events)
+
+	// The entry can be activated again
+	activated, err := s.ds.SetRegistrationEntryActive(ctx, entry1.EntryId, true)
+	s.Require().NoError(err)
+	s.Require().False(activated.Inactive)
+	s.Require().Equal(entry1.RevisionNumber+2, activated.RevisionNumber)
+	s.Require().ElementsMatch([]string{entry1.EntryId, entry2.EntryId}, listEntryIDs(&active))
+	s.Require().Empty(listEntryIDs(&inactive))
+}
+
+func (s *PluginSuite) TestCreateInactiveRegistrationEntry() {
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:  "spiffe://example.org/foo",
+		ParentId:  "spiffe://example.org/bar",
+		Inactive:  true,
+	})
+	s.Require().True(entry.Inactive)
+
+	fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Require().True(fetched.Inactive)
+
+	active := true
+	resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		ByActive: &active,
+	})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.Entries)
+}
+
 func (s *PluginSuite) TestDeleteRegistrationEntry() {
 	// delete non-existing
 	_, err := s.ds.DeleteRegistrationEntry(ctx, "badid")
@@ -3608,6 +7463,17 @@ func (s *PluginSuite) TestListSelectorEntriesSuperset() {
 	}
 }
 
+func (s *PluginSuite) TestListEntriesBySelectorMatchAllIsUnsupported() {
+	// MatchAll only applies to attested nodes
+	_, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		BySelectors: &datastore.BySelectors{
+			Selectors: []*common.Selector{{Type: "a", Value: "1"}},
+			Match:     datastore.MatchAll,
+		},
+	})
+	s.Require().ErrorContains(err, "unhandled selectors match behavior")
+}
+
 func (s *PluginSuite) TestListEntriesBySelectorMatchAny() {
 	now := time.Now().Unix()
 	allEntries := make([]*common.RegistrationEntry, 0)
@@ -3890,6 +7756,84 @@ func (s *PluginSuite) TestListEntriesByFederatesWithMatchAny() {
 	}
 }
 
+func (s *PluginSuite) TestListRegistrationEntriesByFederatesWith() {
+	allEntries := make([]*common.RegistrationEntry, 0)
+	s.getTestDataFromJSONFile(filepath.Join("testdata", "entries_federates_with.json"), &allEntries)
+	createBundles(s.T(), s.ds, []string{
+		"spiffe://td1.org",
+		"spiffe://td2.org",
+		"spiffe://td3.org",
+		"spiffe://td4.org",
+		"spiffe://td5.org",
+	})
+	for i, entry := range allEntries {
+		allEntries[i] = s.createRegistrationEntry(entry)
+	}
+
+	for _, tt := range []struct {
+		name        string
+		trustDomain string
+		expected    []*common.RegistrationEntry
+		expectErr   string
+	}{
+		{
+			name:        "trust domain name",
+			trustDomain: "td3.org",
+			expected:    []*common.RegistrationEntry{allEntries[0], allEntries[2], allEntries[3]},
+		},
+		{
+			name:        "trust domain ID",
+			trustDomain: "spiffe://td4.org",
+			expected:    []*common.RegistrationEntry{allEntries[3], allEntries[4]},
+		},
+		{
+			name:        "federated with every entry",
+			trustDomain: "td2.org",
+			expected:    allEntries,
+		},
+		{
+			name:        "no entries",
+			trustDomain: "td5.org",
+		},
+		{
+			name:        "invalid trust domain",
+			trustDomain: "TD1.org/path",
+			expectErr:   `datastore-validation: invalid trust domain "TD1.org/path"`,
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			resp, err := s.ds.ListRegistrationEntriesByFederatesWith(ctx, tt.trustDomain, nil)
+			if tt.expectErr != "" {
+				require.ErrorContains(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+			expected := slices.Clone(tt.expected)
+			util.SortRegistrationEntries(expected)
+			util.SortRegistrationEntries(resp.Entries)
+			spiretest.RequireProtoListEqual(t, expected, resp.Entries)
+		})
+	}
+
+	// Page through the entries federated with td1.org
+	pagination := &datastore.Pagination{PageSize: 2}
+	var paged []*common.RegistrationEntry
+	for {
+		resp, err := s.ds.ListRegistrationEntriesByFederatesWith(ctx, "td1.org", pagination)
+		s.Require().NoError(err)
+		s.Require().LessOrEqual(len(resp.Entries), 2)
+		paged = append(paged, resp.Entries...)
+		if len(resp.Entries) == 0 {
+			break
+		}
+		pagination = resp.Pagination
+	}
+	expected := []*common.RegistrationEntry{allEntries[0], allEntries[1], allEntries[3], allEntries[4]}
+	util.SortRegistrationEntries(expected)
+	util.SortRegistrationEntries(paged)
+	spiretest.RequireProtoListEqual(s.T(), expected, paged)
+}
+
 func (s *PluginSuite) TestListEntriesByFederatesWithSuperset() {
 	now := time.Now().Unix()
 	allEntries := make([]*common.RegistrationEntry, 0)
@@ -4015,6 +7959,88 @@ func (s *PluginSuite) TestDeleteBundleDeleteRegistrationEntries() {
 	s.fetchRegistrationEntry(unrelated.EntryId)
 }
 
+func (s *PluginSuite) TestFederatesWithAllRegistrationEntry() {
+	s.createBundle("spiffe://example.org")
+	s.createBundle("spiffe://otherdomain.org")
+
+	entry := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors:        []*common.Selector{{Type: "Type1", Value: "Value1"}},
+		SpiffeId:         "spiffe://example.org/foo",
+		ParentId:         "spiffe://example.org/bar",
+		FederatesWithAll: true,
+	})
+	other := s.createRegistrationEntry(&common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "Type2", Value: "Value2"}},
+		SpiffeId:  "spiffe://example.org/baz",
+		ParentId:  "spiffe://example.org/bar",
+	})
+
+	// The entry federates with every trust domain but its own
+	s.Require().True(entry.FederatesWithAll)
+	s.Require().Equal([]string{"spiffe://otherdomain.org"}, entry.FederatesWith)
+
+	requireFederatesWith := func(expected []string) {
+		fetched := s.fetchRegistrationEntry(entry.EntryId)
+		s.Require().Equal(expected, fetched.FederatesWith)
+
+		resp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+		s.Require().NoError(err)
+		s.Require().Len(resp.Entries, 2)
+		for _, listed := range resp.Entries {
+			switch listed.EntryId {
+			case entry.EntryId:
+				s.Require().Equal(expected, listed.FederatesWith)
+			case other.EntryId:
+				s.Require().Empty(listed.FederatesWith)
+			}
+		}
+	}
+
+	eventsResp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	s.Require().NoError(err)
+	lastEventID := eventsResp.Events[len(eventsResp.Events)-1].EventID
+	requireEntryEvent := func() {
+		eventsResp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{
+			GreaterThanEventID: lastEventID,
+		})
+		s.Require().NoError(err)
+		s.Require().Len(eventsResp.Events, 1)
+		s.Require().Equal(entry.EntryId, eventsResp.Events[0].EntryID)
+		lastEventID = eventsResp.Events[0].EventID
+	}
+
+	// A newly added federated trust domain is picked up without updating
+	// the entry, and an event is created so caches refresh the entry
+	s.createBundle("spiffe://thirddomain.org")
+	requireFederatesWith([]string{"spiffe://otherdomain.org", "spiffe://thirddomain.org"})
+	requireEntryEvent()
+
+	// Same when a federated trust domain goes away
+	err = s.ds.DeleteBundle(ctx, "spiffe://otherdomain.org", datastore.Restrict)
+	s.Require().NoError(err)
+	requireFederatesWith([]string{"spiffe://thirddomain.org"})
+	requireEntryEvent()
+
+	// Clearing the flag leaves the entry without federated trust domains,
+	// since the resolved ones were never stored
+	updated, err := s.ds.UpdateRegistrationEntry(ctx, &common.RegistrationEntry{
+		EntryId: entry.EntryId,
+	}, &common.RegistrationEntryMask{FederatesWithAll: true})
+	s.Require().NoError(err)
+	s.Require().False(updated.FederatesWithAll)
+	s.Require().Empty(updated.FederatesWith)
+	requireFederatesWith(nil)
+
+	// The flag can be set on update
+	updated, err = s.ds.UpdateRegistrationEntry(ctx, &common.RegistrationEntry{
+		EntryId:          other.EntryId,
+		FederatesWithAll: true,
+	}, &common.RegistrationEntryMask{FederatesWithAll: true})
+	s.Require().NoError(err)
+	s.Require().True(updated.FederatesWithAll)
+	s.Require().Equal([]string{"spiffe://thirddomain.org"}, updated.FederatesWith)
+}
+
 func (s *PluginSuite) TestDeleteBundleDissociateRegistrationEntries() {
 	// create the bundle and associated entry
 	s.createBundle("spiffe://otherdomain.org")
@@ -4157,6 +8183,134 @@ func (s *PluginSuite) TestListRegistrationEntryEvents() {
 	}
 }
 
+func (s *PluginSuite) TestListRegistrationEntryEventsCursor() {
+	eventIDs := func(events []datastore.RegistrationEntryEvent) []uint {
+		ids := []uint{}
+		for _, event := range events {
+			ids = append(ids, event.EventID)
+		}
+		return ids
+	}
+
+	// No events yet
+	resp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.Events)
+	s.Require().Zero(resp.FirstEventID)
+	s.Require().Zero(resp.LastEventID)
+
+	for i := range 4 {
+		s.createRegistrationEntry(&common.RegistrationEntry{
+			Selectors: []*common.Selector{{Type: "Type1", Value: fmt.Sprintf("Value%d", i)}},
+			SpiffeId:  fmt.Sprintf("spiffe://example.org/workload%d", i),
+			ParentId:  "spiffe://example.org/agent",
+		})
+	}
+
+	// Events past the high-water mark, along with the stored range
+	resp, err = s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{GreaterThanEventID: 2})
+	s.Require().NoError(err)
+	s.Require().Equal([]uint{3, 4}, eventIDs(resp.Events))
+	s.Require().Equal(uint(1), resp.FirstEventID)
+	s.Require().Equal(uint(4), resp.LastEventID)
+
+	// Nothing past the last event
+	resp, err = s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{GreaterThanEventID: 4})
+	s.Require().NoError(err)
+	s.Require().Empty(resp.Events)
+	s.Require().Equal(uint(1), resp.FirstEventID)
+	s.Require().Equal(uint(4), resp.LastEventID)
+
+	// Prune the first two events
+	s.Require().NoError(s.ds.db.Model(&RegisteredEntryEvent{}).Where("id <= ?", 2).Update("created_at", time.Now().Add(-time.Hour)).Error)
+	s.Require().NoError(s.ds.PruneRegistrationEntryEvents(ctx, time.Minute))
+
+	// A reader that last saw event 1 detects that event 2 was pruned
+	resp, err = s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{GreaterThanEventID: 1})
+	s.Require().NoError(err)
+	s.Require().Equal([]uint{3, 4}, eventIDs(resp.Events))
+	s.Require().Equal(uint(3), resp.FirstEventID)
+	s.Require().Equal(uint(4), resp.LastEventID)
+	s.Require().Less(uint(1), resp.FirstEventID-1)
+}
+
+func (s *PluginSuite) TestListRegistrationEntryEventsHistoryUnavailable() {
+	list := func(lastSeenEventID uint) error {
+		_, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{
+			GreaterThanEventID:     lastSeenEventID,
+			RequireCompleteHistory: true,
+		})
+		return err
+	}
+
+	// No entries and no events
+	s.Require().NoError(list(3))
+
+	for i := range 3 {
+		s.createRegistrationEntry(&common.RegistrationEntry{
+			SpiffeId:  fmt.Sprintf("spiffe://example.org/workload%d", i),
+			ParentId:  "spiffe://example.org/parent",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		})
+	}
+
+	// The history is complete
+	s.Require().NoError(list(1))
+	s.Require().NoError(list(3))
+
+	// The events following the last seen one were pruned
+	s.Require().NoError(s.ds.db.Where("id <= ?", 2).Delete(&RegisteredEntryEvent{}).Error)
+	s.Require().NoError(list(2))
+	s.Require().ErrorIs(list(1), datastore.ErrEventHistoryUnavailable)
+
+	// The events table was truncated while registration entries remain
+	s.Require().NoError(s.ds.db.Where("1 = 1").Delete(&RegisteredEntryEvent{}).Error)
+	s.Require().ErrorIs(list(3), datastore.ErrEventHistoryUnavailable)
+
+	// The event IDs were reset
+	s.Require().NoError(s.ds.CreateRegistrationEntryEventForTesting(ctx, &datastore.RegistrationEntryEvent{
+		EventID: 1,
+		EntryID: "entry-1",
+	}))
+	s.Require().ErrorIs(list(3), datastore.ErrEventHistoryUnavailable)
+	s.Require().NoError(list(1))
+}
+
+func (s *PluginSuite) TestCompactRegistrationEntryEvents() {
+	// Nothing to compact
+	compacted, err := s.ds.CompactRegistrationEntryEvents(ctx)
+	s.Require().NoError(err)
+	s.Require().Zero(compacted)
+
+	for i, entryID := range []string{"a", "b", "a", "c", "a", "b"} {
+		s.Require().NoError(s.ds.CreateRegistrationEntryEventForTesting(ctx, &datastore.RegistrationEntryEvent{
+			EventID: uint(i + 1),
+			EntryID: entryID,
+		}))
+	}
+
+	compacted, err = s.ds.CompactRegistrationEntryEvents(ctx)
+	s.Require().NoError(err)
+	s.Require().Equal(3, compacted)
+
+	// Only the latest event of each entry is left, and the highest event ID
+	// is unchanged
+	resp, err := s.ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	s.Require().NoError(err)
+	s.Require().Equal([]datastore.RegistrationEntryEvent{
+		{EventID: 4, EntryID: "c"},
+		{EventID: 5, EntryID: "a"},
+		{EventID: 6, EntryID: "b"},
+	}, resp.Events)
+	s.Require().Equal(uint(4), resp.FirstEventID)
+	s.Require().Equal(uint(6), resp.LastEventID)
+
+	// Compacting again has no effect
+	compacted, err = s.ds.CompactRegistrationEntryEvents(ctx)
+	s.Require().NoError(err)
+	s.Require().Zero(compacted)
+}
+
 func (s *PluginSuite) TestPruneRegistrationEntryEvents() {
 	entry := &common.RegistrationEntry{
 		Selectors: []*common.Selector{
@@ -4215,6 +8369,13 @@ func (s *PluginSuite) TestCreateJoinToken() {
 	// Make sure we can't re-register
 	err = s.ds.CreateJoinToken(ctx, req)
 	s.NotNil(err)
+
+	err = s.ds.CreateJoinToken(ctx, &datastore.JoinToken{
+		Token:         "negative",
+		Expiry:        time.Now().Truncate(time.Second),
+		RemainingUses: -1,
+	})
+	s.EqualError(err, "remaining uses cannot be negative")
 }
 
 func (s *PluginSuite) TestCreateAndFetchJoinToken() {
@@ -4231,6 +8392,7 @@ func (s *PluginSuite) TestCreateAndFetchJoinToken() {
 	s.Require().NoError(err)
 	s.Equal("foobar", res.Token)
 	s.Equal(now, res.Expiry)
+	s.Equal(1, res.RemainingUses)
 }
 
 func (s *PluginSuite) TestDeleteJoinToken() {
@@ -4251,18 +8413,111 @@ func (s *PluginSuite) TestDeleteJoinToken() {
 	err = s.ds.CreateJoinToken(ctx, joinToken2)
 	s.Require().NoError(err)
 
-	err = s.ds.DeleteJoinToken(ctx, joinToken1.Token)
-	s.Require().NoError(err)
+	err = s.ds.DeleteJoinToken(ctx, joinToken1.Token)
+	s.Require().NoError(err)
+
+	// Should not be able to fetch after delete
+	resp, err := s.ds.FetchJoinToken(ctx, joinToken1.Token)
+	s.Require().NoError(err)
+	s.Nil(resp)
+
+	// Second token should still be present
+	resp, err = s.ds.FetchJoinToken(ctx, joinToken2.Token)
+	s.Require().NoError(err)
+	s.Equal(&datastore.JoinToken{Token: "batbaz", Expiry: now, RemainingUses: 1}, resp)
+}
+
+func (s *PluginSuite) TestConsumeJoinToken() {
+	now := time.Now().Truncate(time.Second)
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, &datastore.JoinToken{
+		Token:  "single",
+		Expiry: now,
+	}))
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, &datastore.JoinToken{
+		Token:         "multi",
+		Expiry:        now,
+		RemainingUses: 3,
+	}))
+
+	// A token created without uses can be used once
+	resp, err := s.ds.ConsumeJoinToken(ctx, "single")
+	s.Require().NoError(err)
+	s.Equal(&datastore.JoinToken{Token: "single", Expiry: now, RemainingUses: 0}, resp)
+	resp, err = s.ds.ConsumeJoinToken(ctx, "single")
+	s.Require().NoError(err)
+	s.Nil(resp)
+
+	// Each use of a multi-use token is accounted for
+	for _, remainingUses := range []int{2, 1} {
+		resp, err = s.ds.ConsumeJoinToken(ctx, "multi")
+		s.Require().NoError(err)
+		s.Equal(&datastore.JoinToken{Token: "multi", Expiry: now, RemainingUses: remainingUses}, resp)
+
+		resp, err = s.ds.FetchJoinToken(ctx, "multi")
+		s.Require().NoError(err)
+		s.Equal(remainingUses, resp.RemainingUses)
+	}
+
+	// The token is deleted along with its last use
+	resp, err = s.ds.ConsumeJoinToken(ctx, "multi")
+	s.Require().NoError(err)
+	s.Equal(0, resp.RemainingUses)
+	resp, err = s.ds.FetchJoinToken(ctx, "multi")
+	s.Require().NoError(err)
+	s.Nil(resp)
+	resp, err = s.ds.ConsumeJoinToken(ctx, "multi")
+	s.Require().NoError(err)
+	s.Nil(resp)
+
+	resp, err = s.ds.ConsumeJoinToken(ctx, "inexistent")
+	s.Require().NoError(err)
+	s.Nil(resp)
+}
+
+func (s *PluginSuite) TestConsumeJoinTokenConcurrently() {
+	const uses = 5
+	const consumers = 4 * uses
+	s.Require().NoError(s.ds.CreateJoinToken(ctx, &datastore.JoinToken{
+		Token:         "foobar",
+		Expiry:        time.Now().Truncate(time.Second),
+		RemainingUses: uses,
+	}))
+
+	var wg sync.WaitGroup
+	results := make(chan *datastore.JoinToken, consumers)
+	errs := make(chan error, consumers)
+	for range consumers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := s.ds.ConsumeJoinToken(ctx, "foobar")
+			if err != nil {
+				errs <- err
+				return
+			}
+			results <- resp
+		}()
+	}
+	wg.Wait()
+	close(results)
+	close(errs)
+
+	for err := range errs {
+		s.Require().NoError(err)
+	}
+
+	// Every use was taken exactly once, and the token is gone
+	var remainingUses []int
+	for resp := range results {
+		if resp != nil {
+			remainingUses = append(remainingUses, resp.RemainingUses)
+		}
+	}
+	s.ElementsMatch([]int{4, 3, 2, 1, 0}, remainingUses)
 
-	// Should not be able to fetch after delete
-	resp, err := s.ds.FetchJoinToken(ctx, joinToken1.Token)
+	resp, err := s.ds.FetchJoinToken(ctx, "foobar")
 	s.Require().NoError(err)
 	s.Nil(resp)
-
-	// Second token should still be present
-	resp, err = s.ds.FetchJoinToken(ctx, joinToken2.Token)
-	s.Require().NoError(err)
-	s.Equal(joinToken2, resp)
 }
 
 func (s *PluginSuite) TestPruneJoinTokens() {
@@ -4351,6 +8606,61 @@ func (s *PluginSuite) TestDeleteFederationRelationship() {
 	}
 }
 
+func (s *PluginSuite) TestSetFederationRelationshipLastPoll() {
+	td := spiffeid.RequireTrustDomainFromString("federated-td-web.org")
+	_, err := s.ds.CreateFederationRelationship(ctx, &datastore.FederationRelationship{
+		TrustDomain:           td,
+		BundleEndpointURL:     requireURLFromString(s.T(), "federated-td-web.org/bundleendpoint"),
+		BundleEndpointProfile: datastore.BundleEndpointWeb,
+	})
+	s.Require().NoError(err)
+
+	// Never polled
+	fr, err := s.ds.FetchFederationRelationship(ctx, td)
+	s.Require().NoError(err)
+	s.Require().True(fr.LastPollAt.IsZero())
+	s.Require().Empty(fr.LastPollError)
+	s.Require().True(fr.NextPollAt.IsZero())
+
+	// A failed poll records the error and the next poll
+	failedAt := time.Unix(1000, 0)
+	retryAt := time.Unix(1600, 0)
+	err = s.ds.SetFederationRelationshipLastPoll(ctx, td, failedAt, retryAt, "connection refused")
+	s.Require().NoError(err)
+	fr, err = s.ds.FetchFederationRelationship(ctx, td)
+	s.Require().NoError(err)
+	s.Require().True(failedAt.Equal(fr.LastPollAt))
+	s.Require().Equal("connection refused", fr.LastPollError)
+	s.Require().True(retryAt.Equal(fr.NextPollAt))
+
+	// Long errors are truncated without splitting characters. The next poll
+	// is left as it was when not given.
+	err = s.ds.SetFederationRelationshipLastPoll(ctx, td, failedAt, time.Time{}, "x"+strings.Repeat("é", maxPollErrorLength))
+	s.Require().NoError(err)
+	fr, err = s.ds.FetchFederationRelationship(ctx, td)
+	s.Require().NoError(err)
+	s.Require().Equal("x"+strings.Repeat("é", maxPollErrorLength/2-1), fr.LastPollError)
+	s.Require().True(retryAt.Equal(fr.NextPollAt))
+
+	// A successful poll clears the error
+	succeededAt := time.Unix(2000, 0)
+	nextPollAt := time.Unix(2300, 0)
+	err = s.ds.SetFederationRelationshipLastPoll(ctx, td, succeededAt, nextPollAt, "")
+	s.Require().NoError(err)
+	fr, err = s.ds.FetchFederationRelationship(ctx, td)
+	s.Require().NoError(err)
+	s.Require().True(succeededAt.Equal(fr.LastPollAt))
+	s.Require().Empty(fr.LastPollError)
+	s.Require().True(nextPollAt.Equal(fr.NextPollAt))
+
+	// Trust domains without a federation relationship are ignored
+	err = s.ds.SetFederationRelationshipLastPoll(ctx, spiffeid.RequireTrustDomainFromString("non-existent-td.org"), succeededAt, nextPollAt, "")
+	s.Require().NoError(err)
+
+	err = s.ds.SetFederationRelationshipLastPoll(ctx, spiffeid.TrustDomain{}, succeededAt, nextPollAt, "")
+	s.Require().EqualError(err, "rpc error: code = InvalidArgument desc = trust domain is required")
+}
+
 func (s *PluginSuite) TestFetchFederationRelationship() {
 	testCases := []struct {
 		name        string
@@ -4523,6 +8833,15 @@ func (s *PluginSuite) TestCreateFederationRelationship() {
 				}(),
 			},
 		},
+		{
+			name: "creating a new federation relationship succeeds with refresh hint",
+			fr: &datastore.FederationRelationship{
+				TrustDomain:           spiffeid.RequireTrustDomainFromString("federated-td-web-with-refresh-hint.org"),
+				BundleEndpointURL:     requireURLFromString(s.T(), "federated-td-web-with-refresh-hint.org/bundleendpoint"),
+				BundleEndpointProfile: datastore.BundleEndpointWeb,
+				RefreshHint:           time.Hour,
+			},
+		},
 		{
 			name:       "creating a new nil federation relationship fails nicely ",
 			expectCode: codes.InvalidArgument,
@@ -4602,6 +8921,10 @@ func (s *PluginSuite) TestCreateFederationRelationship() {
 				require.NoError(t, err)
 				spiretest.RequireProtoEqual(t, bundle, fr.TrustDomainBundle)
 			}
+
+			fetched, err := s.ds.FetchFederationRelationship(ctx, fr.TrustDomain)
+			require.NoError(t, err)
+			require.Equal(t, tt.fr.RefreshHint, fetched.RefreshHint)
 		})
 	}
 }
@@ -4643,12 +8966,20 @@ func (s *PluginSuite) TestListFederationRelationships() {
 	_, err = s.ds.CreateFederationRelationship(ctx, fr4)
 	s.Require().NoError(err)
 
+	// The implicit flag is not part of the relationship, set it directly
+	s.Require().NoError(s.ds.db.Model(&FederatedTrustDomain{}).Where("trust_domain = ?", "example-4.org").UpdateColumn("implicit", true).Error)
+
+	implicit := true
+	notImplicit := false
+
 	tests := []struct {
-		name               string
-		pagination         *datastore.Pagination
-		expectedList       []*datastore.FederationRelationship
-		expectedPagination *datastore.Pagination
-		expectedErr        string
+		name                    string
+		pagination              *datastore.Pagination
+		byBundleEndpointProfile datastore.BundleEndpointType
+		byImplicit              *bool
+		expectedList            []*datastore.FederationRelationship
+		expectedPagination      *datastore.Pagination
+		expectedErr             string
 	}{
 		{
 			name:         "no pagination",
@@ -4670,7 +9001,11 @@ func (s *PluginSuite) TestListFederationRelationships() {
 			pagination: &datastore.Pagination{
 				PageSize: 0,
 			},
-			expectedErr: "rpc error: code = InvalidArgument desc = cannot paginate with pagesize = 0",
+			expectedList: []*datastore.FederationRelationship{fr1, fr2, fr3, fr4},
+			expectedPagination: &datastore.Pagination{
+				Token:    "4",
+				PageSize: defaultMaxPageSize,
+			},
 		},
 		{
 			name: "bundles first page",
@@ -4720,11 +9055,56 @@ func (s *PluginSuite) TestListFederationRelationships() {
 				PageSize: 2,
 			},
 		},
+		{
+			name:                    "by https_web profile",
+			byBundleEndpointProfile: datastore.BundleEndpointWeb,
+			expectedList:            []*datastore.FederationRelationship{fr1, fr4},
+		},
+		{
+			name:                    "by https_spiffe profile",
+			byBundleEndpointProfile: datastore.BundleEndpointSPIFFE,
+			expectedList:            []*datastore.FederationRelationship{fr2, fr3},
+		},
+		{
+			name:                    "by https_spiffe profile paginated",
+			byBundleEndpointProfile: datastore.BundleEndpointSPIFFE,
+			pagination: &datastore.Pagination{
+				PageSize: 1,
+			},
+			expectedList: []*datastore.FederationRelationship{fr2},
+			expectedPagination: &datastore.Pagination{
+				Token:    "2",
+				PageSize: 1,
+			},
+		},
+		{
+			name:                    "by unknown profile",
+			byBundleEndpointProfile: "https_unknown",
+			expectedErr:             `rpc error: code = InvalidArgument desc = unknown bundle endpoint profile "https_unknown"`,
+		},
+		{
+			name:         "by implicit",
+			byImplicit:   &implicit,
+			expectedList: []*datastore.FederationRelationship{fr4},
+		},
+		{
+			name:         "by not implicit",
+			byImplicit:   &notImplicit,
+			expectedList: []*datastore.FederationRelationship{fr1, fr2, fr3},
+		},
+		{
+			name:                    "by profile and implicit",
+			byBundleEndpointProfile: datastore.BundleEndpointWeb,
+			byImplicit:              &notImplicit,
+			expectedList:            []*datastore.FederationRelationship{fr1},
+		},
 	}
 	for _, test := range tests {
 		s.T().Run(test.name, func(t *testing.T) {
 			resp, err := s.ds.ListFederationRelationships(ctx, &datastore.ListFederationRelationshipsRequest{
-				Pagination: test.pagination,
+				ByBundleEndpointProfile: test.byBundleEndpointProfile,
+				ByImplicit:              test.byImplicit,
+				Pagination:              test.pagination,
 			})
 			if test.expectedErr != "" {
 				require.EqualError(t, err, test.expectedErr)
@@ -4772,6 +9152,46 @@ func (s *PluginSuite) TestUpdateFederationRelationship() {
 				BundleEndpointProfile: datastore.BundleEndpointWeb,
 			},
 		},
+		{
+			name: "updating refresh hint succeeds",
+			initialFR: &datastore.FederationRelationship{
+				TrustDomain:           spiffeid.RequireTrustDomainFromString("td.org"),
+				BundleEndpointURL:     requireURLFromString(s.T(), "td.org/bundle-endpoint"),
+				BundleEndpointProfile: datastore.BundleEndpointWeb,
+				RefreshHint:           time.Hour,
+			},
+			fr: &datastore.FederationRelationship{
+				TrustDomain: spiffeid.RequireTrustDomainFromString("td.org"),
+				RefreshHint: time.Minute,
+			},
+			mask: &types.FederationRelationshipMask{},
+			expFR: &datastore.FederationRelationship{
+				TrustDomain:           spiffeid.RequireTrustDomainFromString("td.org"),
+				BundleEndpointURL:     requireURLFromString(s.T(), "td.org/bundle-endpoint"),
+				BundleEndpointProfile: datastore.BundleEndpointWeb,
+				RefreshHint:           time.Minute,
+			},
+		},
+		{
+			name: "updating without refresh hint keeps the current one",
+			initialFR: &datastore.FederationRelationship{
+				TrustDomain:           spiffeid.RequireTrustDomainFromString("td.org"),
+				BundleEndpointURL:     requireURLFromString(s.T(), "td.org/bundle-endpoint"),
+				BundleEndpointProfile: datastore.BundleEndpointWeb,
+				RefreshHint:           time.Hour,
+			},
+			fr: &datastore.FederationRelationship{
+				TrustDomain:       spiffeid.RequireTrustDomainFromString("td.org"),
+				BundleEndpointURL: requireURLFromString(s.T(), "td.org/other-bundle-endpoint"),
+			},
+			mask: &types.FederationRelationshipMask{BundleEndpointUrl: true},
+			expFR: &datastore.FederationRelationship{
+				TrustDomain:           spiffeid.RequireTrustDomainFromString("td.org"),
+				BundleEndpointURL:     requireURLFromString(s.T(), "td.org/other-bundle-endpoint"),
+				BundleEndpointProfile: datastore.BundleEndpointWeb,
+				RefreshHint:           time.Hour,
+			},
+		},
 		{
 			name: "updating bundle endpoint profile with pre-existent bundle and no input bundle succeeds",
 			initialFR: &datastore.FederationRelationship{
@@ -4894,6 +9314,15 @@ func (s *PluginSuite) TestUpdateFederationRelationship() {
 				EndpointSPIFFEID:      spiffeid.RequireFromString("spiffe://td.org/federated-server"),
 			},
 		},
+		{
+			name:   "updating a federation relationship with negative refresh hint fails nicely",
+			expErr: "rpc error: code = InvalidArgument desc = refresh hint cannot be negative",
+			mask:   &types.FederationRelationshipMask{},
+			fr: &datastore.FederationRelationship{
+				TrustDomain: spiffeid.RequireTrustDomainFromString("td.org"),
+				RefreshHint: -time.Minute,
+			},
+		},
 		{
 			name:   "updating a federation relationship of unknown type fails nicely",
 			expErr: "rpc error: code = InvalidArgument desc = unknown bundle endpoint profile type: \"wrong-type\"",
@@ -4923,79 +9352,523 @@ func (s *PluginSuite) TestUpdateFederationRelationship() {
 			s.Require().NoError(err)
 			s.Require().NotNil(updatedFR)
 
-			switch tt.expFR.BundleEndpointProfile {
-			case datastore.BundleEndpointWeb:
-			case datastore.BundleEndpointSPIFFE:
-				// Assert bundle is updated
-				bundle, err := s.ds.FetchBundle(ctx, tt.expFR.TrustDomain.IDString())
-				s.Require().NoError(err)
-				s.RequireProtoEqual(bundle, updatedFR.TrustDomainBundle)
+			switch tt.expFR.BundleEndpointProfile {
+			case datastore.BundleEndpointWeb:
+			case datastore.BundleEndpointSPIFFE:
+				// Assert bundle is updated
+				bundle, err := s.ds.FetchBundle(ctx, tt.expFR.TrustDomain.IDString())
+				s.Require().NoError(err)
+				s.RequireProtoEqual(bundle, updatedFR.TrustDomainBundle)
+
+				// Now that bundles were asserted, set them to nil to be able to compare other fields using Require().Equal
+				tt.expFR.TrustDomainBundle = nil
+				updatedFR.TrustDomainBundle = nil
+			default:
+				s.Require().FailNowf("unexpected bundle endpoint profile type: %q", string(tt.expFR.BundleEndpointProfile))
+			}
+
+			s.Require().Equal(tt.expFR, updatedFR)
+		})
+	}
+}
+
+func (s *PluginSuite) TestMigration() {
+	for schemaVersion := range latestSchemaVersion {
+		s.T().Run(fmt.Sprintf("migration_from_schema_version_%d", schemaVersion), func(t *testing.T) {
+			require := require.New(t)
+			dbName := fmt.Sprintf("v%d.sqlite3", schemaVersion)
+			dbPath := filepath.ToSlash(filepath.Join(s.dir, "migration-"+dbName))
+			if runtime.GOOS == "windows" {
+				dbPath = "/" + dbPath
+			}
+			dbURI := fmt.Sprintf("file://%s", dbPath)
+
+			minimalDB := func() string {
+				previousMinor := codeVersion
+				if codeVersion.Minor == 0 {
+					previousMinor.Major--
+				} else {
+					previousMinor.Minor--
+				}
+				return fmt.Sprintf(`
+					CREATE TABLE "migrations" ("id" integer primary key autoincrement, "version" integer,"code_version" varchar(255) );
+					INSERT INTO migrations("version", "code_version") VALUES (%d,%q);
+				`, schemaVersion, previousMinor)
+			}
+
+			prepareDB := func(migrationSupported bool) {
+				dump := migrationDumps[schemaVersion]
+				if migrationSupported {
+					require.NotEmpty(dump, "no migration dump set up for schema version")
+				} else {
+					require.Empty(dump, "migration dump exists for unsupported schema version")
+					dump = minimalDB()
+				}
+				dumpDB(t, dbPath, dump)
+				err := s.ds.Configure(ctx, fmt.Sprintf(`
+					database_type = "sqlite3"
+					connection_string = %q
+				`, dbURI))
+				if migrationSupported {
+					require.NoError(err)
+				} else {
+					require.EqualError(err, fmt.Sprintf("datastore-sql: migrating from schema version %d requires a previous SPIRE release; please follow the upgrade strategy at doc/upgrading.md", schemaVersion))
+				}
+			}
+			switch schemaVersion {
+			// All of these schema versions were migrated by previous versions
+			// of SPIRE server and no longer have migration code.
+			case 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22:
+				prepareDB(false)
+			case 23:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("bundles", "last_refreshed_at"))
+
+				// The bundle in the dump is not refreshed through a
+				// federation relationship, so it is never backfilled.
+				bundle, err := s.ds.FetchBundle(ctx, "spiffe://example.org")
+				require.NoError(err)
+				require.NotNil(bundle)
+				require.Zero(bundle.LastRefreshedAt)
+			case 24:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("registered_entries", "created_by"))
+				require.True(s.ds.db.Dialect().HasIndex("registered_entries", "idx_registered_entries_created_by"))
+
+				// Existing entries have no known creator
+				entry, err := s.ds.FetchRegistrationEntry(ctx, "entry-1")
+				require.NoError(err)
+				require.NotNil(entry)
+				require.Empty(entry.CreatedBy)
+			case 25:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("registered_entries", "trust_domain"))
+				require.True(s.ds.db.Dialect().HasIndex("registered_entries", "idx_registered_entries_trust_domain"))
+
+				// Existing entries are populated from their SPIFFE ID
+				counts, err := s.ds.CountRegistrationEntriesByTrustDomain(ctx)
+				require.NoError(err)
+				require.Equal(map[string]int32{"example.org": 1}, counts)
+			case 26:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasIndex("attested_node_entries", "idx_attested_node_entries_data_type"))
+			case 27:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasTable("attested_node_serial_history"))
+				require.True(s.ds.db.Dialect().HasIndex("attested_node_serial_history", "idx_attested_node_serial_history_spiffe_id"))
+			case 28:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("federated_trust_domains", "refresh_hint"))
+			case 29:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("registered_entries", "idempotency_key"))
+				require.True(s.ds.db.Dialect().HasIndex("registered_entries", "uix_registered_entries_idempotency_key"))
+
+				// Existing entries have no idempotency key
+				entry, err := s.ds.FetchRegistrationEntry(ctx, "entry-1")
+				require.NoError(err)
+				require.NotNil(entry)
+				require.Empty(entry.IdempotencyKey)
+			case 30:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasTable("bundles_events"))
+			case 31:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("registered_entries", "active"))
+
+				// Existing entries are active
+				entry, err := s.ds.FetchRegistrationEntry(ctx, "entry-1")
+				require.NoError(err)
+				require.NotNil(entry)
+				require.False(entry.Inactive)
+			case 32:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("federated_trust_domains", "last_poll_at"))
+				require.True(s.ds.db.Dialect().HasColumn("federated_trust_domains", "last_poll_error"))
+			case 33:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("node_resolver_map_entries", "source"))
+
+				// Existing node selectors have no source
+				selectors, err := s.ds.GetNodeSelectors(ctx, "spiffe://example.org/agent", datastore.RequireCurrent, false)
+				require.NoError(err)
+				spiretest.AssertProtoListEqual(t, []*common.Selector{{Type: "join_token", Value: "1234"}}, selectors)
+			case 34:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("bundles", "content_hash"))
+
+				// Existing bundles have their content hash populated
+				var hashes []string
+				require.NoError(s.ds.db.Model(&Bundle{}).Pluck("content_hash", &hashes).Error)
+				require.Len(hashes, 1)
+				bundle, err := s.ds.FetchBundle(ctx, "spiffe://example.org")
+				require.NoError(err)
+				expectedHash, err := bundleutil.ContentHash(bundle)
+				require.NoError(err)
+				require.Equal(expectedHash, hashes[0])
+			case 35:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("node_resolver_map_entries", "expires_at"))
+
+				// Existing node selectors never expire
+				selectors, err := s.ds.GetNodeSelectors(ctx, "spiffe://example.org/agent", datastore.RequireCurrent, true)
+				require.NoError(err)
+				spiretest.AssertProtoListEqual(t, []*common.Selector{{Type: "join_token", Value: "1234"}}, selectors)
+			case 36:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("join_tokens", "remaining_uses"))
+
+				// Existing join tokens can be used once
+				joinToken, err := s.ds.FetchJoinToken(ctx, "token-1")
+				require.NoError(err)
+				require.Equal(1, joinToken.RemainingUses)
+			case 37:
+				// Add a bundle and a federation relationship whose trust
+				// domain is written in mixed case
+				dumpDB(t, dbPath, migrationDumps[schemaVersion]+insertMixedCaseTrustDomainsSQL(t, "spiffe://Domain.Test"))
+				require.NoError(s.ds.Configure(ctx, fmt.Sprintf(`
+					database_type = "sqlite3"
+					connection_string = %q
+				`, dbURI)))
+
+				bundle, err := s.ds.FetchBundle(ctx, "spiffe://domain.test")
+				require.NoError(err)
+				require.NotNil(bundle)
+				require.Equal("spiffe://domain.test", bundle.TrustDomainId)
+				hash, err := s.ds.FetchBundleContentHash(ctx, "spiffe://domain.test")
+				require.NoError(err)
+				expectedHash, err := bundleutil.ContentHash(bundle)
+				require.NoError(err)
+				require.Equal(expectedHash, hash)
+
+				fr, err := s.ds.FetchFederationRelationship(ctx, spiffeid.RequireTrustDomainFromString("domain.test"))
+				require.NoError(err)
+				require.NotNil(fr)
+				spiretest.AssertProtoEqual(t, bundle, fr.TrustDomainBundle)
+
+				// Lowercase trust domains are left as they are
+				bundle, err = s.ds.FetchBundle(ctx, "spiffe://example.org")
+				require.NoError(err)
+				require.NotNil(bundle)
+			case 38:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("attested_node_entries", "attested_at"))
+				require.True(s.ds.db.Dialect().HasColumn("attested_node_entries", "last_attested_at"))
+
+				// Existing nodes are considered attested when they were created
+				createdAt := time.Date(2026, time.October, 15, 13, 20, 5, 0, time.UTC).Unix()
+				node, err := s.ds.FetchAttestedNode(ctx, "spiffe://example.org/agent")
+				require.NoError(err)
+				require.NotNil(node)
+				require.Equal(createdAt, node.AttestedAt)
+				require.Equal(createdAt, node.LastAttestedAt)
+
+				resp, err := s.ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{})
+				require.NoError(err)
+				require.Len(resp.Nodes, 1)
+				require.Equal(createdAt, resp.Nodes[0].AttestedAt)
+				require.Equal(createdAt, resp.Nodes[0].LastAttestedAt)
+			case 39:
+				prepareDB(true)
+				for _, table := range []string{"bundles", "ca_journals"} {
+					require.True(s.ds.db.Dialect().HasColumn(table, "encryption_key_ref"))
+					require.True(s.ds.db.Dialect().HasColumn(table, "encryption_nonce"))
+				}
+
+				// Existing bundles are left in plaintext
+				bundle, err := s.ds.FetchBundle(ctx, "spiffe://example.org")
+				require.NoError(err)
+				require.NotNil(bundle)
+				require.Len(bundle.RootCas, 1)
+			case 40:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasIndex("attested_node_entries", "idx_attested_node_entries_updated_at"))
+			case 41:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("federated_trust_domains", "next_poll_at"))
+			case 42:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasColumn("registered_entries", "federates_with_all"))
+
+				// Existing entries keep their explicit federation list
+				entry, err := s.ds.FetchRegistrationEntry(ctx, "entry-1")
+				require.NoError(err)
+				require.NotNil(entry)
+				require.False(entry.FederatesWithAll)
+			case 43:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasIndex("selectors", "idx_selectors_type_value_entry"))
+				require.False(s.ds.db.Dialect().HasIndex("selectors", "idx_selectors_type_value"))
+			case 44:
+				prepareDB(true)
+				require.True(s.ds.db.Dialect().HasTable("attested_node_aliases"))
+				require.True(s.ds.db.Dialect().HasIndex("attested_node_aliases", "uix_attested_node_aliases_alias_spiffe_id"))
+			default:
+				t.Fatalf("no migration test added for schema version %d", schemaVersion)
+			}
+		})
+	}
+}
+
+func (s *PluginSuite) TestSchemaVersionMismatch() {
+	incompatibleCodeVersion := codeVersion
+	incompatibleCodeVersion.Minor += 2
+
+	for _, tt := range []struct {
+		name          string
+		schemaVersion int
+		codeVersion   semver.Version
+		allowMismatch bool
+		expectErr     string
+	}{
+		{
+			name:          "schema too new",
+			schemaVersion: latestSchemaVersion + 1,
+			codeVersion:   incompatibleCodeVersion,
+			expectErr:     fmt.Sprintf("datastore-sql: DB schema version %d, migrated by SPIRE Server %s, is newer than the latest schema version %d supported by SPIRE Server %s; upgrade SPIRE Server", latestSchemaVersion+1, incompatibleCodeVersion, latestSchemaVersion, codeVersion),
+		},
+		{
+			name:          "schema too new, mismatch allowed",
+			schemaVersion: latestSchemaVersion + 1,
+			codeVersion:   incompatibleCodeVersion,
+			allowMismatch: true,
+		},
+		{
+			name:          "schema too old",
+			schemaVersion: lastMinorReleaseSchemaVersion - 1,
+			codeVersion:   codeVersion,
+			expectErr:     fmt.Sprintf("datastore-sql: migrating from schema version %d requires a previous SPIRE release; please follow the upgrade strategy at doc/upgrading.md", lastMinorReleaseSchemaVersion-1),
+		},
+		{
+			name:          "schema too old, mismatch allowed",
+			schemaVersion: lastMinorReleaseSchemaVersion - 1,
+			codeVersion:   codeVersion,
+			allowMismatch: true,
+			expectErr:     fmt.Sprintf("datastore-sql: migrating from schema version %d requires a previous SPIRE release; please follow the upgrade strategy at doc/upgrading.md", lastMinorReleaseSchemaVersion-1),
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			dbPath := filepath.ToSlash(filepath.Join(t.TempDir(), "mismatch.sqlite3"))
+			if runtime.GOOS == "windows" {
+				dbPath = "/" + dbPath
+			}
+			dumpDB(t, dbPath, fmt.Sprintf(`
+				CREATE TABLE "migrations" ("id" integer primary key autoincrement, "version" integer,"code_version" varchar(255) );
+				INSERT INTO migrations("version", "code_version") VALUES (%d,%q);
+			`, tt.schemaVersion, tt.codeVersion))
+
+			log, _ := test.NewNullLogger()
+			ds := New(log)
+			defer ds.Close()
+			err := ds.Configure(ctx, fmt.Sprintf(`
+				database_type = "sqlite3"
+				connection_string = %q
+				allow_schema_version_mismatch = %t
+			`, "file://"+dbPath, tt.allowMismatch))
+			if tt.expectErr != "" {
+				require.EqualError(t, err, tt.expectErr)
+				return
+			}
+			require.NoError(t, err)
+
+			// The schema is left untouched
+			var m Migration
+			require.NoError(t, ds.db.First(&m).Error)
+			require.Equal(t, tt.schemaVersion, m.Version)
+			require.Equal(t, tt.codeVersion.String(), m.CodeVersion)
+		})
+	}
+}
+
+func (s *PluginSuite) TestConcurrentMigration() {
+	// Enough datastores are started at once to make them race for the
+	// migration if it wasn't locked
+	const datastores = 10
+
+	for _, tt := range []struct {
+		name      string
+		dump      string
+		expectLog string
+	}{
+		{
+			name:      "new database",
+			expectLog: "Initializing new database",
+		},
+		{
+			name:      "database to migrate",
+			dump:      migrationDumps[lastMinorReleaseSchemaVersion],
+			expectLog: "Running migrations...",
+		},
+	} {
+		s.T().Run(tt.name, func(t *testing.T) {
+			dbPath := filepath.ToSlash(filepath.Join(t.TempDir(), "concurrent-migration.sqlite3"))
+			if runtime.GOOS == "windows" {
+				dbPath = "/" + dbPath
+			}
+			if tt.dump != "" {
+				dumpDB(t, dbPath, tt.dump)
+			}
+			dbURI := "file://" + dbPath
+
+			var hooks []*test.Hook
+			errs := make(chan error, datastores)
+			for range datastores {
+				log, hook := test.NewNullLogger()
+				hooks = append(hooks, hook)
+				ds := New(log)
+				t.Cleanup(func() { ds.Close() })
+				// Switching a new database to the WAL journal mode fails
+				// if other connections are opened at the same time, which
+				// is unrelated to the migration.
+				go func() {
+					errs <- ds.Configure(ctx, fmt.Sprintf(`
+						database_type = "sqlite3"
+						connection_string = %q
+						sqlite_wal_mode = false
+					`, dbURI))
+				}()
+			}
+			for range datastores {
+				require.NoError(t, <-errs)
+			}
+
+			// Only one of the datastores initialized or migrated the database
+			count := 0
+			for _, hook := range hooks {
+				for _, entry := range hook.AllEntries() {
+					if entry.Message == tt.expectLog {
+						count++
+					}
+				}
+			}
+			require.Equal(t, 1, count)
+		})
+	}
+}
+
+func (s *PluginSuite) TestMigrationTrustDomainCaseCollision() {
+	dbPath := filepath.ToSlash(filepath.Join(s.T().TempDir(), "collision.sqlite3"))
+	if runtime.GOOS == "windows" {
+		dbPath = "/" + dbPath
+	}
+
+	// The dump already has a bundle for spiffe://example.org
+	dumpDB(s.T(), dbPath, migrationDumps[37]+insertMixedCaseTrustDomainsSQL(s.T(), "spiffe://Example.Org"))
+
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+	defer ds.Close()
+	err := ds.Configure(ctx, fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = "file://%s"
+	`, dbPath))
+	s.Require().EqualError(err, "datastore-sql: cannot lowercase the trust domains in bundles: rows only differing in case exist for spiffe://example.org; delete the duplicates before upgrading")
+}
+
+// insertMixedCaseTrustDomainsSQL returns the statements that add a bundle and
+// a federation relationship for the given trust domain ID, bypassing the
+// normalization done by the datastore.
+func insertMixedCaseTrustDomainsSQL(t *testing.T, trustDomainID string) string {
+	data, err := proto.Marshal(&common.Bundle{TrustDomainId: trustDomainID})
+	require.NoError(t, err)
+	return fmt.Sprintf(`
+		INSERT INTO bundles(created_at, updated_at, trust_domain, data, content_hash) VALUES(CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, %[1]q, X'%[2]x', 'stale');
+		INSERT INTO federated_trust_domains(created_at, updated_at, trust_domain, bundle_endpoint_url, bundle_endpoint_profile) VALUES(CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, %[3]q, 'https://domain.test/bundle', 'https_web');
+	`, trustDomainID, data, strings.TrimPrefix(trustDomainID, "spiffe://"))
+}
+
+func (s *PluginSuite) TestMigrationLockTimeout() {
+	dbPath := filepath.ToSlash(filepath.Join(s.T().TempDir(), "locked.sqlite3"))
+	if runtime.GOOS == "windows" {
+		dbPath = "/" + dbPath
+	}
+	dbURI := "file://" + dbPath
+
+	cfg := &configuration{ConnectionString: dbURI}
+	lock := sqliteDB{}.newMigrationLock(nil, cfg)
+	acquired, err := lock.tryLock(ctx)
+	s.Require().NoError(err)
+	s.Require().True(acquired)
+	defer func() { s.Require().NoError(lock.unlock()) }()
+
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+	defer ds.Close()
+	err = ds.Configure(ctx, fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = %q
+		migration_lock_timeout = "100ms"
+	`, dbURI))
+	s.Require().EqualError(err, "datastore-sql: timed out after 100ms waiting for another server to finish migrating the database")
+}
+
+func (s *PluginSuite) TestStatementTimeout() {
+	s.ds.db.stmtTimeout = 100 * time.Millisecond
+
+	// slowQuery runs a query that takes much longer than the timeout, and
+	// fails the test if it is not aborted in time.
+	slowQuery := func(ctx context.Context) (any, error) {
+		var query string
+		switch {
+		case isPostgresDbType(s.ds.db.databaseType):
+			query = "SELECT pg_sleep(30)"
+		case isMySQLDbType(s.ds.db.databaseType):
+			query = "SELECT SLEEP(30)"
+		default:
+			query = "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c LIMIT 100000000000) SELECT count(*) FROM c"
+		}
+
+		start := time.Now()
+		defer func() { s.Require().Less(time.Since(start), 10*time.Second) }()
+
+		rows, err := s.ds.db.QueryContext(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+		}
+		return nil, rows.Err()
+	}
+
+	s.Run("slow query is aborted", func() {
+		_, err := withStatementTimeout(ctx, s.ds.db, slowQuery)
+		s.RequireGRPCStatus(err, codes.DeadlineExceeded, "datastore-sql: statement timed out after 100ms")
+	})
 
-				// Now that bundles were asserted, set them to nil to be able to compare other fields using Require().Equal
-				tt.expFR.TrustDomainBundle = nil
-				updatedFR.TrustDomainBundle = nil
-			default:
-				s.Require().FailNowf("unexpected bundle endpoint profile type: %q", string(tt.expFR.BundleEndpointProfile))
-			}
+	s.Run("caller cancellation is not a timeout", func() {
+		ctx, cancel := context.WithCancel(ctx)
+		time.AfterFunc(10*time.Millisecond, cancel)
+		_, err := withStatementTimeout(ctx, s.ds.db, slowQuery)
+		s.Require().Error(err)
+		s.Require().NotEqual(codes.DeadlineExceeded, status.Code(err))
+	})
 
-			s.Require().Equal(tt.expFR, updatedFR)
-		})
+	slowTx := func(tx *gorm.DB) error {
+		time.Sleep(200 * time.Millisecond)
+		return tx.Exec("SELECT 1").Error
 	}
-}
 
-func (s *PluginSuite) TestMigration() {
-	for schemaVersion := range latestSchemaVersion {
-		s.T().Run(fmt.Sprintf("migration_from_schema_version_%d", schemaVersion), func(t *testing.T) {
-			require := require.New(t)
-			dbName := fmt.Sprintf("v%d.sqlite3", schemaVersion)
-			dbPath := filepath.ToSlash(filepath.Join(s.dir, "migration-"+dbName))
-			if runtime.GOOS == "windows" {
-				dbPath = "/" + dbPath
-			}
-			dbURI := fmt.Sprintf("file://%s", dbPath)
+	s.Run("slow transaction is aborted", func() {
+		err := s.ds.withReadTx(ctx, slowTx)
+		s.RequireGRPCStatus(err, codes.DeadlineExceeded, "datastore-sql: statement timed out after 100ms")
+	})
 
-			minimalDB := func() string {
-				previousMinor := codeVersion
-				if codeVersion.Minor == 0 {
-					previousMinor.Major--
-				} else {
-					previousMinor.Minor--
-				}
-				return fmt.Sprintf(`
-					CREATE TABLE "migrations" ("id" integer primary key autoincrement, "version" integer,"code_version" varchar(255) );
-					INSERT INTO migrations("version", "code_version") VALUES (%d,%q);
-				`, schemaVersion, previousMinor)
-			}
+	s.Run("exempt operations are not aborted", func() {
+		err := s.ds.withReadTx(withoutStatementTimeout(ctx), slowTx)
+		s.Require().NoError(err)
+	})
 
-			prepareDB := func(migrationSupported bool) {
-				dump := migrationDumps[schemaVersion]
-				if migrationSupported {
-					require.NotEmpty(dump, "no migration dump set up for schema version")
-				} else {
-					require.Empty(dump, "migration dump exists for unsupported schema version")
-					dump = minimalDB()
-				}
-				dumpDB(t, dbPath, dump)
-				err := s.ds.Configure(ctx, fmt.Sprintf(`
-					database_type = "sqlite3"
-					connection_string = %q
-				`, dbURI))
-				if migrationSupported {
-					require.NoError(err)
-				} else {
-					require.EqualError(err, fmt.Sprintf("datastore-sql: migrating from schema version %d requires a previous SPIRE release; please follow the upgrade strategy at doc/upgrading.md", schemaVersion))
-				}
-			}
-			switch schemaVersion {
-			// All of these schema versions were migrated by previous versions
-			// of SPIRE server and no longer have migration code.
-			case 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22:
-				prepareDB(false)
-			default:
-				t.Fatalf("no migration test added for schema version %d", schemaVersion)
-			}
+	s.Run("operations within the timeout succeed", func() {
+		entry := s.createRegistrationEntry(&common.RegistrationEntry{
+			SpiffeId:  "spiffe://example.org/workload",
+			ParentId:  "spiffe://example.org/parent",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
 		})
-	}
+		fetched, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+		s.Require().NoError(err)
+		s.AssertProtoEqual(entry, fetched)
+	})
 }
 
 func (s *PluginSuite) TestPristineDatabaseMigrationValues() {
@@ -5032,6 +9905,156 @@ func (s *PluginSuite) TestBindVar() {
 	s.Require().Equal("SELECT whatever FROM foo WHERE x = $1 AND y = $2", bound)
 }
 
+func (s *PluginSuite) TestDataEncryption() {
+	// Written before encryption is enabled
+	plaintextBundle := bundleutil.BundleProtoFromRootCA("spiffe://plaintext", s.cert)
+	_, err := s.ds.CreateBundle(ctx, plaintextBundle)
+	s.Require().NoError(err)
+
+	provider := newFakeAEADProvider("key-1")
+	s.ds.db.aeadProvider = provider
+
+	bundle := bundleutil.BundleProtoFromRootCA("spiffe://foo", s.cert)
+	_, err = s.ds.CreateBundle(ctx, bundle)
+	s.Require().NoError(err)
+	journalData := []byte("journal data")
+	caJournal, err := s.ds.SetCAJournal(ctx, &datastore.CAJournal{
+		Data:                  journalData,
+		ActiveX509AuthorityID: "x509-authority",
+	})
+	s.Require().NoError(err)
+	s.Equal(journalData, caJournal.Data)
+
+	// The data is encrypted on disk, along with the key reference and nonce
+	bundleData, err := proto.Marshal(bundle)
+	s.Require().NoError(err)
+	var bundleModel Bundle
+	s.Require().NoError(s.ds.db.Find(&bundleModel, "trust_domain = ?", "spiffe://foo").Error)
+	s.NotEqual(bundleData, bundleModel.Data)
+	s.Equal("key-1", bundleModel.EncryptionKeyRef)
+	s.NotEmpty(bundleModel.EncryptionNonce)
+	var caJournalModel CAJournal
+	s.Require().NoError(s.ds.db.Find(&caJournalModel, "id = ?", caJournal.ID).Error)
+	s.NotEqual(journalData, caJournalModel.Data)
+	s.Equal("key-1", caJournalModel.EncryptionKeyRef)
+	s.NotEmpty(caJournalModel.EncryptionNonce)
+
+	// Encrypted and plaintext rows are both read back
+	s.RequireProtoEqual(bundle, s.fetchBundle("spiffe://foo"))
+	s.RequireProtoEqual(plaintextBundle, s.fetchBundle("spiffe://plaintext"))
+	resp, err := s.ds.ListBundles(ctx, &datastore.ListBundlesRequest{})
+	s.Require().NoError(err)
+	spiretest.AssertProtoListEqual(s.T(), []*common.Bundle{plaintextBundle, bundle}, resp.Bundles)
+	fetchedJournal, err := s.ds.FetchCAJournal(ctx, "x509-authority")
+	s.Require().NoError(err)
+	s.Equal(journalData, fetchedJournal.Data)
+
+	// Plaintext rows are encrypted the next time they are written, with the
+	// current key
+	provider.currentKeyRef = "key-2"
+	appended, err := s.ds.AppendBundle(ctx, &common.Bundle{
+		TrustDomainId:  "spiffe://plaintext",
+		JwtSigningKeys: []*common.PublicKey{{Kid: "kid", PkixBytes: []byte("key")}},
+	})
+	s.Require().NoError(err)
+	s.RequireProtoEqual(appended, s.fetchBundle("spiffe://plaintext"))
+	var plaintextBundleModel Bundle
+	s.Require().NoError(s.ds.db.Find(&plaintextBundleModel, "trust_domain = ?", "spiffe://plaintext").Error)
+	s.Equal("key-2", plaintextBundleModel.EncryptionKeyRef)
+
+	caJournal.Data = []byte("updated journal data")
+	_, err = s.ds.SetCAJournal(ctx, caJournal)
+	s.Require().NoError(err)
+	fetchedJournal, err = s.ds.FetchCAJournal(ctx, "x509-authority")
+	s.Require().NoError(err)
+	s.Equal([]byte("updated journal data"), fetchedJournal.Data)
+
+	// Data that was encrypted cannot be read once encryption is disabled
+	s.ds.db.aeadProvider = nil
+	_, err = s.ds.FetchBundle(ctx, "spiffe://foo")
+	s.RequireGRPCStatus(err, codes.Internal, `datastore-sql: data is encrypted with key "key-1" but no encryption is configured`)
+	_, err = s.ds.FetchCAJournal(ctx, "x509-authority")
+	s.RequireGRPCStatus(err, codes.Internal, `datastore-sql: data is encrypted with key "key-2" but no encryption is configured`)
+}
+
+func (s *PluginSuite) TestObserver() {
+	observer := &recordingObserver{}
+	s.ds.SetObserver(observer)
+
+	node := &common.AttestedNode{
+		SpiffeId:            "spiffe://example.org/spire/agent/node",
+		AttestationDataType: "aws-tag",
+		CertSerialNumber:    "1234",
+		CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+	}
+	_, err := s.ds.CreateAttestedNode(ctx, node)
+	s.Require().NoError(err)
+	node.CertSerialNumber = "5678"
+	_, err = s.ds.UpdateAttestedNode(ctx, node, &common.AttestedNodeMask{CertSerialNumber: true})
+	s.Require().NoError(err)
+	s.Require().NoError(s.ds.SetNodeSelectors(ctx, node.SpiffeId, []*common.Selector{{Type: "TYPE", Value: "VALUE"}}))
+
+	entry, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		Selectors: []*common.Selector{{Type: "TYPE", Value: "VALUE"}},
+		SpiffeId:  "spiffe://example.org/workload",
+		ParentId:  node.SpiffeId,
+	})
+	s.Require().NoError(err)
+	entry.X509SvidTtl = 60
+	_, err = s.ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{X509SvidTtl: true})
+	s.Require().NoError(err)
+
+	// Read operations are not observed
+	_, err = s.ds.FetchAttestedNode(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+	_, err = s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+	s.Require().NoError(err)
+
+	// Failed operations are not observed
+	_, err = s.ds.CreateAttestedNode(ctx, node)
+	s.Require().Error(err)
+
+	// Errors of the observer don't roll back the changes
+	observer.err = errors.New("oh no")
+	_, err = s.ds.DeleteRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	observer.err = nil
+	deleted, err := s.ds.FetchRegistrationEntry(ctx, entry.EntryId)
+	s.Require().NoError(err)
+	s.Require().Nil(deleted)
+	spiretest.AssertLastLogs(s.T(), s.hook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.ErrorLevel,
+			Message: "Datastore observer failed to observe a mutation",
+			Data: logrus.Fields{
+				logrus.ErrorKey:      "oh no",
+				telemetry.Mutation:   MutationDelete,
+				telemetry.EntityType: EntityTypeRegistrationEntry,
+				telemetry.EntityID:   entry.EntryId,
+			},
+		},
+	})
+
+	_, err = s.ds.DeleteAttestedNode(ctx, node.SpiffeId)
+	s.Require().NoError(err)
+
+	s.Require().Equal([]string{
+		"create attested_node " + node.SpiffeId,
+		"update attested_node " + node.SpiffeId,
+		"update node_selectors " + node.SpiffeId,
+		"create registration_entry " + entry.EntryId,
+		"update registration_entry " + entry.EntryId,
+		"delete registration_entry " + entry.EntryId,
+		"delete attested_node " + node.SpiffeId,
+	}, observer.mutations)
+
+	// The observer is no longer notified once removed
+	s.ds.SetObserver(nil)
+	_, err = s.ds.CreateAttestedNode(ctx, node)
+	s.Require().NoError(err)
+	s.Require().Len(observer.mutations, 7)
+}
+
 func (s *PluginSuite) TestSetCAJournal() {
 	testCases := []struct {
 		name      string
@@ -5171,6 +10194,74 @@ func (s *PluginSuite) TestPruneCAJournal() {
 	s.Require().Nil(caj)
 }
 
+func (s *PluginSuite) TestPruneCAJournalAuthorities() {
+	now := time.Now()
+	expiredLongAgo := now.Add(-48 * time.Hour).Unix()
+	expiredRecently := now.Add(-time.Hour).Unix()
+	notExpired := now.Add(time.Hour).Unix()
+
+	entries := &journal.Entries{
+		X509CAs: []*journal.X509CAEntry{
+			{AuthorityId: "x509-old-expired", Status: journal.Status_OLD, NotAfter: expiredLongAgo},
+			{AuthorityId: "x509-old-recently-expired", Status: journal.Status_OLD, NotAfter: expiredRecently},
+			{AuthorityId: "x509-active-id", Status: journal.Status_OLD, NotAfter: expiredLongAgo},
+			{AuthorityId: "x509-prepared", Status: journal.Status_PREPARED, NotAfter: expiredLongAgo},
+			{AuthorityId: "x509-active", Status: journal.Status_ACTIVE, NotAfter: notExpired},
+		},
+		JwtKeys: []*journal.JWTKeyEntry{
+			{AuthorityId: "jwt-old-expired", Status: journal.Status_OLD, NotAfter: expiredLongAgo},
+			{AuthorityId: "jwt-unknown-expired", Status: journal.Status_UNKNOWN, NotAfter: expiredLongAgo},
+			{AuthorityId: "jwt-active", Status: journal.Status_ACTIVE, NotAfter: expiredLongAgo},
+			{AuthorityId: "jwt-prepared", Status: journal.Status_PREPARED, NotAfter: notExpired},
+		},
+	}
+	entriesBytes, err := proto.Marshal(entries)
+	s.Require().NoError(err)
+
+	_, err = s.ds.SetCAJournal(ctx, &datastore.CAJournal{
+		ActiveX509AuthorityID: "x509-active-id",
+		Data:                  entriesBytes,
+	})
+	s.Require().NoError(err)
+
+	authorityIDs := func() (x509IDs, jwtIDs []string) {
+		caj, err := s.ds.FetchCAJournal(ctx, "x509-active-id")
+		s.Require().NoError(err)
+		s.Require().NotNil(caj)
+
+		entries := new(journal.Entries)
+		s.Require().NoError(proto.Unmarshal(caj.Data, entries))
+		for _, x509CA := range entries.X509CAs {
+			x509IDs = append(x509IDs, x509CA.AuthorityId)
+		}
+		for _, jwtKey := range entries.JwtKeys {
+			jwtIDs = append(jwtIDs, jwtKey.AuthorityId)
+		}
+		return x509IDs, jwtIDs
+	}
+
+	s.RequireGRPCStatus(s.ds.PruneCAJournal(ctx, -time.Hour), codes.InvalidArgument, "retention cannot be negative")
+
+	// Nothing expired before the retention period
+	s.Require().NoError(s.ds.PruneCAJournal(ctx, 72*time.Hour))
+	x509IDs, jwtIDs := authorityIDs()
+	s.Require().Equal([]string{"x509-old-expired", "x509-old-recently-expired", "x509-active-id", "x509-prepared", "x509-active"}, x509IDs)
+	s.Require().Equal([]string{"jwt-old-expired", "jwt-unknown-expired", "jwt-active", "jwt-prepared"}, jwtIDs)
+
+	// Only the authorities that expired long ago are pruned, except for the
+	// active and prepared ones
+	s.Require().NoError(s.ds.PruneCAJournal(ctx, 24*time.Hour))
+	x509IDs, jwtIDs = authorityIDs()
+	s.Require().Equal([]string{"x509-old-recently-expired", "x509-active-id", "x509-prepared", "x509-active"}, x509IDs)
+	s.Require().Equal([]string{"jwt-active", "jwt-prepared"}, jwtIDs)
+
+	// Recently expired authorities are pruned with no retention
+	s.Require().NoError(s.ds.PruneCAJournal(ctx, 0))
+	x509IDs, jwtIDs = authorityIDs()
+	s.Require().Equal([]string{"x509-active-id", "x509-prepared", "x509-active"}, x509IDs)
+	s.Require().Equal([]string{"jwt-active", "jwt-prepared"}, jwtIDs)
+}
+
 func (s *PluginSuite) getTestDataFromJSONFile(filePath string, jsonValue any) {
 	entriesJSON, err := os.ReadFile(filePath)
 	s.Require().NoError(err)
@@ -5224,7 +10315,7 @@ func (s *PluginSuite) getNodeSelectors(spiffeID string, dataConsistency datastor
 	if dataConsistency == datastore.TolerateStale && TestReadOnlyDelay != "" {
 		time.Sleep(s.readOnlyDelay)
 	}
-	selectors, err := s.ds.GetNodeSelectors(ctx, spiffeID, dataConsistency)
+	selectors, err := s.ds.GetNodeSelectors(ctx, spiffeID, dataConsistency, false)
 	s.Require().NoError(err)
 	return selectors
 }
@@ -5241,6 +10332,64 @@ func (s *PluginSuite) setNodeSelectors(spiffeID string, selectors []*common.Sele
 	s.Require().NoError(err)
 }
 
+func (s *PluginSuite) TestTransientTransactionRetry() {
+	// Have the database fail the first creates with a MySQL deadlock, which
+	// the dialect below reports as transient regardless of the database used.
+	var failures, creates int
+	s.ds.db.dialect = deadlockDialect{dialect: s.ds.db.dialect}
+	s.ds.db.Callback().Create().Before("gorm:create").Register("test:deadlock", func(scope *gorm.Scope) {
+		creates++
+		if failures > 0 {
+			failures--
+			_ = scope.Err(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+		}
+	})
+	defer s.ds.db.Callback().Create().Remove("test:deadlock")
+
+	s.ds.mu.Lock()
+	s.ds.txRetryBaseDelay = time.Millisecond
+	s.ds.mu.Unlock()
+
+	entry := &common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	}
+
+	s.T().Run("retryable transaction succeeds after a deadlock", func(t *testing.T) {
+		failures, creates = 1, 0
+		created, err := s.ds.CreateRegistrationEntry(ctx, entry)
+		require.NoError(t, err)
+		require.NotEmpty(t, created.EntryId)
+
+		fetched, err := s.ds.FetchRegistrationEntry(ctx, created.EntryId)
+		require.NoError(t, err)
+		require.NotNil(t, fetched)
+	})
+
+	s.T().Run("retryable transaction gives up after max attempts", func(t *testing.T) {
+		s.ds.mu.Lock()
+		s.ds.txRetryMaxAttempts = 2
+		s.ds.mu.Unlock()
+
+		failures, creates = 2, 0
+		_, err := s.ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+			ParentId:  "spiffe://example.org/parent",
+			SpiffeId:  "spiffe://example.org/other",
+			Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+		})
+		require.ErrorContains(t, err, "Deadlock found when trying to get lock")
+		require.Equal(t, 2, creates)
+	})
+
+	s.T().Run("non retryable transaction fails on a deadlock", func(t *testing.T) {
+		failures, creates = 1, 0
+		_, err := s.ds.CreateBundle(ctx, bundleutil.BundleProtoFromRootCA("spiffe://example.org", s.cert))
+		require.ErrorContains(t, err, "Deadlock found when trying to get lock")
+		require.Equal(t, 1, creates)
+	})
+}
+
 func (s *PluginSuite) TestConfigure() {
 	tests := []struct {
 		desc               string
@@ -5269,6 +10418,7 @@ func (s *PluginSuite) TestConfigure() {
 			max_open_conns = 1000
 			max_idle_conns = 50
 			conn_max_lifetime = "10s"
+			conn_max_idle_time = "10s"
 			`,
 			expectMaxOpenConns: 1000,
 			expectIdle:         50,
@@ -5309,6 +10459,164 @@ func (s *PluginSuite) TestConfigure() {
 			require.Equal(t, tt.expectIdle, db.Stats().Idle)
 		})
 	}
+
+	s.T().Run("idle connections are closed", func(t *testing.T) {
+		dbPath := filepath.ToSlash(filepath.Join(s.dir, "test-datastore-configure-idle.sqlite3"))
+
+		log, _ := test.NewNullLogger()
+		p := New(log)
+		err := p.Configure(ctx, fmt.Sprintf(`
+			database_type = "sqlite3"
+			connection_string = "%s"
+			conn_max_idle_time = "10ms"
+		`, dbPath))
+		require.NoError(t, err)
+		defer p.Close()
+
+		db := p.db.DB.DB()
+		rows, err := db.Query("SELECT * FROM bundles")
+		require.NoError(t, err)
+		require.NoError(t, rows.Close())
+
+		// The pool checks for idle connections to close at most every second
+		require.Eventually(t, func() bool {
+			stats := db.Stats()
+			return stats.Idle == 0 && stats.MaxIdleTimeClosed > 0
+		}, 5*time.Second, 50*time.Millisecond)
+	})
+}
+
+func (s *PluginSuite) TestPing() {
+	s.Require().NoError(s.ds.Ping(ctx))
+
+	ds := s.newPlugin()
+	s.Require().NoError(ds.Ping(ctx))
+	s.Require().NoError(ds.Close())
+	s.Require().ErrorContains(ds.Ping(ctx), "sql: database is closed")
+}
+
+func (s *PluginSuite) TestReadOnlyConnectionRouting() {
+	if TestDialect != "" {
+		s.T().Skip("read-only routing is exercised against sqlite3 only")
+	}
+
+	// SQLite does not support read-only connections, so use the database of
+	// another plugin as the read-only database. Since nothing is written to
+	// it, reads routed to it observe an empty database.
+	replica := s.newPlugin()
+	defer replica.Close()
+	s.ds.mu.Lock()
+	s.ds.roDb = replica.db
+	s.ds.mu.Unlock()
+	defer func() {
+		s.ds.mu.Lock()
+		s.ds.roDb = nil
+		s.ds.mu.Unlock()
+	}()
+
+	// Writes go to the primary database
+	s.createRegistrationEntry(&common.RegistrationEntry{
+		ParentId:  "spiffe://example.org/parent",
+		SpiffeId:  "spiffe://example.org/workload",
+		Selectors: []*common.Selector{{Type: "unix", Value: "uid:1000"}},
+	})
+	nodeSelectors := []*common.Selector{{Type: "type", Value: "value"}}
+	s.Require().NoError(s.ds.SetNodeSelectors(ctx, "spiffe://example.org/node", nodeSelectors))
+
+	for _, tt := range []struct {
+		dataConsistency datastore.DataConsistency
+		expectPrimary   bool
+	}{
+		{dataConsistency: datastore.RequireCurrent, expectPrimary: true},
+		{dataConsistency: datastore.TolerateStale, expectPrimary: false},
+	} {
+		expectCount := 0
+		if tt.expectPrimary {
+			expectCount = 1
+		}
+
+		listResp, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+			DataConsistency: tt.dataConsistency,
+		})
+		s.Require().NoError(err)
+		s.Require().Len(listResp.Entries, expectCount)
+
+		count, err := s.ds.CountRegistrationEntries(ctx, &datastore.CountRegistrationEntriesRequest{
+			DataConsistency: tt.dataConsistency,
+		})
+		s.Require().NoError(err)
+		s.Require().Equal(int32(expectCount), count)
+
+		selectors, err := s.ds.GetNodeSelectors(ctx, "spiffe://example.org/node", tt.dataConsistency, false)
+		s.Require().NoError(err)
+		s.Require().Len(selectors, expectCount)
+
+		selectorsResp, err := s.ds.ListNodeSelectors(ctx, &datastore.ListNodeSelectorsRequest{
+			DataConsistency: tt.dataConsistency,
+		})
+		s.Require().NoError(err)
+		s.Require().Len(selectorsResp.Selectors, expectCount)
+	}
+
+	// Reads default to the primary database
+	entries, err := s.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(entries.Entries, 1)
+}
+
+func (s *PluginSuite) TestConnectionStats() {
+	if TestDialect != "" {
+		s.T().Skip("connection stats are exercised against sqlite3 only")
+	}
+
+	metrics := fakemetrics.New()
+	dbPath := filepath.ToSlash(filepath.Join(s.dir, "test-datastore-connection-stats.sqlite3"))
+	log, _ := test.NewNullLogger()
+	p := New(log)
+	p.SetMetrics(metrics)
+	err := p.Configure(ctx, fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = "%s"
+		enable_connection_stats = true
+		connection_stats_period = "10ms"
+	`, dbPath))
+	s.Require().NoError(err)
+	defer p.Close()
+
+	// Per-method latency is reported by the metrics wrapper
+	ds := ds_telemetry.WithMetrics(p, metrics)
+	_, err = ds.FetchBundle(ctx, "spiffe://example.org")
+	s.Require().NoError(err)
+
+	hasKey := func(key ...string) bool {
+		for _, item := range metrics.AllMetrics() {
+			if reflect.DeepEqual(item.Key, key) {
+				return true
+			}
+		}
+		return false
+	}
+	s.Require().Eventually(func() bool {
+		return hasKey(telemetry.Datastore, telemetry.Connections, telemetry.Open)
+	}, time.Minute, 10*time.Millisecond)
+
+	s.Require().True(hasKey(telemetry.Datastore, telemetry.Connections, telemetry.Idle))
+	s.Require().True(hasKey(telemetry.Datastore, telemetry.Connections, telemetry.InUse))
+	s.Require().True(hasKey(telemetry.Datastore, telemetry.Connections, telemetry.WaitCount))
+	s.Require().True(hasKey(telemetry.Datastore, telemetry.Bundle, telemetry.Fetch))
+	s.Require().True(hasKey(telemetry.Datastore, telemetry.Bundle, telemetry.Fetch, telemetry.ElapsedTime))
+
+	for _, item := range metrics.AllMetrics() {
+		if item.Type == fakemetrics.SetGaugeWithLabelsType {
+			s.Require().Equal([]telemetry.Label{{Name: telemetry.ReadOnly, Value: "false"}}, item.Labels)
+		}
+	}
+
+	// No more samples are taken once the datastore is closed
+	s.Require().NoError(p.Close())
+	metrics.Reset()
+	time.Sleep(50 * time.Millisecond)
+	s.Require().Empty(metrics.AllMetrics())
 }
 
 func (s *PluginSuite) assertEntryEqual(t *testing.T, expectEntry, createdEntry *common.RegistrationEntry, now int64) {
@@ -5376,6 +10684,99 @@ func assertBundlesEqual(t *testing.T, expected, actual []*common.Bundle) {
 	}
 }
 
+func TestIsTransientError(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		dialect   dialect
+		err       error
+		transient bool
+	}{
+		{name: "postgres serialization failure", dialect: postgresDB{}, err: &pq.Error{Code: "40001"}, transient: true},
+		{name: "postgres deadlock", dialect: postgresDB{}, err: &pq.Error{Code: "40P01"}, transient: true},
+		{name: "postgres wrapped deadlock", dialect: postgresDB{}, err: newWrappedSQLError(&pq.Error{Code: "40P01"}), transient: true},
+		{name: "postgres unique violation", dialect: postgresDB{}, err: &pq.Error{Code: "23505"}},
+		{name: "postgres other error", dialect: postgresDB{}, err: errors.New("oh no")},
+		{name: "mysql deadlock", dialect: mysqlDB{}, err: &mysql.MySQLError{Number: 1213}, transient: true},
+		{name: "mysql wrapped deadlock", dialect: mysqlDB{}, err: newWrappedSQLError(&mysql.MySQLError{Number: 1213}), transient: true},
+		{name: "mysql duplicate entry", dialect: mysqlDB{}, err: &mysql.MySQLError{Number: 1062}},
+		{name: "mysql other error", dialect: mysqlDB{}, err: errors.New("oh no")},
+		{name: "sqlite", dialect: sqliteDB{}, err: errors.New("oh no")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.transient, tt.dialect.isTransientError(tt.err))
+		})
+	}
+}
+
+func TestIsStatementTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		dialect dialect
+		err     error
+		timeout bool
+	}{
+		{name: "postgres query canceled", dialect: postgresDB{}, err: &pq.Error{Code: "57014"}, timeout: true},
+		{name: "postgres wrapped query canceled", dialect: postgresDB{}, err: newWrappedSQLError(&pq.Error{Code: "57014"}), timeout: true},
+		{name: "postgres other error", dialect: postgresDB{}, err: &pq.Error{Code: "40001"}},
+		{name: "mysql query timeout", dialect: mysqlDB{}, err: &mysql.MySQLError{Number: 3024}, timeout: true},
+		{name: "mysql wrapped query timeout", dialect: mysqlDB{}, err: newWrappedSQLError(&mysql.MySQLError{Number: 3024}), timeout: true},
+		{name: "mysql other error", dialect: mysqlDB{}, err: &mysql.MySQLError{Number: 1213}},
+		{name: "sqlite", dialect: sqliteDB{}, err: errors.New("oh no")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.timeout, tt.dialect.isStatementTimeout(tt.err))
+		})
+	}
+}
+
+// deadlockDialect wraps a dialect so that MySQL deadlock errors are reported
+// as transient, whatever the database the tests are run against.
+type deadlockDialect struct {
+	dialect
+}
+
+func (deadlockDialect) isTransientError(err error) bool {
+	return mysqlDB{}.isTransientError(err)
+}
+
+// BenchmarkListAttestedNodes compares listing nodes with and without their
+// selectors, since loading selectors is a separate query per page.
+func BenchmarkListAttestedNodes(b *testing.B) {
+	log, _ := test.NewNullLogger()
+	ds := New(log)
+	dbPath := filepath.ToSlash(filepath.Join(b.TempDir(), "db.sqlite3"))
+	require.NoError(b, ds.Configure(ctx, fmt.Sprintf(`
+		database_type = "sqlite3"
+		connection_string = "%s"
+	`, dbPath)))
+	defer ds.Close()
+
+	const numNodes = 1000
+	for i := range numNodes {
+		spiffeID := makeID(fmt.Sprintf("agent-%d", i))
+		_, err := ds.CreateAttestedNode(ctx, &common.AttestedNode{
+			SpiffeId:            spiffeID,
+			AttestationDataType: "aws-tag",
+			CertSerialNumber:    strconv.Itoa(i),
+			CertNotAfter:        time.Now().Add(time.Hour).Unix(),
+		})
+		require.NoError(b, err)
+		require.NoError(b, ds.SetNodeSelectors(ctx, spiffeID, makeSelectors("A", "B", "C", "D", "E")))
+	}
+
+	for _, fetchSelectors := range []bool{false, true} {
+		b.Run(fmt.Sprintf("fetch selectors %t", fetchSelectors), func(b *testing.B) {
+			for range b.N {
+				resp, err := ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{
+					FetchSelectors: &fetchSelectors,
+				})
+				require.NoError(b, err)
+				require.Len(b, resp.Nodes, numNodes)
+			}
+		})
+	}
+}
+
 func wipePostgres(t *testing.T, connString string) {
 	db, err := sql.Open("postgres", connString)
 	require.NoError(t, err)
@@ -5501,3 +10902,15 @@ func assertCAJournal(t *testing.T, exp, actual *datastore.CAJournal) {
 	assert.Equal(t, exp.ActiveX509AuthorityID, actual.ActiveX509AuthorityID)
 	assert.Equal(t, exp.Data, actual.Data)
 }
+
+// recordingObserver records the mutations it observes as
+// "<op> <entity type> <entity ID>".
+type recordingObserver struct {
+	mutations []string
+	err       error
+}
+
+func (o *recordingObserver) OnMutation(_ context.Context, op string, entityType string, entityID string) error {
+	o.mutations = append(o.mutations, op+" "+entityType+" "+entityID)
+	return o.err
+}
@@ -0,0 +1,75 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errStatementTimeout is the cause of the cancellation of the contexts
+// returned by statementContext, used to tell statement timeouts apart from
+// the cancellation of the caller context.
+var errStatementTimeout = errors.New("statement timeout exceeded")
+
+type statementTimeoutExemptKey struct{}
+
+// withoutStatementTimeout returns a context for operations that legitimately
+// take longer than the statement timeout, like pruning, so they are not
+// subject to it.
+func withoutStatementTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, statementTimeoutExemptKey{}, true)
+}
+
+// statementTimeout returns the statement timeout that applies to the
+// operation run with the given context, or zero if there is none.
+func (db *sqlDB) statementTimeout(ctx context.Context) time.Duration {
+	if exempt, _ := ctx.Value(statementTimeoutExemptKey{}).(bool); exempt {
+		return 0
+	}
+	return db.stmtTimeout
+}
+
+// statementContext returns a context that is cancelled once the statement
+// timeout of the operation run with it elapses.
+func (db *sqlDB) statementContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := db.statementTimeout(ctx)
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeoutCause(ctx, timeout, errStatementTimeout)
+}
+
+// statementTimeoutError converts the error of an operation run with a context
+// returned by statementContext into a DeadlineExceeded error if the operation
+// was aborted by the statement timeout, either because the context expired
+// or because the database aborted the statement. Other errors are returned
+// as is.
+func (db *sqlDB) statementTimeoutError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(context.Cause(ctx), errStatementTimeout) || (ctx.Err() == nil && db.dialect.isStatementTimeout(err)) {
+		return status.Errorf(codes.DeadlineExceeded, "%s: statement timed out after %s", datastoreSQLErrorPrefix, db.statementTimeout(ctx))
+	}
+	return err
+}
+
+// withStatementTimeout runs a query that is not part of a transaction, and
+// is therefore not covered by the statement timeout applied to transactions,
+// under the statement timeout of the database. Since these queries only read,
+// they are retried if the connection to the database is lost.
+func withStatementTimeout[T any](ctx context.Context, db *sqlDB, query func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := db.retryRead(ctx, func() (bool, error) {
+		ctx, cancel := db.statementContext(ctx)
+		defer cancel()
+
+		var err error
+		result, err = query(ctx)
+		return db.dialect.isConnectionError(err), db.statementTimeoutError(ctx, err)
+	})
+	return result, err
+}
@@ -17,8 +17,6 @@ import (
 
 var _ api.AuthorizedEntryFetcher = (*AuthorizedEntryFetcherWithEventsBasedCache)(nil)
 
-const buildCachePageSize = 10000
-
 type AuthorizedEntryFetcherWithEventsBasedCache struct {
 	cache *authorizedentries.Cache
 	clk   clock.Clock
@@ -30,6 +28,7 @@ type AuthorizedEntryFetcherWithEventsBasedCache struct {
 
 	registrationEntries eventsBasedCache
 	attestedNodes       eventsBasedCache
+	bundles             *bundles
 }
 
 type eventsBasedCache interface {
@@ -42,6 +41,10 @@ func NewAuthorizedEntryFetcherWithEventsBasedCache(ctx context.Context, log logr
 	if err != nil {
 		return nil, err
 	}
+	bundles, err := buildBundlesCache(ctx, ds)
+	if err != nil {
+		return nil, err
+	}
 	log.Info("Completed building event-based in-memory entry cache")
 
 	return &AuthorizedEntryFetcherWithEventsBasedCache{
@@ -53,9 +56,17 @@ func NewAuthorizedEntryFetcherWithEventsBasedCache(ctx context.Context, log logr
 		pruneEventsOlderThan: pruneEventsOlderThan,
 		registrationEntries:  registrationEntries,
 		attestedNodes:        attestedNodes,
+		bundles:              bundles,
 	}, nil
 }
 
+// OnBundleChange registers a function called with the trust domain ID of each
+// bundle changed since the last cache update, as seen in the bundle events.
+// It must be called before the update cache task starts.
+func (a *AuthorizedEntryFetcherWithEventsBasedCache) OnBundleChange(fn func(trustDomainID string)) {
+	a.bundles.onChange = append(a.bundles.onChange, fn)
+}
+
 func (a *AuthorizedEntryFetcherWithEventsBasedCache) LookupAuthorizedEntries(ctx context.Context, agentID spiffeid.ID, entryIDs map[string]struct{}) (map[string]*types.Entry, error) {
 	return a.cache.LookupAuthorizedEntries(agentID, entryIDs), nil
 }
@@ -99,23 +110,24 @@ func (a *AuthorizedEntryFetcherWithEventsBasedCache) PruneEventsTask(ctx context
 }
 
 func (a *AuthorizedEntryFetcherWithEventsBasedCache) pruneEvents(ctx context.Context, olderThan time.Duration) error {
-	pruneRegistrationEntryEventsErr := a.ds.PruneRegistrationEntryEvents(ctx, olderThan)
-	pruneAttestedNodeEventsErr := a.ds.PruneAttestedNodeEvents(ctx, olderThan)
-
-	return errors.Join(pruneRegistrationEntryEventsErr, pruneAttestedNodeEventsErr)
+	if err := a.ds.PruneEvents(ctx, a.clk.Now().Add(-olderThan)); err != nil {
+		return err
+	}
+	return compactEvents(ctx, a.log, a.ds)
 }
 
 func (a *AuthorizedEntryFetcherWithEventsBasedCache) updateCache(ctx context.Context) error {
 	updateRegistrationEntriesCacheErr := a.registrationEntries.updateCache(ctx)
 	updateAttestedNodesCacheErr := a.attestedNodes.updateCache(ctx)
+	updateBundlesCacheErr := a.bundles.updateCache(ctx)
 
-	return errors.Join(updateRegistrationEntriesCacheErr, updateAttestedNodesCacheErr)
+	return errors.Join(updateRegistrationEntriesCacheErr, updateAttestedNodesCacheErr, updateBundlesCacheErr)
 }
 
 func buildCache(ctx context.Context, log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, clk clock.Clock, cacheReloadInterval, sqlTransactionTimeout time.Duration) (*authorizedentries.Cache, *registrationEntries, *attestedNodes, error) {
 	cache := authorizedentries.NewCache(clk)
 
-	registrationEntries, err := buildRegistrationEntriesCache(ctx, log, metrics, ds, clk, cache, buildCachePageSize, cacheReloadInterval, sqlTransactionTimeout)
+	registrationEntries, err := buildRegistrationEntriesCache(ctx, log, metrics, ds, clk, cache, cacheReloadInterval, sqlTransactionTimeout)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -140,9 +140,7 @@ func (a *attestedNodes) scanForNewEvents(ctx context.Context) error {
 
 func (a *attestedNodes) loadCache(ctx context.Context) error {
 	// TODO: determine if this needs paging
-	nodesResp, err := a.ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{
-		FetchSelectors: true,
-	})
+	nodesResp, err := a.ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{})
 	if err != nil {
 		return fmt.Errorf("failed to list attested nodes: %w", err)
 	}
@@ -225,7 +223,7 @@ func (a *attestedNodes) updateCachedNodes(ctx context.Context) error {
 			continue
 		}
 
-		selectors, err := a.ds.GetNodeSelectors(ctx, spiffeId, datastore.RequireCurrent)
+		selectors, err := a.ds.GetNodeSelectors(ctx, spiffeId, datastore.RequireCurrent, false)
 		if err != nil {
 			continue
 		}
@@ -0,0 +1,55 @@
+package endpoints
+
+import (
+	"context"
+
+	"github.com/spiffe/spire/pkg/server/datastore"
+)
+
+// bundles reads the bundle events when the entry cache is updated, and
+// notifies the trust domains whose bundle changed so the bundle caches can
+// be invalidated. Unlike entries and nodes, bundles are not held by the entry
+// cache, so skipped events are not tracked: the bundle caches expire their
+// bundles after a while anyway.
+type bundles struct {
+	ds datastore.DataStore
+
+	lastEvent uint
+	onChange  []func(trustDomainID string)
+}
+
+func buildBundlesCache(ctx context.Context, ds datastore.DataStore) (*bundles, error) {
+	resp, err := ds.ListBundleEvents(ctx, &datastore.ListBundleEventsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	b := &bundles{ds: ds}
+	for _, event := range resp.Events {
+		b.lastEvent = max(b.lastEvent, event.EventID)
+	}
+	return b, nil
+}
+
+// updateCache reads the bundle events created since the last update and
+// notifies the trust domains whose bundle changed, once per update.
+func (b *bundles) updateCache(ctx context.Context) error {
+	resp, err := b.ds.ListBundleEvents(ctx, &datastore.ListBundleEventsRequest{
+		GreaterThanEventID: b.lastEvent,
+	})
+	if err != nil {
+		return err
+	}
+
+	changed := make(map[string]struct{})
+	for _, event := range resp.Events {
+		b.lastEvent = max(b.lastEvent, event.EventID)
+		changed[event.TrustDomain] = struct{}{}
+	}
+	for trustDomainID := range changed {
+		for _, fn := range b.onChange {
+			fn(trustDomainID)
+		}
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/authorizedentries"
 	"github.com/spiffe/spire/pkg/server/datastore"
+	"github.com/spiffe/spire/proto/spire/common"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
@@ -137,40 +138,29 @@ func (a *registrationEntries) scanForNewEvents(ctx context.Context) error {
 	return nil
 }
 
-func (a *registrationEntries) loadCache(ctx context.Context, pageSize int32) error {
-	// Build the cache
-	var token string
-	for {
-		resp, err := a.ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
-			DataConsistency: datastore.RequireCurrent, // preliminary loading should not be done via read-replicas
-			Pagination: &datastore.Pagination{
-				Token:    token,
-				PageSize: pageSize,
-			},
-		})
-		if err != nil {
-			return fmt.Errorf("failed to list registration entries: %w", err)
-		}
-
-		token = resp.Pagination.Token
-		if token == "" {
-			break
-		}
-
-		entries, err := api.RegistrationEntriesToProto(resp.Entries)
+func (a *registrationEntries) loadCache(ctx context.Context) error {
+	// Build the cache. Inactive entries are not used to issue SVIDs. The
+	// entries are streamed so that they are not all held in memory twice.
+	active := true
+	err := a.ds.StreamRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
+		DataConsistency: datastore.RequireCurrent, // preliminary loading should not be done via read-replicas
+		ByActive:        &active,
+	}, func(commonEntry *common.RegistrationEntry) error {
+		entry, err := api.RegistrationEntryToProto(commonEntry)
 		if err != nil {
 			return fmt.Errorf("failed to convert registration entries: %w", err)
 		}
-
-		for _, entry := range entries {
-			a.cache.UpdateEntry(entry)
-		}
+		a.cache.UpdateEntry(entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list registration entries: %w", err)
 	}
 	return nil
 }
 
 // buildRegistrationEntriesCache Fetches all registration entries and adds them to the cache
-func buildRegistrationEntriesCache(ctx context.Context, log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, clk clock.Clock, cache *authorizedentries.Cache, pageSize int32, cacheReloadInterval, sqlTransactionTimeout time.Duration) (*registrationEntries, error) {
+func buildRegistrationEntriesCache(ctx context.Context, log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.DataStore, clk clock.Clock, cache *authorizedentries.Cache, cacheReloadInterval, sqlTransactionTimeout time.Duration) (*registrationEntries, error) {
 	pollPeriods := PollPeriods(cacheReloadInterval, sqlTransactionTimeout)
 
 	registrationEntries := &registrationEntries{
@@ -199,7 +189,7 @@ func buildRegistrationEntriesCache(ctx context.Context, log logrus.FieldLogger,
 		return nil, err
 	}
 
-	if err := registrationEntries.loadCache(ctx, pageSize); err != nil {
+	if err := registrationEntries.loadCache(ctx); err != nil {
 		return nil, err
 	}
 
@@ -230,7 +220,8 @@ func (a *registrationEntries) updateCachedEntries(ctx context.Context) error {
 			continue
 		}
 
-		if commonEntry == nil {
+		// Inactive entries are not used to issue SVIDs
+		if commonEntry == nil || commonEntry.Inactive {
 			a.cache.RemoveEntry(entryId)
 			delete(a.fetchEntries, entryId)
 			continue
@@ -79,39 +79,12 @@ func TestLoadEntryCache(t *testing.T) {
 			expectedError: "any error, doesn't matter",
 		},
 		{
-			name: "loading nothing with a page size of zero raises an error",
-			setup: &entryScenarioSetup{
-				pageSize: 0,
-			},
-			expectedError: "cannot paginate with pagesize = 0",
-		},
-		{
-			name: "initial load loads nothing",
-			setup: &entryScenarioSetup{
-				pageSize: 1000,
-			},
-		},
-		{
-			name: "one registration entry with a page size of zero raises an error",
-			setup: &entryScenarioSetup{
-				pageSize: 0,
-				registrationEntries: []*common.RegistrationEntry{
-					{
-						EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
-						ParentId: "spiffe://example.org/test_node_1",
-						SpiffeId: "spiffe://example.org/test_job_1",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "1"},
-						},
-					},
-				},
-			},
-			expectedError: "cannot paginate with pagesize = 0",
+			name:  "initial load loads nothing",
+			setup: &entryScenarioSetup{},
 		},
 		{
 			name: "initial load loads one registration entry",
 			setup: &entryScenarioSetup{
-				pageSize: 1000,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
@@ -134,183 +107,9 @@ func TestLoadEntryCache(t *testing.T) {
 				{Key: entriesByParentID, Value: 1},
 			},
 		},
-		{
-			name: "five registration entries with a page size of zero raises an error",
-			setup: &entryScenarioSetup{
-				pageSize: 0,
-				registrationEntries: []*common.RegistrationEntry{
-					{
-						EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
-						ParentId: "spiffe://example.org/test_node_1",
-						SpiffeId: "spiffe://example.org/test_job_1",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "1"},
-						},
-					},
-					{
-						EntryId:  "47c96201-a4b1-4116-97fe-8aa9c2440aad",
-						ParentId: "spiffe://example.org/test_node_1",
-						SpiffeId: "spiffe://example.org/test_job_2",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "2"},
-						},
-					},
-					{
-						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_3",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "3"},
-						},
-					},
-					{
-						EntryId:  "8cbf7d48-9d43-41ae-ab63-77d66891f948",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_4",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "4"},
-						},
-					},
-					{
-						EntryId:  "354c16f4-4e61-4c17-8596-7baa7744d504",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_5",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "5"},
-						},
-					},
-				},
-			},
-			expectedError: "cannot paginate with pagesize = 0",
-		},
 		{
 			name: "initial load loads five registration entries",
 			setup: &entryScenarioSetup{
-				pageSize: 1000,
-				registrationEntries: []*common.RegistrationEntry{
-					{
-						EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
-						ParentId: "spiffe://example.org/test_node_1",
-						SpiffeId: "spiffe://example.org/test_job_1",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "1"},
-						},
-					},
-					{
-						EntryId:  "47c96201-a4b1-4116-97fe-8aa9c2440aad",
-						ParentId: "spiffe://example.org/test_node_1",
-						SpiffeId: "spiffe://example.org/test_job_2",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "2"},
-						},
-					},
-					{
-						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_3",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "3"},
-						},
-					},
-					{
-						EntryId:  "8cbf7d48-9d43-41ae-ab63-77d66891f948",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_4",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "4"},
-						},
-					},
-					{
-						EntryId:  "354c16f4-4e61-4c17-8596-7baa7744d504",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_5",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "5"},
-						},
-					},
-				},
-			},
-			expectedRegistrationEntries: []string{
-				"6837984a-bc44-462b-9ca6-5cd59be35066",
-				"47c96201-a4b1-4116-97fe-8aa9c2440aad",
-				"1d78521b-cc92-47c1-85a5-28ce47f121f2",
-				"8cbf7d48-9d43-41ae-ab63-77d66891f948",
-				"354c16f4-4e61-4c17-8596-7baa7744d504",
-			},
-			expectedGauges: []expectedGauge{
-				{Key: skippedEntryEventID, Value: 0},
-				{Key: nodeAliasesByEntryID, Value: 0},
-				{Key: nodeAliasesBySelector, Value: 0},
-				{Key: entriesByEntryID, Value: 5},
-				{Key: entriesByParentID, Value: 5},
-			},
-		},
-		{
-			name: "initial load loads five registration entries, in one page exact",
-			setup: &entryScenarioSetup{
-				pageSize: 5,
-				registrationEntries: []*common.RegistrationEntry{
-					{
-						EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
-						ParentId: "spiffe://example.org/test_node_1",
-						SpiffeId: "spiffe://example.org/test_job_1",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "1"},
-						},
-					},
-					{
-						EntryId:  "47c96201-a4b1-4116-97fe-8aa9c2440aad",
-						ParentId: "spiffe://example.org/test_node_1",
-						SpiffeId: "spiffe://example.org/test_job_2",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "2"},
-						},
-					},
-					{
-						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_3",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "3"},
-						},
-					},
-					{
-						EntryId:  "8cbf7d48-9d43-41ae-ab63-77d66891f948",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_4",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "4"},
-						},
-					},
-					{
-						EntryId:  "354c16f4-4e61-4c17-8596-7baa7744d504",
-						ParentId: "spiffe://example.org/test_node_2",
-						SpiffeId: "spiffe://example.org/test_job_5",
-						Selectors: []*common.Selector{
-							{Type: "testjob", Value: "5"},
-						},
-					},
-				},
-			},
-			expectedRegistrationEntries: []string{
-				"6837984a-bc44-462b-9ca6-5cd59be35066",
-				"47c96201-a4b1-4116-97fe-8aa9c2440aad",
-				"1d78521b-cc92-47c1-85a5-28ce47f121f2",
-				"8cbf7d48-9d43-41ae-ab63-77d66891f948",
-				"354c16f4-4e61-4c17-8596-7baa7744d504",
-			},
-			expectedGauges: []expectedGauge{
-				{Key: skippedEntryEventID, Value: 0},
-				{Key: nodeAliasesByEntryID, Value: 0},
-				{Key: nodeAliasesBySelector, Value: 0},
-				{Key: entriesByEntryID, Value: 5},
-				{Key: entriesByParentID, Value: 5},
-			},
-		},
-		{
-			name: "initial load loads five registration entries, in 2 pages",
-			setup: &entryScenarioSetup{
-				pageSize: 3,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
@@ -431,10 +230,8 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 		expectedFetches           []string
 	}{
 		{
-			name: "first event not loaded",
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			name:  "first event not loaded",
+			setup: &entryScenarioSetup{},
 
 			expectedEventsBeforeFirst: []uint{},
 			expectedFetches:           []string{},
@@ -442,7 +239,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 		{
 			name: "before first event arrived, after transaction timeout",
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -463,7 +259,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 			name: "no before first events",
 
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -476,7 +271,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 			name: "new before first event",
 
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -496,7 +290,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 			name: "new after last event",
 
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -514,7 +307,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 			name: "previously seen before first event",
 
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -533,7 +325,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 			name: "previously seen before first event and after last event",
 
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -556,7 +347,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 			name: "five new before first events",
 
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -596,7 +386,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 			name: "five new before first events, one after last event",
 
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -634,7 +423,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 		{
 			name: "five before first events, two previously seen",
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -673,7 +461,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 		{
 			name: "five before first events, two previously seen, one after last event",
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -710,7 +497,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 		{
 			name: "five before first events, five previously seen",
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -745,7 +531,6 @@ func TestSearchBeforeFirstEntryEvent(t *testing.T) {
 		{
 			name: "five before first events, five previously seen, with after last event",
 			setup: &entryScenarioSetup{
-				pageSize:                1024,
 				registrationEntries:     defaultRegistrationEntries,
 				registrationEntryEvents: defaultRegistrationEntryEventsStartingAt60,
 			},
@@ -827,14 +612,11 @@ func TestSelectedPolledEntryEvents(t *testing.T) {
 		{
 			name:   "nothing after to poll, no action taken, no events",
 			events: []*datastore.RegistrationEntryEvent{},
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			setup:  &entryScenarioSetup{},
 		},
 		{
 			name: "nothing to poll, no action take, one event",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 100,
@@ -846,7 +628,6 @@ func TestSelectedPolledEntryEvents(t *testing.T) {
 		{
 			name: "nothing to poll, no action taken, five events",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -874,7 +655,6 @@ func TestSelectedPolledEntryEvents(t *testing.T) {
 		{
 			name: "polling one item, not found",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -899,7 +679,6 @@ func TestSelectedPolledEntryEvents(t *testing.T) {
 		{
 			name: "polling five items, not found",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -916,7 +695,6 @@ func TestSelectedPolledEntryEvents(t *testing.T) {
 		{
 			name: "polling one item, found",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -941,7 +719,6 @@ func TestSelectedPolledEntryEvents(t *testing.T) {
 		{
 			name: "polling five items, two found",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -971,7 +748,6 @@ func TestSelectedPolledEntryEvents(t *testing.T) {
 		{
 			name: "polling five items, five found",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1043,10 +819,8 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		expectedFetches       []string
 	}{
 		{
-			name: "no new events, no first event",
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			name:  "no new events, no first event",
+			setup: &entryScenarioSetup{},
 
 			expectedTrackedEvents: []uint{},
 			expectedFetches:       []string{},
@@ -1054,7 +828,6 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		{
 			name: "no new event, with first event",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1069,7 +842,6 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		{
 			name: "one new event",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1092,7 +864,6 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		{
 			name: "one new event, skipping an event",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1115,7 +886,6 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		{
 			name: "two new events, same registered event",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1142,7 +912,6 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		{
 			name: "two new events, different attested entries",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1170,7 +939,6 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		{
 			name: "two new events, with a skipped event",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1198,7 +966,6 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		{
 			name: "two new events, with three skipped events",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1226,7 +993,6 @@ func TestScanForNewEntryEvents(t *testing.T) {
 		{
 			name: "five events, four new events, two skip regions",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntryEvents: []*datastore.RegistrationEntryEvent{
 					{
 						EventID: 101,
@@ -1302,24 +1068,21 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		setup                     *entryScenarioSetup
 		createRegistrationEntries []*common.RegistrationEntry // Entries created after setup
 		deleteRegistrationEntries []string                    // Entries deleted after setup
+		deactivateEntries         []string                    // Entries deactivated after setup
 		fetchEntries              []string
 
 		expectedAuthorizedEntries []string
 	}{
 		{
-			name: "empty cache, no fetch entries",
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			name:         "empty cache, no fetch entries",
+			setup:        &entryScenarioSetup{},
 			fetchEntries: []string{},
 
 			expectedAuthorizedEntries: []string{},
 		},
 		{
-			name: "empty cache, fetch one entry, as a new entry",
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			name:  "empty cache, fetch one entry, as a new entry",
+			setup: &entryScenarioSetup{},
 			createRegistrationEntries: []*common.RegistrationEntry{
 				{
 					EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1339,19 +1102,15 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 			},
 		},
 		{
-			name: "empty cache, fetch one entry, as a delete",
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			name:  "empty cache, fetch one entry, as a delete",
+			setup: &entryScenarioSetup{},
 			fetchEntries: []string{
 				"1d78521b-cc92-47c1-85a5-28ce47f121f2",
 			},
 		},
 		{
-			name: "empty cache, fetch five entries, all new entries",
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			name:  "empty cache, fetch five entries, all new entries",
+			setup: &entryScenarioSetup{},
 			createRegistrationEntries: []*common.RegistrationEntry{
 				{
 					EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
@@ -1411,10 +1170,8 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 			},
 		},
 		{
-			name: "empty cache, fetch five entries, three new and two deletes",
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			name:  "empty cache, fetch five entries, three new and two deletes",
+			setup: &entryScenarioSetup{},
 			createRegistrationEntries: []*common.RegistrationEntry{
 				{
 					EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
@@ -1456,10 +1213,8 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 			},
 		},
 		{
-			name: "empty cache, fetch five entries, all deletes",
-			setup: &entryScenarioSetup{
-				pageSize: 1024,
-			},
+			name:  "empty cache, fetch five entries, all deletes",
+			setup: &entryScenarioSetup{},
 			fetchEntries: []string{
 				"6837984a-bc44-462b-9ca6-5cd59be35066",
 				"47c96201-a4b1-4116-97fe-8aa9c2440aad",
@@ -1473,7 +1228,6 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		{
 			name: "one entry in cache, no fetch entries",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1493,7 +1247,6 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		{
 			name: "one entry in cache, fetch one entry, as new entry",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1527,7 +1280,6 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		{
 			name: "one entry in cache, fetch one entry, as an update",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1550,7 +1302,6 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		{
 			name: "one entry in cache, fetch one entry, as a delete",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1574,7 +1325,6 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		{
 			name: "one entry in cache, fetch five entries, all new entries",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1648,7 +1398,6 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		{
 			name: "one entry in cache, fetch five entries, four new entries and one update",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1713,7 +1462,6 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		{
 			name: "one entry in cache, fetch five entries, two new and three deletes",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1762,7 +1510,6 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 		{
 			name: "one entry in cache, fetch five entries, all deletes",
 			setup: &entryScenarioSetup{
-				pageSize: 1024,
 				registrationEntries: []*common.RegistrationEntry{
 					{
 						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
@@ -1787,6 +1534,39 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 
 			expectedAuthorizedEntries: []string{},
 		},
+		{
+			name: "two entries in cache, fetch one entry, as a deactivation",
+			setup: &entryScenarioSetup{
+				registrationEntries: []*common.RegistrationEntry{
+					{
+						EntryId:  "1d78521b-cc92-47c1-85a5-28ce47f121f2",
+						ParentId: "spiffe://example.org/test_node_2",
+						SpiffeId: "spiffe://example.org/test_job_3",
+						Selectors: []*common.Selector{
+							{Type: "testjob", Value: "3"},
+						},
+					},
+					{
+						EntryId:  "6837984a-bc44-462b-9ca6-5cd59be35066",
+						ParentId: "spiffe://example.org/test_node_1",
+						SpiffeId: "spiffe://example.org/test_job_1",
+						Selectors: []*common.Selector{
+							{Type: "testjob", Value: "1"},
+						},
+					},
+				},
+			},
+			deactivateEntries: []string{
+				"1d78521b-cc92-47c1-85a5-28ce47f121f2",
+			},
+			fetchEntries: []string{
+				"1d78521b-cc92-47c1-85a5-28ce47f121f2",
+			},
+
+			expectedAuthorizedEntries: []string{
+				"6837984a-bc44-462b-9ca6-5cd59be35066",
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			scenario := NewEntryScenario(t, tt.setup)
@@ -1800,6 +1580,10 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 				_, err = scenario.ds.DeleteRegistrationEntry(scenario.ctx, registrationEntry)
 				require.NoError(t, err, "error while setting up test")
 			}
+			for _, registrationEntry := range tt.deactivateEntries {
+				_, err = scenario.ds.SetRegistrationEntryActive(scenario.ctx, registrationEntry, false)
+				require.NoError(t, err, "error while setting up test")
+			}
 			for _, fetchEntry := range tt.fetchEntries {
 				registeredEntries.fetchEntries[fetchEntry] = struct{}{}
 			}
@@ -1825,14 +1609,13 @@ func TestUpdateRegistrationEntriesCache(t *testing.T) {
 }
 
 type entryScenario struct {
-	ctx      context.Context
-	log      *logrus.Logger
-	hook     *test.Hook
-	clk      *clock.Mock
-	cache    *authorizedentries.Cache
-	metrics  *fakemetrics.FakeMetrics
-	ds       *fakedatastore.DataStore
-	pageSize int32
+	ctx     context.Context
+	log     *logrus.Logger
+	hook    *test.Hook
+	clk     *clock.Mock
+	cache   *authorizedentries.Cache
+	metrics *fakemetrics.FakeMetrics
+	ds      *fakedatastore.DataStore
 }
 
 type entryScenarioSetup struct {
@@ -1841,7 +1624,6 @@ type entryScenarioSetup struct {
 	registrationEntries     []*common.RegistrationEntry
 	registrationEntryEvents []*datastore.RegistrationEntryEvent
 	err                     error
-	pageSize                int32
 }
 
 func NewEntryScenario(t *testing.T, setup *entryScenarioSetup) *entryScenario {
@@ -1892,19 +1674,18 @@ func NewEntryScenario(t *testing.T, setup *entryScenarioSetup) *entryScenario {
 	}
 
 	return &entryScenario{
-		ctx:      ctx,
-		log:      log,
-		hook:     hook,
-		clk:      clk,
-		cache:    cache,
-		metrics:  metrics,
-		ds:       ds,
-		pageSize: setup.pageSize,
+		ctx:     ctx,
+		log:     log,
+		hook:    hook,
+		clk:     clk,
+		cache:   cache,
+		metrics: metrics,
+		ds:      ds,
 	}
 }
 
 func (s *entryScenario) buildRegistrationEntriesCache() (*registrationEntries, error) {
-	registrationEntries, err := buildRegistrationEntriesCache(s.ctx, s.log, s.metrics, s.ds, s.clk, s.cache, s.pageSize, defaultCacheReloadInterval, defaultSQLTransactionTimeout)
+	registrationEntries, err := buildRegistrationEntriesCache(s.ctx, s.log, s.metrics, s.ds, s.clk, s.cache, defaultCacheReloadInterval, defaultSQLTransactionTimeout)
 	if registrationEntries != nil {
 		// clear out the fetches
 		for entry := range registrationEntries.fetchEntries {
@@ -126,6 +126,98 @@ func TestNewAuthorizedEntryFetcherWithEventsBasedCache(t *testing.T) {
 	assert.ElementsMatch(t, expectedMetrics, metrics.AllMetrics(), "should emit metrics for node aliases, entries, and agents")
 }
 
+func TestAuthorizedEntryFetcherWithEventsBasedCacheResolvesFederatesWithAll(t *testing.T) {
+	ctx := context.Background()
+	log, _ := test.NewNullLogger()
+	clk := clock.NewMock(t)
+	ds := fakedatastore.New(t)
+
+	agentID := spiffeid.RequireFromString("spiffe://example.org/myagent")
+	_, err := ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:     agentID.String(),
+		CertNotAfter: time.Now().Add(5 * time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+
+	createBundle := func(trustDomainID string) {
+		_, err := ds.CreateBundle(ctx, &common.Bundle{
+			TrustDomainId: trustDomainID,
+			RootCas:       []*common.Certificate{{DerBytes: []byte("cert")}},
+		})
+		require.NoError(t, err)
+	}
+	createBundle("spiffe://example.org")
+	createBundle("spiffe://domain1.org")
+
+	_, err = ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		SpiffeId:         "spiffe://example.org/workload",
+		ParentId:         agentID.String(),
+		Selectors:        []*common.Selector{{Type: "workload", Value: "one"}},
+		FederatesWithAll: true,
+	})
+	require.NoError(t, err)
+
+	ef, err := NewAuthorizedEntryFetcherWithEventsBasedCache(ctx, log, fakemetrics.New(), clk, ds, defaultCacheReloadInterval, defaultPruneEventsOlderThan, defaultSQLTransactionTimeout)
+	require.NoError(t, err)
+
+	requireFederatesWith := func(expected []string) {
+		entries, err := ef.FetchAuthorizedEntries(ctx, agentID)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, expected, entries[0].FederatesWith)
+	}
+	require.NoError(t, ef.updateCache(ctx))
+	requireFederatesWith([]string{"domain1.org"})
+
+	// A trust domain federated after the entry was created is picked up
+	// without the entry being updated
+	createBundle("spiffe://domain2.org")
+	require.NoError(t, ef.updateCache(ctx))
+	requireFederatesWith([]string{"domain1.org", "domain2.org"})
+}
+
+func TestAuthorizedEntryFetcherWithEventsBasedCacheNotifiesBundleChanges(t *testing.T) {
+	ctx := context.Background()
+	log, _ := test.NewNullLogger()
+	clk := clock.NewMock(t)
+	ds := fakedatastore.New(t)
+
+	setBundle := func(trustDomainID string) {
+		_, err := ds.SetBundle(ctx, &common.Bundle{
+			TrustDomainId: trustDomainID,
+			RootCas:       []*common.Certificate{{DerBytes: []byte("cert")}},
+		})
+		require.NoError(t, err)
+	}
+	setBundle("spiffe://example.org")
+
+	ef, err := NewAuthorizedEntryFetcherWithEventsBasedCache(ctx, log, fakemetrics.New(), clk, ds, defaultCacheReloadInterval, defaultPruneEventsOlderThan, defaultSQLTransactionTimeout)
+	require.NoError(t, err)
+
+	var changed []string
+	ef.OnBundleChange(func(trustDomainID string) {
+		changed = append(changed, trustDomainID)
+	})
+
+	// Bundle events created before the cache was built are not notified
+	require.NoError(t, ef.updateCache(ctx))
+	require.Empty(t, changed)
+
+	// Each changed bundle is notified once per update
+	setBundle("spiffe://domain1.org")
+	setBundle("spiffe://domain1.org")
+	require.NoError(t, ef.updateCache(ctx))
+	require.Equal(t, []string{"spiffe://domain1.org"}, changed)
+
+	changed = nil
+	require.NoError(t, ef.updateCache(ctx))
+	require.Empty(t, changed)
+
+	require.NoError(t, ds.DeleteBundle(ctx, "spiffe://domain1.org", datastore.Restrict))
+	require.NoError(t, ef.updateCache(ctx))
+	require.Equal(t, []string{"spiffe://domain1.org"}, changed)
+}
+
 func TestNewAuthorizedEntryFetcherWithEventsBasedCacheErrorBuildingCache(t *testing.T) {
 	ctx := context.Background()
 	log, _ := test.NewNullLogger()
@@ -272,6 +364,61 @@ func TestRunUpdateCacheTaskPrunesExpiredAgents(t *testing.T) {
 	require.ErrorIs(t, err, context.Canceled)
 }
 
+func TestPruneEventsCompactsEvents(t *testing.T) {
+	ctx := context.Background()
+	log, hook := test.NewNullLogger()
+	log.SetLevel(logrus.DebugLevel)
+	clk := clock.NewMock(t)
+	ds := fakedatastore.New(t)
+
+	ef, err := NewAuthorizedEntryFetcherWithEventsBasedCache(ctx, log, fakemetrics.New(), clk, ds, defaultCacheReloadInterval, defaultPruneEventsOlderThan, defaultSQLTransactionTimeout)
+	require.NoError(t, err)
+
+	node, err := ds.CreateAttestedNode(ctx, &common.AttestedNode{
+		SpiffeId:     "spiffe://example.org/myagent",
+		CertNotAfter: clk.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	entry, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		SpiffeId:  "spiffe://example.org/workload",
+		ParentId:  node.SpiffeId,
+		Selectors: []*common.Selector{{Type: "workload", Value: "one"}},
+	})
+	require.NoError(t, err)
+	for i := range 2 {
+		entry.Admin = i == 0
+		_, err = ds.UpdateRegistrationEntry(ctx, entry, &common.RegistrationEntryMask{Admin: true})
+		require.NoError(t, err)
+		node.CertNotAfter++
+		_, err = ds.UpdateAttestedNode(ctx, node, &common.AttestedNodeMask{CertNotAfter: true})
+		require.NoError(t, err)
+	}
+
+	entryEvents, err := ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	require.NoError(t, err)
+	require.Len(t, entryEvents.Events, 3)
+	nodeEvents, err := ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	require.NoError(t, err)
+	require.Len(t, nodeEvents.Events, 3)
+
+	// The events are too recent to be pruned, but the superseded ones are
+	// compacted.
+	require.NoError(t, ef.pruneEvents(ctx, defaultPruneEventsOlderThan))
+
+	resp, err := ds.ListRegistrationEntryEvents(ctx, &datastore.ListRegistrationEntryEventsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, entryEvents.Events[2:], resp.Events)
+	nodeResp, err := ds.ListAttestedNodeEvents(ctx, &datastore.ListAttestedNodeEventsRequest{})
+	require.NoError(t, err)
+	require.Equal(t, nodeEvents.Events[2:], nodeResp.Events)
+
+	require.Equal(t, "Compacted events", hook.LastEntry().Message)
+	require.Equal(t, logrus.Fields{
+		telemetry.RegistrationEntryEvent: 2,
+		telemetry.NodeEvent:              2,
+	}, hook.LastEntry().Data)
+}
+
 func TestUpdateRegistrationEntriesCacheSkippedEvents(t *testing.T) {
 	ctx := context.Background()
 	log, _ := test.NewNullLogger()
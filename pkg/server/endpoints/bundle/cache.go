@@ -18,6 +18,11 @@ import (
 
 const (
 	cacheExpiry = time.Second
+
+	// eventsBasedCacheExpiry is how long a bundle is kept by a cache that is
+	// invalidated from the bundle events. It bounds how long a change is
+	// missed if its event is skipped.
+	eventsBasedCacheExpiry = time.Minute
 )
 
 type Cache struct {
@@ -25,13 +30,27 @@ type Cache struct {
 	bundlesMtx sync.Mutex
 	bundles    map[spiffeid.TrustDomain]*bundleEntry
 	clock      clock.Clock
+	expiry     time.Duration
 }
 
+// NewCache returns a cache that reloads the bundles every second.
 func NewCache(ds datastore.DataStore, clk clock.Clock) *Cache {
+	return newCache(ds, clk, cacheExpiry)
+}
+
+// NewEventsBasedCache returns a cache that reloads a bundle once
+// InvalidateBundle is called for its trust domain, which the events-based
+// entry cache does when it reads a bundle event, or once a minute.
+func NewEventsBasedCache(ds datastore.DataStore, clk clock.Clock) *Cache {
+	return newCache(ds, clk, eventsBasedCacheExpiry)
+}
+
+func newCache(ds datastore.DataStore, clk clock.Clock, expiry time.Duration) *Cache {
 	return &Cache{
 		ds:      ds,
 		clock:   clk,
 		bundles: make(map[spiffeid.TrustDomain]*bundleEntry),
+		expiry:  expiry,
 	}
 }
 
@@ -53,7 +72,7 @@ func (c *Cache) FetchBundleX509(ctx context.Context, td spiffeid.TrustDomain) (*
 
 	entry.mu.Lock()
 	defer entry.mu.Unlock()
-	if entry.ts.IsZero() || c.clock.Now().Sub(entry.ts) >= cacheExpiry {
+	if entry.ts.IsZero() || c.clock.Now().Sub(entry.ts) >= c.expiry {
 		bundle, err := c.ds.FetchBundle(ctx, td.IDString())
 		if err != nil {
 			return nil, err
@@ -77,6 +96,16 @@ func (c *Cache) FetchBundleX509(ctx context.Context, td spiffeid.TrustDomain) (*
 	return entry.x509Bundle, nil
 }
 
+// InvalidateBundle makes the next fetch of the bundle of the given trust
+// domain reload it from the datastore.
+func (c *Cache) InvalidateBundle(trustDomainID string) {
+	td, err := spiffeid.TrustDomainFromString(trustDomainID)
+	if err != nil {
+		return
+	}
+	c.deleteEntry(td)
+}
+
 func (c *Cache) deleteEntry(td spiffeid.TrustDomain) {
 	c.bundlesMtx.Lock()
 	delete(c.bundles, td)
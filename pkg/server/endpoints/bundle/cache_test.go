@@ -2,6 +2,7 @@ package bundle
 
 import (
 	"context"
+	"crypto/x509"
 	"testing"
 
 	"github.com/spiffe/spire/test/clock"
@@ -66,3 +67,47 @@ func TestFetchBundleX509(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, updatedBundleX509Response, bundleX509)
 }
+
+func TestFetchBundleX509EventsBasedCache(t *testing.T) {
+	td := spiffeid.RequireTrustDomainFromString("spiffe://domain.test")
+	ca := testca.New(t, td)
+	certs1, _ := ca.CreateX509Certificate()
+	certs2, _ := ca.CreateX509Certificate()
+	certs3, _ := ca.CreateX509Certificate()
+
+	ds := fakedatastore.New(t)
+	clock := clock.NewMock(t)
+	cache := NewEventsBasedCache(ds, clock)
+	ctx := context.Background()
+
+	setBundle := func(certs []*x509.Certificate) {
+		_, err := ds.SetBundle(ctx, &common.Bundle{TrustDomainId: "spiffe://domain.test", RootCas: []*common.Certificate{{DerBytes: certs[0].Raw}}})
+		require.NoError(t, err)
+	}
+	requireBundle := func(certs []*x509.Certificate) {
+		bundleX509, err := cache.FetchBundleX509(ctx, td)
+		require.NoError(t, err)
+		assert.Equal(t, x509bundle.FromX509Authorities(td, certs), bundleX509)
+	}
+
+	setBundle(certs1)
+	requireBundle(certs1)
+
+	// The bundle is kept past the expiry of the regular cache
+	setBundle(certs2)
+	clock.Add(cacheExpiry)
+	requireBundle(certs1)
+
+	// Invalidating the bundle reloads it
+	cache.InvalidateBundle("spiffe://domain.test")
+	requireBundle(certs2)
+
+	// Invalid trust domains are ignored
+	cache.InvalidateBundle("not a trust domain")
+	requireBundle(certs2)
+
+	// The bundle is still reloaded once it expires
+	setBundle(certs3)
+	clock.Add(eventsBasedCacheExpiry)
+	requireBundle(certs3)
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/ca/manager"
 	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/catalog"
+	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/svid"
 )
@@ -109,11 +110,40 @@ type Config struct {
 	// back to the default X509 CA TTL).
 	UseLegacyDownstreamX509CATTL bool
 
+	// X509SVIDTTL and JWTSVIDTTL are the default TTLs of the SVIDs of entries
+	// that don't set their own, and CATTL is the TTL of the server CA. They
+	// are used to report the effective SVID TTLs of created entries. Zero
+	// values mean the built-in defaults.
+	X509SVIDTTL time.Duration
+	JWTSVIDTTL  time.Duration
+	CATTL       time.Duration
+
+	// EntryIssuanceMetrics enables a counter of the SVIDs signed for each
+	// registration entry, emitted for the sample of the signings given by
+	// EntryIssuanceMetricsSampleRate.
+	EntryIssuanceMetrics           bool
+	EntryIssuanceMetricsSampleRate float64
+
+	// PruneAttestedNodesExpiredFor is how long ago attested nodes must have
+	// expired for the server to prune them. It is used to report the agents
+	// to prune. Zero means attested nodes are not pruned.
+	PruneAttestedNodesExpiredFor time.Duration
+
 	// TLSPolicy determines the post-quantum-safe policy used for all TLS
 	// connections.
 	TLSPolicy tlspolicy.Policy
 }
 
+// maxSVIDTTL returns the maximum SVID TTL the server CA guarantees given its
+// TTL.
+func (c *Config) maxSVIDTTL() time.Duration {
+	caTTL := c.CATTL
+	if caTTL == 0 {
+		caTTL = credtemplate.DefaultX509CATTL
+	}
+	return manager.MaxSVIDTTLForCATTL(caTTL)
+}
+
 func (c *Config) maybeMakeBundleEndpointServer() (Server, func(context.Context) error) {
 	if c.BundleEndpoint.Address == nil {
 		return nil, nil
@@ -161,20 +191,40 @@ func (c *Config) maybeMakeBundleEndpointServer() (Server, func(context.Context)
 func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServers {
 	ds := c.Catalog.GetDataStore()
 	upstreamPublisher := UpstreamPublisher(c.AuthorityManager)
+	agentServer := agentv1.New(agentv1.Config{
+		DataStore:                    ds,
+		ServerCA:                     c.ServerCA,
+		TrustDomain:                  c.TrustDomain,
+		Catalog:                      c.Catalog,
+		Clock:                        c.Clock,
+		PruneAttestedNodesExpiredFor: c.PruneAttestedNodesExpiredFor,
+	})
+	entryServer := entryv1.New(entryv1.Config{
+		TrustDomain:        c.TrustDomain,
+		DataStore:          ds,
+		EntryFetcher:       entryFetcher,
+		DefaultX509SVIDTTL: c.X509SVIDTTL,
+		DefaultJWTSVIDTTL:  c.JWTSVIDTTL,
+		MaxSVIDTTL:         c.maxSVIDTTL(),
+		Clock:              c.Clock,
+	})
+	bundleServer := bundlev1.New(bundlev1.Config{
+		TrustDomain:       c.TrustDomain,
+		DataStore:         ds,
+		UpstreamPublisher: upstreamPublisher,
+		Clock:             c.Clock,
+	})
+	trustDomainServer := trustdomainv1.New(trustdomainv1.Config{
+		TrustDomain:     c.TrustDomain,
+		DataStore:       ds,
+		BundleRefresher: c.BundleManager,
+	})
 
 	return APIServers{
-		AgentServer: agentv1.New(agentv1.Config{
-			DataStore:   ds,
-			ServerCA:    c.ServerCA,
-			TrustDomain: c.TrustDomain,
-			Catalog:     c.Catalog,
-			Clock:       c.Clock,
-		}),
-		BundleServer: bundlev1.New(bundlev1.Config{
-			TrustDomain:       c.TrustDomain,
-			DataStore:         ds,
-			UpstreamPublisher: upstreamPublisher,
-		}),
+		AgentServer:           agentServer,
+		AgentExtensionServer:  agentServer,
+		BundleServer:          bundleServer,
+		BundleExtensionServer: bundleServer,
 		DebugServer: debugv1.New(debugv1.Config{
 			TrustDomain:  c.TrustDomain,
 			Clock:        c.Clock,
@@ -182,11 +232,8 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			SVIDObserver: c.SVIDObserver,
 			Uptime:       c.Uptime,
 		}),
-		EntryServer: entryv1.New(entryv1.Config{
-			TrustDomain:  c.TrustDomain,
-			DataStore:    ds,
-			EntryFetcher: entryFetcher,
-		}),
+		EntryServer:          entryServer,
+		EntryExtensionServer: entryServer,
 		HealthServer: healthv1.New(healthv1.Config{
 			TrustDomain: c.TrustDomain,
 			DataStore:   ds,
@@ -195,17 +242,17 @@ func (c *Config) makeAPIServers(entryFetcher api.AuthorizedEntryFetcher) APIServ
 			Log: c.RootLog,
 		}),
 		SVIDServer: svidv1.New(svidv1.Config{
-			TrustDomain:                  c.TrustDomain,
-			EntryFetcher:                 entryFetcher,
-			ServerCA:                     c.ServerCA,
-			DataStore:                    ds,
-			UseLegacyDownstreamX509CATTL: c.UseLegacyDownstreamX509CATTL,
-		}),
-		TrustDomainServer: trustdomainv1.New(trustdomainv1.Config{
-			TrustDomain:     c.TrustDomain,
-			DataStore:       ds,
-			BundleRefresher: c.BundleManager,
+			TrustDomain:                    c.TrustDomain,
+			EntryFetcher:                   entryFetcher,
+			ServerCA:                       c.ServerCA,
+			DataStore:                      ds,
+			UseLegacyDownstreamX509CATTL:   c.UseLegacyDownstreamX509CATTL,
+			Metrics:                        c.Metrics,
+			EntryIssuanceMetrics:           c.EntryIssuanceMetrics,
+			EntryIssuanceMetricsSampleRate: c.EntryIssuanceMetricsSampleRate,
 		}),
+		TrustDomainServer:          trustDomainServer,
+		TrustDomainExtensionServer: trustDomainServer,
 		LocalAUthorityServer: localauthorityv1.New(localauthorityv1.Config{
 			TrustDomain: c.TrustDomain,
 			CAManager:   c.AuthorityManager,
@@ -39,6 +39,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/svid"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 )
 
 const (
@@ -89,15 +90,19 @@ type Endpoints struct {
 }
 
 type APIServers struct {
-	AgentServer          agentv1.AgentServer
-	BundleServer         bundlev1.BundleServer
-	DebugServer          debugv1_pb.DebugServer
-	EntryServer          entryv1.EntryServer
-	HealthServer         grpc_health_v1.HealthServer
-	LoggerServer         loggerv1.LoggerServer
-	SVIDServer           svidv1.SVIDServer
-	TrustDomainServer    trustdomainv1.TrustDomainServer
-	LocalAUthorityServer localauthorityv1.LocalAuthorityServer
+	AgentServer                agentv1.AgentServer
+	AgentExtensionServer       extensionv1.AgentExtensionServer
+	BundleServer               bundlev1.BundleServer
+	BundleExtensionServer      extensionv1.BundleExtensionServer
+	DebugServer                debugv1_pb.DebugServer
+	EntryServer                entryv1.EntryServer
+	EntryExtensionServer       extensionv1.EntryExtensionServer
+	HealthServer               grpc_health_v1.HealthServer
+	LoggerServer               loggerv1.LoggerServer
+	SVIDServer                 svidv1.SVIDServer
+	TrustDomainServer          trustdomainv1.TrustDomainServer
+	TrustDomainExtensionServer extensionv1.TrustDomainExtensionServer
+	LocalAUthorityServer       localauthorityv1.LocalAuthorityServer
 }
 
 // RateLimitConfig holds rate limiting configurations.
@@ -134,11 +139,16 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 
 	var ef api.AuthorizedEntryFetcher
 	var cacheRebuildTask, pruneEventsTask func(context.Context) error
+	var bundleCache *bundle.Cache
 	if c.EventsBasedCache {
 		efEventsBasedCache, err := NewAuthorizedEntryFetcherWithEventsBasedCache(ctx, c.Log, c.Metrics, c.Clock, ds, c.CacheReloadInterval, c.PruneEventsOlderThan, c.SQLTransactionTimeout)
 		if err != nil {
 			return nil, err
 		}
+		// The bundles used to authenticate callers are reloaded when the
+		// entry cache update reads a bundle event for them.
+		bundleCache = bundle.NewEventsBasedCache(ds, c.Clock)
+		efEventsBasedCache.OnBundleChange(bundleCache.InvalidateBundle)
 		cacheRebuildTask = efEventsBasedCache.RunUpdateCacheTask
 		pruneEventsTask = efEventsBasedCache.PruneEventsTask
 		ef = efEventsBasedCache
@@ -156,6 +166,7 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 		cacheRebuildTask = efFullCache.RunRebuildCacheTask
 		pruneEventsTask = efFullCache.PruneEventsTask
 		ef = efFullCache
+		bundleCache = bundle.NewCache(ds, c.Clock)
 	}
 
 	bundleEndpointServer, certificateReloadTask := c.maybeMakeBundleEndpointServer()
@@ -166,7 +177,7 @@ func New(ctx context.Context, c Config) (*Endpoints, error) {
 		SVIDObserver:                 c.SVIDObserver,
 		TrustDomain:                  c.TrustDomain,
 		DataStore:                    ds,
-		BundleCache:                  bundle.NewCache(ds, c.Clock),
+		BundleCache:                  bundleCache,
 		APIServers:                   c.makeAPIServers(ef),
 		BundleEndpointServer:         bundleEndpointServer,
 		Log:                          c.Log,
@@ -196,14 +207,22 @@ func (e *Endpoints) ListenAndServe(ctx context.Context) error {
 	// TCP and UDS
 	agentv1.RegisterAgentServer(tcpServer, e.APIServers.AgentServer)
 	agentv1.RegisterAgentServer(udsServer, e.APIServers.AgentServer)
+	extensionv1.RegisterAgentExtensionServer(tcpServer, e.APIServers.AgentExtensionServer)
+	extensionv1.RegisterAgentExtensionServer(udsServer, e.APIServers.AgentExtensionServer)
 	bundlev1.RegisterBundleServer(tcpServer, e.APIServers.BundleServer)
 	bundlev1.RegisterBundleServer(udsServer, e.APIServers.BundleServer)
+	extensionv1.RegisterBundleExtensionServer(tcpServer, e.APIServers.BundleExtensionServer)
+	extensionv1.RegisterBundleExtensionServer(udsServer, e.APIServers.BundleExtensionServer)
 	entryv1.RegisterEntryServer(tcpServer, e.APIServers.EntryServer)
 	entryv1.RegisterEntryServer(udsServer, e.APIServers.EntryServer)
+	extensionv1.RegisterEntryExtensionServer(tcpServer, e.APIServers.EntryExtensionServer)
+	extensionv1.RegisterEntryExtensionServer(udsServer, e.APIServers.EntryExtensionServer)
 	svidv1.RegisterSVIDServer(tcpServer, e.APIServers.SVIDServer)
 	svidv1.RegisterSVIDServer(udsServer, e.APIServers.SVIDServer)
 	trustdomainv1.RegisterTrustDomainServer(tcpServer, e.APIServers.TrustDomainServer)
 	trustdomainv1.RegisterTrustDomainServer(udsServer, e.APIServers.TrustDomainServer)
+	extensionv1.RegisterTrustDomainExtensionServer(tcpServer, e.APIServers.TrustDomainExtensionServer)
+	extensionv1.RegisterTrustDomainExtensionServer(udsServer, e.APIServers.TrustDomainExtensionServer)
 	localauthorityv1.RegisterLocalAuthorityServer(tcpServer, e.APIServers.LocalAUthorityServer)
 	localauthorityv1.RegisterLocalAuthorityServer(udsServer, e.APIServers.LocalAUthorityServer)
 
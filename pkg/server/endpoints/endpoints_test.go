@@ -32,6 +32,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/pkg/server/endpoints/bundle"
 	"github.com/spiffe/spire/pkg/server/svid"
+	extensionv1 "github.com/spiffe/spire/proto/spire/api/server/extension/v1"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
@@ -113,12 +114,16 @@ func TestNew(t *testing.T) {
 	assert.Equal(t, svidObserver, endpoints.SVIDObserver)
 	assert.Equal(t, testTD, endpoints.TrustDomain)
 	assert.NotNil(t, endpoints.APIServers.AgentServer)
+	assert.NotNil(t, endpoints.APIServers.AgentExtensionServer)
 	assert.NotNil(t, endpoints.APIServers.BundleServer)
+	assert.NotNil(t, endpoints.APIServers.BundleExtensionServer)
 	assert.NotNil(t, endpoints.APIServers.DebugServer)
 	assert.NotNil(t, endpoints.APIServers.EntryServer)
+	assert.NotNil(t, endpoints.APIServers.EntryExtensionServer)
 	assert.NotNil(t, endpoints.APIServers.HealthServer)
 	assert.NotNil(t, endpoints.APIServers.LoggerServer)
 	assert.NotNil(t, endpoints.APIServers.SVIDServer)
+	assert.NotNil(t, endpoints.APIServers.TrustDomainExtensionServer)
 	assert.NotNil(t, endpoints.BundleEndpointServer)
 	assert.NotNil(t, endpoints.APIServers.LocalAUthorityServer)
 	assert.NotNil(t, endpoints.EntryFetcherPruneEventsTask)
@@ -212,15 +217,19 @@ func TestListenAndServe(t *testing.T) {
 		DataStore:    ds,
 		BundleCache:  bundle.NewCache(ds, clk),
 		APIServers: APIServers{
-			AgentServer:          agentServer{},
-			BundleServer:         bundleServer{},
-			DebugServer:          debugServer{},
-			EntryServer:          entryServer{},
-			HealthServer:         healthServer{},
-			LoggerServer:         loggerServer{},
-			SVIDServer:           svidServer{},
-			TrustDomainServer:    trustDomainServer{},
-			LocalAUthorityServer: localAuthorityServer{},
+			AgentServer:                agentServer{},
+			AgentExtensionServer:       agentExtensionServer{},
+			BundleServer:               bundleServer{},
+			BundleExtensionServer:      bundleExtensionServer{},
+			DebugServer:                debugServer{},
+			EntryServer:                entryServer{},
+			EntryExtensionServer:       entryExtensionServer{},
+			HealthServer:               healthServer{},
+			LoggerServer:               loggerServer{},
+			SVIDServer:                 svidServer{},
+			TrustDomainServer:          trustDomainServer{},
+			TrustDomainExtensionServer: trustDomainExtensionServer{},
+			LocalAUthorityServer:       localAuthorityServer{},
 		},
 		BundleEndpointServer:         bundleEndpointServer,
 		Log:                          log,
@@ -323,6 +332,9 @@ func TestListenAndServe(t *testing.T) {
 	t.Run("Agent", func(t *testing.T) {
 		testAgentAPI(ctx, t, conns)
 	})
+	t.Run("AgentExtension", func(t *testing.T) {
+		testAgentExtensionAPI(ctx, t, conns)
+	})
 	t.Run("Debug", func(t *testing.T) {
 		testDebugAPI(ctx, t, conns)
 	})
@@ -335,15 +347,24 @@ func TestListenAndServe(t *testing.T) {
 	t.Run("Bundle", func(t *testing.T) {
 		testBundleAPI(ctx, t, conns)
 	})
+	t.Run("BundleExtension", func(t *testing.T) {
+		testBundleExtensionAPI(ctx, t, conns)
+	})
 	t.Run("Entry", func(t *testing.T) {
 		testEntryAPI(ctx, t, conns)
 	})
+	t.Run("EntryExtension", func(t *testing.T) {
+		testEntryExtensionAPI(ctx, t, conns)
+	})
 	t.Run("SVID", func(t *testing.T) {
 		testSVIDAPI(ctx, t, conns)
 	})
 	t.Run("TrustDomain", func(t *testing.T) {
 		testTrustDomainAPI(ctx, t, conns)
 	})
+	t.Run("TrustDomainExtension", func(t *testing.T) {
+		testTrustDomainExtensionAPI(ctx, t, conns)
+	})
 
 	t.Run("LocalAuthority", func(t *testing.T) {
 		testLocalAuthorityAPI(ctx, t, conns)
@@ -526,6 +547,74 @@ func testAgentAPI(ctx context.Context, t *testing.T, conns testConns) {
 	})
 }
 
+func testAgentExtensionAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewAgentExtensionClient(conns.local), map[string]bool{
+			"SetAgentsCanReattest": true,
+			"ListAgentAliases":     true,
+			"AddAgentAlias":        true,
+			"RemoveAgentAlias":     true,
+			"ListAgentsToPrune":    true,
+			"GetAgentDetails":      true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewAgentExtensionClient(conns.noAuth), map[string]bool{
+			"SetAgentsCanReattest": false,
+			"ListAgentAliases":     false,
+			"AddAgentAlias":        false,
+			"RemoveAgentAlias":     false,
+			"ListAgentsToPrune":    false,
+			"GetAgentDetails":      false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewAgentExtensionClient(conns.agent), map[string]bool{
+			"SetAgentsCanReattest": false,
+			"ListAgentAliases":     false,
+			"AddAgentAlias":        false,
+			"RemoveAgentAlias":     false,
+			"ListAgentsToPrune":    false,
+			"GetAgentDetails":      false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewAgentExtensionClient(conns.admin), map[string]bool{
+			"SetAgentsCanReattest": true,
+			"ListAgentAliases":     true,
+			"AddAgentAlias":        true,
+			"RemoveAgentAlias":     true,
+			"ListAgentsToPrune":    true,
+			"GetAgentDetails":      true,
+		})
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewAgentExtensionClient(conns.federatedAdmin), map[string]bool{
+			"SetAgentsCanReattest": true,
+			"ListAgentAliases":     true,
+			"AddAgentAlias":        true,
+			"RemoveAgentAlias":     true,
+			"ListAgentsToPrune":    true,
+			"GetAgentDetails":      true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewAgentExtensionClient(conns.downstream), map[string]bool{
+			"SetAgentsCanReattest": false,
+			"ListAgentAliases":     false,
+			"AddAgentAlias":        false,
+			"RemoveAgentAlias":     false,
+			"ListAgentsToPrune":    false,
+			"GetAgentDetails":      false,
+		})
+	})
+}
+
 func testHealthAPI(ctx context.Context, t *testing.T, conns testConns) {
 	t.Run("Local", func(t *testing.T) {
 		testAuthorization(ctx, t, grpc_health_v1.NewHealthClient(conns.local), map[string]bool{
@@ -705,6 +794,50 @@ func testBundleAPI(ctx context.Context, t *testing.T, conns testConns) {
 	})
 }
 
+func testBundleExtensionAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewBundleExtensionClient(conns.local), map[string]bool{
+			"ListBundleAuthoritiesToPrune":    true,
+			"ListFederatedBundleRefreshTimes": true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewBundleExtensionClient(conns.noAuth), map[string]bool{
+			"ListBundleAuthoritiesToPrune":    false,
+			"ListFederatedBundleRefreshTimes": false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewBundleExtensionClient(conns.agent), map[string]bool{
+			"ListBundleAuthoritiesToPrune":    false,
+			"ListFederatedBundleRefreshTimes": false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewBundleExtensionClient(conns.admin), map[string]bool{
+			"ListBundleAuthoritiesToPrune":    true,
+			"ListFederatedBundleRefreshTimes": true,
+		})
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewBundleExtensionClient(conns.federatedAdmin), map[string]bool{
+			"ListBundleAuthoritiesToPrune":    true,
+			"ListFederatedBundleRefreshTimes": true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewBundleExtensionClient(conns.downstream), map[string]bool{
+			"ListBundleAuthoritiesToPrune":    false,
+			"ListFederatedBundleRefreshTimes": false,
+		})
+	})
+}
+
 func testEntryAPI(ctx context.Context, t *testing.T, conns testConns) {
 	t.Run("Local", func(t *testing.T) {
 		testAuthorization(ctx, t, entryv1.NewEntryClient(conns.local), map[string]bool{
@@ -785,6 +918,68 @@ func testEntryAPI(ctx context.Context, t *testing.T, conns testConns) {
 	})
 }
 
+func testEntryExtensionAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewEntryExtensionClient(conns.local), map[string]bool{
+			"PruneOrphanedEntryChildren": true,
+			"ListEntriesToPrune":         true,
+			"CountEntriesByTrustDomain":  true,
+			"ExplainListEntries":         true,
+			"GetEntryWithParents":        true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewEntryExtensionClient(conns.noAuth), map[string]bool{
+			"PruneOrphanedEntryChildren": false,
+			"ListEntriesToPrune":         false,
+			"CountEntriesByTrustDomain":  false,
+			"ExplainListEntries":         false,
+			"GetEntryWithParents":        false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewEntryExtensionClient(conns.agent), map[string]bool{
+			"PruneOrphanedEntryChildren": false,
+			"ListEntriesToPrune":         false,
+			"CountEntriesByTrustDomain":  false,
+			"ExplainListEntries":         false,
+			"GetEntryWithParents":        false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewEntryExtensionClient(conns.admin), map[string]bool{
+			"PruneOrphanedEntryChildren": true,
+			"ListEntriesToPrune":         true,
+			"CountEntriesByTrustDomain":  true,
+			"ExplainListEntries":         true,
+			"GetEntryWithParents":        true,
+		})
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewEntryExtensionClient(conns.federatedAdmin), map[string]bool{
+			"PruneOrphanedEntryChildren": true,
+			"ListEntriesToPrune":         true,
+			"CountEntriesByTrustDomain":  true,
+			"ExplainListEntries":         true,
+			"GetEntryWithParents":        true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewEntryExtensionClient(conns.downstream), map[string]bool{
+			"PruneOrphanedEntryChildren": false,
+			"ListEntriesToPrune":         false,
+			"CountEntriesByTrustDomain":  false,
+			"ExplainListEntries":         false,
+			"GetEntryWithParents":        false,
+		})
+	})
+}
+
 func testSVIDAPI(ctx context.Context, t *testing.T, conns testConns) {
 	t.Run("Local", func(t *testing.T) {
 		testAuthorization(ctx, t, svidv1.NewSVIDClient(conns.local), map[string]bool{
@@ -915,6 +1110,50 @@ func testTrustDomainAPI(ctx context.Context, t *testing.T, conns testConns) {
 	})
 }
 
+func testTrustDomainExtensionAPI(ctx context.Context, t *testing.T, conns testConns) {
+	t.Run("Local", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewTrustDomainExtensionClient(conns.local), map[string]bool{
+			"SetFederationRelationshipRefreshInterval": true,
+			"ListFederationRelationshipPolls":          true,
+		})
+	})
+
+	t.Run("NoAuth", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewTrustDomainExtensionClient(conns.noAuth), map[string]bool{
+			"SetFederationRelationshipRefreshInterval": false,
+			"ListFederationRelationshipPolls":          false,
+		})
+	})
+
+	t.Run("Agent", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewTrustDomainExtensionClient(conns.agent), map[string]bool{
+			"SetFederationRelationshipRefreshInterval": false,
+			"ListFederationRelationshipPolls":          false,
+		})
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewTrustDomainExtensionClient(conns.admin), map[string]bool{
+			"SetFederationRelationshipRefreshInterval": true,
+			"ListFederationRelationshipPolls":          true,
+		})
+	})
+
+	t.Run("Federated Admin", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewTrustDomainExtensionClient(conns.federatedAdmin), map[string]bool{
+			"SetFederationRelationshipRefreshInterval": true,
+			"ListFederationRelationshipPolls":          true,
+		})
+	})
+
+	t.Run("Downstream", func(t *testing.T) {
+		testAuthorization(ctx, t, extensionv1.NewTrustDomainExtensionClient(conns.downstream), map[string]bool{
+			"SetFederationRelationshipRefreshInterval": false,
+			"ListFederationRelationshipPolls":          false,
+		})
+	})
+}
+
 func testLocalAuthorityAPI(ctx context.Context, t *testing.T, conns testConns) {
 	t.Run("Local", func(t *testing.T) {
 		testAuthorization(ctx, t, localauthorityv1.NewLocalAuthorityClient(conns.local), map[string]bool{
@@ -1217,6 +1456,34 @@ func (agentServer) PostStatus(_ context.Context, _ *agentv1.PostStatusRequest) (
 	return &agentv1.PostStatusResponse{}, nil
 }
 
+type agentExtensionServer struct {
+	extensionv1.UnsafeAgentExtensionServer
+}
+
+func (agentExtensionServer) SetAgentsCanReattest(_ context.Context, _ *extensionv1.SetAgentsCanReattestRequest) (*extensionv1.SetAgentsCanReattestResponse, error) {
+	return &extensionv1.SetAgentsCanReattestResponse{}, nil
+}
+
+func (agentExtensionServer) ListAgentAliases(_ context.Context, _ *extensionv1.ListAgentAliasesRequest) (*extensionv1.ListAgentAliasesResponse, error) {
+	return &extensionv1.ListAgentAliasesResponse{}, nil
+}
+
+func (agentExtensionServer) AddAgentAlias(_ context.Context, _ *extensionv1.AddAgentAliasRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func (agentExtensionServer) RemoveAgentAlias(_ context.Context, _ *extensionv1.RemoveAgentAliasRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func (agentExtensionServer) ListAgentsToPrune(_ context.Context, _ *extensionv1.ListAgentsToPruneRequest) (*extensionv1.ListAgentsToPruneResponse, error) {
+	return &extensionv1.ListAgentsToPruneResponse{}, nil
+}
+
+func (agentExtensionServer) GetAgentDetails(_ context.Context, _ *extensionv1.GetAgentDetailsRequest) (*extensionv1.GetAgentDetailsResponse, error) {
+	return &extensionv1.GetAgentDetailsResponse{}, nil
+}
+
 type bundleServer struct {
 	bundlev1.UnsafeBundleServer
 }
@@ -1263,6 +1530,18 @@ func (bundleServer) BatchDeleteFederatedBundle(_ context.Context, _ *bundlev1.Ba
 	return &bundlev1.BatchDeleteFederatedBundleResponse{}, nil
 }
 
+type bundleExtensionServer struct {
+	extensionv1.UnsafeBundleExtensionServer
+}
+
+func (bundleExtensionServer) ListBundleAuthoritiesToPrune(_ context.Context, _ *extensionv1.ListBundleAuthoritiesToPruneRequest) (*extensionv1.ListBundleAuthoritiesToPruneResponse, error) {
+	return &extensionv1.ListBundleAuthoritiesToPruneResponse{}, nil
+}
+
+func (bundleExtensionServer) ListFederatedBundleRefreshTimes(_ context.Context, _ *extensionv1.ListFederatedBundleRefreshTimesRequest) (*extensionv1.ListFederatedBundleRefreshTimesResponse, error) {
+	return &extensionv1.ListFederatedBundleRefreshTimesResponse{}, nil
+}
+
 type debugServer struct {
 	debugv1.UnsafeDebugServer
 }
@@ -1307,6 +1586,30 @@ func (entryServer) SyncAuthorizedEntries(stream entryv1.Entry_SyncAuthorizedEntr
 	return stream.Send(&entryv1.SyncAuthorizedEntriesResponse{})
 }
 
+type entryExtensionServer struct {
+	extensionv1.UnsafeEntryExtensionServer
+}
+
+func (entryExtensionServer) PruneOrphanedEntryChildren(_ context.Context, _ *extensionv1.PruneOrphanedEntryChildrenRequest) (*extensionv1.PruneOrphanedEntryChildrenResponse, error) {
+	return &extensionv1.PruneOrphanedEntryChildrenResponse{}, nil
+}
+
+func (entryExtensionServer) ListEntriesToPrune(_ context.Context, _ *extensionv1.ListEntriesToPruneRequest) (*extensionv1.ListEntriesToPruneResponse, error) {
+	return &extensionv1.ListEntriesToPruneResponse{}, nil
+}
+
+func (entryExtensionServer) CountEntriesByTrustDomain(_ context.Context, _ *extensionv1.CountEntriesByTrustDomainRequest) (*extensionv1.CountEntriesByTrustDomainResponse, error) {
+	return &extensionv1.CountEntriesByTrustDomainResponse{}, nil
+}
+
+func (entryExtensionServer) ExplainListEntries(_ context.Context, _ *extensionv1.ExplainListEntriesRequest) (*extensionv1.ExplainListEntriesResponse, error) {
+	return &extensionv1.ExplainListEntriesResponse{}, nil
+}
+
+func (entryExtensionServer) GetEntryWithParents(_ context.Context, _ *extensionv1.GetEntryWithParentsRequest) (*extensionv1.GetEntryWithParentsResponse, error) {
+	return &extensionv1.GetEntryWithParentsResponse{}, nil
+}
+
 type healthServer struct {
 	grpc_health_v1.UnsafeHealthServer
 }
@@ -1387,6 +1690,18 @@ func (trustDomainServer) RefreshBundle(_ context.Context, _ *trustdomainv1.Refre
 	return &emptypb.Empty{}, nil
 }
 
+type trustDomainExtensionServer struct {
+	extensionv1.UnsafeTrustDomainExtensionServer
+}
+
+func (trustDomainExtensionServer) SetFederationRelationshipRefreshInterval(_ context.Context, _ *extensionv1.SetFederationRelationshipRefreshIntervalRequest) (*emptypb.Empty, error) {
+	return &emptypb.Empty{}, nil
+}
+
+func (trustDomainExtensionServer) ListFederationRelationshipPolls(_ context.Context, _ *extensionv1.ListFederationRelationshipPollsRequest) (*extensionv1.ListFederationRelationshipPollsResponse, error) {
+	return &extensionv1.ListFederationRelationshipPollsResponse{}, nil
+}
+
 type localAuthorityServer struct {
 	localauthorityv1.UnsafeLocalAuthorityServer
 }
@@ -2,7 +2,6 @@ package endpoints
 
 import (
 	"context"
-	"errors"
 	"sync"
 	"time"
 
@@ -10,6 +9,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
 	"github.com/spiffe/spire-api-sdk/proto/spire/api/types"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/api"
 	"github.com/spiffe/spire/pkg/server/cache/entrycache"
 	"github.com/spiffe/spire/pkg/server/datastore"
@@ -102,8 +102,29 @@ func (a *AuthorizedEntryFetcherWithFullCache) PruneEventsTask(ctx context.Contex
 }
 
 func (a *AuthorizedEntryFetcherWithFullCache) pruneEvents(ctx context.Context, olderThan time.Duration) error {
-	pruneRegistrationEntryEventsErr := a.ds.PruneRegistrationEntryEvents(ctx, olderThan)
-	pruneAttestedNodeEventsErr := a.ds.PruneAttestedNodeEvents(ctx, olderThan)
+	if err := a.ds.PruneEvents(ctx, a.clk.Now().Add(-olderThan)); err != nil {
+		return err
+	}
+	return compactEvents(ctx, a.log, a.ds)
+}
 
-	return errors.Join(pruneRegistrationEntryEventsErr, pruneAttestedNodeEventsErr)
+// compactEvents deletes the registration entry and attested node events
+// superseded by a later event for the same entry or node, so caches catching
+// up on events don't reload the same entry or node over and over.
+func compactEvents(ctx context.Context, log logrus.FieldLogger, ds datastore.DataStore) error {
+	entryEvents, err := ds.CompactRegistrationEntryEvents(ctx)
+	if err != nil {
+		return err
+	}
+	nodeEvents, err := ds.CompactAttestedNodeEvents(ctx)
+	if err != nil {
+		return err
+	}
+	if entryEvents > 0 || nodeEvents > 0 {
+		log.WithFields(logrus.Fields{
+			telemetry.RegistrationEntryEvent: entryEvents,
+			telemetry.NodeEvent:              nodeEvents,
+		}).Debug("Compacted events")
+	}
+	return nil
 }
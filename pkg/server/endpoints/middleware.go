@@ -3,6 +3,7 @@ package endpoints
 import (
 	"context"
 	"crypto/x509"
+	"errors"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
@@ -17,7 +18,6 @@ import (
 	"github.com/spiffe/spire/pkg/server/authpolicy"
 	"github.com/spiffe/spire/pkg/server/ca/manager"
 	"github.com/spiffe/spire/pkg/server/datastore"
-	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -43,8 +43,10 @@ func Middleware(log logrus.FieldLogger, metrics telemetry.Metrics, ds datastore.
 
 func EntryFetcher(ds datastore.DataStore) middleware.EntryFetcher {
 	return middleware.EntryFetcherFunc(func(ctx context.Context, id spiffeid.ID) ([]*types.Entry, error) {
+		active := true
 		resp, err := ds.ListRegistrationEntries(ctx, &datastore.ListRegistrationEntriesRequest{
 			BySpiffeID: id.String(),
+			ByActive:   &active,
 		})
 		if err != nil {
 			return nil, err
@@ -84,12 +86,12 @@ func AgentAuthorizer(ds datastore.DataStore, clk clock.Clock) middleware.AgentAu
 		case attestedNode.NewCertSerialNumber == agentSVID.SerialNumber.String():
 			// AgentSVID matches the new serial number, access granted
 			// Also update the attested node agent serial number from 'new' to 'current'
-			_, err := ds.UpdateAttestedNode(ctx, &common.AttestedNode{
-				SpiffeId:         attestedNode.SpiffeId,
-				CertNotAfter:     attestedNode.NewCertNotAfter,
-				CertSerialNumber: attestedNode.NewCertSerialNumber,
-				CanReattest:      attestedNode.CanReattest,
-			}, nil)
+			_, err := ds.PromoteAttestedNodeSerial(ctx, attestedNode.SpiffeId, attestedNode.NewCertSerialNumber)
+			if errors.Is(err, datastore.ErrAttestedNodeSerialConflict) {
+				// The node changed since it was fetched. Access is still
+				// granted if another server promoted the serial number.
+				return checkPromotedAgentSerial(ctx, ds, id, agentSVID)
+			}
 			if err != nil {
 				log.WithFields(logrus.Fields{
 					telemetry.SVIDSerialNumber: agentSVID.SerialNumber.String(),
@@ -109,6 +111,30 @@ func AgentAuthorizer(ds datastore.DataStore, clk clock.Clock) middleware.AgentAu
 	})
 }
 
+// checkPromotedAgentSerial checks that the agent SVID became the current one
+// of the agent after its promotion failed because of a conflict.
+func checkPromotedAgentSerial(ctx context.Context, ds datastore.DataStore, id string, agentSVID *x509.Certificate) error {
+	log := rpccontext.Logger(ctx)
+
+	attestedNode, err := ds.FetchAttestedNode(ctx, id)
+	switch {
+	case err != nil:
+		log.WithError(err).Error("Unable to look up agent information")
+		return status.Errorf(codes.Internal, "unable to look up agent information: %v", err)
+	case attestedNode == nil:
+		log.Error("Agent is not attested")
+		return errorutil.PermissionDenied(types.PermissionDeniedDetails_AGENT_NOT_ATTESTED, "agent %q is not attested", id)
+	case attestedNode.CertSerialNumber == agentSVID.SerialNumber.String():
+		return nil
+	default:
+		log.WithFields(logrus.Fields{
+			telemetry.SVIDSerialNumber: agentSVID.SerialNumber.String(),
+			telemetry.SerialNumber:     attestedNode.CertSerialNumber,
+		}).Error("Agent SVID is not active")
+		return errorutil.PermissionDenied(types.PermissionDeniedDetails_AGENT_NOT_ACTIVE, "agent %q expected to have serial number %q; has %q", id, attestedNode.CertSerialNumber, agentSVID.SerialNumber.String())
+	}
+}
+
 func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 	noLimit := middleware.NoLimit()
 	attestLimit := middleware.DisabledLimit()
@@ -129,60 +155,75 @@ func RateLimits(config RateLimitConfig) map[string]api.RateLimiter {
 	pushJWTKeyLimit := middleware.PerIPLimit(limits.PushJWTKeyLimitPerIP)
 
 	return map[string]api.RateLimiter{
-		"/spire.api.server.svid.v1.SVID/MintX509SVID":                                    noLimit,
-		"/spire.api.server.svid.v1.SVID/MintJWTSVID":                                     noLimit,
-		"/spire.api.server.svid.v1.SVID/BatchNewX509SVID":                                csrLimit,
-		"/spire.api.server.svid.v1.SVID/NewJWTSVID":                                      jsrLimit,
-		"/spire.api.server.svid.v1.SVID/NewDownstreamX509CA":                             csrLimit,
-		"/spire.api.server.bundle.v1.Bundle/GetBundle":                                   noLimit,
-		"/spire.api.server.bundle.v1.Bundle/AppendBundle":                                noLimit,
-		"/spire.api.server.bundle.v1.Bundle/PublishJWTAuthority":                         pushJWTKeyLimit,
-		"/spire.api.server.bundle.v1.Bundle/CountBundles":                                noLimit,
-		"/spire.api.server.bundle.v1.Bundle/ListFederatedBundles":                        noLimit,
-		"/spire.api.server.bundle.v1.Bundle/GetFederatedBundle":                          noLimit,
-		"/spire.api.server.bundle.v1.Bundle/BatchCreateFederatedBundle":                  noLimit,
-		"/spire.api.server.bundle.v1.Bundle/BatchUpdateFederatedBundle":                  noLimit,
-		"/spire.api.server.bundle.v1.Bundle/BatchSetFederatedBundle":                     noLimit,
-		"/spire.api.server.bundle.v1.Bundle/BatchDeleteFederatedBundle":                  noLimit,
-		"/spire.api.server.debug.v1.Debug/GetInfo":                                       noLimit,
-		"/spire.api.server.entry.v1.Entry/CountEntries":                                  noLimit,
-		"/spire.api.server.entry.v1.Entry/ListEntries":                                   noLimit,
-		"/spire.api.server.entry.v1.Entry/GetEntry":                                      noLimit,
-		"/spire.api.server.entry.v1.Entry/BatchCreateEntry":                              noLimit,
-		"/spire.api.server.entry.v1.Entry/BatchUpdateEntry":                              noLimit,
-		"/spire.api.server.entry.v1.Entry/BatchDeleteEntry":                              noLimit,
-		"/spire.api.server.entry.v1.Entry/GetAuthorizedEntries":                          noLimit,
-		"/spire.api.server.entry.v1.Entry/SyncAuthorizedEntries":                         noLimit,
-		"/spire.api.server.logger.v1.Logger/GetLogger":                                   noLimit,
-		"/spire.api.server.logger.v1.Logger/SetLogLevel":                                 noLimit,
-		"/spire.api.server.logger.v1.Logger/ResetLogLevel":                               noLimit,
-		"/spire.api.server.agent.v1.Agent/CountAgents":                                   noLimit,
-		"/spire.api.server.agent.v1.Agent/ListAgents":                                    noLimit,
-		"/spire.api.server.agent.v1.Agent/GetAgent":                                      noLimit,
-		"/spire.api.server.agent.v1.Agent/DeleteAgent":                                   noLimit,
-		"/spire.api.server.agent.v1.Agent/BanAgent":                                      noLimit,
-		"/spire.api.server.agent.v1.Agent/AttestAgent":                                   attestLimit,
-		"/spire.api.server.agent.v1.Agent/RenewAgent":                                    csrLimit,
-		"/spire.api.server.agent.v1.Agent/CreateJoinToken":                               noLimit,
-		"/spire.api.server.trustdomain.v1.TrustDomain/ListFederationRelationships":       noLimit,
-		"/spire.api.server.trustdomain.v1.TrustDomain/GetFederationRelationship":         noLimit,
-		"/spire.api.server.trustdomain.v1.TrustDomain/BatchCreateFederationRelationship": noLimit,
-		"/spire.api.server.trustdomain.v1.TrustDomain/BatchUpdateFederationRelationship": noLimit,
-		"/spire.api.server.trustdomain.v1.TrustDomain/BatchDeleteFederationRelationship": noLimit,
-		"/spire.api.server.trustdomain.v1.TrustDomain/RefreshBundle":                     noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/GetJWTAuthorityState":        noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/PrepareJWTAuthority":         noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/ActivateJWTAuthority":        noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/TaintJWTAuthority":           noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/RevokeJWTAuthority":          noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/GetX509AuthorityState":       noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/PrepareX509Authority":        noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/ActivateX509Authority":       noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/TaintX509Authority":          noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/TaintX509UpstreamAuthority":  noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/RevokeX509Authority":         noLimit,
-		"/spire.api.server.localauthority.v1.LocalAuthority/RevokeX509UpstreamAuthority": noLimit,
-		"/grpc.health.v1.Health/Check":                                                   noLimit,
-		"/grpc.health.v1.Health/Watch":                                                   noLimit,
+		"/spire.api.server.svid.v1.SVID/MintX509SVID":                                                  noLimit,
+		"/spire.api.server.svid.v1.SVID/MintJWTSVID":                                                   noLimit,
+		"/spire.api.server.svid.v1.SVID/BatchNewX509SVID":                                              csrLimit,
+		"/spire.api.server.svid.v1.SVID/NewJWTSVID":                                                    jsrLimit,
+		"/spire.api.server.svid.v1.SVID/NewDownstreamX509CA":                                           csrLimit,
+		"/spire.api.server.bundle.v1.Bundle/GetBundle":                                                 noLimit,
+		"/spire.api.server.bundle.v1.Bundle/AppendBundle":                                              noLimit,
+		"/spire.api.server.bundle.v1.Bundle/PublishJWTAuthority":                                       pushJWTKeyLimit,
+		"/spire.api.server.bundle.v1.Bundle/CountBundles":                                              noLimit,
+		"/spire.api.server.bundle.v1.Bundle/ListFederatedBundles":                                      noLimit,
+		"/spire.api.server.bundle.v1.Bundle/GetFederatedBundle":                                        noLimit,
+		"/spire.api.server.bundle.v1.Bundle/BatchCreateFederatedBundle":                                noLimit,
+		"/spire.api.server.bundle.v1.Bundle/BatchUpdateFederatedBundle":                                noLimit,
+		"/spire.api.server.bundle.v1.Bundle/BatchSetFederatedBundle":                                   noLimit,
+		"/spire.api.server.bundle.v1.Bundle/BatchDeleteFederatedBundle":                                noLimit,
+		"/spire.api.server.extension.v1.BundleExtension/ListBundleAuthoritiesToPrune":                  noLimit,
+		"/spire.api.server.extension.v1.BundleExtension/ListFederatedBundleRefreshTimes":               noLimit,
+		"/spire.api.server.debug.v1.Debug/GetInfo":                                                     noLimit,
+		"/spire.api.server.entry.v1.Entry/CountEntries":                                                noLimit,
+		"/spire.api.server.entry.v1.Entry/ListEntries":                                                 noLimit,
+		"/spire.api.server.entry.v1.Entry/GetEntry":                                                    noLimit,
+		"/spire.api.server.entry.v1.Entry/BatchCreateEntry":                                            noLimit,
+		"/spire.api.server.entry.v1.Entry/BatchUpdateEntry":                                            noLimit,
+		"/spire.api.server.entry.v1.Entry/BatchDeleteEntry":                                            noLimit,
+		"/spire.api.server.entry.v1.Entry/GetAuthorizedEntries":                                        noLimit,
+		"/spire.api.server.entry.v1.Entry/SyncAuthorizedEntries":                                       noLimit,
+		"/spire.api.server.extension.v1.EntryExtension/PruneOrphanedEntryChildren":                     noLimit,
+		"/spire.api.server.extension.v1.EntryExtension/ListEntriesToPrune":                             noLimit,
+		"/spire.api.server.extension.v1.EntryExtension/CountEntriesByTrustDomain":                      noLimit,
+		"/spire.api.server.extension.v1.EntryExtension/ExplainListEntries":                             noLimit,
+		"/spire.api.server.extension.v1.EntryExtension/GetEntryWithParents":                            noLimit,
+		"/spire.api.server.logger.v1.Logger/GetLogger":                                                 noLimit,
+		"/spire.api.server.logger.v1.Logger/SetLogLevel":                                               noLimit,
+		"/spire.api.server.logger.v1.Logger/ResetLogLevel":                                             noLimit,
+		"/spire.api.server.agent.v1.Agent/CountAgents":                                                 noLimit,
+		"/spire.api.server.agent.v1.Agent/ListAgents":                                                  noLimit,
+		"/spire.api.server.agent.v1.Agent/GetAgent":                                                    noLimit,
+		"/spire.api.server.agent.v1.Agent/DeleteAgent":                                                 noLimit,
+		"/spire.api.server.agent.v1.Agent/BanAgent":                                                    noLimit,
+		"/spire.api.server.agent.v1.Agent/AttestAgent":                                                 attestLimit,
+		"/spire.api.server.agent.v1.Agent/RenewAgent":                                                  csrLimit,
+		"/spire.api.server.agent.v1.Agent/CreateJoinToken":                                             noLimit,
+		"/spire.api.server.extension.v1.AgentExtension/SetAgentsCanReattest":                           noLimit,
+		"/spire.api.server.extension.v1.AgentExtension/ListAgentAliases":                               noLimit,
+		"/spire.api.server.extension.v1.AgentExtension/AddAgentAlias":                                  noLimit,
+		"/spire.api.server.extension.v1.AgentExtension/RemoveAgentAlias":                               noLimit,
+		"/spire.api.server.extension.v1.AgentExtension/ListAgentsToPrune":                              noLimit,
+		"/spire.api.server.extension.v1.AgentExtension/GetAgentDetails":                                noLimit,
+		"/spire.api.server.trustdomain.v1.TrustDomain/ListFederationRelationships":                     noLimit,
+		"/spire.api.server.trustdomain.v1.TrustDomain/GetFederationRelationship":                       noLimit,
+		"/spire.api.server.trustdomain.v1.TrustDomain/BatchCreateFederationRelationship":               noLimit,
+		"/spire.api.server.trustdomain.v1.TrustDomain/BatchUpdateFederationRelationship":               noLimit,
+		"/spire.api.server.trustdomain.v1.TrustDomain/BatchDeleteFederationRelationship":               noLimit,
+		"/spire.api.server.trustdomain.v1.TrustDomain/RefreshBundle":                                   noLimit,
+		"/spire.api.server.extension.v1.TrustDomainExtension/SetFederationRelationshipRefreshInterval": noLimit,
+		"/spire.api.server.extension.v1.TrustDomainExtension/ListFederationRelationshipPolls":          noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/GetJWTAuthorityState":                      noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/PrepareJWTAuthority":                       noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/ActivateJWTAuthority":                      noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/TaintJWTAuthority":                         noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/RevokeJWTAuthority":                        noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/GetX509AuthorityState":                     noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/PrepareX509Authority":                      noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/ActivateX509Authority":                     noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/TaintX509Authority":                        noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/TaintX509UpstreamAuthority":                noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/RevokeX509Authority":                       noLimit,
+		"/spire.api.server.localauthority.v1.LocalAuthority/RevokeX509UpstreamAuthority":               noLimit,
+		"/grpc.health.v1.Health/Check":                                                                 noLimit,
+		"/grpc.health.v1.Health/Watch":                                                                 noLimit,
 	}
 }
@@ -70,6 +70,7 @@ func TestAgentAuthorizer(t *testing.T) {
 		name           string
 		failFetch      bool
 		failUpdate     bool
+		conflictUpdate bool
 		node           *common.AttestedNode
 		time           time.Time
 		expectedCode   codes.Code
@@ -226,13 +227,39 @@ func TestAgentAuthorizer(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "new SVID replaced while activating it",
+			node: &common.AttestedNode{
+				SpiffeId:            agentID.String(),
+				CertSerialNumber:    "CURRENT",
+				NewCertSerialNumber: agentSVID.SerialNumber.String(),
+			},
+			conflictUpdate: true,
+			expectedCode:   codes.PermissionDenied,
+			expectedMsg:    fmt.Sprintf(`agent "spiffe://domain.test/spire/agent/foo" expected to have serial number "CURRENT"; has %q`, agentSVID.SerialNumber.String()),
+			expectedReason: types.PermissionDeniedDetails_AGENT_NOT_ACTIVE,
+			expectedLogs: []spiretest.LogEntry{
+				{
+					Level:   logrus.ErrorLevel,
+					Message: "Agent SVID is not active",
+					Data: map[string]any{
+						telemetry.CallerID:         agentID.String(),
+						telemetry.CallerAddr:       "127.0.0.1",
+						telemetry.SVIDSerialNumber: agentSVID.SerialNumber.String(),
+						telemetry.SerialNumber:     "CURRENT",
+					},
+				},
+			},
+		},
 	} {
 		t.Run(tt.name, func(t *testing.T) {
 			log, hook := test.NewNullLogger()
 			ds := fakedatastore.New(t)
 
+			var createdNode *common.AttestedNode
 			if tt.node != nil {
-				_, err := ds.CreateAttestedNode(context.Background(), tt.node)
+				var err error
+				createdNode, err = ds.CreateAttestedNode(context.Background(), tt.node)
 				require.NoError(t, err)
 			}
 
@@ -247,6 +274,9 @@ func TestAgentAuthorizer(t *testing.T) {
 				if tt.failUpdate {
 					return errors.New("update failed")
 				}
+				if tt.conflictUpdate {
+					return datastore.ErrAttestedNodeSerialConflict
+				}
 				return nil
 			}())
 
@@ -282,9 +312,14 @@ func TestAgentAuthorizer(t *testing.T) {
 				require.Fail(t, "unexpected error code")
 			}
 
+			// Authorizing the agent does not record a reattestation
+			expectedNode := proto.Clone(tt.expectedNode).(*common.AttestedNode)
+			expectedNode.AttestedAt = createdNode.AttestedAt
+			expectedNode.LastAttestedAt = createdNode.LastAttestedAt
+
 			attestedNode, err := ds.FetchAttestedNode(context.Background(), tt.node.SpiffeId)
 			require.NoError(t, err)
-			spiretest.RequireProtoEqual(t, tt.expectedNode, attestedNode)
+			spiretest.RequireProtoEqual(t, expectedNode, attestedNode)
 		})
 	}
 }
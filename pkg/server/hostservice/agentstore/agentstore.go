@@ -68,9 +68,11 @@ func (v1 *agentStoreV1) GetAgentInfo(ctx context.Context, req *agentstorev1.GetA
 		return nil, status.Error(codes.NotFound, "no such agent")
 	}
 
+	// The agent ID may be an alias of the attested node, so the info reports
+	// the SPIFFE ID of the node itself.
 	return &agentstorev1.GetAgentInfoResponse{
 		Info: &agentstorev1.AgentInfo{
-			AgentId: req.AgentId,
+			AgentId: attestedNode.SpiffeId,
 		},
 	}, nil
 }
@@ -19,18 +19,20 @@ func TestAgentStore(t *testing.T) {
 		SpiffeId: "spiffe://domain.test/spire/agent/test/foo",
 	})
 	require.NoError(t, err)
+	require.NoError(t, ds.AddAttestedNodeAlias(context.Background(), "spiffe://domain.test/spire/agent/test/foo", "spiffe://domain.test/spire/agent/test/alias"))
 
 	deps := &Deps{
 		DataStore: ds,
 	}
 
 	testCases := []struct {
-		name    string
-		deps    *Deps
-		agentID string
-		code    codes.Code
-		depsErr string
-		getErr  string
+		name       string
+		deps       *Deps
+		agentID    string
+		expAgentID string
+		code       codes.Code
+		depsErr    string
+		getErr     string
 	}{
 		{
 			name:   "precondition failure when no deps set",
@@ -50,9 +52,16 @@ func TestAgentStore(t *testing.T) {
 			getErr:  "no such agent",
 		},
 		{
-			name:    "success",
-			agentID: "spiffe://domain.test/spire/agent/test/foo",
-			deps:    deps,
+			name:       "success",
+			agentID:    "spiffe://domain.test/spire/agent/test/foo",
+			expAgentID: "spiffe://domain.test/spire/agent/test/foo",
+			deps:       deps,
+		},
+		{
+			name:       "alias of an agent",
+			agentID:    "spiffe://domain.test/spire/agent/test/alias",
+			expAgentID: "spiffe://domain.test/spire/agent/test/foo",
+			deps:       deps,
 		},
 	}
 
@@ -81,7 +90,7 @@ func TestAgentStore(t *testing.T) {
 				}
 				require.NoError(err)
 				require.NotNil(t, resp)
-				assert.Equal(resp.Info.AgentId, testCase.agentID)
+				assert.Equal(testCase.expAgentID, resp.Info.AgentId)
 			})
 		})
 	}
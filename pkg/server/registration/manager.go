@@ -2,23 +2,50 @@ package registration
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/andres-erbsen/clock"
 	"github.com/sirupsen/logrus"
 	"github.com/spiffe/spire/pkg/common/telemetry"
 	telemetry_server "github.com/spiffe/spire/pkg/common/telemetry/server"
+	"github.com/spiffe/spire/pkg/common/util"
 	"github.com/spiffe/spire/pkg/server/datastore"
 )
 
 const (
 	_pruningCadence = 5 * time.Minute
+
+	// DefaultExpiryCheckInterval is how often registration entries that are
+	// about to expire are looked for, unless configured otherwise
+	DefaultExpiryCheckInterval = 10 * time.Minute
+
+	// DefaultPruneAttestedNodesInterval is how often expired attested nodes
+	// are pruned, unless configured otherwise
+	DefaultPruneAttestedNodesInterval = time.Hour
 )
 
 // ManagerConfig is the config for the registration manager
 type ManagerConfig struct {
 	DataStore datastore.DataStore
 
+	// ExpiryWarningWindow is how long before their expiry registration
+	// entries are reported as about to expire. If zero, entries are not
+	// reported.
+	ExpiryWarningWindow time.Duration
+
+	// ExpiryCheckInterval is how often registration entries that are about
+	// to expire are looked for. Defaults to DefaultExpiryCheckInterval.
+	ExpiryCheckInterval time.Duration
+
+	// PruneAttestedNodesExpiredFor is how long ago attested nodes must have
+	// expired to be pruned. If zero, attested nodes are not pruned.
+	PruneAttestedNodesExpiredFor time.Duration
+
+	// PruneAttestedNodesInterval is how often expired attested nodes are
+	// pruned. Defaults to DefaultPruneAttestedNodesInterval.
+	PruneAttestedNodesInterval time.Duration
+
 	Log     logrus.FieldLogger
 	Metrics telemetry.Metrics
 
@@ -37,6 +64,12 @@ func NewManager(c ManagerConfig) *Manager {
 	if c.Clock == nil {
 		c.Clock = clock.New()
 	}
+	if c.ExpiryCheckInterval <= 0 {
+		c.ExpiryCheckInterval = DefaultExpiryCheckInterval
+	}
+	if c.PruneAttestedNodesInterval <= 0 {
+		c.PruneAttestedNodesInterval = DefaultPruneAttestedNodesInterval
+	}
 
 	return &Manager{
 		c:       c,
@@ -47,7 +80,19 @@ func NewManager(c ManagerConfig) *Manager {
 
 // Run runs the registration manager
 func (m *Manager) Run(ctx context.Context) error {
-	return m.pruneEvery(ctx)
+	tasks := []func(context.Context) error{m.pruneEvery}
+	if m.c.ExpiryWarningWindow > 0 {
+		tasks = append(tasks, m.checkExpiringEvery)
+	}
+	if m.c.PruneAttestedNodesExpiredFor > 0 {
+		tasks = append(tasks, m.pruneAttestedNodesEvery)
+	}
+
+	err := util.RunTasks(ctx, tasks...)
+	if errors.Is(err, context.Canceled) {
+		err = nil
+	}
+	return err
 }
 
 func (m *Manager) pruneEvery(ctx context.Context) error {
@@ -61,6 +106,9 @@ func (m *Manager) pruneEvery(ctx context.Context) error {
 			if err := m.prune(ctx); err != nil && ctx.Err() == nil {
 				m.log.WithError(err).Error("Failed pruning registration entries")
 			}
+			if err := m.pruneNodeSelectors(ctx); err != nil && ctx.Err() == nil {
+				m.log.WithError(err).Error("Failed pruning expired node selectors")
+			}
 		case <-ctx.Done():
 			return nil
 		}
@@ -74,3 +122,83 @@ func (m *Manager) prune(ctx context.Context) (err error) {
 	err = m.c.DataStore.PruneRegistrationEntries(ctx, m.c.Clock.Now())
 	return err
 }
+
+// pruneNodeSelectors deletes the node selectors whose expiry has passed.
+func (m *Manager) pruneNodeSelectors(ctx context.Context) error {
+	pruned, err := m.c.DataStore.PruneNodeSelectors(ctx, m.c.Clock.Now())
+	if err != nil {
+		return err
+	}
+
+	if pruned > 0 {
+		m.log.WithField(telemetry.Count, pruned).Info("Pruned expired node selectors")
+	}
+	return nil
+}
+
+func (m *Manager) checkExpiringEvery(ctx context.Context) error {
+	ticker := m.c.Clock.Ticker(m.c.ExpiryCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		// Log an error on failure unless we're shutting down
+		if err := m.checkExpiring(ctx); err != nil && ctx.Err() == nil {
+			m.c.Log.WithError(err).Error("Failed checking for expiring registration entries")
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// checkExpiring reports the registration entries that expire within the
+// expiry warning window, so operators can renew them before they are pruned.
+func (m *Manager) checkExpiring(ctx context.Context) error {
+	entryIDs, err := m.c.DataStore.ListRegistrationEntriesExpiringBefore(ctx, m.c.Clock.Now().Add(m.c.ExpiryWarningWindow))
+	if err != nil {
+		return err
+	}
+
+	telemetry_server.SetEntryExpiringGauge(m.c.Metrics, len(entryIDs))
+	if len(entryIDs) > 0 {
+		m.c.Log.WithFields(logrus.Fields{
+			telemetry.Count:          len(entryIDs),
+			telemetry.RegistrationID: entryIDs,
+		}).Warn("Registration entries are about to expire")
+	}
+	return nil
+}
+
+func (m *Manager) pruneAttestedNodesEvery(ctx context.Context) error {
+	ticker := m.c.Clock.Ticker(m.c.PruneAttestedNodesInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Log an error on failure unless we're shutting down
+			if err := m.pruneAttestedNodes(ctx); err != nil && ctx.Err() == nil {
+				m.c.Log.WithError(err).Error("Failed pruning expired attested nodes")
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// pruneAttestedNodes deletes the attested nodes that expired at least
+// PruneAttestedNodesExpiredFor ago.
+func (m *Manager) pruneAttestedNodes(ctx context.Context) error {
+	pruned, err := m.c.DataStore.PruneAttestedNodes(ctx, m.c.Clock.Now().Add(-m.c.PruneAttestedNodesExpiredFor))
+	if err != nil {
+		return err
+	}
+
+	if pruned > 0 {
+		m.c.Log.WithField(telemetry.Count, pruned).Info("Pruned expired attested nodes")
+	}
+	return nil
+}
@@ -2,11 +2,13 @@ package registration
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/spiffe/spire/pkg/common/telemetry"
 	"github.com/spiffe/spire/pkg/server/datastore"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/clock"
@@ -126,13 +128,233 @@ func (s *ManagerSuite) TestPruning() {
 	s.Empty(listResp.Entries)
 }
 
-func (s *ManagerSuite) setupAndRunManager() func() {
+func (s *ManagerSuite) TestPruneNodeSelectors() {
+	done := s.setupAndRunManager()
+	defer done()
+
+	const spiffeID = "spiffe://test.test/spire/agent/node"
+	s.Require().NoError(s.ds.SetNodeSelectors(context.Background(), spiffeID, []*common.Selector{
+		{Type: "type", Value: "expired", ExpiresAt: s.clock.Now().Add(time.Minute).Unix()},
+		{Type: "type", Value: "valid", ExpiresAt: s.clock.Now().Add(time.Hour).Unix()},
+		{Type: "type", Value: "never-expires"},
+	}))
+
+	nodeSelectorValues := func() []string {
+		selectors, err := s.ds.GetNodeSelectors(context.Background(), spiffeID, datastore.RequireCurrent, false)
+		s.Require().NoError(err)
+		var values []string
+		for _, selector := range selectors {
+			values = append(values, selector.Value)
+		}
+		return values
+	}
+
+	// no pruning yet
+	s.NoError(s.m.pruneNodeSelectors(context.Background()))
+	s.ElementsMatch([]string{"expired", "valid", "never-expires"}, nodeSelectorValues())
+	s.Empty(s.logHook.AllEntries())
+
+	// only the expired selector is pruned
+	s.clock.Add(_pruningCadence)
+	s.NoError(s.m.pruneNodeSelectors(context.Background()))
+	s.ElementsMatch([]string{"valid", "never-expires"}, nodeSelectorValues())
+	spiretest.AssertLogs(s.T(), s.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Pruned expired node selectors",
+			Data: logrus.Fields{
+				telemetry.Count:         "1",
+				telemetry.RetryInterval: _pruningCadence.String(),
+			},
+		},
+	})
+}
+
+func (s *ManagerSuite) TestExpiryWarnings() {
+	s.m = NewManager(ManagerConfig{
+		Clock:               s.clock,
+		DataStore:           s.ds,
+		ExpiryWarningWindow: time.Hour,
+		Log:                 s.log,
+		Metrics:             s.metrics,
+	})
+
+	createEntry := func(name string, expiry int64) string {
+		entry, err := s.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+			ParentId:    "spiffe://test.test/agent",
+			SpiffeId:    "spiffe://test.test/" + name,
+			Selectors:   []*common.Selector{{Type: "type", Value: "value"}},
+			EntryExpiry: expiry,
+		})
+		s.Require().NoError(err)
+		return entry.EntryId
+	}
+	soon := createEntry("soon", s.clock.Now().Add(30*time.Minute).Unix())
+	later := createEntry("later", s.clock.Now().Add(2*time.Hour).Unix())
+	createEntry("never", 0)
+
+	expectReport := func(entryIDs ...string) {
+		s.Equal([]fakemetrics.MetricItem{
+			{
+				Type: fakemetrics.SetGaugeType,
+				Key:  []string{telemetry.Entry, telemetry.Expiring, telemetry.Count},
+				Val:  float32(len(entryIDs)),
+			},
+		}, s.metrics.AllMetrics())
+		s.metrics.Reset()
+
+		if len(entryIDs) == 0 {
+			s.Empty(s.logHook.AllEntries())
+			return
+		}
+		spiretest.AssertLogs(s.T(), s.logHook.AllEntries(), []spiretest.LogEntry{
+			{
+				Level:   logrus.WarnLevel,
+				Message: "Registration entries are about to expire",
+				Data: logrus.Fields{
+					telemetry.Count:          fmt.Sprint(len(entryIDs)),
+					telemetry.RegistrationID: fmt.Sprint(entryIDs),
+				},
+			},
+		})
+		s.logHook.Reset()
+	}
+
+	// only the first entry expires within the window
+	s.NoError(s.m.checkExpiring(context.Background()))
+	expectReport(soon)
+
+	// both entries expire within the window, soonest first
+	s.clock.Add(90 * time.Minute)
+	s.NoError(s.m.checkExpiring(context.Background()))
+	expectReport(soon, later)
+
+	// pruned entries are no longer reported
+	s.NoError(s.m.prune(context.Background()))
+	s.metrics.Reset()
+	s.NoError(s.m.checkExpiring(context.Background()))
+	expectReport(later)
+}
+
+func (s *ManagerSuite) TestExpiryWarningsOnRun() {
+	_, err := s.ds.CreateRegistrationEntry(context.Background(), &common.RegistrationEntry{
+		ParentId:    "spiffe://test.test/agent",
+		SpiffeId:    "spiffe://test.test/soon",
+		Selectors:   []*common.Selector{{Type: "type", Value: "value"}},
+		EntryExpiry: s.clock.Now().Add(30 * time.Minute).Unix(),
+	})
+	s.Require().NoError(err)
+
+	done := s.setupAndRunManager(func(c *ManagerConfig) {
+		c.ExpiryWarningWindow = time.Hour
+	})
+	defer done()
+
+	expiringGauge := func() bool {
+		for _, metric := range s.metrics.AllMetrics() {
+			if metric.Type == fakemetrics.SetGaugeType && metric.Val == 1 {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Entries are checked as soon as the manager runs, then on every interval
+	s.Require().Eventually(expiringGauge, time.Minute, 10*time.Millisecond)
+	s.clock.WaitForTickerMulti(time.Minute, 2, "waiting for the pruning and expiry check tickers")
+	s.metrics.Reset()
+	s.clock.Add(DefaultExpiryCheckInterval)
+	s.Require().Eventually(expiringGauge, time.Minute, 10*time.Millisecond)
+}
+
+func (s *ManagerSuite) TestPruneAttestedNodes() {
 	s.m = NewManager(ManagerConfig{
+		Clock:                        s.clock,
+		DataStore:                    s.ds,
+		PruneAttestedNodesExpiredFor: time.Hour,
+		Log:                          s.log,
+		Metrics:                      s.metrics,
+	})
+
+	createNode := func(name string, expiresAt time.Time) {
+		_, err := s.ds.CreateAttestedNode(context.Background(), &common.AttestedNode{
+			SpiffeId:            "spiffe://test.test/spire/agent/" + name,
+			AttestationDataType: "test",
+			CertSerialNumber:    name,
+			CertNotAfter:        expiresAt.Unix(),
+		})
+		s.Require().NoError(err)
+	}
+	createNode("expired-long-ago", s.clock.Now().Add(-2*time.Hour))
+	createNode("expired-recently", s.clock.Now().Add(-30*time.Minute))
+	createNode("valid", s.clock.Now().Add(time.Hour))
+
+	listNodes := func() []string {
+		resp, err := s.ds.ListAttestedNodes(context.Background(), &datastore.ListAttestedNodesRequest{})
+		s.Require().NoError(err)
+		var spiffeIDs []string
+		for _, node := range resp.Nodes {
+			spiffeIDs = append(spiffeIDs, node.SpiffeId)
+		}
+		return spiffeIDs
+	}
+
+	// only the node that expired more than an hour ago is pruned
+	s.NoError(s.m.pruneAttestedNodes(context.Background()))
+	s.Equal([]string{
+		"spiffe://test.test/spire/agent/expired-recently",
+		"spiffe://test.test/spire/agent/valid",
+	}, listNodes())
+	spiretest.AssertLogs(s.T(), s.logHook.AllEntries(), []spiretest.LogEntry{
+		{
+			Level:   logrus.InfoLevel,
+			Message: "Pruned expired attested nodes",
+			Data:    logrus.Fields{telemetry.Count: "1"},
+		},
+	})
+
+	// the other expired node is pruned once it expired over an hour ago
+	s.clock.Add(31 * time.Minute)
+	s.NoError(s.m.pruneAttestedNodes(context.Background()))
+	s.Equal([]string{"spiffe://test.test/spire/agent/valid"}, listNodes())
+}
+
+func (s *ManagerSuite) TestPruneAttestedNodesOnRun() {
+	_, err := s.ds.CreateAttestedNode(context.Background(), &common.AttestedNode{
+		SpiffeId:            "spiffe://test.test/spire/agent/expired",
+		AttestationDataType: "test",
+		CertSerialNumber:    "expired",
+		CertNotAfter:        s.clock.Now().Add(-2 * time.Hour).Unix(),
+	})
+	s.Require().NoError(err)
+
+	done := s.setupAndRunManager(func(c *ManagerConfig) {
+		c.PruneAttestedNodesExpiredFor = time.Hour
+		c.PruneAttestedNodesInterval = 10 * time.Minute
+	})
+	defer done()
+
+	// Nodes are pruned on every interval
+	s.clock.WaitForTickerMulti(time.Minute, 2, "waiting for the entry and attested node pruning tickers")
+	s.clock.Add(10 * time.Minute)
+	s.Require().Eventually(func() bool {
+		resp, err := s.ds.ListAttestedNodes(context.Background(), &datastore.ListAttestedNodesRequest{})
+		s.Require().NoError(err)
+		return len(resp.Nodes) == 0
+	}, time.Minute, 10*time.Millisecond)
+}
+
+func (s *ManagerSuite) setupAndRunManager(opts ...func(*ManagerConfig)) func() {
+	c := ManagerConfig{
 		Clock:     s.clock,
 		DataStore: s.ds,
 		Log:       s.log,
 		Metrics:   s.metrics,
-	})
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	s.m = NewManager(c)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	errCh := make(chan error, 1)
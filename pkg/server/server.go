@@ -29,6 +29,7 @@ import (
 	"github.com/spiffe/spire/pkg/server/ca"
 	"github.com/spiffe/spire/pkg/server/ca/manager"
 	"github.com/spiffe/spire/pkg/server/ca/rotator"
+	"github.com/spiffe/spire/pkg/server/cache/dscache"
 	"github.com/spiffe/spire/pkg/server/catalog"
 	"github.com/spiffe/spire/pkg/server/credtemplate"
 	"github.com/spiffe/spire/pkg/server/credvalidator"
@@ -133,6 +134,9 @@ func (s *Server) run(ctx context.Context) (err error) {
 		return err
 	}
 
+	s.dedupeEntrySelectors(ctx, cat.GetDataStore())
+	s.warnExcessDNSNames(ctx, cat.GetDataStore())
+
 	credBuilder, err := s.newCredBuilder(cat)
 	if err != nil {
 		return err
@@ -294,6 +298,10 @@ func (s *Server) loadCatalog(ctx context.Context, metrics telemetry.Metrics, ide
 		IdentityProvider: identityProvider,
 		AgentStore:       agentStore,
 		HealthChecker:    healthChecker,
+		DataStoreCache: dscache.Config{
+			DisableBundleCache: s.config.DisableBundleCache,
+			BundleMaxTTL:       s.config.BundleCacheMaxTTL,
+		},
 	})
 }
 
@@ -364,9 +372,13 @@ func (s *Server) newCASync(ctx context.Context, healthChecker health.Checker, ca
 
 func (s *Server) newRegistrationManager(cat catalog.Catalog, metrics telemetry.Metrics) *registration.Manager {
 	registrationManager := registration.NewManager(registration.ManagerConfig{
-		DataStore: cat.GetDataStore(),
-		Log:       s.config.Log.WithField(telemetry.SubsystemName, telemetry.RegistrationManager),
-		Metrics:   metrics,
+		DataStore:                    cat.GetDataStore(),
+		ExpiryWarningWindow:          s.config.EntryExpiryWarningWindow,
+		ExpiryCheckInterval:          s.config.EntryExpiryCheckInterval,
+		PruneAttestedNodesExpiredFor: s.config.PruneAttestedNodesExpiredFor,
+		PruneAttestedNodesInterval:   s.config.PruneAttestedNodesInterval,
+		Log:                          s.config.Log.WithField(telemetry.SubsystemName, telemetry.RegistrationManager),
+		Metrics:                      metrics,
 	})
 	return registrationManager
 }
@@ -386,28 +398,34 @@ func (s *Server) newSVIDRotator(ctx context.Context, serverCA ca.ServerCA, metri
 
 func (s *Server) newEndpointsServer(ctx context.Context, catalog catalog.Catalog, svidObserver svid.Observer, serverCA ca.ServerCA, metrics telemetry.Metrics, authorityManager manager.AuthorityManager, authPolicyEngine *authpolicy.Engine, bundleManager *bundle_client.Manager) (endpoints.Server, error) {
 	config := endpoints.Config{
-		TCPAddr:                      s.config.BindAddress,
-		LocalAddr:                    s.config.BindLocalAddress,
-		SVIDObserver:                 svidObserver,
-		TrustDomain:                  s.config.TrustDomain,
-		Catalog:                      catalog,
-		ServerCA:                     serverCA,
-		Log:                          s.config.Log.WithField(telemetry.SubsystemName, telemetry.Endpoints),
-		RootLog:                      s.config.Log,
-		Metrics:                      metrics,
-		AuthorityManager:             authorityManager,
-		RateLimit:                    s.config.RateLimit,
-		Uptime:                       uptime.Uptime,
-		Clock:                        clock.New(),
-		CacheReloadInterval:          s.config.CacheReloadInterval,
-		EventsBasedCache:             s.config.EventsBasedCache,
-		PruneEventsOlderThan:         s.config.PruneEventsOlderThan,
-		SQLTransactionTimeout:        s.config.SQLTransactionTimeout,
-		AuditLogEnabled:              s.config.AuditLogEnabled,
-		AuthPolicyEngine:             authPolicyEngine,
-		BundleManager:                bundleManager,
-		AdminIDs:                     s.config.AdminIDs,
-		UseLegacyDownstreamX509CATTL: s.config.UseLegacyDownstreamX509CATTL,
+		TCPAddr:                        s.config.BindAddress,
+		LocalAddr:                      s.config.BindLocalAddress,
+		SVIDObserver:                   svidObserver,
+		TrustDomain:                    s.config.TrustDomain,
+		Catalog:                        catalog,
+		ServerCA:                       serverCA,
+		Log:                            s.config.Log.WithField(telemetry.SubsystemName, telemetry.Endpoints),
+		RootLog:                        s.config.Log,
+		Metrics:                        metrics,
+		AuthorityManager:               authorityManager,
+		RateLimit:                      s.config.RateLimit,
+		Uptime:                         uptime.Uptime,
+		Clock:                          clock.New(),
+		CacheReloadInterval:            s.config.CacheReloadInterval,
+		EventsBasedCache:               s.config.EventsBasedCache,
+		PruneEventsOlderThan:           s.config.PruneEventsOlderThan,
+		SQLTransactionTimeout:          s.config.SQLTransactionTimeout,
+		AuditLogEnabled:                s.config.AuditLogEnabled,
+		AuthPolicyEngine:               authPolicyEngine,
+		BundleManager:                  bundleManager,
+		AdminIDs:                       s.config.AdminIDs,
+		UseLegacyDownstreamX509CATTL:   s.config.UseLegacyDownstreamX509CATTL,
+		X509SVIDTTL:                    s.config.X509SVIDTTL,
+		JWTSVIDTTL:                     s.config.JWTSVIDTTL,
+		CATTL:                          s.config.CATTL,
+		EntryIssuanceMetrics:           s.config.EntryIssuanceMetrics,
+		EntryIssuanceMetricsSampleRate: s.config.EntryIssuanceMetricsSampleRate,
+		PruneAttestedNodesExpiredFor:   s.config.PruneAttestedNodesExpiredFor,
 	}
 	if s.config.Federation.BundleEndpoint != nil {
 		config.BundleEndpoint.Address = s.config.Federation.BundleEndpoint.Address
@@ -428,6 +446,8 @@ func (s *Server) newBundleManager(cat catalog.Catalog, metrics telemetry.Metrics
 			bundle_client.NewTrustDomainConfigSet(s.config.Federation.FederatesWith),
 			bundle_client.DataStoreTrustDomainConfigSource(log, cat.GetDataStore()),
 		),
+		PollFailureThreshold: s.config.Federation.PollFailureThreshold,
+		PollMaxBackoff:       s.config.Federation.PollMaxBackoff,
 	})
 }
 
@@ -467,11 +487,13 @@ func (s *Server) validateTrustDomain(ctx context.Context, ds datastore.DataStore
 	}
 
 	// Get only first page with a single element
+	fetchSelectors := false
 	nodesResponse, err := ds.ListAttestedNodes(ctx, &datastore.ListAttestedNodesRequest{
 		Pagination: &datastore.Pagination{
 			Token:    "",
 			PageSize: pageSize,
 		},
+		FetchSelectors: &fetchSelectors,
 	})
 	if err != nil {
 		return err
@@ -492,6 +514,34 @@ func (s *Server) validateTrustDomain(ctx context.Context, ds datastore.DataStore
 	return nil
 }
 
+// dedupeEntrySelectors deletes the selectors repeated within a registration
+// entry that older versions of SPIRE left behind. The duplicates are harmless,
+// so failing to delete them doesn't prevent the server from starting.
+func (s *Server) dedupeEntrySelectors(ctx context.Context, ds datastore.DataStore) {
+	deleted, err := ds.DedupeEntrySelectors(ctx)
+	if err != nil {
+		s.config.Log.WithError(err).Warn("Failed to delete duplicate registration entry selectors")
+		return
+	}
+	if deleted > 0 {
+		s.config.Log.WithField(telemetry.DuplicateSelectors, deleted).Info("Deleted duplicate registration entry selectors")
+	}
+}
+
+// warnExcessDNSNames warns about the registration entries having more DNS
+// names than the datastore allows, e.g. after lowering the maximum. They are
+// still served, but updating their DNS names fails until some are removed.
+func (s *Server) warnExcessDNSNames(ctx context.Context, ds datastore.DataStore) {
+	entryIDs, err := ds.ListRegistrationEntriesWithExcessDNSNames(ctx)
+	if err != nil {
+		s.config.Log.WithError(err).Warn("Failed to list registration entries with too many DNS names")
+		return
+	}
+	for _, entryID := range entryIDs {
+		s.config.Log.WithField(telemetry.RegistrationID, entryID).Warn("Registration entry has more DNS names than the datastore allows; updating its DNS names will fail until some are removed")
+	}
+}
+
 // CheckHealth is used as a top-level health check for the Server.
 func (s *Server) CheckHealth() health.State {
 	err := s.tryGetBundle()
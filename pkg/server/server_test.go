@@ -3,12 +3,16 @@ package server
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/spiffe/go-spiffe/v2/spiffeid"
+	"github.com/spiffe/spire/pkg/server/datastore/sqlstore"
 	"github.com/spiffe/spire/proto/spire/common"
 	"github.com/spiffe/spire/test/fakes/fakedatastore"
 	"github.com/stretchr/testify/suite"
@@ -138,3 +142,50 @@ func (suite *ServerTestSuite) TestValidateTrustDomain() {
 	suite.NoError(err)
 	suite.Require().Contains(suite.stdout.String(), invalidSpiffeIDAttestedNode)
 }
+
+func (suite *ServerTestSuite) TestDedupeEntrySelectors() {
+	ctx := context.Background()
+
+	// Nothing to delete, nothing logged
+	suite.server.dedupeEntrySelectors(ctx, suite.ds)
+	suite.Empty(suite.stdout.String())
+
+	// Failures are logged without stopping the server
+	suite.ds.SetNextError(errors.New("oops"))
+	suite.server.dedupeEntrySelectors(ctx, suite.ds)
+	suite.Contains(suite.stdout.String(), "Failed to delete duplicate registration entry selectors")
+	suite.Contains(suite.stdout.String(), "oops")
+}
+
+func (suite *ServerTestSuite) TestWarnExcessDNSNames() {
+	ctx := context.Background()
+
+	log, _ := logtest.NewNullLogger()
+	ds := sqlstore.New(log)
+	dbPath := filepath.Join(suite.T().TempDir(), "datastore.sqlite3")
+	suite.Require().NoError(ds.Configure(ctx, fmt.Sprintf("database_type = \"sqlite3\"\nconnection_string = %q\n", dbPath)))
+	suite.T().Cleanup(func() { ds.Close() })
+
+	entry, err := ds.CreateRegistrationEntry(ctx, &common.RegistrationEntry{
+		SpiffeId:  "spiffe://example.org/foo",
+		ParentId:  "spiffe://example.org/parent",
+		Selectors: []*common.Selector{{Type: "TYPE", Value: "VALUE"}},
+		DnsNames:  []string{"a.org", "b.org"},
+	})
+	suite.Require().NoError(err)
+
+	// Within the maximum, nothing logged
+	suite.server.warnExcessDNSNames(ctx, ds)
+	suite.Empty(suite.stdout.String())
+
+	// Lowering the maximum leaves the entry over it
+	suite.Require().NoError(ds.Configure(ctx, fmt.Sprintf("database_type = \"sqlite3\"\nconnection_string = %q\nmax_dns_names_per_entry = 1\n", dbPath)))
+	suite.server.warnExcessDNSNames(ctx, ds)
+	suite.Contains(suite.stdout.String(), "Registration entry has more DNS names than the datastore allows")
+	suite.Contains(suite.stdout.String(), entry.EntryId)
+
+	// Failures are logged without stopping the server
+	suite.ds.SetNextError(errors.New("oops"))
+	suite.server.warnExcessDNSNames(ctx, suite.ds)
+	suite.Contains(suite.stdout.String(), "Failed to list registration entries with too many DNS names")
+}
@@ -8,8 +8,9 @@ var (
 	AddrOutputUsage = `
   -output value
     	Desired output format (pretty, json); default: pretty.
-  -socketPath string
-    	Path to the SPIRE Server API socket (default "/tmp/spire-server/private/api.sock")
+` + AddrUsage
+	AddrUsage = `  -socketPath string
+    	Path to the Kirin Server API socket (default "/tmp/kirin-server/private/api.sock")
 `
 	AddrValue = "/does-not-exist.sock"
 )
@@ -77,6 +77,13 @@ func (s *DataStore) SetBundle(ctx context.Context, bundle *common.Bundle) (*comm
 	return s.ds.SetBundle(ctx, bundle)
 }
 
+func (s *DataStore) SetBundleRefreshedAt(ctx context.Context, trustDomainID string, refreshedAt time.Time) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.SetBundleRefreshedAt(ctx, trustDomainID, refreshedAt)
+}
+
 func (s *DataStore) AppendBundle(ctx context.Context, bundle *common.Bundle) (*common.Bundle, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -106,6 +113,27 @@ func (s *DataStore) FetchBundle(ctx context.Context, trustDomain string) (*commo
 	return s.ds.FetchBundle(ctx, trustDomain)
 }
 
+func (s *DataStore) FetchBundleContentHash(ctx context.Context, trustDomain string) (string, error) {
+	if err := s.getNextError(); err != nil {
+		return "", err
+	}
+	return s.ds.FetchBundleContentHash(ctx, trustDomain)
+}
+
+func (s *DataStore) ListBundleEvents(ctx context.Context, req *datastore.ListBundleEventsRequest) (*datastore.ListBundleEventsResponse, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListBundleEvents(ctx, req)
+}
+
+func (s *DataStore) ListBundleAuthoritiesToPrune(ctx context.Context, trustDomainID string, expiresBefore time.Time) (*datastore.BundleAuthoritiesToPrune, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListBundleAuthoritiesToPrune(ctx, trustDomainID, expiresBefore)
+}
+
 func (s *DataStore) ListBundles(ctx context.Context, req *datastore.ListBundlesRequest) (*datastore.ListBundlesResponse, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -127,6 +155,34 @@ func (s *DataStore) PruneBundle(ctx context.Context, trustDomainID string, expir
 	return s.ds.PruneBundle(ctx, trustDomainID, expiresBefore)
 }
 
+func (s *DataStore) AddAttestedNodeAlias(ctx context.Context, spiffeID, alias string) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.AddAttestedNodeAlias(ctx, spiffeID, alias)
+}
+
+func (s *DataStore) ListAttestedNodeAliases(ctx context.Context, spiffeID string) ([]string, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListAttestedNodeAliases(ctx, spiffeID)
+}
+
+func (s *DataStore) RemoveAttestedNodeAlias(ctx context.Context, alias string) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.RemoveAttestedNodeAlias(ctx, alias)
+}
+
+func (s *DataStore) AttestedNodeExists(ctx context.Context, spiffeID string) (bool, error) {
+	if err := s.getNextError(); err != nil {
+		return false, err
+	}
+	return s.ds.AttestedNodeExists(ctx, spiffeID)
+}
+
 func (s *DataStore) CountAttestedNodes(ctx context.Context, req *datastore.CountAttestedNodesRequest) (int32, error) {
 	if err := s.getNextError(); err != nil {
 		return 0, err
@@ -148,6 +204,13 @@ func (s *DataStore) FetchAttestedNode(ctx context.Context, spiffeID string) (*co
 	return s.ds.FetchAttestedNode(ctx, spiffeID)
 }
 
+func (s *DataStore) FetchAttestedNodeSerialHistory(ctx context.Context, spiffeID string) ([]*datastore.AttestedNodeSerial, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.FetchAttestedNodeSerialHistory(ctx, spiffeID)
+}
+
 func (s *DataStore) ListAttestedNodes(ctx context.Context, req *datastore.ListAttestedNodesRequest) (*datastore.ListAttestedNodesResponse, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -169,6 +232,41 @@ func (s *DataStore) DeleteAttestedNode(ctx context.Context, spiffeID string) (*c
 	return s.ds.DeleteAttestedNode(ctx, spiffeID)
 }
 
+func (s *DataStore) ListAttestedNodesToPrune(ctx context.Context, expiredBefore time.Time) ([]string, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListAttestedNodesToPrune(ctx, expiredBefore)
+}
+
+func (s *DataStore) PromoteAttestedNodeSerial(ctx context.Context, spiffeID, expectedNewSerial string) (*common.AttestedNode, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.PromoteAttestedNodeSerial(ctx, spiffeID, expectedNewSerial)
+}
+
+func (s *DataStore) Ping(ctx context.Context) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.Ping(ctx)
+}
+
+func (s *DataStore) PruneAttestedNodes(ctx context.Context, expiredBefore time.Time) (int, error) {
+	if err := s.getNextError(); err != nil {
+		return 0, err
+	}
+	return s.ds.PruneAttestedNodes(ctx, expiredBefore)
+}
+
+func (s *DataStore) SetAttestedNodesReattest(ctx context.Context, spiffeIDs []string, canReattest bool) (int, error) {
+	if err := s.getNextError(); err != nil {
+		return 0, err
+	}
+	return s.ds.SetAttestedNodesReattest(ctx, spiffeIDs, canReattest)
+}
+
 func (s *DataStore) ListAttestedNodeEvents(ctx context.Context, req *datastore.ListAttestedNodeEventsRequest) (*datastore.ListAttestedNodeEventsResponse, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -183,6 +281,20 @@ func (s *DataStore) PruneAttestedNodeEvents(ctx context.Context, olderThan time.
 	return s.ds.PruneAttestedNodeEvents(ctx, olderThan)
 }
 
+func (s *DataStore) CompactAttestedNodeEvents(ctx context.Context) (int, error) {
+	if err := s.getNextError(); err != nil {
+		return 0, err
+	}
+	return s.ds.CompactAttestedNodeEvents(ctx)
+}
+
+func (s *DataStore) PruneEvents(ctx context.Context, olderThan time.Time) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.PruneEvents(ctx, olderThan)
+}
+
 func (s *DataStore) CreateAttestedNodeEventForTesting(ctx context.Context, event *datastore.AttestedNodeEvent) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -239,6 +351,13 @@ func (s *DataStore) SetNodeSelectors(ctx context.Context, spiffeID string, selec
 	return s.ds.SetNodeSelectors(ctx, spiffeID, selectors)
 }
 
+func (s *DataStore) SetNodeSelectorsBulk(ctx context.Context, selectorsBySpiffeID map[string][]*common.Selector) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.SetNodeSelectorsBulk(ctx, selectorsBySpiffeID)
+}
+
 func (s *DataStore) ListNodeSelectors(ctx context.Context, req *datastore.ListNodeSelectorsRequest) (*datastore.ListNodeSelectorsResponse, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -246,11 +365,18 @@ func (s *DataStore) ListNodeSelectors(ctx context.Context, req *datastore.ListNo
 	return s.ds.ListNodeSelectors(ctx, req)
 }
 
-func (s *DataStore) GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency datastore.DataConsistency) ([]*common.Selector, error) {
+func (s *DataStore) PruneNodeSelectors(ctx context.Context, expiredBefore time.Time) (int, error) {
+	if err := s.getNextError(); err != nil {
+		return 0, err
+	}
+	return s.ds.PruneNodeSelectors(ctx, expiredBefore)
+}
+
+func (s *DataStore) GetNodeSelectors(ctx context.Context, spiffeID string, dataConsistency datastore.DataConsistency, excludeExpired bool) ([]*common.Selector, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
 	}
-	selectors, err := s.ds.GetNodeSelectors(ctx, spiffeID, dataConsistency)
+	selectors, err := s.ds.GetNodeSelectors(ctx, spiffeID, dataConsistency, excludeExpired)
 	if err == nil {
 		// Sorting helps unit-tests have deterministic assertions.
 		util.SortSelectors(selectors)
@@ -265,6 +391,20 @@ func (s *DataStore) CountRegistrationEntries(ctx context.Context, req *datastore
 	return s.ds.CountRegistrationEntries(ctx, req)
 }
 
+func (s *DataStore) CountRegistrationEntriesByTrustDomain(ctx context.Context) (map[string]int32, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.CountRegistrationEntriesByTrustDomain(ctx)
+}
+
+func (s *DataStore) CreateRegistrationEntries(ctx context.Context, entries []*common.RegistrationEntry) ([]*datastore.CreateRegistrationEntryResult, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.CreateRegistrationEntries(ctx, entries)
+}
+
 func (s *DataStore) CreateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry) (*common.RegistrationEntry, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -279,6 +419,13 @@ func (s *DataStore) CreateOrReturnRegistrationEntry(ctx context.Context, entry *
 	return s.ds.CreateOrReturnRegistrationEntry(ctx, entry)
 }
 
+func (s *DataStore) ExplainListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (string, error) {
+	if err := s.getNextError(); err != nil {
+		return "", err
+	}
+	return s.ds.ExplainListRegistrationEntries(ctx, req)
+}
+
 func (s *DataStore) FetchRegistrationEntry(ctx context.Context, entryID string) (*common.RegistrationEntry, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -286,6 +433,13 @@ func (s *DataStore) FetchRegistrationEntry(ctx context.Context, entryID string)
 	return s.ds.FetchRegistrationEntry(ctx, entryID)
 }
 
+func (s *DataStore) FetchRegistrationEntryWithParents(ctx context.Context, entryID string) ([]*common.RegistrationEntry, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.FetchRegistrationEntryWithParents(ctx, entryID)
+}
+
 func (s *DataStore) ListRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest) (*datastore.ListRegistrationEntriesResponse, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -298,6 +452,18 @@ func (s *DataStore) ListRegistrationEntries(ctx context.Context, req *datastore.
 	return resp, err
 }
 
+func (s *DataStore) ListRegistrationEntriesByFederatesWith(ctx context.Context, trustDomain string, pagination *datastore.Pagination) (*datastore.ListRegistrationEntriesResponse, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	resp, err := s.ds.ListRegistrationEntriesByFederatesWith(ctx, trustDomain, pagination)
+	if err == nil {
+		// Sorting helps unit-tests have deterministic assertions.
+		util.SortRegistrationEntries(resp.Entries)
+	}
+	return resp, err
+}
+
 func (s *DataStore) UpdateRegistrationEntry(ctx context.Context, entry *common.RegistrationEntry, mask *common.RegistrationEntryMask) (*common.RegistrationEntry, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -319,6 +485,48 @@ func (s *DataStore) PruneRegistrationEntries(ctx context.Context, expiresBefore
 	return s.ds.PruneRegistrationEntries(ctx, expiresBefore)
 }
 
+func (s *DataStore) FindOrphanedEntryChildren(ctx context.Context, includeIDs bool) (*datastore.OrphanedEntryChildren, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.FindOrphanedEntryChildren(ctx, includeIDs)
+}
+
+func (s *DataStore) PruneOrphanedEntryChildren(ctx context.Context) (*datastore.OrphanedEntryChildren, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.PruneOrphanedEntryChildren(ctx)
+}
+
+func (s *DataStore) DedupeEntrySelectors(ctx context.Context) (int, error) {
+	if err := s.getNextError(); err != nil {
+		return 0, err
+	}
+	return s.ds.DedupeEntrySelectors(ctx)
+}
+
+func (s *DataStore) ListRegistrationEntriesExpiringBefore(ctx context.Context, expiresBefore time.Time) ([]string, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListRegistrationEntriesExpiringBefore(ctx, expiresBefore)
+}
+
+func (s *DataStore) ListRegistrationEntriesToPrune(ctx context.Context, expiresBefore time.Time) ([]string, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListRegistrationEntriesToPrune(ctx, expiresBefore)
+}
+
+func (s *DataStore) ListRegistrationEntriesWithExcessDNSNames(ctx context.Context) ([]string, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ListRegistrationEntriesWithExcessDNSNames(ctx)
+}
+
 func (s *DataStore) ListRegistrationEntryEvents(ctx context.Context, req *datastore.ListRegistrationEntryEventsRequest) (*datastore.ListRegistrationEntryEventsResponse, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -333,6 +541,13 @@ func (s *DataStore) PruneRegistrationEntryEvents(ctx context.Context, olderThan
 	return s.ds.PruneRegistrationEntryEvents(ctx, olderThan)
 }
 
+func (s *DataStore) CompactRegistrationEntryEvents(ctx context.Context) (int, error) {
+	if err := s.getNextError(); err != nil {
+		return 0, err
+	}
+	return s.ds.CompactRegistrationEntryEvents(ctx)
+}
+
 func (s *DataStore) CreateRegistrationEntryEventForTesting(ctx context.Context, event *datastore.RegistrationEntryEvent) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -340,6 +555,20 @@ func (s *DataStore) CreateRegistrationEntryEventForTesting(ctx context.Context,
 	return s.ds.CreateRegistrationEntryEventForTesting(ctx, event)
 }
 
+func (s *DataStore) SetRegistrationEntryActive(ctx context.Context, entryID string, active bool) (*common.RegistrationEntry, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.SetRegistrationEntryActive(ctx, entryID, active)
+}
+
+func (s *DataStore) StreamRegistrationEntries(ctx context.Context, req *datastore.ListRegistrationEntriesRequest, fn func(*common.RegistrationEntry) error) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.StreamRegistrationEntries(ctx, req, fn)
+}
+
 func (s *DataStore) DeleteRegistrationEntryEventForTesting(ctx context.Context, eventID uint) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -354,6 +583,13 @@ func (s *DataStore) FetchRegistrationEntryEvent(ctx context.Context, eventID uin
 	return s.ds.FetchRegistrationEntryEvent(ctx, eventID)
 }
 
+func (s *DataStore) ConsumeJoinToken(ctx context.Context, token string) (*datastore.JoinToken, error) {
+	if err := s.getNextError(); err != nil {
+		return nil, err
+	}
+	return s.ds.ConsumeJoinToken(ctx, token)
+}
+
 func (s *DataStore) CreateJoinToken(ctx context.Context, token *datastore.JoinToken) error {
 	if err := s.getNextError(); err != nil {
 		return err
@@ -417,6 +653,13 @@ func (s *DataStore) UpdateFederationRelationship(ctx context.Context, fr *datast
 	return s.ds.UpdateFederationRelationship(ctx, fr, mask)
 }
 
+func (s *DataStore) SetFederationRelationshipLastPoll(ctx context.Context, trustDomain spiffeid.TrustDomain, polledAt, nextPollAt time.Time, pollErr string) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.SetFederationRelationshipLastPoll(ctx, trustDomain, polledAt, nextPollAt, pollErr)
+}
+
 func (s *DataStore) FetchCAJournal(ctx context.Context, activeX509AuthorityID string) (*datastore.CAJournal, error) {
 	if err := s.getNextError(); err != nil {
 		return nil, err
@@ -438,6 +681,13 @@ func (s *DataStore) SetCAJournal(ctx context.Context, caJournal *datastore.CAJou
 	return s.ds.SetCAJournal(ctx, caJournal)
 }
 
+func (s *DataStore) PruneCAJournal(ctx context.Context, retention time.Duration) error {
+	if err := s.getNextError(); err != nil {
+		return err
+	}
+	return s.ds.PruneCAJournal(ctx, retention)
+}
+
 func (s *DataStore) PruneCAJournals(ctx context.Context, allCAsExpireBefore int64) error {
 	if err := s.getNextError(); err != nil {
 		return err
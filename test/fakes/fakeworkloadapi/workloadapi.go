@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"sync"
 	"testing"
 
 	"github.com/spiffe/go-spiffe/v2/proto/spiffe/workload"
@@ -18,6 +19,16 @@ type FakeRequest struct {
 	Req  proto.Message
 	Resp proto.Message
 	Err  error
+	// ErrCount, if set, limits Err to the first ErrCount calls. Later calls
+	// are served Resp.
+	ErrCount int
+}
+
+func (r *FakeRequest) errForCall(call int) error {
+	if r.ErrCount > 0 && call > r.ErrCount {
+		return nil
+	}
+	return r.Err
 }
 
 type WorkloadAPI struct {
@@ -28,12 +39,20 @@ type WorkloadAPI struct {
 	ExpFetchJWTSVIDReq    *workload.JWTSVIDRequest
 	ExpFetchJWTBundlesReq *workload.JWTBundlesRequest
 
-	fetchX509SVIDRequest   FakeRequest
-	fetchJWTSVIDRequest    FakeRequest
-	fetchJWTBundlesRequest FakeRequest
-	validateJWTRequest     FakeRequest
+	fetchX509SVIDRequest    FakeRequest
+	fetchX509BundlesRequest FakeRequest
+	fetchJWTSVIDRequests    []FakeRequest
+	fetchJWTBundlesRequest  FakeRequest
+	validateJWTRequest      FakeRequest
+
+	mtx                sync.Mutex
+	fetchX509SVIDCalls int
+	fetchJWTSVIDCalls  int
 }
 
+// New starts a fake Workload API serving the given requests. Several JWT-SVID
+// requests can be given, which are served in order, the last one being served
+// to any further call.
 func New(t *testing.T, responses ...*FakeRequest) *WorkloadAPI {
 	w := new(WorkloadAPI)
 	w.t = t
@@ -45,8 +64,10 @@ func New(t *testing.T, responses ...*FakeRequest) *WorkloadAPI {
 		switch response.Resp.(type) {
 		case *workload.X509SVIDResponse:
 			w.fetchX509SVIDRequest = *response
+		case *workload.X509BundlesResponse:
+			w.fetchX509BundlesRequest = *response
 		case *workload.JWTSVIDResponse:
-			w.fetchJWTSVIDRequest = *response
+			w.fetchJWTSVIDRequests = append(w.fetchJWTSVIDRequests, *response)
 		case *workload.JWTBundlesResponse:
 			w.fetchJWTBundlesRequest = *response
 		case *workload.ValidateJWTSVIDResponse:
@@ -65,13 +86,25 @@ func (w *WorkloadAPI) Addr() net.Addr {
 	return w.addr
 }
 
+// FetchX509SVIDCalls returns the number of FetchX509SVID calls received.
+func (w *WorkloadAPI) FetchX509SVIDCalls() int {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return w.fetchX509SVIDCalls
+}
+
 func (w *WorkloadAPI) FetchX509SVID(req *workload.X509SVIDRequest, stream workload.SpiffeWorkloadAPI_FetchX509SVIDServer) error {
 	if err := checkSecurityHeader(stream.Context()); err != nil {
 		return err
 	}
 
-	if w.fetchX509SVIDRequest.Err != nil {
-		return w.fetchX509SVIDRequest.Err
+	w.mtx.Lock()
+	w.fetchX509SVIDCalls++
+	call := w.fetchX509SVIDCalls
+	w.mtx.Unlock()
+
+	if err := w.fetchX509SVIDRequest.errForCall(call); err != nil {
+		return err
 	}
 
 	if request, ok := w.fetchX509SVIDRequest.Req.(*workload.X509SVIDRequest); ok {
@@ -90,20 +123,53 @@ func (w *WorkloadAPI) FetchX509SVID(req *workload.X509SVIDRequest, stream worklo
 	return nil
 }
 
+func (w *WorkloadAPI) FetchX509Bundles(req *workload.X509BundlesRequest, stream workload.SpiffeWorkloadAPI_FetchX509BundlesServer) error {
+	if err := checkSecurityHeader(stream.Context()); err != nil {
+		return err
+	}
+
+	if w.fetchX509BundlesRequest.Err != nil {
+		return w.fetchX509BundlesRequest.Err
+	}
+
+	if request, ok := w.fetchX509BundlesRequest.Req.(*workload.X509BundlesRequest); ok {
+		spiretest.AssertProtoEqual(w.t, request, req)
+	} else {
+		require.FailNow(w.t, fmt.Sprintf("unexpected message type %T", w.fetchX509BundlesRequest.Req))
+	}
+
+	if response, ok := w.fetchX509BundlesRequest.Resp.(*workload.X509BundlesResponse); ok {
+		_ = stream.Send(response)
+		<-stream.Context().Done()
+	} else {
+		require.FailNow(w.t, fmt.Sprintf("unexpected message type %T", w.fetchX509BundlesRequest.Resp))
+	}
+
+	return nil
+}
+
 func (w *WorkloadAPI) FetchJWTSVID(_ context.Context, req *workload.JWTSVIDRequest) (*workload.JWTSVIDResponse, error) {
-	if w.fetchJWTSVIDRequest.Err != nil {
-		return nil, w.fetchJWTSVIDRequest.Err
+	var fetchJWTSVIDRequest FakeRequest
+	w.mtx.Lock()
+	if n := len(w.fetchJWTSVIDRequests); n > 0 {
+		fetchJWTSVIDRequest = w.fetchJWTSVIDRequests[min(w.fetchJWTSVIDCalls, n-1)]
+	}
+	w.fetchJWTSVIDCalls++
+	w.mtx.Unlock()
+
+	if fetchJWTSVIDRequest.Err != nil {
+		return nil, fetchJWTSVIDRequest.Err
 	}
-	if request, ok := w.fetchJWTSVIDRequest.Req.(*workload.JWTSVIDRequest); ok {
+	if request, ok := fetchJWTSVIDRequest.Req.(*workload.JWTSVIDRequest); ok {
 		spiretest.AssertProtoEqual(w.t, request, req)
 	} else {
-		require.FailNow(w.t, fmt.Sprintf("unexpected message type %T", w.fetchJWTSVIDRequest.Req))
+		require.FailNow(w.t, fmt.Sprintf("unexpected message type %T", fetchJWTSVIDRequest.Req))
 	}
 
-	if response, ok := w.fetchJWTSVIDRequest.Resp.(*workload.JWTSVIDResponse); ok {
+	if response, ok := fetchJWTSVIDRequest.Resp.(*workload.JWTSVIDResponse); ok {
 		return response, nil
 	}
-	require.FailNow(w.t, fmt.Sprintf("unexpected message type %T", w.fetchJWTSVIDRequest.Resp))
+	require.FailNow(w.t, fmt.Sprintf("unexpected message type %T", fetchJWTSVIDRequest.Resp))
 	return nil, nil
 }
 